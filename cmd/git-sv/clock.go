@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// sourceDateEpochEnv reproducible builds variable, seconds since unix epoch used as release date, see https://reproducible-builds.org/specs/source-date-epoch/.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// releaseClock is the clock of release dates, calendar versions and tags. It uses the system time unless fixed by
+// --release-date flag or SOURCE_DATE_EPOCH, handlers are created before flags are parsed so it is shared as a pointer.
+type releaseClock struct {
+	fixed *time.Time
+}
+
+// Now returns the fixed release date, or the system time if not fixed.
+func (c *releaseClock) Now() time.Time {
+	if c.fixed != nil {
+		return *c.fixed
+	}
+	return time.Now()
+}
+
+// fix sets the release date from releaseDate (YYYY-MM-DD) on location, local timezone if nil, or from sourceDateEpoch
+// if releaseDate is empty. Clock keeps the system time if both are empty.
+func (c *releaseClock) fix(releaseDate, sourceDateEpoch string, location *time.Location) error {
+	if location == nil {
+		location = time.Local
+	}
+	switch {
+	case releaseDate != "":
+		date, err := time.ParseInLocation(dateFlagLayout, releaseDate, location)
+		if err != nil {
+			return fmt.Errorf("invalid --release-date %s, use YYYY-MM-DD", releaseDate)
+		}
+		c.fixed = &date
+	case sourceDateEpoch != "":
+		seconds, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
+		if err != nil || seconds < 0 {
+			return fmt.Errorf("invalid %s %s, use seconds since unix epoch", sourceDateEpochEnv, sourceDateEpoch)
+		}
+		date := time.Unix(seconds, 0).In(location)
+		c.fixed = &date
+	}
+	return nil
+}
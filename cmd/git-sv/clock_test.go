@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_releaseClock_fix(t *testing.T) {
+	utc3 := time.FixedZone("UTC+3", 3*60*60)
+	tests := []struct {
+		name            string
+		releaseDate     string
+		sourceDateEpoch string
+		location        *time.Location
+		want            time.Time
+		wantErr         bool
+	}{
+		{"release date", "2024-06-01", "", time.UTC, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), false},
+		{"release date on location", "2024-06-01", "", utc3, time.Date(2024, 6, 1, 0, 0, 0, 0, utc3), false},
+		{"release date before source date epoch", "2024-06-01", "0", time.UTC, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), false},
+		{"source date epoch", "", "1717243200", time.UTC, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), false},
+		{"invalid release date", "06/01/2024", "", time.UTC, time.Time{}, true},
+		{"invalid source date epoch", "", "2024-06-01", time.UTC, time.Time{}, true},
+		{"negative source date epoch", "", "-1", time.UTC, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &releaseClock{}
+			err := clock.fix(tt.releaseDate, tt.sourceDateEpoch, tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("releaseClock.fix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := clock.Now(); !got.Equal(tt.want) || got.Location().String() != tt.want.Location().String() {
+				t.Errorf("releaseClock.Now() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_releaseClock_Now_SystemTime(t *testing.T) {
+	clock := &releaseClock{}
+	if err := clock.fix("", "", nil); err != nil {
+		t.Fatalf("releaseClock.fix() unexpected error: %v", err)
+	}
+	if got := clock.Now(); time.Since(got) > time.Minute {
+		t.Errorf("releaseClock.Now() = %v, want system time", got)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// completionFlag flag appended by completion scripts, handled by urfave/cli.
+const completionFlag = "--generate-bash-completion"
+
+const bashCompletionScript = `# bash completion for git-sv, supports "git-sv" and "git sv"
+_git_sv_complete() {
+  local cur="${COMP_WORDS[COMP_CWORD]}" opts
+  local words=("${COMP_WORDS[@]:$1:$((COMP_CWORD-$1))}")
+  if [[ "$cur" == "-"* ]]; then
+    opts=$(git-sv "${words[@]}" "$cur" ` + completionFlag + ` 2>/dev/null)
+  else
+    opts=$(git-sv "${words[@]}" ` + completionFlag + ` 2>/dev/null)
+  fi
+  COMPREPLY=( $(compgen -W "${opts}" -- "${cur}") )
+}
+
+_git_sv_bin() {
+  _git_sv_complete 1
+}
+
+# called by git completion for "git sv"
+_git_sv() {
+  _git_sv_complete 2
+}
+
+complete -o bashdefault -o default -F _git_sv_bin git-sv
+`
+
+const zshCompletionScript = `#compdef git-sv
+# zsh completion for git-sv, supports "git-sv" and "git sv"
+_git_sv() {
+  local -a opts
+  local cur=${words[-1]}
+  if [[ "$cur" == "-"* ]]; then
+    opts=("${(@f)$(git-sv ${words[@]:1:#words[@]-2} ${cur} ` + completionFlag + ` 2>/dev/null)}")
+  else
+    opts=("${(@f)$(git-sv ${words[@]:1:#words[@]-2} ` + completionFlag + ` 2>/dev/null)}")
+  fi
+
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+
+# called by git completion for "git sv"
+_git-sv() {
+  _git_sv
+}
+
+compdef _git_sv git-sv
+`
+
+// completionRequested checks if git-sv is executed by a completion script or to print one,
+// in both cases it must work outside a git repository.
+func completionRequested(args []string) bool {
+	if len(args) > 1 && args[len(args)-1] == completionFlag {
+		return true
+	}
+	for _, arg := range args[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			return arg == "completion"
+		}
+	}
+	return false
+}
+
+// flagCompleters dynamic completion of flag values by command name and flag name, aliases must be included.
+type flagCompleters map[string]map[string]func(c *cli.Context) []string
+
+// register sets commands completion, flags with values functions complete their values and other cases use urfave/cli default completion.
+// args are the command line arguments, the flag completed is the one before the completion flag.
+func (f flagCompleters) register(commands []*cli.Command, args []string) {
+	for _, cmd := range commands {
+		if values, ok := f[cmd.Name]; ok {
+			cmd.BashComplete = completeFlagValues(values, args)
+		}
+	}
+}
+
+func completionHandler(completers flagCompleters, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		switch shell := c.Args().First(); shell {
+		case "bash":
+			out.printf("%s", bashCompletionScript)
+		case "zsh":
+			out.printf("%s", zshCompletionScript)
+		case "fish":
+			script, err := fishCompletionScript(c.App, completers)
+			if err != nil {
+				return fmt.Errorf("error generating fish completion, message: %v", err)
+			}
+			out.printf("%s", script)
+		case "":
+			return fmt.Errorf("missing shell, use one of: bash, zsh, fish")
+		default:
+			return fmt.Errorf("unsupported shell %s, use one of: bash, zsh, fish", shell)
+		}
+		return nil
+	}
+}
+
+// fishCompletionScript generates commands and flags completion with urfave/cli and adds dynamic values for flags with completers.
+func fishCompletionScript(app *cli.App, completers flagCompleters) (string, error) {
+	binApp := *app
+	binApp.Name = "git-sv"
+	script, err := binApp.ToFishCompletion()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(script)
+	for _, cmd := range app.Commands {
+		values := completers[cmd.Name]
+		for _, flag := range cmd.Flags {
+			name := flag.Names()[0]
+			if _, ok := values[name]; !ok {
+				continue
+			}
+			option, arg := "-l "+name, "--"+name
+			if len(name) == 1 {
+				option, arg = "-s "+name, "-"+name
+			}
+			fmt.Fprintf(&sb, "complete -c git-sv -n '__fish_seen_subcommand_from %s' -f %s -r -a '(git-sv %s %s %s 2>/dev/null)'\n",
+				strings.Join(cmd.Names(), " "), option, cmd.Name, arg, completionFlag)
+		}
+	}
+	return sb.String(), nil
+}
+
+// completeFlagValues completes the value of the last flag with its values function, otherwise uses urfave/cli default completion.
+// Values functions are executed on every completion, they must be fast and return no values on errors.
+func completeFlagValues(values map[string]func(c *cli.Context) []string, args []string) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		if len(args) > 2 {
+			if valuesFn, ok := values[strings.TrimLeft(args[len(args)-2], "-")]; ok {
+				for _, value := range valuesFn(c) {
+					fmt.Fprintln(c.App.Writer, value)
+				}
+				return
+			}
+		}
+		cli.DefaultCompleteWithFlags(c.Command)(c)
+	}
+}
+
+func staticValues(values []string) func(c *cli.Context) []string {
+	return func(c *cli.Context) []string {
+		return values
+	}
+}
+
+func presetNames(presets map[string]sv.CommitMessagePresetConfig) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func tagValues(git sv.Git) func(c *cli.Context) []string {
+	return func(c *cli.Context) []string {
+		tags, err := git.TagsAll()
+		if err != nil {
+			return nil
+		}
+		if component := c.String("component"); component != "" {
+			tags = sv.FilterComponentTags(tags, component)
+		}
+		values := make([]string, len(tags))
+		for i, tag := range tags {
+			values[i] = tag.Name
+		}
+		return values
+	}
+}
+
+func componentValues(monorepoProcessor sv.MonorepoProcessor, cfg sv.MonorepoConfig, repoPath string) func(c *cli.Context) []string {
+	return func(c *cli.Context) []string {
+		if repoPath == "" || cfg.VersioningFile == "" {
+			return nil
+		}
+		components, _, err := monorepoProcessor.FindComponents(repoPath, cfg)
+		if err != nil {
+			return nil
+		}
+		values := make([]string, len(components))
+		for i, component := range components {
+			values[i] = component.Name
+		}
+		return values
+	}
+}
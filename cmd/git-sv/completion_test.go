@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+func Test_completionRequested(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"completion command", []string{"git-sv", "completion", "bash"}, true},
+		{"completion command with global flag", []string{"git-sv", "--no-color", "completion", "zsh"}, true},
+		{"shell completion", []string{"git-sv", "commit", "--type", completionFlag}, true},
+		{"other command", []string{"git-sv", "next-version"}, false},
+		{"completion as argument", []string{"git-sv", "commit", "completion"}, false},
+		{"no args", []string{"git-sv"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := completionRequested(tt.args); got != tt.want {
+				t.Errorf("completionRequested() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newCompletionCtx(app *cli.App, args ...string) *cli.Context {
+	set := flag.NewFlagSet("test", 0)
+	set.String("component", "", "")
+	_ = set.Parse(args)
+	return cli.NewContext(app, set, nil)
+}
+
+func Test_completionHandler(t *testing.T) {
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{
+		{Name: "commit", Aliases: []string{"cmt"}, Flags: []cli.Flag{&cli.StringFlag{Name: "type", Aliases: []string{"t"}}, &cli.StringFlag{Name: "description"}}},
+	}
+	completers := flagCompleters{"commit": {"type": staticValues([]string{"feat"}), "t": staticValues([]string{"feat"})}}
+
+	tests := []struct {
+		name     string
+		shell    string
+		contains []string
+		wantErr  bool
+	}{
+		{"bash", "bash", []string{"_git_sv()", "complete -o bashdefault -o default -F _git_sv_bin git-sv"}, false},
+		{"zsh", "zsh", []string{"#compdef git-sv", "_git-sv()"}, false},
+		{"fish", "fish", []string{"complete -c git-sv", "complete -c git-sv -n '__fish_seen_subcommand_from commit cmt' -f -l type -r -a '(git-sv commit --type --generate-bash-completion 2>/dev/null)'"}, false},
+		{"missing shell", "", nil, true},
+		{"unsupported shell", "powershell", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, stdout := newTestPrinter()
+			err := completionHandler(completers, out)(newCompletionCtx(app, tt.shell))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("completionHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("completionHandler() output = %q, want to contain %q", stdout.String(), want)
+				}
+			}
+			if strings.Contains(stdout.String(), "-l description -r -a") {
+				t.Errorf("completionHandler() output = %q, want no dynamic completion for flags without completer", stdout.String())
+			}
+		})
+	}
+}
+
+func Test_completeFlagValues(t *testing.T) {
+	git := mockGit{
+		tagsAllFn: func() ([]sv.GitTag, error) {
+			return []sv.GitTag{{Name: "v1.0.0", Date: time.Now()}, {Name: "alpha/v1.0.0", Date: time.Now()}, {Name: "beta/v2.0.0", Date: time.Now()}}, nil
+		},
+	}
+	values := map[string]func(c *cli.Context) []string{
+		"type": staticValues([]string{"feat", "fix"}),
+		"t":    tagValues(git),
+	}
+
+	tests := []struct {
+		name    string
+		osArgs  []string
+		ctxArgs []string
+		want    string
+	}{
+		{"static values", []string{"git-sv", "commit", "--type", completionFlag}, nil, "feat\nfix\n"},
+		{"tags", []string{"git-sv", "rn", "-t", completionFlag}, nil, "v1.0.0\nalpha/v1.0.0\nbeta/v2.0.0\n"},
+		{"component tags", []string{"git-sv", "mrn", "--component", "alpha", "-t", completionFlag}, []string{"--component", "alpha"}, "alpha/v1.0.0\n"},
+		{"flags", []string{"git-sv", "commit", "--ty", completionFlag}, nil, "--type\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			osArgs := os.Args
+			os.Args = tt.osArgs
+			defer func() { os.Args = osArgs }()
+
+			var buf bytes.Buffer
+			app := cli.NewApp()
+			app.Writer = &buf
+			ctx := newCompletionCtx(app, tt.ctxArgs...)
+			ctx.Command = &cli.Command{Name: "commit", Flags: []cli.Flag{&cli.StringFlag{Name: "type"}}}
+
+			completeFlagValues(values, tt.osArgs)(ctx)
+			if buf.String() != tt.want {
+				t.Errorf("completeFlagValues() output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_completionValues(t *testing.T) {
+	git := mockGit{tagsAllFn: func() ([]sv.GitTag, error) { return nil, errors.New("not a git repository") }}
+	if got := tagValues(git)(newCompletionCtx(cli.NewApp())); len(got) != 0 {
+		t.Errorf("tagValues() = %v, want no values", got)
+	}
+
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{{Name: "alpha"}, {Name: "beta"}}, nil
+		},
+	}
+	cfg := sv.MonorepoConfig{VersioningFile: "*/package.json"}
+	if got := componentValues(mnrp, cfg, "")(newCompletionCtx(cli.NewApp())); len(got) != 0 {
+		t.Errorf("componentValues() outside repository = %v, want no values", got)
+	}
+	if got, want := componentValues(mnrp, cfg, t.TempDir())(newCompletionCtx(cli.NewApp())), []string{"alpha", "beta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("componentValues() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bvieira/sv4git/v2/sv"
+)
+
+// componentCacheKey identifies a cached read, entries read on another HEAD are not used.
+type componentCacheKey struct {
+	head string
+	read string // versioning file and revision, or log range
+}
+
+// componentCache per-invocation cache of component baselines shared by the steps of a monorepo handler: the versioning file
+// committed on a revision and the logs of components, e.g. the changelog and the next version of a component read the commits
+// since the same tag. Versions on disk are parsed once by FindComponents, see seedOnDisk. Entries are keyed by HEAD hash,
+// call invalidate after HEAD may have changed, e.g. after a commit or a hook, to resolve it again.
+type componentCache struct {
+	git      sv.Git
+	head     string
+	resolved bool
+	versions map[componentCacheKey]*semver.Version
+	logs     map[componentCacheKey][]sv.GitCommitLog
+}
+
+func newComponentCache(git sv.Git) *componentCache {
+	return &componentCache{git: git, versions: make(map[componentCacheKey]*semver.Version), logs: make(map[componentCacheKey][]sv.GitCommitLog)}
+}
+
+// invalidate resolves HEAD again on the next read, entries of the previous HEAD are kept and used if HEAD did not change.
+func (cc *componentCache) invalidate() {
+	cc.resolved = false
+}
+
+// key returns the key of read on current HEAD, false if HEAD can't be resolved, e.g. on a repository without commits, then
+// nothing is cached.
+func (cc *componentCache) key(read string) (componentCacheKey, bool) {
+	if !cc.resolved {
+		head, err := cc.git.ShortHash("HEAD")
+		if err != nil {
+			head = ""
+		}
+		cc.head, cc.resolved = head, true
+	}
+	return componentCacheKey{head: cc.head, read: read}, cc.head != ""
+}
+
+func committedVersionRead(revision, relFile string) string {
+	return revision + ":" + filepath.ToSlash(relFile)
+}
+
+// seedOnDisk caches the versions parsed by FindComponents as the versions committed on HEAD, so they are not read again
+// with git show. Use it only when versioning files have no uncommitted changes, e.g. after checkCleanWorkingTree without --allow-dirty.
+func (cc *componentCache) seedOnDisk(repoPath string, components []sv.MonorepoComponent) {
+	for _, component := range components {
+		relFile, err := filepath.Rel(repoPath, component.VersioningFilePath)
+		if err != nil {
+			continue
+		}
+		if key, ok := cc.key(committedVersionRead("HEAD", relFile)); ok {
+			cc.versions[key] = component.CurrentVersion
+		}
+	}
+}
+
+// committedVersion returns the version of the versioning file relFile committed at revision, see committedComponentVersion.
+func (cc *componentCache) committedVersion(cfg sv.MonorepoConfig, revision, relFile string) (*semver.Version, error) {
+	key, ok := cc.key(committedVersionRead(revision, relFile))
+	if version, found := cc.versions[key]; ok && found {
+		return version, nil
+	}
+	version, err := committedComponentVersion(cc.git, cfg, revision, relFile)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		cc.versions[key] = version
+	}
+	return version, nil
+}
+
+// versionCommits returns the commits of component since componentTags up to ref, see componentVersionCommits.
+func (cc *componentCache) versionCommits(repoPath string, component sv.MonorepoComponent, componentTags []sv.GitTag, ref string, cfg sv.VersioningConfig, out *printer) ([]sv.GitCommitLog, error) {
+	return componentVersionCommits(cachedLogGit{Git: cc.git, cache: cc}, repoPath, component, componentTags, ref, cfg, out)
+}
+
+// componentCommits returns the commits of component since its last tag, see componentCommits.
+func (cc *componentCache) componentCommits(repoPath string, component sv.MonorepoComponent, componentTags []sv.GitTag, withFiles bool, out *printer) ([]sv.GitCommitLog, error) {
+	return componentCommits(cachedLogGit{Git: cc.git, cache: cc}, repoPath, component, componentTags, withFiles, out)
+}
+
+// cachedLogGit reads logs through componentCache, other calls are not cached.
+type cachedLogGit struct {
+	sv.Git
+	cache *componentCache
+}
+
+func (g cachedLogGit) Log(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+	key, ok := g.cache.key(fmt.Sprintf("log %v", lr))
+	if commits, found := g.cache.logs[key]; ok && found {
+		return commits, nil
+	}
+	commits, err := g.Git.Log(lr)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		g.cache.logs[key] = commits
+	}
+	return commits, nil
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/bvieira/sv4git/v2/sv"
 	"github.com/imdario/mergo"
 	"github.com/kelseyhightower/envconfig"
@@ -19,28 +23,32 @@ type EnvConfig struct {
 	Home string `envconfig:"SV4GIT_HOME" default:""`
 }
 
-func loadEnvConfig() EnvConfig {
+func loadEnvConfig() (EnvConfig, error) {
 	var c EnvConfig
-	err := envconfig.Process("", &c)
-	if err != nil {
-		log.Fatal("failed to load env config, error: ", err.Error())
+	if err := envconfig.Process("", &c); err != nil {
+		return EnvConfig{}, fmt.Errorf("failed to load env config, error: %v", err)
 	}
-	return c
+	return c, nil
 }
 
-// Config cli yaml config.
-type Config struct {
-	Version       string                 `yaml:"version"`
-	Versioning    sv.VersioningConfig    `yaml:"versioning"`
-	Tag           sv.TagConfig           `yaml:"tag"`
-	ReleaseNotes  sv.ReleaseNotesConfig  `yaml:"release-notes"`
-	Branches      sv.BranchesConfig      `yaml:"branches"`
-	CommitMessage sv.CommitMessageConfig `yaml:"commit-message"`
-	Monorepo      sv.MonorepoConfig      `yaml:"monorepo"`
+// Config cli yaml config, defined on sv package to be shared with library users.
+type Config = sv.Config
+
+// getRepoPath returns the repository top level directory, or the git directory of bare repositories, e.g. on server hooks.
+func getRepoPath(dir string) (string, error) {
+	path, err := revParse(dir, "--show-toplevel")
+	if err == nil {
+		return path, nil
+	}
+	if bare, berr := revParse(dir, "--is-bare-repository"); berr == nil && bare == "true" {
+		return revParse(dir, "--absolute-git-dir")
+	}
+	return "", err
 }
 
-func getRepoPath() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+func revParse(dir, arg string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", arg)
+	cmd.Dir = dir
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", combinedOutputErr(err, out)
@@ -48,68 +56,230 @@ func getRepoPath() (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// repoDirFromArgs returns the value of -C/--repo-dir global flag, it's parsed before cli app
+// because configuration and processors depend on repository path.
+func repoDirFromArgs(args []string) string {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return ""
+		}
+		for _, name := range []string{"-C", "--repo-dir", "-repo-dir"} {
+			if arg == name && i+1 < len(args) {
+				return args[i+1]
+			}
+			if strings.HasPrefix(arg, name+"=") {
+				return strings.TrimPrefix(arg, name+"=")
+			}
+		}
+	}
+	return ""
+}
+
 func combinedOutputErr(err error, out []byte) error {
 	msg := strings.Split(string(out), "\n")
 	return fmt.Errorf("%v - %s", err, msg[0])
 }
 
+// Config file formats, detected by file extension.
+const (
+	configFormatYAML = "yaml"
+	configFormatJSON = "json"
+	configFormatTOML = "toml"
+)
+
+// configExtensions supported config file extensions in lookup order.
+var configExtensions = []string{".yml", ".yaml", ".json", ".toml"}
+
+// findConfigFile returns the first existing config file on dir with name and a supported extension,
+// returns the yml file path if none exists.
+func findConfigFile(dir, name string) string {
+	path, _ := lookupConfigFile(osFS{}, dir, name)
+	return path
+}
+
+// lookupConfigFile returns the first config file on fsys, see findConfigFile, and if it exists.
+func lookupConfigFile(fsys doctorFS, dir, name string) (string, bool) {
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := fsys.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return filepath.Join(dir, name+configExtensions[0]), false
+}
+
+func configFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yml", ".yaml":
+		return configFormatYAML, nil
+	case ".json":
+		return configFormatJSON, nil
+	case ".toml":
+		return configFormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported config file extension: %s", ext)
+	}
+}
+
 func readConfig(filepath string) (Config, error) {
 	content, rerr := os.ReadFile(filepath)
 	if rerr != nil {
 		return Config{}, rerr
 	}
 
-	var cfg Config
-	cerr := yaml.Unmarshal(content, &cfg)
+	format, ferr := configFormat(filepath)
+	if ferr != nil {
+		return Config{}, ferr
+	}
+	cfg, cerr := decodeConfig(content, format)
 	if cerr != nil {
 		return Config{}, fmt.Errorf("could not parse config from path: %s, error: %v", filepath, cerr)
 	}
+	return cfg, nil
+}
+
+// decodeConfig decodes content using yaml struct tags for every format, so field names and unknown fields handling are the same.
+// JSON and TOML are decoded by their own parsers and converted to yaml before decoding, e.g. JSON escapes like \/ are not
+// valid yaml.
+func decodeConfig(content []byte, format string) (Config, error) {
+	var values interface{}
+	switch format {
+	case configFormatYAML:
+	case configFormatJSON:
+		if err := json.Unmarshal(content, &values); err != nil {
+			return Config{}, err
+		}
+	case configFormatTOML:
+		tables := make(map[string]interface{})
+		if err := toml.Unmarshal(content, &tables); err != nil {
+			return Config{}, err
+		}
+		values = tables
+	default:
+		return Config{}, fmt.Errorf("unsupported config format: %s", format)
+	}
+	if values != nil {
+		var err error
+		if content, err = yaml.Marshal(values); err != nil {
+			return Config{}, err
+		}
+	}
 
+	var cfg Config
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return Config{}, err
+	}
 	return cfg, nil
 }
 
-func defaultConfig() Config {
-	skipDetached := false
-	pattern := "%d.%d.%d"
-	filter := ""
-	return Config{
-		Version: "1.1",
-		Versioning: sv.VersioningConfig{
-			UpdateMajor:   []string{},
-			UpdateMinor:   []string{"feat"},
-			UpdatePatch:   []string{"build", "ci", "chore", "docs", "fix", "perf", "refactor", "style", "test"},
-			IgnoreUnknown: false,
-		},
-		Tag: sv.TagConfig{
-			Pattern: &pattern,
-			Filter:  &filter,
-		},
-		ReleaseNotes: sv.ReleaseNotesConfig{
-			Sections: []sv.ReleaseNotesSectionConfig{
-				{Name: "Features", SectionType: sv.ReleaseNotesSectionTypeCommits, CommitTypes: []string{"feat"}},
-				{Name: "Bug Fixes", SectionType: sv.ReleaseNotesSectionTypeCommits, CommitTypes: []string{"fix"}},
-				{Name: "Breaking Changes", SectionType: sv.ReleaseNotesSectionTypeBreakingChanges},
-			},
-		},
-		Branches: sv.BranchesConfig{
-			Prefix:       "([a-z]+\\/)?",
-			Suffix:       "(-.*)?",
-			DisableIssue: false,
-			Skip:         []string{"master", "main", "developer"},
-			SkipDetached: &skipDetached,
-		},
-		CommitMessage: sv.CommitMessageConfig{
-			Types: []string{"build", "ci", "chore", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"},
-			Scope: sv.CommitMessageScopeConfig{},
-			Footer: map[string]sv.CommitMessageFooterConfig{
-				"issue": {Key: "jira", KeySynonyms: []string{"Jira", "JIRA"}},
-			},
-			Issue:          sv.CommitMessageIssueConfig{Regex: "[A-Z]+-[0-9]+"},
-			HeaderSelector: "",
-		},
+// encodeConfig encodes cfg on format with the same field names for every format, yaml and json keep the field order,
+// toml keys are sorted by github.com/BurntSushi/toml. Null values are omitted on toml.
+func encodeConfig(cfg Config, format string) (string, error) {
+	content, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return "", err
+	}
+	if format == configFormatYAML {
+		return string(content), nil
+	}
+
+	switch format {
+	case configFormatJSON:
+		var node yaml.Node
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			return "", err
+		}
+		var sb strings.Builder
+		if err := encodeJSONNode(&sb, &node); err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(sb.String()), "", "  "); err != nil {
+			return "", err
+		}
+		return buf.String() + "\n", nil
+	case configFormatTOML:
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		encoder := toml.NewEncoder(&buf)
+		encoder.Indent = ""
+		if err := encoder.Encode(values); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported config format: %s, use one of: %s, %s, %s", format, configFormatYAML, configFormatJSON, configFormatTOML)
 	}
 }
 
+// encodeJSONNode writes a yaml node as compact JSON keeping mapping keys order.
+func encodeJSONNode(sb *strings.Builder, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			sb.WriteString("null")
+			return nil
+		}
+		return encodeJSONNode(sb, node.Content[0])
+	case yaml.MappingNode:
+		sb.WriteString("{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			sb.Write(key)
+			sb.WriteString(":")
+			if err := encodeJSONNode(sb, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		sb.WriteString("}")
+	case yaml.SequenceNode:
+		sb.WriteString("[")
+		for i, item := range node.Content {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			if err := encodeJSONNode(sb, item); err != nil {
+				return err
+			}
+		}
+		sb.WriteString("]")
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			sb.WriteString("null")
+		case "!!bool", "!!int":
+			sb.WriteString(node.Value)
+		case "!!float":
+			f, err := strconv.ParseFloat(node.Value, 64)
+			if err != nil {
+				return fmt.Errorf("json: invalid float %s", node.Value)
+			}
+			value, err := json.Marshal(f)
+			if err != nil {
+				return err
+			}
+			sb.Write(value)
+		default:
+			value, _ := json.Marshal(node.Value)
+			sb.Write(value)
+		}
+	default:
+		return fmt.Errorf("json: unsupported value %s", node.Value)
+	}
+	return nil
+}
+
+func defaultConfig() Config {
+	return sv.NewDefaultConfig()
+}
+
 func merge(dst *Config, src Config) error {
 	err := mergo.Merge(dst, src, mergo.WithOverride, mergo.WithTransformers(&mergeTransformer{}))
 	if err == nil {
@@ -143,22 +313,31 @@ func (t *mergeTransformer) Transformer(typ reflect.Type) func(dst, src reflect.V
 	return nil
 }
 
-func migrateConfig(cfg Config, filename string) Config {
+// checkConfigWarnings prints config warnings on stderr, with strict they are returned as an error instead.
+func checkConfigWarnings(cfg Config, strict bool, out *printer) error {
+	warnings := append(cfg.Versioning.BumpTypeWarnings(cfg.CommitMessage.Types), cfg.TypeSettingsWarnings()...)
+	if len(warnings) == 0 {
+		return nil
+	}
+	if strict {
+		return fmt.Errorf("invalid config with --strict-config:\n  %s", strings.Join(warnings, "\n  "))
+	}
+	for _, warning := range warnings {
+		out.warnf("config: %s", warning)
+	}
+	return nil
+}
+
+func migrateConfig(cfg Config, filename string, out *printer) Config {
 	if cfg.ReleaseNotes.Headers == nil {
 		return cfg
 	}
-	warnf("config 'release-notes.headers' on %s is deprecated, please use 'sections' instead!", filename)
+	out.warnf("config 'release-notes.headers' on %s is deprecated, please use 'sections' instead!", filename)
 
-	return Config{
-		Version:    cfg.Version,
-		Versioning: cfg.Versioning,
-		Tag:        cfg.Tag,
-		ReleaseNotes: sv.ReleaseNotesConfig{
-			Sections: migrateReleaseNotesConfig(cfg.ReleaseNotes.Headers),
-		},
-		Branches:      cfg.Branches,
-		CommitMessage: cfg.CommitMessage,
-	}
+	migrated := cfg
+	migrated.ReleaseNotes.Sections = migrateReleaseNotesConfig(cfg.ReleaseNotes.Headers)
+	migrated.ReleaseNotes.Headers = nil
+	return migrated
 }
 
 func migrateReleaseNotesConfig(headers map[string]string) []sv.ReleaseNotesSectionConfig {
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/bvieira/sv4git/v2/sv"
@@ -50,3 +55,222 @@ func Test_merge(t *testing.T) {
 		})
 	}
 }
+
+func Test_encodeConfig_decodeConfig(t *testing.T) {
+	cfg := defaultConfig()
+	want, err := decodeConfig([]byte(mustEncodeConfig(t, cfg, configFormatYAML)), configFormatYAML)
+	if err != nil {
+		t.Fatalf("decodeConfig() yaml error = %v", err)
+	}
+	for _, format := range []string{configFormatJSON, configFormatTOML} {
+		t.Run(format, func(t *testing.T) {
+			got, err := decodeConfig([]byte(mustEncodeConfig(t, cfg, format)), format)
+			if err != nil {
+				t.Fatalf("decodeConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decodeConfig() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func Test_encodeConfig_TOML(t *testing.T) {
+	pattern := "v%d.%d.%d"
+	cfg := Config{Version: "1.1", Tag: sv.TagConfig{Pattern: &pattern}, Hooks: sv.HooksConfig{PreTag: []string{"make test"}}}
+	got := mustEncodeConfig(t, cfg, configFormatTOML)
+	for _, want := range []string{"version = \"1.1\"\n", "[tag]\npattern = \"v%d.%d.%d\"\n", "[hooks]\npre-tag = [\"make test\"]\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("encodeConfig() toml = %q, want %q", got, want)
+		}
+	}
+	if strings.Contains(got, "filter") {
+		t.Errorf("encodeConfig() toml = %q, want null tag.filter omitted", got)
+	}
+}
+
+func Test_decodeConfig(t *testing.T) {
+	yamlContent := `version: "1.1"
+unknown-field: true
+tag:
+  pattern: "v%d.%d.%d"
+release-notes:
+  sections:
+    - name: Features
+      section-type: commits
+      commit-types: [feat]
+commit-message:
+  scope:
+    values: [api, "core"]
+    required: true
+`
+	jsonContent := `{
+  "version": "1.1",
+  "unknown-field": true,
+  "tag": {"pattern": "v%d.%d.%d"},
+  "release-notes": {"sections": [{"name": "Features", "section-type": "commits", "commit-types": ["feat"]}]},
+  "commit-message": {"scope": {"values": ["api", "core"], "required": true}}
+}`
+	tomlContent := `# sv4git config
+version = "1.1"
+unknown-field = true
+tag.pattern = 'v%d.%d.%d'
+
+[[release-notes.sections]]
+name = "Features"
+section-type = "commits"
+commit-types = ["feat"]
+
+[commit-message]
+scope = { values = [
+  "api",
+  "core", # trailing comma
+], required = true }
+`
+	tagPattern := "v%d.%d.%d"
+	want := Config{
+		Version:       "1.1",
+		Tag:           sv.TagConfig{Pattern: &tagPattern},
+		ReleaseNotes:  sv.ReleaseNotesConfig{Sections: []sv.ReleaseNotesSectionConfig{{Name: "Features", SectionType: "commits", CommitTypes: []string{"feat"}}}},
+		CommitMessage: sv.CommitMessageConfig{Scope: sv.CommitMessageScopeConfig{Values: []string{"api", "core"}, Required: true}},
+	}
+
+	tests := []struct {
+		format  string
+		content string
+	}{
+		{configFormatYAML, yamlContent},
+		{configFormatJSON, jsonContent},
+		{configFormatTOML, tomlContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := decodeConfig([]byte(tt.content), tt.format)
+			if err != nil {
+				t.Fatalf("decodeConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decodeConfig() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func Test_decodeConfig_FormatSyntax(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		want    string
+	}{
+		{"json escaped slash", configFormatJSON, `{"tag": {"pattern": "release\/%d.%d.%d"}}`, "release/%d.%d.%d"},
+		{"json unicode escape", configFormatJSON, `{"tag": {"pattern": "\u0076%d.%d.%d"}}`, "v%d.%d.%d"},
+		{"toml dates", configFormatTOML, "updated = 2024-06-01T10:00:00Z\nreviewed = 2024-06-01\n[tag]\npattern = 'v%d.%d.%d'\n", "v%d.%d.%d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeConfig([]byte(tt.content), tt.format)
+			if err != nil {
+				t.Fatalf("decodeConfig() error = %v", err)
+			}
+			if got.Tag.Pattern == nil || *got.Tag.Pattern != tt.want {
+				t.Errorf("decodeConfig() tag pattern = %v, want %s", got.Tag.Pattern, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeConfig_InvalidContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+	}{
+		{"json", configFormatJSON, `{"version": "1.1",`},
+		{"toml unterminated string", configFormatTOML, `version = "1.1`},
+		{"toml duplicated key", configFormatTOML, "version = \"1.1\"\nversion = \"1.0\""},
+		{"toml duplicated table", configFormatTOML, "[tag]\n[tag]"},
+		{"toml missing value", configFormatTOML, "version ="},
+		{"unsupported format", "ini", "version=1.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeConfig([]byte(tt.content), tt.format); err == nil {
+				t.Errorf("decodeConfig() expected error, got nil")
+			}
+		})
+	}
+}
+
+func Test_findConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	if got, want := findConfigFile(dir, ".sv4git"), filepath.Join(dir, ".sv4git.yml"); got != want {
+		t.Errorf("findConfigFile() = %s, want %s", got, want)
+	}
+	for _, name := range []string{".sv4git.toml", ".sv4git.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte{}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := findConfigFile(dir, ".sv4git"), filepath.Join(dir, ".sv4git.json"); got != want {
+		t.Errorf("findConfigFile() = %s, want %s", got, want)
+	}
+}
+
+func mustEncodeConfig(t *testing.T, cfg Config, format string) string {
+	t.Helper()
+	content, err := encodeConfig(cfg, format)
+	if err != nil {
+		t.Fatalf("encodeConfig() %s error = %v", format, err)
+	}
+	return content
+}
+
+func Test_checkConfigWarnings(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Versioning.UpdateMinor = []string{"feature"}
+
+	var stderr bytes.Buffer
+	out := newPrinter(io.Discard, &stderr)
+	if err := checkConfigWarnings(cfg, false, out); err != nil {
+		t.Fatalf("checkConfigWarnings() unexpected error: %v", err)
+	}
+	if want := "type feature on versioning.update-minor is not on commit-message.types"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("checkConfigWarnings() stderr = %q, want %q", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	if err := checkConfigWarnings(cfg, true, out); err == nil || !strings.Contains(err.Error(), "feature") {
+		t.Errorf("checkConfigWarnings() strict error = %v, want error with feature type", err)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("checkConfigWarnings() strict stderr = %q, want empty", stderr.String())
+	}
+
+	if err := checkConfigWarnings(defaultConfig(), true, out); err != nil {
+		t.Errorf("checkConfigWarnings() default config error = %v", err)
+	}
+}
+
+func Test_migrateConfig(t *testing.T) {
+	cfg := Config{
+		Version:      "1.1",
+		ReleaseNotes: sv.ReleaseNotesConfig{Headers: map[string]string{"feat": "Features"}, Locale: "de"},
+		Monorepo:     sv.MonorepoConfig{VersioningFile: "*/package.json"},
+		Log:          sv.LogConfig{FirstParent: true},
+		Hooks:        sv.HooksConfig{PreTag: []string{"make test"}},
+	}
+
+	var stderr bytes.Buffer
+	got := migrateConfig(cfg, ".sv4git.yml", newPrinter(io.Discard, &stderr))
+
+	want := cfg
+	want.ReleaseNotes.Headers = nil
+	want.ReleaseNotes.Sections = []sv.ReleaseNotesSectionConfig{{Name: "Features", SectionType: sv.ReleaseNotesSectionTypeCommits, CommitTypes: []string{"feat"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("migrateConfig() = %+v, want %+v", got, want)
+	}
+	if !strings.Contains(stderr.String(), "'release-notes.headers' on .sv4git.yml is deprecated") {
+		t.Errorf("migrateConfig() stderr = %q, want deprecation warning", stderr.String())
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// status of doctor checks, only failures make doctor fail.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// minGitVersion oldest git version supported, see README requirements.
+var minGitVersion = [2]int{2, 17}
+
+// doctorCheck result of a doctor check, hint tells how to fix warnings and failures.
+type doctorCheck struct {
+	name    string
+	status  string
+	message string
+	hint    string
+}
+
+// doctorFS file system read by doctor checks, replaced on tests.
+type doctorFS interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+}
+
+type osFS struct{}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+func (osFS) ReadFile(name string) ([]byte, error)  { return os.ReadFile(name) }
+
+// doctorEnv state of git-sv setup checked by doctor: doctor also runs outside repositories and with an invalid config,
+// then repoErr or configErr are set instead of failing before the command, and config is the default one.
+type doctorEnv struct {
+	repoPath  string
+	repoErr   error
+	home      string // SV4GIT_HOME, directory of the user config
+	configErr error
+	fsys      doctorFS
+}
+
+// doctorRequested checks if doctor is the command, values of global flags are skipped, e.g. -C path.
+func doctorRequested(args []string) bool {
+	for i := 1; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-C" || arg == "--repo-dir" || arg == "-repo-dir" || arg == "--release-date" || arg == "-release-date":
+			i++
+		case !strings.HasPrefix(arg, "-"):
+			return arg == "doctor"
+		}
+	}
+	return false
+}
+
+// doctorHandler prints the result of each check with a hint for warnings and failures, it fails only if a check failed.
+// Checks using the repository are skipped outside repositories.
+func doctorHandler(git sv.Git, messageProcessor sv.MessageProcessor, monorepoProcessor sv.MonorepoProcessor, cfg Config, env doctorEnv, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		checks := []doctorCheck{
+			checkGitVersion(git),
+			checkRepository(env.repoPath, env.repoErr),
+			checkConfigFiles(env.fsys, env.home, env.repoPath, env.configErr),
+		}
+		if env.repoErr == nil {
+			checks = append(checks,
+				checkTags(git, cfg.Monorepo.VersioningFile != ""),
+				checkShallow(git),
+				checkBranch(git, messageProcessor),
+				checkHook(git, env.fsys),
+				checkCommitMessage(git, messageProcessor, cfg.CommitMessage),
+				checkMonorepo(monorepoProcessor, env.repoPath, cfg.Monorepo),
+			)
+		}
+
+		counts := make(map[string]int)
+		for _, check := range checks {
+			counts[check.status]++
+			printCheck(out, check)
+		}
+		out.statusf("%d passed, %d warning(s), %d failure(s)", counts[checkPass], counts[checkWarn], counts[checkFail])
+		if counts[checkFail] > 0 {
+			return fmt.Errorf("%d doctor check(s) failed", counts[checkFail])
+		}
+		return nil
+	}
+}
+
+func printCheck(out *printer, check doctorCheck) {
+	color := map[string]string{checkPass: colorGreen, checkWarn: colorYellow, checkFail: colorRed}[check.status]
+	out.printf("%s %s: %s\n", colorize(out.stdoutColor, color, strings.ToUpper(check.status)), check.name, check.message)
+	if check.hint != "" && check.status != checkPass {
+		out.printf("     hint: %s\n", check.hint)
+	}
+}
+
+var gitVersionRegex = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+// checkGitVersion fails if git is not found or older than minGitVersion.
+func checkGitVersion(git sv.Git) doctorCheck {
+	check := doctorCheck{name: "git"}
+	version, err := git.Version()
+	if err != nil {
+		check.status, check.message, check.hint = checkFail, fmt.Sprintf("git not found: %v", err), "install git and add it to PATH"
+		return check
+	}
+	match := gitVersionRegex.FindStringSubmatch(version)
+	if match == nil {
+		check.status, check.message = checkWarn, fmt.Sprintf("unknown git version %q", version)
+		check.hint = fmt.Sprintf("git %d.%d or newer is required", minGitVersion[0], minGitVersion[1])
+		return check
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	if major < minGitVersion[0] || (major == minGitVersion[0] && minor < minGitVersion[1]) {
+		check.status, check.message = checkFail, fmt.Sprintf("git %s is not supported", version)
+		check.hint = fmt.Sprintf("upgrade git to %d.%d or newer", minGitVersion[0], minGitVersion[1])
+		return check
+	}
+	check.status, check.message = checkPass, "version "+version
+	return check
+}
+
+// checkRepository fails outside git repositories.
+func checkRepository(repoPath string, repoErr error) doctorCheck {
+	if repoErr != nil {
+		return doctorCheck{name: "repository", status: checkFail, message: fmt.Sprintf("no git repository found: %v", repoErr), hint: "run git sv inside a repository or use -C path"}
+	}
+	return doctorCheck{name: "repository", status: checkPass, message: repoPath}
+}
+
+// checkConfigFiles reports the user and repository config files in use, it fails if they could not be read or are invalid.
+func checkConfigFiles(fsys doctorFS, home, repoPath string, configErr error) doctorCheck {
+	check := doctorCheck{name: "config"}
+	if configErr != nil {
+		check.status, check.message, check.hint = checkFail, configErr.Error(), "fix the config, use git sv config default to compare with the default config"
+		return check
+	}
+
+	var found []string
+	if home != "" {
+		if path, ok := lookupConfigFile(fsys, home, configFilename); ok {
+			found = append(found, "user config "+path)
+		}
+	}
+	if repoPath != "" {
+		if path, ok := lookupConfigFile(fsys, repoPath, repoConfigFilename); ok {
+			found = append(found, "repository config "+path)
+		}
+	}
+	if len(found) == 0 {
+		check.status, check.message = checkWarn, "no config file found, using default config"
+		check.hint = fmt.Sprintf("create %s on repository root, e.g. with git sv config default > %s", repoConfigFilename+configExtensions[0], repoConfigFilename+configExtensions[0])
+		return check
+	}
+	check.status, check.message = checkPass, strings.Join(found, ", ")
+	return check
+}
+
+// checkTags reports the number of release tags and the last one, every tag on monorepos since components have their own tags.
+// A repository without tags is a warning, tags may not be fetched.
+func checkTags(git sv.Git, monorepo bool) doctorCheck {
+	check := doctorCheck{name: "tags"}
+	list := git.Tags
+	if monorepo {
+		list = git.TagsAll
+	}
+	tags, err := list()
+	if err != nil {
+		check.status, check.message = checkFail, fmt.Sprintf("error listing tags: %v", err)
+		return check
+	}
+	if len(tags) == 0 {
+		check.status, check.message = checkWarn, "no release tags found, versions are calculated from all commits"
+		check.hint = "if tags exist on remote, fetch them with git fetch --tags or use --fetch"
+		return check
+	}
+	check.status, check.message = checkPass, fmt.Sprintf("%d release tag(s), last tag %s", len(tags), sv.LatestTag(tags))
+	return check
+}
+
+// checkShallow warns on shallow clones, e.g. CI checkouts, commits since the last tag may be missing.
+func checkShallow(git sv.Git) doctorCheck {
+	check := doctorCheck{name: "history"}
+	shallow, err := git.IsShallow()
+	switch {
+	case err != nil:
+		check.status, check.message = checkWarn, fmt.Sprintf("could not check if repository is a shallow clone: %v", err)
+	case shallow:
+		check.status, check.message = checkWarn, "repository is a shallow clone, commits and tags may be missing"
+		check.hint = "use --fetch or git fetch --tags --unshallow, on CI fetch the complete history, e.g. fetch-depth: 0"
+	default:
+		check.status, check.message = checkPass, "complete history"
+	}
+	return check
+}
+
+// checkBranch reports if commit messages are validated and enhanced on the current branch, see branches.skip.
+func checkBranch(git sv.Git, messageProcessor sv.MessageProcessor) doctorCheck {
+	branch := git.Branch()
+	detached, err := git.IsDetached()
+	if err != nil {
+		return doctorCheck{name: "branch", status: checkWarn, message: fmt.Sprintf("could not check if HEAD is detached: %v", err)}
+	}
+	name := branch
+	if detached {
+		name = "detached HEAD"
+	}
+	if messageProcessor.SkipBranch(branch, detached) {
+		return doctorCheck{name: "branch", status: checkPass, message: fmt.Sprintf("%s is skipped by branches config, commit messages are not validated on it", name)}
+	}
+	return doctorCheck{name: "branch", status: checkPass, message: fmt.Sprintf("%s, commit messages are validated", name)}
+}
+
+// commitMessageHooks git hooks validate-commit-message can be installed on, see README.
+var commitMessageHooks = []string{"prepare-commit-msg", "commit-msg"}
+
+// checkHook warns if no commit message hook runs validate-commit-message.
+func checkHook(git sv.Git, fsys doctorFS) doctorCheck {
+	check := doctorCheck{name: "hook", hint: "add git sv validate-commit-message to prepare-commit-msg hook, see README"}
+	dir, err := git.HooksPath()
+	if err != nil {
+		check.status, check.message = checkWarn, fmt.Sprintf("could not find hooks directory: %v", err)
+		return check
+	}
+	var other []string
+	for _, name := range commitMessageHooks {
+		path := filepath.Join(dir, name)
+		content, err := fsys.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(string(content), "validate-commit-message") && !strings.Contains(string(content), "vcm") {
+			other = append(other, path)
+			continue
+		}
+		if info, err := fsys.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+			check.status, check.message, check.hint = checkWarn, fmt.Sprintf("%s is not executable, git does not run it", path), "chmod +x "+path
+			return check
+		}
+		check.status, check.message = checkPass, fmt.Sprintf("%s runs git sv validate-commit-message", path)
+		return check
+	}
+	if len(other) > 0 {
+		check.status, check.message = checkWarn, fmt.Sprintf("%s does not run git sv validate-commit-message", strings.Join(other, ", "))
+		check.hint = "check the hook, or the config of hook runners like lefthook or husky, runs git sv validate-commit-message"
+		return check
+	}
+	check.status, check.message = checkWarn, fmt.Sprintf("no commit message hook installed on %s", dir)
+	return check
+}
+
+// checkCommitMessage validates a sample message, enhanced as validate-commit-message does on the current branch, to find
+// commit-message configs rejecting every commit, e.g. required footers missing from branch names.
+func checkCommitMessage(git sv.Git, messageProcessor sv.MessageProcessor, cfg sv.CommitMessageConfig) doctorCheck {
+	check := doctorCheck{name: "commit message"}
+	if cfg.ValidationMode == sv.ValidationModeOff {
+		check.status, check.message = checkPass, "validation disabled by commit-message.validation-mode off"
+		return check
+	}
+
+	header := "feat"
+	if !containsString(cfg.Types, header) && len(cfg.Types) > 0 {
+		header = cfg.Types[0]
+	}
+	if len(cfg.Scope.Values) > 0 {
+		header += "(" + cfg.Scope.Values[0] + ")"
+	}
+	message := header + ": check commit message config"
+	if branch := git.Branch(); !messageProcessor.SkipBranch(branch, false) {
+		if enhanced, err := messageProcessor.Enhance(branch, message); err == nil {
+			message = enhanced
+		}
+	}
+
+	violations := messageProcessor.Violations(message)
+	if len(violations) == 0 {
+		check.status, check.message = checkPass, fmt.Sprintf("sample message %q is valid", header+": ...")
+		return check
+	}
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.Error())
+	}
+	check.status, check.message = checkWarn, fmt.Sprintf("sample message %q is rejected: %s", header+": ...", strings.Join(messages, "; "))
+	check.hint = "check commit-message config, e.g. required-footers and issue regex against branch names"
+	return check
+}
+
+// checkMonorepo reports the components found by monorepo config, it warns if none is found.
+func checkMonorepo(monorepoProcessor sv.MonorepoProcessor, repoPath string, cfg sv.MonorepoConfig) doctorCheck {
+	check := doctorCheck{name: "monorepo"}
+	if cfg.VersioningFile == "" {
+		check.status, check.message = checkPass, "not configured"
+		return check
+	}
+	components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg)
+	switch {
+	case err != nil:
+		check.status, check.message, check.hint = checkFail, fmt.Sprintf("error finding components: %v", err), "check monorepo config"
+	case len(components) == 0 && len(skipped) == 0:
+		check.status, check.message = checkWarn, fmt.Sprintf("no component found with versioning file %s", cfg.VersioningFile)
+		check.hint = "check monorepo.versioning-file and monorepo.exclude config"
+	case len(skipped) > 0:
+		messages := make([]string, 0, len(skipped))
+		for _, skip := range skipped {
+			messages = append(messages, skip.Error())
+		}
+		check.status, check.message = checkWarn, fmt.Sprintf("%d component(s) found, %d skipped due to invalid versioning files: %s", len(components), len(skipped), strings.Join(messages, "; "))
+		check.hint = "fix the versioning files or exclude their directories with monorepo.exclude config"
+	default:
+		check.status, check.message = checkPass, fmt.Sprintf("%d component(s) found", len(components))
+	}
+	return check
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// mapFS doctorFS of absolute paths to file contents, files are executable unless listed on noExec.
+type mapFS struct {
+	files  map[string]string
+	noExec map[string]bool
+}
+
+func (m mapFS) Stat(name string) (fs.FileInfo, error) {
+	content, found := m.files[name]
+	if !found {
+		return nil, fs.ErrNotExist
+	}
+	mode := fs.FileMode(0755)
+	if m.noExec[name] {
+		mode = 0644
+	}
+	return fstest.MapFS{"file": {Data: []byte(content), Mode: mode}}.Stat("file")
+}
+
+func (m mapFS) ReadFile(name string) ([]byte, error) {
+	content, found := m.files[name]
+	if !found {
+		return nil, fs.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func Test_doctorRequested(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"git-sv", "doctor"}, true},
+		{[]string{"git-sv", "--no-color", "doctor"}, true},
+		{[]string{"git-sv", "-C", "doctor", "next-version"}, false},
+		{[]string{"git-sv", "-C", "repo", "doctor"}, true},
+		{[]string{"git-sv", "--repo-dir=repo", "doctor"}, true},
+		{[]string{"git-sv", "next-version", "doctor"}, false},
+		{[]string{"git-sv"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := doctorRequested(tt.args); got != tt.want {
+				t.Errorf("doctorRequested() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkGitVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		err        error
+		wantStatus string
+	}{
+		{"supported", "2.39.2", nil, checkPass},
+		{"minimum", "2.17.0", nil, checkPass},
+		{"windows build", "2.45.1.windows.1", nil, checkPass},
+		{"too old", "2.16.6", nil, checkFail},
+		{"unknown", "dev", nil, checkWarn},
+		{"not found", "", errors.New("executable file not found"), checkFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{versionFn: func() (string, error) { return tt.version, tt.err }}
+			if got := checkGitVersion(git); got.status != tt.wantStatus {
+				t.Errorf("checkGitVersion() = %+v, want status %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_checkConfigFiles(t *testing.T) {
+	home, repo := filepath.FromSlash("/home/user/.sv4git"), filepath.FromSlash("/repo")
+	tests := []struct {
+		name        string
+		files       []string
+		configErr   error
+		wantStatus  string
+		wantMessage string
+	}{
+		{"no config", nil, nil, checkWarn, "no config file found"},
+		{"repository config", []string{filepath.Join(repo, ".sv4git.yaml")}, nil, checkPass, "repository config " + filepath.Join(repo, ".sv4git.yaml")},
+		{"user and repository config", []string{filepath.Join(home, "config.toml"), filepath.Join(repo, ".sv4git.yml")}, nil, checkPass, "user config " + filepath.Join(home, "config.toml") + ", repository config"},
+		{"invalid config", []string{filepath.Join(repo, ".sv4git.yml")}, errors.New("invalid monorepo config"), checkFail, "invalid monorepo config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := mapFS{files: map[string]string{}}
+			for _, file := range tt.files {
+				fsys.files[file] = ""
+			}
+			got := checkConfigFiles(fsys, home, repo, tt.configErr)
+			if got.status != tt.wantStatus || !strings.Contains(got.message, tt.wantMessage) {
+				t.Errorf("checkConfigFiles() = %+v, want status %s and message %q", got, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_checkTags(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tags := []sv.GitTag{{Name: "v1.0.0", Date: older}, {Name: "v1.1.0", Date: older.AddDate(0, 1, 0)}}
+	tests := []struct {
+		name        string
+		tags        []sv.GitTag
+		allTags     []sv.GitTag
+		monorepo    bool
+		wantStatus  string
+		wantMessage string
+	}{
+		{"release tags", tags, nil, false, checkPass, "2 release tag(s), last tag v1.1.0"},
+		{"no tags", nil, nil, false, checkWarn, "no release tags found"},
+		{"monorepo component tags", nil, []sv.GitTag{{Name: "api/v1.0.0"}}, true, checkPass, "1 release tag(s), last tag api/v1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{
+				tagsFn:    func() ([]sv.GitTag, error) { return tt.tags, nil },
+				tagsAllFn: func() ([]sv.GitTag, error) { return tt.allTags, nil },
+			}
+			got := checkTags(git, tt.monorepo)
+			if got.status != tt.wantStatus || !strings.Contains(got.message, tt.wantMessage) {
+				t.Errorf("checkTags() = %+v, want status %s and message %q", got, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_checkShallow(t *testing.T) {
+	tests := []struct {
+		name       string
+		shallow    bool
+		err        error
+		wantStatus string
+	}{
+		{"complete", false, nil, checkPass},
+		{"shallow", true, nil, checkWarn},
+		{"error", false, errors.New("fatal"), checkWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{isShallowFn: func() (bool, error) { return tt.shallow, tt.err }}
+			if got := checkShallow(git); got.status != tt.wantStatus {
+				t.Errorf("checkShallow() = %+v, want status %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_checkBranch(t *testing.T) {
+	cfg := defaultConfig()
+	tests := []struct {
+		branch      string
+		detached    bool
+		wantMessage string
+	}{
+		{"feature/JIRA-1-login", false, "feature/JIRA-1-login, commit messages are validated"},
+		{"main", false, "main is skipped by branches config"},
+		{"", true, "detached HEAD, commit messages are validated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			git := mockGit{branch: tt.branch, isDetachedFn: func() (bool, error) { return tt.detached, nil }}
+			got := checkBranch(git, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches))
+			if got.status != checkPass || !strings.Contains(got.message, tt.wantMessage) {
+				t.Errorf("checkBranch() = %+v, want message %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_checkHook(t *testing.T) {
+	hooks := filepath.FromSlash("/repo/.git/hooks")
+	prepare, commitMsg := filepath.Join(hooks, "prepare-commit-msg"), filepath.Join(hooks, "commit-msg")
+	tests := []struct {
+		name        string
+		files       map[string]string
+		noExec      map[string]bool
+		hooksErr    error
+		wantStatus  string
+		wantMessage string
+	}{
+		{"prepare-commit-msg", map[string]string{prepare: "#!/bin/sh\ngit sv vcm --path \"$(pwd)\" --file \"$1\" --source \"$2\"\n"}, nil, nil, checkPass, prepare + " runs git sv"},
+		{"commit-msg", map[string]string{commitMsg: "#!/bin/sh\ngit-sv validate-commit-message --path . --file $1 --source message\n"}, nil, nil, checkPass, commitMsg + " runs git sv"},
+		{"other hook", map[string]string{prepare: "#!/bin/sh\nlefthook run prepare-commit-msg\n"}, nil, nil, checkWarn, prepare + " does not run git sv"},
+		{"no hook", nil, nil, nil, checkWarn, "no commit message hook installed"},
+		{"hooks directory error", nil, nil, errors.New("not a git repository"), checkWarn, "could not find hooks directory"},
+		{"not executable", map[string]string{prepare: "#!/bin/sh\ngit sv vcm\n"}, map[string]bool{prepare: true}, nil, checkWarn, "is not executable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.noExec != nil && runtime.GOOS == "windows" {
+				t.Skip("executable bit is not used on windows")
+			}
+			git := mockGit{hooksPathFn: func() (string, error) { return hooks, tt.hooksErr }}
+			got := checkHook(git, mapFS{files: tt.files, noExec: tt.noExec})
+			if got.status != tt.wantStatus || !strings.Contains(got.message, tt.wantMessage) {
+				t.Errorf("checkHook() = %+v, want status %s and message %q", got, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_checkCommitMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		branch      string
+		configure   func(cfg *Config)
+		wantStatus  string
+		wantMessage string
+	}{
+		{"default config", "feature/JIRA-1-login", func(*Config) {}, checkPass, `"feat: ..." is valid`},
+		{"scope values", "main", func(cfg *Config) { cfg.CommitMessage.Scope.Values = []string{"api", "web"} }, checkPass, `"feat(api): ..." is valid`},
+		{"issue footer from branch", "feature/JIRA-1-login", func(cfg *Config) { cfg.CommitMessage.RequiredFooters = []string{"jira"} }, checkPass, "is valid"},
+		{"required footer missing", "main", func(cfg *Config) { cfg.CommitMessage.RequiredFooters = []string{"jira"} }, checkWarn, "is rejected"},
+		{"validation off", "main", func(cfg *Config) { cfg.CommitMessage.ValidationMode = sv.ValidationModeOff }, checkPass, "validation disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.configure(&cfg)
+			git := mockGit{branch: tt.branch}
+			got := checkCommitMessage(git, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg.CommitMessage)
+			if got.status != tt.wantStatus || !strings.Contains(got.message, tt.wantMessage) {
+				t.Errorf("checkCommitMessage() = %+v, want status %s and message %q", got, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_checkMonorepo(t *testing.T) {
+	component := sv.MonorepoComponent{Name: "api"}
+	tests := []struct {
+		name           string
+		versioningFile string
+		components     []sv.MonorepoComponent
+		skipped        []sv.ComponentError
+		err            error
+		wantStatus     string
+		wantMessage    string
+	}{
+		{"not configured", "", nil, nil, nil, checkPass, "not configured"},
+		{"components", "package.json", []sv.MonorepoComponent{component}, nil, nil, checkPass, "1 component(s) found"},
+		{"no component", "package.json", nil, nil, nil, checkWarn, "no component found with versioning file package.json"},
+		{"skipped", "package.json", []sv.MonorepoComponent{component}, []sv.ComponentError{{Path: "web/package.json", Err: errors.New("invalid version")}}, nil, checkWarn, "1 skipped due to invalid versioning files: web/package.json: invalid version"},
+		{"error", "package.json", nil, nil, errors.New("invalid exclude pattern"), checkFail, "invalid exclude pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) { return tt.components, tt.err },
+				skipped:          tt.skipped,
+			}
+			got := checkMonorepo(mnrp, "/repo", sv.MonorepoConfig{VersioningFile: tt.versioningFile})
+			if got.status != tt.wantStatus || !strings.Contains(got.message, tt.wantMessage) {
+				t.Errorf("checkMonorepo() = %+v, want status %s and message %q", got, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_doctorHandler(t *testing.T) {
+	cfg := defaultConfig()
+	tests := []struct {
+		name      string
+		env       doctorEnv
+		wantErr   bool
+		wantLines []string
+	}{
+		{"warnings only", doctorEnv{repoPath: "/repo", fsys: mapFS{}}, false, []string{"PASS git: version 2.39.2", "WARN tags: no release tags found", "     hint: if tags exist on remote"}},
+		{"outside repository", doctorEnv{repoErr: errors.New("not a git repository"), fsys: mapFS{}}, true, []string{"FAIL repository: no git repository found"}},
+		{"invalid config", doctorEnv{repoPath: "/repo", configErr: errors.New("invalid versioning config"), fsys: mapFS{}}, true, []string{"FAIL config: invalid versioning config", "PASS history: complete history"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, stdout := newTestPrinter()
+			handler := doctorHandler(mockGit{branch: "main"}, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), mockMonorepoProcessor{}, cfg, tt.env, out)
+			err := handler(cli.NewContext(cli.NewApp(), flag.NewFlagSet("test", flag.ContinueOnError), nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("doctorHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, line := range tt.wantLines {
+				if !strings.Contains(stdout.String(), line) {
+					t.Errorf("doctorHandler() output = %q, want line %q", stdout.String(), line)
+				}
+			}
+			if tt.env.repoErr != nil && strings.Contains(stdout.String(), "tags:") {
+				t.Errorf("doctorHandler() output = %q, repository checks must be skipped outside repositories", stdout.String())
+			}
+		})
+	}
+}
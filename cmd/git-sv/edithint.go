@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bvieira/sv4git/v2/sv"
+)
+
+// Edit hint block delimiters, the block is comment-prefixed so git removes it from commit messages.
+const (
+	editHintStart = "# ---- git-sv commit message help ----"
+	editHintEnd   = "# ---- end of git-sv commit message help ----"
+)
+
+// editHint formats the help appended to commit message files by validate-commit-message --edit-hint.
+func editHint(cfg sv.CommitMessageConfig, violations []string) string {
+	lines := []string{editHintStart, "# commit message is invalid, fix it and commit again:"}
+	for _, violation := range violations {
+		lines = append(lines, "#   - "+strings.ReplaceAll(violation, "\n", " "))
+	}
+
+	lines = append(lines, "#", "# allowed types: "+strings.Join(cfg.Types, ", "))
+	scopes := "any"
+	if len(cfg.Scope.Values) > 0 {
+		scopes = strings.Join(cfg.Scope.Values, ", ")
+	}
+	if cfg.Scope.Required {
+		scopes += " (required)"
+	}
+	lines = append(lines, "# allowed scopes: "+scopes)
+	if len(cfg.RequiredFooters) > 0 {
+		lines = append(lines, "# required footers: "+strings.Join(cfg.RequiredFooters, ", "))
+	}
+
+	lines = append(lines, "# example:", "#   "+exampleHeader(cfg))
+	if len(cfg.RequiredFooters) > 0 {
+		lines = append(lines, "#")
+		for _, footer := range cfg.RequiredFooters {
+			lines = append(lines, fmt.Sprintf("#   %s: <value>", footer))
+		}
+	}
+	return strings.Join(append(lines, editHintEnd), "\n")
+}
+
+func exampleHeader(cfg sv.CommitMessageConfig) string {
+	commitType := "feat"
+	if len(cfg.Types) > 0 && !containsString(cfg.Types, commitType) {
+		commitType = cfg.Types[0]
+	}
+	scope := ""
+	switch {
+	case len(cfg.Scope.Values) > 0:
+		scope = "(" + cfg.Scope.Values[0] + ")"
+	case cfg.Scope.Required:
+		scope = "(scope)"
+	}
+	return commitType + scope + ": describe the change in lowercase"
+}
+
+// withEditHint replaces the edit hint of message with hint.
+func withEditHint(message, hint string) string {
+	message, _ = removeEditHint(message)
+	return strings.TrimRight(message, "\n") + "\n\n" + hint + "\n"
+}
+
+// removeEditHint removes the edit hint block, and the blank line before it, from message.
+func removeEditHint(message string) (string, bool) {
+	lines := strings.Split(message, "\n")
+	start, end := -1, -1
+	for i, line := range lines {
+		switch {
+		case line == editHintStart && start < 0:
+			start = i
+		case line == editHintEnd && start >= 0:
+			end = i
+		}
+	}
+	if start < 0 || end < 0 {
+		return message, false
+	}
+	if start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+		start--
+	}
+	return strings.Join(append(lines[:start:start], lines[end+1:]...), "\n"), true
+}
+
+// limitViolations returns at most max violations, all if max is 0, and the number of violations left out.
+func limitViolations(violations []string, max int) ([]string, int) {
+	if max <= 0 || len(violations) <= max {
+		return violations, 0
+	}
+	return violations[:max], len(violations) - max
+}
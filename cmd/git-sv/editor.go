@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/bvieira/sv4git/v2/sv"
+)
+
+const (
+	editorCommentPrefix = "#"
+	defaultEditor       = "vi"
+)
+
+func editorTemplate(header, body, footer string) string {
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	if body != "" {
+		b.WriteString("\n" + body + "\n")
+	}
+	if footer != "" {
+		b.WriteString("\n" + footer + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString("# Please enter the commit message for your changes. Lines starting\n")
+	b.WriteString("# with '#' will be ignored, and an empty message aborts the commit.\n")
+	b.WriteString("#\n")
+	b.WriteString("# The first line is the commit header, keep it as <type>(<scope>): <description>.\n")
+	b.WriteString("# Write the body after a blank line, footers (e.g. BREAKING CHANGE: <description>)\n")
+	b.WriteString("# must be placed on the last paragraph.\n")
+	return b.String()
+}
+
+func editorErrorTemplate(content string, err error) string {
+	return fmt.Sprintf("# ERROR: %s\n#\n%s", err.Error(), content)
+}
+
+func editorCommand() string {
+	for _, env := range []string{"GIT_EDITOR", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	return defaultEditor
+}
+
+func openEditor(content string, out *printer) (string, error) {
+	if out.promptsDisabled != "" {
+		return "", fmt.Errorf("could not open editor: %s, use --interactive to force it", out.promptsDisabled)
+	}
+
+	f, err := os.CreateTemp("", "sv4git-COMMIT_EDITMSG-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	args := strings.Fields(editorCommand())
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...) //nolint:gosec
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed, error: %v", args[0], err)
+	}
+
+	return readFile(f.Name())
+}
+
+// parseEditorMessage removes comment lines from editor content and splits it in header, body and footer.
+func parseEditorMessage(content string) (string, string, string) {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), " \t\r"); !strings.HasPrefix(line, editorCommentPrefix) {
+			lines = append(lines, line)
+		}
+	}
+
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return "", "", ""
+	}
+
+	header, rest := lines[0], lines[1:]
+	for len(rest) > 0 && rest[0] == "" {
+		rest = rest[1:]
+	}
+
+	lastParagraph := 0
+	for i, line := range rest {
+		if line == "" {
+			lastParagraph = i + 1
+		}
+	}
+
+	footerRegex := regexp.MustCompile("^[a-zA-Z-]+: .*|^[a-zA-Z-]+ #.*|^BREAKING CHANGE: .*")
+	isFooter := lastParagraph < len(rest)
+	for _, line := range rest[lastParagraph:] {
+		if !footerRegex.MatchString(line) {
+			isFooter = false
+			break
+		}
+	}
+
+	if !isFooter {
+		return header, strings.Join(rest, "\n"), ""
+	}
+	body := rest[:lastParagraph]
+	for len(body) > 0 && body[len(body)-1] == "" {
+		body = body[:len(body)-1]
+	}
+	return header, strings.Join(body, "\n"), strings.Join(rest[lastParagraph:], "\n")
+}
+
+func joinCommitMessage(header, body, footer string) string {
+	message := header
+	for _, content := range []string{body, footer} {
+		if content != "" {
+			message += "\n\n" + content
+		}
+	}
+	return message
+}
+
+func getCommitMessageFromEditor(p sv.MessageProcessor, header, body, footer string, out *printer) (string, string, string, error) {
+	content := editorTemplate(header, body, footer)
+	for {
+		edited, err := openEditor(content, out)
+		if err != nil {
+			return "", "", "", err
+		}
+
+		h, b, f := parseEditorMessage(edited)
+		if h == "" {
+			return "", "", "", fmt.Errorf("aborting commit due to empty commit message")
+		}
+
+		if verr := p.Validate(joinCommitMessage(h, b, f)); verr != nil {
+			content = editorErrorTemplate(editorTemplate(h, b, f), verr)
+			continue
+		}
+		return h, b, f, nil
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bvieira/sv4git/v2/sv"
+)
+
+func Test_parseEditorMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantHeader string
+		wantBody   string
+		wantFooter string
+	}{
+		{"empty", "", "", "", ""},
+		{"only comments", "# comment\n#\n", "", "", ""},
+		{"header only", "feat: something\n\n# comment\n", "feat: something", "", ""},
+		{"header and body", "feat: something\n\nfirst line\nsecond line\n# comment\n", "feat: something", "first line\nsecond line", ""},
+		{"multi paragraph body", "feat: something\n\nfirst paragraph\n\nsecond paragraph\n", "feat: something", "first paragraph\n\nsecond paragraph", ""},
+		{"header and footer", "feat: something\n\njira: JIRA-123\n", "feat: something", "", "jira: JIRA-123"},
+		{"body and footer", "feat: something\n\nbody\n\nBREAKING CHANGE: break\njira: JIRA-123\n# comment", "feat: something", "body", "BREAKING CHANGE: break\njira: JIRA-123"},
+		{"comments between lines", "# error\n#\nfeat: something\n# comment\n\nbody\n", "feat: something", "body", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, body, footer := parseEditorMessage(tt.content)
+			if header != tt.wantHeader {
+				t.Errorf("parseEditorMessage() header = %q, want %q", header, tt.wantHeader)
+			}
+			if body != tt.wantBody {
+				t.Errorf("parseEditorMessage() body = %q, want %q", body, tt.wantBody)
+			}
+			if footer != tt.wantFooter {
+				t.Errorf("parseEditorMessage() footer = %q, want %q", footer, tt.wantFooter)
+			}
+		})
+	}
+}
+
+func Test_editorTemplateRoundTrip(t *testing.T) {
+	content := editorErrorTemplate(editorTemplate("feat: something", "body", "jira: JIRA-123"), errors.New("invalid"))
+	if !strings.HasPrefix(content, "# ERROR: invalid\n") {
+		t.Errorf("editorErrorTemplate() = %q, want error comment at the top", content)
+	}
+
+	header, body, footer := parseEditorMessage(content)
+	if header != "feat: something" || body != "body" || footer != "jira: JIRA-123" {
+		t.Errorf("parseEditorMessage() = (%q, %q, %q), want template values", header, body, footer)
+	}
+}
+
+func Test_getCommitMessageFromEditor(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true") // keeps the template unchanged
+
+	p := sv.NewMessageProcessor(sv.CommitMessageConfig{Types: []string{"feat"}}, sv.BranchesConfig{})
+	header, body, footer, err := getCommitMessageFromEditor(p, "feat: something", "body", "", newPrinter(io.Discard, io.Discard))
+	if err != nil {
+		t.Fatalf("getCommitMessageFromEditor() unexpected error: %v", err)
+	}
+	if header != "feat: something" || body != "body" || footer != "" {
+		t.Errorf("getCommitMessageFromEditor() = (%q, %q, %q), want template values", header, body, footer)
+	}
+}
+
+func Test_getCommitMessageFromEditor_EmptyMessage(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	p := sv.NewMessageProcessor(sv.CommitMessageConfig{Types: []string{"feat"}}, sv.BranchesConfig{})
+	if _, _, _, err := getCommitMessageFromEditor(p, "", "", "", newPrinter(io.Discard, io.Discard)); err == nil {
+		t.Error("getCommitMessageFromEditor() expected error for empty message, got nil")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// bumpExplanation is printed by next-version and monorepo-next-version --explain.
+type bumpExplanation struct {
+	Bump     string            `json:"bump"`
+	Applied  string            `json:"applied"`
+	Override string            `json:"override,omitempty"` // --bump or --set-version, commits are ignored
+	Commits  []explainedCommit `json:"commits"`
+}
+
+type explainedCommit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Bump    string `json:"bump"`
+	Reason  string `json:"reason"`
+}
+
+func explainBump(c *cli.Context, semverProcessor sv.SemVerCommitsProcessor, commits []sv.GitCommitLog) bumpExplanation {
+	explanation := semverProcessor.Explain(commits)
+	result := bumpExplanation{Bump: explanation.Bump, Applied: explanation.Applied, Commits: []explainedCommit{}}
+	for _, cb := range explanation.Commits {
+		result.Commits = append(result.Commits, explainedCommit{Hash: cb.Commit.Hash, Subject: commitSubject(cb.Commit.Message), Bump: cb.Bump, Reason: cb.Reason})
+	}
+	if bump := c.String("bump"); bump != "" {
+		result.Override = "--bump " + bump
+	} else if setVersion := c.String("set-version"); setVersion != "" {
+		result.Override = "--set-version " + setVersion
+	}
+	return result
+}
+
+// commitSubject formats a parsed commit message back to its conventional header, e.g. "feat(api)!: drop v1".
+func commitSubject(message sv.CommitMessage) string {
+	var sb strings.Builder
+	sb.WriteString(message.Type)
+	if message.Scope != "" {
+		fmt.Fprintf(&sb, "(%s)", message.Scope)
+	}
+	if message.IsBreakingChange {
+		sb.WriteString("!")
+	}
+	if sb.Len() > 0 {
+		sb.WriteString(": ")
+	}
+	sb.WriteString(message.Description)
+	return sb.String()
+}
+
+func printBumpExplanation(out *printer, explanation bumpExplanation, indent string) {
+	if explanation.Override != "" {
+		out.printf("%sversion forced by %s, commits are ignored\n", indent, explanation.Override)
+	}
+	if len(explanation.Commits) == 0 {
+		out.printf("%sno commits require a version update\n", indent)
+		return
+	}
+	out.printf("%s%s update required by %d commit(s):\n", indent, explanation.Bump, len(explanation.Commits))
+	for _, commit := range explanation.Commits {
+		out.printf("%s  %s %s (%s)\n", indent, commit.Hash, commit.Subject, commit.Reason)
+	}
+	if explanation.Applied != explanation.Bump {
+		out.printf("%supdate limited to %s by release branch\n", indent, explanation.Applied)
+	}
+}
+
+// commitSummary hash and subject of a commit, e.g. commits not following conventional commits listed by --show-unknown.
+type commitSummary struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+}
+
+func newCommitSummary(commit sv.GitCommitLog) commitSummary {
+	return commitSummary{Hash: commit.Hash, Subject: commitSubject(commit.Message)}
+}
+
+func unknownCommits(commits []sv.GitCommitLog) []commitSummary {
+	result := []commitSummary{}
+	for _, commit := range sv.UnknownCommits(commits) {
+		result = append(result, newCommitSummary(commit))
+	}
+	return result
+}
+
+func printUnknownCommits(w io.Writer, commits []commitSummary) {
+	if len(commits) == 0 {
+		fmt.Fprintln(w, "all commits follow conventional commits")
+		return
+	}
+	fmt.Fprintf(w, "%d commit(s) not following conventional commits:\n", len(commits))
+	for _, commit := range commits {
+		fmt.Fprintf(w, "  %s %s\n", commit.Hash, commit.Subject)
+	}
+}
@@ -1,77 +1,306 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/tabwriter"
+	"text/template"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/bvieira/sv4git/v2/sv"
 	"github.com/urfave/cli/v2"
-	"gopkg.in/yaml.v3"
 )
 
-func configDefaultHandler() func(c *cli.Context) error {
+func configDefaultHandler(out *printer) func(c *cli.Context) error {
 	cfg := defaultConfig()
 	return func(c *cli.Context) error {
-		content, err := yaml.Marshal(&cfg)
+		content, err := encodeConfig(cfg, c.String("format"))
 		if err != nil {
 			return err
 		}
-		fmt.Println(string(content))
+		out.println(content)
 		return nil
 	}
 }
 
-func configShowHandler(cfg Config) func(c *cli.Context) error {
+func configShowHandler(cfg Config, out *printer) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		content, err := yaml.Marshal(&cfg)
+		content, err := encodeConfig(cfg, c.String("format"))
 		if err != nil {
 			return err
 		}
-		fmt.Println(string(content))
+		out.println(content)
 		return nil
 	}
 }
 
-func currentVersionHandler(git sv.Git) func(c *cli.Context) error {
+func configMigrateHandler(out *printer) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		lastTag := git.LastTag()
+		from := c.String("from")
+		if from == "" {
+			return fmt.Errorf("missing v1 config file, use --from")
+		}
+		output := c.String("output")
+		if output != "" {
+			if format, err := configFormat(output); err != nil || format != configFormatYAML {
+				return fmt.Errorf("invalid output file %s, migrated config is written as yaml, use .yml or .yaml extension", output)
+			}
+		}
+
+		content, err := os.ReadFile(from)
+		if err != nil {
+			return fmt.Errorf("error reading v1 config, message: %v", err)
+		}
+		migrated, warnings, err := migrateLegacyConfig(content, filepath.Base(from))
+		if err != nil {
+			return fmt.Errorf("error migrating v1 config, message: %v", err)
+		}
+		for _, warning := range warnings {
+			out.warnf("%s", warning)
+		}
+
+		if output == "" {
+			out.printf("%s", migrated)
+			return nil
+		}
+		if err := writeOutputFile(output, migrated, c.Bool("force")); err != nil {
+			return err
+		}
+		out.statusf("config written to %s", output)
+		return nil
+	}
+}
 
-		currentVer, err := sv.ToVersion(lastTag)
+// checkHistoryHandler fetches tags when requested and checks if history is complete, shallow clones are unshallowed only with --fetch or versioning.auto-fetch.
+func checkHistoryHandler(git sv.Git, cfg sv.VersioningConfig, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		shallow, err := git.IsShallow()
 		if err != nil {
-			return fmt.Errorf("error parsing version: %s from git tag, message: %v", lastTag, err)
+			return fmt.Errorf("error checking if repository is a shallow clone, message: %v", err)
+		}
+		if !shallow {
+			if c.Bool("fetch") || cfg.FetchBefore {
+				fetchTags(git, out)
+			}
+			return nil
+		}
+
+		if !c.Bool("fetch") && !cfg.AutoFetch {
+			missingTags := ""
+			if tags, terr := git.Tags(); terr == nil && len(tags) == 0 {
+				missingTags = " and no tags were found"
+			}
+			return fmt.Errorf("repository is a shallow clone%s, versions would be calculated from incomplete history. Run \"git fetch --tags --unshallow\", use --fetch flag or set versioning.auto-fetch: true", missingTags)
+		}
+
+		out.warnf("repository is a shallow clone, fetching tags and complete history...")
+		if err := git.Unshallow(); err != nil {
+			return fmt.Errorf("error fetching tags and history, message: %v", err)
 		}
-		fmt.Printf("%d.%d.%d\n", currentVer.Major(), currentVer.Minor(), currentVer.Patch())
 		return nil
 	}
 }
 
-func nextVersionHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor) func(c *cli.Context) error {
+// fetchTags updates local tags from remote, when offline versions are calculated from local tags.
+func fetchTags(git sv.Git, out *printer) {
+	out.debugf("fetching tags from remote")
+	if err := git.Fetch(true); err != nil {
+		out.warnf("could not fetch tags, using local tags: %v", err)
+	}
+}
+
+func currentVersionHandler(git sv.Git, cfg Config, out *printer) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
 		lastTag := git.LastTag()
 
-		currentVer, err := sv.ToVersion(lastTag)
+		currentVer, _, err := baseVersion(git, cfg.Versioning, lastTag, "", out)
 		if err != nil {
-			return fmt.Errorf("error parsing version: %s from git tag, message: %v", lastTag, err)
+			return err
+		}
+		out.printf("%d.%d.%d\n", currentVer.Major(), currentVer.Minor(), currentVer.Patch())
+		return nil
+	}
+}
+
+// releaseTag is a row of tags command.
+type releaseTag struct {
+	Tag     string    `json:"tag"`
+	Version string    `json:"version"`
+	Date    time.Time `json:"date"`
+	Commit  string    `json:"commit"`
+
+	version *semver.Version
+}
+
+func tagsHandler(git sv.Git, monorepoProcessor sv.MonorepoProcessor, cfg Config, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		format := str(c.String("format"), "table")
+		if format != "table" && format != "json" {
+			return fmt.Errorf("invalid format: %s, use table or json", format)
+		}
+		filter := c.String("filter")
+		if _, err := path.Match(filter, ""); err != nil {
+			return fmt.Errorf("invalid filter: %s, message: %v", filter, err)
+		}
+		limit := c.Int("limit")
+		if limit < 0 {
+			return fmt.Errorf("invalid limit: %d, use 0 to list every tag", limit)
 		}
 
-		commits, err := git.Log(sv.NewLogRange(sv.TagRange, lastTag, ""))
+		tags, componentTags, err := releaseTags(git, monorepoProcessor, cfg, repoPath, c.String("component"), out)
 		if err != nil {
-			return fmt.Errorf("error getting git log, message: %v", err)
+			return err
+		}
+
+		result := []releaseTag{}
+		for _, tag := range tags {
+			if filter != "" {
+				if matched, _ := path.Match(filter, tag.Name); !matched {
+					continue
+				}
+			}
+			name := tag.Name
+			if componentTags {
+				name = sv.ComponentTagVersion(name)
+			}
+			version, verr := sv.ToVersion(name)
+			if verr != nil {
+				out.debugf("ignoring tag %s, it is not a version", tag.Name)
+				continue
+			}
+			result = append(result, releaseTag{Tag: tag.Name, Version: version.String(), Date: tag.Date, version: version})
+		}
+		// newest version first, tags of the same version, e.g. with different build metadata, by date
+		sort.SliceStable(result, func(i, j int) bool {
+			if cmp := result[i].version.Compare(result[j].version); cmp != 0 {
+				return cmp > 0
+			}
+			return result[i].Date.After(result[j].Date)
+		})
+		if limit > 0 && len(result) > limit {
+			result = result[:limit]
+		}
+		for i := range result {
+			if result[i].Commit, err = git.ShortHash("refs/tags/" + result[i].Tag); err != nil {
+				return fmt.Errorf("error getting commit of tag %s, message: %v", result[i].Tag, err)
+			}
+		}
+
+		if format == "json" {
+			content, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			out.println(string(content))
+			return nil
+		}
+		if len(result) == 0 {
+			out.infof("no release tags found")
+			return nil
+		}
+		var sb strings.Builder
+		w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TAG\tVERSION\tDATE\tCOMMIT")
+		for _, tag := range result {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tag.Tag, tag.Version, tag.Date.Format(dateFlagLayout), tag.Commit)
+		}
+		w.Flush()
+		out.printf("%s", sb.String())
+		return nil
+	}
+}
+
+// releaseTags returns the tags matching tag.filter config, or the tags of component if not empty, and if they are component
+// tags, prefixed by the component name or one of its previous paths.
+func releaseTags(git sv.Git, monorepoProcessor sv.MonorepoProcessor, cfg Config, repoPath, name string, out *printer) ([]sv.GitTag, bool, error) {
+	if name == "" {
+		tags, err := git.Tags()
+		if err != nil {
+			return nil, false, fmt.Errorf("error listing tags, message: %v", err)
+		}
+		return tags, false, nil
+	}
+
+	components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+	if err != nil {
+		return nil, false, fmt.Errorf("error finding monorepo components: %v", err)
+	}
+	logComponents(out, components, skipped)
+	component, found := findComponent(name, components)
+	if !found && len(skipped) > 0 {
+		return nil, false, fmt.Errorf("component: %s not found, %d component(s) skipped due to invalid versioning files", name, len(skipped))
+	}
+	if !found {
+		return nil, false, fmt.Errorf("component: %s not found", name)
+	}
+	tags, err := git.ComponentTags(component.Name, component.PreviousPaths...)
+	if err != nil {
+		return nil, false, fmt.Errorf("error listing tags of component %s, message: %v", component.Name, err)
+	}
+	return tags, true, nil
+}
+
+type nextVersionInfo struct {
+	CurrentVersion string           `json:"currentVersion"`
+	NextVersion    string           `json:"nextVersion"`
+	Updated        bool             `json:"updated"`
+	Explanation    *bumpExplanation `json:"explanation,omitempty"`
+	UnknownCommits []commitSummary  `json:"unknownCommits,omitempty"` // --show-unknown
+}
+
+func nextVersionHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, cfg Config, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		format := str(c.String("format"), "text")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("invalid format: %s, use text or json", format)
+		}
+		checkPaths(git, c.StringSlice("path"), out)
+		currentVer, nextVer, commits, err := nextVersion(c, git, semverProcessor, cfg.Versioning, git.LastTag(), "", out)
+		if err != nil {
+			return err
+		}
+		if nextVer, err = withBuildMetadata(git, c, cfg.Versioning, nextVer, ""); err != nil {
+			return err
+		}
+
+		info := nextVersionInfo{CurrentVersion: currentVer.String(), NextVersion: nextVer.String(), Updated: !nextVer.Equal(currentVer)}
+		if c.Bool("explain") {
+			explanation := explainBump(c, semverProcessor, commits)
+			info.Explanation = &explanation
+		}
+		if c.Bool("show-unknown") {
+			info.UnknownCommits = unknownCommits(commits)
+		}
+		if format == "json" {
+			content, err := json.MarshalIndent(info, "", "  ")
+			if err != nil {
+				return err
+			}
+			out.println(string(content))
+			return nil
 		}
 
-		nextVer, _ := semverProcessor.NextVersion(currentVer, commits)
-		fmt.Printf("%d.%d.%d\n", nextVer.Major(), nextVer.Minor(), nextVer.Patch())
+		out.printf("%s\n", info.NextVersion)
+		if info.Explanation != nil {
+			printBumpExplanation(out, *info.Explanation, "")
+		}
+		if c.Bool("show-unknown") {
+			printUnknownCommits(out.stdout, info.UnknownCommits)
+		}
 		return nil
 	}
 }
 
-func commitLogHandler(git sv.Git) func(c *cli.Context) error {
+func commitLogHandler(git sv.Git, out *printer) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
 		var commits []sv.GitCommitLog
 		var err error
@@ -83,14 +312,15 @@ func commitLogHandler(git sv.Git) func(c *cli.Context) error {
 			return fmt.Errorf("cannot define tag flag with range, start or end flags")
 		}
 
+		paths := logPaths(c, git, out)
 		if tagFlag != "" {
-			commits, err = getTagCommits(git, tagFlag)
+			commits, err = getTagCommits(git, tagFlag, paths)
 		} else {
 			r, rerr := logRange(git, rangeFlag, startFlag, endFlag)
 			if rerr != nil {
 				return rerr
 			}
-			commits, err = git.Log(r)
+			commits, err = git.Log(r.WithPaths(paths))
 		}
 		if err != nil {
 			return fmt.Errorf("error getting git log, message: %v", err)
@@ -101,18 +331,38 @@ func commitLogHandler(git sv.Git) func(c *cli.Context) error {
 			if err != nil {
 				return err
 			}
-			fmt.Println(string(content))
+			out.println(string(content))
 		}
 		return nil
 	}
 }
 
-func getTagCommits(git sv.Git, tag string) ([]sv.GitCommitLog, error) {
+func getTagCommits(git sv.Git, tag string, paths []string) ([]sv.GitCommitLog, error) {
 	prev, _, err := getTags(git, tag)
 	if err != nil {
 		return nil, err
 	}
-	return git.Log(sv.NewLogRange(sv.TagRange, prev, tag))
+	return git.Log(sv.NewLogRangeWithPaths(sv.TagRange, prev, tag, paths))
+}
+
+// logPaths returns --path pathspecs, relative to repository root, see checkPaths.
+func logPaths(c *cli.Context, git sv.Git, out *printer) []string {
+	paths := c.StringSlice("path")
+	checkPaths(git, paths, out)
+	return paths
+}
+
+// checkPaths prints a warning for each path not found on HEAD, e.g. a typo, as it would silently filter every commit.
+// Pathspecs with glob characters or magic, e.g. :(exclude), are not checked.
+func checkPaths(git sv.Git, paths []string, out *printer) {
+	for _, p := range paths {
+		if strings.HasPrefix(p, ":") || strings.ContainsAny(p, "*?[") {
+			continue
+		}
+		if _, err := git.ShowFile("HEAD", path.Clean(filepath.ToSlash(p))); err != nil {
+			out.warnf("path %s not found on HEAD, only commits that changed it in the past are included", p)
+		}
+	}
 }
 
 func logRange(git sv.Git, rangeFlag, startFlag, endFlag string) (sv.LogRange, error) {
@@ -128,77 +378,197 @@ func logRange(git sv.Git, rangeFlag, startFlag, endFlag string) (sv.LogRange, er
 	}
 }
 
-func commitNotesHandler(git sv.Git, rnProcessor sv.ReleaseNoteProcessor, outputFormatter sv.OutputFormatter) func(c *cli.Context) error {
+func commitNotesHandler(git sv.Git, rnProcessor sv.ReleaseNoteProcessor, outputFormatter sv.OutputFormatter, out *printer) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		var date time.Time
-
 		rangeFlag := c.String("r")
 		lr, err := logRange(git, rangeFlag, c.String("s"), c.String("e"))
 		if err != nil {
 			return err
 		}
 
-		commits, err := git.Log(lr)
+		commits, err := git.Log(lr.WithPaths(logPaths(c, git, out)))
 		if err != nil {
 			return fmt.Errorf("error getting git log from range: %s, message: %v", rangeFlag, err)
 		}
 
-		if len(commits) > 0 {
-			date, _ = time.Parse("2006-01-02", commits[0].Date)
+		date, err := latestCommitDate(commits)
+		if err != nil {
+			return err
 		}
 
-		output, err := outputFormatter.FormatReleaseNote(rnProcessor.Create(nil, "", date, commits))
+		output, err := outputFormatter.FormatReleaseNote(rnProcessor.Create(nil, "", "", date, commits))
 		if err != nil {
 			return fmt.Errorf("could not format release notes, message: %v", err)
 		}
-		fmt.Println(output)
+		out.println(output)
 		return nil
 	}
 }
 
-func releaseNotesHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, rnProcessor sv.ReleaseNoteProcessor, outputFormatter sv.OutputFormatter) func(c *cli.Context) error {
+func releaseNotesHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, rnProcessor sv.ReleaseNoteProcessor, outputFormatter sv.OutputFormatter, clock sv.Clock, out *printer) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
 		var commits []sv.GitCommitLog
 		var rnVersion *semver.Version
-		var tag string
+		var tag, previousTag string
 		var date time.Time
 		var err error
 
-		if tag = c.String("t"); tag != "" {
-			rnVersion, date, commits, err = getTagVersionInfo(git, tag)
+		branch := c.String("branch")
+		var preview string
+		if tag = c.String("t"); tag != "" && branch != "" {
+			return fmt.Errorf("use --tag or --branch, not both")
+		}
+		paths := logPaths(c, git, out)
+		if tag != "" {
+			rnVersion, previousTag, date, commits, err = getTagVersionInfo(git, tag, c.Bool("include-metadata"), paths)
+		} else if branch != "" {
+			rnVersion, preview, previousTag, date, commits, err = getBranchPreviewInfo(git, semverProcessor, clock, branch, c.String("base"), paths, out)
 		} else {
 			// TODO: should generate release notes if version was not updated?
-			rnVersion, _, date, commits, err = getNextVersionInfo(git, semverProcessor)
+			var updated bool
+			rnVersion, updated, previousTag, date, commits, err = getNextVersionInfo(git, semverProcessor, clock, paths, out)
+			if err == nil && len(commits) == 0 {
+				err = nothingToReleaseError(previousTag, releaseNotesHint(previousTag))
+			}
+			if err == nil && updated {
+				tag, err = existingNextVersionTag(git, *rnVersion, c.Bool("use-existing"))
+			}
+			if err == nil && tag != "" {
+				out.statusf("next version %s is already tagged, using release notes of tag %s", rnVersion.String(), tag)
+				rnVersion, previousTag, date, commits, err = getTagVersionInfo(git, tag, c.Bool("include-metadata"), paths)
+			}
 		}
 
 		if err != nil {
 			return err
 		}
 
-		releasenote := rnProcessor.Create(rnVersion, tag, date, commits)
+		if c.Bool("show-unknown") {
+			printUnknownCommits(out.stderr, unknownCommits(commits))
+		}
+
+		releasenote := rnProcessor.Create(rnVersion, tag, previousTag, date, commits)
+		if branch != "" {
+			// the branch is not released yet, compare links would point to HEAD instead of the branch.
+			releasenote.Tag, releasenote.CompareURL = preview, ""
+		}
 		output, err := outputFormatter.FormatReleaseNote(releasenote)
 		if err != nil {
 			return fmt.Errorf("could not format release notes, message: %v", err)
 		}
-		fmt.Println(output)
+
+		return writeReleaseNote(c, releasenote, output, out)
+	}
+}
+
+// writeReleaseNote prints output, the formatted releasenote, or writes it to the --output file printing its path on stderr.
+func writeReleaseNote(c *cli.Context, releasenote sv.ReleaseNote, output string, out *printer) error {
+	outputTemplate := c.String("output")
+	if outputTemplate == "" {
+		out.println(output)
 		return nil
 	}
+	path, err := releaseNotesOutputPath(outputTemplate, releasenote)
+	if err != nil {
+		return err
+	}
+	if err := writeOutputFile(path, output+"\n", c.Bool("force")); err != nil {
+		return err
+	}
+	out.errln(path)
+	return nil
 }
 
-func getTagVersionInfo(git sv.Git, tag string) (*semver.Version, time.Time, []sv.GitCommitLog, error) {
-	tagVersion, _ := sv.ToVersion(tag)
+// releaseNotesOutputFileData values available on release-notes --output file name template.
+type releaseNotesOutputFileData struct {
+	Version string // version without prefix, empty if tag is not a version
+	Tag     string // empty for next version
+	Date    string // release date as YYYY-MM-DD
+}
+
+// releaseNotesOutputPath renders --output file name template, e.g. "dist/release-notes-v{{.Version}}.md".
+func releaseNotesOutputPath(outputTemplate string, releasenote sv.ReleaseNote) (string, error) {
+	tpl, err := template.New("output").Option("missingkey=error").Parse(outputTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid output file name template: %s, message: %v", outputTemplate, err)
+	}
+
+	data := releaseNotesOutputFileData{Tag: releasenote.Tag, Date: releasenote.Date.Format("2006-01-02")}
+	if releasenote.Version != nil {
+		data.Version = releasenote.Version.String()
+	}
+	var path strings.Builder
+	if err := tpl.Execute(&path, data); err != nil {
+		return "", fmt.Errorf("could not execute output file name template: %s, message: %v", outputTemplate, err)
+	}
+	if strings.TrimSpace(path.String()) == "" {
+		return "", fmt.Errorf("output file name template: %s resolved to an empty path", outputTemplate)
+	}
+	return path.String(), nil
+}
+
+// writeOutputFile writes content to path creating parent directories, existing files are only overwritten with force.
+func writeOutputFile(path, content string, force bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating directory for %s, message: %v", path, err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("file %s already exists, use --force to overwrite it", path)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating file %s, message: %v", path, err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing file %s, message: %v", path, err)
+	}
+	return file.Close()
+}
+
+// existingNextVersionTag returns the tag already created for next version, e.g. tagged manually on another branch, empty if there is none.
+// An existing tag is an error unless useExisting is set.
+func existingNextVersionTag(git sv.Git, version semver.Version, useExisting bool) (string, error) {
+	tags, err := git.Tags()
+	if err != nil {
+		return "", fmt.Errorf("error listing tags, message: %v", err)
+	}
+	tag := findVersionTag(tags, git.TagName(version))
+	if tag != "" && !useExisting {
+		return "", fmt.Errorf("next version %s is already tagged as %s, use -t %s or --use-existing to generate its release notes", version.String(), tag, tag)
+	}
+	return tag, nil
+}
+
+// findVersionTag returns the tag with name, tags with build metadata included, empty if not found.
+func findVersionTag(tags []sv.GitTag, name string) string {
+	for _, tag := range tags {
+		if tag.Name == name || strings.HasPrefix(tag.Name, name+"+") {
+			return tag.Name
+		}
+	}
+	return ""
+}
+
+func getTagVersionInfo(git sv.Git, tag string, includeMetadata bool, paths []string) (*semver.Version, string, time.Time, []sv.GitCommitLog, error) {
+	version := tagVersion(tag, includeMetadata)
 
 	previousTag, currentTag, err := getTags(git, tag)
 	if err != nil {
-		return nil, time.Time{}, nil, fmt.Errorf("error listing tags, message: %v", err)
+		return nil, "", time.Time{}, nil, fmt.Errorf("error listing tags, message: %v", err)
 	}
 
-	commits, err := git.Log(sv.NewLogRange(sv.TagRange, previousTag, tag))
+	commits, err := git.Log(sv.NewLogRangeWithPaths(sv.TagRange, previousTag, tag, paths))
 	if err != nil {
-		return nil, time.Time{}, nil, fmt.Errorf("error getting git log from tag: %s, message: %v", tag, err)
+		return nil, "", time.Time{}, nil, fmt.Errorf("error getting git log from tag: %s, message: %v", tag, err)
 	}
 
-	return tagVersion, currentTag.Date, commits, nil
+	return version, previousTag, currentTag.Date, commits, nil
 }
 
 func getTags(git sv.Git, tag string) (string, sv.GitTag, error) {
@@ -228,467 +598,3044 @@ func find(tag string, tags []sv.GitTag) int {
 	return -1
 }
 
-func getNextVersionInfo(git sv.Git, semverProcessor sv.SemVerCommitsProcessor) (*semver.Version, bool, time.Time, []sv.GitCommitLog, error) {
+// getNextVersionInfo returns next version, if it was updated, last tag, release date from clock and commits since last tag
+// changing paths, every commit if empty.
+func getNextVersionInfo(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, clock sv.Clock, paths []string, out *printer) (*semver.Version, bool, string, time.Time, []sv.GitCommitLog, error) {
 	lastTag := git.LastTag()
 
-	commits, err := git.Log(sv.NewLogRange(sv.TagRange, lastTag, ""))
+	commits, err := git.Log(sv.NewLogRangeWithPaths(sv.TagRange, lastTag, "", paths))
 	if err != nil {
-		return nil, false, time.Time{}, nil, fmt.Errorf("error getting git log, message: %v", err)
+		return nil, false, "", time.Time{}, nil, fmt.Errorf("error getting git log, message: %v", err)
 	}
+	debugBaseline(out, lastTag)
+	debugCommits(out, semverProcessor, commits)
 
 	currentVer, _ := sv.ToVersion(lastTag)
 	version, updated := semverProcessor.NextVersion(currentVer, commits)
 
-	return version, updated, time.Now(), commits, nil
+	return version, updated, lastTag, clock.Now(), commits, nil
 }
 
-func tagHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor) func(c *cli.Context) error {
-	return func(c *cli.Context) error {
-		lastTag := git.LastTag()
-
-		currentVer, err := sv.ToVersion(lastTag)
+// getBranchPreviewInfo returns release notes info of commits unique to branch, as if it was merged on base, the default
+// branch if empty. The version is only returned when it is final, base has no commits after the last tag, otherwise
+// the preview heading with branch name is returned instead. Only commits changing paths are used, every commit if empty.
+func getBranchPreviewInfo(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, clock sv.Clock, branch, base string, paths []string, out *printer) (*semver.Version, string, string, time.Time, []sv.GitCommitLog, error) {
+	if base == "" {
+		defaultBranch, err := git.DefaultBranch()
 		if err != nil {
-			return fmt.Errorf("error parsing version: %s from git tag, message: %v", lastTag, err)
+			return nil, "", "", time.Time{}, nil, fmt.Errorf("%v, or use --base flag", err)
 		}
-
-		commits, err := git.Log(sv.NewLogRange(sv.TagRange, lastTag, ""))
-		if err != nil {
-			return fmt.Errorf("error getting git log, message: %v", err)
-		}
-
-		nextVer, _ := semverProcessor.NextVersion(currentVer, commits)
-		tagname, err := git.Tag(*nextVer)
-		fmt.Println(tagname)
-		if err != nil {
-			return fmt.Errorf("error generating tag version: %s, message: %v", nextVer.String(), err)
-		}
-		return nil
-	}
-}
-
-func getCommitType(cfg Config, p sv.MessageProcessor, input string) (string, error) {
-	if input == "" {
-		t, err := promptType(cfg.CommitMessage.Types)
-		return t.Type, err
-	}
-	return input, p.ValidateType(input)
-}
-
-func getCommitScope(cfg Config, p sv.MessageProcessor, input string, noScope bool) (string, error) {
-	if input == "" && !noScope {
-		return promptScope(cfg.CommitMessage.Scope.Values)
-	}
-	return input, p.ValidateScope(input)
-}
-
-func getCommitDescription(p sv.MessageProcessor, input string) (string, error) {
-	if input == "" {
-		return promptSubject()
-	}
-	return input, p.ValidateDescription(input)
-}
-
-func getCommitBody(noBody bool) (string, error) {
-	if noBody {
-		return "", nil
+		base = defaultBranch
 	}
-
-	var fullBody strings.Builder
-	for body, err := promptBody(); body != "" || err != nil; body, err = promptBody() {
-		if err != nil {
-			return "", err
-		}
-		if fullBody.Len() > 0 {
-			fullBody.WriteString("\n")
-		}
-		if body != "" {
-			fullBody.WriteString(body)
-		}
+	mergeBase, err := git.MergeBase(base, branch)
+	if err != nil {
+		return nil, "", "", time.Time{}, nil, fmt.Errorf("error finding merge base of %s and %s, message: %v", branch, base, err)
 	}
-	return fullBody.String(), nil
-}
-
-func getCommitIssue(cfg Config, p sv.MessageProcessor, branch string, noIssue bool) (string, error) {
-	branchIssue, err := p.IssueID(branch)
+	commits, err := git.Log(sv.NewLogRangeWithPaths(sv.HashRange, mergeBase, branch, paths))
 	if err != nil {
-		return "", err
+		return nil, "", "", time.Time{}, nil, fmt.Errorf("error getting git log of branch %s, message: %v", branch, err)
 	}
+	debugCommits(out, semverProcessor, commits)
 
-	if cfg.CommitMessage.IssueFooterConfig().Key == "" || cfg.CommitMessage.Issue.Regex == "" {
-		return "", nil
+	lastTag := git.LastTag()
+	unreleased, err := git.Log(sv.NewLogRangeWithPaths(sv.TagRange, lastTag, base, paths))
+	if err != nil {
+		return nil, "", "", time.Time{}, nil, fmt.Errorf("error getting git log of %s, message: %v", base, err)
 	}
 
-	if noIssue {
-		return branchIssue, nil
+	currentVer, _ := sv.ToVersion(lastTag)
+	version, updated := semverProcessor.NextVersion(currentVer, commits)
+	heading := "Preview " + branch
+	switch {
+	case !updated:
+		out.statusf("preview of %s merged on %s: %d commit(s) since %s, no version update", branch, base, len(commits), mergeBase)
+		version = nil
+	case len(unreleased) > 0:
+		out.statusf("preview of %s merged on %s: %d commit(s) since %s, next version at least %s, %s has %d unreleased commit(s)", branch, base, len(commits), mergeBase, version, base, len(unreleased))
+		version = nil
+	default:
+		out.statusf("preview of %s merged on %s: %d commit(s) since %s, next version %s", branch, base, len(commits), mergeBase, version)
+		heading = ""
 	}
-
-	return promptIssueID("issue id", cfg.CommitMessage.Issue.Regex, branchIssue)
+	return version, heading, lastTag, clock.Now(), commits, nil
 }
 
-func getCommitBreakingChange(noBreaking bool, input string) (string, error) {
-	if noBreaking {
-		return "", nil
+// getTagRemote returns the remote used to push tags, --remote flag has priority over tag.remote config.
+// Empty remote means tags should not be pushed.
+func getTagRemote(git sv.Git, c *cli.Context) (string, error) {
+	remote := git.TagRemote()
+	if c.IsSet("remote") {
+		remote = c.String("remote")
 	}
-
-	if strings.TrimSpace(input) != "" {
-		return input, nil
+	if remote == "" {
+		return "", nil
 	}
 
-	hasBreakingChanges, err := promptConfirm("has breaking change?")
+	exists, err := git.RemoteExists(remote)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("error checking remote %s, message: %v", remote, err)
 	}
-	if !hasBreakingChanges {
-		return "", nil
+	if !exists {
+		return "", fmt.Errorf("remote %s not found, use --remote flag or tag.remote config to select an existing remote", remote)
 	}
-
-	return promptBreakingChanges()
+	return remote, nil
 }
 
-func commitHandler(cfg Config, git sv.Git, messageProcessor sv.MessageProcessor) func(c *cli.Context) error {
+// tagHandler creates the next version tag, on a release branch the version must belong to the branch version line.
+func tagHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, cfg Config, releaseBranch sv.ReleaseBranch, repoPath string, out *printer) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		noBreaking := c.Bool("no-breaking")
-		noBody := c.Bool("no-body")
-		noIssue := c.Bool("no-issue")
-		noScope := c.Bool("no-scope")
-		inputType := c.String("type")
-		inputScope := c.String("scope")
-		inputDescription := c.String("description")
-		inputBreakingChange := c.String("breaking-change")
-
-		ctype, err := getCommitType(cfg, messageProcessor, inputType)
-		if err != nil {
+		if err := checkCleanWorkingTree(git, c.Bool("allow-dirty"), "", nil, out); err != nil {
 			return err
 		}
-
-		scope, err := getCommitScope(cfg, messageProcessor, inputScope, noScope)
-		if err != nil {
+		if err := checkTagHead(git, c); err != nil {
 			return err
 		}
 
-		subject, err := getCommitDescription(messageProcessor, inputDescription)
+		remote, err := getTagRemote(git, c)
 		if err != nil {
 			return err
 		}
 
-		fullBody, err := getCommitBody(noBody)
+		lastTag := git.LastTag()
+
+		ref, err := tagCommit(git, c)
 		if err != nil {
 			return err
 		}
-
-		issue, err := getCommitIssue(cfg, messageProcessor, git.Branch(), noIssue)
-		if err != nil {
+		if err := checkCommitAfterTag(git, ref, lastTag); err != nil {
 			return err
 		}
 
-		breakingChange, err := getCommitBreakingChange(noBreaking, inputBreakingChange)
+		_, nextVer, commits, err := nextVersion(c, git, semverProcessor, cfg.Versioning, lastTag, ref, out)
 		if err != nil {
 			return err
 		}
-
-		header, body, footer := messageProcessor.Format(sv.NewCommitMessage(ctype, scope, subject, fullBody, issue, breakingChange))
-
-		err = git.Commit(header, body, footer)
+		if len(commits) == 0 && !versionForced(c) {
+			return nothingToReleaseError(lastTag, "use --bump or --set-version to tag anyway")
+		}
+		if releaseBranch.Name != "" && !releaseBranch.Contains(*nextVer) {
+			return fmt.Errorf("version %s does not belong to release branch %s, last tag %s is from another version line", nextVer.String(), releaseBranch.Name, str(lastTag, "(none)"))
+		}
+		if nextVer, err = withBuildMetadata(git, c, cfg.Versioning, nextVer, ref); err != nil {
+			return err
+		}
+		hooks := newHooks(c, cfg.Hooks, repoPath, out)
+		env := hookEnv{Version: nextVer.String(), Tag: git.TagName(*nextVer)}
+		if err := hooks.run(hookPreTag, env); err != nil {
+			return err
+		}
+		tagname, err := createTag(git, c, remote, str(ref, "HEAD"), func() (string, error) { return git.TagAt(*nextVer, ref, remote) }, out)
+		var existsErr sv.TagExistsError
+		if errors.As(err, &existsErr) {
+			return tagExistsError(existsErr)
+		}
 		if err != nil {
-			return fmt.Errorf("error executing git commit, message: %v", err)
+			return fmt.Errorf("error generating tag version: %s, message: %v", nextVer.String(), withPushHint(err))
+		}
+		out.successf("%s", tagname)
+		if err := hooks.run(hookPostTag, hookEnv{Version: env.Version, Tag: tagname}); err != nil {
+			return postHooksError([]string{err.Error()})
 		}
 		return nil
 	}
 }
 
-func changelogHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, rnProcessor sv.ReleaseNoteProcessor, formatter sv.OutputFormatter) func(c *cli.Context) error {
-	return func(c *cli.Context) error {
-		tags, err := git.Tags()
-		if err != nil {
+// withBuildMetadata sets build metadata from --metadata flag or versioning.metadata-template config on version,
+// the hash placeholder is replaced by the hash of ref, HEAD if empty.
+func withBuildMetadata(git sv.Git, c *cli.Context, cfg sv.VersioningConfig, version *semver.Version, ref string) (*semver.Version, error) {
+	template := cfg.MetadataTemplate
+	if c.IsSet("metadata") {
+		template = c.String("metadata")
+	}
+	if template == "" || version == nil {
+		return version, nil
+	}
+
+	var hash string
+	if strings.Contains(template, sv.MetadataPlaceholderHash) {
+		var err error
+		if hash, err = git.ShortHash(str(ref, "HEAD")); err != nil {
+			return nil, fmt.Errorf("error getting %s hash for build metadata, message: %v", str(ref, "HEAD"), err)
+		}
+	}
+	metadata := sv.BuildMetadata(template, hash, git.Branch(), os.Getenv)
+	if metadata == "" {
+		return version, nil
+	}
+	withMetadata, err := version.SetMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid build metadata: %s, message: %v", metadata, err)
+	}
+	return &withMetadata, nil
+}
+
+// versionOverride returns the version forced by --bump or --set-version flags, nil if none is set.
+// Versions set with --set-version must be greater than current unless --allow-downgrade is set.
+func versionOverride(c *cli.Context, current *semver.Version) (*semver.Version, error) {
+	bump, setVersion := c.String("bump"), c.String("set-version")
+	switch {
+	case bump != "" && setVersion != "":
+		return nil, fmt.Errorf("cannot define bump and set-version flags together")
+	case bump != "":
+		var next semver.Version
+		switch bump {
+		case "major":
+			next = current.IncMajor()
+		case "minor":
+			next = current.IncMinor()
+		case "patch":
+			next = current.IncPatch()
+		default:
+			return nil, fmt.Errorf("invalid bump: %s, use: major, minor or patch", bump)
+		}
+		return &next, nil
+	case setVersion != "":
+		next, err := sv.ToVersion(setVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid set-version: %s, message: %v", setVersion, err)
+		}
+		if !next.GreaterThan(current) && !c.Bool("allow-downgrade") {
+			return nil, fmt.Errorf("version %s must be greater than current version %s, use --allow-downgrade to set it anyway", next.String(), current.String())
+		}
+		return next, nil
+	}
+	return nil, nil
+}
+
+func versionForced(c *cli.Context) bool {
+	return c.String("bump") != "" || c.String("set-version") != ""
+}
+
+// componentNextVersion returns the next version of component based on its commits, unless it is forced by --bump or --set-version flags.
+func componentNextVersion(c *cli.Context, monorepoProcessor sv.MonorepoProcessor, semverProcessor sv.SemVerCommitsProcessor, component sv.MonorepoComponent, commits []sv.GitCommitLog) (*semver.Version, bool, error) {
+	forced, err := versionOverride(c, component.CurrentVersion)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %v", component.Name, err)
+	}
+	if forced != nil {
+		return forced, true, nil
+	}
+	nextVer, updated := monorepoProcessor.NextVersion(component, commits, semverProcessor)
+	return nextVer, updated, nil
+}
+
+// componentVersion returns commits of component since its latest stable tag and its next version. Prerelease tags are not used as
+// baseline, so stable versions include changes already released on prerelease channels. With channel, the next version is a
+// prerelease numbered after the channel tags of the same version, e.g. 1.4.0-beta.2, and it's not updated without commits
+// since the last channel tag.
+func componentVersion(
+	c *cli.Context,
+	cache *componentCache,
+	monorepoProcessor sv.MonorepoProcessor,
+	semverProcessor sv.SemVerCommitsProcessor,
+	cfg sv.VersioningConfig,
+	repoPath string,
+	component sv.MonorepoComponent,
+	componentTags []sv.GitTag,
+	channel string,
+	ref string,
+	out *printer,
+) ([]sv.GitCommitLog, *semver.Version, bool, error) {
+	commits, err := cache.versionCommits(repoPath, component, sv.StableTags(componentTags, component.Name), ref, cfg, out)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("error getting commits for %s: %v", component.Name, err)
+	}
+	debugCommits(out, semverProcessor, commits)
+
+	base := component
+	if component.CurrentVersion.Prerelease() != "" {
+		base.CurrentVersion = sv.StableBaseVersion(componentTags, component.Name)
+	}
+	nextVer, updated, err := componentNextVersion(c, monorepoProcessor, semverProcessor, base, commits)
+	if err != nil || !updated {
+		return commits, nextVer, updated, err
+	}
+	if component.MaxBump != "" && !versionForced(c) && cfg.Scheme != sv.VersioningSchemeCalVer {
+		if nextVer, err = capComponentBump(c, semverProcessor, base, commits, nextVer, out); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	if release := semver.New(component.CurrentVersion.Major(), component.CurrentVersion.Minor(), component.CurrentVersion.Patch(), "", ""); component.CurrentVersion.Prerelease() != "" && nextVer.LessThan(release) {
+		nextVer = release // a prerelease of the current version is already released, e.g. 1.4.0 after 1.4.0-beta.2
+	}
+	if channel == "" || c.String("set-version") != "" {
+		return commits, nextVer, true, nil
+	}
+
+	prerelease, lastTag := sv.NextPrerelease(*nextVer, channel, componentTags, component.Name)
+	if lastTag != "" {
+		since, serr := cache.versionCommits(repoPath, component, []sv.GitTag{{Name: lastTag}}, ref, cfg, out)
+		if serr != nil {
+			return nil, nil, false, fmt.Errorf("error getting commits for %s: %v", component.Name, serr)
+		}
+		if len(since) == 0 {
+			out.debugf("component %s: no commits since prerelease tag %s", component.Name, lastTag)
+			return commits, component.CurrentVersion, false, nil
+		}
+	}
+	return commits, &prerelease, true, nil
+}
+
+// componentBaseline describes the starting point of componentVersion, the latest stable component tag or all commits of the component.
+func componentBaseline(componentTags []sv.GitTag, componentName string) string {
+	if lastTag := sv.LatestTag(sv.StableTags(componentTags, componentName)); lastTag != "" {
+		return "tag " + lastTag
+	}
+	return "all commits"
+}
+
+// checkComponentDowngrade refuses a next version lower than the version on the component versioning file, e.g. calculated
+// from an older tag after the latest one was deleted or filtered out, unless --allow-downgrade is set. The error shows the
+// baseline that led to the version.
+func checkComponentDowngrade(c *cli.Context, component sv.MonorepoComponent, nextVer *semver.Version, baseline string) error {
+	if nextVer.Compare(component.CurrentVersion) >= 0 || c.Bool("allow-downgrade") {
+		return nil
+	}
+	return fmt.Errorf("%s: calculated version %s is lower than current version %s, calculated from %s, check tags and monorepo config or use --allow-downgrade to use it anyway",
+		component.Name, nextVer.String(), component.CurrentVersion.String(), baseline)
+}
+
+// bumpRanks orders bump levels, see bumpLevel.
+var bumpRanks = map[string]int{"none": 0, "patch": 1, "minor": 2, "major": 3}
+
+// capComponentBump checks the version update of component against its max-bump config. An update above max-bump fails listing
+// the commits requiring it, unless --allow-capped-bump is set, then the update is limited to max-bump with a warning.
+func capComponentBump(c *cli.Context, semverProcessor sv.SemVerCommitsProcessor, component sv.MonorepoComponent, commits []sv.GitCommitLog, nextVer *semver.Version, out *printer) (*semver.Version, error) {
+	level := bumpLevel(component.CurrentVersion, nextVer)
+	if bumpRanks[level] <= bumpRanks[component.MaxBump] {
+		return nextVer, nil
+	}
+	var offending []string
+	for _, commit := range commits {
+		if bump := semverProcessor.Explain([]sv.GitCommitLog{commit}).Bump; bumpRanks[bump] > bumpRanks[component.MaxBump] {
+			offending = append(offending, fmt.Sprintf("%s %s (%s)", commit.Hash, commitSubject(commit.Message), bump))
+		}
+	}
+	if !c.Bool("allow-capped-bump") {
+		return nil, fmt.Errorf("%s: %s update exceeds max-bump %s, required by:\n  %s\nuse --allow-capped-bump to limit the update to %s",
+			component.Name, level, component.MaxBump, strings.Join(offending, "\n  "), component.MaxBump)
+	}
+
+	var capped semver.Version
+	switch component.MaxBump {
+	case "minor":
+		capped = component.CurrentVersion.IncMinor()
+	default:
+		capped = component.CurrentVersion.IncPatch()
+	}
+	out.warnf("%s: %s update limited to %s by max-bump, version %s instead of %s, required by: %s",
+		component.Name, level, component.MaxBump, capped.String(), nextVer.String(), strings.Join(offending, ", "))
+	return &capped, nil
+}
+
+// prereleaseChannel returns the prerelease channel of the current branch by monorepo.prerelease.branch-map config, empty if not mapped.
+func prereleaseChannel(git sv.Git, cfg sv.MonorepoConfig, out *printer) (string, error) {
+	if len(cfg.Prerelease.BranchMap) == 0 {
+		return "", nil
+	}
+	branch := git.Branch()
+	channel, err := sv.PrereleaseChannel(cfg.Prerelease.BranchMap, branch)
+	if err != nil {
+		return "", err
+	}
+	if channel != "" {
+		out.debugf("branch %s is on prerelease channel %s", branch, channel)
+	}
+	return channel, nil
+}
+
+// inChannel checks if version is a prerelease of channel, or a stable version if channel is empty.
+func inChannel(version *semver.Version, channel string) bool {
+	if channel == "" {
+		return version.Prerelease() == ""
+	}
+	return strings.HasPrefix(version.Prerelease(), channel+".")
+}
+
+// selectComponents filters components by --component flag names, every name must match a component.
+func selectComponents(components []sv.MonorepoComponent, names []string) ([]sv.MonorepoComponent, error) {
+	if len(names) == 0 {
+		return components, nil
+	}
+	selected := make([]sv.MonorepoComponent, 0, len(names))
+	for _, name := range names {
+		component, found := findComponent(name, components)
+		if !found {
+			return nil, fmt.Errorf("component: %s not found", name)
+		}
+		selected = append(selected, component)
+	}
+	return selected, nil
+}
+
+// dateFlagLayout layout of --since and --until flags.
+const dateFlagLayout = "2006-01-02"
+
+// dateFilter interval defined by --since and --until flags, both days are included and zero values are unbounded.
+type dateFilter struct {
+	since time.Time
+	until time.Time // first instant after --until day.
+}
+
+// newDateFilter parses --since and --until flags on local timezone, or UTC if --utc is set.
+func newDateFilter(c *cli.Context) (dateFilter, error) {
+	location := time.Local
+	if c.Bool("utc") {
+		location = time.UTC
+	}
+
+	var filter dateFilter
+	if since := c.String("since"); since != "" {
+		date, err := time.ParseInLocation(dateFlagLayout, since, location)
+		if err != nil {
+			return dateFilter{}, fmt.Errorf("invalid since date: %s, expected format: YYYY-MM-DD", since)
+		}
+		filter.since = date
+	}
+	if until := c.String("until"); until != "" {
+		date, err := time.ParseInLocation(dateFlagLayout, until, location)
+		if err != nil {
+			return dateFilter{}, fmt.Errorf("invalid until date: %s, expected format: YYYY-MM-DD", until)
+		}
+		filter.until = date.AddDate(0, 0, 1)
+	}
+	if !filter.since.IsZero() && !filter.until.IsZero() && !filter.since.Before(filter.until) {
+		return dateFilter{}, fmt.Errorf("since date: %s must not be after until date: %s", c.String("since"), c.String("until"))
+	}
+	return filter, nil
+}
+
+func (f dateFilter) includes(date time.Time) bool {
+	return (f.since.IsZero() || !date.Before(f.since)) && (f.until.IsZero() || date.Before(f.until))
+}
+
+// versionRange changelog --from-version and --to-version bounds, inclusive, a nil bound is open.
+type versionRange struct {
+	from  *semver.Version
+	to    *semver.Version
+	fuzzy bool // bounds without tag use the nearest tag outside the range, see selectTags
+}
+
+// newVersionRange parses --from-version, --to-version and --fuzzy flags.
+func newVersionRange(c *cli.Context) (versionRange, error) {
+	r := versionRange{fuzzy: c.Bool("fuzzy")}
+	if from := c.String("from-version"); from != "" {
+		version, err := sv.ToVersion(from)
+		if err != nil {
+			return versionRange{}, fmt.Errorf("invalid from version: %s, message: %v", from, err)
+		}
+		r.from = version
+	}
+	if to := c.String("to-version"); to != "" {
+		version, err := sv.ToVersion(to)
+		if err != nil {
+			return versionRange{}, fmt.Errorf("invalid to version: %s, message: %v", to, err)
+		}
+		r.to = version
+	}
+	if r.from != nil && r.to != nil && r.from.GreaterThan(r.to) {
+		return versionRange{}, fmt.Errorf("from version: %s must not be greater than to version: %s", r.from, r.to)
+	}
+	if r.fuzzy && !r.enabled() {
+		return versionRange{}, fmt.Errorf("--fuzzy requires --from-version or --to-version")
+	}
+	return r, nil
+}
+
+func (r versionRange) enabled() bool {
+	return r.from != nil || r.to != nil
+}
+
+// selectTags returns the tags with a version, read from tag names with version, sorted by version descending, and the
+// indexes of the first and last tag in range. A bound must be the version of a tag, with fuzzy the nearest tag outside
+// the range is used instead, at or below from and at or above to, or the nearest tag inside it if there is none.
+func (r versionRange) selectTags(tags []sv.GitTag, version func(tag string) *semver.Version, out *printer) ([]sv.GitTag, int, int, error) {
+	type versionedTag struct {
+		tag     sv.GitTag
+		version *semver.Version
+	}
+	var versioned []versionedTag
+	for _, tag := range tags {
+		if v := version(tag.Name); v != nil {
+			versioned = append(versioned, versionedTag{tag: tag, version: v})
+		}
+	}
+	sort.SliceStable(versioned, func(i, j int) bool {
+		if !versioned[i].version.Equal(versioned[j].version) {
+			return versioned[i].version.GreaterThan(versioned[j].version)
+		}
+		return versioned[i].tag.Date.After(versioned[j].tag.Date)
+	})
+
+	// bound returns the index of the tag with version, the newest one for to and the oldest one for from, or of the nearest tag.
+	bound := func(name string, version *semver.Version, isFrom bool) (int, error) {
+		found, outside, inside := -1, -1, -1
+		for i, t := range versioned {
+			switch {
+			case t.version.Equal(version) && (isFrom || found < 0):
+				found = i
+			case t.version.GreaterThan(version) == isFrom && (isFrom || inside < 0):
+				inside = i // first tag below to, last tag above from
+			case t.version.GreaterThan(version) != isFrom && (!isFrom || outside < 0):
+				outside = i // last tag above to, first tag below from
+			}
+		}
+		if found >= 0 {
+			return found, nil
+		}
+		if !r.fuzzy {
+			return 0, fmt.Errorf("%s version: %s has no tag, use --fuzzy to use the nearest tag", name, version)
+		}
+		nearest := outside
+		if nearest < 0 {
+			nearest = inside
+		}
+		if nearest < 0 {
+			return 0, fmt.Errorf("%s version: %s has no tag, no tag with a version found", name, version)
+		}
+		out.statusf("%s version: %s has no tag, using nearest tag %s", name, version, versioned[nearest].tag.Name)
+		return nearest, nil
+	}
+
+	first, last := 0, len(versioned)-1
+	var err error
+	if r.to != nil {
+		if first, err = bound("to", r.to, false); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	if r.from != nil {
+		if last, err = bound("from", r.from, true); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	result := make([]sv.GitTag, len(versioned))
+	for i, t := range versioned {
+		result[i] = t.tag
+	}
+	return result, first, last, nil
+}
+
+// parsedVersion returns the version of tag, nil if it is not a version.
+func parsedVersion(tag string) *semver.Version {
+	version, err := sv.ToVersion(tag)
+	if err != nil {
+		return nil
+	}
+	return version
+}
+
+// tagVersion parses the version of tag, build metadata is kept only if includeMetadata is set.
+func tagVersion(tag string, includeMetadata bool) *semver.Version {
+	if includeMetadata {
+		if version, err := semver.NewVersion(tag); err == nil {
+			return version
+		}
+	}
+	version, _ := sv.ToVersion(tag)
+	return version
+}
+
+// checkCleanWorkingTree fails if tracked files have uncommitted changes unless allowDirty is set,
+// changes on components versioning files are always warned since versions are read from working tree.
+func checkCleanWorkingTree(git sv.Git, allowDirty bool, repoPath string, components []sv.MonorepoComponent, out *printer) error {
+	clean, dirty, err := git.IsClean()
+	if err != nil {
+		return fmt.Errorf("error checking working tree status, message: %v", err)
+	}
+	if clean {
+		return nil
+	}
+
+	for _, component := range components {
+		for _, file := range component.Files() {
+			relFile, rerr := filepath.Rel(repoPath, file)
+			if rerr == nil && containsString(dirty, filepath.ToSlash(relFile)) {
+				out.warnf("%s: versioning file %s has uncommitted changes", component.Name, relFile)
+			}
+		}
+	}
+	if allowDirty {
+		return nil
+	}
+	return fmt.Errorf("working tree has uncommitted changes: %s, commit or stash them or use --allow-dirty", strings.Join(dirty, ", "))
+}
+
+// createTag creates a tag on target with tagFn, if the tag already exists and --force-retag is set, the tag is deleted,
+// locally and from remote, and created again on target after confirmation, skipped with --yes.
+func createTag(git sv.Git, c *cli.Context, remote, target string, tagFn func() (string, error), out *printer) (string, error) {
+	tagName, err := tagFn()
+	var existsErr sv.TagExistsError
+	if !errors.As(err, &existsErr) || !c.Bool("force-retag") {
+		return tagName, err
+	}
+
+	if !c.Bool("yes") {
+		confirmed, perr := promptConfirm(retagQuestion(existsErr, target), out)
+		if perr != nil {
+			return tagName, perr
+		}
+		if !confirmed {
+			return tagName, err
+		}
+	}
+	if derr := git.DeleteTag(existsErr.Tag, remote); derr != nil {
+		return tagName, fmt.Errorf("error deleting tag %s, message: %v", existsErr.Tag, derr)
+	}
+	return tagFn()
+}
+
+// retagQuestion asks to move the existing tag to target, the commit or tag the new tag is created on.
+func retagQuestion(existsErr sv.TagExistsError, target string) string {
+	return fmt.Sprintf("tag %s already exists on commit %s, move it to %s?", existsErr.Tag, existsErr.Commit, target)
+}
+
+// tagCommit returns the hash of --commit flag, the commit to tag instead of HEAD, empty if not set.
+// The commit must be reachable from HEAD.
+func tagCommit(git sv.Git, c *cli.Context) (string, error) {
+	commit := c.String("commit")
+	if commit == "" {
+		return "", nil
+	}
+	hash, err := git.ShortHash(commit)
+	if err != nil {
+		return "", fmt.Errorf("commit %s not found, message: %v", commit, err)
+	}
+	onHead, err := git.IsAncestor(hash, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("error checking if commit %s is reachable from HEAD, message: %v", commit, err)
+	}
+	if !onHead {
+		return "", fmt.Errorf("commit %s is not reachable from HEAD, check out a branch that contains it", commit)
+	}
+	return hash, nil
+}
+
+// checkCommitAfterTag refuses to tag commit when it is not a descendant of the last tag, the new version would not
+// contain the changes already released. Nothing is checked if commit or tag are empty.
+func checkCommitAfterTag(git sv.Git, commit, tag string) error {
+	if commit == "" || tag == "" {
+		return nil
+	}
+	after, err := git.IsAncestor("refs/tags/"+tag, commit)
+	if err != nil {
+		return fmt.Errorf("error checking if commit %s comes after tag %s, message: %v", commit, tag, err)
+	}
+	if !after {
+		return fmt.Errorf("commit %s is not a descendant of last tag %s, only commits after the last tag can be tagged", commit, tag)
+	}
+	return nil
+}
+
+// checkTagHead refuses to tag a detached HEAD or a HEAD behind its upstream branch, unless --allow-detached or --allow-behind are set.
+func checkTagHead(git sv.Git, c *cli.Context) error {
+	detached, err := git.IsDetached()
+	if err != nil {
+		return fmt.Errorf("error checking detached HEAD, message: %v", err)
+	}
+	if detached {
+		if !c.Bool("allow-detached") {
+			return fmt.Errorf("HEAD is detached, use --allow-detached to tag the checked out commit")
+		}
+		return nil
+	}
+
+	if c.Bool("allow-behind") {
+		return nil
+	}
+	upstream, behind, err := git.BehindUpstream()
+	if err != nil {
+		return fmt.Errorf("error comparing HEAD with upstream branch, message: %v", err)
+	}
+	if behind > 0 {
+		return fmt.Errorf("HEAD is %d commit(s) behind %s, pull before tagging or use --allow-behind to tag anyway", behind, upstream)
+	}
+	return nil
+}
+
+// withPushHint adds the command to push tags created locally when push failed.
+func withPushHint(err error) error {
+	var tagsErr sv.TagsPushError
+	if errors.As(err, &tagsErr) {
+		return fmt.Errorf("%v, push them with: git push %s %s", tagsErr, tagsErr.Remote, strings.Join(tagsErr.Tags(), " "))
+	}
+	var pushErr sv.TagPushError
+	if !errors.As(err, &pushErr) {
+		return err
+	}
+	return fmt.Errorf("%v, push it with: git push %s %s", pushErr, pushErr.Remote, pushErr.Tag)
+}
+
+// maxPushArgsLength limits the length of refs pushed by a single git push, below command line limits of every platform.
+const maxPushArgsLength = 30000
+
+// pushTagBatches pushes tags to remote with one git push per batch of batchSize tags, 0 pushes every tag at once,
+// batches are also split at maxPushArgsLength. Failed tags of every batch are returned as one sv.TagsPushError.
+func pushTagBatches(git sv.Git, remote string, tags []string, batchSize int) error {
+	result := sv.TagsPushError{Remote: remote}
+	for _, batch := range tagBatches(tags, batchSize) {
+		err := git.PushTags(remote, batch)
+		var pushErr sv.TagsPushError
+		switch {
+		case errors.As(err, &pushErr):
+			result.Failed = append(result.Failed, pushErr.Failed...)
+		case err != nil:
+			return err
+		}
+	}
+	if len(result.Failed) > 0 {
+		return result
+	}
+	return nil
+}
+
+func tagBatches(tags []string, batchSize int) [][]string {
+	var batches [][]string
+	var batch []string
+	length := 0
+	for _, tag := range tags {
+		size := len("refs/tags/") + len(tag) + 1
+		if len(batch) > 0 && ((batchSize > 0 && len(batch) >= batchSize) || length+size > maxPushArgsLength) {
+			batches = append(batches, batch)
+			batch, length = nil, 0
+		}
+		batch = append(batch, tag)
+		length += size
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// nothingToReleaseError is returned when there are no commits since lastTag, the next version would be the tagged one.
+func nothingToReleaseError(lastTag, hint string) error {
+	if lastTag == "" {
+		return fmt.Errorf("nothing to release, no commits found, %s", hint)
+	}
+	return fmt.Errorf("nothing to release since %s, %s", lastTag, hint)
+}
+
+func releaseNotesHint(lastTag string) string {
+	if lastTag == "" {
+		return "commit something first"
+	}
+	return fmt.Sprintf("use --tag %s to get its release notes", lastTag)
+}
+
+// tagExistsError returns the error for tags that already exist, git-sv exits with exitCodeTagExists.
+func tagExistsError(errs ...sv.TagExistsError) error {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return exitCodeError{
+		code: exitCodeTagExists,
+		err:  fmt.Errorf("%s, use --force-retag to move to HEAD", strings.Join(messages, "; ")),
+	}
+}
+
+// absPaths resolves paths from current working directory, git commands are executed on repository root.
+func absPaths(paths []string) ([]string, error) {
+	result := make([]string, len(paths))
+	for i, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %s, message: %v", path, err)
+		}
+		result[i] = abs
+	}
+	return result, nil
+}
+
+func stageCommitFiles(git sv.Git, all bool, paths []string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+
+	if all {
+		if err := git.Add(); err != nil {
+			return fmt.Errorf("error staging tracked files, message: %v", err)
+		}
+	}
+	if len(paths) > 0 {
+		if err := git.Add(paths...); err != nil {
+			return fmt.Errorf("error staging files: %s, message: %v", strings.Join(paths, ", "), err)
+		}
+	}
+
+	staged, err := git.HasStagedChanges()
+	if err != nil {
+		return fmt.Errorf("error checking staged changes, message: %v", err)
+	}
+	if !staged {
+		return fmt.Errorf("no changes added to commit, use \"git add\" or the --all/--add flags to stage changes")
+	}
+	return nil
+}
+
+func confirmStagedChanges(git sv.Git, skip bool, out *printer) (bool, error) {
+	if skip {
+		return true, nil
+	}
+
+	stat, err := git.StagedDiffStat()
+	if err != nil {
+		return false, fmt.Errorf("error getting staged changes, message: %v", err)
+	}
+	out.println(stat)
+	return promptConfirmDefaultYes("commit staged changes?", out)
+}
+
+func getCommitType(cfg Config, p sv.MessageProcessor, input string, out *printer) (string, error) {
+	if input == "" {
+		t, err := promptType(cfg.CommitMessage.Types, out)
+		return t.Type, err
+	}
+	return input, p.ValidateType(input)
+}
+
+func getCommitScope(cfg Config, p sv.MessageProcessor, input string, noScope bool, out *printer) (string, error) {
+	if input == "" && !noScope {
+		return promptScope(cfg.CommitMessage.Scope.Values, cfg.CommitMessage.Scope.Required, out)
+	}
+	return input, p.ValidateScope(input)
+}
+
+// getCommitDescription asks for the description until it has no banned or misspelled words, spelling is nil without --check-spelling.
+func getCommitDescription(cfg Config, p sv.MessageProcessor, spelling *sv.SpellChecker, input string, out *printer) (string, error) {
+	if input != "" {
+		if err := p.ValidateDescription(input); err != nil {
+			return input, err
+		}
+		if violations := wordViolations(cfg.CommitMessage.BannedWords, spelling, input); len(violations) > 0 {
+			return input, violations[0]
+		}
+		return input, nil
+	}
+	for {
+		description, err := promptSubject(out)
+		if err != nil {
+			return "", err
+		}
+		violations := wordViolations(cfg.CommitMessage.BannedWords, spelling, description)
+		if len(violations) == 0 {
+			return description, nil
+		}
+		for _, violation := range violations {
+			out.warnf("%v", violation)
+		}
+	}
+}
+
+// wordViolations returns banned and, if spelling is not nil, misspelled words of text.
+func wordViolations(bannedWords []sv.CommitMessageBannedWordConfig, spelling *sv.SpellChecker, text string) []error {
+	violations := sv.BannedWordViolations(bannedWords, text)
+	if spelling != nil {
+		violations = append(violations, spelling.Violations(text)...)
+	}
+	return violations
+}
+
+// spellChecker returns the spell checker with the project dictionary when --check-spelling is used, nil otherwise.
+func spellChecker(c *cli.Context, cfg sv.CommitMessageSpellingConfig, repoPath string) (*sv.SpellChecker, error) {
+	if !c.Bool("check-spelling") {
+		return nil, nil
+	}
+	if cfg.Dictionary == "" {
+		return sv.NewSpellChecker(nil), nil
+	}
+	path := cfg.Dictionary
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoPath, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading commit-message.spelling.dictionary, message: %v", err)
+	}
+	return sv.NewSpellChecker(sv.ParseDictionary(string(content))), nil
+}
+
+func getCommitBody(noBody bool, out *printer) (string, error) {
+	if noBody {
+		return "", nil
+	}
+
+	var fullBody strings.Builder
+	for body, err := promptBody(out); body != "" || err != nil; body, err = promptBody(out) {
+		if err != nil {
+			return "", err
+		}
+		if fullBody.Len() > 0 {
+			fullBody.WriteString("\n")
+		}
+		if body != "" {
+			fullBody.WriteString(body)
+		}
+	}
+	return fullBody.String(), nil
+}
+
+func getCommitEdit(edit, noBody bool, out *printer) (bool, error) {
+	if edit || noBody {
+		return edit, nil
+	}
+	return promptConfirm("open editor to write commit body?", out)
+}
+
+func getCommitIssue(cfg Config, p sv.MessageProcessor, branch string, noIssue bool, out *printer) (string, error) {
+	if len(cfg.CommitMessage.Issue.Trackers) > 0 {
+		return "", nil // replaced by getCommitIssues
+	}
+	branchIssue, err := p.IssueID(branch)
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.CommitMessage.IssueFooterConfig().Key == "" || cfg.CommitMessage.Issue.Regex == "" {
+		return "", nil
+	}
+
+	if noIssue {
+		return branchIssue, nil
+	}
+
+	return promptIssueID("issue id", cfg.CommitMessage.Issue.Regex, branchIssue, out)
+}
+
+// getCommitIssues returns issues of each commit-message.issue.trackers, asking for ids separated by comma, issues found on branch are
+// used as default answer or, with noIssue, without asking.
+func getCommitIssues(cfg Config, branch string, noIssue bool, out *printer) ([]sv.IssueReferences, error) {
+	var result []sv.IssueReferences
+	for _, tracker := range cfg.CommitMessage.Issue.Trackers {
+		var ids []string
+		if !cfg.Branches.DisableIssue {
+			branchIDs, err := tracker.MatchIssues(branch)
+			if err != nil {
+				return nil, err
+			}
+			ids = branchIDs
+		}
+		if !noIssue {
+			input, err := promptIssueIDs(tracker.Key+" issue ids, separated by comma", tracker.Regex, strings.Join(ids, ", "), tracker.Required, out)
+			if err != nil {
+				return nil, err
+			}
+			ids = splitList(input)
+		}
+		if len(ids) > 0 {
+			result = append(result, tracker.References(ids))
+		}
+	}
+	return result, nil
+}
+
+// getCommitBreakingChange returns if the commit has a breaking change and its description, commit-message.breaking.prompt
+// config decides when it is asked. With exclamation style the commit description describes the breaking change, so only a
+// confirmation is asked.
+func getCommitBreakingChange(cfg sv.CommitMessageBreakingConfig, ctype string, noBreaking bool, input string, out *printer) (bool, string, error) {
+	if noBreaking {
+		return false, "", nil
+	}
+
+	if strings.TrimSpace(input) != "" {
+		if cfg.Style == sv.BreakingStyleExclamation {
+			return false, "", fmt.Errorf("breaking change description is not written with commit-message.breaking.style %s, the commit description describes it, use style %s or %s to add it", cfg.Style, sv.BreakingStyleFooter, sv.BreakingStyleBoth)
+		}
+		return true, input, nil
+	}
+	if !cfg.Prompts(ctype) {
+		return false, "", nil
+	}
+
+	hasBreakingChanges, err := promptConfirm("has breaking change?", out)
+	if err != nil {
+		return false, "", err
+	}
+	if !hasBreakingChanges {
+		return false, "", nil
+	}
+	if cfg.Style == sv.BreakingStyleExclamation {
+		return true, "", nil
+	}
+
+	message, err := promptBreakingChanges(out)
+	return err == nil, message, err
+}
+
+// getCommitFooters returns footers from key=value inputs, required footers without input are prompted.
+func getCommitFooters(cfg Config, inputs []string, out *printer) ([]string, error) {
+	var footers []string
+	values := make(map[string]string)
+	for _, input := range inputs {
+		key, value, found := strings.Cut(input, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid footer %s, use key=value", input)
+		}
+		values[key] = value
+		footers = append(footers, fmt.Sprintf("%s: %s", key, value))
+	}
+
+	for _, key := range cfg.CommitMessage.RequiredFooters {
+		if _, exists := values[key]; exists {
+			continue
+		}
+		value, err := promptText(key, "^.+$", "", out)
+		if err != nil {
+			return nil, err
+		}
+		footers = append(footers, fmt.Sprintf("%s: %s", key, strings.TrimSpace(value)))
+	}
+	return footers, nil
+}
+
+// applyCommitPreset returns config and message processor with commit-message presets applied. The preset is selected by name
+// or by staged files, if there are no staged files presets are selected by branch. When changes match more than one preset
+// the strictest rules of all of them are used.
+func applyCommitPreset(cfg Config, git sv.Git, messageProcessor sv.MessageProcessor, name string, out *printer) (Config, sv.MessageProcessor, error) {
+	var names []string
+	switch {
+	case name != "":
+		names = []string{name}
+	case len(cfg.CommitMessage.Presets) > 0:
+		files, err := git.StagedFiles()
+		if err != nil {
+			out.warnf("could not get staged files, selecting commit-message preset by branch, message: %v", err)
+		}
+		names, err = sv.SelectPresets(cfg.CommitMessage.Presets, files, git.Branch())
+		if err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	switch {
+	case len(names) == 0:
+		return cfg, messageProcessor, nil
+	case len(names) > 1:
+		out.warnf("changes match commit-message presets %s, using the strictest rules of all of them", strings.Join(names, ", "))
+	default:
+		out.statusf("using commit-message preset %s", names[0])
+	}
+
+	messageCfg, err := cfg.CommitMessage.WithPresets(names...)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	cfg.CommitMessage = messageCfg
+	return cfg, sv.NewMessageProcessor(messageCfg, cfg.Branches), nil
+}
+
+func commitHandler(cfg Config, git sv.Git, messageProcessor sv.MessageProcessor, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		noBreaking := c.Bool("no-breaking")
+		noBody := c.Bool("no-body")
+		noIssue := c.Bool("no-issue")
+		noScope := c.Bool("no-scope")
+		edit := c.Bool("edit")
+		dryRun := c.Bool("dry-run")
+		inputType := c.String("type")
+		inputScope := c.String("scope")
+		inputDescription := c.String("description")
+		inputBreakingChange := c.String("breaking-change")
+
+		addPaths, err := absPaths(c.StringSlice("add"))
+		if err != nil {
+			return err
+		}
+		if err := stageCommitFiles(git, c.Bool("all"), addPaths, dryRun); err != nil {
+			return err
+		}
+
+		cfg, messageProcessor, err := applyCommitPreset(cfg, git, messageProcessor, c.String("preset"), out)
+		if err != nil {
+			return err
+		}
+		spelling, err := spellChecker(c, cfg.CommitMessage.Spelling, repoPath)
+		if err != nil {
+			return err
+		}
+
+		ctype, err := getCommitType(cfg, messageProcessor, inputType, out)
+		if err != nil {
+			return err
+		}
+
+		scope, err := getCommitScope(cfg, messageProcessor, inputScope, noScope, out)
+		if err != nil {
+			return err
+		}
+
+		subject, err := getCommitDescription(cfg, messageProcessor, spelling, inputDescription, out)
+		if err != nil {
+			return err
+		}
+
+		edit, err = getCommitEdit(edit, noBody, out)
+		if err != nil {
+			return err
+		}
+
+		var fullBody string
+		if !edit {
+			fullBody, err = getCommitBody(noBody, out)
+			if err != nil {
+				return err
+			}
+		}
+
+		issue, err := getCommitIssue(cfg, messageProcessor, git.Branch(), noIssue, out)
+		if err != nil {
+			return err
+		}
+
+		issues, err := getCommitIssues(cfg, git.Branch(), noIssue, out)
+		if err != nil {
+			return err
+		}
+
+		breaking, breakingChange, err := getCommitBreakingChange(cfg.CommitMessage.Breaking, ctype, noBreaking, inputBreakingChange, out)
+		if err != nil {
+			return err
+		}
+
+		footers, err := getCommitFooters(cfg, c.StringSlice("footer"), out)
+		if err != nil {
+			return err
+		}
+
+		msg := sv.NewCommitMessage(ctype, scope, subject, fullBody, issue, breakingChange)
+		msg.IsBreakingChange = breaking
+		msg.Issues = issues
+		header, body, footer := messageProcessor.Format(msg)
+		if len(footers) > 0 {
+			footer = strings.Trim(footer+"\n"+strings.Join(footers, "\n"), "\n")
+		}
+
+		if edit {
+			header, body, footer, err = getCommitMessageFromEditor(messageProcessor, header, body, footer, out)
+			if err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			out.println(joinCommitMessage(header, body, footer))
+			return nil
+		}
+
+		confirmed, err := confirmStagedChanges(git, c.Bool("yes") || out.promptsDisabled != "", out)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("commit aborted")
+		}
+
+		err = git.Commit(header, body, footer)
+		if err != nil {
+			return fmt.Errorf("error executing git commit, message: %v", err)
+		}
+		return nil
+	}
+}
+
+func changelogHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, rnProcessor sv.ReleaseNoteProcessor, formatter sv.OutputFormatter, cfg Config, clock sv.Clock, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		tags, err := git.Tags()
+		if err != nil {
+			return err
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			return tags[i].Date.After(tags[j].Date)
+		})
+
+		type release struct {
+			version     *semver.Version
+			tag         string
+			previousTag string
+			date        time.Time
+			commits     []sv.GitCommitLog
+			loaded      bool // commits of tagged releases are loaded when the release note is created
+		}
+		var releases []release
+
+		size := c.Int("size")
+		all := c.Bool("all")
+		addNextVersion := c.Bool("add-next-version")
+		semanticVersionOnly := c.Bool("semantic-version-only")
+		includeMetadata := c.Bool("include-metadata")
+		dates, err := newDateFilter(c)
+		if err != nil {
+			return err
+		}
+		versions, err := newVersionRange(c)
+		if err != nil {
+			return err
+		}
+
+		start, end := 0, len(tags)-1
+		if versions.enabled() {
+			// tags in range are sorted by version, each one is compared to the previous version.
+			if tags, start, end, err = versions.selectTags(tags, parsedVersion, out); err != nil {
+				return err
+			}
+			all = true
+		}
+
+		if addNextVersion && versions.to == nil && dates.includes(clock.Now()) {
+			rnVersion, updated, lastTag, date, commits, uerr := getNextVersionInfo(git, semverProcessor, clock, nil, out)
+			if uerr != nil {
+				return uerr
+			}
+			if updated {
+				releases = append(releases, release{version: rnVersion, previousTag: lastTag, date: date, commits: commits, loaded: true})
+			}
+		}
+		count := 0
+		for i := start; i <= end; i++ {
+			tag := tags[i]
+			if !dates.includes(tag.Date) {
+				continue
+			}
+			if !all && count >= size {
+				break
+			}
+			count++
+
+			previousTag := ""
+			if i+1 < len(tags) {
+				previousTag = tags[i+1].Name
+			}
+
+			if semanticVersionOnly && !sv.IsValidVersion(tag.Name) {
+				continue
+			}
+			releases = append(releases, release{version: tagVersion(tag.Name, includeMetadata), tag: tag.Name, previousTag: previousTag, date: tag.Date})
+		}
+
+		loadCommits := func(r *release) error {
+			if r.loaded {
+				return nil
+			}
+			commits, lerr := git.Log(sv.NewLogRange(sv.TagRange, r.previousTag, r.tag))
+			if lerr != nil {
+				return fmt.Errorf("error getting git log from tag: %s, message: %v", r.tag, lerr)
+			}
+			r.commits, r.loaded = commits, true
+			return nil
+		}
+
+		// cherry-picks are detected across releases, commits of every release are kept in memory.
+		if cfg.ReleaseNotes.DedupeCherryPicks {
+			releaseCommits := make([]sv.ReleaseCommits, len(releases))
+			for i := range releases {
+				if err := loadCommits(&releases[i]); err != nil {
+					return err
+				}
+				version := releases[i].tag
+				if version == "" {
+					version = releases[i].version.String()
+				}
+				releaseCommits[i] = sv.ReleaseCommits{Version: version, Commits: releases[i].commits}
+			}
+			if releaseCommits, err = sv.DedupeCherryPicks(releaseCommits, cfg.ReleaseNotes.CherryPickPolicy, git.PatchID); err != nil {
+				return fmt.Errorf("error detecting cherry-picked commits, message: %v", err)
+			}
+			for i := range releases {
+				releases[i].commits = releaseCommits[i].Commits
+			}
+		}
+
+		next := 0
+		var logErr error
+		err = printChangelog(out, formatter, func() (sv.ReleaseNote, bool, error) {
+			if next >= len(releases) {
+				return sv.ReleaseNote{}, false, nil
+			}
+			r := &releases[next]
+			next++
+			if logErr = loadCommits(r); logErr != nil {
+				return sv.ReleaseNote{}, false, logErr
+			}
+			releaseNote := rnProcessor.Create(r.version, r.tag, r.previousTag, r.date, r.commits)
+			r.commits = nil // release note is formatted, commits are no longer needed
+			return releaseNote, true, nil
+		})
+		if logErr != nil {
+			return logErr
+		}
+		if err != nil {
+			return fmt.Errorf("could not format changelog, message: %v", err)
+		}
+		return nil
+	}
+}
+
+func validateCommitMessageHandler(cfg Config, git sv.Git, messageProcessor sv.MessageProcessor, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		branch := git.Branch()
+		detached, derr := git.IsDetached()
+
+		if messageProcessor.SkipBranch(branch, derr == nil && detached) {
+			out.warnf("commit message validation skipped, branch in ignore list or detached...")
+			return nil
+		}
+
+		if source := c.String("source"); source == "merge" {
+			out.warnf("commit message validation skipped, ignoring source: %s...", source)
+			return nil
+		}
+
+		cfg, messageProcessor, err := applyCommitPreset(cfg, git, messageProcessor, "", out)
+		if err != nil {
+			return err
+		}
+
+		filepath, err := commitMessageFile(c.String("path"), c.String("file"))
+		if err != nil {
+			return err
+		}
+
+		content, err := readFile(filepath)
+		if err != nil {
+			return fmt.Errorf("failed to read commit message, error: %s", err.Error())
+		}
+		if strings.TrimSpace(content) == "" {
+			return fmt.Errorf("commit message file %s is empty, check --path and --file flags", filepath)
+		}
+		// help added by a previous failed validation is not part of the message.
+		commitMessage, hinted := removeEditHint(content)
+
+		mode := validationMode(c, cfg.CommitMessage)
+		var violations []error
+		if mode != sv.ValidationModeOff {
+			spelling, err := spellChecker(c, cfg.CommitMessage.Spelling, repoPath)
+			if err != nil {
+				return err
+			}
+			violations = messageProcessor.Violations(commitMessage)
+			if spelling != nil {
+				violations = append(violations, spelling.Violations(commitMessage)...)
+			}
+		}
+		changed := hinted
+		if len(violations) > 0 {
+			messages := make([]string, len(violations))
+			for i, violation := range violations {
+				messages[i] = violation.Error()
+			}
+			if mode == sv.ValidationModeWarn {
+				warnViolations(out, "invalid commit message", prefixLines(messages, "- "))
+				commitMessage, changed = sv.WithValidationWarnings(commitMessage, len(violations)), true
+			} else {
+				if c.Bool("edit-hint") {
+					if werr := os.WriteFile(filepath, []byte(withEditHint(content, editHint(cfg.CommitMessage, messages))), 0644); werr != nil {
+						out.warnf("could not add help to commit message file, %s", werr.Error())
+					}
+				}
+				return invalidCommitMessageError(messages, c.Int("max-errors"), c.Bool("compact"), out)
+			}
+		}
+
+		msg, err := messageProcessor.Enhance(branch, commitMessage)
+		var conflict sv.IssueConflictError
+		switch {
+		case errors.As(err, &conflict) && conflict.OnConflict == sv.IssueOnConflictError:
+			return err
+		case errors.As(err, &conflict):
+			out.warnf("%s", err.Error())
+		case err != nil:
+			out.warnf("could not enhance commit message, %s", err.Error())
+		}
+		if msg == "" && !changed {
+			return nil
+		}
+		if msg == "" {
+			msg = commitMessage
+		}
+
+		if err := os.WriteFile(filepath, []byte(msg), 0644); err != nil {
+			return fmt.Errorf("failed to add meta-informations on footer, error: %s", err.Error())
+		}
+
+		return nil
+	}
+}
+
+// commitMessageFile resolves the commit message file passed by prepare-commit-msg hook. Git passes a path relative to the
+// working tree on the main worktree, e.g. .git/COMMIT_EDITMSG, resolved from path, the hook working directory, and an absolute
+// path inside the common git dir on linked worktrees, used as is.
+func commitMessageFile(path, file string) (string, error) {
+	if !filepath.IsAbs(file) {
+		dir, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve commit message path %s, error: %v", path, err)
+		}
+		file = filepath.Join(dir, file)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", fmt.Errorf("commit message file %s not found, check --path and --file flags, error: %v", file, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("commit message file %s is a directory, check --path and --file flags", file)
+	}
+	return file, nil
+}
+
+// invalidCommitMessageError returns the violations of an invalid commit message, at most maxErrors of them or all if maxErrors is 0.
+// On compact mode violations are printed one per line on stderr, for hook runners that truncate long errors.
+func invalidCommitMessageError(violations []string, maxErrors int, compact bool, out *printer) error {
+	shown, hidden := limitViolations(violations, maxErrors)
+	more := ""
+	if hidden > 0 {
+		more = fmt.Sprintf("%d more violation(s), use --max-errors 0 to show all", hidden)
+	}
+
+	if compact {
+		for _, violation := range shown {
+			out.errln("invalid commit message: " + strings.ReplaceAll(violation, "\n", " "))
+		}
+		if more != "" {
+			out.errln("invalid commit message: " + more)
+		}
+		return fmt.Errorf("invalid commit message, %d violation(s)", len(violations))
+	}
+
+	if len(shown) == 1 {
+		if more != "" {
+			return fmt.Errorf("invalid commit message, error: %s (%s)", shown[0], more)
+		}
+		return fmt.Errorf("invalid commit message, error: %s", shown[0])
+	}
+	if more != "" {
+		shown = append(shown, more)
+	}
+	return fmt.Errorf("invalid commit message:\n- %s", strings.Join(shown, "\n- "))
+}
+
+// validationMode returns commit-message.validation-mode config, error if empty, --warn-only flag forces warn.
+func validationMode(c *cli.Context, cfg sv.CommitMessageConfig) string {
+	if c.Bool("warn-only") {
+		return sv.ValidationModeWarn
+	}
+	return str(cfg.ValidationMode, sv.ValidationModeError)
+}
+
+// warnViolations prints violations accepted by validation-mode warn on stderr between banners, they must be noticed
+// although the command succeeds.
+func warnViolations(out *printer, title string, lines []string) {
+	banner := strings.Repeat("*", 72)
+	out.errln(banner)
+	out.warnf("%s, accepted by validation mode warn, it will be an error once the mode is error:", title)
+	out.errln(strings.Join(lines, "\n"))
+	out.errln(banner)
+}
+
+func prefixLines(values []string, prefix string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = prefix + strings.ReplaceAll(value, "\n", " ")
+	}
+	return result
+}
+
+// messageValidation validate-message json output.
+type messageValidation struct {
+	Valid      bool     `json:"valid"`
+	Violations []string `json:"violations"`
+}
+
+// validateMessageHandler validates a commit message from --message, --from-file or stdin, without hook context branches are never skipped.
+func validateMessageHandler(cfg Config, messageProcessor sv.MessageProcessor, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		format := str(c.String("format"), "text")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("invalid format: %s, use text or json", format)
+		}
+
+		message, err := validateMessageInput(c)
+		if err != nil {
+			return err
+		}
+
+		mode := validationMode(c, cfg.CommitMessage)
+		var violations []error
+		if mode != sv.ValidationModeOff {
+			spelling, err := spellChecker(c, cfg.CommitMessage.Spelling, repoPath)
+			if err != nil {
+				return err
+			}
+			violations = messageProcessor.Violations(message)
+			if spelling != nil {
+				violations = append(violations, spelling.Violations(message)...)
+			}
+		}
+
+		result := messageValidation{Valid: true, Violations: []string{}}
+		for _, violation := range violations {
+			result.Valid = false
+			result.Violations = append(result.Violations, violation.Error())
+		}
+
+		if format == "json" {
+			content, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			out.println(string(content))
+			if !result.Valid && mode != sv.ValidationModeWarn {
+				return fmt.Errorf("invalid commit message, %d violation(s)", len(result.Violations))
+			}
+			return nil
+		}
+
+		switch {
+		case mode == sv.ValidationModeOff:
+			out.infof("commit message validation disabled by validation mode off")
+		case !result.Valid && mode == sv.ValidationModeWarn:
+			warnViolations(out, "invalid commit message", prefixLines(result.Violations, "- "))
+		case !result.Valid:
+			return fmt.Errorf("invalid commit message:\n- %s", strings.Join(result.Violations, "\n- "))
+		default:
+			out.infof("valid commit message")
+		}
+		return nil
+	}
+}
+
+// validateMessageInput reads the message from --message or --from-file flags, stdin is used if none is set.
+func validateMessageInput(c *cli.Context) (string, error) {
+	message, file := c.String("message"), c.String("from-file")
+	switch {
+	case message != "" && file != "":
+		return "", fmt.Errorf("cannot define message and from-file flags together")
+	case message != "":
+		return message, nil
+	case file != "":
+		content, err := readFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read commit message, error: %v", err)
+		}
+		return content, nil
+	}
+	content, err := io.ReadAll(c.App.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message from stdin, error: %v", err)
+	}
+	return string(content), nil
+}
+
+// refUpdate a line of pre-receive hook input.
+type refUpdate struct {
+	oldRev, newRev, ref string
+}
+
+// parseRefUpdates reads pre-receive hook input, one "<old> <new> <ref>" line per updated ref.
+func parseRefUpdates(r io.Reader) ([]refUpdate, error) {
+	var updates []refUpdate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid ref update %q, expected: <old> <new> <ref>", line)
+		}
+		updates = append(updates, refUpdate{oldRev: fields[0], newRev: fields[1], ref: fields[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ref updates from stdin, error: %v", err)
+	}
+	return updates, nil
+}
+
+// validatePushHandler validates messages of commits pushed on each ref update read from stdin, for pre-receive hooks of
+// git servers. Deleted refs are ignored and branches.skip applies to branch names, other refs use their full name.
+// On validation mode warn the push is accepted unless violations are more than --max-warnings.
+func validatePushHandler(cfg Config, git sv.Git, messageProcessor sv.MessageProcessor, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		mode := validationMode(c, cfg.CommitMessage)
+		if mode == sv.ValidationModeOff {
+			out.infof("commit message validation disabled by validation mode off")
+			return nil
+		}
+		updates, err := parseRefUpdates(c.App.Reader)
+		if err != nil {
+			return err
+		}
+		spelling, err := spellChecker(c, cfg.CommitMessage.Spelling, repoPath)
+		if err != nil {
+			return err
+		}
+
+		var reports []string
+		validated, invalidCommits, invalidRefs, warnings := 0, 0, 0, 0
+		for _, update := range updates {
+			if sv.IsZeroHash(update.newRev) {
+				continue
+			}
+			branch := update.ref
+			if strings.HasPrefix(branch, "refs/heads/") {
+				branch = strings.TrimPrefix(branch, "refs/heads/")
+			}
+			if messageProcessor.SkipBranch(branch, false) {
+				out.warnf("%s: commit message validation skipped, branch in ignore list", update.ref)
+				continue
+			}
+
+			commits, err := git.PushedCommits(update.oldRev, update.newRev, update.ref)
+			if err != nil {
+				return fmt.Errorf("error listing commits of %s, message: %v", update.ref, err)
+			}
+			var report []string
+			for _, commit := range commits {
+				validated++
+				violations := messageProcessor.Violations(commit.Message)
+				if spelling != nil {
+					violations = append(violations, spelling.Violations(commit.Message)...)
+				}
+				if len(violations) == 0 {
+					continue
+				}
+				invalidCommits++
+				warnings += len(violations)
+				subject, _, _ := strings.Cut(commit.Message, "\n")
+				report = append(report, fmt.Sprintf("  %s %s", commit.Hash, subject))
+				for _, violation := range violations {
+					report = append(report, "    - "+strings.ReplaceAll(violation.Error(), "\n", " "))
+				}
+			}
+			if len(report) > 0 {
+				invalidRefs++
+				reports = append(reports, update.ref+":")
+				reports = append(reports, report...)
+			}
+		}
+
+		if invalidCommits == 0 {
+			out.infof("%d commit message(s) validated", validated)
+			return nil
+		}
+		if mode != sv.ValidationModeWarn {
+			out.errln(strings.Join(reports, "\n"))
+			return fmt.Errorf("invalid commit message on %d commit(s) of %d ref(s)", invalidCommits, invalidRefs)
+		}
+		warnViolations(out, fmt.Sprintf("invalid commit message on %d commit(s) of %d ref(s)", invalidCommits, invalidRefs), reports)
+		if c.IsSet("max-warnings") && warnings > c.Int("max-warnings") {
+			return fmt.Errorf("%d violation(s) found, more than --max-warnings %d", warnings, c.Int("max-warnings"))
+		}
+		return nil
+	}
+}
+
+func readFile(filepath string) (string, error) {
+	f, err := os.ReadFile(filepath)
+	if err != nil {
+		return "", err
+	}
+	return string(f), nil
+}
+
+// printChangelog streams a changelog to stdout followed by a new line, as out.println.
+func printChangelog(out *printer, formatter sv.OutputFormatter, next sv.ReleaseNoteSource) error {
+	w := bufio.NewWriter(out.stdout)
+	if err := formatter.WriteChangelog(w, next); err != nil {
+		return err
+	}
+	if err := w.WriteByte('\n'); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// writeOnFile writes message on filename creating parent directories.
+func writeOnFile(message, filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, []byte(message), 0600)
+}
+
+func str(value, defaultValue string) string {
+	if value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type componentVersionInfo struct {
+	Name           string `json:"name"`
+	Path           string `json:"path"`
+	CurrentVersion string `json:"currentVersion"`
+	NextVersion    string `json:"nextVersion"`
+	Updated        bool   `json:"updated"`
+	CommitCount    int    `json:"commitCount"`
+	BumpLevel      string `json:"bumpLevel"`
+	// ComputedBumpLevel update required by commits, differs from BumpLevel when limited by max-bump.
+	ComputedBumpLevel string `json:"computedBumpLevel"`
+	MaxBump           string `json:"maxBump,omitempty"`
+
+	Explanation *bumpExplanation `json:"explanation,omitempty"`
+}
+
+func monorepoNextVersionHandler(
+	git sv.Git,
+	semverProcessor sv.SemVerCommitsProcessor,
+	monorepoProcessor sv.MonorepoProcessor,
+	cfg Config,
+	repoPath string,
+	out *printer,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		format := str(c.String("format"), "text")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("invalid format: %s, use text or json", format)
+		}
+
+		components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		if err != nil {
+			return fmt.Errorf("error finding monorepo components: %v", err)
+		}
+		logComponents(out, components, skipped)
+		if components, err = selectComponents(components, c.StringSlice("component")); err != nil {
+			return err
+		}
+
+		channel, err := prereleaseChannel(git, cfg.Monorepo, out)
+		if err != nil {
+			return err
+		}
+		tags, err := git.TagsAll()
+		if err != nil {
+			return fmt.Errorf("error listing tags, message: %v", err)
+		}
+
+		cache := newComponentCache(git)
+		infos := make([]componentVersionInfo, 0, len(components))
+		for _, component := range components {
+			commits, nextVer, updated, nerr := componentVersion(c, cache, monorepoProcessor, semverProcessor, cfg.Versioning, repoPath, component, filterComponentTags(tags, component), channel, "", out)
+			if nerr != nil {
+				return nerr
+			}
+			if !updated {
+				nextVer = component.CurrentVersion
+			}
+
+			relDir, rerr := filepath.Rel(repoPath, component.RootPath)
+			if rerr != nil {
+				return fmt.Errorf("error resolving path for %s: %v", component.Name, rerr)
+			}
+			info := componentVersionInfo{
+				Name:           component.Name,
+				Path:           filepath.ToSlash(relDir),
+				CurrentVersion: component.CurrentVersion.String(),
+				NextVersion:    nextVer.String(),
+				Updated:        updated,
+				CommitCount:    len(commits),
+				BumpLevel:      bumpLevel(component.CurrentVersion, nextVer),
+				MaxBump:        component.MaxBump,
+			}
+			info.ComputedBumpLevel = info.BumpLevel
+			if updated && component.MaxBump != "" && !versionForced(c) && cfg.Versioning.Scheme != sv.VersioningSchemeCalVer {
+				info.ComputedBumpLevel = semverProcessor.Explain(commits).Applied
+			}
+			if c.Bool("explain") {
+				explanation := explainBump(c, semverProcessor, commits)
+				info.Explanation = &explanation
+			}
+			infos = append(infos, info)
+		}
+		sort.SliceStable(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+		if format == "json" {
+			content, err := json.MarshalIndent(infos, "", "  ")
+			if err != nil {
+				return err
+			}
+			out.println(string(content))
+			return nil
+		}
+
+		for _, info := range infos {
+			if !info.Updated {
+				out.printf("%s: %s (no change)\n", info.Name, info.NextVersion)
+			} else {
+				out.printf("%s: %s\n", info.Name, info.NextVersion)
+			}
+			if info.Explanation != nil {
+				printBumpExplanation(out, *info.Explanation, "  ")
+			}
+		}
+		return skippedComponentsError(skipped, cfg.Monorepo)
+	}
+}
+
+// bumpLevel returns which version segment changed from current to next: major, minor, patch or none.
+func bumpLevel(current, next *semver.Version) string {
+	switch {
+	case next.Major() != current.Major():
+		return "major"
+	case next.Minor() != current.Minor():
+		return "minor"
+	case next.Patch() != current.Patch():
+		return "patch"
+	}
+	return "none"
+}
+
+func monorepoTagHandler(
+	git sv.Git,
+	semverProcessor sv.SemVerCommitsProcessor,
+	monorepoProcessor sv.MonorepoProcessor,
+	messageProcessor sv.MessageProcessor,
+	cfg Config,
+	repoPath string,
+	clock sv.Clock,
+	out *printer,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) (err error) {
+		summaryFile := c.String("summary-file")
+		if summaryFile != "" {
+			if err := checkSummaryFile(summaryFile); err != nil {
+				return err
+			}
+		}
+		summary := tagSummary{Components: []componentTagSummary{}}
+		var current *componentTagSummary
+		defer func() {
+			if summaryFile == "" {
+				return
+			}
+			if err != nil && current != nil {
+				current.Status, current.Error = tagStatusFailed, err.Error()
+				summary.Components = append(summary.Components, *current)
+			}
+			if werr := writeTagSummary(git, clock, summary, summaryFile, err); werr != nil {
+				if err == nil {
+					err = werr
+					return
+				}
+				out.warnf("%v", werr)
+			}
+		}()
+
+		remote, err := getTagRemote(git, c)
+		if err != nil {
+			return err
+		}
+		// with batch push, tags are only created locally and pushed together after every tag was created.
+		batched := remote != "" && cfg.Monorepo.TagPush != sv.TagPushPerTag
+		tagRemote := remote
+		if batched {
+			tagRemote = ""
+		}
+		var created []string
+		pushed := false
+		defer func() {
+			if err != nil && len(created) > 0 && !pushed {
+				out.warnf("tags created locally were not pushed, push them with: git push %s %s", remote, strings.Join(created, " "))
+			}
+		}()
+
+		components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		if err != nil {
+			return fmt.Errorf("error finding monorepo components: %v", err)
+		}
+		logComponents(out, components, skipped)
+		if components, err = selectComponents(components, c.StringSlice("component")); err != nil {
+			return err
+		}
+		if err := checkCleanWorkingTree(git, c.Bool("allow-dirty"), repoPath, components, out); err != nil {
+			return err
+		}
+		if err := checkTagHead(git, c); err != nil {
+			return err
+		}
+		ref, err := tagCommit(git, c)
+		if err != nil {
+			return err
+		}
+		if ref != "" && c.Bool("bump-and-commit") {
+			return fmt.Errorf("cannot use --commit with --bump-and-commit, versioning files are committed on HEAD")
+		}
+
+		channel, err := prereleaseChannel(git, cfg.Monorepo, out)
+		if err != nil {
+			return err
+		}
+		tags, err := git.TagsAll()
+		if err != nil {
+			return fmt.Errorf("error listing tags, message: %v", err)
+		}
+
+		cache := newComponentCache(git)
+		if !c.Bool("allow-dirty") {
+			cache.seedOnDisk(repoPath, components)
+		}
+		hooks := newHooks(c, cfg.Hooks, repoPath, out)
+		hooks.onRun = cache.invalidate
+		var existing []sv.TagExistsError
+		var hookFailures []string
+		for _, component := range components {
+			current = &componentTagSummary{Name: component.Name, PreviousVersion: component.CurrentVersion.String()}
+			if summaryFile != "" {
+				if current.From, err = previousTagCommit(git, filterComponentTags(tags, component)); err != nil {
+					return err
+				}
+			}
+			if err := checkCommitAfterTag(git, ref, sv.LatestTag(sv.StableTags(filterComponentTags(tags, component), component.Name))); err != nil {
+				return fmt.Errorf("%s: %v", component.Name, err)
+			}
+			_, nextVer, updated, nerr := componentVersion(c, cache, monorepoProcessor, semverProcessor, cfg.Versioning, repoPath, component, filterComponentTags(tags, component), channel, ref, out)
+			if nerr != nil {
+				return nerr
+			}
+			if !updated {
+				out.statusf("%s: no version change (current: %s)", component.Name, component.CurrentVersion.String())
+				current.NewVersion, current.Status = component.CurrentVersion.String(), tagStatusUnchanged
+				summary.Components, current = append(summary.Components, *current), nil
+				continue
+			}
+			current.NewVersion = nextVer.String()
+
+			relFile, rerr := filepath.Rel(repoPath, component.VersioningFilePath)
+			if rerr != nil {
+				return fmt.Errorf("error resolving path for %s: %v", component.Name, rerr)
+			}
+
+			committedVer, verr := cache.committedVersion(cfg.Monorepo, str(ref, "HEAD"), relFile)
+			if verr != nil {
+				return fmt.Errorf("error reading committed version for %s: %v", component.Name, verr)
+			}
+
+			// version bump already committed by monorepo-bump --commit, tag it as is.
+			baseline := componentBaseline(filterComponentTags(tags, component), component.Name)
+			if lastTag := sv.LatestTag(filterComponentTags(tags, component)); lastTag != "" && !versionForced(c) {
+				if tagVer, terr := sv.ToVersion(sv.ComponentTagVersion(lastTag)); terr == nil && committedVer.GreaterThan(tagVer) && inChannel(committedVer, channel) {
+					nextVer, baseline = committedVer, fmt.Sprintf("versioning file %s committed on %s", filepath.ToSlash(relFile), str(ref, "HEAD"))
+				}
+			}
+			if err := checkComponentDowngrade(c, component, nextVer, baseline); err != nil {
+				return err
+			}
+
+			// tag already exists, e.g. created by a previous execution that failed on other components, versioning file is not committed again.
+			if tag := findVersionTag(tags, git.ComponentTagName(*nextVer, component.Name)); tag != "" && !c.Bool("force-retag") {
+				commit, herr := git.ShortHash("refs/tags/" + tag)
+				if herr != nil {
+					return fmt.Errorf("error getting commit of tag %s, message: %v", tag, herr)
+				}
+				existsErr := sv.TagExistsError{Tag: tag, Commit: commit}
+				out.warnf("%s: %v", component.Name, existsErr)
+				existing = append(existing, existsErr)
+				current.Tag, current.To, current.Status = tag, commit, tagStatusExists
+				summary.Components, current = append(summary.Components, *current), nil
+				continue
+			}
+
+			if !committedVer.Equal(nextVer) {
+				if !c.Bool("bump-and-commit") {
+					return fmt.Errorf("versioning file %s at %s has version %s but next version for %s is %s, run monorepo-bump --commit first or use --bump-and-commit", relFile, str(ref, "HEAD"), committedVer.String(), component.Name, nextVer.String())
+				}
+				env := hookEnv{Version: nextVer.String(), Tag: git.ComponentTagName(*nextVer, component.Name), Component: component.Name}
+				if herr := hooks.run(hookPreBump, env); herr != nil {
+					return fmt.Errorf("%s: %v", component.Name, herr)
+				}
+				if uerr := monorepoProcessor.UpdateVersion(component, *nextVer, cfg.Monorepo); uerr != nil {
+					return fmt.Errorf("error updating version for %s: %v", component.Name, uerr)
+				}
+				current.FileUpdated = true
+				if herr := hooks.run(hookPostBump, env); herr != nil {
+					out.errorf("%s: %v", component.Name, herr)
+					hookFailures = append(hookFailures, fmt.Sprintf("%s: %v", component.Name, herr))
+				}
+				body := fmt.Sprintf("- %s: %s", component.Name, nextVer.String())
+				if cerr := commitVersionFiles(git, messageProcessor, cfg.Monorepo.BumpCommitMessage, body, component.Files(), remote != ""); cerr != nil {
+					return fmt.Errorf("error committing version for %s: %v", component.Name, cerr)
+				}
+				cache.invalidate()
+			}
+
+			if nextVer, err = withBuildMetadata(git, c, cfg.Versioning, nextVer, ref); err != nil {
+				return err
+			}
+			env := hookEnv{Version: nextVer.String(), Tag: git.ComponentTagName(*nextVer, component.Name), Component: component.Name}
+			if herr := hooks.run(hookPreTag, env); herr != nil {
+				return fmt.Errorf("%s: %v", component.Name, herr)
+			}
+			tagName, terr := createTag(git, c, remote, str(ref, "HEAD"), func() (string, error) {
+				if ref != "" {
+					return git.TagForComponentAt(*nextVer, component.Name, ref, tagRemote)
+				}
+				return git.TagForComponent(*nextVer, component.Name, tagRemote)
+			}, out)
+			var existsErr sv.TagExistsError
+			if errors.As(terr, &existsErr) {
+				out.warnf("%s: %v", component.Name, existsErr)
+				existing = append(existing, existsErr)
+				current.Tag, current.To, current.Status = existsErr.Tag, existsErr.Commit, tagStatusExists
+				summary.Components, current = append(summary.Components, *current), nil
+				continue
+			}
+			if terr != nil {
+				return fmt.Errorf("error creating tag for %s: %v", component.Name, withPushHint(terr))
+			}
+			out.successf("%s: %s", component.Name, tagName)
+			if batched {
+				created = append(created, tagName)
+			}
+			current.NewVersion, current.Tag, current.Status = nextVer.String(), tagName, tagStatusTagged
+			if current.To, err = git.ShortHash(str(ref, "HEAD")); err != nil {
+				return fmt.Errorf("error getting %s commit, message: %v", str(ref, "HEAD"), err)
+			}
+			summary.Components, current = append(summary.Components, *current), nil
+			env.Tag = tagName
+			if herr := hooks.run(hookPostTag, env); herr != nil {
+				out.errorf("%s: %v", component.Name, herr)
+				hookFailures = append(hookFailures, fmt.Sprintf("%s: %v", component.Name, herr))
+			}
+		}
+		if len(created) > 0 {
+			pushed = true
+			if perr := pushTagBatches(git, remote, created, c.Int("push-batch-size")); perr != nil {
+				var pushErr sv.TagsPushError
+				if errors.As(perr, &pushErr) {
+					for _, failed := range pushErr.Failed {
+						for i := range summary.Components {
+							if summary.Components[i].Tag == failed.Tag {
+								summary.Components[i].Status, summary.Components[i].Error = tagStatusFailed, failed.Error()
+							}
+						}
+					}
+				}
+				return fmt.Errorf("error pushing tags, message: %v", withPushHint(perr))
+			}
+			out.statusf("%d tag(s) pushed to %s", len(created), remote)
+		}
+		if len(existing) > 0 {
+			return tagExistsError(existing...)
+		}
+		if err := postHooksError(hookFailures); err != nil {
+			return err
+		}
+		return skippedComponentsError(skipped, cfg.Monorepo)
+	}
+}
+
+// monorepoPromoteHandler tags the stable version of the latest prerelease tag of each component on the same commit, e.g.
+// component/v1.4.0 on the commit of component/v1.4.0-beta.3, versioning files are not changed.
+func monorepoPromoteHandler(git sv.Git, monorepoProcessor sv.MonorepoProcessor, cfg Config, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		names := c.StringSlice("component")
+		if len(names) == 0 {
+			return fmt.Errorf("missing component, use --component")
+		}
+		remote, err := getTagRemote(git, c)
+		if err != nil {
+			return err
+		}
+
+		components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		if err != nil {
+			return fmt.Errorf("error finding monorepo components: %v", err)
+		}
+		logComponents(out, components, skipped)
+		if components, err = selectComponents(components, names); err != nil {
+			return err
+		}
+
+		tags, err := git.TagsAll()
+		if err != nil {
+			return fmt.Errorf("error listing tags, message: %v", err)
+		}
+
+		var existing []sv.TagExistsError
+		for _, component := range components {
+			prereleaseTag, prereleaseVer := sv.LatestPrerelease(filterComponentTags(tags, component), component.Name, c.String("channel"))
+			if prereleaseTag == "" {
+				return fmt.Errorf("no prerelease tag found for component %s", component.Name)
+			}
+			stable := semver.New(prereleaseVer.Major(), prereleaseVer.Minor(), prereleaseVer.Patch(), "", "")
+
+			tagName, terr := createTag(git, c, remote, prereleaseTag, func() (string, error) {
+				return git.TagForComponentAt(*stable, component.Name, "refs/tags/"+prereleaseTag, remote)
+			}, out)
+			var existsErr sv.TagExistsError
+			if errors.As(terr, &existsErr) {
+				out.warnf("%s: %v", component.Name, existsErr)
+				existing = append(existing, existsErr)
+				continue
+			}
+			if terr != nil {
+				return fmt.Errorf("error creating tag for %s: %v", component.Name, withPushHint(terr))
+			}
+			out.successf("%s: %s (promoted from %s)", component.Name, tagName, prereleaseTag)
+		}
+		if len(existing) > 0 {
+			return tagExistsError(existing...)
+		}
+		return nil
+	}
+}
+
+// monorepoInitComponentHandler creates the versioning file of a new component and, with --tag, its initial tag.
+func monorepoInitComponentHandler(git sv.Git, cfg Config, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		componentDir := c.String("path")
+		name := c.String("name")
+		if cfg.Monorepo.NamePath == "" {
+			if name != "" && name != filepath.ToSlash(filepath.Clean(componentDir)) {
+				return fmt.Errorf("component name is its directory when monorepo.name-path is not configured, use --name %s or omit it", filepath.ToSlash(filepath.Clean(componentDir)))
+			}
+			name = filepath.ToSlash(filepath.Clean(componentDir))
+		} else if name == "" {
+			return fmt.Errorf("--name is required when monorepo.name-path is configured")
+		}
+
+		version, err := sv.ToVersion(c.String("version"))
+		if err != nil {
+			return fmt.Errorf("invalid version: %s, message: %v", c.String("version"), err)
+		}
+
+		relFile, err := sv.ComponentVersioningFile(cfg.Monorepo, componentDir)
+		if err != nil {
+			return err
+		}
+		if err := sv.CreateVersioningFile(filepath.Join(repoPath, filepath.FromSlash(relFile)), cfg.Monorepo, name, version.String()); err != nil {
+			return fmt.Errorf("error creating versioning file for %s: %v", name, err)
+		}
+		out.infof("%s: versioning file %s created with version %s", name, relFile, version.String())
+
+		if !c.Bool("tag") {
+			return nil
+		}
+		remote, err := getTagRemote(git, c)
+		if err != nil {
+			return err
+		}
+		tagName, err := git.TagForComponent(*version, name, remote)
+		if err != nil {
+			return fmt.Errorf("error creating tag for %s: %v", name, withPushHint(err))
+		}
+		out.successf("%s: %s", name, tagName)
+		return nil
+	}
+}
+
+// monorepoStatusHandler lists directories of monorepo.component-dirs with the version of their component, directories
+// without versioning file fail the command unless --create-missing creates them with --initial-version.
+func monorepoStatusHandler(cfg Config, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		create := c.Bool("create-missing")
+		version, err := sv.ToVersion(c.String("initial-version"))
+		if err != nil {
+			return fmt.Errorf("invalid initial-version: %s, message: %v", c.String("initial-version"), err)
+		}
+		dirs, err := sv.ComponentDirs(repoPath, cfg.Monorepo)
+		if err != nil {
+			return fmt.Errorf("error listing component directories: %v", err)
+		}
+		if len(dirs) == 0 {
+			out.infof("no directories matched monorepo.component-dirs %q", cfg.Monorepo.ComponentDirs)
+			return nil
+		}
+
+		var missing []string
+		var sb strings.Builder
+		w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DIRECTORY\tCOMPONENT\tVERSION\tSTATUS")
+		for _, dir := range dirs {
+			switch {
+			case dir.Err != nil:
+				out.warnf("%s: %v", dir.Dir, dir.Err)
+				fmt.Fprintf(w, "%s\t-\t-\tinvalid versioning file\n", dir.Dir)
+			case !dir.Missing():
+				fmt.Fprintf(w, "%s\t%s\t%s\tok\n", dir.Dir, dir.Component.Name, dir.Component.CurrentVersion.String())
+			case create:
+				// with monorepo.name-path the last directory is the name, as on monorepo-init-component with --name.
+				name := dir.Dir
+				if cfg.Monorepo.NamePath != "" {
+					name = path.Base(dir.Dir)
+				}
+				if err := sv.CreateVersioningFile(filepath.Join(repoPath, filepath.FromSlash(dir.VersioningFile)), cfg.Monorepo, name, version.String()); err != nil {
+					return fmt.Errorf("error creating versioning file for %s: %v", dir.Dir, err)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\tcreated %s\n", dir.Dir, name, version.String(), dir.VersioningFile)
+			default:
+				missing = append(missing, dir.Dir)
+				fmt.Fprintf(w, "%s\t-\t-\tmissing %s\n", dir.Dir, dir.VersioningFile)
+			}
+		}
+		w.Flush()
+		out.printf("%s", sb.String())
+
+		if len(missing) > 0 {
+			return fmt.Errorf("%d component directory(ies) without versioning file, they are not released: %s, use --create-missing to create them", len(missing), strings.Join(missing, ", "))
+		}
+		return nil
+	}
+}
+
+// impactReport breaking changes of a monorepo component and the components depending on it, printed by monorepo-impact.
+type impactReport struct {
+	Component       string                   `json:"component"`
+	BreakingChanges []commitSummary          `json:"breakingChanges"`
+	Dependents      []sv.ComponentDependency `json:"dependents"`
+}
+
+// monorepoImpactHandler reports breaking changes of a component on a commit range and the components declaring a
+// dependency on it on monorepo.dependencies files, they need a coordinated bump.
+func monorepoImpactHandler(git sv.Git, monorepoProcessor sv.MonorepoProcessor, cfg Config, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		format := c.String("format")
+		if format != "table" && format != "json" {
+			return fmt.Errorf("invalid format: %s, use table or json", format)
+		}
+		components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		if err != nil {
+			return fmt.Errorf("error finding monorepo components: %v", err)
+		}
+		logComponents(out, components, skipped)
+
+		name := c.String("component")
+		component, found := findComponent(name, components)
+		if !found {
+			return fmt.Errorf("component: %s not found", name)
+		}
+		relDir, err := filepath.Rel(repoPath, component.RootPath)
+		if err != nil {
+			return fmt.Errorf("error resolving path for %s: %v", component.Name, err)
+		}
+
+		lr, err := logRange(git, c.String("r"), c.String("s"), c.String("e"))
+		if err != nil {
+			return err
+		}
+		if c.String("r") == string(sv.TagRange) && c.String("s") == "" {
+			// changes since the last component tag, not the last tag of the repository.
+			tags, err := git.TagsAll()
+			if err != nil {
+				return fmt.Errorf("error listing tags, message: %v", err)
+			}
+			lr = sv.NewLogRange(sv.TagRange, sv.LatestTag(filterComponentTags(tags, component)), c.String("e"))
+		}
+		commits, err := git.Log(lr.WithPaths(append([]string{filepath.ToSlash(relDir)}, component.PreviousPaths...)))
+		if err != nil {
+			return fmt.Errorf("error getting git log, message: %v", err)
+		}
+		dependents, err := sv.FindDependents(repoPath, cfg.Monorepo, components, component)
+		if err != nil {
+			return fmt.Errorf("error reading component dependencies: %v", err)
+		}
+
+		report := impactReport{Component: component.Name, BreakingChanges: []commitSummary{}, Dependents: []sv.ComponentDependency{}}
+		for _, commit := range commits {
+			if commit.Message.IsBreakingChange {
+				report.BreakingChanges = append(report.BreakingChanges, newCommitSummary(commit))
+			}
+		}
+		report.Dependents = append(report.Dependents, dependents...)
+
+		if format == "json" {
+			content, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			out.println(string(content))
+			return nil
+		}
+		printImpactReport(out, report)
+		return nil
+	}
+}
+
+func printImpactReport(out *printer, report impactReport) {
+	if len(report.BreakingChanges) == 0 {
+		out.printf("%s: no breaking changes\n", report.Component)
+	} else {
+		out.printf("%s: %d breaking change(s), dependent components need a coordinated bump\n", report.Component, len(report.BreakingChanges))
+		for _, commit := range report.BreakingChanges {
+			out.printf("  %s %s\n", commit.Hash, commit.Subject)
+		}
+	}
+	if len(report.Dependents) == 0 {
+		out.printf("no component depends on %s\n", report.Component)
+		return
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tFILE\tDEPENDENCY")
+	for _, dependent := range report.Dependents {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", dependent.Component, dependent.File, dependent.Dependency)
+	}
+	w.Flush()
+	out.printf("\n%s", sb.String())
+}
+
+// committedComponentVersion reads the version from the versioning file committed at revision.
+func committedComponentVersion(git sv.Git, cfg sv.MonorepoConfig, revision, relFile string) (*semver.Version, error) {
+	content, err := git.ShowFile(revision, relFile)
+	if err != nil {
+		return nil, err
+	}
+	return sv.ParseVersionFile(relFile, content, cfg.Path, cfg.VersionFormat)
+}
+
+func monorepoUpdateVersionHandler(
+	git sv.Git,
+	semverProcessor sv.SemVerCommitsProcessor,
+	monorepoProcessor sv.MonorepoProcessor,
+	messageProcessor sv.MessageProcessor,
+	cfg Config,
+	repoPath string,
+	out *printer,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		if err != nil {
+			return fmt.Errorf("error finding monorepo components: %v", err)
+		}
+		logComponents(out, components, skipped)
+		if components, err = selectComponents(components, c.StringSlice("component")); err != nil {
+			return err
+		}
+		if err := checkCleanWorkingTree(git, c.Bool("allow-dirty"), repoPath, components, out); err != nil {
+			return err
+		}
+
+		channel, err := prereleaseChannel(git, cfg.Monorepo, out)
+		if err != nil {
+			return err
+		}
+		tags, err := git.TagsAll()
+		if err != nil {
+			return fmt.Errorf("error listing tags, message: %v", err)
+		}
+
+		cache := newComponentCache(git)
+		hooks := newHooks(c, cfg.Hooks, repoPath, out)
+		hooks.onRun = cache.invalidate
+		var bumped []string
+		var files []string
+		var hookFailures []string
+		for _, component := range components {
+			_, nextVer, updated, nerr := componentVersion(c, cache, monorepoProcessor, semverProcessor, cfg.Versioning, repoPath, component, filterComponentTags(tags, component), channel, "", out)
+			if nerr != nil {
+				return nerr
+			}
+			if !updated {
+				out.infof("%s: no version change (current: %s)", component.Name, component.CurrentVersion.String())
+				continue
+			}
+			if derr := checkComponentDowngrade(c, component, nextVer, componentBaseline(filterComponentTags(tags, component), component.Name)); derr != nil {
+				return derr
+			}
+
+			env := hookEnv{Version: nextVer.String(), Tag: git.ComponentTagName(*nextVer, component.Name), Component: component.Name}
+			if herr := hooks.run(hookPreBump, env); herr != nil {
+				return fmt.Errorf("%s: %v", component.Name, herr)
+			}
+			if uerr := monorepoProcessor.UpdateVersion(component, *nextVer, cfg.Monorepo); uerr != nil {
+				return fmt.Errorf("error updating version for %s: %v", component.Name, uerr)
+			}
+			out.successf("%s: %s written to %s", component.Name, nextVer.String(), strings.Join(component.Files(), ", "))
+			if herr := hooks.run(hookPostBump, env); herr != nil {
+				out.errorf("%s: %v", component.Name, herr)
+				hookFailures = append(hookFailures, fmt.Sprintf("%s: %v", component.Name, herr))
+			}
+			bumped = append(bumped, fmt.Sprintf("- %s: %s", component.Name, nextVer.String()))
+			files = append(files, component.Files()...)
+		}
+
+		if c.Bool("commit") && len(files) > 0 {
+			if err := commitVersionFiles(git, messageProcessor, cfg.Monorepo.BumpCommitMessage, strings.Join(bumped, "\n"), files, c.Bool("push")); err != nil {
+				return err
+			}
+		}
+		if err := postHooksError(hookFailures); err != nil {
+			return err
+		}
+		return skippedComponentsError(skipped, cfg.Monorepo)
+	}
+}
+
+// logComponents prints discovered components as debug lines, a warning for each component ignored by monorepo.on-parse-error config
+// and for each secondary versioning file out of sync with the component version.
+func logComponents(out *printer, components []sv.MonorepoComponent, skipped []sv.ComponentError) {
+	for _, component := range components {
+		out.debugf("component %s found at %s, current version %v", component.Name, component.RootPath, component.CurrentVersion)
+		for _, file := range component.SecondaryFiles {
+			if !file.Version.Equal(component.CurrentVersion) {
+				out.warnf("%s: versioning file %s has version %s, different from %s on %s, it is overwritten on next update", component.Name, file.Path, file.Version.String(), component.CurrentVersion.String(), component.VersioningFilePath)
+			}
+		}
+	}
+	for _, s := range skipped {
+		out.warnf("skipping component, error: %v", s)
+	}
+}
+
+// skippedComponentsError returns an error when components were skipped and monorepo.on-parse-error is warn.
+func skippedComponentsError(skipped []sv.ComponentError, cfg sv.MonorepoConfig) error {
+	if len(skipped) == 0 || cfg.OnParseError != sv.OnParseErrorWarn {
+		return nil
+	}
+	return fmt.Errorf("%d component(s) skipped due to invalid versioning files", len(skipped))
+}
+
+// commitVersionFiles stages and commits only the versioning files, changes staged before are kept staged and not committed.
+// The message is validated before commit.
+func commitVersionFiles(git sv.Git, messageProcessor sv.MessageProcessor, header, body string, files []string, push bool) error {
+	if err := messageProcessor.Validate(joinCommitMessage(header, body, "")); err != nil {
+		return fmt.Errorf("invalid bump commit message, check bump-commit-message config, message: %v", err)
+	}
+	msg, err := messageProcessor.Parse(header, body)
+	if err != nil {
+		return fmt.Errorf("error parsing bump commit message, message: %v", err)
+	}
+
+	if err := git.Add(files...); err != nil {
+		return fmt.Errorf("error staging version files, message: %v", err)
+	}
+	// other staged changes, e.g. with --allow-dirty, are left staged instead of committed as part of the release.
+	formattedHeader, formattedBody, footer := messageProcessor.Format(msg)
+	if err := git.Commit(formattedHeader, formattedBody, footer, files...); err != nil {
+		return fmt.Errorf("error executing git commit, message: %v", err)
+	}
+
+	if !push {
+		return nil
+	}
+	if err := git.Push(); err != nil {
+		return fmt.Errorf("error pushing bump commit, message: %v", err)
+	}
+	return nil
+}
+
+func monorepoChangelogHandler(
+	git sv.Git,
+	semverProcessor sv.SemVerCommitsProcessor,
+	monorepoProcessor sv.MonorepoProcessor,
+	rnProcessor sv.ReleaseNoteProcessor,
+	outputFormatter sv.OutputFormatter,
+	cfg Config,
+	repoPath string,
+	clock sv.Clock,
+	tm *timings,
+	out *printer,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) (err error) {
+		tm.enable(c)
+		defer func() {
+			if werr := tm.write(c, out); werr != nil {
+				if err == nil {
+					err = werr
+					return
+				}
+				out.warnf("%v", werr)
+			}
+		}()
+
+		aggregatePath := c.String("aggregate")
+		perComponent := aggregatePath == "" || c.Bool("per-component")
+		toStdout := c.Bool("stdout")
+
+		// keep stdout clean when changelogs are printed instead of written.
+		logf := out.infof
+		if toStdout {
+			logf = out.statusf
+		}
+
+		all := c.Bool("all")
+		dates, err := newDateFilter(c)
+		if err != nil {
+			return err
+		}
+		versions, err := newVersionRange(c)
+		if err != nil {
+			return err
+		}
+		all = all || versions.enabled()
+		addNextVersion := versions.to == nil && dates.includes(clock.Now())
+		if !addNextVersion && !all {
+			logf("unreleased versions are outside the date range, skipping changelogs")
+			return nil
+		}
+
+		pathTemplate, err := template.New("changelog-path").Parse(str(cfg.Monorepo.ChangelogPath, defaultComponentChangelogPath))
+		if err != nil {
+			return fmt.Errorf("invalid monorepo.changelog-path, message: %v", err)
+		}
+		if aggregatePath != "" && !toStdout {
+			if aggregatePath, err = repositoryFilePath(repoPath, aggregatePath); err != nil {
+				return fmt.Errorf("invalid --aggregate, message: %v", err)
+			}
+		}
+
+		stop := tm.measure(phaseDiscovery, "")
+		components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		if err != nil {
+			return fmt.Errorf("error finding monorepo components: %v", err)
+		}
+		logComponents(out, components, skipped)
+		roots, err := componentRoots(repoPath, components)
+		if err != nil {
 			return err
 		}
-		sort.Slice(tags, func(i, j int) bool {
-			return tags[i].Date.After(tags[j].Date)
-		})
+		stop()
+
+		stop = tm.measure(phaseTags, "")
+		tags, err := git.TagsAll()
+		if err != nil {
+			return fmt.Errorf("error listing tags, message: %v", err)
+		}
+		stop()
+
+		var channel string
+		if addNextVersion {
+			if channel, err = prereleaseChannel(git, cfg.Monorepo, out); err != nil {
+				return err
+			}
+		}
 
-		var releaseNotes []sv.ReleaseNote
+		// the next version reads the commits since the component baseline, usually the same log as the changelog.
+		cache := newComponentCache(git)
+		aggregate := make(map[string][]sv.ReleaseNote)
+		for _, component := range components {
+			stop = tm.measure(phaseLog, component.Name)
+			componentTags := filterComponentTags(tags, component)
+			commits, cerr := cache.componentCommits(repoPath, component, componentTags, cfg.ReleaseNotes.DetectSharedCommits, out)
+			if cerr != nil {
+				return fmt.Errorf("error getting commits for %s: %v", component.Name, cerr)
+			}
+			debugCommits(out, semverProcessor, commits)
+			if cfg.ReleaseNotes.DetectSharedCommits {
+				commits = sv.AnnotateSharedCommits(commits, component.Name, roots)
+			}
 
-		size := c.Int("size")
-		all := c.Bool("all")
-		addNextVersion := c.Bool("add-next-version")
-		semanticVersionOnly := c.Bool("semantic-version-only")
+			if cfg.Monorepo.Changelog.RelativePaths {
+				commits = sv.RelativeCommitPaths(commits, roots[component.Name])
+			}
 
-		if addNextVersion {
-			rnVersion, updated, date, commits, uerr := getNextVersionInfo(git, semverProcessor)
-			if uerr != nil {
-				return uerr
+			var nextReleaseNotes []sv.ReleaseNote
+			if len(commits) == 0 {
+				out.debugf("%s: no commits since %s, no next version", component.Name, str(sv.LatestTag(componentTags), "the first commit"))
+			} else if addNextVersion {
+				_, nextVer, updated, nerr := componentVersion(c, cache, monorepoProcessor, semverProcessor, cfg.Versioning, repoPath, component, componentTags, channel, "", out)
+				if nerr != nil {
+					return nerr
+				}
+				if updated {
+					date, derr := latestCommitDate(commits)
+					if derr != nil {
+						return fmt.Errorf("error getting release date for %s: %v", component.Name, derr)
+					}
+					nextReleaseNotes = append(nextReleaseNotes, rnProcessor.Create(nextVer, "", sv.LatestTag(componentTags), date, commits))
+				}
 			}
-			if updated {
-				releaseNotes = append(releaseNotes, rnProcessor.Create(rnVersion, "", date, commits))
+			history, historySize := sv.ReleaseNotes(nil), 0
+			if all {
+				var herr error
+				history, historySize, herr = componentReleaseHistory(git, rnProcessor, component, componentTags, cfg, roots, dates, versions, out)
+				if herr != nil {
+					return fmt.Errorf("error selecting versions of %s: %v", component.Name, herr)
+				}
+				// logs of released versions are read while the changelog is written.
+				history = tm.measureSource(phaseLog, component.Name, history)
 			}
-		}
-		for i, tag := range tags {
-			if !all && i >= size {
-				break
+			if len(nextReleaseNotes)+historySize == 0 {
+				stop()
+				logf("%s: no changes, skipping changelog", component.Name)
+				continue
 			}
 
-			previousTag := ""
-			if i+1 < len(tags) {
-				previousTag = tags[i+1].Name
+			stop()
+			releaseNotes := concatReleaseNotes(sv.ReleaseNotes(nextReleaseNotes), history)
+			// the aggregated changelog is sorted by component name, its release notes are kept until every component is read.
+			if aggregatePath != "" {
+				collected, cerr := collectReleaseNotes(releaseNotes)
+				if cerr != nil {
+					return cerr
+				}
+				aggregate[component.Name] = collected
+				releaseNotes = sv.ReleaseNotes(collected)
+			}
+			if !perComponent {
+				continue
 			}
 
-			if semanticVersionOnly && !sv.IsValidVersion(tag.Name) {
+			if toStdout {
+				stop = tm.measure(phaseFormat, component.Name)
+				if perr := printChangelog(out, outputFormatter, releaseNotes); perr != nil {
+					return fmt.Errorf("could not format changelog for %s: %v", component.Name, perr)
+				}
+				stop()
 				continue
 			}
 
-			commits, err := git.Log(sv.NewLogRange(sv.TagRange, previousTag, tag.Name))
-			if err != nil {
-				return fmt.Errorf("error getting git log from tag: %s, message: %v", tag.Name, err)
+			changelogPath, perr := componentChangelogPath(pathTemplate, repoPath, component)
+			if perr != nil {
+				return fmt.Errorf("could not resolve changelog path for %s: %v", component.Name, perr)
 			}
+			// changelogs are formatted while written, formatting time is part of file writes.
+			stop = tm.measure(phaseWrite, component.Name)
+			werr := streamFileAtomic(changelogPath, 0600, func(w io.Writer) error {
+				return outputFormatter.WriteChangelog(w, releaseNotes)
+			})
+			if werr != nil {
+				return fmt.Errorf("could not write changelog for %s: %v", component.Name, werr)
+			}
+			stop()
+			logf("%s: changelog written to %s", component.Name, changelogPath)
+		}
 
-			currentVer, _ := sv.ToVersion(tag.Name)
-			releaseNotes = append(releaseNotes, rnProcessor.Create(currentVer, tag.Name, tag.Date, commits))
+		if aggregatePath == "" {
+			return skippedComponentsError(skipped, cfg.Monorepo)
+		}
+		if len(aggregate) == 0 {
+			logf("no changes, skipping aggregated changelog")
+			return skippedComponentsError(skipped, cfg.Monorepo)
 		}
 
-		output, err := formatter.FormatChangelog(releaseNotes)
+		stop = tm.measure(phaseFormat, "")
+		output, err := outputFormatter.FormatMonorepoChangelog(aggregate)
 		if err != nil {
-			return fmt.Errorf("could not format changelog, message: %v", err)
+			return fmt.Errorf("could not format aggregated changelog: %v", err)
 		}
-		fmt.Println(output)
-
-		return nil
+		if toStdout {
+			out.println(output)
+			stop()
+			return skippedComponentsError(skipped, cfg.Monorepo)
+		}
+		stop()
+		stop = tm.measure(phaseWrite, "")
+		werr := streamFileAtomic(aggregatePath, 0600, func(w io.Writer) error {
+			_, err := io.WriteString(w, output)
+			return err
+		})
+		if werr != nil {
+			return fmt.Errorf("could not write aggregated changelog: %v", werr)
+		}
+		stop()
+		logf("aggregated changelog written to %s", aggregatePath)
+		return skippedComponentsError(skipped, cfg.Monorepo)
 	}
 }
 
-func validateCommitMessageHandler(git sv.Git, messageProcessor sv.MessageProcessor) func(c *cli.Context) error {
-	return func(c *cli.Context) error {
-		branch := git.Branch()
-		detached, derr := git.IsDetached()
-
-		if messageProcessor.SkipBranch(branch, derr == nil && detached) {
-			warnf("commit message validation skipped, branch in ignore list or detached...")
-			return nil
+// componentReleaseHistory returns a source of release notes of component tags in the date range, newest first, each one
+// linked to the previous component tag, and the number of release notes. With a version range, tags in range are sorted
+// by version and linked to the previous version instead. Commits of a tag are read when its release note is requested.
+// With monorepo.changelog.strip-tag-prefix, headings use tag versions instead of tag names.
+func componentReleaseHistory(git sv.Git, rnProcessor sv.ReleaseNoteProcessor, component sv.MonorepoComponent, componentTags []sv.GitTag, cfg Config, roots map[string]string, dates dateFilter, versions versionRange, out *printer) (sv.ReleaseNoteSource, int, error) {
+	tags := append([]sv.GitTag(nil), componentTags...)
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Date.After(tags[j].Date)
+	})
+	start, end := 0, len(tags)-1
+	if versions.enabled() && len(tags) > 0 {
+		var err error
+		tags, start, end, err = versions.selectTags(tags, func(tag string) *semver.Version {
+			return parsedVersion(sv.ComponentTagVersion(tag))
+		}, out)
+		if err != nil {
+			return nil, 0, err
 		}
+	}
 
-		if source := c.String("source"); source == "merge" {
-			warnf("commit message validation skipped, ignoring source: %s...", source)
-			return nil
+	var indexes []int
+	for i := start; i <= end; i++ {
+		if dates.includes(tags[i].Date) {
+			indexes = append(indexes, i)
 		}
+	}
 
-		filepath := filepath.Join(c.String("path"), c.String("file"))
-
-		commitMessage, err := readFile(filepath)
-		if err != nil {
-			return fmt.Errorf("failed to read commit message, error: %s", err.Error())
+	next := 0
+	return func() (sv.ReleaseNote, bool, error) {
+		if next >= len(indexes) {
+			return sv.ReleaseNote{}, false, nil
 		}
+		i := indexes[next]
+		next++
 
-		if err := messageProcessor.Validate(commitMessage); err != nil {
-			return fmt.Errorf("invalid commit message, error: %s", err.Error())
+		tag := tags[i]
+		previousTag := ""
+		if i+1 < len(tags) {
+			previousTag = tags[i+1].Name
 		}
 
-		msg, err := messageProcessor.Enhance(branch, commitMessage)
+		lr := sv.NewLogRangeWithPaths(sv.TagRange, previousTag, tag.Name, append([]string{roots[component.Name]}, component.PreviousPaths...))
+		if cfg.ReleaseNotes.DetectSharedCommits {
+			lr = lr.WithFiles()
+		}
+		commits, err := git.Log(lr)
 		if err != nil {
-			warnf("could not enhance commit message, %s", err.Error())
-			return nil
+			return sv.ReleaseNote{}, false, fmt.Errorf("error getting git log from tag: %s, message: %v", tag.Name, err)
 		}
-		if msg == "" {
-			return nil
+		if cfg.ReleaseNotes.DetectSharedCommits {
+			commits = sv.AnnotateSharedCommits(commits, component.Name, roots)
+		}
+		if cfg.Monorepo.Changelog.RelativePaths {
+			commits = sv.RelativeCommitPaths(commits, roots[component.Name])
 		}
 
-		if err := appendOnFile(msg, filepath); err != nil {
-			return fmt.Errorf("failed to append meta-informations on footer, error: %s", err.Error())
+		var version *semver.Version
+		if cfg.Monorepo.Changelog.StripTagPrefix {
+			version, _ = sv.ToVersion(sv.ComponentTagVersion(tag.Name))
 		}
+		return rnProcessor.Create(version, tag.Name, previousTag, tag.Date, commits), true, nil
+	}, len(indexes), nil
+}
 
-		return nil
+// concatReleaseNotes returns release notes of sources, in order.
+func concatReleaseNotes(sources ...sv.ReleaseNoteSource) sv.ReleaseNoteSource {
+	return func() (sv.ReleaseNote, bool, error) {
+		for len(sources) > 0 {
+			rn, ok, err := sources[0]()
+			if err != nil || ok {
+				return rn, ok, err
+			}
+			sources = sources[1:]
+		}
+		return sv.ReleaseNote{}, false, nil
 	}
 }
 
-func readFile(filepath string) (string, error) {
-	f, err := os.ReadFile(filepath)
-	if err != nil {
-		return "", err
+// collectReleaseNotes reads every release note of source.
+func collectReleaseNotes(source sv.ReleaseNoteSource) ([]sv.ReleaseNote, error) {
+	var result []sv.ReleaseNote
+	for {
+		rn, ok, err := source()
+		if err != nil || !ok {
+			return result, err
+		}
+		result = append(result, rn)
 	}
-	return string(f), nil
 }
 
-func appendOnFile(message, filepath string) error {
-	f, err := os.OpenFile(filepath, os.O_APPEND|os.O_WRONLY, 0644)
+// componentChangelogPath resolves monorepo.changelog-path template for component, paths outside repository are rejected.
+func componentChangelogPath(pathTemplate *template.Template, repoPath string, component sv.MonorepoComponent) (string, error) {
+	componentDir, err := filepath.Rel(repoPath, component.RootPath)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := pathTemplate.Execute(&b, struct{ ComponentName, ComponentDir string }{component.Name, filepath.ToSlash(componentDir)}); err != nil {
+		return "", err
 	}
-	defer f.Close()
 
-	_, err = f.WriteString(message)
-	return err
+	return repositoryFilePath(repoPath, b.String())
 }
 
-func str(value, defaultValue string) string {
-	if value != "" {
-		return value
+// repositoryFilePath resolves a changelog path relative to repository root, paths outside the repository are refused.
+func repositoryFilePath(repoPath, path string) (string, error) {
+	changelogPath := filepath.FromSlash(path)
+	if !filepath.IsAbs(changelogPath) {
+		changelogPath = filepath.Join(repoPath, changelogPath)
 	}
-	return defaultValue
+	changelogPath = filepath.Clean(changelogPath)
+
+	rel, err := filepath.Rel(repoPath, changelogPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside repository %s", changelogPath, repoPath)
+	}
+	return changelogPath, nil
 }
 
-func monorepoNextVersionHandler(
+func monorepoReleaseNotesHandler(
 	git sv.Git,
 	semverProcessor sv.SemVerCommitsProcessor,
 	monorepoProcessor sv.MonorepoProcessor,
+	rnProcessor sv.ReleaseNoteProcessor,
+	outputFormatter sv.OutputFormatter,
 	cfg Config,
 	repoPath string,
+	clock sv.Clock,
+	out *printer,
 ) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		components, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
 		if err != nil {
 			return fmt.Errorf("error finding monorepo components: %v", err)
 		}
+		logComponents(out, components, skipped)
 
-		for _, component := range components {
-			commits, cerr := componentCommits(git, repoPath, component)
-			if cerr != nil {
-				return fmt.Errorf("error getting commits for %s: %v", component.Name, cerr)
-			}
-
-			nextVer, updated := monorepoProcessor.NextVersion(component, commits, semverProcessor)
-			if !updated {
-				nextVer = component.CurrentVersion
-			}
-			fmt.Printf("%s: %s\n", component.Name, nextVer.String())
-		}
-		return nil
-	}
-}
-
-func monorepoTagHandler(
-	git sv.Git,
-	semverProcessor sv.SemVerCommitsProcessor,
-	monorepoProcessor sv.MonorepoProcessor,
-	cfg Config,
-	repoPath string,
-) func(c *cli.Context) error {
-	return func(c *cli.Context) error {
-		components, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		tags, err := git.TagsAll()
 		if err != nil {
-			return fmt.Errorf("error finding monorepo components: %v", err)
+			return fmt.Errorf("error listing tags, message: %v", err)
 		}
 
-		for _, component := range components {
-			commits, cerr := componentCommits(git, repoPath, component)
-			if cerr != nil {
-				return fmt.Errorf("error getting commits for %s: %v", component.Name, cerr)
-			}
+		name := c.String("component")
+		component, found := findComponent(name, components)
+		if !found && len(skipped) > 0 {
+			return fmt.Errorf("component: %s not found, %d component(s) skipped due to invalid versioning files", name, len(skipped))
+		}
+		if !found {
+			return fmt.Errorf("component: %s not found", name)
+		}
 
-			nextVer, updated := monorepoProcessor.NextVersion(component, commits, semverProcessor)
-			if !updated {
-				fmt.Printf("%s: no version change (current: %s)\n", component.Name, component.CurrentVersion.String())
-				continue
-			}
+		var commits []sv.GitCommitLog
+		var rnVersion *semver.Version
+		var date time.Time
+		var previousTag string
 
-			if uerr := monorepoProcessor.UpdateVersion(component, *nextVer, cfg.Monorepo); uerr != nil {
-				return fmt.Errorf("error updating version for %s: %v", component.Name, uerr)
-			}
+		withFiles := cfg.ReleaseNotes.DetectSharedCommits
+		componentTags := filterComponentTags(tags, component)
+		tag := c.String("t")
+		if tag != "" {
+			rnVersion, previousTag, date, commits, err = getComponentTagVersionInfo(git, repoPath, component, tag, componentTags, withFiles)
+		} else {
+			rnVersion, previousTag, commits, err = componentNextReleaseNoteInfo(c, git, semverProcessor, monorepoProcessor, cfg, repoPath, component, componentTags, out)
+			date = clock.Now()
+		}
+		if err != nil {
+			return err
+		}
 
-			relDir, rerr := filepath.Rel(repoPath, component.RootPath)
+		if withFiles {
+			roots, rerr := componentRoots(repoPath, components)
 			if rerr != nil {
-				return fmt.Errorf("error resolving path for %s: %v", component.Name, rerr)
-			}
-			tagName, terr := git.TagForComponent(*nextVer, relDir)
-			if terr != nil {
-				return fmt.Errorf("error creating tag for %s: %v", component.Name, terr)
+				return rerr
 			}
-			fmt.Printf("%s: %s\n", component.Name, tagName)
+			commits = sv.AnnotateSharedCommits(commits, component.Name, roots)
 		}
-		return nil
-	}
-}
 
-func monorepoUpdateVersionHandler(
-	git sv.Git,
-	semverProcessor sv.SemVerCommitsProcessor,
-	monorepoProcessor sv.MonorepoProcessor,
-	cfg Config,
-	repoPath string,
-) func(c *cli.Context) error {
-	return func(c *cli.Context) error {
-		components, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+		releasenote := rnProcessor.Create(rnVersion, tag, previousTag, date, commits)
+		output, err := outputFormatter.FormatReleaseNote(releasenote)
 		if err != nil {
-			return fmt.Errorf("error finding monorepo components: %v", err)
+			return fmt.Errorf("could not format release notes, message: %v", err)
 		}
-
-		for _, component := range components {
-			commits, cerr := componentCommits(git, repoPath, component)
-			if cerr != nil {
-				return fmt.Errorf("error getting commits for %s: %v", component.Name, cerr)
-			}
-
-			nextVer, updated := monorepoProcessor.NextVersion(component, commits, semverProcessor)
-			if !updated {
-				fmt.Printf("%s: no version change (current: %s)\n", component.Name, component.CurrentVersion.String())
-				continue
-			}
-
-			if uerr := monorepoProcessor.UpdateVersion(component, *nextVer, cfg.Monorepo); uerr != nil {
-				return fmt.Errorf("error updating version for %s: %v", component.Name, uerr)
-			}
-			fmt.Printf("%s: %s written to %s\n", component.Name, nextVer.String(), component.VersioningFilePath)
+		if err := writeReleaseNote(c, releasenote, output, out); err != nil {
+			return err
 		}
-		return nil
+		return skippedComponentsError(skipped, cfg.Monorepo)
 	}
 }
 
-func monorepoChangelogHandler(
+// componentNextReleaseNoteInfo returns the next version of component, as monorepo-next-version calculates it, its previous
+// component tag and the commits since that tag. Without commits since the tag there is nothing to release.
+func componentNextReleaseNoteInfo(
+	c *cli.Context,
 	git sv.Git,
 	semverProcessor sv.SemVerCommitsProcessor,
 	monorepoProcessor sv.MonorepoProcessor,
-	rnProcessor sv.ReleaseNoteProcessor,
-	outputFormatter sv.OutputFormatter,
 	cfg Config,
 	repoPath string,
-) func(c *cli.Context) error {
-	return func(c *cli.Context) error {
-		components, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+	component sv.MonorepoComponent,
+	componentTags []sv.GitTag,
+	out *printer,
+) (*semver.Version, string, []sv.GitCommitLog, error) {
+	previousTag := sv.LatestTag(componentTags)
+	cache := newComponentCache(git)
+	commits, err := cache.componentCommits(repoPath, component, componentTags, cfg.ReleaseNotes.DetectSharedCommits, out)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error getting commits for %s: %v", component.Name, err)
+	}
+	if len(commits) == 0 {
+		return nil, "", nil, nothingToReleaseError(previousTag, releaseNotesHint(previousTag))
+	}
+
+	channel, err := prereleaseChannel(git, cfg.Monorepo, out)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	_, nextVer, updated, err := componentVersion(c, cache, monorepoProcessor, semverProcessor, cfg.Versioning, repoPath, component, componentTags, channel, "", out)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if !updated {
+		nextVer = component.CurrentVersion
+	}
+	return nextVer, previousTag, commits, nil
+}
+
+// componentRoots maps component names to their slash separated directories relative to repository root.
+func componentRoots(repoPath string, components []sv.MonorepoComponent) (map[string]string, error) {
+	roots := make(map[string]string, len(components))
+	for _, component := range components {
+		relDir, err := filepath.Rel(repoPath, component.RootPath)
 		if err != nil {
-			return fmt.Errorf("error finding monorepo components: %v", err)
+			return nil, fmt.Errorf("error resolving path for %s: %v", component.Name, err)
 		}
+		roots[component.Name] = filepath.ToSlash(relDir)
+	}
+	return roots, nil
+}
 
-		for _, component := range components {
-			commits, cerr := componentCommits(git, repoPath, component)
-			if cerr != nil {
-				return fmt.Errorf("error getting commits for %s: %v", component.Name, cerr)
-			}
-
-			nextVer, updated := monorepoProcessor.NextVersion(component, commits, semverProcessor)
-			if !updated {
-				fmt.Printf("%s: no changes, skipping changelog\n", component.Name)
-				continue
-			}
+func findComponent(name string, components []sv.MonorepoComponent) (sv.MonorepoComponent, bool) {
+	for _, component := range components {
+		if component.Name == name {
+			return component, true
+		}
+	}
+	return sv.MonorepoComponent{}, false
+}
 
-			var date time.Time
-			if len(commits) > 0 {
-				date, _ = time.Parse("2006-01-02", commits[0].Date)
-			} else {
-				date = time.Now()
-			}
+// getComponentTagVersionInfo returns version, previous component tag, date and commits restricted to the component path
+// between the previous component tag and the given tag, tags must be the component tags sorted by creation date.
+func getComponentTagVersionInfo(git sv.Git, repoPath string, component sv.MonorepoComponent, tag string, tags []sv.GitTag, withFiles bool) (*semver.Version, string, time.Time, []sv.GitCommitLog, error) {
+	relDir, err := filepath.Rel(repoPath, component.RootPath)
+	if err != nil {
+		return nil, "", time.Time{}, nil, fmt.Errorf("error resolving path for %s: %v", component.Name, err)
+	}
 
-			releaseNote := rnProcessor.Create(nextVer, "", date, commits)
-			output, ferr := outputFormatter.FormatChangelog([]sv.ReleaseNote{releaseNote})
-			if ferr != nil {
-				return fmt.Errorf("could not format changelog for %s: %v", component.Name, ferr)
-			}
+	index := find(tag, tags)
+	if index < 0 {
+		return nil, "", time.Time{}, nil, fmt.Errorf("tag: %s not found for component %s", tag, component.Name)
+	}
+	previousTag := ""
+	if index > 0 {
+		previousTag = tags[index-1].Name
+	}
 
-			changelogPath := filepath.Join(component.RootPath, "CHANGELOG.md")
-			if werr := os.WriteFile(changelogPath, []byte(output), 0600); werr != nil {
-				return fmt.Errorf("could not write changelog for %s: %v", component.Name, werr)
-			}
-			fmt.Printf("%s: changelog written to %s\n", component.Name, changelogPath)
-		}
-		return nil
+	lr := sv.NewLogRangeWithPaths(sv.TagRange, previousTag, tag, append([]string{filepath.ToSlash(relDir)}, component.PreviousPaths...))
+	if withFiles {
+		lr = lr.WithFiles()
 	}
+	commits, err := git.Log(lr)
+	if err != nil {
+		return nil, "", time.Time{}, nil, fmt.Errorf("error getting git log from tag: %s, message: %v", tag, err)
+	}
+
+	tagVersion, _ := sv.ToVersion(sv.ComponentTagVersion(tag))
+	return tagVersion, previousTag, tags[index].Date, commits, nil
 }
 
 // componentCommits returns commits that touched the component's directory since the
-// last Go-style component tag (e.g. "templates/my-component/v1.2.3").
+// last Go-style component tag (e.g. "templates/my-component/v1.2.3"), tags are prefixed with component name.
 // Falls back to all directory commits when no component tag exists yet (first run).
-func componentCommits(git sv.Git, repoPath string, component sv.MonorepoComponent) ([]sv.GitCommitLog, error) {
-	relDir, err := filepath.Rel(repoPath, component.RootPath)
+// componentTags must be sorted by creation date, see sv.FilterComponentTags.
+// withFiles also loads files changed by each commit, required to detect commits shared with other components.
+func componentCommits(git sv.Git, repoPath string, component sv.MonorepoComponent, componentTags []sv.GitTag, withFiles bool, out *printer) ([]sv.GitCommitLog, error) {
+	lr, _, err := componentLogRange(repoPath, component, componentTags, out)
 	if err != nil {
 		return nil, err
 	}
-	lastTag := git.LastComponentTag(relDir)
-	lr := sv.NewLogRangeWithPaths(sv.TagRange, lastTag, "", []string{relDir})
+	if withFiles {
+		lr = lr.WithFiles()
+	}
 	return git.Log(lr)
 }
+
+// componentVersionCommits returns the commits of componentCommits used to calculate versions, commits that changed only
+// versioning.ignore-paths inside the component directory are removed. Commits after ref, if not empty, are ignored.
+func componentVersionCommits(git sv.Git, repoPath string, component sv.MonorepoComponent, componentTags []sv.GitTag, ref string, cfg sv.VersioningConfig, out *printer) ([]sv.GitCommitLog, error) {
+	lr, paths, err := componentLogRange(repoPath, component, componentTags, out)
+	if err != nil {
+		return nil, err
+	}
+	return versionCommits(git, lr.WithEnd(ref), cfg, paths, out)
+}
+
+// componentLogRange returns the log range of component directory since the last component tag and the slash separated
+// directories of the component, the current one first followed by its previous paths, so history is kept across renames.
+func componentLogRange(repoPath string, component sv.MonorepoComponent, componentTags []sv.GitTag, out *printer) (sv.LogRange, []string, error) {
+	relDir, err := filepath.Rel(repoPath, component.RootPath)
+	if err != nil {
+		return sv.LogRange{}, nil, err
+	}
+	paths := append([]string{filepath.ToSlash(relDir)}, component.PreviousPaths...)
+	lastTag := sv.LatestTag(componentTags)
+	if lastTag != "" {
+		out.debugf("component %s: baseline is tag %s, using commits on %s since the tag", component.Name, lastTag, strings.Join(paths, ", "))
+	} else {
+		out.debugf("component %s: no component tag found, using all commits on %s", component.Name, strings.Join(paths, ", "))
+	}
+	return sv.NewLogRangeWithPaths(sv.TagRange, lastTag, "", paths), paths, nil
+}
+
+// filterComponentTags returns the tags of component, including tags created under its previous paths, see sv.FilterComponentTags.
+func filterComponentTags(tags []sv.GitTag, component sv.MonorepoComponent) []sv.GitTag {
+	return sv.FilterComponentTags(tags, component.Name, component.PreviousPaths...)
+}
+
+// versionCommits returns commits used to calculate versions, commits that changed only versioning.ignore-paths inside paths are removed.
+// Files changed by each commit are loaded only if ignore paths are configured.
+func versionCommits(git sv.Git, lr sv.LogRange, cfg sv.VersioningConfig, paths []string, out *printer) ([]sv.GitCommitLog, error) {
+	if len(cfg.IgnorePaths) == 0 {
+		return git.Log(lr)
+	}
+	commits, err := git.Log(lr.WithFiles())
+	if err != nil {
+		return nil, err
+	}
+	filtered := sv.WithoutIgnoredPaths(commits, cfg.IgnorePaths, paths)
+	if out.debugEnabled() && len(filtered) < len(commits) {
+		kept := make(map[string]bool, len(filtered))
+		for _, commit := range filtered {
+			kept[commit.Hash] = true
+		}
+		for _, commit := range commits {
+			if !kept[commit.Hash] {
+				out.debugf("commit %s: ignored, changed only versioning.ignore-paths", commit.Hash)
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// commitDate returns the commit date, the author date or timestamp is used when available so it can be converted to
+// release-notes.timezone. A date that does not parse is an error, release notes never show a zero date.
+func commitDate(commit sv.GitCommitLog) (time.Time, error) {
+	if !commit.AuthorDate.IsZero() {
+		return commit.AuthorDate, nil
+	}
+	if commit.Timestamp > 0 {
+		return time.Unix(int64(commit.Timestamp), 0), nil
+	}
+	date, err := time.Parse("2006-01-02", commit.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q of commit %s", commit.Date, commit.Hash)
+	}
+	return date, nil
+}
+
+// latestCommitDate returns the most recent commit date, git log order is not always by date, e.g. after a rebase.
+func latestCommitDate(commits []sv.GitCommitLog) (time.Time, error) {
+	var latest time.Time
+	for _, commit := range commits {
+		date, err := commitDate(commit)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if date.After(latest) {
+			latest = date
+		}
+	}
+	return latest, nil
+}
+
+// debugBaseline logs the tag used as starting point to calculate the next version.
+func debugBaseline(out *printer, lastTag string) {
+	if lastTag == "" {
+		out.debugf("no tag found, using all commits")
+		return
+	}
+	out.debugf("baseline is tag %s, using commits since the tag", lastTag)
+}
+
+// debugCommits logs the parsed type of each commit and its version bump, calculated as if it was the only commit.
+func debugCommits(out *printer, semverProcessor sv.SemVerCommitsProcessor, commits []sv.GitCommitLog) {
+	if !out.debugEnabled() {
+		return
+	}
+	base := semver.MustParse("1.0.0")
+	for _, commit := range commits {
+		next, _ := semverProcessor.NextVersion(base, []sv.GitCommitLog{commit})
+		out.debugf("commit %s: type %q, scope %q, breaking change %v, bump %s", commit.Hash, commit.Message.Type, commit.Message.Scope, commit.Message.IsBreakingChange, bumpLevel(base, next))
+	}
+}
@@ -1,46 +1,291 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/bvieira/sv4git/v2/sv"
 	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
 )
 
 // ---- mock implementations ----
 
 type mockGit struct {
-	lastComponentTagFn   func(componentPath string) string
-	logFn                func(lr sv.LogRange) ([]sv.GitCommitLog, error)
-	tagForComponentFn    func(version semver.Version, componentPath string) (string, error)
-}
-
-func (m mockGit) LastTag() string                                              { return "" }
-func (m mockGit) Log(lr sv.LogRange) ([]sv.GitCommitLog, error)               { return m.logFn(lr) }
-func (m mockGit) Commit(header, body, footer string) error                     { return nil }
-func (m mockGit) Tag(version semver.Version) (string, error)                   { return "", nil }
-func (m mockGit) Tags() ([]sv.GitTag, error)                                   { return nil, nil }
-func (m mockGit) Branch() string                                               { return "" }
-func (m mockGit) IsDetached() (bool, error)                                    { return false, nil }
-func (m mockGit) LastComponentTag(componentPath string) string                 { return m.lastComponentTagFn(componentPath) }
-func (m mockGit) TagForComponent(version semver.Version, componentPath string) (string, error) {
+	lastTag            string
+	tagFn              func(version semver.Version, remote string) (string, error)
+	tagAtFn            func(version semver.Version, ref, remote string) (string, error)
+	tagsFn             func() ([]sv.GitTag, error)
+	tagsAllFn          func() ([]sv.GitTag, error)
+	logFn              func(lr sv.LogRange) ([]sv.GitCommitLog, error)
+	logAllFn           func(paths []string) ([]sv.GitCommitLog, error)
+	tagForComponentFn  func(version semver.Version, componentPath string) (string, error)
+	tagForComponentAt  func(version semver.Version, componentPath, ref string) (string, error)
+	deleteTagFn        func(tag, remote string) error
+	isCleanFn          func() (bool, []string, error)
+	addFn              func(paths ...string) error
+	stagedDiffStatFn   func() (string, error)
+	stagedFilesFn      func() ([]string, error)
+	branch             string
+	hasStagedChangesFn func() (bool, error)
+	isShallowFn        func() (bool, error)
+	unshallowFn        func() error
+	fetchFn            func(tagsOnly bool) error
+	remoteExistsFn     func(remote string) (bool, error)
+	tagRemote          string
+	commitFn           func(header, body, footer string, paths ...string) error
+	pushFn             func() error
+	pushTagsFn         func(remote string, tags []string) error
+	showFileFn         func(revision, path string) ([]byte, error)
+	shortHashFn        func(revision string) (string, error)
+	versionFn          func() (string, error)
+	hooksPathFn        func() (string, error)
+	patchIDFn          func(hash string) (string, error)
+	isAncestorFn       func(ancestor, revision string) (bool, error)
+	mergeBaseFn        func(a, b string) (string, error)
+	defaultBranchFn    func() (string, error)
+	isDetachedFn       func() (bool, error)
+	behindUpstreamFn   func() (string, int, error)
+	pushedCommitsFn    func(oldRev, newRev, ref string) ([]sv.GitRawCommit, error)
+	tagTargetFn        func(tag string) (string, bool, error)
+	replaceTagFn       func(tag, message, commit string, date time.Time) error
+	forcePushTagsFn    func(remote string, tags []string) error
+}
+
+func (m mockGit) LastTag() string                               { return m.lastTag }
+func (m mockGit) Log(lr sv.LogRange) ([]sv.GitCommitLog, error) { return m.logFn(lr) }
+func (m mockGit) LogAll(paths []string) ([]sv.GitCommitLog, error) {
+	if m.logAllFn != nil {
+		return m.logAllFn(paths)
+	}
+	return nil, nil
+}
+func (m mockGit) Commit(header, body, footer string, paths ...string) error {
+	if m.commitFn != nil {
+		return m.commitFn(header, body, footer, paths...)
+	}
+	return nil
+}
+func (m mockGit) Tag(version semver.Version, remote string) (string, error) {
+	if m.tagFn != nil {
+		return m.tagFn(version, remote)
+	}
+	return "", nil
+}
+func (m mockGit) TagAt(version semver.Version, ref, remote string) (string, error) {
+	if m.tagAtFn != nil {
+		return m.tagAtFn(version, ref, remote)
+	}
+	return m.Tag(version, remote)
+}
+func (m mockGit) TagName(version semver.Version) string { return "v" + version.String() }
+func (m mockGit) Tags() ([]sv.GitTag, error) {
+	if m.tagsFn != nil {
+		return m.tagsFn()
+	}
+	return nil, nil
+}
+func (m mockGit) Branch() string { return m.branch }
+func (m mockGit) IsDetached() (bool, error) {
+	if m.isDetachedFn != nil {
+		return m.isDetachedFn()
+	}
+	return false, nil
+}
+func (m mockGit) PushedCommits(oldRev, newRev, ref string) ([]sv.GitRawCommit, error) {
+	return m.pushedCommitsFn(oldRev, newRev, ref)
+}
+func (m mockGit) Version() (string, error) {
+	if m.versionFn != nil {
+		return m.versionFn()
+	}
+	return "2.39.2", nil
+}
+func (m mockGit) HooksPath() (string, error) {
+	if m.hooksPathFn != nil {
+		return m.hooksPathFn()
+	}
+	return "", errors.New("not a git repository")
+}
+func (m mockGit) BehindUpstream() (string, int, error) {
+	if m.behindUpstreamFn != nil {
+		return m.behindUpstreamFn()
+	}
+	return "origin/master", 0, nil
+}
+func (m mockGit) IsClean() (bool, []string, error) {
+	if m.isCleanFn != nil {
+		return m.isCleanFn()
+	}
+	return true, nil, nil
+}
+func (m mockGit) TagsAll() ([]sv.GitTag, error) {
+	if m.tagsAllFn != nil {
+		return m.tagsAllFn()
+	}
+	return nil, nil
+}
+func (m mockGit) LastComponentTag(componentPath string, previousPaths ...string) string {
+	tags, _ := m.TagsAll()
+	return sv.LatestTag(sv.FilterComponentTags(tags, componentPath, previousPaths...))
+}
+func (m mockGit) ComponentTags(componentPath string, previousPaths ...string) ([]sv.GitTag, error) {
+	tags, err := m.TagsAll()
+	return sv.FilterComponentTags(tags, componentPath, previousPaths...), err
+}
+func (m mockGit) TagForComponent(version semver.Version, componentPath, remote string) (string, error) {
 	return m.tagForComponentFn(version, componentPath)
 }
+func (m mockGit) TagForComponentAt(version semver.Version, componentPath, ref, remote string) (string, error) {
+	return m.tagForComponentAt(version, componentPath, ref)
+}
+func (m mockGit) ComponentTagName(version semver.Version, componentPath string) string {
+	return componentPath + "/v" + version.String()
+}
+func (m mockGit) DeleteTag(tag, remote string) error {
+	if m.deleteTagFn != nil {
+		return m.deleteTagFn(tag, remote)
+	}
+	return nil
+}
+func (m mockGit) TagTarget(tag string) (string, bool, error) {
+	if m.tagTargetFn != nil {
+		return m.tagTargetFn(tag)
+	}
+	return "abc1234", true, nil
+}
+func (m mockGit) ReplaceTag(tag, message, commit string, date time.Time) error {
+	if m.replaceTagFn != nil {
+		return m.replaceTagFn(tag, message, commit, date)
+	}
+	return nil
+}
+func (m mockGit) Add(paths ...string) error {
+	if m.addFn != nil {
+		return m.addFn(paths...)
+	}
+	return nil
+}
+func (m mockGit) StagedDiffStat() (string, error) {
+	if m.stagedDiffStatFn != nil {
+		return m.stagedDiffStatFn()
+	}
+	return "", nil
+}
+func (m mockGit) StagedFiles() ([]string, error) {
+	if m.stagedFilesFn != nil {
+		return m.stagedFilesFn()
+	}
+	return nil, nil
+}
+func (m mockGit) HasStagedChanges() (bool, error) {
+	if m.hasStagedChangesFn != nil {
+		return m.hasStagedChangesFn()
+	}
+	return true, nil
+}
+func (m mockGit) IsShallow() (bool, error) {
+	if m.isShallowFn != nil {
+		return m.isShallowFn()
+	}
+	return false, nil
+}
+func (m mockGit) Unshallow() error {
+	if m.unshallowFn != nil {
+		return m.unshallowFn()
+	}
+	return nil
+}
+func (m mockGit) Fetch(tagsOnly bool) error {
+	if m.fetchFn != nil {
+		return m.fetchFn(tagsOnly)
+	}
+	return nil
+}
+func (m mockGit) Push() error {
+	if m.pushFn != nil {
+		return m.pushFn()
+	}
+	return nil
+}
+func (m mockGit) PushTags(remote string, tags []string) error {
+	if m.pushTagsFn != nil {
+		return m.pushTagsFn(remote, tags)
+	}
+	return nil
+}
+func (m mockGit) ForcePushTags(remote string, tags []string) error {
+	if m.forcePushTagsFn != nil {
+		return m.forcePushTagsFn(remote, tags)
+	}
+	return nil
+}
+func (m mockGit) ShowFile(revision, path string) ([]byte, error) {
+	if m.showFileFn != nil {
+		return m.showFileFn(revision, path)
+	}
+	return nil, nil
+}
+func (m mockGit) ShortHash(revision string) (string, error) {
+	if m.shortHashFn != nil {
+		return m.shortHashFn(revision)
+	}
+	return "abc1234", nil
+}
+func (m mockGit) PatchID(hash string) (string, error) {
+	if m.patchIDFn != nil {
+		return m.patchIDFn(hash)
+	}
+	return "", nil
+}
+func (m mockGit) IsAncestor(ancestor, revision string) (bool, error) {
+	if m.isAncestorFn != nil {
+		return m.isAncestorFn(ancestor, revision)
+	}
+	return true, nil
+}
+func (m mockGit) MergeBase(a, b string) (string, error) {
+	if m.mergeBaseFn != nil {
+		return m.mergeBaseFn(a, b)
+	}
+	return "abc1234", nil
+}
+func (m mockGit) DefaultBranch() (string, error) {
+	if m.defaultBranchFn != nil {
+		return m.defaultBranchFn()
+	}
+	return "origin/master", nil
+}
+func (m mockGit) TagRemote() string { return m.tagRemote }
+func (m mockGit) RemoteExists(remote string) (bool, error) {
+	if m.remoteExistsFn != nil {
+		return m.remoteExistsFn(remote)
+	}
+	return true, nil
+}
 
 type mockMonorepoProcessor struct {
 	findComponentsFn func(repoRoot string, cfg sv.MonorepoConfig) ([]sv.MonorepoComponent, error)
+	skipped          []sv.ComponentError
 	nextVersionFn    func(component sv.MonorepoComponent, commits []sv.GitCommitLog, semverProc sv.SemVerCommitsProcessor) (*semver.Version, bool)
 	updateVersionFn  func(component sv.MonorepoComponent, version semver.Version, cfg sv.MonorepoConfig) error
 }
 
-func (m mockMonorepoProcessor) FindComponents(repoRoot string, cfg sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
-	return m.findComponentsFn(repoRoot, cfg)
+func (m mockMonorepoProcessor) FindComponents(repoRoot string, cfg sv.MonorepoConfig) ([]sv.MonorepoComponent, []sv.ComponentError, error) {
+	components, err := m.findComponentsFn(repoRoot, cfg)
+	return components, m.skipped, err
 }
 func (m mockMonorepoProcessor) NextVersion(component sv.MonorepoComponent, commits []sv.GitCommitLog, semverProc sv.SemVerCommitsProcessor) (*semver.Version, bool) {
 	return m.nextVersionFn(component, commits, semverProc)
@@ -51,23 +296,33 @@ func (m mockMonorepoProcessor) UpdateVersion(component sv.MonorepoComponent, ver
 
 type mockSemVerProcessor struct {
 	nextVersionFn func(version *semver.Version, commits []sv.GitCommitLog) (*semver.Version, bool)
+	explainFn     func(commits []sv.GitCommitLog) sv.BumpExplanation
 }
 
 func (m mockSemVerProcessor) NextVersion(version *semver.Version, commits []sv.GitCommitLog) (*semver.Version, bool) {
 	return m.nextVersionFn(version, commits)
 }
 
+func (m mockSemVerProcessor) Explain(commits []sv.GitCommitLog) sv.BumpExplanation {
+	return m.explainFn(commits)
+}
+
 type mockReleaseNoteProcessor struct{}
 
-func (m mockReleaseNoteProcessor) Create(version *semver.Version, tag string, date time.Time, commits []sv.GitCommitLog) sv.ReleaseNote {
-	return sv.ReleaseNote{Version: version, Tag: tag, Date: date}
+func (m mockReleaseNoteProcessor) Create(version *semver.Version, tag, previousTag string, date time.Time, commits []sv.GitCommitLog) sv.ReleaseNote {
+	return sv.ReleaseNote{Version: version, Tag: tag, PreviousTag: previousTag, Date: date}
 }
 
 type mockOutputFormatter struct {
-	formatChangelogFn func(releasenotes []sv.ReleaseNote) (string, error)
+	formatChangelogFn         func(releasenotes []sv.ReleaseNote) (string, error)
+	formatMonorepoChangelogFn func(releasenotes map[string][]sv.ReleaseNote) (string, error)
+	formatReleaseNoteFn       func(releasenote sv.ReleaseNote) (string, error)
 }
 
 func (m mockOutputFormatter) FormatReleaseNote(releasenote sv.ReleaseNote) (string, error) {
+	if m.formatReleaseNoteFn != nil {
+		return m.formatReleaseNoteFn(releasenote)
+	}
 	return "", nil
 }
 func (m mockOutputFormatter) FormatChangelog(releasenotes []sv.ReleaseNote) (string, error) {
@@ -76,6 +331,24 @@ func (m mockOutputFormatter) FormatChangelog(releasenotes []sv.ReleaseNote) (str
 	}
 	return "# Changelog\n", nil
 }
+func (m mockOutputFormatter) WriteChangelog(w io.Writer, next sv.ReleaseNoteSource) error {
+	releasenotes, err := collectReleaseNotes(next)
+	if err != nil {
+		return err
+	}
+	output, err := m.FormatChangelog(releasenotes)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, output)
+	return err
+}
+func (m mockOutputFormatter) FormatMonorepoChangelog(releasenotes map[string][]sv.ReleaseNote) (string, error) {
+	if m.formatMonorepoChangelogFn != nil {
+		return m.formatMonorepoChangelogFn(releasenotes)
+	}
+	return "", nil
+}
 
 // newCLICtx creates a minimal *cli.Context suitable for calling handlers under test.
 func newCLICtx() *cli.Context {
@@ -99,14 +372,26 @@ func makeComponent(t *testing.T, name, version string) sv.MonorepoComponent {
 	}
 }
 
+func Test_logComponents_SecondaryFiles(t *testing.T) {
+	comp := makeComponent(t, "api", "1.2.0")
+	comp.SecondaryFiles = []sv.ComponentFile{
+		{Path: filepath.Join(comp.RootPath, "chart.yaml"), Version: semver.MustParse("1.2.0")},
+		{Path: filepath.Join(comp.RootPath, "deployment.yaml"), Version: semver.MustParse("1.1.0")},
+	}
+	var stderr bytes.Buffer
+	logComponents(newPrinter(io.Discard, &stderr), []sv.MonorepoComponent{comp}, nil)
+	if got := stderr.String(); !strings.Contains(got, "deployment.yaml has version 1.1.0, different from 1.2.0") || strings.Contains(got, "chart.yaml") {
+		t.Errorf("logComponents() stderr = %q, want warning only for deployment.yaml", got)
+	}
+}
+
 // ---- monorepoNextVersionHandler tests ----
 
 func Test_monorepoNextVersionHandler_NoUpdate(t *testing.T) {
 	comp := makeComponent(t, "alpha", "1.0.0")
 
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
@@ -119,7 +404,8 @@ func Test_monorepoNextVersionHandler_NoUpdate(t *testing.T) {
 	semverProc := mockSemVerProcessor{nextVersionFn: func(v *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) { return v, false }}
 	cfg := Config{Monorepo: sv.MonorepoConfig{VersioningFile: "*/package.json", Path: "version"}}
 
-	handler := monorepoNextVersionHandler(git, semverProc, mnrp, cfg, t.TempDir())
+	out, _ := newTestPrinter()
+	handler := monorepoNextVersionHandler(git, semverProc, mnrp, cfg, t.TempDir(), out)
 	if err := handler(newCLICtx()); err != nil {
 		t.Errorf("monorepoNextVersionHandler() unexpected error: %v", err)
 	}
@@ -130,8 +416,7 @@ func Test_monorepoNextVersionHandler_WithUpdate(t *testing.T) {
 	nextVer := semver.MustParse("1.1.0")
 
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
@@ -144,7 +429,8 @@ func Test_monorepoNextVersionHandler_WithUpdate(t *testing.T) {
 	semverProc := mockSemVerProcessor{nextVersionFn: func(v *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) { return nextVer, true }}
 	cfg := Config{Monorepo: sv.MonorepoConfig{VersioningFile: "*/package.json", Path: "version"}}
 
-	handler := monorepoNextVersionHandler(git, semverProc, mnrp, cfg, t.TempDir())
+	out, _ := newTestPrinter()
+	handler := monorepoNextVersionHandler(git, semverProc, mnrp, cfg, t.TempDir(), out)
 	if err := handler(newCLICtx()); err != nil {
 		t.Errorf("monorepoNextVersionHandler() unexpected error: %v", err)
 	}
@@ -152,8 +438,7 @@ func Test_monorepoNextVersionHandler_WithUpdate(t *testing.T) {
 
 func Test_monorepoNextVersionHandler_FindComponentsError(t *testing.T) {
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
@@ -163,20 +448,111 @@ func Test_monorepoNextVersionHandler_FindComponentsError(t *testing.T) {
 	semverProc := mockSemVerProcessor{}
 	cfg := Config{}
 
-	handler := monorepoNextVersionHandler(git, semverProc, mnrp, cfg, t.TempDir())
+	out, _ := newTestPrinter()
+	handler := monorepoNextVersionHandler(git, semverProc, mnrp, cfg, t.TempDir(), out)
 	if err := handler(newCLICtx()); err == nil {
 		t.Error("monorepoNextVersionHandler() expected error when FindComponents fails, got nil")
 	}
 }
 
+func Test_monorepoNextVersionHandler_SkippedComponents(t *testing.T) {
+	comp := makeComponent(t, "alpha", "1.0.0")
+
+	tests := []struct {
+		name         string
+		onParseError string
+		wantErr      bool
+	}{
+		{"skip", sv.OnParseErrorSkip, false},
+		{"warn", sv.OnParseErrorWarn, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				skipped: []sv.ComponentError{{Path: "beta/package.json", Err: errors.New("invalid version")}},
+				nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return component.CurrentVersion, false
+				},
+			}
+			cfg := Config{Monorepo: sv.MonorepoConfig{VersioningFile: "*/package.json", Path: "version", OnParseError: tt.onParseError}}
+
+			out, _ := newTestPrinter()
+			handler := monorepoNextVersionHandler(git, mockSemVerProcessor{}, mnrp, cfg, t.TempDir(), out)
+			if err := handler(newCLICtx()); (err != nil) != tt.wantErr {
+				t.Errorf("monorepoNextVersionHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_monorepoNextVersionHandler_Debug(t *testing.T) {
+	comp := makeComponent(t, "alpha", "1.0.0")
+	repoPath := filepath.Dir(comp.RootPath)
+
+	git := mockGit{
+		tagsAllFn: func() ([]sv.GitTag, error) {
+			return []sv.GitTag{{Name: "alpha/v1.0.0", Date: time.Now()}}, nil
+		},
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{{Hash: "abc1234", Message: sv.CommitMessage{Type: "feat"}}}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{comp}, nil
+		},
+		nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+	semverProcessor := mockSemVerProcessor{
+		nextVersionFn: func(version *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) {
+			next := version.IncMinor()
+			return &next, true
+		},
+	}
+
+	tests := []struct {
+		name  string
+		level logLevel
+		want  []string
+	}{
+		{"info", levelInfo, nil},
+		{"debug", levelDebug, []string{
+			"DEBUG: component alpha found at " + comp.RootPath + ", current version 1.0.0\n",
+			"DEBUG: component alpha: baseline is tag alpha/v1.0.0, using commits on alpha since the tag\n",
+			"DEBUG: commit abc1234: type \"feat\", scope \"\", breaking change false, bump minor\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			out := newPrinter(io.Discard, &stderr)
+			out.level = tt.level
+			handler := monorepoNextVersionHandler(git, semverProcessor, mnrp, Config{}, repoPath, out)
+			if err := handler(newCLICtx()); err != nil {
+				t.Fatalf("monorepoNextVersionHandler() error = %v", err)
+			}
+			if want := strings.Join(tt.want, ""); stderr.String() != want {
+				t.Errorf("monorepoNextVersionHandler() stderr = %q, want %q", stderr.String(), want)
+			}
+		})
+	}
+}
+
 // ---- monorepoTagHandler tests ----
 
 func Test_monorepoTagHandler_SkipsNoUpdate(t *testing.T) {
 	comp := makeComponent(t, "beta", "2.0.0")
 
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
@@ -189,7 +565,8 @@ func Test_monorepoTagHandler_SkipsNoUpdate(t *testing.T) {
 	semverProc := mockSemVerProcessor{}
 	cfg := Config{}
 
-	handler := monorepoTagHandler(git, semverProc, mnrp, cfg, comp.RootPath)
+	out, _ := newTestPrinter()
+	handler := monorepoTagHandler(git, semverProc, mnrp, sv.NewMessageProcessor(sv.CommitMessageConfig{}, sv.BranchesConfig{}), cfg, comp.RootPath, sv.SystemClock{}, out)
 	if err := handler(newCLICtx()); err != nil {
 		t.Errorf("monorepoTagHandler() unexpected error: %v", err)
 	}
@@ -200,20 +577,336 @@ func Test_monorepoTagHandler_UpdatesAndTags(t *testing.T) {
 	comp := makeComponent(t, "gamma", "3.0.0")
 	// RootPath must be inside repoRoot for filepath.Rel to work.
 	comp.RootPath = filepath.Join(repoRoot, "gamma")
+	comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
 	if err := os.MkdirAll(comp.RootPath, 0755); err != nil {
 		t.Fatal(err)
 	}
 
-	nextVer := semver.MustParse("3.1.0")
-	var updatedVersion semver.Version
-	var createdTag string
+	tests := []struct {
+		name          string
+		lastTag       string
+		committed     string
+		nextVer       string
+		bumpAndCommit bool
+		wantUpdate    bool
+		wantTag       string
+		wantErr       bool
+	}{
+		{"bump and commit", "gamma/v3.0.0", "3.0.0", "3.1.0", true, true, "gamma/v3.1.0", false},
+		{"version file not bumped", "gamma/v3.0.0", "3.0.0", "3.1.0", false, false, "", true},
+		{"version bump already committed", "gamma/v3.0.0", "3.1.0", "3.1.1", false, false, "gamma/v3.1.0", false},
+		{"first release bump and commit", "", "3.0.0", "3.1.0", true, true, "gamma/v3.1.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updatedVersion *semver.Version
+			var createdTag, showFilePath string
+			committed := false
+
+			git := mockGit{
+				tagsAllFn: func() ([]sv.GitTag, error) {
+					if tt.lastTag == "" {
+						return nil, nil
+					}
+					return []sv.GitTag{{Name: tt.lastTag}}, nil
+				},
+				// the versioning file has uncommitted changes, its committed version is read with git show.
+				isCleanFn: func() (bool, []string, error) { return false, []string{"gamma/package.json"}, nil },
+				logFn:     func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+				showFileFn: func(revision, path string) ([]byte, error) {
+					showFilePath = revision + ":" + path
+					return []byte(`{"version": "` + tt.committed + `"}`), nil
+				},
+				commitFn: func(string, string, string, ...string) error {
+					committed = true
+					return nil
+				},
+				tagForComponentFn: func(version semver.Version, componentPath string) (string, error) {
+					createdTag = componentPath + "/v" + version.String()
+					return createdTag, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse(tt.nextVer), true
+				},
+				updateVersionFn: func(_ sv.MonorepoComponent, version semver.Version, _ sv.MonorepoConfig) error {
+					updatedVersion = &version
+					return nil
+				},
+			}
+			cfg := defaultConfig()
+			cfg.Monorepo.Path = "version"
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("bump-and-commit", tt.bumpAndCommit, "")
+			set.Bool("allow-dirty", true, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("monorepoTagHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if showFilePath != "HEAD:gamma/package.json" {
+				t.Errorf("ShowFile() called with %q, want HEAD:gamma/package.json", showFilePath)
+			}
+			if (updatedVersion != nil) != tt.wantUpdate || committed != tt.wantUpdate {
+				t.Errorf("monorepoTagHandler() updated = %v, committed = %v, want %v", updatedVersion, committed, tt.wantUpdate)
+			}
+			if createdTag != tt.wantTag {
+				t.Errorf("TagForComponent tag = %q, want %q", createdTag, tt.wantTag)
+			}
+		})
+	}
+}
+
+func Test_monorepoTagHandler_Downgrade(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "gamma", "4.0.0")
+	comp.RootPath = filepath.Join(repoRoot, "gamma")
+	comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+
+	tests := []struct {
+		name           string
+		lastTag        string
+		committed      string
+		allowDowngrade bool
+		wantTag        string
+		wantErr        string
+	}{
+		{"tag baseline", "gamma/v3.0.0", "3.0.0", false, "", "calculated from tag gamma/v3.0.0"},
+		{"file commit baseline", "gamma/v3.0.0", "3.1.0", false, "", "calculated from versioning file gamma/package.json committed on HEAD"},
+		{"all commits baseline", "", "3.1.0", false, "", "calculated from all commits"},
+		{"allow downgrade", "gamma/v3.0.0", "3.1.0", true, "gamma/v3.1.0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createdTag string
+			git := mockGit{
+				tagsAllFn: func() ([]sv.GitTag, error) {
+					if tt.lastTag == "" {
+						return nil, nil
+					}
+					return []sv.GitTag{{Name: tt.lastTag}}, nil
+				},
+				// the versioning file has uncommitted changes, its committed version is read with git show.
+				isCleanFn: func() (bool, []string, error) { return false, []string{"gamma/package.json"}, nil },
+				logFn:     func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+				showFileFn: func(string, string) ([]byte, error) {
+					return []byte(`{"version": "` + tt.committed + `"}`), nil
+				},
+				tagForComponentFn: func(version semver.Version, componentPath string) (string, error) {
+					createdTag = componentPath + "/v" + version.String()
+					return createdTag, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("3.1.0"), true
+				},
+			}
+			cfg := defaultConfig()
+			cfg.Monorepo.Path = "version"
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("allow-downgrade", tt.allowDowngrade, "")
+			set.Bool("allow-dirty", true, "")
 
+			out, _ := newTestPrinter()
+			handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("monorepoTagHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "gamma: calculated version 3.1.0 is lower than current version 4.0.0") {
+				t.Errorf("monorepoTagHandler() error = %v, want versions on message", err)
+			}
+			if createdTag != tt.wantTag {
+				t.Errorf("TagForComponent tag = %q, want %q", createdTag, tt.wantTag)
+			}
+		})
+	}
+}
+
+func Test_monorepoTagHandler_Commit(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "gamma", "3.0.0")
+	comp.RootPath = filepath.Join(repoRoot, "gamma")
+	comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+
+	tests := []struct {
+		name          string
+		afterTag      bool
+		bumpAndCommit bool
+		wantTag       string
+		wantErr       string
+	}{
+		{"tag commit", true, false, "gamma/v3.1.0@abc1234", ""},
+		{"commit before component tag", false, false, "", "gamma: commit abc1234 is not a descendant of last tag gamma/v3.0.0"},
+		{"bump and commit", true, true, "", "cannot use --commit with --bump-and-commit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createdTag, showFileRevision string
+			var logEnds []string
+			git := mockGit{
+				tagsAllFn: func() ([]sv.GitTag, error) { return []sv.GitTag{{Name: "gamma/v3.0.0"}}, nil },
+				logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+					logEnds = append(logEnds, fmt.Sprintf("%+v", lr))
+					return []sv.GitCommitLog{{Hash: "abc"}}, nil
+				},
+				showFileFn: func(revision, _ string) ([]byte, error) {
+					showFileRevision = revision
+					return []byte(`{"version": "3.1.0"}`), nil
+				},
+				isAncestorFn: func(ancestor, _ string) (bool, error) {
+					return ancestor == "abc1234" || tt.afterTag, nil
+				},
+				tagForComponentAt: func(version semver.Version, componentPath, ref string) (string, error) {
+					createdTag = componentPath + "/v" + version.String() + "@" + ref
+					return createdTag, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("3.1.0"), true
+				},
+			}
+			cfg := defaultConfig()
+			cfg.Monorepo.Path = "version"
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("commit", "HEAD~1", "")
+			set.Bool("bump-and-commit", tt.bumpAndCommit, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("monorepoTagHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if createdTag != tt.wantTag {
+				t.Errorf("TagForComponentAt tag = %q, want %q", createdTag, tt.wantTag)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if showFileRevision != "abc1234" {
+				t.Errorf("ShowFile() revision = %q, want abc1234", showFileRevision)
+			}
+			for _, lr := range logEnds {
+				if !strings.Contains(lr, "end:abc1234") {
+					t.Errorf("Log() range = %s, want end abc1234", lr)
+				}
+			}
+		})
+	}
+}
+
+func Test_monorepoTagHandler_ExistingTag(t *testing.T) {
+	repoRoot := t.TempDir()
+	var components []sv.MonorepoComponent
+	for _, name := range []string{"alpha", "beta"} {
+		comp := makeComponent(t, name, "1.1.0")
+		comp.RootPath = filepath.Join(repoRoot, name)
+		comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+		components = append(components, comp)
+	}
+
+	tests := []struct {
+		name        string
+		forceRetag  bool
+		wantDeleted []string
+		wantTags    []string
+		wantErr     bool
+	}{
+		{"continues with other components", false, nil, []string{"beta/v1.1.0"}, true},
+		{"force retag", true, []string{"alpha/v1.1.0"}, []string{"alpha/v1.1.0", "beta/v1.1.0"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted, created []string
+			git := mockGit{
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+				showFileFn: func(string, string) ([]byte, error) {
+					return []byte(`{"version": "1.1.0"}`), nil
+				},
+				tagForComponentFn: func(version semver.Version, componentPath string) (string, error) {
+					tag := componentPath + "/v" + version.String()
+					if componentPath == "alpha" && len(deleted) == 0 {
+						return tag, sv.TagExistsError{Tag: tag, Commit: "1a2b3c4"}
+					}
+					created = append(created, tag)
+					return tag, nil
+				},
+				deleteTagFn: func(tag, remote string) error {
+					deleted = append(deleted, tag)
+					return nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return components, nil
+				},
+				nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("1.1.0"), true
+				},
+			}
+			cfg := defaultConfig()
+			cfg.Monorepo.Path = "version"
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("force-retag", tt.forceRetag, "")
+			set.Bool("yes", true, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("monorepoTagHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && (exitCode(err) != exitCodeTagExists || !strings.Contains(err.Error(), "alpha/v1.1.0 already exists on commit 1a2b3c4")) {
+				t.Errorf("monorepoTagHandler() error = %v, exit code %d, want existing tag error", err, exitCode(err))
+			}
+			if !reflect.DeepEqual(deleted, tt.wantDeleted) || !reflect.DeepEqual(created, tt.wantTags) {
+				t.Errorf("monorepoTagHandler() deleted = %v, created = %v, want %v and %v", deleted, created, tt.wantDeleted, tt.wantTags)
+			}
+		})
+	}
+}
+
+func Test_monorepoTagHandler_NextVersionAlreadyTagged(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "gamma", "3.0.0")
+	comp.RootPath = filepath.Join(repoRoot, "gamma")
+	comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+
+	committed, tagged := false, false
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+		tagsAllFn: func() ([]sv.GitTag, error) {
+			return []sv.GitTag{{Name: "gamma/v3.0.0"}, {Name: "gamma/v3.1.0"}}, nil
+		},
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+		showFileFn: func(string, string) ([]byte, error) {
+			return []byte(`{"version": "3.0.0"}`), nil
+		},
+		commitFn: func(string, string, string, ...string) error {
+			committed = true
+			return nil
+		},
 		tagForComponentFn: func(version semver.Version, componentPath string) (string, error) {
-			createdTag = componentPath + "/v" + version.String()
-			return createdTag, nil
+			tagged = true
+			return componentPath + "/v" + version.String(), nil
 		},
 	}
 	mnrp := mockMonorepoProcessor{
@@ -221,36 +914,490 @@ func Test_monorepoTagHandler_UpdatesAndTags(t *testing.T) {
 			return []sv.MonorepoComponent{comp}, nil
 		},
 		nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
-			return nextVer, true
+			return semver.MustParse("3.1.0"), true
 		},
-		updateVersionFn: func(_ sv.MonorepoComponent, version semver.Version, _ sv.MonorepoConfig) error {
-			updatedVersion = version
-			return nil
+		updateVersionFn: func(sv.MonorepoComponent, semver.Version, sv.MonorepoConfig) error { return nil },
+	}
+	cfg := defaultConfig()
+	cfg.Monorepo.Path = "version"
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Bool("bump-and-commit", true, "")
+
+	out, _ := newTestPrinter()
+	handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+	err := handler(cli.NewContext(cli.NewApp(), set, nil))
+	if err == nil || exitCode(err) != exitCodeTagExists || !strings.Contains(err.Error(), "gamma/v3.1.0 already exists on commit abc1234") {
+		t.Fatalf("monorepoTagHandler() error = %v, want existing tag error", err)
+	}
+	if committed || tagged {
+		t.Errorf("monorepoTagHandler() committed = %v, tagged = %v, want no commit and no tag", committed, tagged)
+	}
+}
+
+func Test_monorepoTagHandler_PushFailure(t *testing.T) {
+	repoRoot := t.TempDir()
+	var components []sv.MonorepoComponent
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		comp := makeComponent(t, name, "1.1.0")
+		comp.RootPath = filepath.Join(repoRoot, name)
+		comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+		components = append(components, comp)
+	}
+
+	git := mockGit{
+		tagRemote: "origin",
+		logFn:     func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+		showFileFn: func(string, string) ([]byte, error) {
+			return []byte(`{"version": "1.1.0"}`), nil
+		},
+		tagForComponentFn: func(version semver.Version, componentPath string) (string, error) {
+			tag := componentPath + "/v" + version.String()
+			if componentPath == "beta" {
+				return tag, sv.TagPushError{Tag: tag, Remote: "origin", Err: errors.New("exit status 128")}
+			}
+			return tag, nil
 		},
 	}
-	semverProc := mockSemVerProcessor{}
-	cfg := Config{}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return components, nil
+		},
+		nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+	cfg := defaultConfig()
+	cfg.Monorepo.Path = "version"
+	cfg.Monorepo.TagPush = sv.TagPushPerTag
 
-	handler := monorepoTagHandler(git, semverProc, mnrp, cfg, repoRoot)
-	if err := handler(newCLICtx()); err != nil {
-		t.Fatalf("monorepoTagHandler() unexpected error: %v", err)
+	out, stdout := newTestPrinter()
+	handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+	err := handler(newCLICtx())
+	if err == nil || !strings.Contains(err.Error(), "beta") || !strings.Contains(err.Error(), "push it with: git push origin beta/v1.1.0") {
+		t.Fatalf("monorepoTagHandler() error = %v, want push error with remediation", err)
 	}
-	if updatedVersion.String() != "3.1.0" {
-		t.Errorf("UpdateVersion called with %s, want 3.1.0", updatedVersion.String())
+	if got, want := stdout.String(), "alpha: alpha/v1.1.0\n"; got != want {
+		t.Errorf("monorepoTagHandler() stdout = %q, want %q", got, want)
 	}
-	if !strings.HasSuffix(createdTag, "v3.1.0") {
-		t.Errorf("TagForComponent tag = %q, want suffix v3.1.0", createdTag)
+}
+
+func Test_monorepoTagHandler_BatchPush(t *testing.T) {
+	repoRoot := t.TempDir()
+	var components []sv.MonorepoComponent
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		comp := makeComponent(t, name, "1.1.0")
+		comp.RootPath = filepath.Join(repoRoot, name)
+		comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+		components = append(components, comp)
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return components, nil
+		},
+		nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+
+	tests := []struct {
+		name       string
+		batchSize  int
+		rejected   string
+		wantPushes [][]string
+		wantStatus []string
+	}{
+		{"single push", 0, "", [][]string{{"alpha/v1.1.0", "beta/v1.1.0", "gamma/v1.1.0"}}, []string{tagStatusTagged, tagStatusTagged, tagStatusTagged}},
+		{"batch size", 2, "", [][]string{{"alpha/v1.1.0", "beta/v1.1.0"}, {"gamma/v1.1.0"}}, []string{tagStatusTagged, tagStatusTagged, tagStatusTagged}},
+		{"partial failure", 0, "beta/v1.1.0", [][]string{{"alpha/v1.1.0", "beta/v1.1.0", "gamma/v1.1.0"}}, []string{tagStatusTagged, tagStatusFailed, tagStatusTagged}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pushes [][]string
+			git := mockGit{
+				tagRemote: "origin",
+				logFn:     func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+				showFileFn: func(string, string) ([]byte, error) {
+					return []byte(`{"version": "1.1.0"}`), nil
+				},
+				tagForComponentFn: func(version semver.Version, componentPath string) (string, error) {
+					if len(pushes) > 0 {
+						t.Errorf("tag of %s created after tags were pushed", componentPath)
+					}
+					return componentPath + "/v" + version.String(), nil
+				},
+				pushTagsFn: func(remote string, tags []string) error {
+					pushes = append(pushes, tags)
+					for _, tag := range tags {
+						if tag == tt.rejected {
+							return sv.TagsPushError{Remote: remote, Failed: []sv.TagPushError{{Tag: tag, Remote: remote, Err: errors.New("[remote rejected] (hook declined)")}}}
+						}
+					}
+					return nil
+				},
+			}
+			cfg := defaultConfig()
+			cfg.Monorepo.Path = "version"
+
+			path := filepath.Join(t.TempDir(), "summary.json")
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Int("push-batch-size", tt.batchSize, "")
+			set.String("summary-file", path, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if tt.rejected == "" && err != nil {
+				t.Fatalf("monorepoTagHandler() unexpected error: %v", err)
+			}
+			if tt.rejected != "" && (err == nil || !strings.Contains(err.Error(), "push them with: git push origin "+tt.rejected)) {
+				t.Fatalf("monorepoTagHandler() error = %v, want push error of %s with remediation", err, tt.rejected)
+			}
+			if !reflect.DeepEqual(pushes, tt.wantPushes) {
+				t.Errorf("monorepoTagHandler() pushes = %v, want %v", pushes, tt.wantPushes)
+			}
+
+			content, rerr := os.ReadFile(path)
+			if rerr != nil {
+				t.Fatalf("summary file not written: %v", rerr)
+			}
+			var summary tagSummary
+			if err := json.Unmarshal(content, &summary); err != nil {
+				t.Fatal(err)
+			}
+			for i, component := range summary.Components {
+				if component.Status != tt.wantStatus[i] {
+					t.Errorf("summary %s status = %s, want %s", component.Name, component.Status, tt.wantStatus[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_tagBatches(t *testing.T) {
+	long := strings.Repeat("x", maxPushArgsLength/2)
+	tests := []struct {
+		name      string
+		tags      []string
+		batchSize int
+		want      [][]string
+	}{
+		{"without tags", nil, 0, nil},
+		{"unlimited", []string{"a", "b", "c"}, 0, [][]string{{"a", "b", "c"}}},
+		{"batch size", []string{"a", "b", "c"}, 2, [][]string{{"a", "b"}, {"c"}}},
+		{"args length", []string{long, long, "c"}, 0, [][]string{{long}, {long, "c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tagBatches(tt.tags, tt.batchSize); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tagBatches() = %d batches, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+}
+
+func Test_monorepoTagHandler_SummaryFile(t *testing.T) {
+	repoRoot := t.TempDir()
+	var components []sv.MonorepoComponent
+	for _, name := range []string{"alpha", "beta"} {
+		comp := makeComponent(t, name, "1.0.0")
+		comp.RootPath = filepath.Join(repoRoot, name)
+		comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+		components = append(components, comp)
+	}
+
+	tests := []struct {
+		name        string
+		file        string
+		failOn      string
+		wantPartial bool
+		wantStatus  []string
+	}{
+		{"all components tagged", "summary.json", "", false, []string{tagStatusTagged, tagStatusTagged}},
+		{"partial failure", "summary.json", "beta", true, []string{tagStatusTagged, tagStatusFailed}},
+		{"yaml", "out/summary.yaml", "", false, []string{tagStatusTagged, tagStatusTagged}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{
+				tagsAllFn: func() ([]sv.GitTag, error) { return []sv.GitTag{{Name: "alpha/v1.0.0"}}, nil },
+				// versioning files have uncommitted changes, their committed versions are read with git show.
+				isCleanFn: func() (bool, []string, error) {
+					return false, []string{"alpha/package.json", "beta/package.json"}, nil
+				},
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+				showFileFn: func(string, string) ([]byte, error) {
+					return []byte(`{"version": "1.1.0"}`), nil
+				},
+				tagForComponentFn: func(version semver.Version, componentPath string) (string, error) {
+					if componentPath == tt.failOn {
+						return "", errors.New("exit status 128")
+					}
+					return componentPath + "/v" + version.String(), nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return components, nil
+				},
+				nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("1.1.0"), true
+				},
+			}
+			cfg := defaultConfig()
+			cfg.Monorepo.Path = "version"
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("summary-file", path, "")
+			set.Bool("allow-dirty", true, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+			if err := handler(cli.NewContext(cli.NewApp(), set, nil)); (err != nil) != tt.wantPartial {
+				t.Fatalf("monorepoTagHandler() error = %v, wantErr %v", err, tt.wantPartial)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("summary file not written: %v", err)
+			}
+			var summary tagSummary
+			if filepath.Ext(path) == ".json" {
+				err = json.Unmarshal(content, &summary)
+			} else {
+				err = yaml.Unmarshal(content, &summary)
+			}
+			if err != nil {
+				t.Fatalf("invalid summary file: %v\n%s", err, content)
+			}
+			if summary.Partial != tt.wantPartial || summary.Head != "abc1234" || summary.Timestamp.IsZero() {
+				t.Errorf("summary = %+v, want partial %v on head abc1234", summary, tt.wantPartial)
+			}
+			var status []string
+			for _, component := range summary.Components {
+				status = append(status, component.Status)
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Fatalf("summary components status = %v, want %v", status, tt.wantStatus)
+			}
+			want := componentTagSummary{Name: "alpha", PreviousVersion: "1.0.0", NewVersion: "1.1.0", Tag: "alpha/v1.1.0", From: "abc1234", To: "abc1234", Status: tagStatusTagged}
+			if !reflect.DeepEqual(summary.Components[0], want) {
+				t.Errorf("summary component = %+v, want %+v", summary.Components[0], want)
+			}
+			if tt.failOn != "" && summary.Components[1].Error == "" {
+				t.Errorf("summary failed component without error: %+v", summary.Components[1])
+			}
+		})
+	}
+}
+
+func Test_checkSummaryFile(t *testing.T) {
+	for _, path := range []string{"summary.json", "summary.YML", "out/summary.yaml"} {
+		if err := checkSummaryFile(path); err != nil {
+			t.Errorf("checkSummaryFile(%q) unexpected error: %v", path, err)
+		}
+	}
+	if err := checkSummaryFile("summary.txt"); err == nil {
+		t.Errorf("checkSummaryFile(summary.txt) expected error")
 	}
 }
 
 // ---- monorepoChangelogHandler tests ----
 
+func Test_monorepoTagHandler_ReusesOnDiskVersions(t *testing.T) {
+	repoRoot := t.TempDir()
+	var components []sv.MonorepoComponent
+	for _, name := range []string{"alpha", "beta"} {
+		comp := makeComponent(t, name, "1.1.0") // bump committed by monorepo-bump --commit
+		comp.RootPath = filepath.Join(repoRoot, name)
+		comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+		components = append(components, comp)
+	}
+
+	tests := []struct {
+		name      string
+		dirty     []string
+		wantFiles int
+	}{
+		{"clean working tree", nil, 0},
+		{"uncommitted versioning files", []string{"alpha/package.json", "beta/package.json"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []string
+			files := map[string]int{}
+			git := mockGit{
+				isCleanFn: func() (bool, []string, error) { return len(tt.dirty) == 0, tt.dirty, nil },
+				tagsAllFn: func() ([]sv.GitTag, error) { return []sv.GitTag{{Name: "alpha/v1.0.0"}, {Name: "beta/v1.0.0"}}, nil },
+				logFn:     func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+				showFileFn: func(revision, path string) ([]byte, error) {
+					files[revision+":"+path]++
+					return []byte(`{"version": "1.1.0"}`), nil
+				},
+				tagForComponentFn: func(version semver.Version, componentPath string) (string, error) {
+					created = append(created, componentPath+"/v"+version.String())
+					return created[len(created)-1], nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) { return components, nil },
+				nextVersionFn: func(sv.MonorepoComponent, []sv.GitCommitLog, sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("1.1.0"), true
+				},
+			}
+			cfg := defaultConfig()
+			cfg.Monorepo.Path = "version"
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("allow-dirty", len(tt.dirty) > 0, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoTagHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoRoot, sv.SystemClock{}, out)
+			if err := handler(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+				t.Fatalf("monorepoTagHandler() error = %v", err)
+			}
+			if want := []string{"alpha/v1.1.0", "beta/v1.1.0"}; !reflect.DeepEqual(created, want) {
+				t.Errorf("monorepoTagHandler() tags = %v, want %v", created, want)
+			}
+			if len(files) != tt.wantFiles {
+				t.Errorf("ShowFile() read %v, want %d files", files, tt.wantFiles)
+			}
+			for key, count := range files {
+				if count > 1 {
+					t.Errorf("ShowFile(%s) called %d times, want once", key, count)
+				}
+			}
+		})
+	}
+}
+
+func Test_componentCache(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "alpha", "1.0.0")
+	comp.RootPath = filepath.Join(repoRoot, "alpha")
+	comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+	tags := []sv.GitTag{{Name: "alpha/v1.0.0"}}
+
+	head, headErr := "abc1234", error(nil)
+	logs, files := 0, 0
+	git := mockGit{
+		shortHashFn: func(string) (string, error) { return head, headErr },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+			logs++
+			return []sv.GitCommitLog{{Hash: "abc"}}, nil
+		},
+		showFileFn: func(string, string) ([]byte, error) {
+			files++
+			return []byte(`{"version": "1.1.0"}`), nil
+		},
+	}
+	out, _ := newTestPrinter()
+	cache := newComponentCache(git)
+	read := func() {
+		t.Helper()
+		if _, err := cache.versionCommits(repoRoot, comp, tags, "", sv.VersioningConfig{}, out); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cache.committedVersion(sv.MonorepoConfig{Path: "version"}, "HEAD", "alpha/package.json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(name string, wantLogs, wantFiles int) {
+		t.Helper()
+		if logs != wantLogs || files != wantFiles {
+			t.Errorf("%s: Log called %d times and ShowFile %d times, want %d and %d", name, logs, files, wantLogs, wantFiles)
+		}
+	}
+
+	read()
+	read()
+	check("same HEAD", 1, 1)
+
+	cache.invalidate()
+	read()
+	check("HEAD not changed", 1, 1)
+
+	head = "def5678"
+	read()
+	check("HEAD changed without invalidate", 1, 1)
+	cache.invalidate()
+	read()
+	check("HEAD changed", 2, 2)
+
+	if _, err := cache.versionCommits(repoRoot, comp, nil, "", sv.VersioningConfig{}, out); err != nil {
+		t.Fatal(err)
+	}
+	check("other baseline", 3, 2)
+
+	head, headErr = "", errors.New("no commits")
+	cache.invalidate()
+	read()
+	read()
+	check("HEAD not resolved", 5, 4)
+}
+
+func Test_monorepoChangelogHandler_ReadsLogsOnce(t *testing.T) {
+	repoRoot := t.TempDir()
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var components []sv.MonorepoComponent
+	var tags []sv.GitTag
+	for _, name := range []string{"alpha", "beta"} {
+		comp := makeComponent(t, name, "1.1.0-beta.1")
+		comp.RootPath = filepath.Join(repoRoot, name)
+		comp.VersioningFilePath = filepath.Join(comp.RootPath, "package.json")
+		components = append(components, comp)
+		tags = append(tags, sv.GitTag{Name: name + "/v1.0.0", Date: date}, sv.GitTag{Name: name + "/v1.1.0-beta.1", Date: date.AddDate(0, 0, 1)})
+	}
+
+	// the changelog reads commits since the prerelease tag, the next version since the stable tag and the prerelease tag again.
+	logs := map[string]int{}
+	git := mockGit{
+		branch:    "develop",
+		tagsAllFn: func() ([]sv.GitTag, error) { return tags, nil },
+		logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+			logs[fmt.Sprintf("%v", lr)]++
+			return []sv.GitCommitLog{{Hash: "abc", Date: "2024-01-03"}}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) { return components, nil },
+		nextVersionFn: func(sv.MonorepoComponent, []sv.GitCommitLog, sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+	var versions []string
+	formatter := mockOutputFormatter{
+		formatChangelogFn: func(releasenotes []sv.ReleaseNote) (string, error) {
+			versions = append(versions, releasenotes[0].Version.String())
+			return "", nil
+		},
+	}
+	cfg := Config{Monorepo: sv.MonorepoConfig{Prerelease: sv.MonorepoPrereleaseConfig{BranchMap: map[string]string{"develop": "beta"}}}}
+
+	out, _ := newTestPrinter()
+	handler := monorepoChangelogHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, formatter, cfg, repoRoot, sv.SystemClock{}, newTimings(time.Now), out)
+	if err := handler(newCLICtx()); err != nil {
+		t.Fatalf("monorepoChangelogHandler() unexpected error: %v", err)
+	}
+	if want := []string{"1.1.0-beta.2", "1.1.0-beta.2"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("monorepoChangelogHandler() next versions = %v, want %v", versions, want)
+	}
+	if len(logs) != 2*len(components) {
+		t.Errorf("monorepoChangelogHandler() read logs %v, want two ranges per component", logs)
+	}
+	for key, count := range logs {
+		if count > 1 {
+			t.Errorf("Log(%s) called %d times, want once", key, count)
+		}
+	}
+}
+
 func Test_monorepoChangelogHandler_SkipsNoUpdate(t *testing.T) {
 	comp := makeComponent(t, "delta", "1.0.0")
 
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
@@ -265,7 +1412,8 @@ func Test_monorepoChangelogHandler_SkipsNoUpdate(t *testing.T) {
 	formatter := mockOutputFormatter{}
 	cfg := Config{}
 
-	handler := monorepoChangelogHandler(git, semverProc, mnrp, rnProc, formatter, cfg, comp.RootPath)
+	out, _ := newTestPrinter()
+	handler := monorepoChangelogHandler(git, semverProc, mnrp, rnProc, formatter, cfg, comp.RootPath, sv.SystemClock{}, newTimings(time.Now), out)
 	if err := handler(newCLICtx()); err != nil {
 		t.Errorf("monorepoChangelogHandler() unexpected error: %v", err)
 	}
@@ -288,8 +1436,9 @@ func Test_monorepoChangelogHandler_WritesChangelog(t *testing.T) {
 	const changelogContent = "# Changelog\n## v1.1.0\n"
 
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc", Date: "2024-01-01"}}, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{{Hash: "abc", Date: "2024-01-01"}}, nil
+		},
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
@@ -306,7 +1455,8 @@ func Test_monorepoChangelogHandler_WritesChangelog(t *testing.T) {
 	}
 	cfg := Config{}
 
-	handler := monorepoChangelogHandler(git, semverProc, mnrp, rnProc, formatter, cfg, repoRoot)
+	out, _ := newTestPrinter()
+	handler := monorepoChangelogHandler(git, semverProc, mnrp, rnProc, formatter, cfg, repoRoot, sv.SystemClock{}, newTimings(time.Now), out)
 	if err := handler(newCLICtx()); err != nil {
 		t.Fatalf("monorepoChangelogHandler() unexpected error: %v", err)
 	}
@@ -321,17 +1471,229 @@ func Test_monorepoChangelogHandler_WritesChangelog(t *testing.T) {
 	}
 }
 
+func Test_componentReleaseHistory_VersionRange(t *testing.T) {
+	alpha := makeComponent(t, "alpha", "2.0.0")
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	tags := []sv.GitTag{
+		{Name: "alpha/v1.0.0", Date: day(1)},
+		{Name: "alpha/v2.0.0", Date: day(2)},
+		{Name: "alpha/v1.1.0", Date: day(3)},
+		{Name: "alpha/v2.1.0", Date: day(4)},
+	}
+	git := mockGit{logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil }}
+	out, _ := newTestPrinter()
+
+	history, size, err := componentReleaseHistory(git, mockReleaseNoteProcessor{}, alpha, tags, Config{}, map[string]string{"alpha": "alpha"}, dateFilter{}, versionRange{from: semver.MustParse("1.1.0"), to: semver.MustParse("2.0.0")}, out)
+	if err != nil {
+		t.Fatalf("componentReleaseHistory() unexpected error: %v", err)
+	}
+	releaseNotes, err := collectReleaseNotes(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rn := range releaseNotes {
+		got = append(got, rn.Tag+"<"+rn.PreviousTag)
+	}
+	if want := []string{"alpha/v2.0.0<alpha/v1.1.0", "alpha/v1.1.0<alpha/v1.0.0"}; size != 2 || !reflect.DeepEqual(got, want) {
+		t.Errorf("componentReleaseHistory() = %v (size %d), want %v", got, size, want)
+	}
+
+	if _, _, err := componentReleaseHistory(git, mockReleaseNoteProcessor{}, alpha, tags, Config{}, nil, dateFilter{}, versionRange{to: semver.MustParse("1.2.0")}, out); err == nil {
+		t.Error("componentReleaseHistory() expected error for a bound without component tag, got nil")
+	}
+}
+
+func Test_monorepoChangelogHandler_AggregatePath(t *testing.T) {
+	tests := []struct {
+		name      string
+		aggregate string
+		wantFile  string
+		wantErr   string
+	}{
+		{"relative to repository", "docs/changes/CHANGELOG.md", "docs/changes/CHANGELOG.md", ""},
+		{"outside repository", "../CHANGELOG.md", "", "invalid --aggregate, message: path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := filepath.Join(t.TempDir(), "repo")
+			alpha := makeComponent(t, "alpha", "1.0.0")
+			alpha.RootPath = filepath.Join(repoRoot, "alpha")
+
+			git := mockGit{
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+					return []sv.GitCommitLog{{Hash: "abc", Date: "2024-01-01"}}, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{alpha}, nil
+				},
+				nextVersionFn: func(sv.MonorepoComponent, []sv.GitCommitLog, sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("1.1.0"), true
+				},
+			}
+			formatter := mockOutputFormatter{
+				formatMonorepoChangelogFn: func(map[string][]sv.ReleaseNote) (string, error) { return "# alpha\n", nil },
+			}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("aggregate", tt.aggregate, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoChangelogHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, formatter, Config{}, repoRoot, sv.SystemClock{}, newTimings(time.Now), out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("monorepoChangelogHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantFile == "" {
+				if _, serr := os.Stat(filepath.Join(repoRoot, tt.aggregate)); !os.IsNotExist(serr) {
+					t.Errorf("monorepoChangelogHandler() wrote %s outside repository", tt.aggregate)
+				}
+				return
+			}
+			if content, rerr := os.ReadFile(filepath.Join(repoRoot, tt.wantFile)); rerr != nil || string(content) != "# alpha\n" {
+				t.Errorf("monorepoChangelogHandler() aggregated changelog = %q, error %v", content, rerr)
+			}
+		})
+	}
+}
+
+func Test_monorepoChangelogHandler_SkippedComponents(t *testing.T) {
+	tests := []struct {
+		name      string
+		aggregate string
+		stdout    bool
+	}{
+		{"per component", "", false},
+		{"per component stdout", "", true},
+		{"aggregate stdout", "CHANGELOG.md", true},
+		{"aggregate", "CHANGELOG.md", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			alpha := makeComponent(t, "alpha", "1.0.0")
+			alpha.RootPath = filepath.Join(repoRoot, "alpha")
+
+			git := mockGit{
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+					return []sv.GitCommitLog{{Hash: "abc", Date: "2024-01-01"}}, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{alpha}, nil
+				},
+				skipped: []sv.ComponentError{{Path: "beta/package.json", Err: errors.New("invalid version")}},
+				nextVersionFn: func(sv.MonorepoComponent, []sv.GitCommitLog, sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("1.1.0"), true
+				},
+			}
+			cfg := Config{Monorepo: sv.MonorepoConfig{VersioningFile: "*/package.json", Path: "version", OnParseError: sv.OnParseErrorWarn}}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			if tt.aggregate != "" {
+				set.String("aggregate", filepath.Join(repoRoot, tt.aggregate), "")
+			}
+			set.Bool("stdout", tt.stdout, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoChangelogHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, mockOutputFormatter{}, cfg, repoRoot, sv.SystemClock{}, newTimings(time.Now), out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if err == nil || !strings.Contains(err.Error(), "1 component(s) skipped") {
+				t.Errorf("monorepoChangelogHandler() error = %v, want skipped components error", err)
+			}
+		})
+	}
+}
+
+func Test_monorepoChangelogHandler_Aggregate(t *testing.T) {
+	repoRoot := t.TempDir()
+	alpha := makeComponent(t, "alpha", "1.0.0")
+	alpha.RootPath = filepath.Join(repoRoot, "alpha")
+	beta := makeComponent(t, "beta", "1.0.0")
+	const aggregateContent = "# alpha\n"
+
+	git := mockGit{
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{{Hash: "abc", Date: "2024-01-01"}}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{alpha, beta}, nil
+		},
+		nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			if component.Name == "beta" {
+				return component.CurrentVersion, false
+			}
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+	var gotComponents []string
+	formatter := mockOutputFormatter{
+		formatMonorepoChangelogFn: func(releasenotes map[string][]sv.ReleaseNote) (string, error) {
+			for name := range releasenotes {
+				gotComponents = append(gotComponents, name)
+			}
+			return aggregateContent, nil
+		},
+	}
+
+	tests := []struct {
+		name             string
+		perComponent     bool
+		wantPerComponent bool
+	}{
+		{"aggregate only", false, false},
+		{"aggregate and per component", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotComponents = nil
+			_ = os.Remove(filepath.Join(alpha.RootPath, "CHANGELOG.md"))
+			aggregatePath := filepath.Join(repoRoot, tt.name+".md")
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("aggregate", aggregatePath, "")
+			set.Bool("per-component", tt.perComponent, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoChangelogHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, formatter, Config{}, repoRoot, sv.SystemClock{}, newTimings(time.Now), out)
+			if err := handler(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+				t.Fatalf("monorepoChangelogHandler() unexpected error: %v", err)
+			}
+
+			got, err := os.ReadFile(aggregatePath)
+			if err != nil {
+				t.Fatalf("aggregated changelog not written: %v", err)
+			}
+			if string(got) != aggregateContent {
+				t.Errorf("aggregated changelog content = %q, want %q", string(got), aggregateContent)
+			}
+			if len(gotComponents) != 1 || gotComponents[0] != "alpha" {
+				t.Errorf("FormatMonorepoChangelog() components = %v, want [alpha]", gotComponents)
+			}
+
+			_, serr := os.Stat(filepath.Join(alpha.RootPath, "CHANGELOG.md"))
+			if written := serr == nil; written != tt.wantPerComponent {
+				t.Errorf("monorepoChangelogHandler() wrote component CHANGELOG.md = %v, want %v", written, tt.wantPerComponent)
+			}
+		})
+	}
+}
 func Test_monorepoChangelogHandler_FindComponentsError(t *testing.T) {
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
 			return nil, os.ErrPermission
 		},
 	}
-	handler := monorepoChangelogHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, mockOutputFormatter{}, Config{}, t.TempDir())
+	out, _ := newTestPrinter()
+	handler := monorepoChangelogHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, mockOutputFormatter{}, Config{}, t.TempDir(), sv.SystemClock{}, newTimings(time.Now), out)
 	if err := handler(newCLICtx()); err == nil {
 		t.Error("monorepoChangelogHandler() expected error when FindComponents fails, got nil")
 	}
@@ -344,8 +1706,7 @@ func Test_monorepoUpdateVersionHandler_SkipsNoUpdate(t *testing.T) {
 
 	updateCalled := false
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
@@ -360,7 +1721,8 @@ func Test_monorepoUpdateVersionHandler_SkipsNoUpdate(t *testing.T) {
 		},
 	}
 
-	handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, Config{}, comp.RootPath)
+	out, _ := newTestPrinter()
+	handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(sv.CommitMessageConfig{}, sv.BranchesConfig{}), Config{}, comp.RootPath, out)
 	if err := handler(newCLICtx()); err != nil {
 		t.Fatalf("monorepoUpdateVersionHandler() unexpected error: %v", err)
 	}
@@ -382,8 +1744,7 @@ func Test_monorepoUpdateVersionHandler_WritesVersion(t *testing.T) {
 	tagCalled := false
 
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
 		tagForComponentFn: func(version semver.Version, _ string) (string, error) {
 			tagCalled = true
 			return "", nil
@@ -402,7 +1763,8 @@ func Test_monorepoUpdateVersionHandler_WritesVersion(t *testing.T) {
 		},
 	}
 
-	handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, Config{}, repoRoot)
+	out, _ := newTestPrinter()
+	handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(sv.CommitMessageConfig{}, sv.BranchesConfig{}), Config{}, repoRoot, out)
 	if err := handler(newCLICtx()); err != nil {
 		t.Fatalf("monorepoUpdateVersionHandler() unexpected error: %v", err)
 	}
@@ -414,18 +1776,982 @@ func Test_monorepoUpdateVersionHandler_WritesVersion(t *testing.T) {
 	}
 }
 
+func Test_monorepoUpdateVersionHandler_Downgrade(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "eta", "2.0.0")
+	comp.RootPath = filepath.Join(repoRoot, "eta")
+
+	tests := []struct {
+		name           string
+		tags           []sv.GitTag
+		allowDowngrade bool
+		wantUpdate     bool
+		wantErr        string
+	}{
+		{"tag baseline", []sv.GitTag{{Name: "eta/v1.0.0"}, {Name: "eta/v1.5.0-rc.1"}}, false, false, "calculated from tag eta/v1.0.0"},
+		{"all commits baseline", nil, false, false, "calculated from all commits"},
+		{"allow downgrade", []sv.GitTag{{Name: "eta/v1.0.0"}}, true, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := false
+			git := mockGit{
+				tagsAllFn: func() ([]sv.GitTag, error) { return tt.tags, nil },
+				logFn:     func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("1.1.0"), true
+				},
+				updateVersionFn: func(sv.MonorepoComponent, semver.Version, sv.MonorepoConfig) error {
+					updated = true
+					return nil
+				},
+			}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("allow-downgrade", tt.allowDowngrade, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(sv.CommitMessageConfig{}, sv.BranchesConfig{}), Config{}, repoRoot, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("monorepoUpdateVersionHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if updated != tt.wantUpdate {
+				t.Errorf("monorepoUpdateVersionHandler() updated = %v, want %v", updated, tt.wantUpdate)
+			}
+		})
+	}
+}
+
+func Test_monorepoUpdateVersionHandler_Hooks(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "eta", "2.0.0")
+	comp.RootPath = filepath.Join(repoRoot, "eta")
+
+	tests := []struct {
+		name       string
+		hooks      sv.HooksConfig
+		wantUpdate bool
+		wantErr    string
+	}{
+		{"pre-bump failure", sv.HooksConfig{PreBump: []string{"exit 1"}}, false, `eta: pre-bump hook "exit 1" failed`},
+		{"post-bump failure", sv.HooksConfig{PostBump: []string{"exit 1"}}, true, `eta: post-bump hook "exit 1" failed`},
+		{"environment", sv.HooksConfig{PreBump: []string{`test "$SV_VERSION $SV_TAG $SV_COMPONENT" = "2.1.0 eta/v2.1.0 eta"`}}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := false
+			git := mockGit{
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				nextVersionFn: func(_ sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("2.1.0"), true
+				},
+				updateVersionFn: func(sv.MonorepoComponent, semver.Version, sv.MonorepoConfig) error {
+					updated = true
+					return nil
+				},
+			}
+
+			out, _ := newTestPrinter()
+			handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(sv.CommitMessageConfig{}, sv.BranchesConfig{}), Config{Hooks: tt.hooks}, repoRoot, out)
+			err := handler(newCLICtx())
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("monorepoUpdateVersionHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if updated != tt.wantUpdate {
+				t.Errorf("monorepoUpdateVersionHandler() updated = %v, want %v", updated, tt.wantUpdate)
+			}
+		})
+	}
+}
+
 func Test_monorepoUpdateVersionHandler_FindComponentsError(t *testing.T) {
 	git := mockGit{
-		lastComponentTagFn: func(string) string { return "" },
-		logFn:              func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
 	}
 	mnrp := mockMonorepoProcessor{
 		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
 			return nil, os.ErrPermission
 		},
 	}
-	handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, Config{}, t.TempDir())
+	out, _ := newTestPrinter()
+	handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, sv.NewMessageProcessor(sv.CommitMessageConfig{}, sv.BranchesConfig{}), Config{}, t.TempDir(), out)
 	if err := handler(newCLICtx()); err == nil {
 		t.Error("monorepoUpdateVersionHandler() expected error when FindComponents fails, got nil")
 	}
 }
+
+func Test_monorepoReleaseNotesHandler(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "payments", "1.3.0")
+	comp.RootPath = filepath.Join(repoRoot, "payments")
+
+	tags := []sv.GitTag{
+		{Name: "payments/v1.2.0", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "payments/v1.3.0", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "payments/v1.4.0", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name        string
+		component   string
+		tag         string
+		wantRange   sv.LogRange
+		wantVersion string
+		wantPrevTag string
+		wantErr     string
+		noCommits   bool
+	}{
+		{"component tag", "payments", "payments/v1.3.0", sv.NewLogRangeWithPaths(sv.TagRange, "payments/v1.2.0", "payments/v1.3.0", []string{"payments"}), "1.3.0", "payments/v1.2.0", "", false},
+		{"first component tag", "payments", "payments/v1.2.0", sv.NewLogRangeWithPaths(sv.TagRange, "", "payments/v1.2.0", []string{"payments"}), "1.2.0", "", "", false},
+		{"next version", "payments", "", sv.NewLogRangeWithPaths(sv.TagRange, "payments/v1.4.0", "", []string{"payments"}), "1.4.1", "payments/v1.4.0", "", false},
+		{"tag not found", "payments", "payments/v9.9.9", sv.LogRange{}, "", "", "tag: payments/v9.9.9 not found for component payments", false},
+		{"component not found", "unknown", "", sv.LogRange{}, "", "", "component: unknown not found", false},
+		{"nothing to release", "payments", "", sv.LogRange{}, "", "", "nothing to release since payments/v1.4.0, use --tag payments/v1.4.0 to get its release notes", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRange sv.LogRange
+			git := mockGit{
+				tagsAllFn: func() ([]sv.GitTag, error) { return tags, nil },
+				logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+					gotRange = lr
+					if tt.noCommits {
+						return nil, nil
+					}
+					return []sv.GitCommitLog{{Hash: "abc"}}, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				nextVersionFn: func(sv.MonorepoComponent, []sv.GitCommitLog, sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semver.MustParse("1.4.1"), true
+				},
+			}
+			var gotVersion, gotPrevTag string
+			formatter := mockOutputFormatter{
+				formatReleaseNoteFn: func(rn sv.ReleaseNote) (string, error) {
+					gotVersion, gotPrevTag = rn.Version.String(), rn.PreviousTag
+					return "", nil
+				},
+			}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("component", tt.component, "")
+			set.String("t", tt.tag, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoReleaseNotesHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, formatter, Config{}, repoRoot, sv.SystemClock{}, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("monorepoReleaseNotesHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if !reflect.DeepEqual(gotRange, tt.wantRange) {
+				t.Errorf("monorepoReleaseNotesHandler() log range = %+v, want %+v", gotRange, tt.wantRange)
+			}
+			if gotVersion != tt.wantVersion {
+				t.Errorf("monorepoReleaseNotesHandler() version = %s, want %s", gotVersion, tt.wantVersion)
+			}
+			if gotPrevTag != tt.wantPrevTag {
+				t.Errorf("monorepoReleaseNotesHandler() previous tag = %s, want %s", gotPrevTag, tt.wantPrevTag)
+			}
+		})
+	}
+}
+
+func Test_monorepoReleaseNotesHandler_NextVersion(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "payments", "1.1.0-beta.1")
+	comp.RootPath = filepath.Join(repoRoot, "payments")
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tags := []sv.GitTag{{Name: "payments/v1.0.0", Date: date}, {Name: "payments/v1.1.0-beta.1", Date: date.AddDate(0, 0, 1)}}
+
+	tests := []struct {
+		name        string
+		cfg         Config
+		files       []string
+		wantVersion string
+	}{
+		{"prerelease channel", Config{Monorepo: sv.MonorepoConfig{Prerelease: sv.MonorepoPrereleaseConfig{BranchMap: map[string]string{"develop": "beta"}}}}, nil, "1.1.0-beta.2"},
+		{"stable branch", Config{}, nil, "1.1.0"},
+		{"only ignored paths", Config{Versioning: sv.VersioningConfig{IgnorePaths: []string{"docs"}}}, []string{"payments/docs/README.md"}, "1.1.0-beta.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{
+				branch:    "develop",
+				tagsAllFn: func() ([]sv.GitTag, error) { return tags, nil },
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+					return []sv.GitCommitLog{{Hash: "abc", Files: tt.files}}, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				nextVersionFn: func(component sv.MonorepoComponent, commits []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					if len(commits) == 0 {
+						return component.CurrentVersion, false
+					}
+					return semver.MustParse("1.1.0"), true
+				},
+			}
+			var gotVersion string
+			formatter := mockOutputFormatter{
+				formatReleaseNoteFn: func(rn sv.ReleaseNote) (string, error) {
+					gotVersion = rn.Version.String()
+					return "", nil
+				},
+			}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("component", "payments", "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoReleaseNotesHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, formatter, tt.cfg, repoRoot, sv.SystemClock{}, out)
+			if err := handler(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+				t.Fatalf("monorepoReleaseNotesHandler() error = %v", err)
+			}
+			if gotVersion != tt.wantVersion {
+				t.Errorf("monorepoReleaseNotesHandler() version = %s, want %s", gotVersion, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func Test_monorepoUpdateVersionHandler_Commit(t *testing.T) {
+	alpha := makeComponent(t, "alpha", "1.0.0")
+	beta := makeComponent(t, "beta", "2.0.0")
+
+	tests := []struct {
+		name       string
+		updated    map[string]bool
+		push       bool
+		wantFiles  []string
+		wantHeader string
+		wantBody   string
+		wantPush   bool
+	}{
+		{"commit updated components", map[string]bool{"alpha": true, "beta": true}, false, []string{alpha.VersioningFilePath, beta.VersioningFilePath}, "chore(release): bump versions", "- alpha: 1.1.0\n- beta: 2.1.0", false},
+		{"commit and push", map[string]bool{"beta": true}, true, []string{beta.VersioningFilePath}, "chore(release): bump versions", "- beta: 2.1.0", true},
+		{"nothing to commit", map[string]bool{}, true, nil, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFiles, gotCommitted []string
+			var gotHeader, gotBody string
+			gotPush := false
+			git := mockGit{
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc"}}, nil },
+				addFn: func(paths ...string) error {
+					gotFiles = paths
+					return nil
+				},
+				commitFn: func(header, body, _ string, paths ...string) error {
+					gotHeader, gotBody, gotCommitted = header, body, paths
+					return nil
+				},
+				pushFn: func() error {
+					gotPush = true
+					return nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{alpha, beta}, nil
+				},
+				nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					next := component.CurrentVersion.IncMinor()
+					return &next, tt.updated[component.Name]
+				},
+				updateVersionFn: func(sv.MonorepoComponent, semver.Version, sv.MonorepoConfig) error { return nil },
+			}
+			cfg := defaultConfig()
+			messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("commit", true, "")
+			set.Bool("push", tt.push, "")
+
+			out, _ := newTestPrinter()
+			handler := monorepoUpdateVersionHandler(git, mockSemVerProcessor{}, mnrp, messageProcessor, cfg, t.TempDir(), out)
+			if err := handler(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+				t.Fatalf("monorepoUpdateVersionHandler() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(gotFiles, tt.wantFiles) {
+				t.Errorf("monorepoUpdateVersionHandler() staged files = %v, want %v", gotFiles, tt.wantFiles)
+			}
+			if !reflect.DeepEqual(gotCommitted, tt.wantFiles) {
+				t.Errorf("monorepoUpdateVersionHandler() committed files = %v, want only %v", gotCommitted, tt.wantFiles)
+			}
+			if gotHeader != tt.wantHeader || gotBody != tt.wantBody {
+				t.Errorf("monorepoUpdateVersionHandler() commit = (%q, %q), want (%q, %q)", gotHeader, gotBody, tt.wantHeader, tt.wantBody)
+			}
+			if gotPush != tt.wantPush {
+				t.Errorf("monorepoUpdateVersionHandler() push = %v, want %v", gotPush, tt.wantPush)
+			}
+		})
+	}
+}
+
+func Test_componentChangelogPath(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := sv.MonorepoComponent{Name: "billing-api", RootPath: filepath.Join(repoRoot, "billing", "api")}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{"default", defaultComponentChangelogPath, filepath.Join(repoRoot, "billing", "api", "CHANGELOG.md"), false},
+		{"component name", "docs/changelogs/{{.ComponentName}}.md", filepath.Join(repoRoot, "docs", "changelogs", "billing-api.md"), false},
+		{"outside repository", "../{{.ComponentName}}.md", "", true},
+		{"absolute outside repository", filepath.Join(t.TempDir(), "CHANGELOG.md"), "", true},
+		{"invalid template field", "{{.Unknown}}.md", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := componentChangelogPath(template.Must(template.New("test").Parse(tt.template)), repoRoot, comp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("componentChangelogPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("componentChangelogPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_monorepoChangelogHandler_ChangelogPath(t *testing.T) {
+	repoRoot := t.TempDir()
+	comp := makeComponent(t, "zeta", "1.0.0")
+	comp.RootPath = filepath.Join(repoRoot, "zeta")
+	const changelogContent = "# Changelog\n## v1.1.0\n"
+
+	git := mockGit{
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{{Hash: "abc", Date: "2024-01-01"}}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{comp}, nil
+		},
+		nextVersionFn: func(sv.MonorepoComponent, []sv.GitCommitLog, sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+	formatter := mockOutputFormatter{
+		formatChangelogFn: func([]sv.ReleaseNote) (string, error) { return changelogContent, nil },
+	}
+	cfg := Config{Monorepo: sv.MonorepoConfig{ChangelogPath: "docs/changelogs/{{.ComponentName}}.md"}}
+	changelogPath := filepath.Join(repoRoot, "docs", "changelogs", "zeta.md")
+
+	tests := []struct {
+		name      string
+		stdout    bool
+		wantFile  bool
+		wantPrint bool
+	}{
+		{"stdout", true, false, true},
+		{"write file", false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("stdout", tt.stdout, "")
+
+			out, stdout := newTestPrinter()
+			handler := monorepoChangelogHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, formatter, cfg, repoRoot, sv.SystemClock{}, newTimings(time.Now), out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			printed := stdout.String()
+			if err != nil {
+				t.Fatalf("monorepoChangelogHandler() unexpected error: %v", err)
+			}
+
+			if isPrinted := printed == changelogContent+"\n"; isPrinted != tt.wantPrint {
+				t.Errorf("monorepoChangelogHandler() stdout = %q, want changelog printed %v", printed, tt.wantPrint)
+			}
+			got, rerr := os.ReadFile(changelogPath)
+			if (rerr == nil) != tt.wantFile {
+				t.Fatalf("monorepoChangelogHandler() file written = %v, want %v", rerr == nil, tt.wantFile)
+			}
+			if tt.wantFile && string(got) != changelogContent {
+				t.Errorf("changelog content = %q, want %q", string(got), changelogContent)
+			}
+		})
+	}
+}
+
+func Test_monorepoNextVersionHandler_Format(t *testing.T) {
+	repoRoot := t.TempDir()
+	alpha := makeComponent(t, "alpha", "1.0.0")
+	alpha.RootPath = filepath.Join(repoRoot, "services", "alpha")
+	beta := makeComponent(t, "beta", "2.0.0")
+	beta.RootPath = filepath.Join(repoRoot, "services", "beta")
+
+	git := mockGit{
+		logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{{Hash: "a"}, {Hash: "b"}}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{beta, alpha}, nil
+		},
+		nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			if component.Name == "beta" {
+				return component.CurrentVersion, false
+			}
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"text", "alpha: 1.1.0\nbeta: 2.0.0 (no change)\n", false},
+		{"json", `[
+  {
+    "name": "alpha",
+    "path": "services/alpha",
+    "currentVersion": "1.0.0",
+    "nextVersion": "1.1.0",
+    "updated": true,
+    "commitCount": 2,
+    "bumpLevel": "minor",
+    "computedBumpLevel": "minor"
+  },
+  {
+    "name": "beta",
+    "path": "services/beta",
+    "currentVersion": "2.0.0",
+    "nextVersion": "2.0.0",
+    "updated": false,
+    "commitCount": 2,
+    "bumpLevel": "none",
+    "computedBumpLevel": "none"
+  }
+]
+`, false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("format", tt.format, "")
+
+			out, stdout := newTestPrinter()
+			handler := monorepoNextVersionHandler(git, mockSemVerProcessor{}, mnrp, Config{}, repoRoot, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			got := stdout.String()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("monorepoNextVersionHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("monorepoNextVersionHandler() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_monorepoNextVersionHandler_Explain(t *testing.T) {
+	alpha := makeComponent(t, "alpha", "1.0.0")
+	git := mockGit{
+		logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{{Hash: "abc1234", Message: sv.CommitMessage{Type: "feat", Description: "add retry"}}}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{alpha}, nil
+		},
+		nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+	semverProc := mockSemVerProcessor{explainFn: func(commits []sv.GitCommitLog) sv.BumpExplanation {
+		return sv.BumpExplanation{Bump: "minor", Applied: "minor", Commits: []sv.CommitBump{{Commit: commits[0], Bump: "minor", Reason: "type feat on versioning.update-minor"}}}
+	}}
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Bool("explain", true, "")
+
+	out, stdout := newTestPrinter()
+	if err := monorepoNextVersionHandler(git, semverProc, mnrp, Config{}, filepath.Dir(alpha.RootPath), out)(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+		t.Fatalf("monorepoNextVersionHandler() unexpected error: %v", err)
+	}
+	want := "alpha: 1.1.0\n  minor update required by 1 commit(s):\n    abc1234 feat: add retry (type feat on versioning.update-minor)\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("monorepoNextVersionHandler() output = %q, want %q", got, want)
+	}
+}
+
+func Test_monorepoNextVersionHandler_MaxBump(t *testing.T) {
+	repoRoot := t.TempDir()
+	api := makeComponent(t, "api", "1.2.0")
+	api.RootPath = filepath.Join(repoRoot, "api")
+	api.MaxBump = "minor"
+	git := mockGit{
+		logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{
+				{Hash: "aaa1111", Message: sv.CommitMessage{Type: "feat", Description: "add filter"}},
+				{Hash: "bbb2222", Message: sv.CommitMessage{Type: "feat", Description: "drop v1", IsBreakingChange: true}},
+			}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{api}, nil
+		},
+		nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("2.0.0"), true
+		},
+	}
+	semverProc := mockSemVerProcessor{explainFn: func(commits []sv.GitCommitLog) sv.BumpExplanation {
+		bump := "minor"
+		for _, commit := range commits {
+			if commit.Message.IsBreakingChange {
+				bump = "major"
+			}
+		}
+		return sv.BumpExplanation{Bump: bump, Applied: bump}
+	}}
+
+	tests := []struct {
+		name    string
+		allow   bool
+		bump    string
+		want    string
+		wantErr string
+	}{
+		{"exceeded", false, "", "", "api: major update exceeds max-bump minor, required by:\n  bbb2222 feat!: drop v1 (major)\nuse --allow-capped-bump to limit the update to minor"},
+		{"capped", true, "", `[
+  {
+    "name": "api",
+    "path": "api",
+    "currentVersion": "1.2.0",
+    "nextVersion": "1.3.0",
+    "updated": true,
+    "commitCount": 2,
+    "bumpLevel": "minor",
+    "computedBumpLevel": "major",
+    "maxBump": "minor"
+  }
+]
+`, ""},
+		{"forced", false, "major", `[
+  {
+    "name": "api",
+    "path": "api",
+    "currentVersion": "1.2.0",
+    "nextVersion": "2.0.0",
+    "updated": true,
+    "commitCount": 2,
+    "bumpLevel": "major",
+    "computedBumpLevel": "major",
+    "maxBump": "minor"
+  }
+]
+`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("format", "json", "")
+			set.Bool("allow-capped-bump", tt.allow, "")
+			set.String("bump", tt.bump, "")
+
+			out, stdout := newTestPrinter()
+			err := monorepoNextVersionHandler(git, semverProc, mnrp, Config{}, repoRoot, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("monorepoNextVersionHandler() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("monorepoNextVersionHandler() unexpected error: %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("monorepoNextVersionHandler() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_monorepoNextVersionHandler_IgnorePaths(t *testing.T) {
+	repoRoot := t.TempDir()
+	alpha := makeComponent(t, "alpha", "1.0.0")
+	alpha.RootPath = filepath.Join(repoRoot, "services", "alpha")
+	git := mockGit{
+		logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{{Hash: "a", Files: []string{"services/alpha/docs/usage.md", "main.go"}}}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{alpha}, nil
+		},
+		nextVersionFn: func(component sv.MonorepoComponent, commits []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			if len(commits) == 0 {
+				return component.CurrentVersion, false
+			}
+			return semver.MustParse("1.0.1"), true
+		},
+	}
+	cfg := Config{Versioning: sv.VersioningConfig{IgnorePaths: []string{"docs"}}}
+
+	out, stdout := newTestPrinter()
+	if err := monorepoNextVersionHandler(git, mockSemVerProcessor{}, mnrp, cfg, repoRoot, out)(newCLICtx()); err != nil {
+		t.Fatalf("monorepoNextVersionHandler() unexpected error: %v", err)
+	}
+	if got, want := stdout.String(), "alpha: 1.0.0 (no change)\n"; got != want {
+		t.Errorf("monorepoNextVersionHandler() output = %q, want %q", got, want)
+	}
+}
+
+func Test_bumpLevel(t *testing.T) {
+	tests := []struct {
+		current string
+		next    string
+		want    string
+	}{
+		{"1.0.0", "2.0.0", "major"},
+		{"1.0.0", "1.1.0", "minor"},
+		{"1.0.0", "1.0.1", "patch"},
+		{"1.0.0", "1.0.0", "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.next, func(t *testing.T) {
+			if got := bumpLevel(semver.MustParse(tt.current), semver.MustParse(tt.next)); got != tt.want {
+				t.Errorf("bumpLevel() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_monorepoNextVersionHandler_ForcedVersion(t *testing.T) {
+	alpha := makeComponent(t, "alpha", "1.0.0")
+	beta := makeComponent(t, "beta", "2.0.0")
+
+	git := mockGit{
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{alpha, beta}, nil
+		},
+		nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return component.CurrentVersion, false
+		},
+	}
+
+	tests := []struct {
+		name       string
+		components []string
+		bump       string
+		setVersion string
+		want       string
+		wantErr    bool
+	}{
+		{"no override", nil, "", "", "alpha: 1.0.0 (no change)\nbeta: 2.0.0 (no change)\n", false},
+		{"bump all components", nil, "patch", "", "alpha: 1.0.1\nbeta: 2.0.1\n", false},
+		{"bump selected component", []string{"beta"}, "major", "", "beta: 3.0.0\n", false},
+		{"set version of selected component", []string{"alpha"}, "", "1.4.0", "alpha: 1.4.0\n", false},
+		{"set version lower than a component", nil, "", "1.4.0", "", true},
+		{"unknown component", []string{"gamma"}, "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Var(cli.NewStringSlice(tt.components...), "component", "")
+			set.String("bump", tt.bump, "")
+			set.String("set-version", tt.setVersion, "")
+			set.Bool("allow-downgrade", false, "")
+
+			out, stdout := newTestPrinter()
+			handler := monorepoNextVersionHandler(git, mockSemVerProcessor{}, mnrp, Config{}, t.TempDir(), out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("monorepoNextVersionHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("monorepoNextVersionHandler() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_monorepoNextVersionHandler_Prerelease(t *testing.T) {
+	repoRoot := t.TempDir()
+	now := time.Now()
+	tags := []sv.GitTag{
+		{Name: "alpha/v1.3.0", Date: now.Add(-3 * time.Hour)},
+		{Name: "alpha/v1.4.0-beta.1", Date: now.Add(-2 * time.Hour)},
+		{Name: "alpha/v1.4.0-beta.2", Date: now.Add(-time.Hour)},
+	}
+	feat := sv.GitCommitLog{Hash: "a", Message: sv.CommitMessage{Type: "feat"}}
+	breaking := sv.GitCommitLog{Hash: "b", Message: sv.CommitMessage{Type: "fix", IsBreakingChange: true}}
+
+	tests := []struct {
+		name        string
+		branch      string
+		sinceStable []sv.GitCommitLog
+		sinceBeta   []sv.GitCommitLog
+		want        string
+	}{
+		{"next prerelease on channel", "develop", []sv.GitCommitLog{feat}, []sv.GitCommitLog{feat}, "alpha: 1.4.0-beta.3\n"},
+		{"no commits since last prerelease", "develop", []sv.GitCommitLog{feat}, nil, "alpha: 1.4.0-beta.2 (no change)\n"},
+		{"breaking change starts new version", "develop", []sv.GitCommitLog{feat, breaking}, []sv.GitCommitLog{breaking}, "alpha: 2.0.0-beta.1\n"},
+		{"other channel", "release/1.4", []sv.GitCommitLog{feat}, []sv.GitCommitLog{feat}, "alpha: 1.4.0-rc.1\n"},
+		{"stable from stable tag", "main", []sv.GitCommitLog{feat}, nil, "alpha: 1.4.0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comp := makeComponent(t, "alpha", "1.4.0-beta.2")
+			comp.RootPath = filepath.Join(repoRoot, "alpha")
+			git := mockGit{
+				branch:    tt.branch,
+				tagsAllFn: func() ([]sv.GitTag, error) { return tags, nil },
+				logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+					switch {
+					case reflect.DeepEqual(lr, sv.NewLogRangeWithPaths(sv.TagRange, "alpha/v1.3.0", "", []string{"alpha"})):
+						return tt.sinceStable, nil
+					case reflect.DeepEqual(lr, sv.NewLogRangeWithPaths(sv.TagRange, "alpha/v1.4.0-beta.2", "", []string{"alpha"})):
+						return tt.sinceBeta, nil
+					}
+					t.Errorf("unexpected log range %+v", lr)
+					return nil, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+				nextVersionFn: func(component sv.MonorepoComponent, commits []sv.GitCommitLog, semverProc sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+					return semverProc.NextVersion(component.CurrentVersion, commits)
+				},
+			}
+			cfg := defaultConfig()
+			cfg.Monorepo.Prerelease.BranchMap = map[string]string{"develop": "beta", "release/.*": "rc"}
+
+			out, stdout := newTestPrinter()
+			handler := monorepoNextVersionHandler(git, sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage), mnrp, cfg, repoRoot, out)
+			if err := handler(newCLICtx()); err != nil {
+				t.Fatalf("monorepoNextVersionHandler() error = %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("monorepoNextVersionHandler() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_monorepoPromoteHandler(t *testing.T) {
+	comp := makeComponent(t, "alpha", "1.4.0-beta.2")
+	tags := []sv.GitTag{
+		{Name: "alpha/v1.3.0"},
+		{Name: "alpha/v1.4.0-beta.1"},
+		{Name: "alpha/v1.4.0-beta.2"},
+		{Name: "alpha/v1.5.0-rc.1"},
+		{Name: "beta/v2.0.0-beta.1"},
+	}
+
+	tests := []struct {
+		name       string
+		components []string
+		channel    string
+		existing   string
+		wantTag    string
+		wantRef    string
+		wantErr    bool
+	}{
+		{"latest prerelease", []string{"alpha"}, "", "", "alpha/v1.5.0", "refs/tags/alpha/v1.5.0-rc.1", false},
+		{"latest prerelease of channel", []string{"alpha"}, "beta", "", "alpha/v1.4.0", "refs/tags/alpha/v1.4.0-beta.2", false},
+		{"no prerelease of channel", []string{"alpha"}, "alpha", "", "", "", true},
+		{"stable already tagged", []string{"alpha"}, "beta", "alpha/v1.4.0", "", "", true},
+		{"missing component", nil, "", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTag, gotRef string
+			git := mockGit{
+				tagsAllFn: func() ([]sv.GitTag, error) { return tags, nil },
+				tagForComponentAt: func(version semver.Version, componentPath, ref string) (string, error) {
+					tag := componentPath + "/v" + version.String()
+					if tag == tt.existing {
+						return tag, sv.TagExistsError{Tag: tag, Commit: "abc1234"}
+					}
+					gotTag, gotRef = tag, ref
+					return tag, nil
+				},
+			}
+			mnrp := mockMonorepoProcessor{
+				findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+					return []sv.MonorepoComponent{comp}, nil
+				},
+			}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Var(cli.NewStringSlice(tt.components...), "component", "")
+			set.String("channel", tt.channel, "")
+
+			out, _ := newTestPrinter()
+			err := monorepoPromoteHandler(git, mnrp, defaultConfig(), t.TempDir(), out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("monorepoPromoteHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotTag != tt.wantTag || gotRef != tt.wantRef {
+				t.Errorf("monorepoPromoteHandler() tag = %q on %q, want %q on %q", gotTag, gotRef, tt.wantTag, tt.wantRef)
+			}
+		})
+	}
+}
+
+func Test_monorepoStatusHandler(t *testing.T) {
+	repoPath := t.TempDir()
+	writeFile(t, filepath.Join(repoPath, "services", "alpha", "version.yml"), "metadata:\n    version: 1.2.0\n")
+	for _, dir := range []string{"beta", "gamma"} {
+		if err := os.MkdirAll(filepath.Join(repoPath, "services", dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := defaultConfig()
+	cfg.Monorepo = sv.MonorepoConfig{VersioningFile: "services/*/version.yml", Path: "metadata.version", ComponentDirs: "services/*"}
+
+	run := func(args ...string) (string, error) {
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		set.Bool("create-missing", false, "")
+		set.String("initial-version", "0.1.0", "")
+		if err := set.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		out, stdout := newTestPrinter()
+		err := monorepoStatusHandler(cfg, repoPath, out)(cli.NewContext(cli.NewApp(), set, nil))
+		return stdout.String(), err
+	}
+
+	stdout, err := run()
+	if err == nil || err.Error() != "2 component directory(ies) without versioning file, they are not released: services/beta, services/gamma, use --create-missing to create them" {
+		t.Errorf("monorepoStatusHandler() error = %v, want missing versioning files error", err)
+	}
+	want := "DIRECTORY       COMPONENT       VERSION  STATUS\n" +
+		"services/alpha  services/alpha  1.2.0    ok\n" +
+		"services/beta   -               -        missing services/beta/version.yml\n" +
+		"services/gamma  -               -        missing services/gamma/version.yml\n"
+	if stdout != want {
+		t.Errorf("monorepoStatusHandler() stdout = %q, want %q", stdout, want)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "services", "beta", "version.yml")); !os.IsNotExist(err) {
+		t.Errorf("monorepoStatusHandler() without --create-missing created a versioning file, stat error = %v", err)
+	}
+
+	if _, err := run("--create-missing", "--initial-version", "0.2.0"); err != nil {
+		t.Fatalf("monorepoStatusHandler() with --create-missing error = %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repoPath, "services", "beta", "version.yml")); string(content) != "metadata:\n    version: 0.2.0\n" {
+		t.Errorf("created versioning file = %q, want nested version", content)
+	}
+	components, _, err := sv.NewMonorepoProcessor().FindComponents(repoPath, cfg.Monorepo)
+	if err != nil || len(components) != 3 {
+		t.Errorf("FindComponents() = %v, %v, want 3 components", components, err)
+	}
+	if _, err := run(); err != nil {
+		t.Errorf("monorepoStatusHandler() after --create-missing error = %v", err)
+	}
+	if _, err := run("--initial-version", "abc"); err == nil {
+		t.Error("monorepoStatusHandler() expected error for invalid initial version")
+	}
+}
+
+func Test_retagHandler_Component(t *testing.T) {
+	payments := makeComponent(t, "payments", "1.1.0")
+	repoPath := filepath.Dir(payments.RootPath)
+	tags := []sv.GitTag{
+		{Name: "payments/v1.0.0", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "other/v1.0.0", Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{Name: "payments/v1.1.0", Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	tests := []struct {
+		name       string
+		movedAfter bool
+		wantErr    string
+		wantPushed []string
+	}{
+		{"recreated and pushed", false, "", []string{"payments/v1.1.0"}},
+		{"commit changed", true, "tag payments/v1.1.0 points to commit bbbbbbb after it was recreated instead of aaaaaaa, restore it with: git tag -f payments/v1.1.0 aaaaaaaaaa", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replaced, logRange string
+			var replacedDate time.Time
+			var pushed []string
+			git := mockGit{
+				tagRemote: "origin",
+				tagsAllFn: func() ([]sv.GitTag, error) { return tags, nil },
+				logFn: func(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+					logRange = fmt.Sprintf("%+v", lr)
+					return nil, nil
+				},
+				tagTargetFn: func(tag string) (string, bool, error) {
+					if replaced != "" && tt.movedAfter {
+						return "bbbbbbbbbb", true, nil
+					}
+					return "aaaaaaaaaa", true, nil
+				},
+				replaceTagFn: func(tag, message, commit string, date time.Time) error {
+					replaced, replacedDate = tag+" "+commit+" "+message, date
+					return nil
+				},
+				forcePushTagsFn: func(remote string, tags []string) error {
+					pushed = append(pushed, tags...)
+					return nil
+				},
+			}
+			processor := mockMonorepoProcessor{findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+				return []sv.MonorepoComponent{payments}, nil
+			}}
+			formatter := mockOutputFormatter{formatReleaseNoteFn: func(rn sv.ReleaseNote) (string, error) {
+				return fmt.Sprintf("%s since %s", rn.Tag, rn.PreviousTag), nil
+			}}
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Var(cli.NewStringSlice("payments/v1.1.0"), "t", "")
+			set.String("component", "payments", "")
+			set.Bool("yes", true, "")
+
+			out, _ := newTestPrinter()
+			err := retagHandler(git, processor, mockReleaseNoteProcessor{}, formatter, Config{}, repoPath, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("retagHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if want := "payments/v1.1.0 aaaaaaaaaa payments/v1.1.0 since payments/v1.0.0"; replaced != want || !replacedDate.Equal(tags[2].Date) {
+				t.Errorf("retagHandler() replaced = %q on %v, want %q on %v", replaced, replacedDate, want, tags[2].Date)
+			}
+			if !strings.Contains(logRange, "payments") {
+				t.Errorf("retagHandler() log range = %s, want component path", logRange)
+			}
+			if !reflect.DeepEqual(pushed, tt.wantPushed) {
+				t.Errorf("retagHandler() pushed = %v, want %v", pushed, tt.wantPushed)
+			}
+		})
+	}
+}
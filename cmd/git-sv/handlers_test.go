@@ -0,0 +1,1555 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+func Test_stageCommitFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		all       bool
+		paths     []string
+		dryRun    bool
+		staged    bool
+		addErr    error
+		wantCalls [][]string
+		wantErr   bool
+	}{
+		{"nothing staged", false, nil, false, false, nil, nil, true},
+		{"already staged", false, nil, false, true, nil, nil, false},
+		{"all", true, nil, false, true, nil, [][]string{{}}, false},
+		{"add paths", false, []string{"a.txt", "b.txt"}, false, true, nil, [][]string{{"a.txt", "b.txt"}}, false},
+		{"all and add paths", true, []string{"a.txt"}, false, true, nil, [][]string{{}, {"a.txt"}}, false},
+		{"dry run stages nothing", true, []string{"a.txt"}, true, false, nil, nil, false},
+		{"add error", false, []string{"a.txt"}, false, true, errors.New("error"), [][]string{{"a.txt"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]string
+			git := mockGit{
+				addFn: func(paths ...string) error {
+					calls = append(calls, append([]string{}, paths...))
+					return tt.addErr
+				},
+				hasStagedChangesFn: func() (bool, error) { return tt.staged, nil },
+			}
+
+			err := stageCommitFiles(git, tt.all, tt.paths, tt.dryRun)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("stageCommitFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("stageCommitFiles() add calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_confirmStagedChanges_Skip(t *testing.T) {
+	git := mockGit{
+		stagedDiffStatFn: func() (string, error) {
+			t.Error("StagedDiffStat() should not be called when confirmation is skipped")
+			return "", nil
+		},
+	}
+
+	confirmed, err := confirmStagedChanges(git, true, newPrinter(io.Discard, io.Discard))
+	if err != nil || !confirmed {
+		t.Errorf("confirmStagedChanges() = %v, %v, want true, nil", confirmed, err)
+	}
+}
+
+func Test_confirmStagedChanges_DiffStatError(t *testing.T) {
+	git := mockGit{
+		stagedDiffStatFn: func() (string, error) { return "", errors.New("error") },
+	}
+
+	if _, err := confirmStagedChanges(git, false, newPrinter(io.Discard, io.Discard)); err == nil {
+		t.Error("confirmStagedChanges() expected error when StagedDiffStat fails, got nil")
+	}
+}
+
+func Test_checkHistoryHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		shallow       bool
+		fetchFlag     bool
+		autoFetch     bool
+		unshallowErr  error
+		wantUnshallow bool
+		wantErr       bool
+	}{
+		{"complete history", false, false, false, nil, false, false},
+		{"complete history with fetch", false, true, false, nil, false, false},
+		{"shallow without fetch", true, false, false, nil, false, true},
+		{"shallow with fetch flag", true, true, false, nil, true, false},
+		{"shallow with auto fetch", true, false, true, nil, true, false},
+		{"shallow fetch error", true, true, false, errors.New("error"), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unshallowCalled := false
+			git := mockGit{
+				isShallowFn: func() (bool, error) { return tt.shallow, nil },
+				unshallowFn: func() error {
+					unshallowCalled = true
+					return tt.unshallowErr
+				},
+			}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("fetch", tt.fetchFlag, "")
+			err := checkHistoryHandler(git, sv.VersioningConfig{AutoFetch: tt.autoFetch}, newPrinter(io.Discard, io.Discard))(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkHistoryHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if unshallowCalled != tt.wantUnshallow {
+				t.Errorf("checkHistoryHandler() unshallow called = %v, want %v", unshallowCalled, tt.wantUnshallow)
+			}
+		})
+	}
+}
+
+func Test_checkHistoryHandler_FetchTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		fetchFlag   bool
+		fetchBefore bool
+		fetchErr    error
+		wantFetch   bool
+		wantWarn    bool
+	}{
+		{"without fetch", false, false, nil, false, false},
+		{"fetch flag", true, false, nil, true, false},
+		{"fetch before config", false, true, nil, true, false},
+		{"offline", true, false, errors.New("could not resolve host"), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched := false
+			git := mockGit{
+				fetchFn: func(tagsOnly bool) error {
+					fetched = tagsOnly
+					return tt.fetchErr
+				},
+			}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("fetch", tt.fetchFlag, "")
+			var stderr bytes.Buffer
+			err := checkHistoryHandler(git, sv.VersioningConfig{FetchBefore: tt.fetchBefore}, newPrinter(io.Discard, &stderr))(cli.NewContext(cli.NewApp(), set, nil))
+			if err != nil {
+				t.Fatalf("checkHistoryHandler() unexpected error: %v", err)
+			}
+			if fetched != tt.wantFetch {
+				t.Errorf("checkHistoryHandler() tags fetched = %v, want %v", fetched, tt.wantFetch)
+			}
+			if got := strings.Contains(stderr.String(), "could not fetch tags"); got != tt.wantWarn {
+				t.Errorf("checkHistoryHandler() stderr = %q, want warning %v", stderr.String(), tt.wantWarn)
+			}
+		})
+	}
+}
+
+func Test_getTagRemote(t *testing.T) {
+	tests := []struct {
+		name      string
+		tagRemote string
+		flag      *string
+		exists    bool
+		want      string
+		wantErr   bool
+	}{
+		{"default remote", "origin", nil, true, "origin", false},
+		{"flag overrides default", "origin", strPtr("upstream"), true, "upstream", false},
+		{"empty default skips push", "", nil, false, "", false},
+		{"empty flag skips push", "origin", strPtr(""), false, "", false},
+		{"remote not found", "origin", strPtr("unknown"), false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{
+				tagRemote:      tt.tagRemote,
+				remoteExistsFn: func(remote string) (bool, error) { return tt.exists, nil },
+			}
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("remote", "", "")
+			if tt.flag != nil {
+				if err := set.Set("remote", *tt.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := getTagRemote(git, cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getTagRemote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getTagRemote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func strPtr(value string) *string {
+	return &value
+}
+
+// newTestPrinter returns a printer writing command results to the returned buffer, stderr is discarded.
+func newTestPrinter() (*printer, *bytes.Buffer) {
+	var stdout bytes.Buffer
+	return newPrinter(&stdout, io.Discard), &stdout
+}
+
+func Test_tagHandler_ReleaseBranch(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  sv.ReleaseBranch
+		lastTag string
+		wantTag string
+		wantErr bool
+	}{
+		{"not a release branch", sv.ReleaseBranch{}, "2.0.0", "2.0.1", false},
+		{"version on branch line", sv.ReleaseBranch{Name: "release/1.2.x", Major: 1, Minor: 2}, "1.2.3", "1.2.4", false},
+		{"version from another line", sv.ReleaseBranch{Name: "release/1.2.x", Major: 1, Minor: 2}, "2.0.0", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created string
+			git := mockGit{
+				lastTag: tt.lastTag,
+				logFn:   func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc1234"}}, nil },
+				tagFn: func(version semver.Version, _ string) (string, error) {
+					created = version.String()
+					return created, nil
+				},
+			}
+			semverProc := mockSemVerProcessor{nextVersionFn: func(v *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) {
+				next := v.IncPatch()
+				return &next, true
+			}}
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("remote", "", "")
+
+			out, _ := newTestPrinter()
+			err := tagHandler(git, semverProc, Config{}, tt.branch, "", out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tagHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if created != tt.wantTag {
+				t.Errorf("tagHandler() created tag = %q, want %q", created, tt.wantTag)
+			}
+		})
+	}
+}
+
+func Test_tagHandler_Output(t *testing.T) {
+	pushErr := errors.New("exit status 1: remote rejected")
+	tests := []struct {
+		name       string
+		tagErr     error
+		wantStdout string
+		wantErr    string
+	}{
+		{"tag created and pushed", nil, "v1.2.4\n", ""},
+		{"push failed", sv.TagPushError{Tag: "v1.2.4", Remote: "origin", Err: pushErr}, "", "push it with: git push origin v1.2.4"},
+		{"tag failed", errors.New("tag error"), "", "tag error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{
+				lastTag:   "v1.2.3",
+				tagRemote: "origin",
+				logFn:     func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc1234"}}, nil },
+				tagFn: func(version semver.Version, _ string) (string, error) {
+					return "v" + version.String(), tt.tagErr
+				},
+			}
+			semverProc := mockSemVerProcessor{nextVersionFn: func(v *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) {
+				next := v.IncPatch()
+				return &next, true
+			}}
+
+			out, stdout := newTestPrinter()
+			err := tagHandler(git, semverProc, Config{}, sv.ReleaseBranch{}, "", out)(newCLICtx())
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("tagHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("tagHandler() stdout = %q, want %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
+
+func Test_retagQuestion(t *testing.T) {
+	existsErr := sv.TagExistsError{Tag: "v1.2.0", Commit: "abc1234"}
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{"head", "HEAD", "tag v1.2.0 already exists on commit abc1234, move it to HEAD?"},
+		{"commit flag", "def5678", "tag v1.2.0 already exists on commit abc1234, move it to def5678?"},
+		{"promoted prerelease", "api/v1.2.0-rc.1", "tag v1.2.0 already exists on commit abc1234, move it to api/v1.2.0-rc.1?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retagQuestion(existsErr, tt.target); got != tt.want {
+				t.Errorf("retagQuestion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_nextVersionHandler_BuildMetadata(t *testing.T) {
+	t.Setenv("BUILD_NUMBER", "4821")
+	tests := []struct {
+		name     string
+		template string
+		flag     *string
+		want     string
+	}{
+		{"no metadata", "", nil, "1.3.0\n"},
+		{"config template", "build.{env:BUILD_NUMBER}.sha.{hash}", nil, "1.3.0+build.4821.sha.abc1234\n"},
+		{"flag overrides config", "build.{env:BUILD_NUMBER}", strPtr("sha.{hash}"), "1.3.0+sha.abc1234\n"},
+		{"flag disables config", "build.{env:BUILD_NUMBER}", strPtr(""), "1.3.0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{
+				lastTag: "1.2.0",
+				logFn:   func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+			}
+			semverProc := mockSemVerProcessor{nextVersionFn: func(v *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) {
+				next := v.IncMinor()
+				return &next, true
+			}}
+			cfg := Config{Versioning: sv.VersioningConfig{MetadataTemplate: tt.template}}
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("metadata", "", "")
+			if tt.flag != nil {
+				if err := set.Set("metadata", *tt.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			out, stdout := newTestPrinter()
+			if err := nextVersionHandler(git, semverProc, cfg, out)(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+				t.Fatalf("nextVersionHandler() unexpected error: %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("nextVersionHandler() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_nextVersionHandler_Explain(t *testing.T) {
+	commits := []sv.GitCommitLog{
+		{Hash: "abc1234", Message: sv.CommitMessage{Type: "fix", Description: "handle nil"}},
+		{Hash: "def5678", Message: sv.CommitMessage{Type: "feat", Scope: "api", Description: "drop v1", IsBreakingChange: true}},
+	}
+	git := mockGit{
+		lastTag: "1.2.0",
+		logFn:   func(sv.LogRange) ([]sv.GitCommitLog, error) { return commits, nil },
+	}
+	semverProc := sv.NewSemVerCommitsProcessor(sv.VersioningConfig{UpdateMinor: []string{"feat"}, UpdatePatch: []string{"fix"}}, sv.CommitMessageConfig{Types: []string{"feat", "fix"}})
+
+	tests := []struct {
+		name  string
+		flags map[string]string
+		want  string
+	}{
+		{"without explain", map[string]string{}, "2.0.0\n"},
+		{"text", map[string]string{"explain": "true"}, "2.0.0\nmajor update required by 1 commit(s):\n  def5678 feat(api)!: drop v1 (breaking change)\n"},
+		{"forced bump", map[string]string{"explain": "true", "bump": "patch"}, "1.2.1\nversion forced by --bump patch, commits are ignored\nmajor update required by 1 commit(s):\n  def5678 feat(api)!: drop v1 (breaking change)\n"},
+		{"json", map[string]string{"explain": "true", "format": "json"}, `{
+  "currentVersion": "1.2.0",
+  "nextVersion": "2.0.0",
+  "updated": true,
+  "explanation": {
+    "bump": "major",
+    "applied": "major",
+    "commits": [
+      {
+        "hash": "def5678",
+        "subject": "feat(api)!: drop v1",
+        "bump": "major",
+        "reason": "breaking change"
+      }
+    ]
+  }
+}
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("explain", false, "")
+			set.String("format", "text", "")
+			set.String("bump", "", "")
+			for name, value := range tt.flags {
+				if err := set.Set(name, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			out, stdout := newTestPrinter()
+			if err := nextVersionHandler(git, semverProc, Config{}, out)(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+				t.Fatalf("nextVersionHandler() unexpected error: %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("nextVersionHandler() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_nextVersionHandler_IgnorePaths(t *testing.T) {
+	git := mockGit{
+		lastTag: "1.2.0",
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+			return []sv.GitCommitLog{
+				{Hash: "abc1234", Message: sv.CommitMessage{Type: "feat", Description: "new guide"}, Files: []string{"docs/guide.md"}},
+				{Hash: "def5678", Message: sv.CommitMessage{Type: "fix", Description: "handle nil"}, Files: []string{"docs/guide.md", "main.go"}},
+			}, nil
+		},
+	}
+	tests := []struct {
+		name   string
+		ignore []string
+		want   string
+	}{
+		{"without ignore paths", nil, "1.3.0\n"},
+		{"ignoring docs", []string{"docs/"}, "1.2.1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcfg := sv.VersioningConfig{UpdateMinor: []string{"feat"}, UpdatePatch: []string{"fix"}, IgnorePaths: tt.ignore}
+			semverProc := sv.NewSemVerCommitsProcessor(vcfg, sv.CommitMessageConfig{Types: []string{"feat", "fix"}})
+
+			out, stdout := newTestPrinter()
+			if err := nextVersionHandler(git, semverProc, Config{Versioning: vcfg}, out)(newCLICtx()); err != nil {
+				t.Fatalf("nextVersionHandler() unexpected error: %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("nextVersionHandler() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_versionOverride(t *testing.T) {
+	tests := []struct {
+		name           string
+		bump           string
+		setVersion     string
+		allowDowngrade bool
+		want           string
+		wantErr        bool
+	}{
+		{"no override", "", "", false, "", false},
+		{"bump major", "major", "", false, "2.0.0", false},
+		{"bump minor", "minor", "", false, "1.3.0", false},
+		{"bump patch", "patch", "", false, "1.2.4", false},
+		{"invalid bump", "huge", "", false, "", true},
+		{"set version", "", "v1.5.0", false, "1.5.0", false},
+		{"invalid set version", "", "abc", false, "", true},
+		{"set lower version", "", "1.0.0", false, "", true},
+		{"set equal version", "", "1.2.3", false, "", true},
+		{"set lower version allowing downgrade", "", "1.0.0", true, "1.0.0", false},
+		{"bump and set version", "minor", "1.5.0", false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("bump", tt.bump, "")
+			set.String("set-version", tt.setVersion, "")
+			set.Bool("allow-downgrade", tt.allowDowngrade, "")
+
+			got, err := versionOverride(cli.NewContext(cli.NewApp(), set, nil), semver.MustParse("1.2.3"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("versionOverride() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			gotStr := ""
+			if got != nil {
+				gotStr = got.String()
+			}
+			if gotStr != tt.want {
+				t.Errorf("versionOverride() = %s, want %s", gotStr, tt.want)
+			}
+		})
+	}
+}
+
+func Test_changelogHandler_DateRange(t *testing.T) {
+	date := func(value string) time.Time {
+		d, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	git := mockGit{
+		tagsFn: func() ([]sv.GitTag, error) {
+			return []sv.GitTag{
+				{Name: "v1.0.0", Date: date("2023-12-31T23:30:00Z")},
+				{Name: "v1.1.0", Date: date("2024-01-01T00:30:00Z")},
+				{Name: "v1.2.0", Date: date("2024-03-15T10:00:00Z")},
+				{Name: "v1.3.0", Date: date("2024-06-30T23:30:00Z")},
+				{Name: "v1.4.0", Date: date("2024-07-01T00:30:00Z")},
+			}, nil
+		},
+		lastTag: "v1.4.0",
+		logFn:   func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "a"}}, nil },
+	}
+	semverProc := mockSemVerProcessor{nextVersionFn: func(v *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) {
+		next := v.IncMinor()
+		return &next, true
+	}}
+
+	tests := []struct {
+		name           string
+		since          string
+		until          string
+		size           int
+		addNextVersion bool
+		want           []string
+		wantErr        bool
+	}{
+		{"no range", "", "", 10, false, []string{"v1.4.0", "v1.3.0", "v1.2.0", "v1.1.0", "v1.0.0"}, false},
+		{"since and until", "2024-01-01", "2024-06-30", 10, false, []string{"v1.3.0", "v1.2.0", "v1.1.0"}, false},
+		{"size applied after range", "2024-01-01", "2024-06-30", 2, false, []string{"v1.3.0", "v1.2.0"}, false},
+		{"only since", "2024-03-15", "", 10, false, []string{"v1.4.0", "v1.3.0", "v1.2.0"}, false},
+		{"next version with past until", "", "2024-06-30", 1, true, []string{"v1.3.0"}, false},
+		{"next version without until", "2024-07-01", "", 10, true, []string{"1.5.0", "v1.4.0"}, false},
+		{"invalid since", "01/01/2024", "", 10, false, nil, true},
+		{"since after until", "2024-06-30", "2024-01-01", 10, false, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("since", tt.since, "")
+			set.String("until", tt.until, "")
+			set.Bool("utc", true, "")
+			set.Int("size", tt.size, "")
+			set.Bool("add-next-version", tt.addNextVersion, "")
+
+			var got []string
+			formatter := mockOutputFormatter{formatChangelogFn: func(releasenotes []sv.ReleaseNote) (string, error) {
+				for _, rn := range releasenotes {
+					got = append(got, str(rn.Tag, rn.Version.String()))
+				}
+				return "", nil
+			}}
+			out, _ := newTestPrinter()
+			err := changelogHandler(git, semverProc, mockReleaseNoteProcessor{}, formatter, Config{}, sv.SystemClock{}, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("changelogHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changelogHandler() versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_changelogHandler_VersionRange(t *testing.T) {
+	date := func(value string) time.Time {
+		d, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	git := mockGit{
+		tagsFn: func() ([]sv.GitTag, error) {
+			return []sv.GitTag{
+				{Name: "v1.7.0", Date: date("2023-11-01T10:00:00Z")},
+				{Name: "v1.8.0", Date: date("2024-01-01T10:00:00Z")},
+				{Name: "v2.0.0", Date: date("2024-03-01T10:00:00Z")},
+				{Name: "v1.9.1", Date: date("2024-04-01T10:00:00Z")},
+				{Name: "v2.1.0", Date: date("2024-05-01T10:00:00Z")},
+				{Name: "v2.2.0", Date: date("2024-06-01T10:00:00Z")},
+				{Name: "v2.3.0", Date: date("2024-07-01T10:00:00Z")},
+			}, nil
+		},
+		lastTag: "v2.3.0",
+		logFn:   func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "a"}}, nil },
+	}
+	semverProc := mockSemVerProcessor{nextVersionFn: func(v *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) {
+		next := v.IncMinor()
+		return &next, true
+	}}
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		fuzzy   bool
+		want    []string
+		wantErr bool
+	}{
+		{"inclusive range by version", "1.8.0", "2.2.0", false, []string{"v2.2.0<v2.1.0", "v2.1.0<v2.0.0", "v2.0.0<v1.9.1", "v1.9.1<v1.8.0", "v1.8.0<v1.7.0"}, false},
+		{"only from adds next version", "v2.2.0", "", false, []string{"2.4.0<v2.3.0", "v2.3.0<v2.2.0", "v2.2.0<v2.1.0"}, false},
+		{"only to", "", "1.8.0", false, []string{"v1.8.0<v1.7.0", "v1.7.0<"}, false},
+		{"from without tag", "1.8.5", "2.2.0", false, nil, true},
+		{"to without tag", "1.8.0", "2.1.5", false, nil, true},
+		{"fuzzy bounds", "1.8.5", "2.1.5", true, []string{"v2.2.0<v2.1.0", "v2.1.0<v2.0.0", "v2.0.0<v1.9.1", "v1.9.1<v1.8.0", "v1.8.0<v1.7.0"}, false},
+		{"fuzzy bounds outside tags", "1.0.0", "3.0.0", true, []string{"v2.3.0<v2.2.0", "v2.2.0<v2.1.0", "v2.1.0<v2.0.0", "v2.0.0<v1.9.1", "v1.9.1<v1.8.0", "v1.8.0<v1.7.0", "v1.7.0<"}, false},
+		{"from greater than to", "2.2.0", "1.8.0", false, nil, true},
+		{"fuzzy without range", "", "", true, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("from-version", tt.from, "")
+			set.String("to-version", tt.to, "")
+			set.Bool("fuzzy", tt.fuzzy, "")
+			set.Int("size", 1, "")
+			set.Bool("add-next-version", true, "")
+
+			var got []string
+			formatter := mockOutputFormatter{formatChangelogFn: func(releasenotes []sv.ReleaseNote) (string, error) {
+				for _, rn := range releasenotes {
+					got = append(got, str(rn.Tag, rn.Version.String())+"<"+rn.PreviousTag)
+				}
+				return "", nil
+			}}
+			out, _ := newTestPrinter()
+			err := changelogHandler(git, semverProc, mockReleaseNoteProcessor{}, formatter, Config{}, sv.SystemClock{}, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("changelogHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changelogHandler() versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// streamingFormatter records each release note as WriteChangelog reads it.
+type streamingFormatter struct {
+	mockOutputFormatter
+	events *[]string
+}
+
+func (f streamingFormatter) WriteChangelog(_ io.Writer, next sv.ReleaseNoteSource) error {
+	for {
+		rn, ok, err := next()
+		if err != nil || !ok {
+			return err
+		}
+		*f.events = append(*f.events, "format "+rn.Tag)
+	}
+}
+
+func Test_changelogHandler_Streaming(t *testing.T) {
+	tests := []struct {
+		name   string
+		dedupe bool
+		want   []string
+	}{
+		{"commits read per release", false, []string{"log", "format v1.2.0", "log", "format v1.1.0", "log", "format v1.0.0"}},
+		{"dedupe cherry-picks reads every release first", true, []string{"log", "log", "log", "format v1.2.0", "format v1.1.0", "format v1.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			git := mockGit{
+				tagsFn: func() ([]sv.GitTag, error) {
+					return []sv.GitTag{
+						{Name: "v1.0.0", Date: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+						{Name: "v1.1.0", Date: time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)},
+						{Name: "v1.2.0", Date: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+					}, nil
+				},
+				logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+					events = append(events, "log")
+					return []sv.GitCommitLog{{Hash: "a"}}, nil
+				},
+			}
+			cfg := Config{ReleaseNotes: sv.ReleaseNotesConfig{DedupeCherryPicks: tt.dedupe}}
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Int("size", 10, "")
+
+			out, _ := newTestPrinter()
+			err := changelogHandler(git, mockSemVerProcessor{}, mockReleaseNoteProcessor{}, streamingFormatter{events: &events}, cfg, sv.SystemClock{}, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if err != nil {
+				t.Fatalf("changelogHandler() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(events, tt.want) {
+				t.Errorf("changelogHandler() events = %v, want %v", events, tt.want)
+			}
+		})
+	}
+}
+
+func Test_changelogHandler_LogError(t *testing.T) {
+	git := mockGit{
+		tagsFn: func() ([]sv.GitTag, error) {
+			return []sv.GitTag{{Name: "v1.0.0", Date: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}}, nil
+		},
+		logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, errors.New("bad revision") },
+	}
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Int("size", 10, "")
+
+	out, _ := newTestPrinter()
+	err := changelogHandler(git, mockSemVerProcessor{}, mockReleaseNoteProcessor{}, mockOutputFormatter{}, Config{}, sv.SystemClock{}, out)(cli.NewContext(cli.NewApp(), set, nil))
+	if want := "error getting git log from tag: v1.0.0, message: bad revision"; err == nil || err.Error() != want {
+		t.Errorf("changelogHandler() error = %v, want %q", err, want)
+	}
+}
+
+func Test_validateMessageHandler(t *testing.T) {
+	cfg := defaultConfig()
+	messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+	msgFile := filepath.Join(t.TempDir(), "msg.txt")
+	if err := os.WriteFile(msgFile, []byte("fix: correct parser\n\nrefs: #12\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		message    string
+		fromFile   string
+		stdin      string
+		format     string
+		wantStdout string
+		wantErr    string
+	}{
+		{"valid message", "feat: add validate-message", "", "", "text", "valid commit message\n", ""},
+		{"valid message from file", "", msgFile, "", "text", "valid commit message\n", ""},
+		{"valid message from stdin", "", "", "feat: add stdin\n", "text", "valid commit message\n", ""},
+		{"all violations", "feature: Add thing", "", "", "text", "", "- message type should be one of"},
+		{"json output", "feature: Add thing", "", "", "json", `{
+  "valid": false,
+  "violations": [
+    "message type should be one of [build, ci, chore, docs, feat, fix, perf, refactor, revert, style, test]",
+    "description [Add thing] should begins with lowercase letter"
+  ]
+}
+`, "2 violation(s)"},
+		{"json valid output", "fix: thing", "", "", "json", "{\n  \"valid\": true,\n  \"violations\": []\n}\n", ""},
+		{"message and file", "feat: thing", msgFile, "", "text", "", "cannot define message and from-file"},
+		{"invalid format", "feat: thing", "", "", "xml", "", "invalid format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("message", tt.message, "")
+			set.String("from-file", tt.fromFile, "")
+			set.String("format", tt.format, "")
+			app := cli.NewApp()
+			app.Reader = strings.NewReader(tt.stdin)
+
+			out, stdout := newTestPrinter()
+			err := validateMessageHandler(cfg, messageProcessor, "", out)(cli.NewContext(app, set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validateMessageHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("validateMessageHandler() stdout = %q, want %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
+
+func Test_validateMessageHandler_CheckSpelling(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoPath, ".dictionary"), []byte("# project terms\nseperator\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+
+	tests := []struct {
+		name          string
+		checkSpelling bool
+		dictionary    string
+		wantErr       string
+	}{
+		{"without flag", false, "", ""},
+		{"misspelling", true, "", `word "recieve" at line 1, column 6 is misspelled, did you mean "receive"?`},
+		{"dictionary", true, ".dictionary", `word "recieve"`},
+		{"missing dictionary", true, "missing.txt", "error reading commit-message.spelling.dictionary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.CommitMessage.Spelling.Dictionary = tt.dictionary
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("message", "fix: recieve seperator events", "")
+			set.Bool("check-spelling", tt.checkSpelling, "")
+
+			out, _ := newTestPrinter()
+			err := validateMessageHandler(cfg, messageProcessor, repoPath, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validateMessageHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if err != nil && tt.dictionary == ".dictionary" && strings.Contains(err.Error(), "seperator") {
+				t.Errorf("validateMessageHandler() error = %v, dictionary words should be accepted", err)
+			}
+		})
+	}
+}
+
+func Test_getCommitDescription_BannedWords(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.CommitMessage.BannedWords = []sv.CommitMessageBannedWordConfig{{Regex: "(?i)capybara", Message: "internal codename"}}
+	p := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+	out, _ := newTestPrinter()
+
+	if got, err := getCommitDescription(cfg, p, nil, "add login", out); err != nil || got != "add login" {
+		t.Errorf("getCommitDescription() = %q, %v, want valid description", got, err)
+	}
+	if _, err := getCommitDescription(cfg, p, nil, "enable capybara mode", out); err == nil || !strings.Contains(err.Error(), "internal codename") {
+		t.Errorf("getCommitDescription() error = %v, want banned word error", err)
+	}
+	if _, err := getCommitDescription(cfg, p, sv.NewSpellChecker(nil), "recieve events", out); err == nil || !strings.Contains(err.Error(), "misspelled") {
+		t.Errorf("getCommitDescription() error = %v, want spelling error", err)
+	}
+}
+
+func presetTestConfig() Config {
+	cfg := defaultConfig()
+	cfg.CommitMessage.Presets = map[string]sv.CommitMessagePresetConfig{
+		"infra": {Paths: []string{"infra"}, Branches: []string{"infra/.*"}, Types: []string{"fix", "chore"},
+			Scope: &sv.CommitMessageScopeConfig{Required: true}, RequiredFooters: []string{"Risk"}},
+		"docs": {Paths: []string{"docs"}, Types: []string{"docs", "chore"}},
+	}
+	return cfg
+}
+
+func Test_commitHandler_Preset(t *testing.T) {
+	tests := []struct {
+		name       string
+		staged     []string
+		preset     string
+		ctype      string
+		scope      string
+		footers    []string
+		wantStdout string
+		wantErr    string
+	}{
+		{"preset by staged files", []string{"infra/main.tf"}, "", "fix", "network", []string{"Risk=low"}, "fix(network): update config\n\nRisk: low\n", ""},
+		{"type not allowed by preset", []string{"infra/main.tf"}, "", "feat", "network", []string{"Risk=low"}, "", "message type should be one of [fix, chore]"},
+		{"scope required by preset", []string{"infra/main.tf"}, "", "fix", "", []string{"Risk=low"}, "", "message scope is required"},
+		{"strictest of spanned presets", []string{"docs/index.md", "infra/main.tf"}, "", "docs", "network", []string{"Risk=low"}, "", "message type should be one of [chore]"},
+		{"preset flag", []string{"infra/main.tf"}, "docs", "docs", "", nil, "docs: update config\n", ""},
+		{"unknown preset flag", nil, "web", "docs", "", nil, "", "commit-message preset web not found"},
+		{"no preset matched", []string{"main.go"}, "", "feat", "", nil, "feat: update config\n", ""},
+		{"invalid footer", nil, "", "feat", "", []string{"Risk"}, "", "invalid footer Risk, use key=value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := presetTestConfig()
+			git := mockGit{stagedFilesFn: func() ([]string, error) { return tt.staged, nil }}
+			out, stdout := newTestPrinter()
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			for _, name := range []string{"no-body", "no-issue", "no-breaking", "dry-run"} {
+				set.Bool(name, true, "")
+			}
+			set.Bool("no-scope", tt.scope == "", "")
+			set.String("type", tt.ctype, "")
+			set.String("scope", tt.scope, "")
+			set.String("description", "update config", "")
+			set.String("preset", tt.preset, "")
+			set.Var(cli.NewStringSlice(tt.footers...), "footer", "")
+
+			err := commitHandler(cfg, git, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), "", out)(cli.NewContext(cli.NewApp(), set, nil))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("commitHandler() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("commitHandler() error = %v", err)
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("commitHandler() stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+		})
+	}
+}
+
+func Test_validateCommitMessageHandler_PresetByBranch(t *testing.T) {
+	cfg := presetTestConfig()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		branch  string
+		message string
+		wantErr bool
+	}{
+		{"preset by branch", "infra/network", "fix(network): update routes\n\nRisk: low\n", false},
+		{"preset footer missing", "infra/network", "fix(network): update routes\n", true},
+		{"no preset matched", "feature/x", "feat: update routes\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(dir, "COMMIT_EDITMSG"), []byte(tt.message), 0644); err != nil {
+				t.Fatal(err)
+			}
+			git := mockGit{branch: tt.branch}
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("path", dir, "")
+			set.String("file", "COMMIT_EDITMSG", "")
+			set.String("source", "message", "")
+
+			cfg.Branches.DisableIssue = true
+			err := validateCommitMessageHandler(cfg, git, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), "", newPrinter(io.Discard, io.Discard))(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCommitMessageHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_tagHandler_EmptyRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		bump    string
+		wantTag string
+		wantErr string
+	}{
+		{"no commits since last tag", "", "", "nothing to release since v1.2.3, use --bump or --set-version to tag anyway"},
+		{"forced bump", "minor", "1.3.0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created string
+			git := mockGit{
+				lastTag: "v1.2.3",
+				logFn:   func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil },
+				tagFn: func(version semver.Version, _ string) (string, error) {
+					created = version.String()
+					return created, nil
+				},
+			}
+			// a processor bumping without commits must not create a tag.
+			semverProc := mockSemVerProcessor{nextVersionFn: func(v *semver.Version, _ []sv.GitCommitLog) (*semver.Version, bool) {
+				next := v.IncPatch()
+				return &next, true
+			}}
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("bump", tt.bump, "")
+
+			out, _ := newTestPrinter()
+			err := tagHandler(git, semverProc, Config{}, sv.ReleaseBranch{}, "", out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("tagHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if created != tt.wantTag {
+				t.Errorf("tagHandler() created tag = %q, want %q", created, tt.wantTag)
+			}
+		})
+	}
+}
+
+func Test_releaseNotesHandler_ExistingNextVersionTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        []string
+		useExisting bool
+		updated     bool
+		wantOutput  string
+		wantErr     string
+	}{
+		{"next version not tagged", []string{"v1.0.0"}, false, true, "1.1.0  v1.0.0", ""},
+		{"next version already tagged", []string{"v1.0.0", "v1.1.0"}, false, true, "", "next version 1.1.0 is already tagged as v1.1.0, use -t v1.1.0 or --use-existing"},
+		{"tag with build metadata", []string{"v1.0.0", "v1.1.0+build.1"}, false, true, "", "already tagged as v1.1.0+build.1"},
+		{"use existing tag", []string{"v1.0.0", "v1.1.0"}, true, true, "1.1.0 v1.1.0 v1.0.0", ""},
+		{"version not updated", []string{"v1.0.0", "v1.1.0"}, false, false, "1.1.0  v1.0.0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := mockGit{
+				lastTag: "v1.0.0",
+				logFn:   func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc1234"}}, nil },
+				tagsFn: func() ([]sv.GitTag, error) {
+					var tags []sv.GitTag
+					for _, name := range tt.tags {
+						tags = append(tags, sv.GitTag{Name: name})
+					}
+					return tags, nil
+				},
+			}
+			semverProcessor := mockSemVerProcessor{nextVersionFn: func(*semver.Version, []sv.GitCommitLog) (*semver.Version, bool) {
+				return semver.MustParse("1.1.0"), tt.updated
+			}}
+			formatter := mockOutputFormatter{formatReleaseNoteFn: func(rn sv.ReleaseNote) (string, error) {
+				return fmt.Sprintf("%s %s %s", rn.Version, rn.Tag, rn.PreviousTag), nil
+			}}
+			out, stdout := newTestPrinter()
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("t", "", "")
+			set.Bool("use-existing", tt.useExisting, "")
+
+			err := releaseNotesHandler(git, semverProcessor, mockReleaseNoteProcessor{}, formatter, sv.SystemClock{}, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("releaseNotesHandler() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("releaseNotesHandler() error = %v", err)
+			}
+			if got := strings.TrimSpace(stdout.String()); got != tt.wantOutput {
+				t.Errorf("releaseNotesHandler() output = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_releaseNotesOutputPath(t *testing.T) {
+	date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		template string
+		rn       sv.ReleaseNote
+		want     string
+		wantErr  bool
+	}{
+		{"version", "dist/release-notes-v{{.Version}}.md", sv.ReleaseNote{Version: semver.MustParse("1.2.0"), Date: date}, "dist/release-notes-v1.2.0.md", false},
+		{"tag and date", "notes/{{.Tag}}-{{.Date}}.json", sv.ReleaseNote{Tag: "v1.2.0", Date: date}, "notes/v1.2.0-2024-03-01.json", false},
+		{"static name", "notes.html", sv.ReleaseNote{}, "notes.html", false},
+		{"unknown field", "{{.Name}}.md", sv.ReleaseNote{}, "", true},
+		{"empty path", "{{.Tag}}", sv.ReleaseNote{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := releaseNotesOutputPath(tt.template, tt.rn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("releaseNotesOutputPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("releaseNotesOutputPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_releaseNotesHandler_Output(t *testing.T) {
+	dir := t.TempDir()
+	git := mockGit{
+		lastTag: "v1.0.0",
+		logFn:   func(sv.LogRange) ([]sv.GitCommitLog, error) { return []sv.GitCommitLog{{Hash: "abc1234"}}, nil },
+	}
+	semverProcessor := mockSemVerProcessor{nextVersionFn: func(*semver.Version, []sv.GitCommitLog) (*semver.Version, bool) {
+		return semver.MustParse("1.1.0"), false
+	}}
+	content := "## v1.1.0"
+	formatter := mockOutputFormatter{formatReleaseNoteFn: func(sv.ReleaseNote) (string, error) { return content, nil }}
+	want := filepath.Join(dir, "dist", "release-notes-v1.1.0.md")
+
+	run := func(force bool) (string, string, error) {
+		var stdout, stderr bytes.Buffer
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		set.String("t", "", "")
+		set.String("output", filepath.Join(dir, "dist", "release-notes-v{{.Version}}.md"), "")
+		set.Bool("force", force, "")
+		err := releaseNotesHandler(git, semverProcessor, mockReleaseNoteProcessor{}, formatter, sv.SystemClock{}, newPrinter(&stdout, &stderr))(cli.NewContext(cli.NewApp(), set, nil))
+		return stdout.String(), stderr.String(), err
+	}
+
+	stdout, stderr, err := run(false)
+	if err != nil {
+		t.Fatalf("releaseNotesHandler() error = %v", err)
+	}
+	if stdout != "" || stderr != want+"\n" {
+		t.Errorf("releaseNotesHandler() stdout = %q, stderr = %q, want empty stdout and %q", stdout, stderr, want)
+	}
+	if got, _ := os.ReadFile(want); string(got) != content+"\n" {
+		t.Errorf("releaseNotesHandler() file content = %q, want %q", got, content+"\n")
+	}
+
+	if _, _, err := run(false); err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Errorf("releaseNotesHandler() error = %v, want existing file error", err)
+	}
+	content = "## v1.1.0 updated"
+	if _, _, err := run(true); err != nil {
+		t.Fatalf("releaseNotesHandler() with force error = %v", err)
+	}
+	if got, _ := os.ReadFile(want); string(got) != content+"\n" {
+		t.Errorf("releaseNotesHandler() with force file content = %q, want %q", got, content+"\n")
+	}
+}
+
+func Test_configMigrateHandler(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "v1.env")
+	if err := os.WriteFile(from, []byte("SV4GIT_TAG_PATTERN=%d.%d.%d\nSV4GIT_BREAKING_CHANGE_PREFIXES=BREAKING\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, ".sv4git.yml")
+	if err := os.WriteFile(output, []byte("version: \"1.1\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(output string, force bool) (string, string, error) {
+		var stdout, stderr bytes.Buffer
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		set.String("from", from, "")
+		set.String("output", output, "")
+		set.Bool("force", force, "")
+		err := configMigrateHandler(newPrinter(&stdout, &stderr))(cli.NewContext(cli.NewApp(), set, nil))
+		return stdout.String(), stderr.String(), err
+	}
+
+	stdout, stderr, err := run("", false)
+	if err != nil {
+		t.Fatalf("configMigrateHandler() error = %v", err)
+	}
+	if !strings.Contains(stdout, "pattern: '%d.%d.%d'") || !strings.Contains(stderr, "WARN: v1 setting SV4GIT_BREAKING_CHANGE_PREFIXES no longer exists") {
+		t.Errorf("configMigrateHandler() stdout = %q, stderr = %q", stdout, stderr)
+	}
+
+	if _, _, err := run(output, false); err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Errorf("configMigrateHandler() error = %v, want existing file error", err)
+	}
+	if _, _, err := run(filepath.Join(dir, ".sv4git.json"), false); err == nil {
+		t.Error("configMigrateHandler() expected error for non yaml output, got nil")
+	}
+	if _, _, err := run(output, true); err != nil {
+		t.Fatalf("configMigrateHandler() with force error = %v", err)
+	}
+	if got, _ := os.ReadFile(output); string(got) != stdout {
+		t.Errorf("configMigrateHandler() file content = %q, want %q", got, stdout)
+	}
+}
+
+func Test_validateCommitMessageHandler_Output(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.CommitMessage.Scope.Values = []string{"api"}
+	cfg.Branches.DisableIssue = true
+	scopeErr := "message scope should one of [api]"
+	descriptionErr := "description [Update readme] should begins with lowercase letter"
+
+	tests := []struct {
+		name       string
+		maxErrors  int
+		compact    bool
+		wantErr    string
+		wantStderr string
+	}{
+		{"first violation", 1, false, "invalid commit message, error: " + scopeErr + " (1 more violation(s), use --max-errors 0 to show all)", ""},
+		{"all violations", 0, false, "invalid commit message:\n- " + scopeErr + "\n- " + descriptionErr, ""},
+		{"compact", 0, true, "invalid commit message, 2 violation(s)", "invalid commit message: " + scopeErr + "\ninvalid commit message: " + descriptionErr + "\n"},
+		{"compact limited", 1, true, "invalid commit message, 2 violation(s)", "invalid commit message: " + scopeErr + "\ninvalid commit message: 1 more violation(s), use --max-errors 0 to show all\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "COMMIT_EDITMSG"), []byte("docs(web): Update readme\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("path", dir, "")
+			set.String("file", "COMMIT_EDITMSG", "")
+			set.String("source", "message", "")
+			set.Int("max-errors", tt.maxErrors, "")
+			set.Bool("compact", tt.compact, "")
+
+			var stderr bytes.Buffer
+			err := validateCommitMessageHandler(cfg, mockGit{}, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), "", newPrinter(io.Discard, &stderr))(cli.NewContext(cli.NewApp(), set, nil))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("validateCommitMessageHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if stderr.String() != tt.wantStderr {
+				t.Errorf("validateCommitMessageHandler() stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func Test_validateCommitMessageHandler_IssueConflict(t *testing.T) {
+	tests := []struct {
+		onConflict  string
+		wantErr     bool
+		wantMessage string
+	}{
+		{"", false, "feat: add something\n\njira: JIRA-456\n"},
+		{sv.IssueOnConflictPreferBranch, false, "feat: add something\n\njira: JIRA-123\n"},
+		{sv.IssueOnConflictError, true, "feat: add something\n\njira: JIRA-456\n"},
+	}
+	for _, tt := range tests {
+		t.Run(str(tt.onConflict, "default"), func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.CommitMessage.Issue.OnConflict = tt.onConflict
+			dir := t.TempDir()
+			file := filepath.Join(dir, "COMMIT_EDITMSG")
+			if err := os.WriteFile(file, []byte("feat: add something\n\njira: JIRA-456\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("path", dir, "")
+			set.String("file", "COMMIT_EDITMSG", "")
+			set.String("source", "message", "")
+
+			var stderr bytes.Buffer
+			err := validateCommitMessageHandler(cfg, mockGit{branch: "feature/JIRA-123"}, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), "", newPrinter(io.Discard, &stderr))(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateCommitMessageHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !strings.Contains(stderr.String(), `message footer "jira: JIRA-456" references another issue than branch issue JIRA-123`) {
+				t.Errorf("validateCommitMessageHandler() stderr = %q, want conflict warning", stderr.String())
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.wantMessage {
+				t.Errorf("validateCommitMessageHandler() message = %q, want %q", content, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_validateCommitMessageHandler_EditHint(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.CommitMessage.Scope.Values = []string{"api", "web"}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "COMMIT_EDITMSG")
+
+	run := func(message string) (string, error) {
+		t.Helper()
+		if message != "" {
+			if err := os.WriteFile(file, []byte(message), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		set.String("path", dir, "")
+		set.String("file", "COMMIT_EDITMSG", "")
+		set.String("source", "message", "")
+		set.Bool("edit-hint", true, "")
+		err := validateCommitMessageHandler(cfg, mockGit{branch: "feature/ABC-123"}, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), "", newPrinter(io.Discard, io.Discard))(cli.NewContext(cli.NewApp(), set, nil))
+		content, rerr := os.ReadFile(file)
+		if rerr != nil {
+			t.Fatal(rerr)
+		}
+		return string(content), err
+	}
+
+	content, err := run("update readme\n")
+	if err == nil {
+		t.Fatal("validateCommitMessageHandler() expected error, got nil")
+	}
+	for _, want := range []string{"update readme\n\n" + editHintStart, "#   - ", "# allowed scopes: api, web\n", "#   feat(api): describe the change in lowercase\n", editHintEnd + "\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("commit message file = %q, want it to contain %q", content, want)
+		}
+	}
+
+	// validated again without changes, the help is replaced and not duplicated.
+	if content, _ = run(""); strings.Count(content, editHintStart) != 1 {
+		t.Errorf("commit message file = %q, want a single help block", content)
+	}
+
+	fixed := strings.Replace(content, "update readme", "docs(web): update readme", 1)
+	content, err = run(fixed)
+	if err != nil {
+		t.Fatalf("validateCommitMessageHandler() error = %v", err)
+	}
+	if want := "docs(web): update readme\n\njira: ABC-123\n"; content != want {
+		t.Errorf("commit message file = %q, want %q", content, want)
+	}
+}
+
+func Test_commitDate(t *testing.T) {
+	authorDate := time.Date(2024, 3, 31, 22, 30, 0, 0, time.FixedZone("-0300", -3*60*60))
+	tests := []struct {
+		name    string
+		commit  sv.GitCommitLog
+		want    time.Time
+		wantErr bool
+	}{
+		{"author date", sv.GitCommitLog{Date: "2024-03-31", Timestamp: 1711935000, AuthorDate: authorDate}, authorDate, false},
+		{"timestamp", sv.GitCommitLog{Date: "2024-03-31", Timestamp: 1711935000}, time.Unix(1711935000, 0), false},
+		{"date only", sv.GitCommitLog{Date: "2024-03-31"}, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), false},
+		{"invalid date", sv.GitCommitLog{Date: "31/03/2024"}, time.Time{}, true},
+		{"without date", sv.GitCommitLog{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := commitDate(tt.commit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("commitDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("commitDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_latestCommitDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []sv.GitCommitLog
+		want    time.Time
+		wantErr bool
+	}{
+		{"newest first", []sv.GitCommitLog{{Date: "2024-03-31"}, {Date: "2024-03-01"}}, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), false},
+		{"first entry older than a later one", []sv.GitCommitLog{{Date: "2024-03-01"}, {Date: "2024-03-31"}, {Date: "2024-02-01"}}, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), false},
+		{"invalid date", []sv.GitCommitLog{{Date: "2024-03-01"}, {Hash: "abc", Date: "invalid"}}, time.Time{}, true},
+		{"without commits", nil, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := latestCommitDate(tt.commits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("latestCommitDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("latestCommitDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getCommitIssues(t *testing.T) {
+	cfg := Config{CommitMessage: sv.CommitMessageConfig{Issue: sv.CommitMessageIssueConfig{Trackers: []sv.CommitMessageIssueTrackerConfig{
+		{Key: "jira", Regex: "PROJ-[0-9]+", URLTemplate: "https://jira.example.com/browse/{id}"},
+		{Key: "refs", Regex: "#[0-9]+"},
+	}}}}
+	tests := []struct {
+		name          string
+		branch        string
+		disableIssues bool
+		want          []sv.IssueReferences
+	}{
+		{"issues on branch", "feature/PROJ-12-PROJ-13", false, []sv.IssueReferences{{Tracker: "jira", Issues: []sv.IssueLink{
+			{ID: "PROJ-12", URL: "https://jira.example.com/browse/PROJ-12"},
+			{ID: "PROJ-13", URL: "https://jira.example.com/browse/PROJ-13"},
+		}}}},
+		{"without issues", "main", false, nil},
+		{"branch issues disabled", "feature/PROJ-12", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Branches.DisableIssue = tt.disableIssues
+			got, err := getCommitIssues(cfg, tt.branch, true, newPrinter(io.Discard, io.Discard))
+			if err != nil {
+				t.Fatalf("getCommitIssues() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getCommitIssues() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tagsHandler(t *testing.T) {
+	date := func(month time.Month) time.Time { return time.Date(2024, month, 1, 10, 0, 0, 0, time.UTC) }
+	git := mockGit{
+		tagsFn: func() ([]sv.GitTag, error) {
+			return []sv.GitTag{{Name: "v1.2.0", Date: date(2)}, {Name: "v1.10.0", Date: date(3)}, {Name: "v1.9.0", Date: date(4)}, {Name: "v2.0.0-rc.1", Date: date(5)}, {Name: "latest", Date: date(5)}}, nil
+		},
+		tagsAllFn: func() ([]sv.GitTag, error) {
+			return []sv.GitTag{{Name: "api/v1.1.0", Date: date(2)}, {Name: "api/v1.0.0", Date: date(3)}, {Name: "web/v3.0.0", Date: date(4)}}, nil
+		},
+	}
+	mnrp := mockMonorepoProcessor{findComponentsFn: func(repoRoot string, cfg sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+		return []sv.MonorepoComponent{{Name: "api"}, {Name: "web"}}, nil
+	}}
+
+	tests := []struct {
+		name      string
+		filter    string
+		limit     int
+		component string
+		want      []string
+		wantErr   bool
+	}{
+		{"sorted by version", "", 0, "", []string{"v2.0.0-rc.1", "v1.10.0", "v1.9.0", "v1.2.0"}, false},
+		{"filter", "v1.*", 0, "", []string{"v1.10.0", "v1.9.0", "v1.2.0"}, false},
+		{"filter and limit", "v1.*", 2, "", []string{"v1.10.0", "v1.9.0"}, false},
+		{"filter without match", "v3.*", 0, "", []string{}, false},
+		{"component", "", 0, "api", []string{"api/v1.1.0", "api/v1.0.0"}, false},
+		{"component not found", "", 0, "db", nil, true},
+		{"invalid filter", "v[1", 0, "", nil, true},
+		{"invalid limit", "", -1, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("format", "json", "")
+			set.String("filter", tt.filter, "")
+			set.Int("limit", tt.limit, "")
+			set.String("component", tt.component, "")
+
+			out, stdout := newTestPrinter()
+			err := tagsHandler(git, mnrp, Config{}, "", out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tagsHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var result []releaseTag
+			if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+				t.Fatalf("tagsHandler() invalid json %q: %v", stdout, err)
+			}
+			got := []string{}
+			for _, tag := range result {
+				got = append(got, tag.Tag)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tagsHandler() tags = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tagsHandler_Table(t *testing.T) {
+	git := mockGit{tagsFn: func() ([]sv.GitTag, error) {
+		return []sv.GitTag{{Name: "v1.9.0", Date: time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)}, {Name: "v1.10.0", Date: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}}, nil
+	}}
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String("format", "table", "")
+
+	out, stdout := newTestPrinter()
+	if err := tagsHandler(git, mockMonorepoProcessor{}, Config{}, "", out)(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+		t.Fatalf("tagsHandler() unexpected error: %v", err)
+	}
+	want := "TAG      VERSION  DATE        COMMIT\n" +
+		"v1.10.0  1.10.0   2024-05-01  abc1234\n" +
+		"v1.9.0   1.9.0    2024-04-01  abc1234\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("tagsHandler() stdout = %q, want %q", got, want)
+	}
+}
+
+func Test_commitMessageFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "COMMIT_EDITMSG"), "feat: add login")
+	gitDir := filepath.Join(t.TempDir(), ".git", "worktrees", "wt")
+	writeFile(t, filepath.Join(gitDir, "COMMIT_EDITMSG"), "feat: add login")
+
+	tests := []struct {
+		name    string
+		path    string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{"relative file", dir, ".git/COMMIT_EDITMSG", filepath.Join(dir, ".git", "COMMIT_EDITMSG"), false},
+		{"absolute file of worktree", dir, filepath.Join(gitDir, "COMMIT_EDITMSG"), filepath.Join(gitDir, "COMMIT_EDITMSG"), false},
+		{"missing file", dir, "COMMIT_EDITMSG", "", true},
+		{"directory", dir, ".git", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := commitMessageFile(tt.path, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("commitMessageFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("commitMessageFile() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateCommitMessageHandler_EmptyFile(t *testing.T) {
+	cfg := defaultConfig()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "COMMIT_EDITMSG"), "\n")
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String("path", dir, "")
+	set.String("file", "COMMIT_EDITMSG", "")
+	set.String("source", "message", "")
+
+	err := validateCommitMessageHandler(cfg, mockGit{branch: "feature/x"}, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), "", newPrinter(io.Discard, io.Discard))(cli.NewContext(cli.NewApp(), set, nil))
+	if err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Errorf("validateCommitMessageHandler() error = %v, want empty file error", err)
+	}
+}
+
+func Test_getCommitBreakingChange(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          sv.CommitMessageBreakingConfig
+		noBreaking   bool
+		input        string
+		wantBreaking bool
+		wantMessage  string
+		wantErr      bool
+	}{
+		{"input", sv.CommitMessageBreakingConfig{}, false, "drops v1", true, "drops v1", false},
+		{"input with both style", sv.CommitMessageBreakingConfig{Style: sv.BreakingStyleBoth}, false, "drops v1", true, "drops v1", false},
+		{"input with exclamation style", sv.CommitMessageBreakingConfig{Style: sv.BreakingStyleExclamation}, false, "drops v1", false, "", true},
+		{"no breaking flag", sv.CommitMessageBreakingConfig{}, true, "drops v1", false, "", false},
+		{"prompt never", sv.CommitMessageBreakingConfig{Prompt: sv.BreakingPromptNever}, false, "", false, "", false},
+		{"prompt never with input", sv.CommitMessageBreakingConfig{Prompt: sv.BreakingPromptNever}, false, "drops v1", true, "drops v1", false},
+		{"type not in prompt types", sv.CommitMessageBreakingConfig{Prompt: sv.BreakingPromptWhenTypeIn, Types: []string{"fix"}}, false, "", false, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaking, message, err := getCommitBreakingChange(tt.cfg, "feat", tt.noBreaking, tt.input, newPrinter(io.Discard, io.Discard))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getCommitBreakingChange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if breaking != tt.wantBreaking || message != tt.wantMessage {
+				t.Errorf("getCommitBreakingChange() = %v, %q, want %v, %q", breaking, message, tt.wantBreaking, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_commitHandler_BreakingStyle(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"", "feat(api): drop v1\n\nBREAKING CHANGE: clients must use v2\n"},
+		{sv.BreakingStyleFooter, "feat(api): drop v1\n\nBREAKING CHANGE: clients must use v2\n"},
+		{sv.BreakingStyleBoth, "feat(api)!: drop v1\n\nBREAKING CHANGE: clients must use v2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			cfg := Config{CommitMessage: sv.CommitMessageConfig{Types: []string{"feat"}, Breaking: sv.CommitMessageBreakingConfig{Style: tt.style}}}
+			out, stdout := newTestPrinter()
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			for _, name := range []string{"no-body", "no-issue", "dry-run"} {
+				set.Bool(name, true, "")
+			}
+			set.String("type", "feat", "")
+			set.String("scope", "api", "")
+			set.String("description", "drop v1", "")
+			set.String("breaking-change", "clients must use v2", "")
+
+			err := commitHandler(cfg, mockGit{}, sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), "", out)(cli.NewContext(cli.NewApp(), set, nil))
+			if err != nil {
+				t.Fatalf("commitHandler() error = %v", err)
+			}
+			if stdout.String() != tt.want {
+				t.Errorf("commitHandler() stdout = %q, want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
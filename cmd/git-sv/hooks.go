@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// hook stages, keys of hooks config.
+const (
+	hookPreBump  = "pre-bump"
+	hookPostBump = "post-bump"
+	hookPreTag   = "pre-tag"
+	hookPostTag  = "post-tag"
+)
+
+// hookEnv values exposed to hooks as SV_VERSION, SV_TAG and SV_COMPONENT environment variables.
+type hookEnv struct {
+	Version   string
+	Tag       string
+	Component string // empty outside monorepo commands
+}
+
+func (e hookEnv) environ() []string {
+	return []string{"SV_VERSION=" + e.Version, "SV_TAG=" + e.Tag, "SV_COMPONENT=" + e.Component}
+}
+
+// hooks runs commands of hooks config on the repository root, disabled by --no-hooks.
+type hooks struct {
+	cfg      sv.HooksConfig
+	dir      string
+	disabled bool
+	out      *printer
+	onRun    func() // called after commands of a stage ran, they may have changed the repository, e.g. committed files
+}
+
+func newHooks(c *cli.Context, cfg sv.HooksConfig, dir string, out *printer) hooks {
+	return hooks{cfg: cfg, dir: dir, disabled: c.Bool("no-hooks"), out: out}
+}
+
+func (h hooks) commands(stage string) []string {
+	switch stage {
+	case hookPreBump:
+		return h.cfg.PreBump
+	case hookPostBump:
+		return h.cfg.PostBump
+	case hookPreTag:
+		return h.cfg.PreTag
+	case hookPostTag:
+		return h.cfg.PostTag
+	}
+	return nil
+}
+
+// run executes the commands of stage in order and stops on the first failure. Command output, stdout and stderr,
+// is streamed to stderr with the stage and command index as prefix, e.g. "[post-tag #2] ", stdout is kept for the command result.
+func (h hooks) run(stage string, env hookEnv) error {
+	commands := h.commands(stage)
+	if len(commands) == 0 {
+		return nil
+	}
+	if h.disabled {
+		h.out.warnf("%s hooks skipped by --no-hooks", stage)
+		return nil
+	}
+	for i, command := range commands {
+		h.out.statusf("running %s hook: %s", stage, command)
+		w := &prefixWriter{w: h.out.stderr, prefix: fmt.Sprintf("[%s #%d] ", stage, i+1)}
+		cmd := shellCommand(command)
+		cmd.Dir = h.dir
+		cmd.Env = append(os.Environ(), env.environ()...)
+		cmd.Stdout, cmd.Stderr = w, w
+		err := cmd.Run()
+		w.flush()
+		if h.onRun != nil {
+			h.onRun()
+		}
+		if err != nil {
+			return fmt.Errorf("%s hook %q failed, message: %v", stage, command, err)
+		}
+	}
+	return nil
+}
+
+// postHooksError reports post hooks failures after the command finished, tags and versioning files are kept.
+func postHooksError(failures []string) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("post hooks failed, tags and versioning files were kept:\n  %s", strings.Join(failures, "\n  "))
+}
+
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command) //nolint:gosec
+	}
+	return exec.Command("sh", "-c", command) //nolint:gosec
+}
+
+// prefixWriter writes every line with prefix, incomplete lines are kept until the next write or flush.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf[:i]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+func (p *prefixWriter) flush() {
+	if len(p.buf) > 0 {
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf)
+		p.buf = nil
+	}
+}
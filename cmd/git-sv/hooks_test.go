@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+func Test_hooks_run(t *testing.T) {
+	cfg := sv.HooksConfig{
+		PreTag:  []string{`echo "$SV_VERSION $SV_TAG $SV_COMPONENT"; echo warning >&2`, "printf 'no newline'"},
+		PostTag: []string{"exit 3", "echo not executed"},
+	}
+	tests := []struct {
+		name       string
+		stage      string
+		noHooks    bool
+		wantStderr string
+		wantErr    string
+	}{
+		{"environment and prefix", hookPreTag, false, "running pre-tag hook: echo \"$SV_VERSION $SV_TAG $SV_COMPONENT\"; echo warning >&2\n[pre-tag #1] 1.2.0 api/v1.2.0 api\n[pre-tag #1] warning\nrunning pre-tag hook: printf 'no newline'\n[pre-tag #2] no newline\n", ""},
+		{"stops on failure", hookPostTag, false, "running post-tag hook: exit 3\n", `post-tag hook "exit 3" failed, message: exit status 3`},
+		{"stage without commands", hookPreBump, false, "", ""},
+		{"no hooks", hookPreTag, true, "WARN: pre-tag hooks skipped by --no-hooks\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("no-hooks", tt.noHooks, "")
+			var stdout, stderr bytes.Buffer
+			out := newPrinter(&stdout, &stderr)
+
+			err := newHooks(cli.NewContext(cli.NewApp(), set, nil), cfg, t.TempDir(), out).run(tt.stage, hookEnv{Version: "1.2.0", Tag: "api/v1.2.0", Component: "api"})
+			if (err != nil) != (tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("hooks.run() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := stderr.String(); got != tt.wantStderr {
+				t.Errorf("hooks.run() stderr = %q, want %q", got, tt.wantStderr)
+			}
+			if stdout.Len() > 0 {
+				t.Errorf("hooks.run() stdout = %q, want empty", stdout.String())
+			}
+		})
+	}
+}
+
+func Test_postHooksError(t *testing.T) {
+	if err := postHooksError(nil); err != nil {
+		t.Errorf("postHooksError() = %v, want nil", err)
+	}
+	err := postHooksError([]string{"api: post-tag hook failed", "web: post-tag hook failed"})
+	if err == nil || !strings.HasSuffix(err.Error(), "were kept:\n  api: post-tag hook failed\n  web: post-tag hook failed") {
+		t.Errorf("postHooksError() = %v, want failures listed", err)
+	}
+}
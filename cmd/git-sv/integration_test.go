@@ -0,0 +1,638 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// setupIntegrationRepo creates a temporary git repository with a bare origin and a
+// working clone, returning a function to run git commands inside it and its path.
+func setupIntegrationRepo(t *testing.T) (func(args ...string), string) {
+	t.Helper()
+
+	originDir := t.TempDir()
+	if err := exec.Command("git", "init", "--bare", originDir).Run(); err != nil {
+		t.Fatalf("git init --bare: %v", err)
+	}
+
+	workDir := t.TempDir()
+	if err := exec.Command("git", "clone", originDir, workDir).Run(); err != nil {
+		t.Fatalf("git clone: %v", err)
+	}
+	workDir, err := filepath.EvalSymlinks(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = workDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	gitCmd("config", "user.email", "test@test.com")
+	gitCmd("config", "user.name", "Test User")
+	gitCmd("config", "commit.gpgsign", "false")
+	gitCmd("config", "tag.gpgsign", "false")
+
+	writeFile(t, filepath.Join(workDir, "README.md"), "test")
+	gitCmd("add", "README.md")
+	gitCmd("commit", "-m", "initial commit")
+	gitCmd("push", "-u", "origin", "HEAD")
+
+	return gitCmd, workDir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// chdir changes the process working directory and restores it on test cleanup.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(orig) })
+}
+
+// newIntegrationGit creates a sv.GitImpl for repoPath using default configuration.
+func newIntegrationGit(cfg Config, repoPath string) *sv.GitImpl {
+	git := sv.NewGit(sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg.Tag, cfg.Log)
+	git.SetDir(repoPath)
+	return git
+}
+
+func Test_monorepoNextVersionHandler_FromNestedDirectory(t *testing.T) {
+	gitCmd, repoPath := setupIntegrationRepo(t)
+
+	writeFile(t, filepath.Join(repoPath, "services", "alpha", "version.yml"), "version: 1.0.0\n")
+	writeFile(t, filepath.Join(repoPath, "services", "beta", "version.yml"), "version: 2.0.0\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "chore: add components")
+	writeFile(t, filepath.Join(repoPath, "services", "alpha", "main.go"), "package main\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "feat: alpha feature")
+
+	cfg := defaultConfig()
+	cfg.Monorepo = sv.MonorepoConfig{VersioningFile: "services/*/version.yml", Path: "version"}
+	git := newIntegrationGit(cfg, repoPath)
+	out, stdout := newTestPrinter()
+	handler := monorepoNextVersionHandler(git, sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage), sv.NewMonorepoProcessor(), cfg, repoPath, out)
+
+	chdir(t, repoPath)
+	if err := handler(newCLICtx()); err != nil {
+		t.Fatalf("monorepoNextVersionHandler() from root unexpected error: %v", err)
+	}
+	fromRoot := stdout.String()
+
+	stdout.Reset()
+	chdir(t, filepath.Join(repoPath, "services", "beta"))
+	if err := handler(newCLICtx()); err != nil {
+		t.Fatalf("monorepoNextVersionHandler() from nested directory unexpected error: %v", err)
+	}
+	fromNested := stdout.String()
+
+	if want := "services/alpha: 1.1.0\nservices/beta: 2.0.1\n"; fromRoot != want {
+		t.Errorf("monorepoNextVersionHandler() output from root = %q, want %q", fromRoot, want)
+	}
+	if fromNested != fromRoot {
+		t.Errorf("monorepoNextVersionHandler() output from nested directory = %q, want %q", fromNested, fromRoot)
+	}
+}
+
+func Test_getRepoPath_FromNestedDirectory(t *testing.T) {
+	_, repoPath := setupIntegrationRepo(t)
+	nested := filepath.Join(repoPath, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := getRepoPath(nested)
+	if err != nil {
+		t.Fatalf("getRepoPath() unexpected error: %v", err)
+	}
+	if got != repoPath {
+		t.Errorf("getRepoPath() = %q, want %q", got, repoPath)
+	}
+}
+
+func Test_validatePushHandler_BareRepository(t *testing.T) {
+	gitCmd, repoPath := setupIntegrationRepo(t)
+	remoteURL, err := exec.Command("git", "-C", repoPath, "remote", "get-url", "origin").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin, err := getRepoPath(strings.TrimSpace(string(remoteURL)))
+	if err != nil {
+		t.Fatalf("getRepoPath() on bare repository unexpected error: %v", err)
+	}
+	base := shortHash(t, repoPath, "HEAD")
+	gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+	gitCmd("commit", "--allow-empty", "-m", "Add logout")
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+	invalid, head := shortHash(t, repoPath, "HEAD~1"), shortHash(t, repoPath, "HEAD")
+	// on pre-receive, pushed objects exist on the server and refs are not updated yet.
+	gitCmd("push", "origin", "HEAD:refs/quarantine/push")
+	if out, err := exec.Command("git", "-C", origin, "update-ref", "-d", "refs/quarantine/push").CombinedOutput(); err != nil {
+		t.Fatalf("git update-ref: %v\n%s", err, out)
+	}
+
+	zero := strings.Repeat("0", 40)
+	tests := []struct {
+		name       string
+		stdin      string
+		wantStderr string
+		wantErr    string
+	}{
+		{"branch update", base + " " + head + " refs/heads/main\n", "refs/heads/main:\n  " + invalid + " Add logout\n    - ", "invalid commit message on 1 commit(s) of 1 ref(s)"},
+		{"new branch", zero + " " + head + " refs/heads/feature\n", "refs/heads/feature:\n  " + invalid + " Add logout\n", "invalid commit message on 1 commit(s) of 1 ref(s)"},
+		{"already reachable commits", base + " " + base + " refs/heads/feature\n", "", ""},
+		{"skipped branch", base + " " + head + " refs/heads/wip\n", "WARN: refs/heads/wip: commit message validation skipped, branch in ignore list\n", ""},
+		{"deleted branch", head + " " + zero + " refs/heads/feature\n", "", ""},
+		{"invalid input", "refs/heads/main\n", "", "invalid ref update"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Branches.Skip = []string{"wip"}
+			messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+			app := cli.NewApp()
+			app.Reader = strings.NewReader(tt.stdin)
+			var stdout, stderr bytes.Buffer
+
+			err := validatePushHandler(cfg, newIntegrationGit(cfg, origin), messageProcessor, origin, newPrinter(&stdout, &stderr))(cli.NewContext(app, flag.NewFlagSet("test", flag.ContinueOnError), nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validatePushHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.HasPrefix(stderr.String(), tt.wantStderr) || (tt.wantStderr == "" && stderr.Len() > 0) {
+				t.Errorf("validatePushHandler() stderr = %q, want prefix %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+
+	warnTests := []struct {
+		name        string
+		mode        string
+		maxWarnings string
+		wantStderr  string
+		wantErr     string
+	}{
+		{"warn", sv.ValidationModeWarn, "", "accepted by validation mode warn", ""},
+		{"warn within max warnings", sv.ValidationModeWarn, "3", "accepted by validation mode warn", ""},
+		{"warn above max warnings", sv.ValidationModeWarn, "2", "accepted by validation mode warn", "3 violation(s) found, more than --max-warnings 2"},
+		{"off", sv.ValidationModeOff, "0", "", ""},
+	}
+	for _, tt := range warnTests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Branches.Skip = []string{"wip"}
+			cfg.CommitMessage.ValidationMode = tt.mode
+			messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+			app := cli.NewApp()
+			app.Reader = strings.NewReader(base + " " + head + " refs/heads/main\n")
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Int("max-warnings", 0, "")
+			if tt.maxWarnings != "" {
+				_ = set.Set("max-warnings", tt.maxWarnings)
+			}
+			var stdout, stderr bytes.Buffer
+
+			err := validatePushHandler(cfg, newIntegrationGit(cfg, origin), messageProcessor, origin, newPrinter(&stdout, &stderr))(cli.NewContext(app, set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validatePushHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) || (tt.wantStderr == "" && stderr.Len() > 0) {
+				t.Errorf("validatePushHandler() stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func Test_repoDirFromArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no flag", []string{"git-sv", "nv"}, ""},
+		{"short flag", []string{"git-sv", "-C", "repo", "nv"}, "repo"},
+		{"long flag", []string{"git-sv", "--repo-dir", "repo", "nv"}, "repo"},
+		{"long flag with equals", []string{"git-sv", "--verbose", "--repo-dir=repo", "nv"}, "repo"},
+		{"command flag ignored", []string{"git-sv", "commit", "-C", "repo"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repoDirFromArgs(tt.args); got != tt.want {
+				t.Errorf("repoDirFromArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_monorepoNextVersionHandler_CachedLog(t *testing.T) {
+	gitCmd, repoPath := setupIntegrationRepo(t)
+
+	const componentsCount = 20
+	for i := 0; i < componentsCount; i++ {
+		writeFile(t, filepath.Join(repoPath, "services", fmt.Sprintf("svc%02d", i), "version.yml"), "version: 1.0.0\n")
+	}
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "chore: add components")
+	for i := 0; i < componentsCount; i++ {
+		gitCmd("tag", "-a", fmt.Sprintf("services/svc%02d/v1.0.0", i), "-m", "v1.0.0")
+	}
+	for i := 0; i < componentsCount; i += 2 {
+		writeFile(t, filepath.Join(repoPath, "services", fmt.Sprintf("svc%02d", i), "main.go"), "package main\n")
+		gitCmd("add", ".")
+		gitCmd("commit", "-m", fmt.Sprintf("feat: svc%02d feature", i))
+	}
+
+	cfg := defaultConfig()
+	cfg.Monorepo = sv.MonorepoConfig{VersioningFile: "services/*/version.yml", Path: "version"}
+	run := func(cached bool) (string, int) {
+		var commands bytes.Buffer
+		impl := newIntegrationGit(cfg, repoPath)
+		impl.LogCommands(&commands)
+		var git sv.Git = impl
+		if cached {
+			git = sv.NewCachedLogGit(impl, cfg.Log)
+		}
+
+		out, stdout := newTestPrinter()
+		handler := monorepoNextVersionHandler(git, sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage), sv.NewMonorepoProcessor(), cfg, repoPath, out)
+		if err := handler(newCLICtx()); err != nil {
+			t.Fatalf("monorepoNextVersionHandler() unexpected error: %v", err)
+		}
+		return stdout.String(), strings.Count(commands.String(), "git log ")
+	}
+
+	want, logExecs := run(false)
+	got, cachedLogExecs := run(true)
+	if got != want {
+		t.Errorf("monorepoNextVersionHandler() cached output = %q, want %q", got, want)
+	}
+	if logExecs != componentsCount || cachedLogExecs != 1 {
+		t.Errorf("git log executions = %d without cache and %d with cache, want %d and 1", logExecs, cachedLogExecs, componentsCount)
+	}
+	if !strings.Contains(got, "services/svc00: 1.1.0\n") || !strings.Contains(got, "services/svc01: 1.0.0 (no change)\n") {
+		t.Errorf("monorepoNextVersionHandler() output = %q, want svc00 bumped and svc01 unchanged", got)
+	}
+}
+
+func Test_monorepoUpdateVersionHandler_WorkingTree(t *testing.T) {
+	tests := []struct {
+		name        string
+		dirtyFile   string
+		allowDirty  bool
+		wantErr     bool
+		wantWarning string
+		wantVersion string
+	}{
+		{"clean", "", false, false, "", "version: 1.1.0\n"},
+		{"dirty unrelated file", "README.md", false, true, "", "version: 1.0.0\n"},
+		{"dirty unrelated file allowed", "README.md", true, false, "", "version: 1.1.0\n"},
+		{"dirty versioning file", "services/alpha/version.yml", false, true, "services/alpha: versioning file services/alpha/version.yml has uncommitted changes", "version: 1.0.0\n"},
+		{"dirty versioning file allowed", "services/alpha/version.yml", true, false, "services/alpha: versioning file services/alpha/version.yml has uncommitted changes", "version: 1.1.0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitCmd, repoPath := setupIntegrationRepo(t)
+			versionFile := filepath.Join(repoPath, "services", "alpha", "version.yml")
+			writeFile(t, versionFile, "version: 1.0.0\n")
+			gitCmd("add", ".")
+			gitCmd("commit", "-m", "chore: add component")
+			gitCmd("tag", "-a", "services/alpha/v1.0.0", "-m", "v1.0.0")
+			writeFile(t, filepath.Join(repoPath, "services", "alpha", "main.go"), "package main\n")
+			gitCmd("add", ".")
+			gitCmd("commit", "-m", "feat: alpha feature")
+			writeFile(t, filepath.Join(repoPath, "untracked.txt"), "ignored")
+			if tt.dirtyFile != "" {
+				path := filepath.Join(repoPath, filepath.FromSlash(tt.dirtyFile))
+				content, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, path, string(content)+"# changed\n")
+			}
+
+			cfg := defaultConfig()
+			cfg.Monorepo = sv.MonorepoConfig{VersioningFile: "services/*/version.yml", Path: "version"}
+			git := newIntegrationGit(cfg, repoPath)
+			var stderr bytes.Buffer
+			out := newPrinter(io.Discard, &stderr)
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.Bool("allow-dirty", tt.allowDirty, "")
+
+			handler := monorepoUpdateVersionHandler(git, sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage), sv.NewMonorepoProcessor(), sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches), cfg, repoPath, out)
+			err := handler(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("monorepoUpdateVersionHandler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.dirtyFile) {
+				t.Errorf("monorepoUpdateVersionHandler() error = %v, want dirty path %s", err, tt.dirtyFile)
+			}
+			if warning := stderr.String(); !strings.Contains(warning, tt.wantWarning) || (tt.wantWarning == "" && strings.Contains(warning, "versioning file")) {
+				t.Errorf("monorepoUpdateVersionHandler() stderr = %q, want %q", warning, tt.wantWarning)
+			}
+			content, rerr := os.ReadFile(versionFile)
+			if rerr != nil {
+				t.Fatal(rerr)
+			}
+			if got := strings.TrimSuffix(string(content), "# changed\n"); got != tt.wantVersion {
+				t.Errorf("versioning file = %q, want %q", got, tt.wantVersion)
+			}
+		})
+	}
+}
+
+// pushFromOtherClone pushes a new commit to origin from another clone, leaving repoPath behind its upstream after fetch.
+func pushFromOtherClone(t *testing.T, repoPath string) {
+	t.Helper()
+	originURL, err := exec.Command("git", "-C", repoPath, "remote", "get-url", "origin").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherDir := t.TempDir()
+	for _, args := range [][]string{
+		{"clone", strings.TrimSpace(string(originURL)), otherDir},
+		{"-C", otherDir, "-c", "user.email=other@test.com", "-c", "user.name=Other", "commit", "--allow-empty", "-m", "fix: remote fix"},
+		{"-C", otherDir, "push", "origin", "HEAD"},
+		{"-C", repoPath, "fetch", "origin"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+func Test_tagHandler_HeadPosition(t *testing.T) {
+	tests := []struct {
+		name          string
+		behind        bool
+		detached      bool
+		allowBehind   bool
+		allowDetached bool
+		wantErr       string
+	}{
+		{"up to date", false, false, false, false, ""},
+		{"behind upstream", true, false, false, false, "HEAD is 1 commit(s) behind origin/"},
+		{"behind upstream allowed", true, false, true, false, ""},
+		{"detached", false, true, false, false, "HEAD is detached"},
+		{"detached allowed", false, true, false, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitCmd, repoPath := setupIntegrationRepo(t)
+			gitCmd("tag", "-a", "1.0.0", "-m", "1.0.0")
+			gitCmd("commit", "--allow-empty", "-m", "feat: local feature")
+			if tt.behind {
+				gitCmd("push", "origin", "HEAD")
+				pushFromOtherClone(t, repoPath)
+			}
+			if tt.detached {
+				gitCmd("checkout", "-q", "--detach", "HEAD")
+			}
+
+			cfg := defaultConfig()
+			git := newIntegrationGit(cfg, repoPath)
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("remote", "", "")
+			set.Bool("allow-behind", tt.allowBehind, "")
+			set.Bool("allow-detached", tt.allowDetached, "")
+			// do not push tags to origin.
+			if err := set.Set("remote", ""); err != nil {
+				t.Fatal(err)
+			}
+
+			out, stdout := newTestPrinter()
+			err := tagHandler(git, sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage), cfg, sv.ReleaseBranch{}, repoPath, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("tagHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			want := "1.1.0\n"
+			if tt.wantErr != "" {
+				want = ""
+			}
+			if got := stdout.String(); got != want {
+				t.Errorf("tagHandler() stdout = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func Test_tagHandler_Commit(t *testing.T) {
+	tests := []struct {
+		name    string
+		commit  string
+		wantTag string
+		wantErr string
+	}{
+		{"commit before HEAD", "HEAD~1", "1.0.1", ""},
+		{"HEAD", "HEAD", "1.1.0", ""},
+		{"unknown commit", "not-a-commit", "", "commit not-a-commit not found"},
+		{"commit before last tag", "HEAD~3", "", "is not a descendant of last tag 1.0.0"},
+		{"commit not reachable from HEAD", "side", "", "is not reachable from HEAD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitCmd, repoPath := setupIntegrationRepo(t)
+			gitCmd("commit", "--allow-empty", "-m", "chore: base")
+			gitCmd("tag", "-a", "1.0.0", "-m", "1.0.0")
+			gitCmd("branch", "side")
+			gitCmd("commit", "--allow-empty", "-m", "fix: release fix")
+			gitCmd("commit", "--allow-empty", "-m", "feat: reverted feature")
+			gitCmd("checkout", "-q", "side")
+			gitCmd("commit", "--allow-empty", "-m", "feat: side feature")
+			gitCmd("checkout", "-q", "-")
+
+			cfg := defaultConfig()
+			git := newIntegrationGit(cfg, repoPath)
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			set.String("remote", "", "")
+			set.String("commit", tt.commit, "")
+			// do not push tags to origin.
+			if err := set.Set("remote", ""); err != nil {
+				t.Fatal(err)
+			}
+
+			out, stdout := newTestPrinter()
+			err := tagHandler(git, sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage), cfg, sv.ReleaseBranch{}, repoPath, out)(cli.NewContext(cli.NewApp(), set, nil))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("tagHandler() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if got := stdout.String(); got != tt.wantTag+"\n" {
+				t.Errorf("tagHandler() stdout = %q, want %q", got, tt.wantTag+"\n")
+			}
+			tagged, err := exec.Command("git", "-C", repoPath, "rev-parse", tt.wantTag+"^{commit}").Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := exec.Command("git", "-C", repoPath, "rev-parse", tt.commit).Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(tagged) != string(want) {
+				t.Errorf("tag %s on commit %s, want %s", tt.wantTag, tagged, want)
+			}
+		})
+	}
+}
+
+func Test_monorepoInitComponentHandler(t *testing.T) {
+	_, repoPath := setupIntegrationRepo(t)
+	cfg := defaultConfig()
+	cfg.Monorepo = sv.MonorepoConfig{VersioningFile: "services/*/catalog-info.yaml", Path: "metadata.annotations[\"sv4git/version\"]", NamePath: "metadata.name"}
+	git := newIntegrationGit(cfg, repoPath)
+
+	run := func(args ...string) error {
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		set.String("path", "", "")
+		set.String("name", "", "")
+		set.String("version", "0.1.0", "")
+		set.Bool("tag", false, "")
+		set.String("remote", "", "")
+		if err := set.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		out, _ := newTestPrinter()
+		return monorepoInitComponentHandler(git, cfg, repoPath, out)(cli.NewContext(cli.NewApp(), set, nil))
+	}
+
+	if err := run("--path", "services/billing", "--name", "billing", "--version", "0.2.0", "--tag", "--remote", ""); err != nil {
+		t.Fatalf("monorepoInitComponentHandler() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(repoPath, "services", "billing", "catalog-info.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "metadata:\n    annotations:\n        sv4git/version: 0.2.0\n    name: billing\n"; string(content) != want {
+		t.Errorf("versioning file = %q, want %q", content, want)
+	}
+
+	components, _, err := sv.NewMonorepoProcessor().FindComponents(repoPath, cfg.Monorepo)
+	if err != nil || len(components) != 1 || components[0].Name != "billing" || components[0].CurrentVersion.String() != "0.2.0" {
+		t.Fatalf("FindComponents() = %v, %v, want billing 0.2.0", components, err)
+	}
+	if tag := git.LastComponentTag("billing"); tag != "billing/v0.2.0" {
+		t.Errorf("LastComponentTag() = %q, want billing/v0.2.0", tag)
+	}
+
+	for _, args := range [][]string{
+		{"--path", "services/billing", "--name", "billing"},
+		{"--path", "libs/billing", "--name", "billing"},
+		{"--path", "services/payments"},
+		{"--path", "services/payments", "--name", "payments", "--version", "abc"},
+	} {
+		if err := run(args...); err == nil {
+			t.Errorf("monorepoInitComponentHandler(%v) expected error, got nil", args)
+		}
+	}
+}
+
+func Test_checkHistoryHandler_FetchTagsFromOrigin(t *testing.T) {
+	gitCmd, repoPath := setupIntegrationRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "feat: new feature")
+	gitCmd("push", "origin", "HEAD")
+
+	originURL, err := exec.Command("git", "-C", repoPath, "remote", "get-url", "origin").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", strings.TrimSpace(string(originURL)), "tag", "v1.2.0", "HEAD").CombinedOutput(); err != nil {
+		t.Fatalf("git tag on origin: %v\n%s", err, out)
+	}
+
+	cfg := defaultConfig()
+	git := newIntegrationGit(cfg, repoPath)
+	for _, fetch := range []bool{false, true} {
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		set.Bool("fetch", fetch, "")
+		c := cli.NewContext(cli.NewApp(), set, nil)
+		out, stdout := newTestPrinter()
+		if err := checkHistoryHandler(git, cfg.Versioning, out)(c); err != nil {
+			t.Fatalf("checkHistoryHandler() unexpected error: %v", err)
+		}
+		if err := currentVersionHandler(git, Config{}, out)(c); err != nil {
+			t.Fatalf("currentVersionHandler() unexpected error: %v", err)
+		}
+		want := "0.0.0\n"
+		if fetch {
+			want = "1.2.0\n"
+		}
+		if stdout.String() != want {
+			t.Errorf("current-version with fetch %v = %q, want %q", fetch, stdout.String(), want)
+		}
+	}
+}
+
+func Test_validateCommitMessage_Worktree(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("checkout", "-b", "feature/base")
+
+	// hook records its working directory and arguments, git passes them to validate-commit-message.
+	hooksDir, argsFile := t.TempDir(), filepath.Join(t.TempDir(), "args")
+	writeFile(t, filepath.Join(hooksDir, "prepare-commit-msg"), fmt.Sprintf("#!/bin/sh\nprintf '%%s\\n%%s\\n' \"$(pwd)\" \"$1\" > %q\n", argsFile))
+	if err := os.Chmod(filepath.Join(hooksDir, "prepare-commit-msg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("config", "core.hooksPath", hooksDir)
+	hookArgs := func() (string, string) {
+		t.Helper()
+		content, err := os.ReadFile(argsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args := strings.Split(strings.TrimSpace(string(content)), "\n")
+		return args[0], args[1]
+	}
+
+	gitCmd("commit", "--allow-empty", "-m", "feat: valid message on main worktree")
+	mainPath, mainFile := hookArgs()
+
+	worktree := filepath.Join(t.TempDir(), "wt")
+	gitCmd("worktree", "add", "-b", "feature/wt", worktree)
+	cmd := exec.Command("git", "commit", "--allow-empty", "-m", "invalid message on linked worktree")
+	cmd.Dir = worktree
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit on worktree: %v\n%s", err, out)
+	}
+	wtPath, wtFile := hookArgs()
+	if !filepath.IsAbs(wtFile) {
+		t.Fatalf("hook file on worktree = %s, want absolute path inside common git dir", wtFile)
+	}
+
+	if _, stderr, err := runCLI(repoPath, "validate-commit-message", "--path", mainPath, "--file", mainFile, "--source", "message"); err != nil {
+		t.Errorf("Run(vcm) on main worktree unexpected error: %v, stderr: %s", err, stderr)
+	}
+	_, stderr, err := runCLI(worktree, "validate-commit-message", "--path", wtPath, "--file", wtFile, "--source", "message")
+	if err == nil || !strings.Contains(stderr, "subject [invalid message on linked worktree]") {
+		t.Errorf("Run(vcm) on linked worktree error = %v, stderr = %q, want invalid commit message", err, stderr)
+	}
+}
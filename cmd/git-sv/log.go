@@ -2,9 +2,98 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"io"
 )
 
-func warnf(format string, values ...interface{}) {
-	fmt.Fprintf(os.Stderr, "WARN: "+format+"\n", values...)
+// logLevel defines which informational lines are printed by printer.
+type logLevel int
+
+const (
+	levelQuiet logLevel = iota
+	levelInfo
+	levelDebug
+)
+
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+)
+
+// printer writes handlers output, command results go to stdout and warnings and errors to stderr.
+type printer struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	stdoutColor bool
+	stderrColor bool
+	level       logLevel
+	// promptsDisabled why interactive prompts and the editor are disabled, they are allowed when empty.
+	promptsDisabled string
+}
+
+func newPrinter(stdout, stderr io.Writer) *printer {
+	return &printer{stdout: stdout, stderr: stderr, level: levelInfo}
+}
+
+// printf prints command result without colors, used for versions and machine readable formats.
+func (p *printer) printf(format string, values ...interface{}) {
+	fmt.Fprintf(p.stdout, format, values...)
+}
+
+// println prints content as command result without colors.
+func (p *printer) println(content string) {
+	fmt.Fprintln(p.stdout, content)
+}
+
+// errln prints the command result on stderr when stdout is not used, e.g. the path of a written file, not suppressed by --quiet.
+func (p *printer) errln(content string) {
+	fmt.Fprintln(p.stderr, content)
+}
+
+// successf prints created tags and versions, green when colors are enabled.
+func (p *printer) successf(format string, values ...interface{}) {
+	fmt.Fprintln(p.stdout, colorize(p.stdoutColor, colorGreen, fmt.Sprintf(format, values...)))
+}
+
+// infof prints informational lines on stdout, suppressed by --quiet.
+func (p *printer) infof(format string, values ...interface{}) {
+	if p.level >= levelInfo {
+		fmt.Fprintf(p.stdout, format+"\n", values...)
+	}
+}
+
+// statusf prints informational lines on stderr when stdout is reserved to the command result, suppressed by --quiet.
+func (p *printer) statusf(format string, values ...interface{}) {
+	if p.level >= levelInfo {
+		fmt.Fprintf(p.stderr, format+"\n", values...)
+	}
+}
+
+// debugEnabled checks if debug lines are printed, used to skip expensive debug information.
+func (p *printer) debugEnabled() bool {
+	return p.level >= levelDebug
+}
+
+// debugf prints debug lines on stderr, enabled by --debug.
+func (p *printer) debugf(format string, values ...interface{}) {
+	if p.debugEnabled() {
+		fmt.Fprintf(p.stderr, "DEBUG: "+format+"\n", values...)
+	}
+}
+
+// warnf prints warnings on stderr, yellow when colors are enabled.
+func (p *printer) warnf(format string, values ...interface{}) {
+	fmt.Fprintln(p.stderr, colorize(p.stderrColor, colorYellow, fmt.Sprintf("WARN: "+format, values...)))
+}
+
+// errorf prints errors on stderr, red when colors are enabled.
+func (p *printer) errorf(format string, values ...interface{}) {
+	fmt.Fprintln(p.stderr, colorize(p.stderrColor, colorRed, fmt.Sprintf("ERROR: "+format, values...)))
+}
+
+func colorize(enabled bool, color, text string) string {
+	if !enabled {
+		return text
+	}
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
 }
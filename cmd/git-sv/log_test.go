@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+func Test_printer(t *testing.T) {
+	tests := []struct {
+		name       string
+		color      bool
+		level      logLevel
+		wantStdout string
+		wantStderr string
+	}{
+		{"plain", false, levelInfo, "1.0.0\ninfo line\nv1.0.0\n", "WARN: warning\nERROR: failure\n"},
+		{"quiet", false, levelQuiet, "1.0.0\nv1.0.0\n", "WARN: warning\nERROR: failure\n"},
+		{"debug", false, levelDebug, "1.0.0\ninfo line\nv1.0.0\n", "DEBUG: debug line\nWARN: warning\nERROR: failure\n"},
+		{"colors", true, levelInfo, "1.0.0\ninfo line\n\x1b[32mv1.0.0\x1b[0m\n", "\x1b[33mWARN: warning\x1b[0m\n\x1b[31mERROR: failure\x1b[0m\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			p := newPrinter(&stdout, &stderr)
+			p.stdoutColor, p.stderrColor, p.level = tt.color, tt.color, tt.level
+
+			p.printf("%s\n", "1.0.0")
+			p.infof("info %s", "line")
+			p.successf("v%s", "1.0.0")
+			p.debugf("debug %s", "line")
+			p.warnf("warning")
+			p.errorf("failure")
+
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("printer stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if stderr.String() != tt.wantStderr {
+				t.Errorf("printer stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func Test_printer_StatusOnStderr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	p := newPrinter(&stdout, &stderr)
+	p.statusf("written to %s", "CHANGELOG.md")
+	if stdout.Len() != 0 || stderr.String() != "written to CHANGELOG.md\n" {
+		t.Errorf("statusf() stdout = %q, stderr = %q, want only stderr", stdout.String(), stderr.String())
+	}
+
+	stderr.Reset()
+	p.level = levelQuiet
+	p.statusf("written to %s", "CHANGELOG.md")
+	if stderr.Len() != 0 {
+		t.Errorf("statusf() with quiet printed %q, want nothing", stderr.String())
+	}
+}
+
+func Test_printer_JSONWithoutColors(t *testing.T) {
+	comp := makeComponent(t, "alpha", "1.0.0")
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return []sv.MonorepoComponent{comp}, nil
+		},
+		nextVersionFn: func(component sv.MonorepoComponent, _ []sv.GitCommitLog, _ sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+	git := mockGit{logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil }}
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String("format", "json", "")
+	out, stdout := newTestPrinter()
+	out.stdoutColor = true
+	if err := monorepoNextVersionHandler(git, mockSemVerProcessor{}, mnrp, Config{}, filepath.Dir(comp.RootPath), out)(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+		t.Fatalf("monorepoNextVersionHandler() unexpected error: %v", err)
+	}
+	if strings.Contains(stdout.String(), "\x1b[") {
+		t.Errorf("monorepoNextVersionHandler() json output = %q, must not contain color codes", stdout.String())
+	}
+}
@@ -1,58 +1,307 @@
 package main
 
 import (
-	"embed"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
-	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/bvieira/sv4git/v2/sv"
 	"github.com/urfave/cli/v2"
 )
 
-// Version for git-sv.
-var Version = "source"
+// Build information for git-sv, injected with ldflags.
+var (
+	Version   = "source"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
 
 const (
-	configFilename     = "config.yml"
-	repoConfigFilename = ".sv4git.yml"
+	configFilename     = "config"
+	repoConfigFilename = ".sv4git"
 	configDir          = ".sv4git"
+
+	defaultComponentChangelogPath = "{{.ComponentDir}}/CHANGELOG.md"
 )
 
-var (
-	//go:embed resources/templates/*.tpl
-	defaultTemplatesFS embed.FS
+// exit codes, other errors exit with 1.
+const (
+	exitCodeTagExists = 3
 )
 
+// exitCodeError error that sets git-sv exit code.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e exitCodeError) Unwrap() error {
+	return e.err
+}
+
+func exitCode(err error) int {
+	var codeErr exitCodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+	return 1
+}
+
 func templateFS(filepath string) fs.FS {
 	if _, err := os.Stat(filepath); err != nil {
-		defaultTemplatesFS, _ := fs.Sub(defaultTemplatesFS, "resources/templates")
-		return defaultTemplatesFS
+		return sv.DefaultTemplatesFS()
 	}
 	return os.DirFS(filepath)
 }
 
 func main() {
-	log.SetFlags(0)
+	if err := Run(os.Args, os.Stdout, os.Stderr, ""); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// Run runs git-sv in process as the command line does, args[0] is the program name. Command results are written to stdout,
+// warnings and errors to stderr, errors are also returned. dir is the directory used to find the repository, current directory
+// if empty, a relative -C/--repo-dir flag is resolved from it. Other relative paths, e.g. --output, are resolved from the
+// current directory.
+func Run(args []string, stdout, stderr io.Writer, dir string) error {
+	out := newPrinter(stdout, stderr)
+	err := run(args, dir, out)
+	if err != nil {
+		out.errorf("%v", err)
+	}
+	return err
+}
 
-	repoPath, rerr := getRepoPath()
-	if rerr != nil {
-		log.Fatal("failed to discovery repository top level, error: ", rerr)
+func run(args []string, dir string, out *printer) error {
+	repoDir := repoDirFromArgs(args)
+	if dir != "" && !filepath.IsAbs(repoDir) {
+		repoDir = filepath.Join(dir, repoDir)
+	}
+	doctor := doctorRequested(args)
+	repoPath, rerr := getRepoPath(repoDir)
+	if rerr != nil && !completionRequested(args) && !doctor {
+		return fmt.Errorf("failed to discovery repository top level, error: %v", rerr)
 	}
 
-	cfg := loadCfg(repoPath)
+	cfg, cerr := loadCfg(repoPath, out)
+	if cerr == nil {
+		cerr = validateConfig(cfg)
+	}
+	if cerr != nil && !doctor {
+		return cerr
+	}
+	if cerr != nil {
+		cfg = defaultConfig() // doctor reports the error and runs the other checks with the default config
+	}
 	messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
-	git := sv.NewGit(messageProcessor, cfg.Tag)
+	git := sv.NewGit(messageProcessor, cfg.Tag, cfg.Log)
+	git.SetDir(repoPath)
+	git.SetCommandOutput(out.stderr)
+	git.SetMonotonic(cfg.Versioning.Monotonic)
 	semverProcessor := sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage)
+	clock := &releaseClock{}
+	semverProcessor.SetClock(clock)
+	var releaseBranch sv.ReleaseBranch
+	if cfg.Branches.ReleasePattern != "" {
+		branch, found, berr := sv.MatchReleaseBranch(cfg.Branches.ReleasePattern, git.Branch())
+		if berr != nil {
+			return fmt.Errorf("invalid branches.release-pattern config, error: %v", berr)
+		}
+		if found {
+			releaseBranch = branch
+			semverProcessor.SetReleaseBranch(branch)
+		}
+	}
 	releasenotesProcessor := sv.NewReleaseNoteProcessor(cfg.ReleaseNotes)
+	releasenotesProcessor.SetTypeSettings(cfg.CommitMessage.TypeSettings)
 	outputFormatter := sv.NewOutputFormatter(templateFS(filepath.Join(repoPath, configDir, "templates")))
+	location, lerr := cfg.ReleaseNotes.Location()
+	if lerr != nil {
+		return fmt.Errorf("invalid release notes config, error: %v", lerr)
+	}
+	if lerr := outputFormatter.SetLocale(cfg.ReleaseNotes.Locale, cfg.ReleaseNotes.Strings); lerr != nil {
+		return fmt.Errorf("invalid release notes config, error: %v", lerr)
+	}
+	outputFormatter.SetDateFormat(cfg.ReleaseNotes.DateLayout(), location)
+	outputFormatter.SetRawMarkdown(cfg.ReleaseNotes.RawMarkdown)
 	monorepoProcessor := sv.NewMonorepoProcessor()
+	tm := newTimings(time.Now)
+	monorepoGit := sv.NewCachedLogGit(timedGit{Git: git, timings: tm}, cfg.Log) // monorepo commands read the log of each component, load history only once
+
+	checkHistory := checkHistoryHandler(git, cfg.Versioning, out)
+	fetchFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "fetch", Usage: "fetch tags from remote before reading them, and complete history when repository is a shallow clone"}
+	}
+	firstParentFlag := func() cli.Flag {
+		return &cli.BoolFlag{
+			Name:  "first-parent",
+			Usage: "follow only the first parent of merge commits (default: log.first-parent config), commits from merged branches are ignored. Use with log.include-merges only-conventional or always so merge commits, e.g. pull request titles, are used instead",
+			Action: func(_ *cli.Context, enabled bool) error {
+				git.SetFirstParent(enabled)
+				return nil
+			},
+		}
+	}
+	styleFlag := func() cli.Flag {
+		return &cli.StringFlag{
+			Name:  "style",
+			Usage: "release notes `style`: default or checklist, checklist renders commits and breaking changes as task list items, e.g. - [ ] feat(api): add endpoint",
+			Action: func(_ *cli.Context, style string) error {
+				return outputFormatter.SetStyle(style)
+			},
+		}
+	}
+	remoteFlag := func() cli.Flag {
+		return &cli.StringFlag{Name: "remote", Usage: "`remote` used to push tags, use empty value to skip push (default: tag.remote config or current branch remote)"}
+	}
+	metadataFlag := func() cli.Flag {
+		return &cli.StringFlag{Name: "metadata", Usage: "build metadata `template` appended to the version, e.g. build.{env:BUILD_NUMBER}.sha.{hash}, placeholders: {hash}, {branch}, {env:NAME} (default: versioning.metadata-template config)"}
+	}
+	bumpFlag := func() cli.Flag {
+		return &cli.StringFlag{Name: "bump", Usage: "force version `increment`: major, minor or patch, commits are not used to calculate the version"}
+	}
+	setVersionFlag := func() cli.Flag {
+		return &cli.StringFlag{Name: "set-version", Usage: "force `version`, it must be greater than current version"}
+	}
+	allowDowngradeFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "allow-downgrade", Usage: "allow --set-version lower than or equal to current version, and on monorepo-tag and monorepo-bump a calculated version lower than the versioning file"}
+	}
+	allowCappedBumpFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "allow-capped-bump", Usage: "limit updates above monorepo.components max-bump config to max-bump with a warning instead of failing"}
+	}
+	explainFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "explain", Usage: "print the commits that decided the version update, with hash, subject and reason"}
+	}
+	componentsFlag := func() cli.Flag {
+		return &cli.StringSliceFlag{Name: "component", Aliases: []string{"c"}, Usage: "only process component `name`, can be used multiple times"}
+	}
+	pathFlag := func() cli.Flag {
+		return &cli.StringSliceFlag{Name: "path", Usage: "only include commits changing `pathspec`, relative to repository root, can be used multiple times"}
+	}
+	dateRangeFlags := func() []cli.Flag {
+		return []cli.Flag{
+			&cli.StringFlag{Name: "since", Usage: "only include versions released on or after `date` (YYYY-MM-DD)"},
+			&cli.StringFlag{Name: "until", Usage: "only include versions released on or before `date` (YYYY-MM-DD)"},
+			&cli.BoolFlag{Name: "utc", Usage: "use UTC for since and until dates instead of local timezone"},
+		}
+	}
+	versionRangeFlags := func() []cli.Flag {
+		return []cli.Flag{
+			&cli.StringFlag{Name: "from-version", Usage: "only include tags with version `version` or higher, sorted by version instead of date"},
+			&cli.StringFlag{Name: "to-version", Usage: "only include tags with version `version` or lower, sorted by version instead of date"},
+			&cli.BoolFlag{Name: "fuzzy", Usage: "use the nearest tag when --from-version or --to-version has no tag instead of failing"},
+		}
+	}
+	allowBehindFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "allow-behind", Usage: "tag even if HEAD is behind its upstream branch"}
+	}
+	allowDetachedFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "allow-detached", Usage: "tag a detached HEAD, e.g. a commit checked out by CI"}
+	}
+	includeMetadataFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "include-metadata", Usage: "keep build metadata of tags on version headings"}
+	}
+	allowDirtyFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "allow-dirty", Usage: "run even if tracked files have uncommitted changes"}
+	}
+	configFormatFlag := func() cli.Flag {
+		return &cli.StringFlag{Name: "format", Value: "yaml", Usage: "config output `format`: yaml, json or toml"}
+	}
+	noPagerFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "no-pager", Usage: "do not pipe output through GIT_PAGER or PAGER (default less -R) when stdout is a terminal"}
+	}
+	noHooksFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "no-hooks", Usage: "do not run hooks config commands, e.g. in emergencies when a hook is broken"}
+	}
+	warnOnlyFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "warn-only", Usage: "print violations as warnings and succeed, as commit-message.validation-mode warn"}
+	}
+	checkSpellingFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "check-spelling", Usage: "report common misspellings on subject and body, words of commit-message.spelling.dictionary are accepted"}
+	}
+	forceRetagFlag := func() cli.Flag {
+		return &cli.BoolFlag{Name: "force-retag", Usage: "move tags that already exist to HEAD, they are deleted and pushed again, asks for confirmation unless --yes"}
+	}
+	tagCommitFlag := func() cli.Flag {
+		return &cli.StringFlag{Name: "commit", Usage: "tag `hash` instead of HEAD, only commits since the last tag up to hash are used to calculate the version"}
+	}
+
+	tagCompletion := tagValues(git)
+	componentCompletion := componentValues(monorepoProcessor, cfg.Monorepo, repoPath)
+	bumpCompletion := staticValues([]string{"major", "minor", "patch"})
+	completers := flagCompleters{
+		"commit":                 {"type": staticValues(cfg.CommitMessage.Types), "t": staticValues(cfg.CommitMessage.Types), "scope": staticValues(cfg.CommitMessage.Scope.Values), "s": staticValues(cfg.CommitMessage.Scope.Values), "preset": staticValues(presetNames(cfg.CommitMessage.Presets))},
+		"commit-log":             {"t": tagCompletion, "tag": tagCompletion},
+		"release-notes":          {"t": tagCompletion, "tag": tagCompletion},
+		"monorepo-release-notes": {"component": componentCompletion, "c": componentCompletion, "t": tagCompletion, "tag": tagCompletion},
+		"monorepo-next-version":  {"component": componentCompletion, "c": componentCompletion, "bump": bumpCompletion},
+		"monorepo-tag":           {"component": componentCompletion, "c": componentCompletion, "bump": bumpCompletion},
+		"monorepo-bump":          {"component": componentCompletion, "c": componentCompletion, "bump": bumpCompletion},
+		"monorepo-promote":       {"component": componentCompletion, "c": componentCompletion},
+		"bump":                   {"bump": bumpCompletion},
+		"next-version":           {"bump": bumpCompletion},
+		"tag":                    {"bump": bumpCompletion},
+		"tags":                   {"component": componentCompletion, "c": componentCompletion},
+		"monorepo-impact":        {"component": componentCompletion, "c": componentCompletion},
+		"retag":                  {"t": tagCompletion, "tag": tagCompletion, "component": componentCompletion, "c": componentCompletion},
+	}
 
 	app := cli.NewApp()
+	app.Writer = out.stdout
+	app.ErrWriter = out.stderr
+	app.ExitErrHandler = func(*cli.Context, error) {} // errors are returned by Run, exit code is set by main
 	app.Name = "sv"
 	app.Version = Version
 	app.Usage = "semantic version for git"
+	app.EnableBashCompletion = true
+	app.Flags = []cli.Flag{
+		&cli.BoolFlag{Name: "verbose", Usage: "print every git command executed on stderr"},
+		&cli.BoolFlag{Name: "debug", Usage: "print on stderr every git command with its duration, discovered components with their baseline tag and the bump of each commit"},
+		&cli.StringFlag{Name: "repo-dir", Aliases: []string{"C"}, Usage: "run as if git-sv was started in `path` instead of the current working directory"},
+		&cli.BoolFlag{Name: "interactive", Usage: "allow prompts and editor even when running on CI or when stdin is not a terminal"},
+		&cli.BoolFlag{Name: "quiet", Aliases: []string{"q"}, Usage: "print only the command result, informational lines are suppressed"},
+		&cli.BoolFlag{Name: "no-color", Usage: "disable colored output, NO_COLOR environment variable is also supported"},
+		&cli.BoolFlag{Name: "strict-config", Usage: "fail on config warnings, e.g. types on versioning update lists missing on commit-message.types"},
+		&cli.StringFlag{Name: "release-date", Usage: "use `date` (YYYY-MM-DD) instead of the current date for unreleased versions, calendar versions and tags, for reproducible builds SOURCE_DATE_EPOCH environment variable is also supported"},
+	}
+	app.Before = func(c *cli.Context) error {
+		env := currentEnvironment(out.stdout)
+		out.promptsDisabled = env.nonInteractiveReason(c.Bool("interactive"))
+		colors := !c.Bool("no-color") && env.colorsAllowed()
+		out.stdoutColor = colors && isTerminalWriter(out.stdout)
+		out.stderrColor = colors && isTerminalWriter(out.stderr)
+		switch {
+		case c.Bool("debug"):
+			out.level = levelDebug
+			git.OnCommandExecuted(func(command string, duration time.Duration) {
+				out.debugf("git %s (%s)", command, duration.Round(time.Microsecond))
+			})
+		case c.Bool("quiet"):
+			out.level = levelQuiet
+		}
+		if c.Bool("verbose") {
+			git.LogCommands(out.stderr)
+		}
+		if err := checkConfigWarnings(cfg, c.Bool("strict-config"), out); err != nil {
+			return err
+		}
+		if err := clock.fix(c.String("release-date"), os.Getenv(sourceDateEpochEnv), location); err != nil {
+			return err
+		}
+		if clock.fixed != nil {
+			git.SetClock(clock) // otherwise git uses its own date, GIT_COMMITTER_DATE is respected
+		}
+		return nil
+	}
 	app.Commands = []*cli.Command{
 		{
 			Name:    "config",
@@ -62,12 +311,24 @@ func main() {
 				{
 					Name:   "default",
 					Usage:  "show default config",
-					Action: configDefaultHandler(),
+					Action: configDefaultHandler(out),
+					Flags:  []cli.Flag{configFormatFlag()},
 				},
 				{
 					Name:   "show",
 					Usage:  "show current config",
-					Action: configShowHandler(cfg),
+					Action: configShowHandler(cfg, out),
+					Flags:  []cli.Flag{configFormatFlag()},
+				},
+				{
+					Name:   "migrate",
+					Usage:  "convert a v1 config, SV4GIT_ settings one per line, to the current yaml format",
+					Action: configMigrateHandler(out),
+					Flags: []cli.Flag{
+						&cli.StringFlag{Name: "from", Usage: "v1 config `file`"},
+						&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write migrated config to `file` instead of stdout, e.g. .sv4git.yml"},
+						&cli.BoolFlag{Name: "force", Usage: "overwrite output file if it exists"},
+					},
 				},
 			},
 		},
@@ -75,25 +336,71 @@ func main() {
 			Name:    "current-version",
 			Aliases: []string{"cv"},
 			Usage:   "get last released version from git",
-			Action:  currentVersionHandler(git),
+			Before:  checkHistory,
+			Action:  currentVersionHandler(git, cfg, out),
+			Flags:   []cli.Flag{fetchFlag()},
 		},
 		{
 			Name:    "next-version",
 			Aliases: []string{"nv"},
 			Usage:   "generate the next version based on git commit messages",
-			Action:  nextVersionHandler(git, semverProcessor),
+			Before:  checkHistory,
+			Action:  nextVersionHandler(git, semverProcessor, cfg, out),
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "format", Value: "text", Usage: "output format, use: text or json"},
+				fetchFlag(),
+				metadataFlag(),
+				bumpFlag(),
+				setVersionFlag(),
+				allowDowngradeFlag(),
+				explainFlag(),
+				&cli.BoolFlag{Name: "show-unknown", Usage: "list commits not following conventional commits, with hash and subject"},
+				pathFlag(),
+			},
+		},
+		{
+			Name:   "bump",
+			Usage:  "write the next version to versioning.file without tagging, a version already bumped on the committed file is kept",
+			Before: checkHistory,
+			Action: bumpHandler(git, semverProcessor, messageProcessor, cfg, repoPath, out),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{Name: "commit", Usage: "stage and commit the versioning file using versioning.bump-commit-message config"},
+				&cli.BoolFlag{Name: "push", Usage: "push bump commit to the remote of the current branch, requires --commit"},
+				fetchFlag(),
+				allowDirtyFlag(),
+				bumpFlag(),
+				setVersionFlag(),
+				allowDowngradeFlag(),
+				noHooksFlag(),
+			},
+		},
+		{
+			Name:   "tags",
+			Usage:  "list release tags sorted by semantic version, newest first, with version, date and commit",
+			Before: checkHistory,
+			Action: withPager(out, tagsHandler(git, monorepoProcessor, cfg, repoPath, out)),
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "filter", Usage: "only list tags matching glob `pattern`, e.g. v1.*"},
+				&cli.IntFlag{Name: "limit", Usage: "list at most `n` tags, 0 lists every tag"},
+				&cli.StringFlag{Name: "format", Value: "table", Usage: "output format, use: table or json"},
+				&cli.StringFlag{Name: "component", Aliases: []string{"c"}, Usage: "list tags of monorepo component `name` instead of tags matching tag.filter config"},
+				fetchFlag(),
+			},
 		},
 		{
 			Name:        "commit-log",
 			Aliases:     []string{"cl"},
 			Usage:       "list all commit logs according to range as jsons",
 			Description: "The range filter is used based on git log filters, check https://git-scm.com/docs/git-log for more info. When flag range is \"tag\" and start is empty, last tag created will be used instead. When flag range is \"date\", if \"end\" is YYYY-MM-DD the range will be inclusive.",
-			Action:      commitLogHandler(git),
+			Action:      withPager(out, commitLogHandler(git, out)),
 			Flags: []cli.Flag{
 				&cli.StringFlag{Name: "t", Aliases: []string{"tag"}, Usage: "get commit log from a specific tag"},
 				&cli.StringFlag{Name: "r", Aliases: []string{"range"}, Usage: "type of range of commits, use: tag, date or hash", Value: string(sv.TagRange)},
 				&cli.StringFlag{Name: "s", Aliases: []string{"start"}, Usage: "start range of git log revision range, if date, the value is used on since flag instead"},
 				&cli.StringFlag{Name: "e", Aliases: []string{"end"}, Usage: "end range of git log revision range, if date, the value is used on until flag instead"},
+				pathFlag(),
+				firstParentFlag(),
+				noPagerFlag(),
 			},
 		},
 		{
@@ -101,118 +408,373 @@ func main() {
 			Aliases:     []string{"cn"},
 			Usage:       "generate a commit notes according to range",
 			Description: "The range filter is used based on git log filters, check https://git-scm.com/docs/git-log for more info. When flag range is \"tag\" and start is empty, last tag created will be used instead. When flag range is \"date\", if \"end\" is YYYY-MM-DD the range will be inclusive.",
-			Action:      commitNotesHandler(git, releasenotesProcessor, outputFormatter),
+			Action:      withPager(out, commitNotesHandler(git, releasenotesProcessor, outputFormatter, out)),
 			Flags: []cli.Flag{
 				&cli.StringFlag{Name: "r", Aliases: []string{"range"}, Usage: "type of range of commits, use: tag, date or hash", Required: true},
 				&cli.StringFlag{Name: "s", Aliases: []string{"start"}, Usage: "start range of git log revision range, if date, the value is used on since flag instead"},
 				&cli.StringFlag{Name: "e", Aliases: []string{"end"}, Usage: "end range of git log revision range, if date, the value is used on until flag instead"},
+				pathFlag(),
+				firstParentFlag(),
+				noPagerFlag(),
 			},
 		},
 		{
 			Name:    "release-notes",
 			Aliases: []string{"rn"},
 			Usage:   "generate release notes",
-			Action:  releaseNotesHandler(git, semverProcessor, releasenotesProcessor, outputFormatter),
-			Flags:   []cli.Flag{&cli.StringFlag{Name: "t", Aliases: []string{"tag"}, Usage: "get release note from tag"}},
+			Before:  checkHistory,
+			Action:  withPager(out, releaseNotesHandler(git, semverProcessor, releasenotesProcessor, outputFormatter, clock, out)),
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "t", Aliases: []string{"tag"}, Usage: "get release note from tag"},
+				&cli.BoolFlag{Name: "use-existing", Usage: "if next version is already tagged, get release note from that tag instead of failing"},
+				&cli.BoolFlag{Name: "show-unknown", Usage: "list commits not following conventional commits on stderr, with hash and subject"},
+				&cli.StringFlag{Name: "branch", Usage: "preview release notes of commits unique to `branch`, as if it was merged on the default branch"},
+				&cli.StringFlag{Name: "base", Usage: "`branch` used by --branch previews instead of the default branch, origin/HEAD"},
+				&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write release note to `file` instead of stdout, a go template with .Version, .Tag and .Date, the path is printed on stderr"},
+				&cli.BoolFlag{Name: "force", Usage: "overwrite output file if it already exists"},
+				styleFlag(),
+				pathFlag(),
+				fetchFlag(),
+				firstParentFlag(),
+				includeMetadataFlag(),
+				noPagerFlag(),
+			},
 		},
 		{
 			Name:    "changelog",
 			Aliases: []string{"cgl"},
 			Usage:   "generate changelog",
-			Action:  changelogHandler(git, semverProcessor, releasenotesProcessor, outputFormatter),
-			Flags: []cli.Flag{
+			Before:  checkHistory,
+			Action:  withPager(out, changelogHandler(git, semverProcessor, releasenotesProcessor, outputFormatter, cfg, clock, out)),
+			Flags: append([]cli.Flag{
 				&cli.IntFlag{Name: "size", Value: 10, Aliases: []string{"n"}, Usage: "get changelog from last 'n' tags"},
 				&cli.BoolFlag{Name: "all", Usage: "ignore size parameter, get changelog for every tag"},
 				&cli.BoolFlag{Name: "add-next-version", Usage: "add next version on change log (commits since last tag, but only if there is a new version to release)"},
 				&cli.BoolFlag{Name: "semantic-version-only", Usage: "only show tags 'SemVer-ish'"},
-			},
+				styleFlag(),
+				fetchFlag(),
+				firstParentFlag(),
+				includeMetadataFlag(),
+				noPagerFlag(),
+			}, append(dateRangeFlags(), versionRangeFlags()...)...),
 		},
 		{
 			Name:    "tag",
 			Aliases: []string{"tg"},
 			Usage:   "generate tag with version based on git commit messages",
-			Action:  tagHandler(git, semverProcessor),
+			Before:  checkHistory,
+			Action:  tagHandler(git, semverProcessor, cfg, releaseBranch, repoPath, out),
+			Flags: []cli.Flag{
+				fetchFlag(),
+				remoteFlag(),
+				metadataFlag(),
+				bumpFlag(),
+				setVersionFlag(),
+				allowDowngradeFlag(),
+				allowDirtyFlag(),
+				allowBehindFlag(),
+				allowDetachedFlag(),
+				forceRetagFlag(),
+				tagCommitFlag(),
+				noHooksFlag(),
+				&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "do not ask for confirmation before moving an existing tag"},
+			},
+		},
+		{
+			Name:   "retag",
+			Usage:  "recreate existing release tags with their release notes as annotation message, on the same commit, and force-push them",
+			Before: checkHistory,
+			Action: retagHandler(git, monorepoProcessor, releasenotesProcessor, outputFormatter, cfg, repoPath, out),
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{Name: "t", Aliases: []string{"tag"}, Usage: "recreate `tag`, can be used multiple times"},
+				&cli.BoolFlag{Name: "all-semver", Usage: "recreate every tag with a semantic version, tags matching tag.filter config or tags of --component"},
+				&cli.StringFlag{Name: "component", Aliases: []string{"c"}, Usage: "recreate tags of monorepo component `name`, e.g. payments/v1.4.0"},
+				&cli.BoolFlag{Name: "convert", Usage: "replace lightweight tags by annotated tags, lightweight tags are refused otherwise"},
+				&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "recreate and force-push the tags, without it the new messages are only printed"},
+				remoteFlag(),
+				fetchFlag(),
+				includeMetadataFlag(),
+			},
 		},
 		{
 			Name:    "commit",
 			Aliases: []string{"cmt"},
 			Usage:   "execute git commit with convetional commit message helper",
-			Action:  commitHandler(cfg, git, messageProcessor),
+			Action:  commitHandler(cfg, git, messageProcessor, repoPath, out),
 			Flags: []cli.Flag{
 				&cli.BoolFlag{Name: "no-scope", Aliases: []string{"nsc"}, Usage: "do not prompt for commit scope"},
 				&cli.BoolFlag{Name: "no-body", Aliases: []string{"nbd"}, Usage: "do not prompt for commit body"},
 				&cli.BoolFlag{Name: "no-issue", Aliases: []string{"nis"}, Usage: "do not prompt for commit issue, will try to recover from branch if enabled"},
-				&cli.BoolFlag{Name: "no-breaking", Aliases: []string{"nbc"}, Usage: "do not prompt for breaking changes"},
+				&cli.BoolFlag{Name: "no-breaking", Aliases: []string{"nbc"}, Usage: "do not prompt for breaking changes (default: commit-message.breaking.prompt config)"},
+				&cli.BoolFlag{Name: "edit", Aliases: []string{"e"}, Usage: "open $GIT_EDITOR or $EDITOR to write commit body and footer"},
+				&cli.BoolFlag{Name: "all", Aliases: []string{"a"}, Usage: "stage all tracked files changes before commit, like git commit -a"},
+				&cli.StringSliceFlag{Name: "add", Usage: "stage path before commit, can be used multiple times"},
+				&cli.BoolFlag{Name: "dry-run", Usage: "print commit message without staging files or committing"},
+				&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "do not ask for confirmation before commit"},
 				&cli.StringFlag{Name: "type", Aliases: []string{"t"}, Usage: "define commit type"},
 				&cli.StringFlag{Name: "scope", Aliases: []string{"s"}, Usage: "define commit scope"},
 				&cli.StringFlag{Name: "description", Aliases: []string{"d"}, Usage: "define commit description"},
 				&cli.StringFlag{Name: "breaking-change", Aliases: []string{"b"}, Usage: "define commit breaking change message"},
+				&cli.StringFlag{Name: "preset", Usage: "use commit-message preset `name` instead of selecting it by staged files or branch"},
+				&cli.StringSliceFlag{Name: "footer", Usage: "add footer as `key=value`, required footers without value are prompted, can be used multiple times"},
+				checkSpellingFlag(),
 			},
 		},
 		{
 			Name:    "validate-commit-message",
 			Aliases: []string{"vcm"},
 			Usage:   "use as prepare-commit-message hook to validate and enhance commit message",
-			Action:  validateCommitMessageHandler(git, messageProcessor),
+			Action:  validateCommitMessageHandler(cfg, git, messageProcessor, repoPath, out),
 			Flags: []cli.Flag{
 				&cli.StringFlag{Name: "path", Required: true, Usage: "git working directory"},
 				&cli.StringFlag{Name: "file", Required: true, Usage: "name of the file that contains the commit log message"},
 				&cli.StringFlag{Name: "source", Required: true, Usage: "source of the commit message"},
+				&cli.IntFlag{Name: "max-errors", Value: 1, Usage: "report at most `N` violations, use 0 to report all of them"},
+				&cli.BoolFlag{Name: "compact", Usage: "print one line per violation on stderr, for hook runners like lefthook that truncate long output"},
+				&cli.BoolFlag{Name: "edit-hint", Usage: "on failure append a commented help block, with allowed types, scopes and an example, to the commit message file"},
+				warnOnlyFlag(),
+				checkSpellingFlag(),
+			},
+		},
+		{
+			Name:      "validate-push",
+			Usage:     "use as pre-receive hook of git servers to validate messages of pushed commits, works on bare repositories",
+			UsageText: "git-sv validate-push < ref updates, one \"<old> <new> <ref>\" line per updated ref",
+			Action:    validatePushHandler(cfg, git, messageProcessor, repoPath, out),
+			Flags: []cli.Flag{
+				warnOnlyFlag(),
+				&cli.IntFlag{Name: "max-warnings", Usage: "on validation mode warn reject the push if violations are more than `N`, to ratchet adoption"},
+				checkSpellingFlag(),
+			},
+		},
+		{
+			Name:      "validate-message",
+			Aliases:   []string{"vm"},
+			Usage:     "validate a commit message or pull request title, reads from stdin if no flag is set",
+			UsageText: "git-sv validate-message [-m message | --from-file path] [--format text|json]",
+			Action:    validateMessageHandler(cfg, messageProcessor, repoPath, out),
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "message", Aliases: []string{"m"}, Usage: "commit `message` to validate"},
+				&cli.StringFlag{Name: "from-file", Usage: "read commit message from `path`"},
+				&cli.StringFlag{Name: "format", Value: "text", Usage: "output format, use: text or json"},
+				warnOnlyFlag(),
+				checkSpellingFlag(),
+			},
+		},
+		{
+			Name:   "upgrade-check",
+			Usage:  "check if a newer git-sv release is available, the result is cached for 24h",
+			Action: upgradeCheckHandler(newUpgradeChecker(), Version, out),
+			Flags: []cli.Flag{
+				&cli.DurationFlag{Name: "timeout", Value: 5 * time.Second, Usage: "maximum time waiting for the releases API"},
 			},
 		},
 		{
 			Name:    "monorepo-next-version",
 			Aliases: []string{"mnv"},
 			Usage:   "generate next version for each component in a monorepo",
-			Action:  monorepoNextVersionHandler(git, semverProcessor, monorepoProcessor, cfg, repoPath),
+			Before:  checkHistory,
+			Action:  monorepoNextVersionHandler(monorepoGit, semverProcessor, monorepoProcessor, cfg, repoPath, out),
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "format", Value: "text", Usage: "output format, use: text or json"},
+				fetchFlag(),
+				componentsFlag(),
+				bumpFlag(),
+				setVersionFlag(),
+				allowDowngradeFlag(),
+				allowCappedBumpFlag(),
+				explainFlag(),
+			},
 		},
 		{
 			Name:    "monorepo-tag",
 			Aliases: []string{"mtg"},
-			Usage:   "update version files for all changed components in a monorepo",
-			Action:  monorepoTagHandler(git, semverProcessor, monorepoProcessor, cfg, repoPath),
+			Usage:   "create and push a tag for all changed components in a monorepo, versioning files must be already bumped and committed",
+			Before:  checkHistory,
+			Action:  monorepoTagHandler(monorepoGit, semverProcessor, monorepoProcessor, messageProcessor, cfg, repoPath, clock, out),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{Name: "bump-and-commit", Usage: "update and commit versioning file of each component before creating its tag"},
+				fetchFlag(),
+				componentsFlag(),
+				remoteFlag(),
+				metadataFlag(),
+				bumpFlag(),
+				setVersionFlag(),
+				allowDowngradeFlag(),
+				allowCappedBumpFlag(),
+				allowDirtyFlag(),
+				allowBehindFlag(),
+				allowDetachedFlag(),
+				forceRetagFlag(),
+				tagCommitFlag(),
+				noHooksFlag(),
+				&cli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "do not ask for confirmation before moving existing tags"},
+				&cli.IntFlag{Name: "push-batch-size", Usage: "push at most `n` tags per git push, 0 pushes every tag at once, see monorepo.tag-push config"},
+				&cli.StringFlag{Name: "summary-file", Usage: "write a summary of released components to `file` after tagging, json or yaml by file extension, also written with a partial marker on failure"},
+			},
+		},
+		{
+			Name:   "monorepo-promote",
+			Usage:  "tag the stable version of the latest prerelease tag of components on the same commit, e.g. 1.4.0 from 1.4.0-beta.3",
+			Before: checkHistory,
+			Action: monorepoPromoteHandler(monorepoGit, monorepoProcessor, cfg, repoPath, out),
+			Flags: []cli.Flag{
+				componentsFlag(),
+				&cli.StringFlag{Name: "channel", Usage: "only promote prerelease tags of channel `identifier`, e.g. beta"},
+				remoteFlag(),
+				fetchFlag(),
+			},
 		},
 		{
 			Name:    "monorepo-bump",
 			Aliases: []string{"mbu"},
 			Usage:   "bump version files for all changed components in a monorepo without tagging or committing",
-			Action:  monorepoUpdateVersionHandler(git, semverProcessor, monorepoProcessor, cfg, repoPath),
+			Before:  checkHistory,
+			Action:  monorepoUpdateVersionHandler(monorepoGit, semverProcessor, monorepoProcessor, messageProcessor, cfg, repoPath, out),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{Name: "commit", Usage: "stage and commit updated version files using monorepo.bump-commit-message config"},
+				&cli.BoolFlag{Name: "push", Usage: "push bump commit to the remote of the current branch, requires --commit"},
+				fetchFlag(),
+				allowDirtyFlag(),
+				componentsFlag(),
+				bumpFlag(),
+				setVersionFlag(),
+				allowDowngradeFlag(),
+				allowCappedBumpFlag(),
+				noHooksFlag(),
+			},
+		},
+		{
+			Name:    "monorepo-release-notes",
+			Aliases: []string{"mrn"},
+			Usage:   "generate release notes for a monorepo component",
+			Before:  checkHistory,
+			Action:  withPager(out, monorepoReleaseNotesHandler(monorepoGit, semverProcessor, monorepoProcessor, releasenotesProcessor, outputFormatter, cfg, repoPath, clock, out)),
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "component", Aliases: []string{"c"}, Usage: "component name", Required: true},
+				&cli.StringFlag{Name: "t", Aliases: []string{"tag"}, Usage: "get release note from component tag, e.g. payments/v1.4.0"},
+				&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write release note to `file` instead of stdout, a go template with .Version, .Tag and .Date, the path is printed on stderr"},
+				&cli.BoolFlag{Name: "force", Usage: "overwrite output file if it already exists"},
+				styleFlag(),
+				fetchFlag(),
+				noPagerFlag(),
+			},
+		},
+		{
+			Name:      "monorepo-init-component",
+			Aliases:   []string{"mic"},
+			Usage:     "create the versioning file of a new monorepo component",
+			UsageText: "git-sv monorepo-init-component --path services/billing [--name billing] [--version 0.1.0] [--tag]",
+			Action:    monorepoInitComponentHandler(git, cfg, repoPath, out),
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "path", Required: true, Usage: "component `directory` relative to repository root"},
+				&cli.StringFlag{Name: "name", Usage: "component `name`, required if monorepo.name-path is configured, otherwise the directory is used"},
+				&cli.StringFlag{Name: "version", Value: "0.1.0", Usage: "initial `version`"},
+				&cli.BoolFlag{Name: "tag", Usage: "create the initial component tag"},
+				remoteFlag(),
+			},
+		},
+		{
+			Name:      "monorepo-status",
+			Aliases:   []string{"mst"},
+			Usage:     "list directories of monorepo.component-dirs and report the ones without versioning file",
+			UsageText: "git-sv monorepo-status [--create-missing] [--initial-version 0.1.0]",
+			Action:    monorepoStatusHandler(cfg, repoPath, out),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{Name: "create-missing", Usage: "create missing versioning files with the configured monorepo.path structure"},
+				&cli.StringFlag{Name: "initial-version", Value: "0.1.0", Usage: "`version` of created versioning files"},
+			},
+		},
+		{
+			Name:    "monorepo-impact",
+			Aliases: []string{"mim"},
+			Usage:   "report breaking changes of a component on a commit range and the components depending on it, see monorepo.dependencies",
+			Before:  checkHistory,
+			Action:  monorepoImpactHandler(git, monorepoProcessor, cfg, repoPath, out),
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "component", Aliases: []string{"c"}, Usage: "component `name`", Required: true},
+				&cli.StringFlag{Name: "r", Aliases: []string{"range"}, Usage: "type of range of commits, use: tag, date or hash", Value: string(sv.TagRange)},
+				&cli.StringFlag{Name: "s", Aliases: []string{"start"}, Usage: "start range of git log revision range, if date, the value is used on since flag instead"},
+				&cli.StringFlag{Name: "e", Aliases: []string{"end"}, Usage: "end range of git log revision range, if date, the value is used on until flag instead"},
+				&cli.StringFlag{Name: "format", Value: "table", Usage: "output format, use: table or json"},
+				fetchFlag(),
+			},
 		},
 		{
 			Name:    "monorepo-changelog",
 			Aliases: []string{"mcgl"},
 			Usage:   "generate and write CHANGELOG.md for each component in a monorepo",
-			Action:  monorepoChangelogHandler(git, semverProcessor, monorepoProcessor, releasenotesProcessor, outputFormatter, cfg, repoPath),
+			Before:  checkHistory,
+			Action:  monorepoChangelogHandler(monorepoGit, semverProcessor, monorepoProcessor, releasenotesProcessor, outputFormatter, cfg, repoPath, clock, tm, out),
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{Name: "aggregate", Usage: "write a single changelog to `path` with a heading per component instead of one changelog per component"},
+				&cli.BoolFlag{Name: "per-component", Usage: "with --aggregate, also write the changelog of each component"},
+				&cli.BoolFlag{Name: "stdout", Usage: "print changelogs instead of writing them"},
+				&cli.BoolFlag{Name: "all", Usage: "include every released version of each component, read from component tags"},
+				&cli.BoolFlag{Name: "timings", Usage: "print on stderr the wall time and git calls of each phase after the command"},
+				&cli.StringFlag{Name: "timings-json", Usage: "write wall time and git calls of each phase and component to `path` as json, e.g. for trend tracking on CI"},
+				fetchFlag(),
+			}, append(dateRangeFlags(), versionRangeFlags()...)...),
+		},
+		{
+			Name:   "doctor",
+			Usage:  "check git version, repository, config, tags, hooks and monorepo components, with hints to fix problems",
+			Action: doctorHandler(git, messageProcessor, monorepoProcessor, cfg, doctorEnv{repoPath: repoPath, repoErr: rerr, home: os.Getenv("SV4GIT_HOME"), configErr: cerr, fsys: osFS{}}, out),
+		},
+		{
+			Name:      "completion",
+			Usage:     "print shell completion script",
+			UsageText: "git-sv completion bash|zsh|fish",
+			Action:    completionHandler(completers, out),
 		},
 	}
+	completers.register(app.Commands, args)
+
+	return app.Run(args)
+}
 
-	if apperr := app.Run(os.Args); apperr != nil {
-		log.Fatal("ERROR: ", apperr)
+// validateConfig validates each config section.
+func validateConfig(cfg Config) error {
+	if err := cfg.Versioning.Validate(); err != nil {
+		return fmt.Errorf("invalid versioning config, error: %v", err)
 	}
+	if err := cfg.CommitMessage.Validate(); err != nil {
+		return fmt.Errorf("invalid commit message config, error: %v", err)
+	}
+	if err := cfg.Monorepo.Validate(); err != nil {
+		return fmt.Errorf("invalid monorepo config, error: %v", err)
+	}
+	if err := cfg.ReleaseNotes.Validate(); err != nil {
+		return fmt.Errorf("invalid release notes config, error: %v", err)
+	}
+	return nil
 }
 
-func loadCfg(repoPath string) Config {
+func loadCfg(repoPath string, out *printer) (Config, error) {
 	cfg := defaultConfig()
 
-	envCfg := loadEnvConfig()
+	envCfg, err := loadEnvConfig()
+	if err != nil {
+		return Config{}, err
+	}
 	if envCfg.Home != "" {
-		homeCfgFilepath := filepath.Join(envCfg.Home, configFilename)
+		homeCfgFilepath := findConfigFile(envCfg.Home, configFilename)
 		if homeCfg, err := readConfig(homeCfgFilepath); err == nil {
-			if merr := merge(&cfg, migrateConfig(homeCfg, homeCfgFilepath)); merr != nil {
-				log.Fatal("failed to merge user config, error: ", merr)
+			if merr := merge(&cfg, migrateConfig(homeCfg, homeCfgFilepath, out)); merr != nil {
+				return Config{}, fmt.Errorf("failed to merge user config, error: %v", merr)
 			}
 		}
 	}
 
-	repoCfgFilepath := filepath.Join(repoPath, repoConfigFilename)
+	repoCfgFilepath := findConfigFile(repoPath, repoConfigFilename)
 	if repoCfg, err := readConfig(repoCfgFilepath); err == nil {
-		if merr := merge(&cfg, migrateConfig(repoCfg, repoCfgFilepath)); merr != nil {
-			log.Fatal("failed to merge repo config, error: ", merr)
+		if merr := merge(&cfg, migrateConfig(repoCfg, repoCfgFilepath, out)); merr != nil {
+			return Config{}, fmt.Errorf("failed to merge repo config, error: %v", merr)
 		}
 		if len(repoCfg.ReleaseNotes.Headers) > 0 { // mergo is merging maps, headers will be overwritten
 			cfg.ReleaseNotes.Headers = repoCfg.ReleaseNotes.Headers
 		}
 	}
 
-	return cfg
+	return cfg, nil
 }
@@ -0,0 +1,980 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// setupRunRepo creates a repository with fixed commit dates, so tag dates and output are the same on every execution.
+func setupRunRepo(t *testing.T) (func(args ...string), string) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_DATE", "2024-06-01T10:00:00Z")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-01T10:00:00Z")
+	t.Setenv("SV4GIT_HOME", "")
+	return setupIntegrationRepo(t)
+}
+
+// runCLI runs git-sv in process on dir, returning stdout, stderr and error.
+func runCLI(dir string, args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	err := Run(append([]string{"git-sv"}, args...), &stdout, &stderr, dir)
+	return stdout.String(), stderr.String(), err
+}
+
+func shortHash(t *testing.T, repoPath, revision string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--short", revision).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func Test_Run_ReleaseFlow(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "feat(auth): add login")
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+
+	steps := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"next-version"}, "0.1.0\n"},
+		{[]string{"tag"}, "0.1.0\n"},
+		{[]string{"next-version"}, "0.1.0\n"},
+	}
+	for _, step := range steps {
+		stdout, stderr, err := runCLI(repoPath, step.args...)
+		if err != nil {
+			t.Fatalf("Run(%v) unexpected error: %v, stderr: %s", step.args, err, stderr)
+		}
+		if stdout != step.want {
+			t.Errorf("Run(%v) stdout = %q, want %q", step.args, stdout, step.want)
+		}
+	}
+
+	feat, fix := shortHash(t, repoPath, "HEAD~1"), shortHash(t, repoPath, "HEAD")
+	notes := "## v0.1.0 (2024-06-01)\n\n### Features\n\n- **auth:** add login (" + feat + ")\n\n### Bug Fixes\n\n- handle timeout (" + fix + ")\n"
+	outputs := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"changelog"}, "# Changelog\n\n" + notes + "\n---\n"},
+		{[]string{"release-notes", "-t", "0.1.0"}, notes + "\n"},
+		{[]string{"tags"}, "TAG    VERSION  DATE        COMMIT\n0.1.0  0.1.0    2024-06-01  " + fix + "\n"},
+	}
+	for _, output := range outputs {
+		first, _, err := runCLI(repoPath, output.args...)
+		if err != nil {
+			t.Fatalf("Run(%v) unexpected error: %v", output.args, err)
+		}
+		if first != output.want {
+			t.Errorf("Run(%v) stdout = %q, want %q", output.args, first, output.want)
+		}
+		if second, _, _ := runCLI(repoPath, output.args...); second != first {
+			t.Errorf("Run(%v) stdout changed between executions: %q and %q", output.args, first, second)
+		}
+	}
+}
+
+func Test_Run_Errors(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+	if _, _, err := runCLI(repoPath, "tag"); err != nil {
+		t.Fatalf("Run(tag) unexpected error: %v", err)
+	}
+
+	if _, stderr, err := runCLI(repoPath, "tag"); err == nil || !strings.Contains(stderr, "nothing to release since 0.1.0") {
+		t.Errorf("Run(tag) without commits error = %v, stderr = %q, want nothing to release error", err, stderr)
+	}
+
+	// an older tag of the next version, LastTag is still 0.1.0.
+	t.Setenv("GIT_COMMITTER_DATE", "2024-05-01T10:00:00Z")
+	gitCmd("tag", "-a", "0.1.1", "-m", "0.1.1", "HEAD~1")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-01T10:00:00Z")
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+	stdout, stderr, err := runCLI(repoPath, "tag")
+	if exitCode(err) != exitCodeTagExists || stdout != "" || !strings.HasPrefix(stderr, "ERROR: tag 0.1.1 already exists on commit") {
+		t.Errorf("Run(tag) again error = %v, exit code %d, stdout = %q, stderr = %q, want existing tag error", err, exitCode(err), stdout, stderr)
+	}
+
+	if _, stderr, err := runCLI(t.TempDir(), "next-version"); err == nil || !strings.Contains(stderr, "failed to discovery repository top level") {
+		t.Errorf("Run(next-version) outside repository error = %v, stderr = %q", err, stderr)
+	}
+}
+
+func Test_Run_Version(t *testing.T) {
+	_, repoPath := setupRunRepo(t)
+
+	var wg sync.WaitGroup
+	stdouts := make([]string, 4)
+	for i := range stdouts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stdouts[i], _, _ = runCLI(repoPath, "--version")
+		}(i)
+	}
+	wg.Wait()
+
+	for i, stdout := range stdouts {
+		if stdout != versionInfo("sv") {
+			t.Errorf("Run(--version) %d stdout = %q, want %q", i, stdout, versionInfo("sv"))
+		}
+	}
+}
+
+func Test_Run_RepoDir(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+
+	stdout, _, err := runCLI(filepath.Dir(repoPath), "-C", filepath.Base(repoPath), "next-version")
+	if err != nil {
+		t.Fatalf("Run(-C next-version) unexpected error: %v", err)
+	}
+	if stdout != "0.0.1\n" {
+		t.Errorf("Run(-C next-version) stdout = %q, want %q", stdout, "0.0.1\n")
+	}
+}
+
+func Test_Run_ReleaseDate(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+	t.Setenv("GIT_COMMITTER_DATE", "2030-01-01T10:00:00Z") // ignored when release date is fixed
+
+	heading := "## v0.1.0 (2024-07-15)\n"
+	first, stderr, err := runCLI(repoPath, "--release-date", "2024-07-15", "changelog", "--add-next-version")
+	if err != nil {
+		t.Fatalf("Run(--release-date changelog) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	if !strings.Contains(first, heading) {
+		t.Errorf("Run(--release-date changelog) stdout = %q, want heading %q", first, heading)
+	}
+	if second, _, _ := runCLI(repoPath, "--release-date", "2024-07-15", "changelog", "--add-next-version"); second != first {
+		t.Errorf("Run(--release-date changelog) stdout changed between executions: %q and %q", first, second)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1721044800") // 2024-07-15T12:00:00Z
+	if stdout, _, err := runCLI(repoPath, "release-notes"); err != nil || !strings.HasPrefix(stdout, heading) {
+		t.Errorf("Run(release-notes) with SOURCE_DATE_EPOCH error = %v, stdout = %q, want heading %q", err, stdout, heading)
+	}
+
+	if _, stderr, err := runCLI(repoPath, "--release-date", "2024-07-15", "tag"); err != nil {
+		t.Fatalf("Run(--release-date tag) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	out, err := exec.Command("git", "-C", repoPath, "for-each-ref", "--format=%(taggerdate:short)", "refs/tags/0.1.0").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "2024-07-15" {
+		t.Errorf("Run(--release-date tag) tag date = %s, want 2024-07-15", got)
+	}
+
+	if _, stderr, err := runCLI(repoPath, "--release-date", "15/07/2024", "next-version"); err == nil || !strings.Contains(stderr, "invalid --release-date") {
+		t.Errorf("Run(--release-date invalid) error = %v, stderr = %q", err, stderr)
+	}
+}
+
+func Test_Run_CommitNotesDate(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	t.Setenv("GIT_AUTHOR_DATE", "2024-06-20T10:00:00Z")
+	gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+	t.Setenv("GIT_AUTHOR_DATE", "2024-06-05T10:00:00Z") // rebased commit, first on log but older
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+
+	stdout, stderr, err := runCLI(repoPath, "commit-notes", "-r", "hash", "-s", "HEAD~2")
+	if err != nil {
+		t.Fatalf("Run(commit-notes) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	if heading := "## 2024-06-20\n"; !strings.HasPrefix(stdout, heading) {
+		t.Errorf("Run(commit-notes) stdout = %q, want heading %q", stdout, heading)
+	}
+}
+
+func Test_Run_ReleaseNotesBranchPreview(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+	if _, stderr, err := runCLI(repoPath, "tag"); err != nil {
+		t.Fatalf("Run(tag) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	gitCmd("push", "origin", "HEAD")
+	gitCmd("checkout", "-b", "feature/search")
+	gitCmd("commit", "--allow-empty", "-m", "feat: add search")
+	gitCmd("checkout", "-")
+
+	if _, stderr, err := runCLI(repoPath, "release-notes", "--branch", "feature/search"); err == nil || !strings.Contains(stderr, "git remote set-head origin --auto") {
+		t.Errorf("Run(release-notes --branch) without origin/HEAD error = %v, stderr = %q", err, stderr)
+	}
+	gitCmd("remote", "set-head", "origin", "--auto")
+
+	tests := []struct {
+		name        string
+		mainCommit  string
+		wantHeading string
+	}{
+		{"final version", "", "## v0.2.0 (2024-07-15)\n\n### Features\n\n- add search"},
+		{"unreleased commits on default branch", "fix: handle timeout", "## Preview feature/search (2024-07-15)\n\n### Features\n\n- add search"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mainCommit != "" {
+				gitCmd("commit", "--allow-empty", "-m", tt.mainCommit)
+				gitCmd("push", "origin", "HEAD")
+			}
+			stdout, stderr, err := runCLI(repoPath, "--release-date", "2024-07-15", "release-notes", "--branch", "feature/search")
+			if err != nil {
+				t.Fatalf("Run(release-notes --branch) unexpected error: %v, stderr: %s", err, stderr)
+			}
+			if !strings.HasPrefix(stdout, tt.wantHeading) {
+				t.Errorf("Run(release-notes --branch) stdout = %q, want prefix %q", stdout, tt.wantHeading)
+			}
+			if !strings.Contains(stderr, "preview of feature/search merged on origin/") {
+				t.Errorf("Run(release-notes --branch) stderr = %q, want preview status", stderr)
+			}
+		})
+	}
+}
+
+func Test_Run_ShowUnknown(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "version: \"1.1\"\nversioning:\n  ignore-unknown: true\n")
+	gitCmd("commit", "--allow-empty", "-m", "chore: setup")
+	gitCmd("tag", "0.1.0")
+	gitCmd("commit", "--allow-empty", "-m", "update readme")
+	gitCmd("commit", "--allow-empty", "-m", "docs: add usage")
+	gitCmd("commit", "--allow-empty", "-m", "Fix typo")
+	readme, typo := shortHash(t, repoPath, "HEAD~2"), shortHash(t, repoPath, "HEAD")
+
+	wantList := "2 commit(s) not following conventional commits:\n  " + typo + " Fix typo\n  " + readme + " update readme\n"
+	stdout, stderr, err := runCLI(repoPath, "next-version", "--show-unknown")
+	if err != nil {
+		t.Fatalf("Run(next-version --show-unknown) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	if want := "0.1.1\n" + wantList; stdout != want {
+		t.Errorf("Run(next-version --show-unknown) stdout = %q, want %q", stdout, want)
+	}
+
+	stdout, stderr, err = runCLI(repoPath, "next-version", "--show-unknown", "--format", "json")
+	if err != nil {
+		t.Fatalf("Run(next-version --show-unknown --format json) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	var info nextVersionInfo
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		t.Fatalf("Run(next-version --format json) invalid json %q: %v", stdout, err)
+	}
+	if want := []commitSummary{{Hash: typo, Subject: "Fix typo"}, {Hash: readme, Subject: "update readme"}}; !reflect.DeepEqual(info.UnknownCommits, want) {
+		t.Errorf("Run(next-version --format json) unknown commits = %+v, want %+v", info.UnknownCommits, want)
+	}
+
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "version: \"1.1\"\nversioning:\n  ignore-unknown: true\n  unknown-commit-bump: patch\n")
+	stdout, stderr, err = runCLI(repoPath, "release-notes", "--show-unknown")
+	if err != nil {
+		t.Fatalf("Run(release-notes --show-unknown) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	if !strings.Contains(stderr, wantList) || strings.Contains(stdout, "not following") {
+		t.Errorf("Run(release-notes --show-unknown) stdout = %q, stderr = %q, want list on stderr", stdout, stderr)
+	}
+}
+
+func Test_Run_CommitLogTrailers(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "feat!: add login", "-m", "BREAKING CHANGE: session cookie\n  renamed\nCo-authored-by: Ana <ana@example.com>\nCo-authored-by: Bob <bob@example.com>\njira: JIRA-12")
+
+	stdout, stderr, err := runCLI(repoPath, "commit-log", "-r", "hash", "-s", "HEAD~1")
+	if err != nil {
+		t.Fatalf("Run(commit-log) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	var commit struct {
+		Message struct {
+			Metadata map[string]string `json:"metadata"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &commit); err != nil {
+		t.Fatalf("Run(commit-log) invalid json %q: %v", stdout, err)
+	}
+	want := map[string]string{"breaking-change": "session cookie renamed", "Co-authored-by": "Ana <ana@example.com>, Bob <bob@example.com>", "issue": "JIRA-12"}
+	if !reflect.DeepEqual(commit.Message.Metadata, want) {
+		t.Errorf("Run(commit-log) metadata = %v, want %v", commit.Message.Metadata, want)
+	}
+}
+
+func Test_Run_MonorepoChangelogHistory(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	release := func(version, date, message string) {
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		writeFile(t, filepath.Join(repoPath, "sigma", "version.yml"), "version: "+version+"\n")
+		gitCmd("add", ".")
+		gitCmd("commit", "-m", message)
+		gitCmd("tag", "-a", "sigma/v"+version, "-m", "sigma "+version)
+	}
+	release("1.0.0", "2024-06-01T10:00:00Z", "feat: add sigma")
+	release("1.0.1", "2024-06-02T10:00:00Z", "fix: handle empty input on sigma/handler.go")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-03T10:00:00Z")
+	gitCmd("commit", "--allow-empty", "-m", "chore: unrelated change")
+
+	hashes := strings.NewReplacer("{feat}", shortHash(t, repoPath, "sigma/v1.0.0^{commit}"), "{fix}", shortHash(t, repoPath, "sigma/v1.0.1^{commit}"))
+
+	tests := []struct {
+		name      string
+		changelog string
+		want      string
+	}{
+		{"tag names", "", "# Changelog\n\n" +
+			"## [sigma/v1.0.1](https://example.com/compare/sigma/v1.0.0...sigma/v1.0.1) (2024-06-02)\n\n### Bug Fixes\n\n- handle empty input on sigma/handler.go ({fix})\n\n---\n\n" +
+			"## [sigma/v1.0.0](https://example.com/tree/sigma/v1.0.0) (2024-06-01)\n\n### Features\n\n- add sigma ({feat})\n\n---\n"},
+		{"strip tag prefix and relative paths", "    changelog:\n        strip-tag-prefix: true\n        relative-paths: true\n", "# Changelog\n\n" +
+			"## [v1.0.1](https://example.com/compare/sigma/v1.0.0...sigma/v1.0.1) (2024-06-02)\n\n### Bug Fixes\n\n- handle empty input on handler.go ({fix})\n\n---\n\n" +
+			"## [v1.0.0](https://example.com/tree/sigma/v1.0.0) (2024-06-01)\n\n### Features\n\n- add sigma ({feat})\n\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "release-notes:\n"+
+				"    compare-url-template: https://example.com/compare/{previous-tag}...{tag}\n"+
+				"    tag-url-template: https://example.com/tree/{tag}\n"+
+				"monorepo:\n    versioning-file: '*/version.yml'\n    path: version\n"+tt.changelog)
+
+			stdout, stderr, err := runCLI(repoPath, "monorepo-changelog", "--all", "--stdout")
+			if err != nil {
+				t.Fatalf("Run(monorepo-changelog --all) unexpected error: %v, stderr: %s", err, stderr)
+			}
+			if want := hashes.Replace(tt.want); stdout != want {
+				t.Errorf("Run(monorepo-changelog --all) stdout = %q, want %q", stdout, want)
+			}
+		})
+	}
+}
+
+func Test_Run_MonorepoReleaseNotesOutput(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "monorepo:\n    versioning-file: '*/version.yml'\n    path: version\n")
+	writeFile(t, filepath.Join(repoPath, "sigma", "version.yml"), "version: 1.0.0\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "feat: add sigma")
+	gitCmd("tag", "-a", "sigma/v1.0.0", "-m", "sigma 1.0.0")
+	writeFile(t, filepath.Join(repoPath, "sigma", "handler.go"), "package sigma\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "fix(sigma): handle empty input")
+
+	output := filepath.Join(repoPath, "dist", "sigma-{{.Version}}.md")
+	stdout, stderr, err := runCLI(repoPath, "monorepo-release-notes", "-c", "sigma", "--style", "checklist", "-o", output)
+	path := filepath.Join(repoPath, "dist", "sigma-1.0.1.md")
+	if err != nil || stdout != "" || stderr != path+"\n" {
+		t.Fatalf("Run(monorepo-release-notes -o) = %q, stderr %q, error %v, want only path %s on stderr", stdout, stderr, err, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(content), "- [ ] fix(sigma): handle empty input") {
+		t.Errorf("Run(monorepo-release-notes -o) file = %q, error %v, want checklist release note", content, err)
+	}
+	if _, _, err := runCLI(repoPath, "monorepo-release-notes", "-c", "sigma", "-o", output); err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Errorf("Run(monorepo-release-notes -o) error = %v, want existing file error", err)
+	}
+	if _, _, err := runCLI(repoPath, "monorepo-release-notes", "-c", "sigma", "-o", output, "--force"); err != nil {
+		t.Errorf("Run(monorepo-release-notes -o --force) unexpected error: %v", err)
+	}
+}
+
+func Test_Run_MonorepoChangelogLightweightTags(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	release := func(version, date, message string) {
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		writeFile(t, filepath.Join(repoPath, "sigma", "version.yml"), "version: "+version+"\n")
+		gitCmd("add", ".")
+		gitCmd("commit", "-m", message)
+	}
+	release("1.0.0", "2024-06-01T10:00:00Z", "feat: add sigma")
+	release("1.0.1", "2024-06-02T10:00:00Z", "fix: handle empty input")
+	gitCmd("tag", "-a", "sigma/v1.0.1", "-m", "sigma 1.0.1")
+	release("1.1.0", "2024-06-03T10:00:00Z", "feat: support streams")
+	release("1.1.1", "2024-06-04T10:00:00Z", "fix: close streams")
+
+	// tags created later by a script: lightweight tags and a tag object without tagger, as written by some tools.
+	t.Setenv("GIT_COMMITTER_DATE", "2024-07-01T10:00:00Z")
+	gitCmd("tag", "sigma/v1.0.0", "HEAD~3")
+	gitCmd("tag", "sigma/v1.1.1", "HEAD")
+	commit, err := exec.Command("git", "-C", repoPath, "rev-parse", "HEAD~1").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "hash-object", "-t", "tag", "-w", "--stdin")
+	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader("object " + strings.TrimSpace(string(commit)) + "\ntype commit\ntag sigma/v1.1.0\n\nsigma 1.1.0\n")
+	object, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git hash-object: %v", err)
+	}
+	gitCmd("update-ref", "refs/tags/sigma/v1.1.0", strings.TrimSpace(string(object)))
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "monorepo:\n    versioning-file: '*/version.yml'\n    path: version\n")
+
+	stdout, stderr, err := runCLI(repoPath, "monorepo-changelog", "--all", "--stdout")
+	if err != nil {
+		t.Fatalf("Run(monorepo-changelog --all) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	hash := func(tag string) string { return shortHash(t, repoPath, tag+"^{commit}") }
+	want := "# Changelog\n\n" +
+		"## sigma/v1.1.1 (2024-06-04)\n\n### Bug Fixes\n\n- close streams (" + hash("sigma/v1.1.1") + ")\n\n---\n\n" +
+		"## sigma/v1.1.0 (2024-06-03)\n\n### Features\n\n- support streams (" + hash("sigma/v1.1.0") + ")\n\n---\n\n" +
+		"## sigma/v1.0.1 (2024-06-02)\n\n### Bug Fixes\n\n- handle empty input (" + hash("sigma/v1.0.1") + ")\n\n---\n\n" +
+		"## sigma/v1.0.0 (2024-06-01)\n\n### Features\n\n- add sigma (" + hash("sigma/v1.0.0") + ")\n\n---\n"
+	if stdout != want {
+		t.Errorf("Run(monorepo-changelog --all) stdout = %q, want %q", stdout, want)
+	}
+}
+
+func Test_Run_MonorepoPreviousPaths(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	writeFile(t, filepath.Join(repoPath, "services", "payments", "version.yml"), "version: 1.0.0\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "feat: add payments")
+	gitCmd("tag", "-a", "services/payments/v1.0.0", "-m", "payments 1.0.0")
+	writeFile(t, filepath.Join(repoPath, "services", "payments", "handler.go"), "package payments\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "feat: support refunds")
+	gitCmd("mv", "services/payments", "services/billing")
+	gitCmd("commit", "-m", "refactor: rename payments to billing")
+
+	hashes := strings.NewReplacer("{add}", shortHash(t, repoPath, "services/payments/v1.0.0^{commit}"), "{refunds}", shortHash(t, repoPath, "HEAD~1"))
+
+	tests := []struct {
+		name          string
+		components    string
+		wantVersion   string
+		wantChangelog string
+	}{
+		{"without previous-paths", "", "services/billing: 1.0.1\n", "# Changelog\n\n## v1.0.1 (2024-06-01)\n\n---\n"},
+		{"with previous-paths", "    components:\n        - name: services/billing\n          previous-paths: [services/payments]\n", "services/billing: 1.1.0\n", "# Changelog\n\n" +
+			"## v1.1.0 (2024-06-01)\n\n### Features\n\n- support refunds ({refunds})\n\n---\n\n" +
+			"## services/payments/v1.0.0 (2024-06-01)\n\n### Features\n\n- add payments ({add})\n\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "monorepo:\n    versioning-file: 'services/*/version.yml'\n    path: version\n"+tt.components)
+
+			stdout, stderr, err := runCLI(repoPath, "monorepo-next-version")
+			if err != nil {
+				t.Fatalf("Run(monorepo-next-version) unexpected error: %v, stderr: %s", err, stderr)
+			}
+			if stdout != tt.wantVersion {
+				t.Errorf("Run(monorepo-next-version) stdout = %q, want %q", stdout, tt.wantVersion)
+			}
+
+			stdout, stderr, err = runCLI(repoPath, "monorepo-changelog", "--all", "--stdout")
+			if err != nil {
+				t.Fatalf("Run(monorepo-changelog --all) unexpected error: %v, stderr: %s", err, stderr)
+			}
+			if want := hashes.Replace(tt.wantChangelog); stdout != want {
+				t.Errorf("Run(monorepo-changelog --all) stdout = %q, want %q", stdout, want)
+			}
+		})
+	}
+}
+
+func Test_Run_TagHooks(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "version: \"1.1\"\nhooks:\n  pre-tag:\n    - exit 1\n")
+	if _, _, err := runCLI(repoPath, "tag"); err == nil || !strings.Contains(err.Error(), `pre-tag hook "exit 1" failed`) {
+		t.Fatalf("Run(tag) error = %v, want pre-tag hook failure", err)
+	}
+	if tags, _ := exec.Command("git", "-C", repoPath, "tag").Output(); len(tags) > 0 {
+		t.Fatalf("Run(tag) created tags %q after pre-tag hook failure", tags)
+	}
+
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "version: \"1.1\"\nhooks:\n  pre-tag:\n    - exit 1\n  post-tag:\n    - echo \"released $SV_TAG\" > released.txt; exit 2\n")
+	stdout, stderr, err := runCLI(repoPath, "tag", "--no-hooks")
+	if err != nil {
+		t.Fatalf("Run(tag --no-hooks) unexpected error: %v, stderr: %s", err, stderr)
+	}
+	if stdout != "0.1.0\n" || !strings.Contains(stderr, "pre-tag hooks skipped by --no-hooks") {
+		t.Errorf("Run(tag --no-hooks) stdout = %q, stderr = %q", stdout, stderr)
+	}
+
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "version: \"1.1\"\nhooks:\n  post-tag:\n    - echo \"released $SV_TAG\" > released.txt; exit 2\n")
+	stdout, _, err = runCLI(repoPath, "tag")
+	if err == nil || !strings.Contains(err.Error(), "tags and versioning files were kept") {
+		t.Errorf("Run(tag) error = %v, want post-tag hook failure", err)
+	}
+	if stdout != "0.1.1\n" {
+		t.Errorf("Run(tag) stdout = %q, want tag created before post-tag hook", stdout)
+	}
+	content, rerr := os.ReadFile(filepath.Join(repoPath, "released.txt"))
+	if rerr != nil || string(content) != "released 0.1.1\n" {
+		t.Errorf("post-tag hook output = %q, error %v, want released 0.1.1", content, rerr)
+	}
+}
+
+func Test_Run_TypeSettings(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), `version: "1.1"
+commit-message:
+    types: [feat, fix, ci, security]
+    type-settings:
+        ci: {bump: none, notes: true}
+        security: {bump: patch}
+release-notes:
+    sections:
+        - {name: Maintenance, section-type: commits, commit-types: [ci]}
+`)
+	gitCmd("commit", "--allow-empty", "-m", "chore: setup")
+	gitCmd("tag", "1.0.0")
+	gitCmd("commit", "--allow-empty", "-m", "ci: cache modules")
+
+	if stdout, stderr, err := runCLI(repoPath, "next-version"); err != nil || stdout != "1.0.0\n" {
+		t.Errorf("Run(next-version) = %q, error %v, stderr %s, want 1.0.0", stdout, err, stderr)
+	}
+	stdout, stderr, err := runCLI(repoPath, "release-notes")
+	if err != nil || !strings.Contains(stdout, "### Maintenance\n\n- cache modules") {
+		t.Errorf("Run(release-notes) = %q, error %v, stderr %s, want ci commit on Maintenance", stdout, err, stderr)
+	}
+
+	gitCmd("commit", "--allow-empty", "-m", "security: rotate keys")
+	if stdout, stderr, err := runCLI(repoPath, "next-version"); err != nil || stdout != "1.0.1\n" {
+		t.Errorf("Run(next-version) = %q, error %v, stderr %s, want 1.0.1", stdout, err, stderr)
+	}
+}
+
+func Test_Run_Monotonic(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	tag := func(name, date string) {
+		t.Helper()
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		gitCmd("tag", "-a", name, "-m", name)
+	}
+	// v1.9.5 is tagged on the maintenance branch after v2.3.0, then merged back on main.
+	gitCmd("checkout", "-b", "main")
+	gitCmd("commit", "--allow-empty", "-m", "chore: setup")
+	tag("v1.9.0", "2024-01-01T10:00:00Z")
+	gitCmd("branch", "maintenance")
+	gitCmd("commit", "--allow-empty", "-m", "feat: new api")
+	tag("v2.3.0", "2024-02-01T10:00:00Z")
+	gitCmd("checkout", "maintenance")
+	gitCmd("commit", "--allow-empty", "-m", "fix: backport timeout")
+	tag("v1.9.5", "2024-03-01T10:00:00Z")
+	gitCmd("checkout", "main")
+	gitCmd("merge", "--no-ff", "-m", "Merge branch 'maintenance'", "maintenance")
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle nil")
+
+	// the most recent tag is the baseline, v2.3.0 commits are released again as 1.10.0.
+	if stdout, stderr, err := runCLI(repoPath, "next-version"); err != nil || stdout != "1.10.0\n" {
+		t.Errorf("Run(next-version) = %q, error %v, stderr %s, want 1.10.0", stdout, err, stderr)
+	}
+
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "version: \"1.1\"\nversioning:\n    monotonic: true\n")
+	if stdout, stderr, err := runCLI(repoPath, "next-version"); err != nil || stdout != "2.3.1\n" {
+		t.Errorf("Run(next-version) = %q, error %v, stderr %s, want 2.3.1", stdout, err, stderr)
+	}
+	stdout, stderr, err := runCLI(repoPath, "release-notes")
+	if err != nil || !strings.Contains(stdout, "## v2.3.1") || !strings.Contains(stdout, "- backport timeout") || !strings.Contains(stdout, "- handle nil") ||
+		strings.Contains(stdout, "new api") || strings.Contains(stdout, "setup") {
+		t.Errorf("Run(release-notes) = %q, error %v, stderr %s, want v2.3.1 with fixes since v2.3.0", stdout, err, stderr)
+	}
+	if stdout, stderr, err := runCLI(repoPath, "current-version"); err != nil || stdout != "2.3.0\n" {
+		t.Errorf("Run(current-version) = %q, error %v, stderr %s, want 2.3.0", stdout, err, stderr)
+	}
+}
+
+func Test_Run_VersioningFile(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	writeFile(t, filepath.Join(repoPath, "package.json"), "{\n  \"name\": \"app\",\n  \"version\": \"1.0.0\"\n}\n")
+	gitCmd("add", "package.json")
+	gitCmd("commit", "-m", "chore: setup")
+	gitCmd("tag", "v1.0.0")
+	gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+
+	tagCfg := "version: \"1.1\"\ntag:\n    pattern: v%d.%d.%d\nversioning:\n    file: package.json\n"
+	fileCfg := tagCfg + "    source: file\n"
+	versions := func(cfg string) string {
+		t.Helper()
+		writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), cfg)
+		current, stderr, err := runCLI(repoPath, "current-version")
+		if err != nil {
+			t.Fatalf("Run(current-version) unexpected error: %v, stderr: %s", err, stderr)
+		}
+		next, stderr, err := runCLI(repoPath, "next-version")
+		if err != nil {
+			t.Fatalf("Run(next-version) unexpected error: %v, stderr: %s", err, stderr)
+		}
+		return strings.TrimSpace(current) + " " + strings.TrimSpace(next)
+	}
+
+	// in sync, both sources agree.
+	if tagGot, fileGot := versions(tagCfg), versions(fileCfg); tagGot != "1.0.0 1.1.0" || fileGot != tagGot {
+		t.Errorf("versions in sync = %q with source tag, %q with source file, want 1.0.0 1.1.0", tagGot, fileGot)
+	}
+
+	stdout, stderr, err := runCLI(repoPath, "bump", "--commit")
+	if err != nil || stdout != "1.1.0 written to package.json\n" {
+		t.Fatalf("Run(bump --commit) = %q, stderr %q, error %v", stdout, stderr, err)
+	}
+	if !strings.Contains(stderr, "chore(release): bump version") {
+		t.Errorf("Run(bump --commit) stderr = %q, want git commit output on stderr", stderr)
+	}
+	content, _ := os.ReadFile(filepath.Join(repoPath, "package.json"))
+	if string(content) != "{\n  \"name\": \"app\",\n  \"version\": \"1.1.0\"\n}\n" {
+		t.Errorf("package.json after bump = %q", content)
+	}
+	if subject, _ := exec.Command("git", "-C", repoPath, "log", "-1", "--format=%s").Output(); string(subject) != "chore(release): bump version\n" {
+		t.Errorf("bump commit subject = %q", subject)
+	}
+
+	// bump committed, the file is the next version and bump is idempotent.
+	if got := versions(fileCfg); got != "1.1.0 1.1.0" {
+		t.Errorf("versions after bump with source file = %q, want 1.1.0 1.1.0", got)
+	}
+	if stdout, _, err := runCLI(repoPath, "bump"); err != nil || stdout != "no version change, package.json already has version 1.1.0\n" {
+		t.Errorf("Run(bump) again = %q, error %v, want no version change", stdout, err)
+	}
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-02T10:00:00Z") // tags are sorted by date
+	if stdout, stderr, err := runCLI(repoPath, "tag"); err != nil || stdout != "v1.1.0\n" {
+		t.Fatalf("Run(tag) = %q, stderr %q, error %v, want v1.1.0", stdout, stderr, err)
+	}
+
+	// file behind the tag, e.g. tagged without bump, source file falls back to the tag with a warning.
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-03T10:00:00Z")
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+	gitCmd("tag", "v1.2.0")
+	gitCmd("commit", "--allow-empty", "-m", "fix: retry")
+	if got := versions(fileCfg); got != "1.2.0 1.2.1" {
+		t.Errorf("versions with file behind tag = %q, want 1.2.0 1.2.1", got)
+	}
+	if _, stderr, _ := runCLI(repoPath, "next-version"); !strings.Contains(stderr, "WARN: versioning file package.json has version 1.1.0, lower than tag v1.2.0") {
+		t.Errorf("Run(next-version) stderr = %q, want file behind tag warning", stderr)
+	}
+	if got := versions(tagCfg); got != "1.2.0 1.2.1" {
+		t.Errorf("versions with source tag = %q, want 1.2.0 1.2.1", got)
+	}
+
+	t.Run("without tag", func(t *testing.T) {
+		gitCmd, repoPath := setupRunRepo(t)
+		writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), fileCfg)
+		writeFile(t, filepath.Join(repoPath, "package.json"), "{\n  \"version\": \"1.0.0\"\n}\n")
+		gitCmd("add", "package.json")
+		gitCmd("commit", "-m", "chore: setup")
+		gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+
+		// the file version includes commits up to its last change, bump applies only later commits.
+		for i, want := range []string{"1.1.0 written to package.json\n", "no version change, package.json already has version 1.1.0\n"} {
+			if stdout, stderr, err := runCLI(repoPath, "bump", "--commit"); err != nil || stdout != want {
+				t.Errorf("Run(bump --commit) #%d = %q, stderr %q, error %v, want %q", i+1, stdout, stderr, err, want)
+			}
+		}
+		if stdout, stderr, err := runCLI(repoPath, "next-version"); err != nil || stdout != "1.1.0\n" {
+			t.Errorf("Run(next-version) = %q, stderr %q, error %v, want 1.1.0", stdout, stderr, err)
+		}
+		gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+		if stdout, stderr, err := runCLI(repoPath, "next-version"); err != nil || stdout != "1.1.1\n" {
+			t.Errorf("Run(next-version) after fix = %q, stderr %q, error %v, want 1.1.1", stdout, stderr, err)
+		}
+	})
+}
+
+func Test_Run_ValidationMode(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("checkout", "-b", "feature/readme")
+	file := filepath.Join(repoPath, ".git", "COMMIT_EDITMSG")
+	exitCodeOf := func(err error) int {
+		if err == nil {
+			return 0
+		}
+		return exitCode(err)
+	}
+
+	tests := []struct {
+		name        string
+		mode        string
+		args        []string
+		wantCode    int
+		wantWarning bool
+		wantMessage string
+	}{
+		{"error", "", nil, 1, false, "Update readme\n"},
+		{"error config", "error", nil, 1, false, "Update readme\n"},
+		{"warn", "warn", nil, 0, true, "Update readme\n\nValidation-Warnings: 3\n"},
+		{"warn only flag", "error", []string{"--warn-only"}, 0, true, "Update readme\n\nValidation-Warnings: 3\n"},
+		{"off", "off", nil, 0, false, "Update readme\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "version: \"1.1\"\nbranches:\n    disable-issue: true\ncommit-message:\n    validation-mode: '"+tt.mode+"'\n")
+			writeFile(t, file, "Update readme\n")
+
+			args := append([]string{"validate-commit-message", "--path", repoPath, "--file", ".git/COMMIT_EDITMSG", "--source", "message"}, tt.args...)
+			_, stderr, err := runCLI(repoPath, args...)
+			if got := exitCodeOf(err); got != tt.wantCode {
+				t.Errorf("Run(validate-commit-message) exit code = %d, want %d, stderr: %s", got, tt.wantCode, stderr)
+			}
+			if got := strings.Contains(stderr, "accepted by validation mode warn"); got != tt.wantWarning {
+				t.Errorf("Run(validate-commit-message) stderr = %q, want warning %v", stderr, tt.wantWarning)
+			}
+			if content, _ := os.ReadFile(file); string(content) != tt.wantMessage {
+				t.Errorf("commit message file = %q, want %q", content, tt.wantMessage)
+			}
+
+			_, stderr, err = runCLI(repoPath, append([]string{"validate-message", "-m", "Update readme"}, tt.args...)...)
+			if got := exitCodeOf(err); got != tt.wantCode {
+				t.Errorf("Run(validate-message) exit code = %d, want %d, stderr: %s", got, tt.wantCode, stderr)
+			}
+		})
+	}
+
+	// the count is replaced when the message is validated again, e.g. on amend.
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "version: \"1.1\"\nbranches:\n    disable-issue: true\ncommit-message:\n    validation-mode: warn\n")
+	writeFile(t, file, "Update readme\n\nValidation-Warnings: 1\n")
+	if _, _, err := runCLI(repoPath, "validate-commit-message", "--path", repoPath, "--file", ".git/COMMIT_EDITMSG", "--source", "message"); err != nil {
+		t.Fatalf("Run(validate-commit-message) unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "Update readme\n\nValidation-Warnings: 3\n" {
+		t.Errorf("commit message file validated again = %q, want a single footer", content)
+	}
+}
+
+func Test_Run_EmptyRange(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	// HEAD is the last tag of the repository and of the component.
+	writeFile(t, filepath.Join(repoPath, "sigma", "version.yml"), "version: 1.0.0\n")
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "monorepo:\n    versioning-file: '*/version.yml'\n    path: version\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "feat: add sigma")
+	gitCmd("tag", "-a", "sigma/v1.0.0", "-m", "sigma 1.0.0")
+	gitCmd("tag", "-a", "0.1.0", "-m", "0.1.0")
+
+	tests := []struct {
+		args       []string
+		wantStdout string
+		wantErr    string
+	}{
+		{[]string{"next-version"}, "0.1.0\n", ""},
+		{[]string{"next-version", "--format", "json"}, "{\n  \"currentVersion\": \"0.1.0\",\n  \"nextVersion\": \"0.1.0\",\n  \"updated\": false\n}\n", ""},
+		{[]string{"tag"}, "", "nothing to release since 0.1.0, use --bump or --set-version to tag anyway"},
+		{[]string{"release-notes"}, "", "nothing to release since 0.1.0, use --tag 0.1.0 to get its release notes"},
+		{[]string{"changelog", "--add-next-version"}, "", ""},
+		{[]string{"monorepo-changelog", "--stdout"}, "", ""},
+		{[]string{"monorepo-next-version"}, "sigma: 1.0.0 (no change)\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			stdout, stderr, err := runCLI(repoPath, tt.args...)
+			if (err != nil) != (tt.wantErr != "") || !strings.Contains(stderr, tt.wantErr) {
+				t.Fatalf("Run(%v) error = %v, stderr = %q, want %q", tt.args, err, stderr, tt.wantErr)
+			}
+			if tt.wantStdout != "" && stdout != tt.wantStdout {
+				t.Errorf("Run(%v) stdout = %q, want %q", tt.args, stdout, tt.wantStdout)
+			}
+			if strings.Contains(stdout, "0.1.1") {
+				t.Errorf("Run(%v) stdout = %q, want no next version", tt.args, stdout)
+			}
+		})
+	}
+
+	stdout, stderr, err := runCLI(repoPath, "tag", "--bump", "patch")
+	if err != nil || stdout != "0.1.1\n" {
+		t.Errorf("Run(tag --bump patch) = %q, error = %v, stderr = %q, want 0.1.1", stdout, err, stderr)
+	}
+}
+
+func Test_Run_Retag(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitOutput := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repoPath}, args...)...).Output()
+		if err != nil {
+			t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	t.Setenv("GIT_COMMITTER_DATE", "2024-05-01T10:00:00Z")
+	gitCmd("commit", "--allow-empty", "-m", "feat: add login")
+	gitCmd("tag", "-a", "0.1.0", "-m", "Version 0.1.0")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-01T10:00:00Z")
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+	gitCmd("tag", "0.1.1")
+	gitCmd("push", "origin", "--tags")
+	commit := gitOutput("rev-parse", "0.1.0^{commit}")
+
+	stdout, stderr, err := runCLI(repoPath, "retag", "-t", "0.1.0")
+	if err != nil || !strings.Contains(stdout, "tag 0.1.0 on commit "+commit[:7]) || !strings.Contains(stdout, "add login") || !strings.Contains(stderr, "use --yes to recreate them and force-push them to origin") {
+		t.Fatalf("Run(retag -t 0.1.0) = %q, stderr = %q, error = %v, want preview", stdout, stderr, err)
+	}
+	if got := gitOutput("tag", "-l", "--format=%(contents)", "0.1.0"); got != "Version 0.1.0" {
+		t.Fatalf("tag 0.1.0 message = %q, want unchanged without --yes", got)
+	}
+
+	if _, stderr, err := runCLI(repoPath, "retag", "-t", "0.1.1", "--yes"); err == nil || !strings.Contains(stderr, "tag 0.1.1 is a lightweight tag, use --convert") {
+		t.Fatalf("Run(retag -t 0.1.1 --yes) error = %v, stderr = %q, want lightweight tag error", err, stderr)
+	}
+	if _, _, err := runCLI(repoPath, "retag"); err == nil {
+		t.Fatal("Run(retag) error = nil, want error without --tag or --all-semver")
+	}
+
+	stdout, stderr, err = runCLI(repoPath, "retag", "--all-semver", "--convert", "--yes")
+	if err != nil || stdout != "0.1.0\n0.1.1\n" || !strings.Contains(stderr, "lightweight tag 0.1.1 converted") {
+		t.Fatalf("Run(retag --all-semver --convert --yes) = %q, stderr = %q, error = %v", stdout, stderr, err)
+	}
+	if got := gitOutput("tag", "-l", "--format=%(contents)", "0.1.0"); !strings.HasPrefix(got, "## v0.1.0 (2024-05-01)") || !strings.Contains(got, "add login") {
+		t.Errorf("tag 0.1.0 message = %q, want release notes", got)
+	}
+	if got := gitOutput("rev-parse", "0.1.0^{commit}"); got != commit {
+		t.Errorf("tag 0.1.0 commit = %s, want %s", got, commit)
+	}
+	if got := gitOutput("tag", "-l", "--format=%(taggerdate:short)", "0.1.0"); got != "2024-05-01" {
+		t.Errorf("tag 0.1.0 tagger date = %s, want the original date", got)
+	}
+	if got := gitOutput("cat-file", "-t", "0.1.1"); got != "tag" {
+		t.Errorf("tag 0.1.1 type = %s, want annotated tag", got)
+	}
+	for _, tag := range []string{"0.1.0", "0.1.1"} {
+		local := gitOutput("rev-parse", "refs/tags/"+tag)
+		if remote := gitOutput("ls-remote", "origin", "refs/tags/"+tag); !strings.HasPrefix(remote, local) {
+			t.Errorf("remote tag %s = %q, want force-pushed %s", tag, remote, local)
+		}
+	}
+	if stdout, _, err := runCLI(repoPath, "current-version"); err != nil || stdout != "0.1.1\n" {
+		t.Errorf("Run(current-version) = %q, error = %v, want tags order kept", stdout, err)
+	}
+}
+
+func Test_Run_MonorepoImpact(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "monorepo:\n    versioning-file: '*/version.yml'\n    path: version\n")
+	writeFile(t, filepath.Join(repoPath, "shared", "version.yml"), "version: 1.0.0\n")
+	writeFile(t, filepath.Join(repoPath, "shared", "package.json"), `{"name": "@acme/shared"}`)
+	writeFile(t, filepath.Join(repoPath, "web", "version.yml"), "version: 1.0.0\n")
+	writeFile(t, filepath.Join(repoPath, "web", "package.json"), `{"name": "web", "dependencies": {"@acme/shared": "^1.0.0"}}`)
+	writeFile(t, filepath.Join(repoPath, "docs", "version.yml"), "version: 1.0.0\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "chore: add components")
+	base := shortHash(t, repoPath, "HEAD")
+	writeFile(t, filepath.Join(repoPath, "shared", "index.js"), "export {}\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "feat(shared)!: drop v1 api")
+	writeFile(t, filepath.Join(repoPath, "web", "index.js"), "export {}\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "feat(web)!: new layout")
+	breaking := shortHash(t, repoPath, "HEAD~1")
+
+	stdout, stderr, err := runCLI(repoPath, "monorepo-impact", "-c", "shared", "-r", "hash", "-s", base, "-e", "HEAD")
+	want := "shared: 1 breaking change(s), dependent components need a coordinated bump\n  " + breaking + " feat(shared)!: drop v1 api\n\nCOMPONENT  FILE              DEPENDENCY\nweb        web/package.json  @acme/shared\n"
+	if err != nil || stdout != want {
+		t.Fatalf("Run(monorepo-impact) = %q, error = %v, stderr = %q, want %q", stdout, err, stderr, want)
+	}
+
+	stdout, _, err = runCLI(repoPath, "monorepo-impact", "-c", "web", "--format", "json")
+	want = "{\n  \"component\": \"web\",\n  \"breakingChanges\": [\n    {\n      \"hash\": \"" + shortHash(t, repoPath, "HEAD") + "\",\n      \"subject\": \"feat(web)!: new layout\"\n    }\n  ],\n  \"dependents\": []\n}\n"
+	if err != nil || stdout != want {
+		t.Errorf("Run(monorepo-impact --format json) = %q, error = %v, want %q", stdout, err, want)
+	}
+}
+
+func Test_Run_Path(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	writeFile(t, filepath.Join(repoPath, "README.md"), "# repo\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "chore: init")
+	gitCmd("tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	writeFile(t, filepath.Join(repoPath, "pkg", "api", "api.go"), "package api\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "feat(api): add endpoint")
+	writeFile(t, filepath.Join(repoPath, "pkg", "web", "web.go"), "package web\n")
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "fix(web): layout")
+
+	tests := []struct {
+		args       []string
+		wantStdout string
+	}{
+		{[]string{"next-version"}, "1.1.0\n"},
+		{[]string{"next-version", "--path", "pkg/web"}, "1.0.1\n"},
+		{[]string{"next-version", "--path", "pkg/web", "--path", "pkg/api/"}, "1.1.0\n"},
+		{[]string{"next-version", "--path", "docs"}, "1.0.0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			stdout, stderr, err := runCLI(repoPath, tt.args...)
+			if err != nil || stdout != tt.wantStdout {
+				t.Errorf("Run(%v) = %q, error = %v, stderr = %q, want %q", tt.args, stdout, err, stderr, tt.wantStdout)
+			}
+		})
+	}
+
+	stdout, stderr, err := runCLI(repoPath, "commit-log", "--path", "pkg/api", "--path", "docs")
+	if err != nil || strings.Count(stdout, "\n") != 1 || !strings.Contains(stdout, "add endpoint") {
+		t.Errorf("Run(commit-log --path pkg/api) = %q, error = %v, want only the api commit", stdout, err)
+	}
+	if !strings.Contains(stderr, "path docs not found on HEAD") || strings.Contains(stderr, "pkg/api") {
+		t.Errorf("Run(commit-log --path pkg/api --path docs) stderr = %q, want warning only for docs", stderr)
+	}
+
+	stdout, _, err = runCLI(repoPath, "release-notes", "--path", "pkg/web")
+	if err != nil || !strings.Contains(stdout, "## v1.0.1") || !strings.Contains(stdout, "layout") || strings.Contains(stdout, "endpoint") {
+		t.Errorf("Run(release-notes --path pkg/web) = %q, error = %v, want only the web commit on v1.0.1", stdout, err)
+	}
+}
+
+func Test_Run_Style(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("commit", "--allow-empty", "-m", "feat(api): add endpoint")
+	gitCmd("tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	gitCmd("commit", "--allow-empty", "-m", "fix: handle timeout")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"release-notes", "--style", "checklist"}, "- [ ] fix: handle timeout (" + shortHash(t, repoPath, "HEAD") + ")\n"},
+		{[]string{"changelog", "--style", "checklist"}, "- [ ] feat(api): add endpoint (" + shortHash(t, repoPath, "v1.0.0^{}") + ")\n"},
+		{[]string{"release-notes", "--style", "default"}, "- handle timeout (" + shortHash(t, repoPath, "HEAD") + ")\n"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			stdout, stderr, err := runCLI(repoPath, tt.args...)
+			if err != nil || !strings.Contains(stdout, tt.want) {
+				t.Errorf("Run(%v) = %q, error = %v, stderr = %q, want line %q", tt.args, stdout, err, stderr, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := runCLI(repoPath, "release-notes", "--style", "todo"); err == nil || !strings.Contains(err.Error(), "invalid style") {
+		t.Errorf("Run(release-notes --style todo) error = %v, want invalid style", err)
+	}
+}
+
+func Test_Run_Doctor(t *testing.T) {
+	gitCmd, repoPath := setupRunRepo(t)
+	gitCmd("tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	writeFile(t, filepath.Join(repoPath, ".git", "hooks", "prepare-commit-msg"), "#!/bin/sh\ngit sv vcm --path \"$(pwd)\" --file \"$1\" --source \"$2\"\n")
+	if err := os.Chmod(filepath.Join(repoPath, ".git", "hooks", "prepare-commit-msg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, err := runCLI(repoPath, "--no-color", "doctor")
+	if err != nil {
+		t.Fatalf("Run(doctor) error = %v, stderr = %q", err, stderr)
+	}
+	for _, want := range []string{"PASS repository: ", "WARN config: no config file found", "PASS tags: 1 release tag(s), last tag v1.0.0", "PASS hook: "} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Run(doctor) = %q, want %q", stdout, want)
+		}
+	}
+
+	writeFile(t, filepath.Join(repoPath, ".sv4git.yml"), "versioning:\n  ignore-paths: ['[']\n")
+	stdout, _, err = runCLI(repoPath, "--no-color", "doctor")
+	if err == nil || !strings.Contains(stdout, "FAIL config: invalid versioning config") || !strings.Contains(stdout, "PASS tags: ") {
+		t.Errorf("Run(doctor) with invalid config = %q, error = %v, want config failure and other checks", stdout, err)
+	}
+	if _, _, err := runCLI(repoPath, "next-version"); err == nil || !strings.Contains(err.Error(), "invalid versioning config") {
+		t.Errorf("Run(next-version) with invalid config error = %v, want invalid versioning config", err)
+	}
+
+	stdout, _, err = runCLI(t.TempDir(), "--no-color", "doctor")
+	if err == nil || !strings.Contains(stdout, "FAIL repository: ") || strings.Contains(stdout, "tags:") {
+		t.Errorf("Run(doctor) outside repository = %q, error = %v, want repository failure only", stdout, err)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"gopkg.in/yaml.v3"
+)
+
+// v1 was configured by SV4GIT_ prefixed environment variables, legacy config files define them one per line, e.g.:
+//
+//	SV4GIT_MINOR_VERSION_TYPES=feat
+//	export SV4GIT_RELEASE_NOTES_TAGS="fix:Bug Fixes,feat:Features"
+const legacyConfigPrefix = "SV4GIT_"
+
+// legacySetting maps a v1 setting to current config, path is empty if the setting no longer exists.
+type legacySetting struct {
+	path  string
+	note  string // semantics change, added to translation table
+	apply func(cfg *Config, value string) error
+}
+
+var legacySettings = map[string]legacySetting{
+	"MAJOR_VERSION_TYPES": {path: "versioning.update-major", apply: func(cfg *Config, value string) error {
+		cfg.Versioning.UpdateMajor = splitList(value)
+		return nil
+	}},
+	"MINOR_VERSION_TYPES": {path: "versioning.update-minor", apply: func(cfg *Config, value string) error {
+		cfg.Versioning.UpdateMinor = splitList(value)
+		return nil
+	}},
+	"PATCH_VERSION_TYPES": {path: "versioning.update-patch", apply: func(cfg *Config, value string) error {
+		cfg.Versioning.UpdatePatch = splitList(value)
+		return nil
+	}},
+	"INCLUDE_UNKNOWN_TYPE_AS_PATCH": {path: "versioning.ignore-unknown", note: "inverted, true became false", apply: func(cfg *Config, value string) error {
+		include, err := strconv.ParseBool(value)
+		cfg.Versioning.IgnoreUnknown = !include
+		return err
+	}},
+	"BREAKING_CHANGE_PREFIXES": {note: "removed, BREAKING CHANGE and BREAKING CHANGES footers and ! after type are used"},
+	"BRAKING_CHANGE_PREFIXES":  {note: "removed, BREAKING CHANGE and BREAKING CHANGES footers and ! after type are used"},
+	"ISSUEID_PREFIXES": {path: "commit-message.footer.issue.key-synonyms", note: "only used to read footers, new footers use the key", apply: func(cfg *Config, value string) error {
+		footer := issueFooter(cfg)
+		footer.KeySynonyms = splitList(value)
+		cfg.CommitMessage.Footer["issue"] = footer
+		return nil
+	}},
+	"TAG_PATTERN": {path: "tag.pattern", apply: func(cfg *Config, value string) error {
+		cfg.Tag.Pattern = &value
+		return nil
+	}},
+	"RELEASE_NOTES_TAGS": {path: "release-notes.sections", note: "type:title pairs became ordered sections, a Breaking Changes section is added", apply: func(cfg *Config, value string) error {
+		for _, item := range splitList(value) {
+			commitType, name, found := strings.Cut(item, ":")
+			if !found || strings.TrimSpace(commitType) == "" {
+				return fmt.Errorf("invalid release notes tag %s, expected type:title", item)
+			}
+			cfg.ReleaseNotes.Sections = append(cfg.ReleaseNotes.Sections, sv.ReleaseNotesSectionConfig{
+				Name: strings.TrimSpace(name), SectionType: sv.ReleaseNotesSectionTypeCommits, CommitTypes: []string{strings.TrimSpace(commitType)}})
+		}
+		cfg.ReleaseNotes.Sections = append(cfg.ReleaseNotes.Sections, sv.ReleaseNotesSectionConfig{
+			Name: "Breaking Changes", SectionType: sv.ReleaseNotesSectionTypeBreakingChanges})
+		return nil
+	}},
+	"VALIDATE_MESSAGE_SKIP_BRANCHES": {path: "branches.skip", apply: func(cfg *Config, value string) error {
+		cfg.Branches.Skip = splitList(value)
+		return nil
+	}},
+	"COMMIT_MESSAGE_TYPES": {path: "commit-message.types", apply: func(cfg *Config, value string) error {
+		cfg.CommitMessage.Types = splitList(value)
+		return nil
+	}},
+	"ISSUE_KEY_NAME": {path: "commit-message.footer.issue.key", apply: func(cfg *Config, value string) error {
+		footer := issueFooter(cfg)
+		footer.Key = value
+		cfg.CommitMessage.Footer["issue"] = footer
+		return nil
+	}},
+	"ISSUE_REGEX": {path: "commit-message.issue.regex", apply: func(cfg *Config, value string) error {
+		cfg.CommitMessage.Issue.Regex = value
+		return nil
+	}},
+	"BRANCH_ISSUE_PREFIX_REGEX": {path: "branches.prefix", apply: func(cfg *Config, value string) error {
+		cfg.Branches.Prefix = value
+		return nil
+	}},
+	"BRANCH_ISSUE_SUFFIX_REGEX": {path: "branches.suffix", apply: func(cfg *Config, value string) error {
+		cfg.Branches.Suffix = value
+		return nil
+	}},
+}
+
+func issueFooter(cfg *Config) sv.CommitMessageFooterConfig {
+	if cfg.CommitMessage.Footer == nil {
+		cfg.CommitMessage.Footer = make(map[string]sv.CommitMessageFooterConfig)
+	}
+	return cfg.CommitMessage.Footer["issue"]
+}
+
+// splitList splits comma separated values, spaces are trimmed and empty values removed.
+func splitList(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// migrateLegacyConfig converts v1 settings to a yaml config with only the migrated fields, settings with changed semantics are
+// listed on a translation table on the header comment. Returns warnings for settings that no longer exist or are unknown.
+func migrateLegacyConfig(content []byte, filename string) (string, []string, error) {
+	cfg := Config{Version: defaultConfig().Version}
+	paths := make(map[string]bool)
+	var warnings []string
+	var table [][3]string
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !found {
+			return "", nil, fmt.Errorf("invalid v1 setting on line %d: %s, expected KEY=value", i+1, line)
+		}
+		key = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(key)), legacyConfigPrefix)
+		value = unquoteLegacyValue(strings.TrimSpace(value))
+
+		setting, ok := legacySettings[key]
+		switch {
+		case !ok:
+			warnings = append(warnings, fmt.Sprintf("unknown v1 setting %s%s on line %d ignored", legacyConfigPrefix, key, i+1))
+			continue
+		case setting.path == "":
+			warnings = append(warnings, fmt.Sprintf("v1 setting %s%s no longer exists and was not migrated: %s", legacyConfigPrefix, key, setting.note))
+			table = append(table, [3]string{legacyConfigPrefix + key, "-", setting.note})
+			continue
+		}
+		if err := setting.apply(&cfg, value); err != nil {
+			return "", nil, fmt.Errorf("invalid value for v1 setting %s%s on line %d, message: %v", legacyConfigPrefix, key, i+1, err)
+		}
+		paths[setting.path] = true
+		if setting.note != "" {
+			table = append(table, [3]string{legacyConfigPrefix + key, setting.path, setting.note})
+		}
+	}
+
+	var node yaml.Node
+	if err := node.Encode(&cfg); err != nil {
+		return "", nil, err
+	}
+	paths["version"] = true
+	pruneConfigNode(&node, "", paths)
+	node.HeadComment = legacyHeaderComment(filename, table)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(4)
+	if err := encoder.Encode(&node); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), warnings, encoder.Close()
+}
+
+func unquoteLegacyValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// pruneConfigNode removes mapping entries not in paths and not parents of them.
+func pruneConfigNode(node *yaml.Node, prefix string, paths map[string]bool) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	var content []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		path := prefix + node.Content[i].Value
+		if paths[path] {
+			content = append(content, node.Content[i], node.Content[i+1])
+			continue
+		}
+		for p := range paths {
+			if strings.HasPrefix(p, path+".") {
+				pruneConfigNode(node.Content[i+1], path+".", paths)
+				content = append(content, node.Content[i], node.Content[i+1])
+				break
+			}
+		}
+	}
+	node.Content = content
+}
+
+func legacyHeaderComment(filename string, table [][3]string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "migrated from v1 config %s, settings not defined use default config values", filename)
+	if len(table) > 0 {
+		rows := append([][3]string{{"v1 setting", "setting", "change"}}, table...)
+		var widths [2]int
+		for _, row := range rows {
+			for i := range widths {
+				if len(row[i]) > widths[i] {
+					widths[i] = len(row[i])
+				}
+			}
+		}
+		sb.WriteString("\n\nsettings with changed semantics:")
+		for _, row := range rows {
+			fmt.Fprintf(&sb, "\n%-*s | %-*s | %s", widths[0], row[0], widths[1], row[1], row[2])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_migrateLegacyConfig(t *testing.T) {
+	content := `# v1 settings
+SV4GIT_MINOR_VERSION_TYPES=feat, refactor
+export SV4GIT_RELEASE_NOTES_TAGS="fix:Bug Fixes,feat:Features"
+SV4GIT_INCLUDE_UNKNOWN_TYPE_AS_PATCH=false
+SV4GIT_BRAKING_CHANGE_PREFIXES=BREAKING CHANGE
+ISSUE_KEY_NAME='jira'
+SV4GIT_UNKNOWN=1
+`
+	want := `# migrated from v1 config v1.env, settings not defined use default config values
+
+# settings with changed semantics:
+# v1 setting                           | setting                   | change
+# SV4GIT_RELEASE_NOTES_TAGS            | release-notes.sections    | type:title pairs became ordered sections, a Breaking Changes section is added
+# SV4GIT_INCLUDE_UNKNOWN_TYPE_AS_PATCH | versioning.ignore-unknown | inverted, true became false
+# SV4GIT_BRAKING_CHANGE_PREFIXES       | -                         | removed, BREAKING CHANGE and BREAKING CHANGES footers and ! after type are used
+version: "1.1"
+versioning:
+    update-minor: [feat, refactor]
+    ignore-unknown: true
+release-notes:
+    sections:
+        - name: Bug Fixes
+          section-type: commits
+          commit-types: [fix]
+        - name: Features
+          section-type: commits
+          commit-types: [feat]
+        - name: Breaking Changes
+          section-type: breaking-changes
+commit-message:
+    footer:
+        issue:
+            key: jira
+`
+	wantWarnings := []string{
+		"v1 setting SV4GIT_BRAKING_CHANGE_PREFIXES no longer exists and was not migrated: removed, BREAKING CHANGE and BREAKING CHANGES footers and ! after type are used",
+		"unknown v1 setting SV4GIT_UNKNOWN on line 7 ignored",
+	}
+
+	got, warnings, err := migrateLegacyConfig([]byte(content), "v1.env")
+	if err != nil {
+		t.Fatalf("migrateLegacyConfig() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("migrateLegacyConfig() = %s, want %s", got, want)
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("migrateLegacyConfig() warnings = %v, want %v", warnings, wantWarnings)
+	}
+
+	cfg, err := decodeConfig([]byte(got), configFormatYAML)
+	if err != nil {
+		t.Fatalf("decodeConfig() error = %v", err)
+	}
+	if !cfg.Versioning.IgnoreUnknown || cfg.CommitMessage.Footer["issue"].Key != "jira" || len(cfg.ReleaseNotes.Sections) != 3 {
+		t.Errorf("decodeConfig() of migrated config = %+v", cfg)
+	}
+}
+
+func Test_migrateLegacyConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"not key value", "version: 1.1", "line 1"},
+		{"invalid bool", "\nSV4GIT_INCLUDE_UNKNOWN_TYPE_AS_PATCH=maybe", "SV4GIT_INCLUDE_UNKNOWN_TYPE_AS_PATCH on line 2"},
+		{"invalid release notes tag", "SV4GIT_RELEASE_NOTES_TAGS=fix", "expected type:title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := migrateLegacyConfig([]byte(tt.content), "v1.env"); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("migrateLegacyConfig() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
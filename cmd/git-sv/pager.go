@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+)
+
+const defaultPager = "less -R"
+
+// pagerCommand returns the pager for command results, like git it uses GIT_PAGER or PAGER,
+// empty if stdout is not a terminal or the pager is "cat".
+func (e environment) pagerCommand() string {
+	if !e.stdoutTerminal {
+		return ""
+	}
+	pager := defaultPager
+	for _, key := range []string{"GIT_PAGER", "PAGER"} {
+		if value := strings.TrimSpace(e.getenv(key)); value != "" {
+			pager = value
+			break
+		}
+	}
+	if pager == "cat" {
+		return ""
+	}
+	return pager
+}
+
+// withPager pipes the command result through the pager, unless disabled by --no-pager or written to a file by --output.
+func withPager(out *printer, action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.Bool("no-pager") || c.String("output") != "" {
+			return action(c)
+		}
+		return runPaged(out, currentEnvironment(out.stdout).pagerCommand(), func() error { return action(c) })
+	}
+}
+
+// shellMetaChars characters that make git run a command through the shell, other commands are executed directly.
+const shellMetaChars = "|&;<>()$`\\\"' \t\n*?[#~=%"
+
+// pagerShellCommand returns the command running pager like git does: through the shell when it has arguments, quotes, env
+// assignments or pipes, e.g. "less -R" or "LESS=S less", executed directly otherwise.
+func pagerShellCommand(pager string) *exec.Cmd {
+	if strings.ContainsAny(pager, shellMetaChars) {
+		return shellCommand(pager)
+	}
+	return exec.Command(pager) //nolint:gosec
+}
+
+// runPaged runs fn with stdout of out redirected to pager, fn runs without pager if pager is empty or could not be started.
+func runPaged(out *printer, pager string, fn func() error) error {
+	pager = strings.TrimSpace(pager)
+	if pager == "" {
+		return fn()
+	}
+	cmd := pagerShellCommand(pager)
+	cmd.Stdout = out.stdout
+	cmd.Stderr = out.stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// same default as git, quit if output fits on one screen, keep colors and do not clear the screen.
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fn()
+	}
+	if err := cmd.Start(); err != nil {
+		out.debugf("could not start pager %s, error: %v", pager, err)
+		return fn()
+	}
+
+	stdout := out.stdout
+	out.stdout = stdin
+	err = fn()
+	out.stdout = stdout
+	stdin.Close()
+	_ = cmd.Wait() // pager exit status is ignored, e.g. less closed before the end of the output
+
+	if errors.Is(err, syscall.EPIPE) {
+		return nil // pager closed before the end of the output
+	}
+	return err
+}
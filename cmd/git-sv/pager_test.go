@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_environment_pagerCommand(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		stdoutTerminal bool
+		want           string
+	}{
+		{"default", nil, true, "less -R"},
+		{"stdout not terminal", map[string]string{"PAGER": "more"}, false, ""},
+		{"pager", map[string]string{"PAGER": "more"}, true, "more"},
+		{"git pager first", map[string]string{"GIT_PAGER": "delta", "PAGER": "more"}, true, "delta"},
+		{"cat disables pager", map[string]string{"GIT_PAGER": "cat"}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := environment{getenv: envFrom(tt.env), stdoutTerminal: tt.stdoutTerminal}
+			if got := env.pagerCommand(); got != tt.want {
+				t.Errorf("pagerCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runPaged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paged")
+	out, stdout := newTestPrinter()
+	err := runPaged(out, "cp /dev/stdin "+path, func() error {
+		out.println("## v1.0.0")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runPaged() unexpected error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "## v1.0.0\n" {
+		t.Errorf("runPaged() pager input = %q, want %q", got, "## v1.0.0\n")
+	}
+	if stdout.Len() != 0 || out.stdout != stdout {
+		t.Errorf("runPaged() stdout = %q, want output only on pager and stdout restored", stdout.String())
+	}
+}
+
+func Test_runPaged_Shell(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paged output")
+	tests := []struct {
+		name  string
+		pager string
+		want  string
+	}{
+		{"quoted arguments", "tr '[:lower:]' '[:upper:]' > '" + path + "'", "## V1.0.0\n"},
+		{"env assignment and pipe", "SUFFIX=end; cat | sed \"s/\\$/ $SUFFIX/\" > '" + path + "'", "## v1.0.0 end\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, stdout := newTestPrinter()
+			if err := runPaged(out, tt.pager, func() error { out.println("## v1.0.0"); return nil }); err != nil {
+				t.Fatalf("runPaged() unexpected error: %v", err)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.want || stdout.Len() != 0 {
+				t.Errorf("runPaged() pager output = %q, stdout = %q, want %q only on pager", got, stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_runPaged_PagerClosed(t *testing.T) {
+	out, _ := newTestPrinter()
+	err := runPaged(out, "true", func() error {
+		line := strings.Repeat("x", 1024) + "\n"
+		for i := 0; i < 1024; i++ {
+			if _, err := out.stdout.Write([]byte(line)); err != nil {
+				return err
+			}
+		}
+		return errors.New("unreachable, pager input should be closed")
+	})
+	if err != nil {
+		t.Errorf("runPaged() error = %v, want nil when pager is closed early", err)
+	}
+}
+
+func Test_runPaged_WithoutPager(t *testing.T) {
+	out, stdout := newTestPrinter()
+	for _, pager := range []string{"", "sv4git-missing-pager"} {
+		stdout.Reset()
+		if err := runPaged(out, pager, func() error { out.println("content"); return nil }); err != nil {
+			t.Fatalf("runPaged(%q) unexpected error: %v", pager, err)
+		}
+		if stdout.String() != "content\n" {
+			t.Errorf("runPaged(%q) stdout = %q, want content printed directly", pager, stdout.String())
+		}
+	}
+}
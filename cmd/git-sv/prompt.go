@@ -2,19 +2,24 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"reflect"
 	"regexp"
 
 	"github.com/manifoldco/promptui"
 )
 
+func promptsDisabledErr(label string, out *printer) error {
+	return fmt.Errorf("could not prompt for %s: %s, use command flags to set the value or --interactive to force prompts", label, out.promptsDisabled)
+}
+
 type commitType struct {
 	Type        string
 	Description string
 	Example     string
 }
 
-func promptType(types []string) (commitType, error) {
+func promptType(types []string, out *printer) (commitType, error) {
 	defaultTypes := map[string]commitType{
 		"build":    {Type: "build", Description: "changes that affect the build system or external dependencies", Example: "gradle, maven, go mod, npm"},
 		"ci":       {Type: "ci", Description: "changes to our CI configuration files and scripts", Example: "Circle, BrowserStack, SauceLabs"},
@@ -49,44 +54,59 @@ func promptType(types []string) (commitType, error) {
 {{ "Example:" | faint }}	{{ .Example }}`,
 	}
 
-	i, err := promptSelect("type", items, template)
+	i, err := promptSelect("type", items, template, out)
 	if err != nil {
 		return commitType{}, err
 	}
 	return items[i], nil
 }
 
-func promptScope(values []string) (string, error) {
+func promptScope(values []string, required bool, out *printer) (string, error) {
 	if len(values) > 0 {
-		selected, err := promptSelect("scope", values, nil)
+		selected, err := promptSelect("scope", values, nil, out)
 		if err != nil {
 			return "", err
 		}
 		return values[selected], nil
 	}
-	return promptText("scope", "^[a-z0-9-]*$", "")
+	if required {
+		return promptText("scope", "^[a-z0-9-]+$", "", out)
+	}
+	return promptText("scope", "^[a-z0-9-]*$", "", out)
+}
+
+func promptSubject(out *printer) (string, error) {
+	return promptText("subject", "^[a-z].+$", "", out)
 }
 
-func promptSubject() (string, error) {
-	return promptText("subject", "^[a-z].+$", "")
+func promptBody(out *printer) (string, error) {
+	return promptText("body (leave empty to finish)", "^.*$", "", out)
 }
 
-func promptBody() (string, error) {
-	return promptText("body (leave empty to finish)", "^.*$", "")
+func promptIssueID(issueLabel, issueRegex, defaultValue string, out *printer) (string, error) {
+	return promptText(issueLabel, "^("+issueRegex+")?$", defaultValue, out)
 }
 
-func promptIssueID(issueLabel, issueRegex, defaultValue string) (string, error) {
-	return promptText(issueLabel, "^("+issueRegex+")?$", defaultValue)
+// promptIssueIDs asks for issue ids separated by comma, at least one is expected if required.
+func promptIssueIDs(issueLabel, issueRegex, defaultValue string, required bool, out *printer) (string, error) {
+	list := fmt.Sprintf(`(%s)(\s*,\s*(%s))*`, issueRegex, issueRegex)
+	if !required {
+		list = "(" + list + ")?"
+	}
+	return promptText(issueLabel, `^\s*`+list+`\s*$`, defaultValue, out)
 }
 
-func promptBreakingChanges() (string, error) {
-	return promptText("Breaking change description", "[a-z].+", "")
+func promptBreakingChanges(out *printer) (string, error) {
+	return promptText("Breaking change description", "[a-z].+", "", out)
 }
 
-func promptSelect(label string, items interface{}, template *promptui.SelectTemplates) (int, error) {
+func promptSelect(label string, items interface{}, template *promptui.SelectTemplates, out *printer) (int, error) {
 	if items == nil || reflect.TypeOf(items).Kind() != reflect.Slice {
 		return 0, fmt.Errorf("items %v is not a slice", items)
 	}
+	if out.promptsDisabled != "" {
+		return 0, promptsDisabledErr(label, out)
+	}
 
 	prompt := promptui.Select{
 		Label:     label,
@@ -99,7 +119,11 @@ func promptSelect(label string, items interface{}, template *promptui.SelectTemp
 	return index, err
 }
 
-func promptText(label, regex, defaultValue string) (string, error) {
+func promptText(label, regex, defaultValue string, out *printer) (string, error) {
+	if out.promptsDisabled != "" {
+		return "", promptsDisabledErr(label, out)
+	}
+
 	validate := func(input string) error {
 		regex := regexp.MustCompile(regex)
 		if !regex.MatchString(input) {
@@ -117,10 +141,34 @@ func promptText(label, regex, defaultValue string) (string, error) {
 	return prompt.Run()
 }
 
-func promptConfirm(label string) (bool, error) {
-	r, err := promptText(label+" [y/n]", "^y|n$", "")
+// promptConfirm asks a yes/no question, answers no when prompts are disabled.
+func promptConfirm(label string, out *printer) (bool, error) {
+	if out.promptsDisabled != "" {
+		return false, nil
+	}
+	r, err := promptText(label+" [y/n]", "^y|n$", "", out)
 	if err != nil {
 		return false, err
 	}
 	return r == "y", nil
 }
+
+// promptConfirmDefaultYes asks a yes/no question, answers yes when prompts are disabled.
+func promptConfirmDefaultYes(label string, out *printer) (bool, error) {
+	if out.promptsDisabled != "" {
+		return true, nil
+	}
+	r, err := promptText(label+" [Y/n]", "^(y|n)?$", "", out)
+	if err != nil {
+		return false, err
+	}
+	return r != "n", nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
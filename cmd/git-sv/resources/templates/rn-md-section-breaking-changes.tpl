@@ -1,7 +0,0 @@
-{{- if ne .Name ""}}
-
-### {{.Name}}
-{{range $k,$v := .Messages}}
-- {{$v}}
-{{- end}}
-{{- end}}
\ No newline at end of file
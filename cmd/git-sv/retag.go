@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// retagTarget an existing release tag and the annotation message it is recreated with.
+type retagTarget struct {
+	tag       sv.GitTag
+	commit    string // full hash of the tagged commit, it must not change
+	converted bool   // lightweight tag replaced by an annotated tag
+	message   string
+}
+
+// retagHandler recreates existing release tags as annotated tags with the release notes of their range as message, on the
+// same commit, and force-pushes them. Without --yes the new messages are only printed.
+func retagHandler(git sv.Git, monorepoProcessor sv.MonorepoProcessor, rnProcessor sv.ReleaseNoteProcessor, outputFormatter sv.OutputFormatter, cfg Config, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		names, all := c.StringSlice("t"), c.Bool("all-semver")
+		if len(names) == 0 && !all {
+			return fmt.Errorf("use --tag to select the tags to recreate or --all-semver to recreate every release tag")
+		}
+		if len(names) > 0 && all {
+			return fmt.Errorf("use --tag or --all-semver, not both")
+		}
+
+		notes, err := retagNotes(c, git, monorepoProcessor, repoPath, cfg)
+		if err != nil {
+			return err
+		}
+		selected, err := notes.selectTags(names, all)
+		if err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			out.infof("no release tags found")
+			return nil
+		}
+
+		remote, err := getTagRemote(git, c)
+		if err != nil {
+			return err
+		}
+
+		targets := make([]retagTarget, 0, len(selected))
+		for _, index := range selected {
+			tag := notes.tags[index]
+			commit, annotated, err := git.TagTarget(tag.Name)
+			if err != nil {
+				return fmt.Errorf("error reading tag %s, message: %v", tag.Name, err)
+			}
+			if !annotated && !c.Bool("convert") {
+				return fmt.Errorf("tag %s is a lightweight tag, use --convert to replace it with an annotated tag", tag.Name)
+			}
+			releasenote, err := notes.releaseNote(rnProcessor, index)
+			if err != nil {
+				return err
+			}
+			message, err := outputFormatter.FormatReleaseNote(releasenote)
+			if err != nil {
+				return fmt.Errorf("could not format release notes of tag %s, message: %v", tag.Name, err)
+			}
+			targets = append(targets, retagTarget{tag: tag, commit: commit, converted: !annotated, message: message})
+		}
+
+		if !c.Bool("yes") {
+			for _, target := range targets {
+				out.printf("tag %s on commit %s:\n\n%s\n\n", target.tag.Name, shortCommit(target.commit), target.message)
+			}
+			out.statusf("%d tag(s) not changed, use --yes to recreate them%s", len(targets), forcePushHint(remote))
+			return nil
+		}
+
+		retagged := make([]string, 0, len(targets))
+		for _, target := range targets {
+			if err := retag(git, target); err != nil {
+				return retagError(err, retagged, remote)
+			}
+			if target.converted {
+				out.statusf("lightweight tag %s converted to an annotated tag", target.tag.Name)
+			}
+			out.successf("%s", target.tag.Name)
+			retagged = append(retagged, target.tag.Name)
+		}
+		if remote == "" {
+			return nil
+		}
+		if err := git.ForcePushTags(remote, retagged); err != nil {
+			return fmt.Errorf("error pushing tags to %s, message: %v, push them with: git push --force %s %s", remote, err, remote, strings.Join(retagged, " "))
+		}
+		return nil
+	}
+}
+
+// retag recreates the tag of target with its message, the tagged commit is verified before and after the tag is replaced.
+func retag(git sv.Git, target retagTarget) error {
+	before, _, err := git.TagTarget(target.tag.Name)
+	if err != nil {
+		return fmt.Errorf("error reading tag %s, message: %v", target.tag.Name, err)
+	}
+	if before != target.commit {
+		return fmt.Errorf("tag %s moved from commit %s to %s while its release notes were generated, run retag again", target.tag.Name, shortCommit(target.commit), shortCommit(before))
+	}
+	if err := git.ReplaceTag(target.tag.Name, target.message, target.commit, target.tag.Date); err != nil {
+		return fmt.Errorf("error recreating tag %s, message: %v", target.tag.Name, err)
+	}
+	after, _, err := git.TagTarget(target.tag.Name)
+	if err != nil {
+		return fmt.Errorf("error reading tag %s, message: %v", target.tag.Name, err)
+	}
+	if after != target.commit {
+		return fmt.Errorf("tag %s points to commit %s after it was recreated instead of %s, restore it with: git tag -f %s %s", target.tag.Name, shortCommit(after), shortCommit(target.commit), target.tag.Name, target.commit)
+	}
+	return nil
+}
+
+// retagError adds the tags already recreated locally to err, they are not pushed yet.
+func retagError(err error, retagged []string, remote string) error {
+	if len(retagged) == 0 || remote == "" {
+		return err
+	}
+	return fmt.Errorf("%v, tags already recreated locally are not pushed, push them with: git push --force %s %s", err, remote, strings.Join(retagged, " "))
+}
+
+func forcePushHint(remote string) string {
+	if remote == "" {
+		return ""
+	}
+	return " and force-push them to " + remote
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// retagReleaseNotes creates release notes of release tags or of the tags of a monorepo component, see --component.
+type retagReleaseNotes struct {
+	git             sv.Git
+	tags            []sv.GitTag // sorted by creation date, the previous tag of a tag is the one before it
+	component       *sv.MonorepoComponent
+	repoPath        string
+	roots           map[string]string // component roots, only to detect shared commits
+	includeMetadata bool
+}
+
+func retagNotes(c *cli.Context, git sv.Git, monorepoProcessor sv.MonorepoProcessor, repoPath string, cfg Config) (retagReleaseNotes, error) {
+	notes := retagReleaseNotes{git: git, repoPath: repoPath, includeMetadata: c.Bool("include-metadata")}
+	name := c.String("component")
+	if name == "" {
+		tags, err := git.Tags()
+		if err != nil {
+			return notes, fmt.Errorf("error listing tags, message: %v", err)
+		}
+		notes.tags = tags
+		return notes, nil
+	}
+
+	components, skipped, err := monorepoProcessor.FindComponents(repoPath, cfg.Monorepo)
+	if err != nil {
+		return notes, fmt.Errorf("error finding monorepo components: %v", err)
+	}
+	component, found := findComponent(name, components)
+	if !found && len(skipped) > 0 {
+		return notes, fmt.Errorf("component: %s not found, %d component(s) skipped due to invalid versioning files", name, len(skipped))
+	}
+	if !found {
+		return notes, fmt.Errorf("component: %s not found", name)
+	}
+	tags, err := git.TagsAll()
+	if err != nil {
+		return notes, fmt.Errorf("error listing tags, message: %v", err)
+	}
+	notes.tags, notes.component = filterComponentTags(tags, component), &component
+	if cfg.ReleaseNotes.DetectSharedCommits {
+		if notes.roots, err = componentRoots(repoPath, components); err != nil {
+			return notes, err
+		}
+	}
+	return notes, nil
+}
+
+// selectTags returns the indexes of names on tags, or of every tag with a semantic version if all is set.
+func (n retagReleaseNotes) selectTags(names []string, all bool) ([]int, error) {
+	var selected []int
+	if all {
+		for i, tag := range n.tags {
+			if n.version(tag.Name) != nil {
+				selected = append(selected, i)
+			}
+		}
+		return selected, nil
+	}
+	for _, name := range names {
+		index := find(name, n.tags)
+		if index < 0 && n.component != nil {
+			return nil, fmt.Errorf("tag: %s not found for component %s", name, n.component.Name)
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("tag: %s not found, check tag filter", name)
+		}
+		selected = append(selected, index)
+	}
+	return selected, nil
+}
+
+func (n retagReleaseNotes) version(tag string) *semver.Version {
+	if n.component != nil {
+		version, err := sv.ToVersion(sv.ComponentTagVersion(tag))
+		if err != nil {
+			return nil
+		}
+		return version
+	}
+	if !sv.IsValidVersion(tag) {
+		return nil
+	}
+	return tagVersion(tag, n.includeMetadata)
+}
+
+// releaseNote creates the release notes of the tag at index, with commits since the previous tag.
+func (n retagReleaseNotes) releaseNote(rnProcessor sv.ReleaseNoteProcessor, index int) (sv.ReleaseNote, error) {
+	tag := n.tags[index]
+	if n.component != nil {
+		version, previousTag, date, commits, err := getComponentTagVersionInfo(n.git, n.repoPath, *n.component, tag.Name, n.tags, n.roots != nil)
+		if err != nil {
+			return sv.ReleaseNote{}, err
+		}
+		if n.roots != nil {
+			commits = sv.AnnotateSharedCommits(commits, n.component.Name, n.roots)
+		}
+		return rnProcessor.Create(version, tag.Name, previousTag, date, commits), nil
+	}
+
+	previousTag := ""
+	if index > 0 {
+		previousTag = n.tags[index-1].Name
+	}
+	commits, err := n.git.Log(sv.NewLogRange(sv.TagRange, previousTag, tag.Name))
+	if err != nil {
+		return sv.ReleaseNote{}, fmt.Errorf("error getting git log from tag: %s, message: %v", tag.Name, err)
+	}
+	return rnProcessor.Create(n.version(tag.Name), tag.Name, previousTag, tag.Date, commits), nil
+}
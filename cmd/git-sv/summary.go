@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bvieira/sv4git/v2/sv"
+	"gopkg.in/yaml.v3"
+)
+
+// status of each component on monorepo-tag summary.
+const (
+	tagStatusUnchanged = "unchanged"
+	tagStatusTagged    = "tagged"
+	tagStatusExists    = "exists"
+	tagStatusFailed    = "failed"
+)
+
+// tagSummary is written by monorepo-tag --summary-file, deployment jobs read it to know which components were released.
+type tagSummary struct {
+	Timestamp  time.Time             `json:"timestamp" yaml:"timestamp"`
+	Head       string                `json:"head" yaml:"head"`
+	Partial    bool                  `json:"partial" yaml:"partial"` // true if the command failed, components may be missing
+	Error      string                `json:"error,omitempty" yaml:"error,omitempty"`
+	Components []componentTagSummary `json:"components" yaml:"components"`
+}
+
+type componentTagSummary struct {
+	Name            string `json:"name" yaml:"name"`
+	PreviousVersion string `json:"previousVersion" yaml:"previous-version"`
+	NewVersion      string `json:"newVersion,omitempty" yaml:"new-version,omitempty"`
+	Tag             string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	From            string `json:"from,omitempty" yaml:"from,omitempty"` // commit of the previous component tag, empty on first release
+	To              string `json:"to,omitempty" yaml:"to,omitempty"`     // tagged commit
+	FileUpdated     bool   `json:"fileUpdated" yaml:"file-updated"`
+	Status          string `json:"status" yaml:"status"`
+	Error           string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// writeTagSummary writes summary to path with HEAD commit and current time from clock, cmdErr marks the summary as partial.
+func writeTagSummary(git sv.Git, clock sv.Clock, summary tagSummary, path string, cmdErr error) error {
+	summary.Timestamp = clock.Now().UTC().Truncate(time.Second)
+	head, err := git.ShortHash("HEAD")
+	if err != nil {
+		return fmt.Errorf("error writing summary file %s, message: %v", path, err)
+	}
+	summary.Head = head
+	if cmdErr != nil {
+		summary.Partial, summary.Error = true, cmdErr.Error()
+	}
+	content, err := encodeTagSummary(summary, path)
+	if err != nil {
+		return fmt.Errorf("error writing summary file %s, message: %v", path, err)
+	}
+	return writeFileAtomic(path, content)
+}
+
+// previousTagCommit returns the commit of the latest tag, empty if there is no tag.
+func previousTagCommit(git sv.Git, tags []sv.GitTag) (string, error) {
+	lastTag := sv.LatestTag(tags)
+	if lastTag == "" {
+		return "", nil
+	}
+	commit, err := git.ShortHash("refs/tags/" + lastTag)
+	if err != nil {
+		return "", fmt.Errorf("error getting commit of tag %s, message: %v", lastTag, err)
+	}
+	return commit, nil
+}
+
+// checkSummaryFile validates summary file extension, json or yaml.
+func checkSummaryFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yml", ".yaml":
+		return nil
+	}
+	return fmt.Errorf("invalid summary file %s, use .json, .yml or .yaml extension", path)
+}
+
+func encodeTagSummary(summary tagSummary, path string) ([]byte, error) {
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		content, err := json.MarshalIndent(summary, "", "  ")
+		return append(content, '\n'), err
+	}
+	return yaml.Marshal(summary)
+}
+
+// writeFileAtomic writes content on a temporary file in the same directory and renames it to path,
+// readers never see a partially written file.
+func writeFileAtomic(path string, content []byte) error {
+	return streamFileAtomic(path, 0644, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	})
+}
+
+// streamFileAtomic is writeFileAtomic for content written by write, e.g. a changelog formatted one release at a time.
+func streamFileAtomic(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating directory for %s, message: %v", path, err)
+	}
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating file %s, message: %v", path, err)
+	}
+	defer os.Remove(file.Name()) // no-op after rename
+
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing file %s, message: %v", path, err)
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing file %s, message: %v", path, err)
+	}
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing file %s, message: %v", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing file %s, message: %v", path, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("error writing file %s, message: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ciEnvVars environment variables defined by common CI services.
+var ciEnvVars = []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "JENKINS_URL"}
+
+// environment execution environment used to decide if interactive prompts can be used.
+type environment struct {
+	getenv         func(key string) string
+	stdinTerminal  bool
+	stdoutTerminal bool
+}
+
+// currentEnvironment returns the environment of the process, stdout is the writer of command results.
+func currentEnvironment(stdout io.Writer) environment {
+	return environment{getenv: os.Getenv, stdinTerminal: isTerminal(os.Stdin), stdoutTerminal: isTerminalWriter(stdout)}
+}
+
+// isTerminalWriter checks if w is a file connected to a terminal, e.g. os.Stdout.
+func isTerminalWriter(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+// ci returns the environment variable used to detect a CI execution, empty if not running on CI.
+func (e environment) ci() string {
+	for _, key := range ciEnvVars {
+		if value := strings.TrimSpace(e.getenv(key)); value != "" && value != "0" && !strings.EqualFold(value, "false") {
+			return key
+		}
+	}
+	return ""
+}
+
+// nonInteractiveReason returns why prompts cannot be used, empty if prompts are allowed or forced by --interactive flag.
+func (e environment) nonInteractiveReason(forceInteractive bool) string {
+	if forceInteractive {
+		return ""
+	}
+	if key := e.ci(); key != "" {
+		return fmt.Sprintf("CI environment detected (%s is set)", key)
+	}
+	if !e.stdinTerminal {
+		return "stdin is not a terminal"
+	}
+	return ""
+}
+
+// colorsAllowed checks if colored output can be used, colors are disabled on CI and by NO_COLOR environment variable.
+func (e environment) colorsAllowed() bool {
+	return e.getenv("NO_COLOR") == "" && e.ci() == ""
+}
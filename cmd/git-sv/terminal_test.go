@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func envFrom(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func Test_environment_nonInteractiveReason(t *testing.T) {
+	tests := []struct {
+		name             string
+		env              map[string]string
+		stdinTerminal    bool
+		forceInteractive bool
+		want             string
+	}{
+		{"terminal", nil, true, false, ""},
+		{"stdin not terminal", nil, false, false, "stdin is not a terminal"},
+		{"generic ci", map[string]string{"CI": "true"}, true, false, "CI environment detected (CI is set)"},
+		{"github actions", map[string]string{"GITHUB_ACTIONS": "true"}, true, false, "CI environment detected (GITHUB_ACTIONS is set)"},
+		{"gitlab", map[string]string{"GITLAB_CI": "true"}, true, false, "CI environment detected (GITLAB_CI is set)"},
+		{"jenkins", map[string]string{"JENKINS_URL": "http://jenkins"}, true, false, "CI environment detected (JENKINS_URL is set)"},
+		{"ci disabled", map[string]string{"CI": "false"}, true, false, ""},
+		{"ci zero", map[string]string{"CI": "0"}, true, false, ""},
+		{"forced on ci", map[string]string{"CI": "true"}, false, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := environment{getenv: envFrom(tt.env), stdinTerminal: tt.stdinTerminal}
+			if got := env.nonInteractiveReason(tt.forceInteractive); got != tt.want {
+				t.Errorf("nonInteractiveReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_environment_colorsAllowed(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"default", nil, true},
+		{"no color", map[string]string{"NO_COLOR": "1"}, false},
+		{"ci", map[string]string{"GITHUB_ACTIONS": "true"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (environment{getenv: envFrom(tt.env)}).colorsAllowed(); got != tt.want {
+				t.Errorf("colorsAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_prompts_Disabled(t *testing.T) {
+	out, _ := newTestPrinter()
+	out.promptsDisabled = "CI environment detected (CI is set)"
+
+	if _, err := promptSubject(out); err == nil || !strings.Contains(err.Error(), "--interactive") {
+		t.Errorf("promptSubject() error = %v, want error suggesting --interactive", err)
+	}
+	if _, err := promptScope([]string{"api"}, false, out); err == nil {
+		t.Error("promptScope() expected error when prompts are disabled, got nil")
+	}
+	if got, err := promptConfirm("has breaking change?", out); err != nil || got {
+		t.Errorf("promptConfirm() = (%v, %v), want (false, nil)", got, err)
+	}
+	if got, err := promptConfirmDefaultYes("commit staged changes?", out); err != nil || !got {
+		t.Errorf("promptConfirmDefaultYes() = (%v, %v), want (true, nil)", got, err)
+	}
+	if _, err := openEditor("", out); err == nil {
+		t.Error("openEditor() expected error when prompts are disabled, got nil")
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// phases measured by --timings.
+const (
+	phaseDiscovery = "component discovery"
+	phaseTags      = "tag listing"
+	phaseLog       = "component log"
+	phaseFormat    = "formatting"
+	phaseWrite     = "file writes"
+)
+
+// timings records wall time and git calls of each command phase for --timings and --timings-json,
+// nothing is recorded until enabled.
+type timings struct {
+	now          func() time.Time
+	enabled      bool
+	start        time.Time
+	gitCalls     int // every git call, also outside phases
+	current      *measurement
+	measurements []measurement
+}
+
+// measurement a single execution of a phase, component is empty for phases of the whole repository.
+type measurement struct {
+	phase     string
+	component string
+	duration  time.Duration // excluding nested phases
+	gitCalls  int
+	nested    time.Duration
+}
+
+func newTimings(now func() time.Time) *timings {
+	return &timings{now: now}
+}
+
+// enable starts recording if --timings or --timings-json is set.
+func (t *timings) enable(c *cli.Context) {
+	if c.Bool("timings") || c.String("timings-json") != "" {
+		t.enabled, t.start = true, t.now()
+	}
+}
+
+// measure starts phase, git calls are counted on it until the returned function is called. Time of nested phases is
+// only counted on them, consecutive measurements of the same phase and component are merged. Phases interrupted by an
+// error, i.e. stop is never called, are not recorded.
+func (t *timings) measure(phase, component string) (stop func()) {
+	if !t.enabled {
+		return func() {}
+	}
+	m := &measurement{phase: phase, component: component}
+	previous, start := t.current, t.now()
+	t.current = m
+	return func() {
+		elapsed := t.now().Sub(start)
+		m.duration = elapsed - m.nested
+		t.current = previous
+		if previous != nil {
+			previous.nested += elapsed
+		}
+		if n := len(t.measurements); n > 0 && t.measurements[n-1].phase == phase && t.measurements[n-1].component == component {
+			t.measurements[n-1].duration += m.duration
+			t.measurements[n-1].gitCalls += m.gitCalls
+			return
+		}
+		t.measurements = append(t.measurements, *m)
+	}
+}
+
+// measureSource measures every read of source as phase, e.g. logs of release notes read while a changelog is written.
+func (t *timings) measureSource(phase, component string, source sv.ReleaseNoteSource) sv.ReleaseNoteSource {
+	return func() (sv.ReleaseNote, bool, error) {
+		stop := t.measure(phase, component)
+		rn, ok, err := source()
+		if err == nil {
+			stop()
+		}
+		return rn, ok, err
+	}
+}
+
+func (t *timings) gitCall() {
+	if !t.enabled {
+		return
+	}
+	t.gitCalls++
+	if t.current != nil {
+		t.current.gitCalls++
+	}
+}
+
+// timingsEntry is a row of the summary table and an item of --timings-json.
+type timingsEntry struct {
+	Phase      string  `json:"phase"`
+	Component  string  `json:"component,omitempty"`
+	Runs       int     `json:"runs,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+	GitCalls   int     `json:"gitCalls"`
+}
+
+// timingsReport content of --timings-json, phases are totals by phase in execution order, measurements every phase execution.
+type timingsReport struct {
+	DurationMs   float64        `json:"durationMs"`
+	GitCalls     int            `json:"gitCalls"`
+	Phases       []timingsEntry `json:"phases"`
+	Measurements []timingsEntry `json:"measurements"`
+}
+
+func (t *timings) report() timingsReport {
+	result := timingsReport{DurationMs: milliseconds(t.now().Sub(t.start)), GitCalls: t.gitCalls, Phases: []timingsEntry{}, Measurements: []timingsEntry{}}
+	totals := make(map[string]*measurement)
+	var order []string
+	runs := make(map[string]int)
+	for _, m := range t.measurements {
+		result.Measurements = append(result.Measurements, timingsEntry{Phase: m.phase, Component: m.component, DurationMs: milliseconds(m.duration), GitCalls: m.gitCalls})
+		total, found := totals[m.phase]
+		if !found {
+			total = &measurement{phase: m.phase}
+			totals[m.phase] = total
+			order = append(order, m.phase)
+		}
+		total.duration += m.duration
+		total.gitCalls += m.gitCalls
+		runs[m.phase]++
+	}
+	for _, phase := range order {
+		result.Phases = append(result.Phases, timingsEntry{Phase: phase, Runs: runs[phase], DurationMs: milliseconds(totals[phase].duration), GitCalls: totals[phase].gitCalls})
+	}
+	return result
+}
+
+// write prints the summary table on stderr with --timings and writes the raw measurements to --timings-json path,
+// it is called after the command, also when it failed.
+func (t *timings) write(c *cli.Context, out *printer) error {
+	if !t.enabled {
+		return nil
+	}
+	report := t.report()
+	if c.Bool("timings") {
+		var sb strings.Builder
+		w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PHASE\tRUNS\tGIT CALLS\tTIME")
+		for _, phase := range report.Phases {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", phase.Phase, phase.Runs, phase.GitCalls, durationOf(phase.DurationMs))
+		}
+		fmt.Fprintf(w, "total\t\t%d\t%s\n", report.GitCalls, durationOf(report.DurationMs))
+		w.Flush()
+		fmt.Fprint(out.stderr, sb.String())
+	}
+	if path := c.String("timings-json"); path != "" {
+		content, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error writing timings file %s, message: %v", path, err)
+		}
+		return writeFileAtomic(path, append(content, '\n'))
+	}
+	return nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func durationOf(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond)).Round(100 * time.Microsecond)
+}
+
+// timedGit Git decorator counting git calls on timings, calls of methods that do not run git are not counted.
+type timedGit struct {
+	sv.Git
+	timings *timings
+}
+
+func (g timedGit) LastTag() string {
+	g.timings.gitCall()
+	return g.Git.LastTag()
+}
+
+func (g timedGit) Log(lr sv.LogRange) ([]sv.GitCommitLog, error) {
+	g.timings.gitCall()
+	return g.Git.Log(lr)
+}
+
+func (g timedGit) LogAll(paths []string) ([]sv.GitCommitLog, error) {
+	g.timings.gitCall()
+	return g.Git.LogAll(paths)
+}
+
+func (g timedGit) Commit(header, body, footer string, paths ...string) error {
+	g.timings.gitCall()
+	return g.Git.Commit(header, body, footer, paths...)
+}
+
+func (g timedGit) Tag(version semver.Version, remote string) (string, error) {
+	g.timings.gitCall()
+	return g.Git.Tag(version, remote)
+}
+
+func (g timedGit) TagAt(version semver.Version, ref, remote string) (string, error) {
+	g.timings.gitCall()
+	return g.Git.TagAt(version, ref, remote)
+}
+
+func (g timedGit) Tags() ([]sv.GitTag, error) {
+	g.timings.gitCall()
+	return g.Git.Tags()
+}
+
+func (g timedGit) TagsAll() ([]sv.GitTag, error) {
+	g.timings.gitCall()
+	return g.Git.TagsAll()
+}
+
+func (g timedGit) Branch() string {
+	g.timings.gitCall()
+	return g.Git.Branch()
+}
+
+func (g timedGit) IsDetached() (bool, error) {
+	g.timings.gitCall()
+	return g.Git.IsDetached()
+}
+
+func (g timedGit) IsClean() (bool, []string, error) {
+	g.timings.gitCall()
+	return g.Git.IsClean()
+}
+
+func (g timedGit) LastComponentTag(componentPath string, previousPaths ...string) string {
+	g.timings.gitCall()
+	return g.Git.LastComponentTag(componentPath, previousPaths...)
+}
+
+func (g timedGit) ComponentTags(componentPath string, previousPaths ...string) ([]sv.GitTag, error) {
+	g.timings.gitCall()
+	return g.Git.ComponentTags(componentPath, previousPaths...)
+}
+
+func (g timedGit) TagForComponent(version semver.Version, componentPath, remote string) (string, error) {
+	g.timings.gitCall()
+	return g.Git.TagForComponent(version, componentPath, remote)
+}
+
+func (g timedGit) TagForComponentAt(version semver.Version, componentPath, ref, remote string) (string, error) {
+	g.timings.gitCall()
+	return g.Git.TagForComponentAt(version, componentPath, ref, remote)
+}
+
+func (g timedGit) DeleteTag(tag, remote string) error {
+	g.timings.gitCall()
+	return g.Git.DeleteTag(tag, remote)
+}
+
+func (g timedGit) TagTarget(tag string) (string, bool, error) {
+	g.timings.gitCall()
+	return g.Git.TagTarget(tag)
+}
+
+func (g timedGit) ReplaceTag(tag, message, commit string, date time.Time) error {
+	g.timings.gitCall()
+	return g.Git.ReplaceTag(tag, message, commit, date)
+}
+
+func (g timedGit) Add(paths ...string) error {
+	g.timings.gitCall()
+	return g.Git.Add(paths...)
+}
+
+func (g timedGit) HasStagedChanges() (bool, error) {
+	g.timings.gitCall()
+	return g.Git.HasStagedChanges()
+}
+
+func (g timedGit) StagedDiffStat() (string, error) {
+	g.timings.gitCall()
+	return g.Git.StagedDiffStat()
+}
+
+func (g timedGit) StagedFiles() ([]string, error) {
+	g.timings.gitCall()
+	return g.Git.StagedFiles()
+}
+
+func (g timedGit) IsShallow() (bool, error) {
+	g.timings.gitCall()
+	return g.Git.IsShallow()
+}
+
+func (g timedGit) Unshallow() error {
+	g.timings.gitCall()
+	return g.Git.Unshallow()
+}
+
+func (g timedGit) Fetch(tagsOnly bool) error {
+	g.timings.gitCall()
+	return g.Git.Fetch(tagsOnly)
+}
+
+func (g timedGit) TagRemote() string {
+	g.timings.gitCall()
+	return g.Git.TagRemote()
+}
+
+func (g timedGit) RemoteExists(remote string) (bool, error) {
+	g.timings.gitCall()
+	return g.Git.RemoteExists(remote)
+}
+
+func (g timedGit) Push() error {
+	g.timings.gitCall()
+	return g.Git.Push()
+}
+
+func (g timedGit) PushTags(remote string, tags []string) error {
+	g.timings.gitCall()
+	return g.Git.PushTags(remote, tags)
+}
+
+func (g timedGit) ForcePushTags(remote string, tags []string) error {
+	g.timings.gitCall()
+	return g.Git.ForcePushTags(remote, tags)
+}
+
+func (g timedGit) ShowFile(revision, path string) ([]byte, error) {
+	g.timings.gitCall()
+	return g.Git.ShowFile(revision, path)
+}
+
+func (g timedGit) ShortHash(revision string) (string, error) {
+	g.timings.gitCall()
+	return g.Git.ShortHash(revision)
+}
+
+func (g timedGit) IsAncestor(ancestor, revision string) (bool, error) {
+	g.timings.gitCall()
+	return g.Git.IsAncestor(ancestor, revision)
+}
+
+func (g timedGit) MergeBase(a, b string) (string, error) {
+	g.timings.gitCall()
+	return g.Git.MergeBase(a, b)
+}
+
+func (g timedGit) DefaultBranch() (string, error) {
+	g.timings.gitCall()
+	return g.Git.DefaultBranch()
+}
+
+func (g timedGit) PatchID(hash string) (string, error) {
+	g.timings.gitCall()
+	return g.Git.PatchID(hash)
+}
+
+func (g timedGit) BehindUpstream() (string, int, error) {
+	g.timings.gitCall()
+	return g.Git.BehindUpstream()
+}
+
+func (g timedGit) PushedCommits(oldRev, newRev, ref string) ([]sv.GitRawCommit, error) {
+	g.timings.gitCall()
+	return g.Git.PushedCommits(oldRev, newRev, ref)
+}
+
+func (g timedGit) Version() (string, error) {
+	g.timings.gitCall()
+	return g.Git.Version()
+}
+
+func (g timedGit) HooksPath() (string, error) {
+	g.timings.gitCall()
+	return g.Git.HooksPath()
+}
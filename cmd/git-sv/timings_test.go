@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+// stepClock returns a clock moving step forward on every call.
+func stepClock(step time.Duration) func() time.Time {
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func newTimingsCtx(timingsFlag bool, timingsJSON string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Bool("timings", timingsFlag, "")
+	set.String("timings-json", timingsJSON, "")
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func Test_timings_gitCalls(t *testing.T) {
+	tm := newTimings(stepClock(10 * time.Millisecond))
+	git := timedGit{Git: mockGit{logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) { return nil, nil }}, timings: tm}
+
+	git.TagsAll()
+	if tm.gitCalls != 0 {
+		t.Fatalf("timings.gitCalls = %d before enable, want 0", tm.gitCalls)
+	}
+
+	tm.enable(newTimingsCtx(true, ""))
+	git.Branch() // outside phases, only on total
+	stop := tm.measure(phaseTags, "")
+	git.TagsAll()
+	stop()
+	for _, component := range []string{"api", "web"} {
+		stop = tm.measure(phaseLog, component)
+		git.Log(sv.LogRange{})
+		git.LastComponentTag(component)
+		stop()
+		stop = tm.measure(phaseWrite, component)
+		nested := tm.measure(phaseLog, component) // e.g. release history read while the changelog is written
+		git.Log(sv.LogRange{})
+		nested()
+		git.ComponentTagName(*semver.MustParse("1.0.0"), component) // no git execution
+		stop()
+	}
+
+	got := tm.report()
+	want := timingsReport{
+		DurationMs: 150,
+		GitCalls:   8,
+		Phases: []timingsEntry{
+			{Phase: phaseTags, Runs: 1, DurationMs: 10, GitCalls: 1},
+			{Phase: phaseLog, Runs: 2, DurationMs: 40, GitCalls: 6},
+			{Phase: phaseWrite, Runs: 2, DurationMs: 40, GitCalls: 0},
+		},
+		Measurements: []timingsEntry{
+			{Phase: phaseTags, DurationMs: 10, GitCalls: 1},
+			{Phase: phaseLog, Component: "api", DurationMs: 20, GitCalls: 3},
+			{Phase: phaseWrite, Component: "api", DurationMs: 20},
+			{Phase: phaseLog, Component: "web", DurationMs: 20, GitCalls: 3},
+			{Phase: phaseWrite, Component: "web", DurationMs: 20},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("timings.report() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_timings_write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timings.json")
+	tm := newTimings(stepClock(time.Second))
+	c := newTimingsCtx(true, path)
+	tm.enable(c)
+	stop := tm.measure(phaseDiscovery, "")
+	stop()
+
+	var stdout, stderr bytes.Buffer
+	if err := tm.write(c, newPrinter(&stdout, &stderr)); err != nil {
+		t.Fatalf("timings.write() unexpected error: %v", err)
+	}
+	wantTable := "PHASE                RUNS  GIT CALLS  TIME\n" +
+		"component discovery  1     0          1s\n" +
+		"total                      0          3s\n"
+	if stderr.String() != wantTable || stdout.Len() > 0 {
+		t.Errorf("timings.write() stderr = %q, stdout = %q, want stderr %q", stderr.String(), stdout.String(), wantTable)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("timings file not written: %v", err)
+	}
+	var report timingsReport
+	if err := json.Unmarshal(content, &report); err != nil || report.DurationMs != 3000 || len(report.Measurements) != 1 {
+		t.Errorf("timings file = %s, error %v, want 3000ms and 1 measurement", content, err)
+	}
+}
+
+func Test_monorepoChangelogHandler_Timings(t *testing.T) {
+	repoRoot := t.TempDir()
+	components := []sv.MonorepoComponent{makeComponent(t, "api", "1.0.0"), makeComponent(t, "web", "1.0.0")}
+	for i := range components {
+		components[i].RootPath = filepath.Join(repoRoot, components[i].Name)
+	}
+	mnrp := mockMonorepoProcessor{
+		findComponentsFn: func(string, sv.MonorepoConfig) ([]sv.MonorepoComponent, error) {
+			return components, nil
+		},
+		nextVersionFn: func(sv.MonorepoComponent, []sv.GitCommitLog, sv.SemVerCommitsProcessor) (*semver.Version, bool) {
+			return semver.MustParse("1.1.0"), true
+		},
+	}
+	tm := newTimings(time.Now)
+	git := timedGit{Git: mockGit{logFn: func(sv.LogRange) ([]sv.GitCommitLog, error) {
+		return []sv.GitCommitLog{{Hash: "abc", Date: "2024-01-01"}}, nil
+	}}, timings: tm}
+	path := filepath.Join(t.TempDir(), "timings.json")
+
+	out, _ := newTestPrinter()
+	handler := monorepoChangelogHandler(git, mockSemVerProcessor{}, mnrp, mockReleaseNoteProcessor{}, mockOutputFormatter{}, Config{}, repoRoot, sv.SystemClock{}, tm, out)
+	if err := handler(newTimingsCtx(false, path)); err != nil {
+		t.Fatalf("monorepoChangelogHandler() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("timings file not written: %v", err)
+	}
+	var report timingsReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatal(err)
+	}
+	var got []timingsEntry
+	for _, phase := range report.Phases {
+		got = append(got, timingsEntry{Phase: phase.Phase, Runs: phase.Runs, GitCalls: phase.GitCalls})
+	}
+	want := []timingsEntry{
+		{Phase: phaseDiscovery, Runs: 1},
+		{Phase: phaseTags, Runs: 1, GitCalls: 1},
+		// HEAD is resolved once for the component cache, next versions reuse the changelog logs.
+		{Phase: phaseLog, Runs: 2, GitCalls: 3},
+		{Phase: phaseWrite, Runs: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("monorepoChangelogHandler() timings phases = %+v, want %+v", got, want)
+	}
+}
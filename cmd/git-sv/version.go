@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	latestReleaseURL     = "https://api.github.com/repos/bvieira/sv4git/releases/latest"
+	upgradeCheckCacheTTL = 24 * time.Hour
+)
+
+func init() {
+	// cli.VersionPrinter is shared by every app, printVersion writes to the app writer so each Run prints to its own stdout.
+	cli.VersionPrinter = printVersion
+}
+
+func printVersion(c *cli.Context) {
+	fmt.Fprint(c.App.Writer, versionInfo(c.App.Name))
+}
+
+// versionInfo returns the text printed by --version with build metadata.
+func versionInfo(name string) string {
+	return fmt.Sprintf("%s version %s\ncommit: %s\nbuild date: %s\ngo version: %s\n", name, Version, Commit, BuildDate, runtime.Version())
+}
+
+// latestRelease latest git-sv release published on GitHub, as stored on upgrade check cache.
+type latestRelease struct {
+	Version   string    `json:"version"`
+	URL       string    `json:"url"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// githubRelease release payload of GitHub releases API.
+type githubRelease struct { //nolint:tagliatelle // field names defined by GitHub API
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// upgradeChecker queries the latest git-sv release, results are cached to avoid querying the releases API on every execution.
+type upgradeChecker struct {
+	releaseURL string
+	cachePath  string
+	cacheTTL   time.Duration
+	now        func() time.Time
+}
+
+func newUpgradeChecker() upgradeChecker {
+	cachePath := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		cachePath = filepath.Join(dir, "sv4git", "latest-release.json")
+	}
+	return upgradeChecker{releaseURL: latestReleaseURL, cachePath: cachePath, cacheTTL: upgradeCheckCacheTTL, now: time.Now}
+}
+
+// latest returns the latest release from cache if checked less than cacheTTL ago, otherwise from releases API.
+func (u upgradeChecker) latest(timeout time.Duration) (latestRelease, error) {
+	if release, ok := u.cached(); ok {
+		return release, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.releaseURL, nil)
+	if err != nil {
+		return latestRelease{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return latestRelease{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return latestRelease{}, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	var payload githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return latestRelease{}, fmt.Errorf("invalid response, message: %v", err)
+	}
+	release := latestRelease{Version: payload.TagName, URL: payload.HTMLURL, CheckedAt: u.now()}
+	u.store(release)
+	return release, nil
+}
+
+func (u upgradeChecker) cached() (latestRelease, bool) {
+	if u.cachePath == "" {
+		return latestRelease{}, false
+	}
+	content, err := os.ReadFile(u.cachePath)
+	if err != nil {
+		return latestRelease{}, false
+	}
+	var release latestRelease
+	if err := json.Unmarshal(content, &release); err != nil || release.Version == "" || u.now().Sub(release.CheckedAt) >= u.cacheTTL {
+		return latestRelease{}, false
+	}
+	return release, true
+}
+
+// store saves release on cache, failures are ignored since cache only avoids requests.
+func (u upgradeChecker) store(release latestRelease) {
+	if u.cachePath == "" {
+		return
+	}
+	content, err := json.Marshal(release)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(u.cachePath), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(u.cachePath, content, 0644)
+}
+
+func upgradeCheckHandler(checker upgradeChecker, currentVersion string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		release, err := checker.latest(c.Duration("timeout"))
+		if err != nil {
+			out.warnf("could not check latest version, message: %v", err)
+			return nil
+		}
+
+		latestVer, err := semver.NewVersion(release.Version)
+		if err != nil {
+			out.warnf("could not check latest version, invalid release version %s", release.Version)
+			return nil
+		}
+		currentVer, err := semver.NewVersion(currentVersion)
+		if err != nil {
+			out.infof("latest version is %s, current build %s is not a release", latestVer, currentVersion)
+			return nil
+		}
+
+		if latestVer.GreaterThan(currentVer) {
+			out.warnf("a new version of git-sv is available: %s (current %s), download at %s", latestVer, currentVer, release.URL)
+			return nil
+		}
+		out.infof("git-sv %s is the latest version", currentVer)
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Test_versionInfo(t *testing.T) {
+	got := versionInfo("sv")
+	for _, want := range []string{"sv version " + Version + "\n", "commit: " + Commit + "\n", "build date: " + BuildDate + "\n", "go version: go"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("versionInfo() = %q, want to contain %q", got, want)
+		}
+	}
+}
+
+func newReleaseServer(t *testing.T, status int, body string) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newUpgradeCheckCtx(timeout time.Duration) *cli.Context {
+	set := flag.NewFlagSet("test", 0)
+	set.Duration("timeout", timeout, "")
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func Test_upgradeCheckHandler(t *testing.T) {
+	release := `{"tag_name": "v2.5.0", "html_url": "https://example.com/releases/v2.5.0"}`
+	tests := []struct {
+		name       string
+		current    string
+		status     int
+		body       string
+		wantStdout string
+		wantStderr string
+	}{
+		{"newer version", "2.4.1", http.StatusOK, release, "", "WARN: a new version of git-sv is available: 2.5.0 (current 2.4.1), download at https://example.com/releases/v2.5.0\n"},
+		{"latest version", "2.5.0", http.StatusOK, release, "git-sv 2.5.0 is the latest version\n", ""},
+		{"development build", "source", http.StatusOK, release, "latest version is 2.5.0, current build source is not a release\n", ""},
+		{"api error", "2.4.1", http.StatusForbidden, `{"message": "rate limit"}`, "", "WARN: could not check latest version, message: unexpected response status: 403 Forbidden\n"},
+		{"invalid response", "2.4.1", http.StatusOK, `{`, "", "WARN: could not check latest version, message: invalid response, message: unexpected EOF\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newReleaseServer(t, tt.status, tt.body)
+			checker := upgradeChecker{releaseURL: server.URL, cacheTTL: time.Hour, now: time.Now}
+
+			var stdout, stderr bytes.Buffer
+			handler := upgradeCheckHandler(checker, tt.current, newPrinter(&stdout, &stderr))
+			if err := handler(newUpgradeCheckCtx(time.Second)); err != nil {
+				t.Fatalf("upgradeCheckHandler() error = %v, want nil", err)
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("upgradeCheckHandler() stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if stderr.String() != tt.wantStderr {
+				t.Errorf("upgradeCheckHandler() stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func Test_upgradeCheckHandler_NetworkError(t *testing.T) {
+	server, _ := newReleaseServer(t, http.StatusOK, "{}")
+	server.Close()
+	checker := upgradeChecker{releaseURL: server.URL, cacheTTL: time.Hour, now: time.Now}
+
+	var stderr bytes.Buffer
+	handler := upgradeCheckHandler(checker, "2.4.1", newPrinter(&bytes.Buffer{}, &stderr))
+	if err := handler(newUpgradeCheckCtx(time.Second)); err != nil {
+		t.Fatalf("upgradeCheckHandler() error = %v, want nil", err)
+	}
+	if !strings.HasPrefix(stderr.String(), "WARN: could not check latest version") {
+		t.Errorf("upgradeCheckHandler() stderr = %q, want warning", stderr.String())
+	}
+}
+
+func Test_upgradeChecker_Cache(t *testing.T) {
+	server, requests := newReleaseServer(t, http.StatusOK, `{"tag_name": "v2.5.0", "html_url": "https://example.com"}`)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	checker := upgradeChecker{
+		releaseURL: server.URL,
+		cachePath:  filepath.Join(t.TempDir(), "sv4git", "latest-release.json"),
+		cacheTTL:   24 * time.Hour,
+		now:        func() time.Time { return now },
+	}
+
+	steps := []struct {
+		elapsed      time.Duration
+		wantRequests int
+	}{
+		{0, 1},
+		{time.Hour, 1},
+		{23 * time.Hour, 1},
+		{25 * time.Hour, 2},
+	}
+	start := now
+	for _, step := range steps {
+		now = start.Add(step.elapsed)
+		release, err := checker.latest(time.Second)
+		if err != nil {
+			t.Fatalf("latest() error = %v", err)
+		}
+		if release.Version != "v2.5.0" {
+			t.Errorf("latest() version = %s, want v2.5.0", release.Version)
+		}
+		if *requests != step.wantRequests {
+			t.Errorf("latest() after %v requests = %d, want %d", step.elapsed, *requests, step.wantRequests)
+		}
+	}
+
+	content, err := os.ReadFile(checker.cachePath)
+	if want := `{"version":"v2.5.0","url":"https://example.com","checkedAt":"2022-01-02T11:00:00Z"}`; err != nil || string(content) != want {
+		t.Errorf("latest() cache file = %s, error %v, want %s", content, err, want)
+	}
+}
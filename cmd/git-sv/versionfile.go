@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bvieira/sv4git/v2/sv"
+	"github.com/urfave/cli/v2"
+)
+
+const defaultBumpCommitMessage = "chore(release): bump version"
+
+// committedFileVersion reads the version of versioning.file committed on ref, HEAD if empty, uncommitted changes are ignored.
+func committedFileVersion(git sv.Git, cfg sv.VersioningConfig, ref string) (*semver.Version, error) {
+	ref = str(ref, "HEAD")
+	content, err := git.ShowFile(ref, cfg.File)
+	if err != nil {
+		return nil, fmt.Errorf("error reading versioning file %s at %s, message: %v", cfg.File, ref, err)
+	}
+	version, err := sv.ParseVersionFile(cfg.File, content, cfg.VersionPath(), "")
+	if err != nil {
+		return nil, fmt.Errorf("error reading versioning file %s, message: %v", cfg.File, err)
+	}
+	return version, nil
+}
+
+// baseVersion returns the version next versions are calculated from, commits are read as described on nextVersion. With
+// versioning.source file it is the version of versioning.file committed on ref and, as monorepo-tag does with versioning
+// files, a file version above the lastTag version is a bump already committed: it is the next version as is, bumped is true.
+// A file version below the lastTag version is ignored with a warning, the tag version is used.
+func baseVersion(git sv.Git, cfg sv.VersioningConfig, lastTag, ref string, out *printer) (*semver.Version, bool, error) {
+	tagVer, err := sv.ToVersion(lastTag)
+	if err != nil {
+		return nil, false, fmt.Errorf("error parsing version: %s from git tag, message: %v", lastTag, err)
+	}
+	if cfg.Source != sv.VersioningSourceFile {
+		return tagVer, false, nil
+	}
+	fileVer, err := committedFileVersion(git, cfg, ref)
+	if err != nil {
+		return nil, false, err
+	}
+	switch {
+	case lastTag == "":
+		out.debugf("no tag found, baseline is versioning file %s with version %s", cfg.File, fileVer)
+		return fileVer, false, nil
+	case fileVer.GreaterThan(tagVer):
+		out.debugf("versioning file %s has version %s, above tag %s, version bump already committed", cfg.File, fileVer, lastTag)
+		return fileVer, true, nil
+	case fileVer.LessThan(tagVer):
+		out.warnf("versioning file %s has version %s, lower than tag %s, using the tag version, run bump to update the file", cfg.File, fileVer, lastTag)
+		return tagVer, false, nil
+	}
+	return fileVer, false, nil
+}
+
+// fileChangeCommit returns the last commit up to ref, HEAD if empty, changing versioning.file. Without tags the version committed
+// on the file already includes the commits up to it, e.g. a bump commit, so only later commits update the version.
+func fileChangeCommit(git sv.Git, cfg sv.VersioningConfig, ref string) (string, error) {
+	commits, err := git.Log(sv.NewLogRangeWithPaths(sv.TagRange, "", ref, []string{cfg.File}))
+	if err != nil {
+		return "", fmt.Errorf("error getting git log of versioning file %s, message: %v", cfg.File, err)
+	}
+	if len(commits) == 0 {
+		return "", nil
+	}
+	return commits[0].Hash, nil
+}
+
+// nextVersion returns the current version, see baseVersion, the next version and the commits since last tag up to ref, HEAD if empty,
+// changing --path pathspecs if set. Without tags, with versioning.source file commits are read since the last commit changing
+// versioning.file, see fileChangeCommit.
+// The next version is the current version when there is no update, or when the bump is already committed on versioning.file.
+func nextVersion(c *cli.Context, git sv.Git, semverProcessor sv.SemVerCommitsProcessor, cfg sv.VersioningConfig, lastTag, ref string, out *printer) (*semver.Version, *semver.Version, []sv.GitCommitLog, error) {
+	currentVer, bumped, err := baseVersion(git, cfg, lastTag, ref, out)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	since := lastTag
+	if lastTag == "" && cfg.Source == sv.VersioningSourceFile {
+		if since, err = fileChangeCommit(git, cfg, ref); err != nil {
+			return nil, nil, nil, err
+		}
+		out.debugf("using commits since %s, the last commit changing versioning file %s", since, cfg.File)
+	} else {
+		debugBaseline(out, lastTag)
+	}
+
+	paths := c.StringSlice("path")
+	commits, err := versionCommits(git, sv.NewLogRangeWithPaths(sv.TagRange, since, ref, paths), cfg, paths, out)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting git log, message: %v", err)
+	}
+	debugCommits(out, semverProcessor, commits)
+
+	nextVer := currentVer
+	if !bumped {
+		nextVer, _ = semverProcessor.NextVersion(currentVer, commits)
+	}
+	forced, err := versionOverride(c, currentVer)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if forced != nil {
+		nextVer = forced
+	}
+	return currentVer, nextVer, commits, nil
+}
+
+// bumpHandler writes the next version to versioning.file, commits it with --commit. The version already committed on the file
+// is kept, so running it again before the tag is created does not bump twice.
+func bumpHandler(git sv.Git, semverProcessor sv.SemVerCommitsProcessor, messageProcessor sv.MessageProcessor, cfg Config, repoPath string, out *printer) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		if cfg.Versioning.File == "" {
+			return fmt.Errorf("versioning.file is not configured, bump writes the next version to it")
+		}
+		if err := checkCleanWorkingTree(git, c.Bool("allow-dirty"), repoPath, nil, out); err != nil {
+			return err
+		}
+
+		// the committed file is the baseline, also with versioning.source tag, so a committed bump is not applied again.
+		versioning := cfg.Versioning
+		versioning.Source = sv.VersioningSourceFile
+		currentVer, nextVer, _, err := nextVersion(c, git, semverProcessor, versioning, git.LastTag(), "", out)
+		if err != nil {
+			return err
+		}
+		if nextVer.Equal(currentVer) {
+			out.infof("no version change, %s already has version %s", cfg.Versioning.File, currentVer.String())
+			return nil
+		}
+
+		file := filepath.Join(repoPath, filepath.FromSlash(cfg.Versioning.File))
+		hooks := newHooks(c, cfg.Hooks, repoPath, out)
+		env := hookEnv{Version: nextVer.String(), Tag: git.TagName(*nextVer)}
+		if err := hooks.run(hookPreBump, env); err != nil {
+			return err
+		}
+		if err := sv.UpdateVersionFile(file, versioning.VersionPath(), *nextVer); err != nil {
+			return fmt.Errorf("error updating versioning file %s, message: %v", cfg.Versioning.File, err)
+		}
+		out.successf("%s written to %s", nextVer.String(), cfg.Versioning.File)
+		hookErr := hooks.run(hookPostBump, env)
+		if hookErr != nil {
+			out.errorf("%v", hookErr)
+		}
+
+		if c.Bool("commit") {
+			body := fmt.Sprintf("- %s: %s", cfg.Versioning.File, nextVer.String())
+			if err := commitVersionFiles(git, messageProcessor, str(cfg.Versioning.BumpCommitMessage, defaultBumpCommitMessage), body, []string{file}, c.Bool("push")); err != nil {
+				return err
+			}
+		}
+		if hookErr != nil {
+			return postHooksError([]string{hookErr.Error()})
+		}
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package sv
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ReleaseBranch maintenance branch matched by branches.release-pattern config, e.g. release/1.x or release/1.2.
+type ReleaseBranch struct {
+	Name  string
+	Major int64 // captured major version, -1 if not captured
+	Minor int64 // captured minor version, -1 if not captured
+}
+
+// MatchReleaseBranch checks if branch matches pattern, the first regex group, if present, captures the version line
+// of the branch as major or major.minor.
+func MatchReleaseBranch(pattern, branch string) (ReleaseBranch, bool, error) {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return ReleaseBranch{}, false, err
+	}
+	match := regex.FindStringSubmatch(branch)
+	if branch == "" || match == nil {
+		return ReleaseBranch{}, false, nil
+	}
+
+	result := ReleaseBranch{Name: branch, Major: -1, Minor: -1}
+	if len(match) < 2 || match[1] == "" {
+		return result, true, nil
+	}
+	parts := strings.Split(match[1], ".")
+	if len(parts) > 2 {
+		return ReleaseBranch{}, false, fmt.Errorf("branch %s captured version %s, expected major or major.minor", branch, match[1])
+	}
+	if result.Major, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return ReleaseBranch{}, false, fmt.Errorf("branch %s captured invalid major version %s", branch, parts[0])
+	}
+	if len(parts) == 2 {
+		if result.Minor, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return ReleaseBranch{}, false, fmt.Errorf("branch %s captured invalid minor version %s", branch, parts[1])
+		}
+	}
+	return result, true, nil
+}
+
+// Contains checks if version belongs to the captured version line, any version belongs to branches without captured version.
+func (b ReleaseBranch) Contains(version semver.Version) bool {
+	if b.Major >= 0 && int64(version.Major()) != b.Major {
+		return false
+	}
+	return b.Minor < 0 || int64(version.Minor()) == b.Minor
+}
+
+// maxUpdate release branches allow minor updates only when the captured line is a major version, e.g. release/1.x.
+func (b ReleaseBranch) maxUpdate() versionType {
+	if b.Major >= 0 && b.Minor < 0 {
+		return minor
+	}
+	return patch
+}
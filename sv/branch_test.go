@@ -0,0 +1,59 @@
+package sv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchReleaseBranch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		branch  string
+		want    ReleaseBranch
+		wantOk  bool
+		wantErr bool
+	}{
+		{"main", `^release/(\d+(?:\.\d+)?)\.x$`, "main", ReleaseBranch{}, false, false},
+		{"detached", `^release/`, "", ReleaseBranch{}, false, false},
+		{"major line", `^release/(\d+(?:\.\d+)?)\.x$`, "release/1.x", ReleaseBranch{Name: "release/1.x", Major: 1, Minor: -1}, true, false},
+		{"minor line", `^release/(\d+(?:\.\d+)?)\.x$`, "release/1.2.x", ReleaseBranch{Name: "release/1.2.x", Major: 1, Minor: 2}, true, false},
+		{"optional group not matched", `^release/(\d+)?`, "release/next", ReleaseBranch{Name: "release/next", Major: -1, Minor: -1}, true, false},
+		{"without group", `^hotfix/`, "hotfix/payments", ReleaseBranch{Name: "hotfix/payments", Major: -1, Minor: -1}, true, false},
+		{"invalid captured version", `^release/(.+)$`, "release/1.2.3", ReleaseBranch{}, false, true},
+		{"invalid regex", `^release/(`, "release/1.x", ReleaseBranch{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := MatchReleaseBranch(tt.pattern, tt.branch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MatchReleaseBranch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchReleaseBranch() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestReleaseBranch_Contains(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  ReleaseBranch
+		version string
+		want    bool
+	}{
+		{"no captured version", ReleaseBranch{Name: "release", Major: -1, Minor: -1}, "3.0.0", true},
+		{"same major", ReleaseBranch{Name: "release/1.x", Major: 1, Minor: -1}, "1.4.2", true},
+		{"other major", ReleaseBranch{Name: "release/1.x", Major: 1, Minor: -1}, "2.0.0", false},
+		{"same minor", ReleaseBranch{Name: "release/1.2.x", Major: 1, Minor: 2}, "1.2.7", true},
+		{"other minor", ReleaseBranch{Name: "release/1.2.x", Major: 1, Minor: 2}, "1.3.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.branch.Contains(*version(tt.version)); got != tt.want {
+				t.Errorf("ReleaseBranch.Contains(%s) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
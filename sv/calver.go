@@ -0,0 +1,54 @@
+package sv
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const defaultCalVerLayout = "YYYY.MM"
+
+// calVerTokens date parts supported on versioning.calver-layout.
+var calVerTokens = map[string]func(date time.Time) uint64{
+	"YYYY": func(date time.Time) uint64 { return uint64(date.Year()) },
+	"YY":   func(date time.Time) uint64 { return uint64(date.Year() % 100) },
+	"MM":   func(date time.Time) uint64 { return uint64(date.Month()) },
+	"WW": func(date time.Time) uint64 {
+		_, week := date.ISOWeek()
+		return uint64(week)
+	},
+	"DD": func(date time.Time) uint64 { return uint64(date.Day()) },
+}
+
+// calVerLayout major and minor date parts of a calver version.
+type calVerLayout struct {
+	major, minor func(date time.Time) uint64
+}
+
+func parseCalVerLayout(layout string) (calVerLayout, error) {
+	if layout == "" {
+		layout = defaultCalVerLayout
+	}
+	parts := strings.Split(layout, ".")
+	if len(parts) != 2 {
+		return calVerLayout{}, fmt.Errorf("invalid versioning.calver-layout: %s, expected major and minor date parts, e.g. %s", layout, defaultCalVerLayout)
+	}
+	major, mexists := calVerTokens[parts[0]]
+	minor, nexists := calVerTokens[parts[1]]
+	if !mexists || !nexists {
+		return calVerLayout{}, fmt.Errorf("invalid versioning.calver-layout: %s, supported date parts: YYYY, YY, MM, WW, DD", layout)
+	}
+	return calVerLayout{major: major, minor: minor}, nil
+}
+
+// next returns the calver version released on date, the patch is incremented if version was released on the same period,
+// otherwise it starts from 0. Versions are compared by numeric segments, e.g. 2024.9 is older than 2024.10.
+func (l calVerLayout) next(version semver.Version, date time.Time) semver.Version {
+	major, minor := l.major(date), l.minor(date)
+	if version.Major() == major && version.Minor() == minor {
+		return version.IncPatch()
+	}
+	return *semver.New(major, minor, 0, "", "")
+}
@@ -0,0 +1,105 @@
+package sv
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseCalVerLayout(t *testing.T) {
+	date := time.Date(2024, time.October, 3, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		layout    string
+		wantMajor uint64
+		wantMinor uint64
+		wantErr   bool
+	}{
+		{"", 2024, 10, false},
+		{"YYYY.MM", 2024, 10, false},
+		{"YY.MM", 24, 10, false},
+		{"YYYY.WW", 2024, 40, false},
+		{"YY.DD", 24, 3, false},
+		{"YYYY", 0, 0, true},
+		{"YYYY.MM.DD", 0, 0, true},
+		{"YYYY.0M", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			got, err := parseCalVerLayout(tt.layout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCalVerLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (got.major(date) != tt.wantMajor || got.minor(date) != tt.wantMinor) {
+				t.Errorf("parseCalVerLayout() = %d.%d, want %d.%d", got.major(date), got.minor(date), tt.wantMajor, tt.wantMinor)
+			}
+		})
+	}
+}
+
+func TestSemVerCommitsProcessorImpl_NextVersion_CalVer(t *testing.T) {
+	commits := []GitCommitLog{
+		commitlog("fix", map[string]string{}, "a"),
+		commitlog("feat", map[string]string{"breaking-change": "break"}, "a"),
+	}
+	tests := []struct {
+		name        string
+		version     string
+		date        time.Time
+		commits     []GitCommitLog
+		want        string
+		wantUpdated bool
+	}{
+		{"first release", "0.0.0", time.Date(2024, time.September, 30, 0, 0, 0, 0, time.UTC), commits, "2024.9.0", true},
+		{"same month increments patch", "2024.9.0", time.Date(2024, time.September, 30, 0, 0, 0, 0, time.UTC), commits, "2024.9.1", true},
+		{"next month resets patch", "2024.9.3", time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC), commits, "2024.10.0", true},
+		{"next year resets patch", "2024.12.3", time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC), commits, "2025.1.0", true},
+		{"zero padded tag", "2024.09.1", time.Date(2024, time.September, 30, 0, 0, 0, 0, time.UTC), commits, "2024.9.2", true},
+		{"no update", "2024.9.0", time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC), []GitCommitLog{commitlog("docs", map[string]string{}, "a")}, "2024.9.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewSemVerCommitsProcessor(VersioningConfig{UpdateMinor: []string{"feat"}, UpdatePatch: []string{"fix"}, IgnoreUnknown: true, Scheme: VersioningSchemeCalVer}, CommitMessageConfig{Types: []string{"feat", "fix", "docs"}})
+			p.now = func() time.Time { return tt.date }
+			current, err := ToVersion(tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, updated := p.NextVersion(current, tt.commits)
+			if got.String() != tt.want || updated != tt.wantUpdated {
+				t.Errorf("SemVerCommitsProcessorImpl.NextVersion() = %s, %v, want %s, %v", got, updated, tt.want, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestVersioningConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     VersioningConfig
+		wantErr bool
+	}{
+		{"default", VersioningConfig{}, false},
+		{"semver", VersioningConfig{Scheme: VersioningSchemeSemVer}, false},
+		{"calver", VersioningConfig{Scheme: VersioningSchemeCalVer, CalVerLayout: "YY.WW"}, false},
+		{"calver invalid layout", VersioningConfig{Scheme: VersioningSchemeCalVer, CalVerLayout: "MM"}, true},
+		{"unknown scheme", VersioningConfig{Scheme: "romver"}, true},
+		{"ignore paths", VersioningConfig{IgnorePaths: []string{"docs/**", ".github/", "*.md"}}, false},
+		{"invalid ignore path", VersioningConfig{IgnorePaths: []string{"docs/[a"}}, true},
+		{"empty ignore path", VersioningConfig{IgnorePaths: []string{"/"}}, true},
+		{"unknown commit bump", VersioningConfig{UnknownCommitBump: UnknownCommitBumpPatch}, false},
+		{"invalid unknown commit bump", VersioningConfig{UnknownCommitBump: "minor"}, true},
+		{"file source", VersioningConfig{Source: VersioningSourceFile, File: "package.json"}, false},
+		{"file source without file", VersioningConfig{Source: VersioningSourceFile}, true},
+		{"tag source with file", VersioningConfig{Source: VersioningSourceTag, File: "chart/Chart.yaml", FilePath: "appVersion"}, false},
+		{"unknown source", VersioningConfig{Source: "branch"}, true},
+		{"file outside repository", VersioningConfig{File: "../version.yml"}, true},
+		{"absolute file", VersioningConfig{File: "/version.yml"}, true},
+		{"invalid file path", VersioningConfig{File: "version.yml", FilePath: "tool[\"version"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("VersioningConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
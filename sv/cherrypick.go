@@ -0,0 +1,136 @@
+package sv
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Cherry-pick policies for duplicated commits on changelogs.
+const (
+	CherryPickPolicyAnnotate = "annotate"
+	CherryPickPolicySuppress = "suppress"
+)
+
+const duplicateOfMetadataKey = "duplicate-of"
+
+var cherryPickTrailerRegex = regexp.MustCompile(`(?m)^\(cherry picked from commit ([0-9a-f]+)\)$`)
+
+// ReleaseCommits commits of a release rendered on a changelog.
+type ReleaseCommits struct {
+	Version string
+	Commits []GitCommitLog
+}
+
+// seenCommit commit of an older release.
+type seenCommit struct {
+	commit  GitCommitLog
+	version string
+	picked  string // hash from cherry-pick trailer
+}
+
+// DedupeCherryPicks finds commits included in more than one release, by the "(cherry picked from commit <hash>)" trailer or by
+// the same subject, author and patch id. Duplicates are removed or annotated with duplicate-of metadata on the newer release
+// according to policy. Releases must be ordered from newest to oldest, as rendered on changelogs, only commits of the given
+// releases are compared and patch ids are calculated only for commits with the same subject and author.
+func DedupeCherryPicks(releases []ReleaseCommits, policy string, patchID func(hash string) (string, error)) ([]ReleaseCommits, error) {
+	if policy == "" {
+		policy = CherryPickPolicyAnnotate
+	}
+	if policy != CherryPickPolicyAnnotate && policy != CherryPickPolicySuppress {
+		return nil, fmt.Errorf("invalid cherry-pick policy: %s, use %s or %s", policy, CherryPickPolicyAnnotate, CherryPickPolicySuppress)
+	}
+
+	patchIDs := make(map[string]string)
+	samePatch := func(a, b string) (bool, error) {
+		for _, hash := range []string{a, b} {
+			if _, ok := patchIDs[hash]; ok {
+				continue
+			}
+			id, err := patchID(hash)
+			if err != nil {
+				return false, fmt.Errorf("error getting patch id of %s, message: %v", hash, err)
+			}
+			patchIDs[hash] = id
+		}
+		return patchIDs[a] != "" && patchIDs[a] == patchIDs[b], nil
+	}
+
+	result := make([]ReleaseCommits, len(releases))
+	bySubject := make(map[string][]seenCommit)
+	var seen []seenCommit
+	for i := len(releases) - 1; i >= 0; i-- {
+		release := releases[i]
+		result[i] = ReleaseCommits{Version: release.Version}
+		var current []seenCommit
+		for _, commit := range release.Commits {
+			entry := seenCommit{commit: commit, version: release.Version, picked: cherryPickedFrom(commit)}
+			current = append(current, entry)
+
+			duplicateOf := ""
+			for _, older := range seen {
+				if sameCommit(older.picked, commit.Hash) || sameCommit(entry.picked, older.commit.Hash) {
+					duplicateOf = older.version
+					break
+				}
+			}
+			if duplicateOf == "" {
+				for _, older := range bySubject[subjectKey(commit)] {
+					same, err := samePatch(older.commit.Hash, commit.Hash)
+					if err != nil {
+						return nil, err
+					}
+					if same {
+						duplicateOf = older.version
+						break
+					}
+				}
+			}
+
+			switch {
+			case duplicateOf == "":
+				result[i].Commits = append(result[i].Commits, commit)
+			case policy == CherryPickPolicyAnnotate:
+				result[i].Commits = append(result[i].Commits, withCommitMetadata(commit, duplicateOfMetadataKey, duplicateOf))
+			}
+		}
+		for _, entry := range current {
+			seen = append(seen, entry)
+			key := subjectKey(entry.commit)
+			bySubject[key] = append(bySubject[key], entry)
+		}
+	}
+	return result, nil
+}
+
+// cherryPickedFrom returns the hash from cherry-pick trailer added by "git cherry-pick -x", empty if not found.
+func cherryPickedFrom(commit GitCommitLog) string {
+	match := cherryPickTrailerRegex.FindStringSubmatch(commit.Message.Body)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// sameCommit compares hashes with different abbreviations.
+func sameCommit(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+func subjectKey(commit GitCommitLog) string {
+	return strings.Join([]string{commit.Message.Type, commit.Message.Scope, commit.Message.Description, commit.AuthorName}, "\x00")
+}
+
+// withCommitMetadata returns a copy of commit with metadata key set, commit metadata map is not changed.
+func withCommitMetadata(commit GitCommitLog, key, value string) GitCommitLog {
+	metadata := make(map[string]string, len(commit.Message.Metadata)+1)
+	for k, v := range commit.Message.Metadata {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	commit.Message.Metadata = metadata
+	return commit
+}
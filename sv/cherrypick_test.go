@@ -0,0 +1,91 @@
+package sv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDedupeCherryPicks(t *testing.T) {
+	commit := func(hash, description, author, body string) GitCommitLog {
+		return GitCommitLog{Hash: hash, AuthorName: author, Message: CommitMessage{Type: "fix", Description: description, Body: body}}
+	}
+	patchIDs := map[string]string{"a1": "p1", "b1": "p1", "a2": "p2", "b2": "p3"}
+	patchID := func(hash string) (string, error) { return patchIDs[hash], nil }
+
+	releases := []ReleaseCommits{
+		{Version: "v1.3.0", Commits: []GitCommitLog{
+			commit("c3", "fix login", "ana", ""),
+			commit("b1", "fix parser", "ana", ""),
+			commit("b2", "fix cache", "bob", ""),
+			commit("b3", "fix typo", "bob", "details\n\n(cherry picked from commit a3a3a3a3a3)"),
+		}},
+		{Version: "v1.2.1", Commits: []GitCommitLog{
+			commit("a1", "fix parser", "ana", ""),
+			commit("a2", "fix cache", "bob", ""),
+			commit("a3a3a3a", "fix typo", "carl", ""),
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		policy  string
+		want    map[string]string // hash of v1.3.0 commits to duplicate-of metadata
+		wantErr bool
+	}{
+		{"annotate", "", map[string]string{"c3": "", "b1": "v1.2.1", "b2": "", "b3": "v1.2.1"}, false},
+		{"suppress", CherryPickPolicySuppress, map[string]string{"c3": "", "b2": ""}, false},
+		{"invalid policy", "drop", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DedupeCherryPicks(releases, tt.policy, patchID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DedupeCherryPicks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got[1], releases[1]) {
+				t.Errorf("DedupeCherryPicks() oldest release = %v, want unchanged", got[1])
+			}
+			newest := make(map[string]string)
+			for _, c := range got[0].Commits {
+				newest[c.Hash] = c.Message.Metadata[duplicateOfMetadataKey]
+			}
+			if !reflect.DeepEqual(newest, tt.want) {
+				t.Errorf("DedupeCherryPicks() newest release = %v, want %v", newest, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeCherryPicks_OriginalOnNewerRelease(t *testing.T) {
+	releases := []ReleaseCommits{
+		{Version: "v1.3.0", Commits: []GitCommitLog{{Hash: "abc1234", Message: CommitMessage{Description: "fix parser"}}}},
+		{Version: "v1.2.1", Commits: []GitCommitLog{{Hash: "def5678", Message: CommitMessage{Description: "fix parser", Body: "(cherry picked from commit abc1234ffff)"}}}},
+	}
+	got, err := DedupeCherryPicks(releases, CherryPickPolicyAnnotate, func(string) (string, error) { return "", nil })
+	if err != nil {
+		t.Fatalf("DedupeCherryPicks() error = %v", err)
+	}
+	if duplicateOf := got[0].Commits[0].Message.Metadata[duplicateOfMetadataKey]; duplicateOf != "v1.2.1" {
+		t.Errorf("DedupeCherryPicks() duplicate-of = %q, want v1.2.1", duplicateOf)
+	}
+}
+
+func TestDedupeCherryPicks_PatchIDError(t *testing.T) {
+	releases := []ReleaseCommits{
+		{Version: "v1.3.0", Commits: []GitCommitLog{{Hash: "err", AuthorName: "ana"}}},
+		{Version: "v1.2.1", Commits: []GitCommitLog{{Hash: "a1", AuthorName: "ana"}}},
+	}
+	_, err := DedupeCherryPicks(releases, "", func(hash string) (string, error) {
+		if hash == "err" {
+			return "", errors.New("patch-id failed")
+		}
+		return "p1", nil
+	})
+	if err == nil {
+		t.Error("DedupeCherryPicks() expected error, got nil")
+	}
+}
@@ -0,0 +1,26 @@
+package sv
+
+import "time"
+
+// Clock provides the current time, used as date of unreleased versions, calendar versions and tags.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock Clock using the system time.
+type SystemClock struct{}
+
+// Now returns the current system time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock Clock always returning the same time, used for reproducible builds.
+type FixedClock struct {
+	Time time.Time
+}
+
+// Now returns the fixed time.
+func (c FixedClock) Now() time.Time {
+	return c.Time
+}
@@ -1,14 +1,224 @@
 package sv
 
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config sv4git configuration, it can be loaded from yaml files and used to build
+// every processor available on this package.
+type Config struct {
+	Version       string              `yaml:"version"`
+	Versioning    VersioningConfig    `yaml:"versioning"`
+	Tag           TagConfig           `yaml:"tag"`
+	ReleaseNotes  ReleaseNotesConfig  `yaml:"release-notes"`
+	Branches      BranchesConfig      `yaml:"branches"`
+	CommitMessage CommitMessageConfig `yaml:"commit-message"`
+	Monorepo      MonorepoConfig      `yaml:"monorepo"`
+	Log           LogConfig           `yaml:"log"`
+	Hooks         HooksConfig         `yaml:"hooks"`
+}
+
+// NewDefaultConfig returns the default configuration used by git-sv when no
+// user or repository configuration is found.
+func NewDefaultConfig() Config {
+	skipDetached := false
+	pattern := "%d.%d.%d"
+	filter := ""
+	return Config{
+		Version: "1.1",
+		Versioning: VersioningConfig{
+			UpdateMajor:   []string{},
+			UpdateMinor:   []string{"feat"},
+			UpdatePatch:   []string{"build", "ci", "chore", "docs", "fix", "perf", "refactor", "style", "test"},
+			IgnoreUnknown: false,
+			AutoFetch:     false,
+		},
+		Tag: TagConfig{
+			Pattern: &pattern,
+			Filter:  &filter,
+		},
+		ReleaseNotes: ReleaseNotesConfig{
+			Sections: []ReleaseNotesSectionConfig{
+				{Name: "Features", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"feat"}},
+				{Name: "Bug Fixes", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"fix"}},
+				{Name: "Breaking Changes", SectionType: ReleaseNotesSectionTypeBreakingChanges},
+			},
+		},
+		Branches: BranchesConfig{
+			Prefix:       "([a-z]+\\/)?",
+			Suffix:       "(-.*)?",
+			DisableIssue: false,
+			Skip:         []string{"master", "main", "developer"},
+			SkipDetached: &skipDetached,
+		},
+		CommitMessage: CommitMessageConfig{
+			Types: []string{"build", "ci", "chore", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"},
+			Scope: CommitMessageScopeConfig{},
+			Footer: map[string]CommitMessageFooterConfig{
+				"issue": {Key: "jira", KeySynonyms: []string{"Jira", "JIRA"}},
+			},
+			Issue:          CommitMessageIssueConfig{Regex: "[A-Z]+-[0-9]+"},
+			HeaderSelector: "",
+		},
+		Monorepo: MonorepoConfig{
+			Exclude:           []string{"node_modules", "vendor", "testdata"},
+			OnParseError:      OnParseErrorFail,
+			BumpCommitMessage: "chore(release): bump versions",
+		},
+		Log: LogConfig{
+			IncludeMerges: IncludeMergesNever,
+		},
+	}
+}
+
 // ==== Message ====
 
 // CommitMessageConfig config a commit message.
 type CommitMessageConfig struct {
-	Types          []string                             `yaml:"types,flow"`
-	HeaderSelector string                               `yaml:"header-selector"`
-	Scope          CommitMessageScopeConfig             `yaml:"scope"`
-	Footer         map[string]CommitMessageFooterConfig `yaml:"footer"`
-	Issue          CommitMessageIssueConfig             `yaml:"issue"`
+	Types           []string                             `yaml:"types,flow"`
+	HeaderSelector  string                               `yaml:"header-selector"`
+	Scope           CommitMessageScopeConfig             `yaml:"scope"`
+	Footer          map[string]CommitMessageFooterConfig `yaml:"footer"`
+	Issue           CommitMessageIssueConfig             `yaml:"issue"`
+	Enhance         []CommitMessageEnhanceConfig         `yaml:"enhance"`
+	RequiredFooters []string                             `yaml:"required-footers,flow"`
+	Presets         map[string]CommitMessagePresetConfig `yaml:"presets"`
+	// BannedWords words not allowed on subject and body, e.g. internal codenames, see BannedWordViolations.
+	BannedWords []CommitMessageBannedWordConfig `yaml:"banned-words,omitempty"`
+	Spelling    CommitMessageSpellingConfig     `yaml:"spelling,omitempty"`
+	// HeaderSelectors regexes tried in order after HeaderSelector to parse and validate headers of mixed histories, e.g.
+	// "[PROJ-123] feat: something". A header group selects a conventional header, otherwise type, scope, description, issue
+	// and breaking groups define the commit fields. Headers not matching any selector use the conventional commits format.
+	HeaderSelectors []string `yaml:"header-selectors,flow,omitempty"`
+	// TypeSettings version update and release notes inclusion per commit type, independent of each other, see CommitTypeSettings.
+	TypeSettings map[string]CommitTypeSettings `yaml:"type-settings,omitempty"`
+	Breaking     CommitMessageBreakingConfig   `yaml:"breaking,omitempty"`
+	// ValidationMode of commit message validation commands: error (default), warn, violations are printed and commands
+	// succeed, or off.
+	ValidationMode string `yaml:"validation-mode,omitempty"`
+}
+
+// constants for CommitMessageConfig.ValidationMode.
+const (
+	ValidationModeError = "error"
+	ValidationModeWarn  = "warn"
+	ValidationModeOff   = "off"
+)
+
+// CommitMessageBreakingConfig breaking change prompt of commit command and how breaking changes are written. Parse accepts
+// every style whatever the configured one.
+type CommitMessageBreakingConfig struct {
+	// Prompt when commit asks for breaking changes: always (default), never or when-type-in, only for commit types on Types.
+	Prompt string   `yaml:"prompt,omitempty"`
+	Types  []string `yaml:"types,flow,omitempty"`
+	// Style of breaking changes written by commit: footer (default), exclamation or both. Footer writes a BREAKING CHANGE
+	// footer, exclamation a ! after type and scope, the commit description describes the breaking change.
+	Style string `yaml:"style,omitempty"`
+}
+
+// constants for CommitMessageBreakingConfig.Prompt.
+const (
+	BreakingPromptAlways     = "always"
+	BreakingPromptNever      = "never"
+	BreakingPromptWhenTypeIn = "when-type-in"
+)
+
+// constants for CommitMessageBreakingConfig.Style.
+const (
+	BreakingStyleFooter      = "footer"
+	BreakingStyleExclamation = "exclamation"
+	BreakingStyleBoth        = "both"
+)
+
+// Prompts returns true if commit command asks for breaking changes on commits of commitType.
+func (c CommitMessageBreakingConfig) Prompts(commitType string) bool {
+	switch c.Prompt {
+	case BreakingPromptNever:
+		return false
+	case BreakingPromptWhenTypeIn:
+		return contains(commitType, c.Types)
+	}
+	return true
+}
+
+// Validate checks prompt and style values.
+func (c CommitMessageBreakingConfig) Validate() error {
+	switch c.Prompt {
+	case "", BreakingPromptAlways, BreakingPromptNever:
+	case BreakingPromptWhenTypeIn:
+		if len(c.Types) == 0 {
+			return fmt.Errorf("commit-message.breaking.prompt %s requires commit-message.breaking.types", c.Prompt)
+		}
+	default:
+		return fmt.Errorf("invalid commit-message.breaking.prompt: %s, use: %s, %s or %s", c.Prompt, BreakingPromptAlways, BreakingPromptNever, BreakingPromptWhenTypeIn)
+	}
+	switch c.Style {
+	case "", BreakingStyleFooter, BreakingStyleExclamation, BreakingStyleBoth:
+		return nil
+	}
+	return fmt.Errorf("invalid commit-message.breaking.style: %s, use: %s, %s or %s", c.Style, BreakingStyleFooter, BreakingStyleExclamation, BreakingStyleBoth)
+}
+
+// CommitTypeSettings settings of a commit type on commit-message.type-settings, empty attributes keep the behavior of
+// versioning update lists and release-notes sections.
+type CommitTypeSettings struct {
+	// Bump version update of commits of the type: major, minor, patch or none, it overrides versioning update lists.
+	Bump string `yaml:"bump,omitempty"`
+	// Notes includes (true) or excludes (false) commits of the type from release notes, the section listing the type or the
+	// catch-all section is used, it overrides hidden sections.
+	Notes *bool `yaml:"notes,omitempty"`
+}
+
+// Validate checks header selectors, issue trackers, breaking changes, validation mode, banned words and type settings config.
+func (c CommitMessageConfig) Validate() error {
+	for commitType, settings := range c.TypeSettings {
+		switch settings.Bump {
+		case "", "major", "minor", "patch", "none":
+		default:
+			return fmt.Errorf("invalid commit-message.type-settings bump for %s: %s, use: major, minor, patch or none", commitType, settings.Bump)
+		}
+	}
+	for _, selector := range c.HeaderSelectors {
+		if _, err := compileHeaderSelector(selector); err != nil {
+			return err
+		}
+	}
+	if err := c.Issue.Validate(); err != nil {
+		return err
+	}
+	if err := c.Breaking.Validate(); err != nil {
+		return err
+	}
+	switch c.ValidationMode {
+	case "", ValidationModeError, ValidationModeWarn, ValidationModeOff:
+	default:
+		return fmt.Errorf("invalid commit-message.validation-mode: %s, use: %s, %s or %s", c.ValidationMode, ValidationModeError, ValidationModeWarn, ValidationModeOff)
+	}
+	for _, rule := range c.BannedWords {
+		if _, err := rule.regex(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommitMessageBannedWordConfig regex of words not allowed on commit messages and the message shown when it is found.
+type CommitMessageBannedWordConfig struct {
+	Regex   string `yaml:"regex"`
+	Message string `yaml:"message,omitempty"`
+}
+
+// CommitMessageSpellingConfig spelling check preferences, used with --check-spelling.
+type CommitMessageSpellingConfig struct {
+	// Dictionary file with words accepted by spelling check, one per line, relative to repository root.
+	Dictionary string `yaml:"dictionary,omitempty"`
 }
 
 // IssueFooterConfig config for issue.
@@ -19,9 +229,27 @@ func (c CommitMessageConfig) IssueFooterConfig() CommitMessageFooterConfig {
 	return CommitMessageFooterConfig{}
 }
 
+// CommitMessageEnhanceConfig footer added to commit messages by validate-commit-message hook.
+// ValueTemplate is a go template with Branch, Issue, Type and Scope fields.
+type CommitMessageEnhanceConfig struct {
+	Key           string `yaml:"key"`
+	ValueTemplate string `yaml:"value-template"`
+}
+
 // CommitMessageScopeConfig config scope preferences.
 type CommitMessageScopeConfig struct {
-	Values []string `yaml:"values"`
+	Values   []string `yaml:"values"`
+	Required bool     `yaml:"required"`
+}
+
+// CommitMessagePresetConfig overrides commit message rules for a repository area.
+// Presets are selected by staged files matching Paths prefixes or, when there are no staged files, by branch matching Branches regexes.
+type CommitMessagePresetConfig struct {
+	Paths           []string                  `yaml:"paths,flow"`
+	Branches        []string                  `yaml:"branches,flow"`
+	Types           []string                  `yaml:"types,flow"`
+	Scope           *CommitMessageScopeConfig `yaml:"scope"`
+	RequiredFooters []string                  `yaml:"required-footers,flow"`
 }
 
 // CommitMessageFooterConfig config footer metadata.
@@ -35,6 +263,28 @@ type CommitMessageFooterConfig struct {
 // CommitMessageIssueConfig issue preferences.
 type CommitMessageIssueConfig struct {
 	Regex string `yaml:"regex"`
+	// Trackers issue trackers referenced by commits, each one with its own footer, when defined regex and the issue footer are not used.
+	Trackers []CommitMessageIssueTrackerConfig `yaml:"trackers,omitempty"`
+	// OnConflict what the prepare-commit-msg hook does when the message has an issue footer with another issue than the
+	// branch: keep-existing (default), prefer-branch or error, a warning names both issues.
+	OnConflict string `yaml:"on-conflict,omitempty"`
+}
+
+// constants for CommitMessageIssueConfig.OnConflict.
+const (
+	IssueOnConflictKeepExisting = "keep-existing"
+	IssueOnConflictPreferBranch = "prefer-branch"
+	IssueOnConflictError        = "error"
+)
+
+// CommitMessageIssueTrackerConfig issue tracker, e.g. jira or github, referenced on footers with tracker key, e.g. "jira: PROJ-12, PROJ-13".
+type CommitMessageIssueTrackerConfig struct {
+	Key   string `yaml:"key"`
+	Regex string `yaml:"regex"`
+	// URLTemplate link of each issue on release notes, see URLPlaceholderIssue.
+	URLTemplate string `yaml:"url-template,omitempty"`
+	// Required commit messages must reference at least one issue of the tracker.
+	Required bool `yaml:"required,omitempty"`
 }
 
 // ==== Branches ====
@@ -46,6 +296,8 @@ type BranchesConfig struct {
 	DisableIssue bool     `yaml:"disable-issue"`
 	Skip         []string `yaml:"skip,flow"`
 	SkipDetached *bool    `yaml:"skip-detached"`
+	// ReleasePattern regex matching maintenance branches, versions are only patched on them.
+	ReleasePattern string `yaml:"release-pattern,omitempty"`
 }
 
 // ==== Versioning ====
@@ -56,6 +308,148 @@ type VersioningConfig struct {
 	UpdateMinor   []string `yaml:"update-minor,flow"`
 	UpdatePatch   []string `yaml:"update-patch,flow"`
 	IgnoreUnknown bool     `yaml:"ignore-unknown"`
+	AutoFetch     bool     `yaml:"auto-fetch"`
+	// FetchBefore fetches tags from remote before reading them, the same as --fetch flag.
+	FetchBefore bool `yaml:"fetch-before,omitempty"`
+	// MetadataTemplate build metadata appended to next versions and tags, see BuildMetadata.
+	MetadataTemplate string `yaml:"metadata-template,omitempty"`
+	// Scheme version numbering scheme, semver (default) or calver.
+	Scheme string `yaml:"scheme,omitempty"`
+	// CalVerLayout layout of major and minor versions on calver scheme, default YYYY.MM.
+	CalVerLayout string `yaml:"calver-layout,omitempty"`
+	// IgnorePaths commits changing only files matching these patterns do not update versions, see WithoutIgnoredPaths.
+	IgnorePaths []string `yaml:"ignore-paths,omitempty"`
+	// UnknownCommitBump update of commits not following conventional commits, none or patch, empty applies ignore-unknown.
+	UnknownCommitBump string `yaml:"unknown-commit-bump,omitempty"`
+	// Source of the current version, tag (default) or file, the version on File committed on HEAD.
+	Source string `yaml:"source,omitempty"`
+	// File versioning file relative to repository root, e.g. package.json, written by bump command.
+	File string `yaml:"file,omitempty"`
+	// FilePath path of the version on File, dot or bracket notation as monorepo.path, default version.
+	FilePath string `yaml:"file-path,omitempty"`
+	// BumpCommitMessage header of the commit created by bump --commit, default "chore(release): bump version".
+	BumpCommitMessage string `yaml:"bump-commit-message,omitempty"`
+	// Monotonic uses the tag with the highest version reachable from HEAD as last tag instead of the most recent tag, so merged
+	// maintenance branches never lower versions, see GitImpl.SetMonotonic.
+	Monotonic bool `yaml:"monotonic,omitempty"`
+}
+
+// VersionPath returns the path of the version on versioning file, FilePath or version if empty.
+func (cfg VersioningConfig) VersionPath() string {
+	return str(cfg.FilePath, "version")
+}
+
+// constants for VersioningConfig.Scheme.
+const (
+	VersioningSchemeSemVer = "semver"
+	VersioningSchemeCalVer = "calver"
+)
+
+// constants for VersioningConfig.Source.
+const (
+	VersioningSourceTag  = "tag"
+	VersioningSourceFile = "file"
+)
+
+// constants for VersioningConfig.UnknownCommitBump.
+const (
+	UnknownCommitBumpNone  = "none"
+	UnknownCommitBumpPatch = "patch"
+)
+
+// Validate checks versioning scheme and ignore paths config.
+func (cfg VersioningConfig) Validate() error {
+	for _, pattern := range cfg.IgnorePaths {
+		if !validPathPattern(pattern) {
+			return fmt.Errorf("invalid versioning.ignore-paths pattern: %q", pattern)
+		}
+	}
+	switch cfg.UnknownCommitBump {
+	case "", UnknownCommitBumpNone, UnknownCommitBumpPatch:
+	default:
+		return fmt.Errorf("invalid versioning.unknown-commit-bump: %s, use: %s or %s", cfg.UnknownCommitBump, UnknownCommitBumpNone, UnknownCommitBumpPatch)
+	}
+	switch cfg.Source {
+	case "", VersioningSourceTag:
+	case VersioningSourceFile:
+		if cfg.File == "" {
+			return fmt.Errorf("versioning.source %s requires versioning.file", cfg.Source)
+		}
+	default:
+		return fmt.Errorf("invalid versioning.source: %s, use: %s or %s", cfg.Source, VersioningSourceTag, VersioningSourceFile)
+	}
+	if clean := path.Clean(cfg.File); cfg.File != "" && (clean != cfg.File || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../")) {
+		return fmt.Errorf("invalid versioning.file: %s, use a slash separated path relative to repository root", cfg.File)
+	}
+	if cfg.File != "" {
+		if _, err := parsePath(cfg.VersionPath()); err != nil {
+			return fmt.Errorf("invalid versioning.file-path %q: %v", cfg.VersionPath(), err)
+		}
+	}
+	switch cfg.Scheme {
+	case "", VersioningSchemeSemVer:
+		return nil
+	case VersioningSchemeCalVer:
+		_, err := parseCalVerLayout(cfg.CalVerLayout)
+		return err
+	default:
+		return fmt.Errorf("invalid versioning.scheme: %s, use: %s or %s", cfg.Scheme, VersioningSchemeSemVer, VersioningSchemeCalVer)
+	}
+}
+
+// BumpTypeWarnings cross-checks versioning update lists with commit message types, it returns a warning for each
+// type missing on types, such commits never update the version, and for each type on more than one update list.
+// A type on several lists uses the highest one: update-major, then update-minor, then update-patch.
+func (cfg VersioningConfig) BumpTypeWarnings(types []string) []string {
+	lists := []struct {
+		name  string
+		types []string
+	}{
+		{"versioning.update-major", cfg.UpdateMajor},
+		{"versioning.update-minor", cfg.UpdateMinor},
+		{"versioning.update-patch", cfg.UpdatePatch},
+	}
+	var warnings []string
+	applied := make(map[string]string)
+	for _, list := range lists {
+		for _, t := range list.types {
+			if !contains(t, types) {
+				warnings = append(warnings, fmt.Sprintf("type %s on %s is not on commit-message.types", t, list.name))
+			}
+			if previous, exists := applied[t]; exists {
+				if previous != list.name {
+					warnings = append(warnings, fmt.Sprintf("type %s is on %s and %s, %s is applied", t, previous, list.name, previous))
+				}
+				continue
+			}
+			applied[t] = list.name
+		}
+	}
+	return warnings
+}
+
+// TypeSettingsWarnings cross-checks commit-message.type-settings with commit types and release notes sections, it returns a
+// warning for each type missing on commit-message.types and for each type with notes enabled that no section would render.
+func (cfg Config) TypeSettingsWarnings() []string {
+	types := make([]string, 0, len(cfg.CommitMessage.TypeSettings))
+	for commitType := range cfg.CommitMessage.TypeSettings {
+		types = append(types, commitType)
+	}
+	sort.Strings(types)
+
+	mapping := commitSectionMapping(cfg.ReleaseNotes.Sections)
+	var warnings []string
+	for _, commitType := range types {
+		if !contains(commitType, cfg.CommitMessage.Types) {
+			warnings = append(warnings, fmt.Sprintf("type %s on commit-message.type-settings is not on commit-message.types", commitType))
+		}
+		if notes := cfg.CommitMessage.TypeSettings[commitType].Notes; notes != nil && *notes {
+			if _, exists := mapping[commitType]; !exists && cfg.ReleaseNotes.catchAllSection() == nil {
+				warnings = append(warnings, fmt.Sprintf("type %s has notes enabled on commit-message.type-settings but no release-notes section lists it", commitType))
+			}
+		}
+	}
+	return warnings
 }
 
 // ==== Tag ====
@@ -64,14 +458,128 @@ type VersioningConfig struct {
 type TagConfig struct {
 	Pattern *string `yaml:"pattern"`
 	Filter  *string `yaml:"filter"`
+	Remote  *string `yaml:"remote,omitempty"`
+}
+
+// ==== Log ====
+
+// LogConfig git log preferences.
+type LogConfig struct {
+	IncludeMerges string `yaml:"include-merges"`
+	FirstParent   bool   `yaml:"first-parent"`
+}
+
+// constants for LogConfig.IncludeMerges.
+const (
+	IncludeMergesNever            = "never"
+	IncludeMergesAlways           = "always"
+	IncludeMergesOnlyConventional = "only-conventional"
+)
+
+// ==== Hooks ====
+
+// HooksConfig shell commands executed around version updates and tags, e.g. to regenerate lockfiles with the new version.
+// Commands run on the repository root with SV_VERSION, SV_TAG and SV_COMPONENT environment variables, a failing pre hook aborts
+// the operation and a failing post hook is reported without undoing it.
+type HooksConfig struct {
+	PreBump  []string `yaml:"pre-bump,omitempty"`
+	PostBump []string `yaml:"post-bump,omitempty"`
+	PreTag   []string `yaml:"pre-tag,omitempty"`
+	PostTag  []string `yaml:"post-tag,omitempty"`
 }
 
 // ==== Release Notes ====
 
 // ReleaseNotesConfig release notes preferences.
 type ReleaseNotesConfig struct {
-	Headers  map[string]string           `yaml:"headers,omitempty"`
-	Sections []ReleaseNotesSectionConfig `yaml:"sections"`
+	Headers            map[string]string           `yaml:"headers,omitempty"`
+	Sections           []ReleaseNotesSectionConfig `yaml:"sections"`
+	CompareURLTemplate string                      `yaml:"compare-url-template,omitempty"`
+	TagURLTemplate     string                      `yaml:"tag-url-template,omitempty"`
+	PRURLTemplate      string                      `yaml:"pr-url-template,omitempty"`
+	// DetectSharedCommits annotates monorepo release notes entries with the other components changed by the commit.
+	DetectSharedCommits bool `yaml:"detect-shared-commits,omitempty"`
+	// DedupeCherryPicks detects commits included in more than one release of the same changelog, see DedupeCherryPicks.
+	DedupeCherryPicks bool   `yaml:"dedupe-cherry-picks,omitempty"`
+	CherryPickPolicy  string `yaml:"cherry-pick-policy,omitempty"`
+	// ShowSummary renders "X changes by Y contributors" below release headers on default templates.
+	ShowSummary bool `yaml:"show-summary,omitempty"`
+	// DateFormat layout of release dates on default templates, a go time layout or a preset: iso (default), us, eu, short or long.
+	DateFormat string `yaml:"date-format,omitempty"`
+	// Timezone IANA name, e.g. Europe/Berlin, release dates are converted to it before formatting, by default tag dates keep the
+	// timezone of the tagger.
+	Timezone string `yaml:"timezone,omitempty"`
+	// RawMarkdown keeps markdown written on commit subjects, scopes and breaking changes, by default it is escaped on default templates.
+	RawMarkdown bool `yaml:"raw-markdown,omitempty"`
+	// Locale BCP 47 language tag of release dates, e.g. de or de-DE: month and day names and the default date-format, see DateLayout.
+	Locale string `yaml:"locale,omitempty"`
+	// Strings overrides phrases written by default templates, e.g. the summary line, keys are String* constants.
+	Strings map[string]string `yaml:"strings,omitempty"`
+}
+
+// release-notes.date-format presets.
+var dateFormatPresets = map[string]string{
+	"iso": "2006-01-02",
+	"us":  "01/02/2006",
+	"eu":  "02/01/2006",
+}
+
+// DateLayout returns the go time layout of release-notes.date-format, resolving presets. The short and long presets are the
+// numeric and month name layouts of release-notes.locale, english if not defined, short is the default if a locale is defined.
+func (cfg ReleaseNotesConfig) DateLayout() string {
+	format := cfg.DateFormat
+	if format == "" && cfg.Locale != "" {
+		format = "short"
+	}
+	switch format {
+	case "":
+		return dateFormatPresets["iso"]
+	case "short", "long":
+		l, err := findLocale(str(cfg.Locale, "en"))
+		if err != nil {
+			l = locales["en"]
+		}
+		if format == "short" {
+			return l.short
+		}
+		return l.long
+	}
+	if layout, found := dateFormatPresets[format]; found {
+		return layout
+	}
+	return format
+}
+
+// Validate checks release-notes.locale and release-notes.strings keys.
+func (cfg ReleaseNotesConfig) Validate() error {
+	if cfg.Locale != "" {
+		if _, err := findLocale(cfg.Locale); err != nil {
+			return err
+		}
+	}
+	return validateStrings(cfg.Strings)
+}
+
+// Location returns the release-notes.timezone location, nil if not defined.
+func (cfg ReleaseNotesConfig) Location() (*time.Location, error) {
+	if cfg.Timezone == "" {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release-notes.timezone: %s, message: %v", cfg.Timezone, err)
+	}
+	return location, nil
+}
+
+// catchAllSection returns the first commits section with catch-all enabled, nil if there is none.
+func (cfg ReleaseNotesConfig) catchAllSection() *ReleaseNotesSectionConfig {
+	for _, sectionCfg := range cfg.Sections {
+		if sectionCfg.SectionType == ReleaseNotesSectionTypeCommits && sectionCfg.CatchAll {
+			return &sectionCfg
+		}
+	}
+	return nil
 }
 
 func (cfg ReleaseNotesConfig) sectionConfig(sectionType string) *ReleaseNotesSectionConfig {
@@ -84,10 +592,17 @@ func (cfg ReleaseNotesConfig) sectionConfig(sectionType string) *ReleaseNotesSec
 }
 
 // ReleaseNotesSectionConfig preferences for a single section on release notes.
+// Sections are rendered in config order, commit types listed together are merged on the same section.
 type ReleaseNotesSectionConfig struct {
 	Name        string   `yaml:"name"`
 	SectionType string   `yaml:"section-type"`
 	CommitTypes []string `yaml:"commit-types,flow,omitempty"`
+	// Hidden removes commits of the section types from release notes, breaking changes of these commits are still rendered.
+	Hidden bool `yaml:"hidden,omitempty"`
+	// CatchAll commits section that also receives commits with types not listed on any other section, otherwise they are dropped.
+	CatchAll bool `yaml:"catch-all,omitempty"`
+	// Prefix rendered before the section name on default templates, e.g. an emoji, empty by default.
+	Prefix string `yaml:"prefix,omitempty"`
 }
 
 const (
@@ -101,6 +616,258 @@ const (
 
 // MonorepoConfig monorepo versioning preferences.
 type MonorepoConfig struct {
-	VersioningFile string `yaml:"versioning-file"`
-	Path           string `yaml:"path"`
+	VersioningFile    string   `yaml:"versioning-file"`
+	Path              string   `yaml:"path"`
+	NamePath          string   `yaml:"name-path"`
+	Exclude           []string `yaml:"exclude,flow"`
+	ChangelogPath     string   `yaml:"changelog-path"`
+	OnParseError      string   `yaml:"on-parse-error"`
+	BumpCommitMessage string   `yaml:"bump-commit-message"`
+	// Changelog format of component changelogs written by monorepo-changelog.
+	Changelog MonorepoChangelogConfig `yaml:"changelog,omitempty"`
+	// TagPush how monorepo-tag pushes component tags: batch (default), one push after every tag was created, or per-tag.
+	TagPush string `yaml:"tag-push,omitempty"`
+	// VersionFormat regex matching the whole version string of versioning files, its first capture group is the semantic version,
+	// e.g. ^release-(.+)$, text around the group is kept when versions are updated. A leading v is always kept.
+	VersionFormat string `yaml:"version-format,omitempty"`
+	// Prerelease channels of components, versions created on mapped branches are prereleases, e.g. 1.4.0-beta.2.
+	Prerelease MonorepoPrereleaseConfig `yaml:"prerelease,omitempty"`
+	// VersioningFiles versioning files of each component when versioning-file is a list, the first one is authoritative
+	// and also defines VersioningFile and Path. Versions are read from the authoritative file and written to every file.
+	VersioningFiles []VersioningFileConfig `yaml:"-"`
+	// Components settings of single components, matched by component name.
+	Components []MonorepoComponentConfig `yaml:"components,omitempty"`
+	// ComponentDirs directory glob of expected components, e.g. services/*, used by monorepo-status to report directories
+	// without versioning file. Components are still found only by their versioning files.
+	ComponentDirs string `yaml:"component-dirs,omitempty"`
+	// Dependencies declaration files of dependencies between components, used by monorepo-impact to find the components
+	// depending on another one, DefaultDependencyFiles if empty.
+	Dependencies []MonorepoDependencyConfig `yaml:"dependencies,omitempty"`
+}
+
+// MonorepoDependencyConfig dependency declaration file of monorepo components, dependencies are read with paths on json
+// and yaml files or with pattern on other files, e.g. go.mod.
+type MonorepoDependencyConfig struct {
+	// File glob of declaration files relative to each component directory, e.g. package.json or charts/*/Chart.yaml.
+	File string `yaml:"file"`
+	// Paths of dependencies on json and yaml files: keys and string values of objects, and string items or string fields
+	// of list items, e.g. name and repository of Chart.yaml dependencies.
+	Paths []string `yaml:"paths,flow,omitempty"`
+	// Pattern regex matched against each line of other files, its name group, or first group, is a dependency.
+	Pattern string `yaml:"pattern,omitempty"`
+	// NamePath path of the name the component is published with on json and yaml files, e.g. name on package.json.
+	NamePath string `yaml:"name-path,omitempty"`
+	// NamePattern regex matching the name the component is published with on other files, e.g. ^module\s+(\S+) on go.mod.
+	NamePattern string `yaml:"name-pattern,omitempty"`
+}
+
+// DefaultDependencyFiles dependency declaration files used when monorepo.dependencies is not configured.
+var DefaultDependencyFiles = []MonorepoDependencyConfig{
+	{File: "package.json", Paths: []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}, NamePath: "name"},
+	{File: "Chart.yaml", Paths: []string{"dependencies"}, NamePath: "name"},
+}
+
+// DependencyFiles returns monorepo.dependencies, DefaultDependencyFiles if not configured.
+func (cfg MonorepoConfig) DependencyFiles() []MonorepoDependencyConfig {
+	if len(cfg.Dependencies) == 0 {
+		return DefaultDependencyFiles
+	}
+	return cfg.Dependencies
+}
+
+// Validate checks monorepo.dependencies entry, one of paths or pattern is required.
+func (cfg MonorepoDependencyConfig) Validate() error {
+	if file := path.Clean(cfg.File); cfg.File == "" || !validPathPattern(file) || path.IsAbs(file) || file == "." || file == ".." || strings.HasPrefix(file, "../") {
+		return fmt.Errorf("invalid file %q, use a slash separated glob relative to component directories, e.g. package.json", cfg.File)
+	}
+	if (len(cfg.Paths) == 0) == (cfg.Pattern == "") {
+		return fmt.Errorf("file %s: use paths for json and yaml files or pattern for other files", cfg.File)
+	}
+	for _, p := range append(append([]string{}, cfg.Paths...), cfg.NamePath) {
+		if _, err := parsePath(p); p != "" && err != nil {
+			return fmt.Errorf("file %s: invalid path %q: %v", cfg.File, p, err)
+		}
+	}
+	for _, pattern := range []string{cfg.Pattern, cfg.NamePattern} {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("file %s: invalid pattern %q: %v", cfg.File, pattern, err)
+		}
+		if pattern != "" && r.NumSubexp() == 0 {
+			return fmt.Errorf("file %s: invalid pattern %q, a capture group for the name is required", cfg.File, pattern)
+		}
+	}
+	return nil
+}
+
+// MonorepoComponentConfig settings of a monorepo component.
+type MonorepoComponentConfig struct {
+	Name string `yaml:"name"`
+	// MaxBump highest version update allowed by commits: patch, minor or major, no limit if empty.
+	MaxBump string `yaml:"max-bump,omitempty"`
+	// PreviousPaths directories of the component before it was renamed or moved, relative to repository root, e.g.
+	// services/payments for services/billing. Their commits and tags are part of the component history.
+	PreviousPaths []string `yaml:"previous-paths,flow,omitempty"`
+}
+
+// MonorepoChangelogConfig component changelog preferences.
+type MonorepoChangelogConfig struct {
+	// StripTagPrefix uses the version of component tags on headings, e.g. v1.1.0 instead of sigma/v1.1.0.
+	StripTagPrefix bool `yaml:"strip-tag-prefix,omitempty"`
+	// RelativePaths rewrites component paths mentioned on commits to be relative to the component directory, see RelativeCommitPaths.
+	RelativePaths bool `yaml:"relative-paths,omitempty"`
+}
+
+// VersioningFileConfig versioning file glob of monorepo components and the path of the version inside the file,
+// monorepo.path is used if path is empty.
+type VersioningFileConfig struct {
+	Glob string `yaml:"glob"`
+	Path string `yaml:"path,omitempty"`
+}
+
+// UnmarshalYAML decodes monorepo config, versioning-file can be a glob or a list of glob and path pairs.
+func (cfg *MonorepoConfig) UnmarshalYAML(node *yaml.Node) error {
+	type plain MonorepoConfig
+	var files []VersioningFileConfig
+	if node.Kind == yaml.MappingNode {
+		fields := *node
+		fields.Content = make([]*yaml.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "versioning-file" && value.Kind == yaml.SequenceNode {
+				if err := value.Decode(&files); err != nil {
+					return err
+				}
+				if len(files) == 0 {
+					return fmt.Errorf("monorepo.versioning-file list is empty")
+				}
+				continue
+			}
+			fields.Content = append(fields.Content, key, value)
+		}
+		node = &fields
+	}
+	if err := node.Decode((*plain)(cfg)); err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		cfg.VersioningFiles = files
+		cfg.VersioningFile = files[0].Glob
+		if files[0].Path != "" {
+			cfg.Path = files[0].Path
+		}
+	}
+	return nil
+}
+
+// MarshalYAML encodes monorepo config, versioning-file is encoded as a list when VersioningFiles is defined.
+func (cfg MonorepoConfig) MarshalYAML() (interface{}, error) {
+	type plain MonorepoConfig
+	var node yaml.Node
+	if err := node.Encode(plain(cfg)); err != nil {
+		return nil, err
+	}
+	if len(cfg.VersioningFiles) == 0 {
+		return &node, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "versioning-file" {
+			var files yaml.Node
+			if err := files.Encode(cfg.VersioningFiles); err != nil {
+				return nil, err
+			}
+			node.Content[i+1] = &files
+		}
+	}
+	return &node, nil
+}
+
+// SecondaryVersioningFiles returns versioning files kept in sync with the authoritative one, entries without path use monorepo.path.
+func (cfg MonorepoConfig) SecondaryVersioningFiles() []VersioningFileConfig {
+	if len(cfg.VersioningFiles) < 2 {
+		return nil
+	}
+	files := make([]VersioningFileConfig, 0, len(cfg.VersioningFiles)-1)
+	for _, file := range cfg.VersioningFiles[1:] {
+		files = append(files, VersioningFileConfig{Glob: file.Glob, Path: str(file.Path, cfg.Path)})
+	}
+	return files
 }
+
+// Validate checks monorepo version-format regex, versioning-file list and components.
+func (cfg MonorepoConfig) Validate() error {
+	for i, file := range cfg.VersioningFiles {
+		if file.Glob == "" {
+			return fmt.Errorf("monorepo.versioning-file entry %d: glob is required", i+1)
+		}
+	}
+	names := make(map[string]bool)
+	for i, component := range cfg.Components {
+		if component.Name == "" {
+			return fmt.Errorf("monorepo.components entry %d: name is required", i+1)
+		}
+		if names[component.Name] {
+			return fmt.Errorf("duplicated monorepo.components name: %s", component.Name)
+		}
+		names[component.Name] = true
+		switch component.MaxBump {
+		case "", "patch", "minor", "major":
+		default:
+			return fmt.Errorf("invalid monorepo.components max-bump %q for %s, use: patch, minor or major", component.MaxBump, component.Name)
+		}
+		for _, previous := range component.PreviousPaths {
+			if clean := path.Clean(previous); previous == "" || clean == "." || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || clean != previous {
+				return fmt.Errorf("invalid monorepo.components previous-paths %q for %s, use a slash separated directory relative to repository root", previous, component.Name)
+			}
+		}
+	}
+	if dirs := path.Clean(cfg.ComponentDirs); cfg.ComponentDirs != "" && (!validPathPattern(dirs) || path.IsAbs(dirs) || dirs == "." || dirs == ".." || strings.HasPrefix(dirs, "../")) {
+		return fmt.Errorf("invalid monorepo.component-dirs %q, use a slash separated directory glob relative to repository root, e.g. services/*", cfg.ComponentDirs)
+	}
+	for i, dependency := range cfg.Dependencies {
+		if err := dependency.Validate(); err != nil {
+			return fmt.Errorf("invalid monorepo.dependencies entry %d: %v", i+1, err)
+		}
+	}
+	switch cfg.TagPush {
+	case "", TagPushBatch, TagPushPerTag:
+	default:
+		return fmt.Errorf("invalid monorepo.tag-push: %s, use: %s or %s", cfg.TagPush, TagPushBatch, TagPushPerTag)
+	}
+	if cfg.VersionFormat == "" {
+		return nil
+	}
+	_, err := versionFormatRegex(cfg.VersionFormat)
+	return err
+}
+
+// component returns the settings of component name, empty if not configured.
+func (cfg MonorepoConfig) component(name string) MonorepoComponentConfig {
+	for _, component := range cfg.Components {
+		if component.Name == name {
+			return component
+		}
+	}
+	return MonorepoComponentConfig{}
+}
+
+// MonorepoPrereleaseConfig prerelease channels of monorepo components.
+type MonorepoPrereleaseConfig struct {
+	// BranchMap maps branch regexes, matching the whole branch name, to prerelease identifiers, e.g. develop: beta.
+	BranchMap map[string]string `yaml:"branch-map,omitempty"`
+}
+
+const (
+	// OnParseErrorFail MonorepoConfig.OnParseError value, abort when a versioning file could not be parsed.
+	OnParseErrorFail = "fail"
+	// OnParseErrorSkip MonorepoConfig.OnParseError value, ignore components whose versioning file could not be parsed.
+	OnParseErrorSkip = "skip"
+	// OnParseErrorWarn MonorepoConfig.OnParseError value, ignore invalid components and exit with error after processing the others.
+	OnParseErrorWarn = "warn"
+)
+
+// constants for MonorepoConfig.TagPush.
+const (
+	TagPushBatch  = "batch"
+	TagPushPerTag = "per-tag"
+)
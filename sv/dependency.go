@@ -0,0 +1,191 @@
+package sv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ComponentDependency dependency on a component declared by another component, see MonorepoConfig.Dependencies.
+type ComponentDependency struct {
+	Component  string `json:"component"`  // dependent component name
+	File       string `json:"file"`       // declaration file, slash separated relative to repository root
+	Dependency string `json:"dependency"` // declared dependency matching the component, a name or a relative path
+}
+
+// FindDependents returns the dependencies on component declared by the other components on monorepo.dependencies files,
+// sorted by dependent component and file. A dependency refers to component by its name, its directory relative to
+// repository root, the name read with name-path or name-pattern from its own declaration files, or by a relative path to
+// its directory, e.g. file://../shared-lib on Chart.yaml or file:../shared-lib on package.json.
+func FindDependents(repoRoot string, cfg MonorepoConfig, components []MonorepoComponent, component MonorepoComponent) ([]ComponentDependency, error) {
+	files := cfg.DependencyFiles()
+	names, err := componentNames(repoRoot, component, files, cfg.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ComponentDependency
+	for _, dependent := range components {
+		if dependent.Name == component.Name {
+			continue
+		}
+		for _, file := range files {
+			matches, err := globFiles(dependent.RootPath, file.File, cfg.Exclude)
+			if err != nil {
+				return nil, fmt.Errorf("invalid monorepo.dependencies file %q: %v", file.File, err)
+			}
+			for _, match := range matches {
+				if components[componentOf(components, match)].Name != dependent.Name {
+					continue // file of a nested component
+				}
+				dependencies, err := readDependencies(match, file)
+				if err != nil {
+					return nil, fmt.Errorf("reading dependencies from %s: %v", match, err)
+				}
+				for _, dependency := range dependencies {
+					if !names[dependency] && !refersTo(filepath.Dir(match), dependency, component.RootPath) {
+						continue
+					}
+					rel, err := filepath.Rel(repoRoot, match)
+					if err != nil {
+						return nil, err
+					}
+					result = append(result, ComponentDependency{Component: dependent.Name, File: filepath.ToSlash(rel), Dependency: dependency})
+					break // a file is reported once, e.g. Chart.yaml dependencies with name and repository
+				}
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Component != result[j].Component {
+			return result[i].Component < result[j].Component
+		}
+		return result[i].File < result[j].File
+	})
+	return result, nil
+}
+
+// componentNames returns the names component is referenced by: its name, its directory and the names read from its declaration files.
+func componentNames(repoRoot string, component MonorepoComponent, files []MonorepoDependencyConfig, exclude []string) (map[string]bool, error) {
+	names := map[string]bool{component.Name: true}
+	if rel, err := filepath.Rel(repoRoot, component.RootPath); err == nil {
+		names[filepath.ToSlash(rel)] = true
+	}
+	for _, file := range files {
+		if file.NamePath == "" && file.NamePattern == "" {
+			continue
+		}
+		matches, err := globFiles(component.RootPath, file.File, exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid monorepo.dependencies file %q: %v", file.File, err)
+		}
+		for _, match := range matches {
+			content, err := os.ReadFile(match)
+			if err != nil {
+				return nil, err
+			}
+			if file.NamePath != "" {
+				if name, err := readStringByPath(match, content, file.NamePath); err == nil {
+					names[name] = true
+				}
+				continue
+			}
+			if values := patternValues(content, file.NamePattern); len(values) > 0 {
+				names[values[0]] = true
+			}
+		}
+	}
+	return names, nil
+}
+
+// readDependencies reads the dependencies declared on filePath with paths or pattern of file.
+func readDependencies(filePath string, file MonorepoDependencyConfig) ([]string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if file.Pattern != "" {
+		return patternValues(content, file.Pattern), nil
+	}
+
+	data, err := parseFileContent(filePath, content)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, dotPath := range file.Paths {
+		segments, err := parsePath(dotPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %v", dotPath, err)
+		}
+		value, err := getByPath(data, segments)
+		if err != nil {
+			continue // e.g. package.json without devDependencies
+		}
+		result = append(result, dependencyValues(value)...)
+	}
+	return result, nil
+}
+
+// dependencyValues returns keys and string values of objects, string items and string fields of list items.
+func dependencyValues(value interface{}) []string {
+	var result []string
+	switch v := value.(type) {
+	case string:
+		result = append(result, v)
+	case map[string]interface{}:
+		for key, item := range v {
+			result = append(result, key)
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if fields, ok := item.(map[string]interface{}); ok {
+				for _, field := range fields {
+					if s, ok := field.(string); ok {
+						result = append(result, s)
+					}
+				}
+				continue
+			}
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// patternValues returns the name group, or first group, of each line of content matching pattern.
+func patternValues(content []byte, pattern string) []string {
+	r, err := regexp.Compile(pattern)
+	if err != nil || r.NumSubexp() == 0 {
+		return nil
+	}
+	group := r.SubexpIndex("name")
+	if group < 0 {
+		group = 1
+	}
+	var result []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if match := r.FindStringSubmatch(strings.TrimRight(line, "\r")); match != nil && match[group] != "" {
+			result = append(result, match[group])
+		}
+	}
+	return result
+}
+
+// refersTo check if dependency is a relative path, optionally prefixed by file: or file://, from dir to the component root.
+func refersTo(dir, dependency, root string) bool {
+	value := strings.TrimPrefix(strings.TrimPrefix(dependency, "file:"), "//")
+	if !strings.HasPrefix(value, "./") && !strings.HasPrefix(value, "../") {
+		return false
+	}
+	return filepath.Join(dir, filepath.FromSlash(value)) == filepath.Clean(root)
+}
@@ -0,0 +1,99 @@
+package sv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindDependents(t *testing.T) {
+	root := t.TempDir()
+	for file, content := range map[string]string{
+		"libs/shared/version.yml":     "version: 1.0.0\n",
+		"libs/shared/package.json":    `{"name": "@acme/shared", "version": "1.0.0"}`,
+		"libs/shared/go.mod":          "module example.com/acme/shared\n",
+		"apps/web/version.yml":        "version: 1.0.0\n",
+		"apps/web/package.json":       `{"name": "web", "dependencies": {"react": "^18.0.0"}, "devDependencies": {"@acme/shared": "workspace:*"}}`,
+		"apps/local/version.yml":      "version: 1.0.0\n",
+		"apps/local/package.json":     `{"name": "local", "dependencies": {"shared": "file:../../libs/shared"}}`,
+		"charts/api/version.yml":      "version: 1.0.0\n",
+		"charts/api/Chart.yaml":       "name: api\ndependencies:\n  - name: common\n    repository: file://../../libs/shared\n",
+		"services/api/version.yml":    "version: 1.0.0\n",
+		"services/api/go.mod":         "module example.com/acme/api\n\nrequire (\n\texample.com/acme/shared v1.0.0\n)\n",
+		"services/other/version.yml":  "version: 1.0.0\n",
+		"services/other/package.json": `{"name": "other", "dependencies": {"@acme/shared-utils": "1.0.0"}}`,
+	} {
+		fpath := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fpath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	goMod := MonorepoDependencyConfig{File: "go.mod", Pattern: `^\s*(?:require\s+)?(?P<name>[^\s()]+)\s+v\d`, NamePattern: `^module\s+(\S+)`}
+
+	tests := []struct {
+		name         string
+		dependencies []MonorepoDependencyConfig
+		want         []ComponentDependency
+	}{
+		{"default files", nil, []ComponentDependency{
+			{Component: "apps/local", File: "apps/local/package.json", Dependency: "file:../../libs/shared"},
+			{Component: "apps/web", File: "apps/web/package.json", Dependency: "@acme/shared"},
+			{Component: "charts/api", File: "charts/api/Chart.yaml", Dependency: "file://../../libs/shared"},
+		}},
+		{"go.mod pattern", []MonorepoDependencyConfig{goMod}, []ComponentDependency{
+			{Component: "services/api", File: "services/api/go.mod", Dependency: "example.com/acme/shared"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := MonorepoConfig{VersioningFile: "**/version.yml", Path: "version", Dependencies: tt.dependencies}
+			components, _, err := NewMonorepoProcessor().FindComponents(root, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var shared MonorepoComponent
+			for _, component := range components {
+				if component.Name == "libs/shared" {
+					shared = component
+				}
+			}
+			got, err := FindDependents(root, cfg, components, shared)
+			if err != nil {
+				t.Fatalf("FindDependents() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindDependents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonorepoDependencyConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     MonorepoDependencyConfig
+		wantErr bool
+	}{
+		{"paths", MonorepoDependencyConfig{File: "package.json", Paths: []string{"dependencies"}, NamePath: "name"}, false},
+		{"pattern", MonorepoDependencyConfig{File: "go.mod", Pattern: `^require (\S+)`, NamePattern: `^module (\S+)`}, false},
+		{"nested glob", MonorepoDependencyConfig{File: "charts/*/Chart.yaml", Paths: []string{"dependencies"}}, false},
+		{"without file", MonorepoDependencyConfig{Paths: []string{"dependencies"}}, true},
+		{"file outside component", MonorepoDependencyConfig{File: "../package.json", Paths: []string{"dependencies"}}, true},
+		{"without paths and pattern", MonorepoDependencyConfig{File: "package.json"}, true},
+		{"paths and pattern", MonorepoDependencyConfig{File: "package.json", Paths: []string{"dependencies"}, Pattern: "(.+)"}, true},
+		{"invalid path", MonorepoDependencyConfig{File: "package.json", Paths: []string{`a["b`}}, true},
+		{"invalid pattern", MonorepoDependencyConfig{File: "go.mod", Pattern: "(["}, true},
+		{"pattern without group", MonorepoDependencyConfig{File: "go.mod", Pattern: "^require"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("MonorepoDependencyConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
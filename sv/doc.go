@@ -0,0 +1,16 @@
+// Package sv implements semantic versioning using git and conventional commits.
+//
+// It's the library used by git-sv cli and can be used to compute next versions,
+// validate commit messages and generate release notes programmatically:
+//
+//	cfg := sv.NewDefaultConfig()
+//	messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+//	git := sv.NewGit(messageProcessor, cfg.Tag, cfg.Log)
+//	semverProcessor := sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage)
+//	releaseNoteProcessor := sv.NewReleaseNoteProcessor(cfg.ReleaseNotes)
+//	outputFormatter := sv.NewDefaultOutputFormatter()
+//
+// Git executes git commands on the current working directory, every other
+// processor works only with the values received, so they can be used with
+// commits recovered from other sources.
+package sv
@@ -0,0 +1,62 @@
+package sv_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bvieira/sv4git/v2/sv"
+)
+
+func ExampleSemVerCommitsProcessorImpl_NextVersion() {
+	cfg := sv.NewDefaultConfig()
+	messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+	semverProcessor := sv.NewSemVerCommitsProcessor(cfg.Versioning, cfg.CommitMessage)
+
+	message, _ := messageProcessor.Parse("feat(api): add endpoint", "")
+	commits := []sv.GitCommitLog{{Hash: "a1b2c3d", Message: message}}
+
+	next, updated := semverProcessor.NextVersion(semver.MustParse("1.2.3"), commits)
+	fmt.Println(next, updated)
+	// Output: 1.3.0 true
+}
+
+func ExampleOutputFormatterImpl_FormatReleaseNote() {
+	cfg := sv.NewDefaultConfig()
+	messageProcessor := sv.NewMessageProcessor(cfg.CommitMessage, cfg.Branches)
+	releaseNoteProcessor := sv.NewReleaseNoteProcessor(cfg.ReleaseNotes)
+	outputFormatter := sv.NewDefaultOutputFormatter()
+
+	feat, _ := messageProcessor.Parse("feat(api): add endpoint", "")
+	fix, _ := messageProcessor.Parse("fix: handle empty values", "")
+	commits := []sv.GitCommitLog{{Hash: "a1b2c3d", Message: feat}, {Hash: "e4f5a6b", Message: fix}}
+
+	date := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	releaseNote := releaseNoteProcessor.Create(semver.MustParse("1.3.0"), "v1.3.0", "v1.2.0", date, commits)
+
+	output, _ := outputFormatter.FormatReleaseNote(releaseNote)
+	fmt.Println(output)
+	// Output:
+	// ## v1.3.0 (2022-03-01)
+	//
+	// ### Features
+	//
+	// - **api:** add endpoint (a1b2c3d)
+	//
+	// ### Bug Fixes
+	//
+	// - handle empty values (e4f5a6b)
+}
+
+func ExampleParseCommitMessage() {
+	message, err := sv.ParseCommitMessage("feat(api)!: remove v1 endpoints", "BREAKING CHANGE: v1 endpoints are not available anymore")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(message.Type, message.Scope, message.IsBreakingChange)
+	fmt.Println(message.BreakingMessage())
+	// Output:
+	// feat api true
+	// v1 endpoints are not available anymore
+}
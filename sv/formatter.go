@@ -2,9 +2,13 @@ package sv
 
 import (
 	"bytes"
+	"embed"
+	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"sort"
+	"strings"
 	"text/template"
 	"time"
 
@@ -12,40 +16,187 @@ import (
 )
 
 type releaseNoteTemplateVariables struct {
-	Release     string
-	Tag         string
-	Version     *semver.Version
-	Date        time.Time
-	Sections    []ReleaseNoteSection
-	AuthorNames []string
+	Release      string
+	Tag          string
+	PreviousTag  string
+	CompareURL   string
+	Version      *semver.Version
+	Date         time.Time
+	DateFormat   string
+	Sections     []ReleaseNoteSection
+	AuthorNames  []string
+	CommitCount  int
+	Contributors []string
+	ShowSummary  bool
+	Summary      string // changes and contributors summary line, see release-notes.strings
+}
+
+type monorepoComponentTemplateVariables struct {
+	Name         string
+	ReleaseNotes []releaseNoteTemplateVariables
+}
+
+//go:embed resources/templates/*.tpl
+var defaultTemplatesFS embed.FS
+
+// DefaultTemplatesFS returns the default markdown templates used to format
+// release notes and changelogs.
+func DefaultTemplatesFS() fs.FS {
+	templatesFS, _ := fs.Sub(defaultTemplatesFS, "resources/templates")
+	return templatesFS
 }
 
 // OutputFormatter output formatter interface.
 type OutputFormatter interface {
 	FormatReleaseNote(releasenote ReleaseNote) (string, error)
 	FormatChangelog(releasenotes []ReleaseNote) (string, error)
+	FormatMonorepoChangelog(releasenotes map[string][]ReleaseNote) (string, error)
+	WriteChangelog(w io.Writer, next ReleaseNoteSource) error
+}
+
+// ReleaseNoteSource returns the next release note of a changelog, ok is false after the last one.
+type ReleaseNoteSource func() (releasenote ReleaseNote, ok bool, err error)
+
+// ReleaseNotes returns a source of releasenotes, in order.
+func ReleaseNotes(releasenotes []ReleaseNote) ReleaseNoteSource {
+	i := 0
+	return func() (ReleaseNote, bool, error) {
+		if i >= len(releasenotes) {
+			return ReleaseNote{}, false, nil
+		}
+		i++
+		return releasenotes[i-1], true, nil
+	}
 }
 
 // OutputFormatterImpl formater for release note and changelog.
 type OutputFormatterImpl struct {
-	templates *template.Template
+	templates   *template.Template
+	dateFormat  string
+	location    *time.Location
+	rawMarkdown bool
+	locale      *locale
+	strings     map[string]string
+	style       string
 }
 
+// Release notes styles, see OutputFormatterImpl.SetStyle.
+const (
+	StyleDefault   = "default"
+	StyleChecklist = "checklist"
+)
+
 // NewOutputFormatter TemplateProcessor constructor.
 func NewOutputFormatter(templatesFS fs.FS) *OutputFormatterImpl {
+	p := &OutputFormatterImpl{dateFormat: dateFormatPresets["iso"], style: StyleDefault}
 	templateFNs := map[string]interface{}{
-		"timefmt":    timeFormat,
+		"timefmt":    p.timeFormat,
 		"getsection": getSection,
 		"getenv":     os.Getenv,
+		"escape":     p.escape,
+		"text":       p.text,
+		"checklist":  p.checklist,
+	}
+	p.templates = template.Must(template.New("templates").Funcs(templateFNs).ParseFS(templatesFS, "*"))
+	return p
+}
+
+// SetRawMarkdown disables escaping of commit text on templates, for teams that write markdown on commit messages.
+func (p *OutputFormatterImpl) SetRawMarkdown(raw bool) {
+	p.rawMarkdown = raw
+}
+
+// SetStyle sets how default templates render commits: default or checklist, where every commit and breaking change is a
+// task list item, e.g. "- [ ] feat(api): add endpoint", so release notes can be used as a test plan.
+func (p *OutputFormatterImpl) SetStyle(style string) error {
+	switch style {
+	case "", StyleDefault:
+		p.style = StyleDefault
+	case StyleChecklist:
+		p.style = StyleChecklist
+	default:
+		return fmt.Errorf("invalid style: %s, use: %s or %s", style, StyleDefault, StyleChecklist)
+	}
+	return nil
+}
+
+// checklist is used by templates to check if commits are rendered as task list items, see SetStyle.
+func (p *OutputFormatterImpl) checklist() bool {
+	return p.style == StyleChecklist
+}
+
+// escape is used by templates on commit provided text, see escapeMarkdown.
+func (p *OutputFormatterImpl) escape(text string) string {
+	if p.rawMarkdown {
+		return text
+	}
+	return escapeMarkdown(text)
+}
+
+// SetLocale sets the language of month and day names on release dates, see ReleaseNotesConfig.Locale, and overrides phrases of
+// default templates with values, see ReleaseNotesConfig.Strings.
+func (p *OutputFormatterImpl) SetLocale(tag string, values map[string]string) error {
+	if err := validateStrings(values); err != nil {
+		return err
+	}
+	p.locale, p.strings = nil, values
+	if tag == "" {
+		return nil
+	}
+	l, err := findLocale(tag)
+	if err != nil {
+		return err
 	}
-	tpls := template.Must(template.New("templates").Funcs(templateFNs).ParseFS(templatesFS, "*"))
-	return &OutputFormatterImpl{templates: tpls}
+	p.locale = &l
+	return nil
+}
+
+// timeFormat is used by templates to format dates, names are written in the locale language.
+func (p *OutputFormatterImpl) timeFormat(t time.Time, layout string) string {
+	if p.locale == nil || t.IsZero() {
+		return timeFormat(t, layout)
+	}
+	return p.locale.format(t, layout)
+}
+
+// text is used by templates to write phrases, see StringChangelog and other keys.
+func (p *OutputFormatterImpl) text(key string) string {
+	if value, found := p.strings[key]; found {
+		return value
+	}
+	return defaultStrings[key]
+}
+
+// summary returns the summary line of a release with commits and contributors.
+func (p *OutputFormatterImpl) summary(commits, contributors int) string {
+	count := func(n int, one, other string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, p.text(one))
+		}
+		return fmt.Sprintf("%d %s", n, p.text(other))
+	}
+	return strings.NewReplacer(
+		"{changes}", count(commits, StringChange, StringChanges),
+		"{contributors}", count(contributors, StringContributor, StringContributors),
+	).Replace(p.text(StringSummary))
+}
+
+// SetDateFormat sets the layout of release dates on default templates, available to templates as .DateFormat,
+// and the location release dates are converted to, nil keeps dates unchanged.
+func (p *OutputFormatterImpl) SetDateFormat(layout string, location *time.Location) {
+	p.dateFormat = layout
+	p.location = location
+}
+
+// NewDefaultOutputFormatter OutputFormatterImpl constructor using the default templates.
+func NewDefaultOutputFormatter() *OutputFormatterImpl {
+	return NewOutputFormatter(DefaultTemplatesFS())
 }
 
 // FormatReleaseNote format a release note.
 func (p OutputFormatterImpl) FormatReleaseNote(releasenote ReleaseNote) (string, error) {
 	var b bytes.Buffer
-	if err := p.templates.ExecuteTemplate(&b, "releasenotes-md.tpl", releaseNoteVariables(releasenote)); err != nil {
+	if err := p.templates.ExecuteTemplate(&b, "releasenotes-md.tpl", p.releaseNoteVariables(releasenote)); err != nil {
 		return "", err
 	}
 	return b.String(), nil
@@ -53,30 +204,94 @@ func (p OutputFormatterImpl) FormatReleaseNote(releasenote ReleaseNote) (string,
 
 // FormatChangelog format a changelog.
 func (p OutputFormatterImpl) FormatChangelog(releasenotes []ReleaseNote) (string, error) {
-	templateVars := make([]releaseNoteTemplateVariables, len(releasenotes))
-	for i, v := range releasenotes {
-		templateVars[i] = releaseNoteVariables(v)
+	var b bytes.Buffer
+	if err := p.WriteChangelog(&b, ReleaseNotes(releasenotes)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// WriteChangelog formats a changelog on w reading one release note at a time from next, changelog-md.tpl ranges over
+// release notes as they are created, so memory does not grow with the number of releases.
+func (p OutputFormatterImpl) WriteChangelog(w io.Writer, next ReleaseNoteSource) error {
+	templateVars := make(chan releaseNoteTemplateVariables)
+	done := make(chan struct{})
+	var nextErr error
+	go func() {
+		defer close(templateVars)
+		for {
+			rn, ok, err := next()
+			if err != nil || !ok {
+				nextErr = err
+				return
+			}
+			select {
+			case templateVars <- p.releaseNoteVariables(rn):
+			case <-done: // template failed, stop reading release notes
+				return
+			}
+		}
+	}()
+
+	err := p.templates.ExecuteTemplate(w, "changelog-md.tpl", templateVars)
+	close(done)
+	for range templateVars { // drain, the goroutine may be sending a release note
+	}
+	if nextErr != nil {
+		return nextErr
+	}
+	return err
+}
+
+// FormatMonorepoChangelog format a changelog with a heading per component sorted by name,
+// components without release notes are omitted.
+func (p OutputFormatterImpl) FormatMonorepoChangelog(releasenotes map[string][]ReleaseNote) (string, error) {
+	names := make([]string, 0, len(releasenotes))
+	for name, rn := range releasenotes {
+		if len(rn) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	templateVars := make([]monorepoComponentTemplateVariables, len(names))
+	for i, name := range names {
+		templateVars[i] = monorepoComponentTemplateVariables{Name: name, ReleaseNotes: make([]releaseNoteTemplateVariables, len(releasenotes[name]))}
+		for j, rn := range releasenotes[name] {
+			templateVars[i].ReleaseNotes[j] = p.releaseNoteVariables(rn)
+		}
 	}
 
 	var b bytes.Buffer
-	if err := p.templates.ExecuteTemplate(&b, "changelog-md.tpl", templateVars); err != nil {
+	if err := p.templates.ExecuteTemplate(&b, "monorepo-changelog-md.tpl", templateVars); err != nil {
 		return "", err
 	}
 	return b.String(), nil
 }
 
-func releaseNoteVariables(releasenote ReleaseNote) releaseNoteTemplateVariables {
+func (p OutputFormatterImpl) releaseNoteVariables(releasenote ReleaseNote) releaseNoteTemplateVariables {
 	release := releasenote.Tag
 	if releasenote.Version != nil {
 		release = "v" + releasenote.Version.String()
 	}
+	date := releasenote.Date
+	if p.location != nil && !date.IsZero() {
+		date = date.In(p.location)
+	}
 	return releaseNoteTemplateVariables{
-		Release:     release,
-		Tag:         releasenote.Tag,
-		Version:     releasenote.Version,
-		Date:        releasenote.Date,
-		Sections:    releasenote.Sections,
-		AuthorNames: toSortedArray(releasenote.AuthorsNames),
+		Release:      release,
+		Tag:          releasenote.Tag,
+		PreviousTag:  releasenote.PreviousTag,
+		CompareURL:   releasenote.CompareURL,
+		Version:      releasenote.Version,
+		Date:         date,
+		DateFormat:   p.dateFormat,
+		Sections:     releasenote.Sections,
+		AuthorNames:  toSortedArray(releasenote.AuthorsNames),
+		CommitCount:  releasenote.CommitCount,
+		Contributors: releasenote.Contributors,
+		ShowSummary:  releasenote.ShowSummary,
+		Summary:      p.summary(releasenote.CommitCount, len(releasenote.Contributors)),
 	}
 }
 
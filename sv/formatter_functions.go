@@ -1,6 +1,9 @@
 package sv
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 func timeFormat(t time.Time, format string) string {
 	if t.IsZero() {
@@ -17,3 +20,14 @@ func getSection(sections []ReleaseNoteSection, name string) ReleaseNoteSection {
 	}
 	return nil
 }
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `#`, `\#`, `|`, `\|`, `~`, `\~`,
+	`<`, `&lt;`, `>`, `&gt;`,
+)
+
+// escapeMarkdown escapes characters of text that would be rendered as markdown or html, e.g. __init__ or <script>,
+// issue references like #123 are not auto linked.
+func escapeMarkdown(text string) string {
+	return markdownReplacer.Replace(text)
+}
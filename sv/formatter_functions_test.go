@@ -43,3 +43,22 @@ func Test_getSection(t *testing.T) {
 		})
 	}
 }
+
+func Test_escapeMarkdown(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"__init__", `\_\_init\_\_`},
+		{"<a href='x'>", "&lt;a href='x'&gt;"},
+		{"fix #12 in `main`", "fix \\#12 in \\`main\\`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := escapeMarkdown(tt.text); got != tt.want {
+				t.Errorf("escapeMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,20 @@ package sv
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 )
 
-var templatesFS = os.DirFS("../cmd/git-sv/resources/templates")
+var templatesFS = os.DirFS("resources/templates")
 
 var dateChangelog = `## v1.0.0 (2020-05-01)
 `
@@ -17,6 +23,59 @@ var dateChangelog = `## v1.0.0 (2020-05-01)
 var nonVersioningChangelog = `## abc (2020-05-01)
 `
 
+var compareURLChangelog = `## [v1.0.0](https://example.com/compare/v0.9.0...v1.0.0) (2020-05-01)
+`
+
+var summaryChangelog = `## v1.0.0 (2020-05-01)
+
+_3 changes by 1 contributor_
+`
+
+var pullRequestChangelog = `## v1.0.0 (2020-05-01)
+
+### Features
+
+- subject text () ([#12](https://example.com/pull/12))
+- subject text () (#13)
+`
+
+var sharedCommitChangelog = `## v1.0.0 (2020-05-01)
+
+### Features
+
+- subject text () (shared with: api, web)
+`
+
+var duplicateCommitChangelog = `## v1.0.0 (2020-05-01)
+
+### Features
+
+- subject text () (also in v0.9.1)
+`
+
+var issueTrackersChangelog = `## v1.0.0 (2020-05-01)
+
+### Features
+
+- subject text () ([PROJ-12](https://jira.example.com/browse/PROJ-12), [PROJ-13](https://jira.example.com/browse/PROJ-13)) (#345)
+`
+
+var checklistReleaseNotes = `## v1.0.0 (2020-05-01)
+
+### Features
+
+- [ ] feat(api): add \_\_init\_\_ endpoint (abc1234) ([PROJ-12](https://jira.example.com/browse/PROJ-12))
+- [ ] feat!: subject text (def5678) (PROJ-13) ([#12](https://example.com/pull/12))
+
+### Bug Fixes
+
+- [ ] fix: subject text ()
+
+### Breaking Changes
+
+- [ ] drop v1 client
+`
+
 var emptyDateChangelog = `## v1.0.0
 `
 
@@ -56,6 +115,13 @@ func TestOutputFormatterImpl_FormatReleaseNote(t *testing.T) {
 		{"without version", emptyReleaseNote("", date.Truncate(time.Minute)), emptyVersionChangelog, false},
 		{"non versioning tag", emptyReleaseNote("abc", date.Truncate(time.Minute)), nonVersioningChangelog, false},
 		{"full changelog", fullReleaseNote("1.0.0", date.Truncate(time.Minute)), fullChangeLog, false},
+		{"pull requests", pullRequestReleaseNote(date), pullRequestChangelog, false},
+		{"shared commit", sharedCommitReleaseNote(date), sharedCommitChangelog, false},
+		{"duplicate commit", duplicateCommitReleaseNote(date), duplicateCommitChangelog, false},
+		{"issue trackers", issueTrackersReleaseNote(date), issueTrackersChangelog, false},
+		{"summary", ReleaseNote{Version: semver.MustParse("1.0.0"), Date: date, CommitCount: 3, Contributors: []string{"a"}, ShowSummary: true}, summaryChangelog, false},
+		{"summary disabled", ReleaseNote{Version: semver.MustParse("1.0.0"), Date: date, CommitCount: 3, Contributors: []string{"a"}}, dateChangelog, false},
+		{"compare url", ReleaseNote{Version: semver.MustParse("1.0.0"), Date: date, CompareURL: "https://example.com/compare/v0.9.0...v1.0.0"}, compareURLChangelog, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -71,6 +137,201 @@ func TestOutputFormatterImpl_FormatReleaseNote(t *testing.T) {
 	}
 }
 
+func TestOutputFormatterImpl_SetDateFormat(t *testing.T) {
+	// tagged near midnight, the date differs between the tagger timezone and UTC.
+	tags, _ := parseTagsOutput("2024-03-31 22:30:00 -0300#1.0.0")
+	releaseNotes := []ReleaseNote{emptyReleaseNote("1.0.0", tags[0].Date)}
+
+	tests := []struct {
+		name     string
+		layout   string
+		location *time.Location
+		want     string
+	}{
+		{"tagger timezone", "2006-01-02", nil, "## v1.0.0 (2024-03-31)\n"},
+		{"utc", "2006-01-02", time.UTC, "## v1.0.0 (2024-04-01)\n"},
+		{"ahead of utc", "2006-01-02", time.FixedZone("UTC+9", 9*60*60), "## v1.0.0 (2024-04-01)\n"},
+		{"behind tagger timezone", "2006-01-02", time.FixedZone("UTC-5", -5*60*60), "## v1.0.0 (2024-03-31)\n"},
+		{"us layout", ReleaseNotesConfig{DateFormat: "us"}.DateLayout(), time.UTC, "## v1.0.0 (04/01/2024)\n"},
+		{"go layout", "Jan 2, 2006 15:04 MST", time.UTC, "## v1.0.0 (Apr 1, 2024 01:30 UTC)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := NewOutputFormatter(templatesFS)
+			formatter.SetDateFormat(tt.layout, tt.location)
+			got, err := formatter.FormatChangelog(releaseNotes)
+			if err != nil {
+				t.Fatalf("OutputFormatterImpl.FormatChangelog() unexpected error: %v", err)
+			}
+			if want := "# Changelog\n"; !strings.HasPrefix(got, want) {
+				t.Fatalf("OutputFormatterImpl.FormatChangelog() = %q, want prefix %q", got, want)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("OutputFormatterImpl.FormatChangelog() = %q, want heading %q", got, tt.want)
+			}
+		})
+	}
+}
+
+var monorepoChangelog = `# alpha
+
+## v1.0.0 (2020-05-01)
+
+---
+
+# beta
+
+## v2.0.0 (2020-05-01)
+
+---
+
+## v1.0.0 (2020-05-01)
+
+---
+`
+
+func TestOutputFormatterImpl_FormatMonorepoChangelog(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2020-05-01")
+
+	got, err := NewOutputFormatter(templatesFS).FormatMonorepoChangelog(map[string][]ReleaseNote{
+		"beta":  {emptyReleaseNote("2.0.0", date), emptyReleaseNote("1.0.0", date)},
+		"gamma": {},
+		"alpha": {emptyReleaseNote("1.0.0", date)},
+	})
+	if err != nil {
+		t.Fatalf("OutputFormatterImpl.FormatMonorepoChangelog() unexpected error: %v", err)
+	}
+	if got != monorepoChangelog {
+		t.Errorf("OutputFormatterImpl.FormatMonorepoChangelog() = %q, want %q", got, monorepoChangelog)
+	}
+}
+
+func TestOutputFormatterImpl_WriteChangelog(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2020-05-01")
+	releaseNotes := []ReleaseNote{fullReleaseNote("2.0.0", date), emptyReleaseNote("1.0.0", date)}
+	formatter := NewOutputFormatter(templatesFS)
+
+	want, err := formatter.FormatChangelog(releaseNotes)
+	if err != nil {
+		t.Fatalf("OutputFormatterImpl.FormatChangelog() unexpected error: %v", err)
+	}
+	var b bytes.Buffer
+	if err := formatter.WriteChangelog(&b, ReleaseNotes(releaseNotes)); err != nil {
+		t.Fatalf("OutputFormatterImpl.WriteChangelog() unexpected error: %v", err)
+	}
+	if b.String() != want || !strings.Contains(want, "## v2.0.0") || !strings.Contains(want, "## v1.0.0") {
+		t.Errorf("OutputFormatterImpl.WriteChangelog() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestOutputFormatterImpl_WriteChangelog_Errors(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2020-05-01")
+	sourceErr := errors.New("git log failed")
+	failing := func() (ReleaseNote, bool, error) { return ReleaseNote{}, false, sourceErr }
+	if err := NewOutputFormatter(templatesFS).WriteChangelog(io.Discard, failing); !errors.Is(err, sourceErr) {
+		t.Errorf("OutputFormatterImpl.WriteChangelog() error = %v, want %v", err, sourceErr)
+	}
+
+	// a failing template stops reading release notes instead of waiting for the next one forever.
+	brokenFS := fstest.MapFS{"changelog-md.tpl": {Data: []byte("{{range .}}{{.Missing}}{{end}}")}}
+	endless := func() (ReleaseNote, bool, error) { return emptyReleaseNote("1.0.0", date), true, nil }
+	if err := NewOutputFormatter(brokenFS).WriteChangelog(io.Discard, endless); err == nil {
+		t.Errorf("OutputFormatterImpl.WriteChangelog() expected template error")
+	}
+}
+
+// BenchmarkOutputFormatterImpl_WriteChangelog compares the heap used to format changelogs of generated histories,
+// FormatChangelog holds every release note and the output while WriteChangelog holds one release note at a time.
+func BenchmarkOutputFormatterImpl_WriteChangelog(b *testing.B) {
+	date, _ := time.Parse("2006-01-02", "2020-05-01")
+	formatter := NewOutputFormatter(templatesFS)
+	generate := func(i int) ReleaseNote { return fullReleaseNote(fmt.Sprintf("%d.0.0", i+1), date) }
+
+	for _, releases := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("FormatChangelog/%d", releases), func(b *testing.B) {
+			var peak uint64
+			for n := 0; n < b.N; n++ {
+				releaseNotes := make([]ReleaseNote, releases)
+				for i := range releaseNotes {
+					releaseNotes[i] = generate(i)
+				}
+				output, err := formatter.FormatChangelog(releaseNotes)
+				if err != nil {
+					b.Fatal(err)
+				}
+				peak = maxHeap(peak)
+				_, _ = io.WriteString(io.Discard, output)
+			}
+			b.ReportMetric(float64(peak), "peak-heap-bytes")
+		})
+		b.Run(fmt.Sprintf("WriteChangelog/%d", releases), func(b *testing.B) {
+			var peak uint64
+			for n := 0; n < b.N; n++ {
+				i := 0
+				next := func() (ReleaseNote, bool, error) {
+					if i >= releases {
+						peak = maxHeap(peak)
+						return ReleaseNote{}, false, nil
+					}
+					i++
+					return generate(i - 1), true, nil
+				}
+				if err := formatter.WriteChangelog(io.Discard, next); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(peak), "peak-heap-bytes")
+		})
+	}
+}
+
+// maxHeap returns the greatest of peak and live heap after a garbage collection.
+func maxHeap(peak uint64) uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.HeapAlloc > peak {
+		return stats.HeapAlloc
+	}
+	return peak
+}
+
+func TestOutputFormatterImpl_LocalizedSections(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2020-05-01")
+	processor := NewReleaseNoteProcessor(ReleaseNotesConfig{Sections: []ReleaseNotesSectionConfig{
+		{Name: "Fonctionnalités", Prefix: "✨", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"feat"}},
+		{Name: "Correctifs", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"fix"}},
+		{Name: "Changements majeurs", Prefix: "💥", SectionType: ReleaseNotesSectionTypeBreakingChanges},
+	}})
+	commits := []GitCommitLog{
+		commitlog("feat", map[string]string{breakingChangeMetadataKey: "nouvelle API"}, "a"),
+		commitlog("fix", map[string]string{}, "a"),
+	}
+	releaseNotes := []ReleaseNote{processor.Create(semver.MustParse("1.0.0"), "1.0.0", "", date, commits)}
+
+	got, err := NewOutputFormatter(templatesFS).FormatChangelog(releaseNotes)
+	if err != nil {
+		t.Fatalf("OutputFormatterImpl.FormatChangelog() unexpected error: %v", err)
+	}
+	for _, heading := range []string{"\n### ✨ Fonctionnalités\n", "\n### Correctifs\n", "\n### 💥 Changements majeurs\n"} {
+		if !strings.Contains(got, heading) {
+			t.Errorf("OutputFormatterImpl.FormatChangelog() = %q, want heading %q", got, heading)
+		}
+	}
+
+	customFS := fstest.MapFS{
+		"changelog-md.tpl":    {Data: []byte(`{{range .}}{{template "releasenotes-md.tpl" .}}{{end}}`)},
+		"releasenotes-md.tpl": {Data: []byte(`{{range .Sections}}{{.Prefix}}{{.SectionName}};{{end}}{{with getsection .Sections "Correctifs"}}found{{end}}`)},
+	}
+	got, err = NewOutputFormatter(customFS).FormatChangelog(releaseNotes)
+	if err != nil {
+		t.Fatalf("OutputFormatterImpl.FormatChangelog() with custom templates unexpected error: %v", err)
+	}
+	if want := "✨Fonctionnalités;Correctifs;💥Changements majeurs;found"; got != want {
+		t.Errorf("OutputFormatterImpl.FormatChangelog() with custom templates = %q, want %q", got, want)
+	}
+}
+
 func emptyReleaseNote(tag string, date time.Time) ReleaseNote {
 	v, _ := semver.NewVersion(tag)
 	return ReleaseNote{
@@ -86,11 +347,85 @@ func fullReleaseNote(tag string, date time.Time) ReleaseNote {
 		newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{commitlog("feat", map[string]string{}, "a")}),
 		newReleaseNoteCommitsSection("Bug Fixes", []string{"fix"}, []GitCommitLog{commitlog("fix", map[string]string{}, "a")}),
 		newReleaseNoteCommitsSection("Build", []string{"build"}, []GitCommitLog{commitlog("build", map[string]string{}, "a")}),
-		ReleaseNoteBreakingChangeSection{"Breaking Changes", []string{"break change message"}},
+		ReleaseNoteBreakingChangeSection{Name: "Breaking Changes", Messages: []string{"break change message"}},
 	}
 	return releaseNote(v, tag, date, sections, map[string]struct{}{"a": {}})
 }
 
+func pullRequestReleaseNote(date time.Time) ReleaseNote {
+	commits := []GitCommitLog{
+		commitlog("feat", map[string]string{"pr": "12", "pr-url": "https://example.com/pull/12"}, "a"),
+		commitlog("feat", map[string]string{"pr": "13"}, "a"),
+	}
+	sections := []ReleaseNoteSection{newReleaseNoteCommitsSection("Features", []string{"feat"}, commits)}
+	return releaseNote(semver.MustParse("1.0.0"), "1.0.0", date, sections, map[string]struct{}{"a": {}})
+}
+
+func sharedCommitReleaseNote(date time.Time) ReleaseNote {
+	commits := []GitCommitLog{commitlog("feat", map[string]string{"shared-with": "api, web"}, "a")}
+	sections := []ReleaseNoteSection{newReleaseNoteCommitsSection("Features", []string{"feat"}, commits)}
+	return releaseNote(semver.MustParse("1.0.0"), "1.0.0", date, sections, map[string]struct{}{"a": {}})
+}
+
+func duplicateCommitReleaseNote(date time.Time) ReleaseNote {
+	commits := []GitCommitLog{commitlog("feat", map[string]string{"duplicate-of": "v0.9.1"}, "a")}
+	sections := []ReleaseNoteSection{newReleaseNoteCommitsSection("Features", []string{"feat"}, commits)}
+	return releaseNote(semver.MustParse("1.0.0"), "1.0.0", date, sections, map[string]struct{}{"a": {}})
+}
+
+func issueTrackersReleaseNote(date time.Time) ReleaseNote {
+	commit := commitlog("feat", map[string]string{"issue": "PROJ-12"}, "a")
+	commit.Message.Issues = []IssueReferences{
+		ccfgTrackers.Issue.Trackers[0].References([]string{"PROJ-12", "PROJ-13"}),
+		{Tracker: "refs", Issues: []IssueLink{{ID: "#345"}}},
+	}
+	sections := []ReleaseNoteSection{newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{commit})}
+	return releaseNote(semver.MustParse("1.0.0"), "1.0.0", date, sections, map[string]struct{}{"a": {}})
+}
+
+func checklistReleaseNote(version string, date time.Time) ReleaseNote {
+	scoped := GitCommitLog{Hash: "abc1234", Message: CommitMessage{Type: "feat", Scope: "api", Description: "add __init__ endpoint", Metadata: map[string]string{},
+		Issues: []IssueReferences{ccfgTrackers.Issue.Trackers[0].References([]string{"PROJ-12"})}}}
+	breaking := commitlog("feat", map[string]string{breakingChangeMetadataKey: "drop v1 client", "issue": "PROJ-13", "pr": "12", "pr-url": "https://example.com/pull/12"}, "a")
+	breaking.Hash = "def5678"
+	sections := []ReleaseNoteSection{
+		newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{scoped, breaking}),
+		newReleaseNoteCommitsSection("Bug Fixes", []string{"fix"}, []GitCommitLog{commitlog("fix", map[string]string{}, "a")}),
+		ReleaseNoteBreakingChangeSection{Name: "Breaking Changes", Messages: []string{"drop v1 client"}},
+	}
+	return releaseNote(semver.MustParse(version), version, date, sections, map[string]struct{}{"a": {}})
+}
+
+func TestOutputFormatterImpl_SetStyle(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2020-05-01")
+	previous := strings.Replace(strings.Replace(checklistReleaseNotes, "v1.0.0", "v0.9.0", 1), "2020-05-01", "2020-04-01", 1)
+
+	formatter := NewOutputFormatter(templatesFS)
+	if err := formatter.SetStyle(StyleChecklist); err != nil {
+		t.Fatalf("OutputFormatterImpl.SetStyle() unexpected error: %v", err)
+	}
+	got, err := formatter.FormatReleaseNote(checklistReleaseNote("1.0.0", date))
+	if err != nil || got != checklistReleaseNotes {
+		t.Errorf("OutputFormatterImpl.FormatReleaseNote() = %v, %v, want %v", got, err, checklistReleaseNotes)
+	}
+	got, err = formatter.FormatChangelog([]ReleaseNote{checklistReleaseNote("1.0.0", date), checklistReleaseNote("0.9.0", date.AddDate(0, -1, 0))})
+	if want := "# Changelog\n\n" + checklistReleaseNotes + "\n---\n\n" + previous + "\n---"; err != nil || got != want {
+		t.Errorf("OutputFormatterImpl.FormatChangelog() = %q, %v, want %q", got, err, want)
+	}
+
+	if err := formatter.SetStyle(StyleDefault); err != nil {
+		t.Fatalf("OutputFormatterImpl.SetStyle() unexpected error: %v", err)
+	}
+	got, _ = formatter.FormatReleaseNote(fullReleaseNote("1.0.0", date))
+	if got != fullChangeLog {
+		t.Errorf("OutputFormatterImpl.FormatReleaseNote() = %v, want %v", got, fullChangeLog)
+	}
+
+	if err := formatter.SetStyle("todo"); err == nil {
+		t.Errorf("OutputFormatterImpl.SetStyle() expected error for invalid style")
+	}
+}
+
 func Test_checkTemplatesExecution(t *testing.T) {
 	tpls := NewOutputFormatter(templatesFS).templates
 	tests := []struct {
@@ -99,6 +434,7 @@ func Test_checkTemplatesExecution(t *testing.T) {
 	}{
 		{"changelog-md.tpl", changelogVariables("v1.0.0", "v1.0.1")},
 		{"releasenotes-md.tpl", releaseNotesVariables("v1.0.0")},
+		{"monorepo-changelog-md.tpl", []monorepoComponentTemplateVariables{{Name: "alpha", ReleaseNotes: changelogVariables("v1.0.0")}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.template, func(t *testing.T) {
@@ -117,13 +453,14 @@ func Test_checkTemplatesExecution(t *testing.T) {
 
 func releaseNotesVariables(release string) releaseNoteTemplateVariables {
 	return releaseNoteTemplateVariables{
-		Release: release,
-		Date:    time.Date(2006, 1, 02, 0, 0, 0, 0, time.UTC),
+		Release:    release,
+		Date:       time.Date(2006, 1, 02, 0, 0, 0, 0, time.UTC),
+		DateFormat: "2006-01-02",
 		Sections: []ReleaseNoteSection{
 			newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{commitlog("feat", map[string]string{}, "a")}),
 			newReleaseNoteCommitsSection("Bug Fixes", []string{"fix"}, []GitCommitLog{commitlog("fix", map[string]string{}, "a")}),
 			newReleaseNoteCommitsSection("Build", []string{"build"}, []GitCommitLog{commitlog("build", map[string]string{}, "a")}),
-			ReleaseNoteBreakingChangeSection{"Breaking Changes", []string{"break change message"}},
+			ReleaseNoteBreakingChangeSection{Name: "Breaking Changes", Messages: []string{"break change message"}},
 		},
 	}
 }
@@ -136,3 +473,132 @@ func changelogVariables(releases ...string) []releaseNoteTemplateVariables {
 	return variables
 
 }
+
+func TestOutputFormatterImpl_EscapeMarkdown(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2020-05-01")
+	tests := []struct {
+		name        string
+		scope       string
+		description string
+		breaking    string
+		raw         bool
+		want        string
+	}{
+		{"dunder", "", "support __init__ files", "", false, `- support \_\_init\_\_ files (abc1234)`},
+		{"emphasis and code", "api", "handle *args and `code`", "", false, "- **api:** handle \\*args and \\`code\\` (abc1234)"},
+		{"issue reference", "", "revert #123 [draft]", "", false, `- revert \#123 \[draft\] (abc1234)`},
+		{"html injection", "<b>core</b>", `render <script>alert("x")</script> <img src=x onerror=alert(1)>`, "", false,
+			`- **&lt;b&gt;core&lt;/b&gt;:** render &lt;script&gt;alert("x")&lt;/script&gt; &lt;img src=x onerror=alert(1)&gt; (abc1234)`},
+		{"table and strikethrough", "", "split a | b ~~c~~", "", false, `- split a \| b \~\~c\~\~ (abc1234)`},
+		{"backslash", "", `escape \* literal`, "", false, `- escape \\\* literal (abc1234)`},
+		{"breaking change", "", "drop v1", "removes <Client> __init__", false, `- removes &lt;Client&gt; \_\_init\_\_`},
+		{"raw markdown", "api", "use **bold** `code`", "see <b>docs</b>", true, "- **api:** use **bold** `code` (abc1234)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit := GitCommitLog{Hash: "abc1234", Message: CommitMessage{Type: "feat", Scope: tt.scope, Description: tt.description, Metadata: map[string]string{}}}
+			sections := []ReleaseNoteSection{newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{commit})}
+			if tt.breaking != "" {
+				sections = append(sections, ReleaseNoteBreakingChangeSection{Name: "Breaking Changes", Messages: []string{tt.breaking}})
+			}
+			formatter := NewOutputFormatter(templatesFS)
+			formatter.SetRawMarkdown(tt.raw)
+			got, err := formatter.FormatReleaseNote(releaseNote(semver.MustParse("1.0.0"), "1.0.0", date, sections, map[string]struct{}{"a": {}}))
+			if err != nil {
+				t.Fatalf("OutputFormatterImpl.FormatReleaseNote() unexpected error: %v", err)
+			}
+			if !strings.Contains(got, tt.want+"\n") {
+				t.Errorf("OutputFormatterImpl.FormatReleaseNote() = %q, want line %q", got, tt.want)
+			}
+			if tt.raw && !strings.Contains(got, "- see <b>docs</b>") {
+				t.Errorf("OutputFormatterImpl.FormatReleaseNote() = %q, want raw breaking change", got)
+			}
+		})
+	}
+}
+
+func TestOutputFormatterImpl_SetLocale(t *testing.T) {
+	// a friday, month and day names differ between locales.
+	date := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	releaseNote := withSummary(sharedCommitReleaseNote(date), 1, "a", "b")
+	releaseNote.ShowSummary = true
+	previous := withSummary(duplicateCommitReleaseNote(date.AddDate(0, -1, 0)), 2, "a")
+	previous.Version, previous.ShowSummary = semver.MustParse("0.9.0"), true
+
+	tests := []struct {
+		name    string
+		cfg     ReleaseNotesConfig
+		want    string
+		wantErr bool
+	}{
+		{"default", ReleaseNotesConfig{}, "# Changelog\n\n" +
+			"## v1.0.0 (2024-03-01)\n\n_1 change by 2 contributors_\n\n### Features\n\n- subject text () (shared with: api, web)\n\n---\n\n" +
+			"## v0.9.0 (2024-02-01)\n\n_2 changes by 1 contributor_\n\n### Features\n\n- subject text () (also in v0.9.1)\n\n---", false},
+		{"german", ReleaseNotesConfig{Locale: "de-DE", Strings: map[string]string{
+			StringChangelog: "Änderungsprotokoll", StringSummary: "{changes} von {contributors}", StringChange: "Änderung", StringChanges: "Änderungen",
+			StringContributor: "Mitwirkendem", StringContributors: "Mitwirkenden", StringSharedWith: "gemeinsam mit:", StringAlsoIn: "auch in",
+		}}, "# Änderungsprotokoll\n\n" +
+			"## v1.0.0 (1.3.2024)\n\n_1 Änderung von 2 Mitwirkenden_\n\n### Features\n\n- subject text () (gemeinsam mit: api, web)\n\n---\n\n" +
+			"## v0.9.0 (1.2.2024)\n\n_2 Änderungen von 1 Mitwirkendem_\n\n### Features\n\n- subject text () (auch in v0.9.1)\n\n---", false},
+		{"german long", ReleaseNotesConfig{Locale: "de", DateFormat: "long"}, "# Changelog\n\n" +
+			"## v1.0.0 (1. März 2024)\n\n_1 change by 2 contributors_\n\n### Features\n\n- subject text () (shared with: api, web)\n\n---\n\n" +
+			"## v0.9.0 (1. Februar 2024)\n\n_2 changes by 1 contributor_\n\n### Features\n\n- subject text () (also in v0.9.1)\n\n---", false},
+		{"french go layout", ReleaseNotesConfig{Locale: "fr", DateFormat: "Monday 2 Jan 2006", Strings: map[string]string{StringSummary: "{changes}, {contributors}"}}, "# Changelog\n\n" +
+			"## v1.0.0 (vendredi 1 mars 2024)\n\n_1 change, 2 contributors_\n\n### Features\n\n- subject text () (shared with: api, web)\n\n---\n\n" +
+			"## v0.9.0 (jeudi 1 févr. 2024)\n\n_2 changes, 1 contributor_\n\n### Features\n\n- subject text () (also in v0.9.1)\n\n---", false},
+		{"english long", ReleaseNotesConfig{DateFormat: "long"}, "# Changelog\n\n" +
+			"## v1.0.0 (March 1, 2024)\n\n_1 change by 2 contributors_\n\n### Features\n\n- subject text () (shared with: api, web)\n\n---\n\n" +
+			"## v0.9.0 (February 1, 2024)\n\n_2 changes by 1 contributor_\n\n### Features\n\n- subject text () (also in v0.9.1)\n\n---", false},
+		{"unknown locale", ReleaseNotesConfig{Locale: "xx"}, "", true},
+		{"unknown string", ReleaseNotesConfig{Strings: map[string]string{"title": "Changelog"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := NewOutputFormatter(templatesFS)
+			if err := formatter.SetLocale(tt.cfg.Locale, tt.cfg.Strings); (err != nil) != tt.wantErr {
+				t.Fatalf("OutputFormatterImpl.SetLocale() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			formatter.SetDateFormat(tt.cfg.DateLayout(), nil)
+			got, err := formatter.FormatChangelog([]ReleaseNote{releaseNote, previous})
+			if err != nil {
+				t.Fatalf("OutputFormatterImpl.FormatChangelog() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("OutputFormatterImpl.FormatChangelog() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseNotesConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ReleaseNotesConfig
+		wantErr bool
+	}{
+		{"default", ReleaseNotesConfig{}, false},
+		{"language", ReleaseNotesConfig{Locale: "de"}, false},
+		{"language and region", ReleaseNotesConfig{Locale: "pt_BR"}, false},
+		{"unsupported language", ReleaseNotesConfig{Locale: "ja"}, true},
+		{"language with script and region", ReleaseNotesConfig{Locale: "pt-Latn-BR"}, false},
+		{"language with variant", ReleaseNotesConfig{Locale: "de-AT-1996"}, false},
+		{"three letter language", ReleaseNotesConfig{Locale: "deu"}, false},
+		{"invalid tag", ReleaseNotesConfig{Locale: "german"}, true},
+		{"repeated region", ReleaseNotesConfig{Locale: "de-AT-AT"}, true},
+		{"unknown language", ReleaseNotesConfig{Locale: "xx-DE"}, true},
+		{"region without language", ReleaseNotesConfig{Locale: "und-DE"}, true},
+		{"trailing separator", ReleaseNotesConfig{Locale: "de-"}, true},
+		{"strings", ReleaseNotesConfig{Strings: map[string]string{StringSummary: "{changes}"}}, false},
+		{"unknown string", ReleaseNotesConfig{Strings: map[string]string{"heading": "Changes"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("ReleaseNotesConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,10 +3,14 @@ package sv
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,30 +19,124 @@ import (
 )
 
 const (
-	logSeparator = "###"
-	endLine      = "~~~"
+	logSeparator         = "###"
+	endLine              = "~~~"
+	logRecordStart       = "\x1e"
+	logAllFormat         = "--pretty=format:%x1e%aI" + logSeparator + "%at" + logSeparator + "%aN" + logSeparator + "%aE" + logSeparator + "%h" + logSeparator + "%p" + logSeparator + "%D" + logSeparator + "%s" + logSeparator + "%b" + endLine
+	maxErrorOutputLength = 500
+	defaultRemote        = "origin"
+	deepenCommits        = 100
+	maxDeepenAttempts    = 50
+	fetchTimeout         = 15 * time.Second
 )
 
 // Git commands.
 type Git interface {
 	LastTag() string
 	Log(lr LogRange) ([]GitCommitLog, error)
-	Commit(header, body, footer string) error
-	Tag(version semver.Version) (string, error)
+	LogAll(paths []string) ([]GitCommitLog, error)
+	Commit(header, body, footer string, paths ...string) error
+	Tag(version semver.Version, remote string) (string, error)
+	TagAt(version semver.Version, ref, remote string) (string, error)
+	TagName(version semver.Version) string
 	Tags() ([]GitTag, error)
+	TagsAll() ([]GitTag, error)
 	Branch() string
 	IsDetached() (bool, error)
-	LastComponentTag(componentPath string) string
-	TagForComponent(version semver.Version, componentPath string) (string, error)
+	IsClean() (bool, []string, error)
+	LastComponentTag(componentPath string, previousPaths ...string) string
+	ComponentTags(componentPath string, previousPaths ...string) ([]GitTag, error)
+	TagForComponent(version semver.Version, componentPath, remote string) (string, error)
+	TagForComponentAt(version semver.Version, componentPath, ref, remote string) (string, error)
+	ComponentTagName(version semver.Version, componentPath string) string
+	DeleteTag(tag, remote string) error
+	TagTarget(tag string) (string, bool, error)
+	ReplaceTag(tag, message, commit string, date time.Time) error
+	Add(paths ...string) error
+	HasStagedChanges() (bool, error)
+	StagedDiffStat() (string, error)
+	StagedFiles() ([]string, error)
+	IsShallow() (bool, error)
+	Unshallow() error
+	Fetch(tagsOnly bool) error
+	TagRemote() string
+	RemoteExists(remote string) (bool, error)
+	Push() error
+	PushTags(remote string, tags []string) error
+	ForcePushTags(remote string, tags []string) error
+	ShowFile(revision, path string) ([]byte, error)
+	ShortHash(revision string) (string, error)
+	IsAncestor(ancestor, revision string) (bool, error)
+	MergeBase(a, b string) (string, error)
+	DefaultBranch() (string, error)
+	PatchID(hash string) (string, error)
+	BehindUpstream() (string, int, error)
+	PushedCommits(oldRev, newRev, ref string) ([]GitRawCommit, error)
+	Version() (string, error)
+	HooksPath() (string, error)
 }
 
 // GitCommitLog description of a single commit log.
 type GitCommitLog struct {
-	Date       string        `json:"date,omitempty"`
-	Timestamp  int           `json:"timestamp,omitempty"`
-	AuthorName string        `json:"authorName,omitempty"`
-	Hash       string        `json:"hash,omitempty"`
-	Message    CommitMessage `json:"message,omitempty"`
+	Date        string        `json:"date,omitempty"` // author date, yyyy-mm-dd on author timezone
+	Timestamp   int           `json:"timestamp,omitempty"`
+	AuthorDate  time.Time     `json:"-"` // author date with time and timezone, loaded by Git.Log and Git.LogAll
+	AuthorName  string        `json:"authorName,omitempty"`
+	AuthorEmail string        `json:"authorEmail,omitempty"`
+	Hash        string        `json:"hash,omitempty"`
+	Message     CommitMessage `json:"message,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Parents     []string      `json:"-"`
+	Files       []string      `json:"-"` // changed files relative to repository root, loaded by Git.LogAll or LogRange.WithFiles
+
+}
+
+// TagExistsError returned when the tag to be created already exists.
+type TagExistsError struct {
+	Tag    string
+	Commit string // Abbreviated hash of the commit pointed by the existing tag
+}
+
+func (e TagExistsError) Error() string {
+	return fmt.Sprintf("tag %s already exists on commit %s", e.Tag, e.Commit)
+}
+
+// TagPushError returned when the tag was created locally but could not be pushed to remote.
+type TagPushError struct {
+	Tag    string
+	Remote string
+	Err    error
+}
+
+func (e TagPushError) Error() string {
+	return fmt.Sprintf("tag %s created locally but push to %s failed, message: %v", e.Tag, e.Remote, e.Err)
+}
+
+func (e TagPushError) Unwrap() error {
+	return e.Err
+}
+
+// TagsPushError returned when tags were created locally but some of them could not be pushed, see GitImpl.PushTags.
+type TagsPushError struct {
+	Remote string
+	Failed []TagPushError
+}
+
+func (e TagsPushError) Error() string {
+	messages := make([]string, len(e.Failed))
+	for i, failed := range e.Failed {
+		messages[i] = fmt.Sprintf("%s (%v)", failed.Tag, failed.Err)
+	}
+	return fmt.Sprintf("tags created locally but push to %s failed: %s", e.Remote, strings.Join(messages, ", "))
+}
+
+// Tags returns names of tags that could not be pushed.
+func (e TagsPushError) Tags() []string {
+	tags := make([]string, len(e.Failed))
+	for i, failed := range e.Failed {
+		tags[i] = failed.Tag
+	}
+	return tags
 }
 
 // GitTag git tag info.
@@ -47,6 +145,12 @@ type GitTag struct {
 	Date time.Time
 }
 
+// GitRawCommit commit with its message as written, not parsed.
+type GitRawCommit struct {
+	Hash    string
+	Message string
+}
+
 // LogRangeType type of log range.
 type LogRangeType string
 
@@ -63,6 +167,7 @@ type LogRange struct {
 	start     string
 	end       string
 	paths     []string // optional: filter commits by these file/directory paths
+	files     bool     // optional: load changed files of each commit
 }
 
 // NewLogRange LogRange constructor.
@@ -75,34 +180,185 @@ func NewLogRangeWithPaths(t LogRangeType, start, end string, paths []string) Log
 	return LogRange{rangeType: t, start: start, end: end, paths: paths}
 }
 
+// WithFiles returns a copy of the range that also loads the files changed by each commit, including files outside range paths.
+// Listing files requires git to compute each commit diff, use only when needed.
+func (lr LogRange) WithFiles() LogRange {
+	lr.files = true
+	return lr
+}
+
+// WithPaths returns a copy of the range filtering commits by paths, e.g. the directories of a monorepo component.
+func (lr LogRange) WithPaths(paths []string) LogRange {
+	lr.paths = paths
+	return lr
+}
+
+// WithEnd returns a copy of the range ending on end, e.g. a commit hash, HEAD if empty.
+func (lr LogRange) WithEnd(end string) LogRange {
+	lr.end = end
+	return lr
+}
+
 // GitImpl git command implementation.
 type GitImpl struct {
 	messageProcessor MessageProcessor
 	tagCfg           TagConfig
+	logCfg           LogConfig
+	commandLog       io.Writer
+	commandOutput    io.Writer
+	commandTimer     func(command string, duration time.Duration)
+	dir              string
+	clock            Clock
+	monotonic        bool
 }
 
 // NewGit constructor.
-func NewGit(messageProcessor MessageProcessor, cfg TagConfig) *GitImpl {
+func NewGit(messageProcessor MessageProcessor, tagCfg TagConfig, logCfg LogConfig) *GitImpl {
 	return &GitImpl{
 		messageProcessor: messageProcessor,
-		tagCfg:           cfg,
+		tagCfg:           tagCfg,
+		logCfg:           logCfg,
 	}
 }
 
-// LastTag get last tag, if no tag found, return empty.
+// LogCommands writes every git command line to w before its execution, use nil to disable it.
+func (g *GitImpl) LogCommands(w io.Writer) {
+	g.commandLog = w
+}
+
+// SetCommandOutput defines where the output of git commit and its hooks is written, e.g. stderr so stdout keeps only the
+// command result, it is discarded if nil.
+func (g *GitImpl) SetCommandOutput(w io.Writer) {
+	g.commandOutput = w
+}
+
+// OnCommandExecuted calls fn with the command line and the duration of every git command after its execution, use nil to disable it.
+func (g *GitImpl) OnCommandExecuted(fn func(command string, duration time.Duration)) {
+	g.commandTimer = fn
+}
+
+// SetFirstParent overrides log.first-parent config, when enabled only the first parent of merge commits is followed on logs.
+func (g *GitImpl) SetFirstParent(enabled bool) {
+	g.logCfg.FirstParent = enabled
+}
+
+// SetMonotonic sets how LastTag selects the last tag: when enabled, the tag with the highest version among tags reachable from
+// HEAD, otherwise the most recently created tag. Logs since the last tag exclude commits reachable from it, so commits of a
+// merged maintenance branch already released, e.g. v1.9.5 merged after v2.3.0, neither lower the version nor are listed twice.
+func (g *GitImpl) SetMonotonic(enabled bool) {
+	g.monotonic = enabled
+}
+
+// SetClock defines the clock used as date of created tags, git uses the system time if nil.
+func (g *GitImpl) SetClock(clock Clock) {
+	g.clock = clock
+}
+
+// SetDir defines the directory where git commands are executed, if empty the current working directory is used.
+func (g *GitImpl) SetDir(dir string) {
+	g.dir = dir
+}
+
+func (g GitImpl) command(args ...string) *exec.Cmd {
+	return g.commandContext(context.Background(), args...)
+}
+
+// commandContext creates a git command killed when ctx is done.
+func (g GitImpl) commandContext(ctx context.Context, args ...string) *exec.Cmd {
+	if g.commandLog != nil {
+		fmt.Fprintf(g.commandLog, "git %s\n", strings.Join(args, " "))
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.dir
+	return cmd
+}
+
+// execute runs cmd reporting its duration to the OnCommandExecuted callback.
+func (g GitImpl) execute(cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Run()
+	if g.commandTimer != nil {
+		g.commandTimer(strings.Join(cmd.Args[1:], " "), time.Since(start))
+	}
+	return err
+}
+
+// run executes a git command returning its stdout, errors include the command and its stderr.
+func (g GitImpl) run(args ...string) (string, error) {
+	return g.runWithInput(nil, args...)
+}
+
+// runWithInput executes a git command reading stdin from input.
+func (g GitImpl) runWithInput(input io.Reader, args ...string) (string, error) {
+	cmd := g.command(args...)
+	cmd.Stdin = input
+	return g.runCommand(cmd, args)
+}
+
+// runWithEnv executes a git command adding env, e.g. GIT_COMMITTER_DATE=..., to the current environment.
+func (g GitImpl) runWithEnv(env []string, args ...string) (string, error) {
+	cmd := g.command(args...)
+	cmd.Env = append(os.Environ(), env...)
+	return g.runCommand(cmd, args)
+}
+
+func (g GitImpl) runCommand(cmd *exec.Cmd, args []string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := g.execute(cmd); err != nil {
+		return stdout.String(), commandErr(args, err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// LastTag get last tag, the most recently created one or on monotonic mode the highest version reachable from HEAD, see
+// SetMonotonic. If no tag found, return empty.
 func (g GitImpl) LastTag() string {
-	cmd := exec.Command("git", "for-each-ref", "refs/tags/"+*g.tagCfg.Filter, "--sort", "-creatordate", "--format", "%(refname:short)", "--count", "1")
-	out, err := cmd.CombinedOutput()
+	if g.monotonic {
+		return g.highestMergedTag()
+	}
+	out, err := g.run("for-each-ref", "refs/tags/"+*g.tagCfg.Filter, "--sort", "-creatordate", "--format", "%(refname:short)", "--count", "1")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// highestMergedTag returns the tag with the highest version reachable from HEAD, tags that are not versions are ignored and on
+// equal versions, e.g. with different build metadata, the most recent tag is used.
+func (g GitImpl) highestMergedTag() string {
+	out, err := g.run("for-each-ref", "refs/tags/"+*g.tagCfg.Filter, "--merged", "HEAD", "--sort", "-creatordate", "--format", "%(refname:short)")
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(strings.Trim(string(out), "\n"))
+	var last string
+	var lastVersion *semver.Version
+	for _, tag := range strings.Fields(out) {
+		version, err := ToVersion(tag)
+		if err != nil {
+			continue
+		}
+		if lastVersion == nil || version.GreaterThan(lastVersion) {
+			last, lastVersion = tag, version
+		}
+	}
+	return last
 }
 
-// Log return git log.
+// Log return git log, merge commits are included according with log.include-merges config.
 func (g GitImpl) Log(lr LogRange) ([]GitCommitLog, error) {
-	format := "--pretty=format:\"%ad" + logSeparator + "%at" + logSeparator + "%cN" + logSeparator + "%h" + logSeparator + "%s" + logSeparator + "%b" + endLine + "\""
-	params := []string{"log", "--date=short", format}
+	format := "--pretty=format:\"%aI" + logSeparator + "%at" + logSeparator + "%aN" + logSeparator + "%aE" + logSeparator + "%h" + logSeparator + "%p" + logSeparator + "%s" + logSeparator + "%b" + endLine + "\""
+	if lr.files {
+		format = logAllFormat
+	}
+	params := append([]string{"log", format}, g.logModeParams()...)
+	if lr.files {
+		params = append(params, "--name-only", "--full-diff")
+	}
+	if g.logCfg.IncludeMerges != IncludeMergesAlways && g.logCfg.IncludeMerges != IncludeMergesOnlyConventional {
+		params = append(params, "--no-merges")
+	}
 
 	if lr.start != "" || lr.end != "" {
 		switch lr.rangeType {
@@ -122,120 +378,714 @@ func (g GitImpl) Log(lr LogRange) ([]GitCommitLog, error) {
 		params = append(params, lr.paths...)
 	}
 
-	cmd := exec.Command("git", params...)
-	out, err := cmd.CombinedOutput()
+	out, err := g.run(params...)
 	if err != nil {
-		return nil, combinedOutputErr(err, out)
+		return nil, err
+	}
+	parse := parseLogOutput
+	if lr.files {
+		parse = parseLogAllOutput
 	}
-	logs, parseErr := parseLogOutput(g.messageProcessor, string(out))
+	logs, parseErr := parse(g.messageProcessor, out)
 	if parseErr != nil {
 		return nil, parseErr
 	}
-	return logs, nil
+	return filterCommits(g.logCfg, logs), nil
 }
 
-// Commit runs git commit.
-func (g GitImpl) Commit(header, body, footer string) error {
-	cmd := exec.Command("git", "commit", "-m", header, "-m", "", "-m", body, "-m", "", "-m", footer)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+func (g GitImpl) logModeParams() []string {
+	if g.logCfg.FirstParent {
+		return []string{"--first-parent"}
+	}
+	return nil
+}
+
+// LogAll return every commit reachable from HEAD with tags, parents and changed files, filtered by paths if not empty.
+// With log.first-parent, only commits reachable following first parents are returned and merges list files changed from their first parent.
+func (g GitImpl) LogAll(paths []string) ([]GitCommitLog, error) {
+	params := append([]string{"log", "--name-only", logAllFormat}, g.logModeParams()...)
+	if len(paths) > 0 {
+		params = append(params, "--")
+		params = append(params, paths...)
+	}
+
+	out, err := g.run(params...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLogAllOutput(g.messageProcessor, out)
 }
 
-// Tag create a git tag.
-func (g GitImpl) Tag(version semver.Version) (string, error) {
-	tag := fmt.Sprintf(*g.tagCfg.Pattern, version.Major(), version.Minor(), version.Patch())
+// Commit runs git commit, when paths are defined only them are committed, other staged changes are kept staged.
+func (g GitImpl) Commit(header, body, footer string, paths ...string) error {
+	args := []string{"commit", "-m", header, "-m", "", "-m", body, "-m", "", "-m", footer}
+	if len(paths) > 0 {
+		args = append(append(args, "--only", "--"), paths...)
+	}
+	var stderr bytes.Buffer
+	cmd := g.command(args...)
+	cmd.Stdout, cmd.Stderr = g.commandOutput, &stderr
+	if g.commandOutput != nil {
+		cmd.Stderr = io.MultiWriter(g.commandOutput, &stderr)
+	}
+	if err := g.execute(cmd); err != nil {
+		return commandErr(args, err, stderr.String())
+	}
+	return nil
+}
+
+// Add stages paths, if no path is defined all tracked files changes are staged.
+func (g GitImpl) Add(paths ...string) error {
+	params := []string{"add"}
+	if len(paths) == 0 {
+		params = append(params, "--update")
+	} else {
+		params = append(params, "--")
+		params = append(params, paths...)
+	}
+
+	_, err := g.run(params...)
+	return err
+}
+
+// HasStagedChanges check if there are changes staged to be committed.
+func (g GitImpl) HasStagedChanges() (bool, error) {
+	_, err := g.run("diff", "--cached", "--quiet")
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, err
+}
+
+// StagedDiffStat return diffstat of changes staged to be committed.
+func (g GitImpl) StagedDiffStat() (string, error) {
+	out, err := g.run("diff", "--cached", "--stat")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+// StagedFiles return paths of changes staged to be committed, relative to repository root.
+func (g GitImpl) StagedFiles() ([]string, error) {
+	out, err := g.run("diff", "--cached", "--name-only", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range strings.Split(out, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// Version returns the version of the git executable, e.g. 2.39.2.
+func (g GitImpl) Version() (string, error) {
+	out, err := g.run("--version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(out), "git version "), nil
+}
+
+// HooksPath returns the absolute path of the hooks directory, core.hooksPath config is respected.
+func (g GitImpl) HooksPath() (string, error) {
+	out, err := g.run("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(out)
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	dir := g.dir
+	if dir == "" {
+		if dir, err = os.Getwd(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, path), nil
+}
+
+// IsShallow check if repository is a shallow clone.
+func (g GitImpl) IsShallow() (bool, error) {
+	out, err := g.run("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "true", nil
+}
+
+// Unshallow fetches tags and the complete history from the remote of the current branch,
+// if the remote refuses to unshallow, history is deepened in increments until it is complete.
+func (g GitImpl) Unshallow() error {
+	remote := g.branchRemote()
+	if _, err := g.run("fetch", "--tags", "--unshallow", remote); err == nil {
+		return nil
+	}
+
+	for i := 0; i < maxDeepenAttempts; i++ {
+		if _, err := g.run("fetch", "--tags", fmt.Sprintf("--deepen=%d", deepenCommits), remote); err != nil {
+			return err
+		}
+		shallow, err := g.IsShallow()
+		if err != nil {
+			return err
+		}
+		if !shallow {
+			return nil
+		}
+	}
+	return fmt.Errorf("repository history is still incomplete after fetching %d commits from %s", deepenCommits*maxDeepenAttempts, remote)
+}
+
+// Fetch fetches tags from the tag remote, or the remote of the current branch when tags are not pushed,
+// with tagsOnly branches are not updated. Fetch is aborted after fetchTimeout and never prompts for credentials.
+func (g GitImpl) Fetch(tagsOnly bool) error {
+	remote := g.TagRemote()
+	if remote == "" {
+		remote = g.branchRemote()
+	}
+	args := []string{"fetch", "--quiet", "--tags", remote}
+	if tagsOnly {
+		args = []string{"fetch", "--quiet", "--no-tags", remote, "refs/tags/*:refs/tags/*"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := g.commandContext(ctx, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stderr = &stderr
+	if err := g.execute(cmd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("git fetch from %s timed out after %s", remote, fetchTimeout)
+		}
+		return commandErr(args, err, stderr.String())
+	}
+	return nil
+}
+
+// branchRemote returns the remote configured for the current branch, if not found returns origin.
+func (g GitImpl) branchRemote() string {
+	branch := g.Branch()
+	if branch == "" {
+		return defaultRemote
+	}
+	out, err := g.run("config", "--get", "branch."+branch+".remote")
+	if remote := strings.TrimSpace(out); err == nil && remote != "" {
+		return remote
+	}
+	return defaultRemote
+}
+
+// Tag create a git tag and push it to remote, if remote is empty the tag is not pushed.
+func (g GitImpl) Tag(version semver.Version, remote string) (string, error) {
+	return g.TagAt(version, "", remote)
+}
+
+// TagAt create a git tag on ref, HEAD if ref is empty, and push it to remote, if remote is empty the tag is not pushed.
+func (g GitImpl) TagAt(version semver.Version, ref, remote string) (string, error) {
+	tag := g.TagName(version)
 	tagMsg := fmt.Sprintf("Version %d.%d.%d", version.Major(), version.Minor(), version.Patch())
+	return tag, g.createTag(tag, tagMsg, ref, remote)
+}
+
+// TagName returns the tag name of version using tag.pattern config.
+func (g GitImpl) TagName(version semver.Version) string {
+	return withMetadata(fmt.Sprintf(*g.tagCfg.Pattern, version.Major(), version.Minor(), version.Patch()), version)
+}
+
+// withMetadata appends version build metadata to tag, build metadata does not affect version precedence.
+func withMetadata(tag string, version semver.Version) string {
+	if version.Metadata() == "" {
+		return tag
+	}
+	return tag + "+" + version.Metadata()
+}
+
+// createTag creates an annotated tag on ref, HEAD if ref is empty, and pushes it to remote if not empty.
+func (g GitImpl) createTag(tag, tagMsg, ref, remote string) error {
+	if commit, exists := g.tagCommit(tag); exists {
+		return TagExistsError{Tag: tag, Commit: commit}
+	}
+	args := []string{"tag", "-a", tag, "-m", tagMsg}
+	if ref != "" {
+		args = append(args, ref+"^{commit}")
+	}
+	var env []string
+	if g.clock != nil {
+		// tagger date of annotated tags is read from committer date
+		env = append(env, "GIT_COMMITTER_DATE="+gitDate(g.clock.Now()))
+	}
+	if _, err := g.runWithEnv(env, args...); err != nil {
+		return err
+	}
+
+	if remote == "" {
+		return nil
+	}
+	if _, err := g.run("push", remote, tag); err != nil {
+		return TagPushError{Tag: tag, Remote: remote, Err: err}
+	}
+	return nil
+}
+
+// gitDate formats t on git internal date format, seconds since epoch and timezone offset, e.g. 1717200000 +0200.
+func gitDate(t time.Time) string {
+	return fmt.Sprintf("%d %s", t.Unix(), t.Format("-0700"))
+}
+
+// tagCommit returns the abbreviated hash of the commit pointed by tag, false if tag does not exist.
+func (g GitImpl) tagCommit(tag string) (string, bool) {
+	out, err := g.run("rev-parse", "-q", "--verify", "--short", "refs/tags/"+tag+"^{commit}")
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(out), true
+}
 
-	tagCommand := exec.Command("git", "tag", "-a", tag, "-m", tagMsg)
-	if out, err := tagCommand.CombinedOutput(); err != nil {
-		return tag, combinedOutputErr(err, out)
+// DeleteTag deletes tag locally and, if remote is not empty, from remote when it was already pushed.
+func (g GitImpl) DeleteTag(tag, remote string) error {
+	if _, err := g.run("tag", "-d", tag); err != nil {
+		return err
 	}
+	if remote == "" {
+		return nil
+	}
+	out, err := g.run("ls-remote", "--tags", remote, "refs/tags/"+tag)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(out) == "" {
+		return nil
+	}
+	_, err = g.run("push", "--delete", remote, "refs/tags/"+tag)
+	return err
+}
+
+// TagTarget returns the full hash of the commit pointed by tag and whether tag is annotated, false for lightweight tags.
+func (g GitImpl) TagTarget(tag string) (string, bool, error) {
+	ref := "refs/tags/" + tag
+	objectType, err := g.run("cat-file", "-t", ref)
+	if err != nil {
+		return "", false, err
+	}
+	commit, err := g.run("rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(commit), strings.TrimSpace(objectType) == "tag", nil
+}
 
-	pushCommand := exec.Command("git", "push", "origin", tag)
-	if out, err := pushCommand.CombinedOutput(); err != nil {
-		return tag, combinedOutputErr(err, out)
+// ReplaceTag creates tag as an annotated tag with message on commit, replacing the existing local tag. The tagger date is
+// set to date, if not zero, so the tag keeps its order on Tags. Message lines starting with # are kept, e.g. markdown headings.
+func (g GitImpl) ReplaceTag(tag, message, commit string, date time.Time) error {
+	var env []string
+	if !date.IsZero() {
+		env = append(env, "GIT_COMMITTER_DATE="+gitDate(date))
 	}
-	return tag, nil
+	_, err := g.runWithEnv(env, "tag", "-a", "-f", "--cleanup=whitespace", "-m", message, tag, commit+"^{commit}")
+	return err
 }
 
-// Tags list repository tags.
+// TagRemote returns the remote used to push tags: tag.remote config when defined,
+// otherwise the remote of the current branch. Empty means tags should not be pushed.
+func (g GitImpl) TagRemote() string {
+	if g.tagCfg.Remote != nil {
+		return *g.tagCfg.Remote
+	}
+	return g.branchRemote()
+}
+
+// ShowFile returns the content of path, relative to repository root, at revision.
+func (g GitImpl) ShowFile(revision, path string) ([]byte, error) {
+	out, err := g.run("show", revision+":"+filepath.ToSlash(path))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// ShortHash returns the abbreviated hash of the commit pointed by revision.
+func (g GitImpl) ShortHash(revision string) (string, error) {
+	out, err := g.run("rev-parse", "--short", revision+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// IsAncestor checks if ancestor commit is reachable from revision, a commit is an ancestor of itself.
+func (g GitImpl) IsAncestor(ancestor, revision string) (bool, error) {
+	_, err := g.run("merge-base", "--is-ancestor", ancestor+"^{commit}", revision+"^{commit}")
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, err
+}
+
+// MergeBase returns the abbreviated hash of the best common ancestor of a and b.
+func (g GitImpl) MergeBase(a, b string) (string, error) {
+	out, err := g.run("merge-base", a+"^{commit}", b+"^{commit}")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", fmt.Errorf("%s and %s have no common history", a, b)
+		}
+		return "", err
+	}
+	return g.ShortHash(strings.TrimSpace(out))
+}
+
+// PushedCommits returns the commits added by a ref update received by pre-receive hook, oldest first: commits of newRev not
+// reachable from oldRev or from other refs. Merge commits are not listed and oldRev is ignored when it is the zero hash, i.e. a
+// new ref. Works on bare repositories, on pre-receive the pushed objects are already readable and refs not updated yet.
+func (g GitImpl) PushedCommits(oldRev, newRev, ref string) ([]GitRawCommit, error) {
+	params := []string{"log", "--reverse", "--no-merges", "--format=" + logRecordStart + "%h%x00%B", newRev}
+	if !IsZeroHash(oldRev) {
+		params = append(params, "^"+oldRev)
+	}
+	params = append(params, "--not", "--exclude="+ref, "--all")
+	out, err := g.run(params...)
+	if err != nil {
+		return nil, err
+	}
+	return parseRawCommitsOutput(out), nil
+}
+
+// IsZeroHash checks if rev is the zero hash used by git hooks for missing refs, e.g. the old value of a created ref.
+func IsZeroHash(rev string) bool {
+	return rev != "" && strings.Trim(rev, "0") == ""
+}
+
+func parseRawCommitsOutput(out string) []GitRawCommit {
+	var commits []GitRawCommit
+	for _, record := range strings.Split(out, logRecordStart) {
+		hash, message, found := strings.Cut(record, "\x00")
+		if !found {
+			continue
+		}
+		commits = append(commits, GitRawCommit{Hash: hash, Message: strings.TrimSpace(message)})
+	}
+	return commits
+}
+
+// PatchID returns the stable patch id of commit changes, commits with same changes have the same patch id, empty if commit has no changes.
+func (g GitImpl) PatchID(hash string) (string, error) {
+	diff, err := g.run("show", "--format=", "--no-color", "--no-ext-diff", hash)
+	if err != nil {
+		return "", err
+	}
+	out, err := g.runWithInput(strings.NewReader(diff), "patch-id", "--stable")
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], nil
+}
+
+// Push pushes current branch to its remote.
+func (g GitImpl) Push() error {
+	_, err := g.run("push", g.branchRemote(), "HEAD")
+	return err
+}
+
+// PushTags pushes tags to remote with a single git push. Tags rejected by remote are returned as TagsPushError with the
+// reason of each one, other tags are still pushed. When the push fails without rejections, e.g. connection errors, every tag failed.
+func (g GitImpl) PushTags(remote string, tags []string) error {
+	return g.pushTags(remote, tags, false)
+}
+
+// ForcePushTags pushes tags to remote as PushTags, replacing tags with the same name already on remote.
+func (g GitImpl) ForcePushTags(remote string, tags []string) error {
+	return g.pushTags(remote, tags, true)
+}
+
+func (g GitImpl) pushTags(remote string, tags []string, force bool) error {
+	args := []string{"push", "--porcelain"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, remote)
+	for _, tag := range tags {
+		args = append(args, "refs/tags/"+tag)
+	}
+	out, err := g.run(args...)
+	if err == nil {
+		return nil
+	}
+
+	rejected := parsePushRejections(out)
+	result := TagsPushError{Remote: remote}
+	for _, tag := range tags {
+		if reason, exists := rejected["refs/tags/"+tag]; exists {
+			result.Failed = append(result.Failed, TagPushError{Tag: tag, Remote: remote, Err: errors.New(reason)})
+		}
+	}
+	if len(result.Failed) == 0 {
+		for _, tag := range tags {
+			result.Failed = append(result.Failed, TagPushError{Tag: tag, Remote: remote, Err: err})
+		}
+	}
+	return result
+}
+
+// parsePushRejections returns the reason of each ref rejected on git push --porcelain output by local ref,
+// e.g. "!\trefs/tags/a/v1.0.0:refs/tags/a/v1.0.0\t[rejected] (already exists)".
+func parsePushRejections(output string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 || fields[0] != "!" {
+			continue
+		}
+		ref := strings.SplitN(fields[1], ":", 2)[0]
+		result[ref] = strings.TrimSpace(fields[2])
+	}
+	return result
+}
+
+// RemoteExists check if remote is configured on repository.
+func (g GitImpl) RemoteExists(remote string) (bool, error) {
+	out, err := g.run("remote")
+	if err != nil {
+		return false, err
+	}
+	for _, r := range strings.Fields(out) {
+		if r == remote {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// tagsFormat for-each-ref format of tags: creation date, the tagger date of annotated tags or the committer date of the
+// tagged commit for lightweight tags, then the committer date of the tagged commit, used when annotated tags created by
+// other tools have no tagger, and the tag name.
+const tagsFormat = "%(creatordate:iso8601)#%(*committerdate:iso8601)#%(refname:short)"
+
+// Tags list repository tags sorted by creation date, lightweight tags by the date of the tagged commit.
 func (g GitImpl) Tags() ([]GitTag, error) {
-	cmd := exec.Command("git", "for-each-ref", "--sort", "creatordate", "--format", "%(creatordate:iso8601)#%(refname:short)", "refs/tags/"+*g.tagCfg.Filter)
-	out, err := cmd.CombinedOutput()
+	out, err := g.run("for-each-ref", "--sort", "creatordate", "--format", tagsFormat, "refs/tags/"+*g.tagCfg.Filter)
 	if err != nil {
-		return nil, combinedOutputErr(err, out)
+		return nil, err
 	}
-	return parseTagsOutput(string(out))
+	return parseTagsOutput(out)
 }
 
 // Branch get git branch.
-func (GitImpl) Branch() string {
-	cmd := exec.Command("git", "symbolic-ref", "--short", "HEAD")
-	out, err := cmd.CombinedOutput()
+func (g GitImpl) Branch() string {
+	out, err := g.run("symbolic-ref", "--short", "HEAD")
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(strings.Trim(string(out), "\n"))
+	return strings.TrimSpace(out)
+}
+
+// IsClean check if tracked files have no uncommitted changes, staged or not, untracked files are ignored.
+// Returns the changed paths relative to repository root.
+func (g GitImpl) IsClean() (bool, []string, error) {
+	out, err := g.run("status", "--porcelain", "-z", "--untracked-files=no")
+	if err != nil {
+		return false, nil, err
+	}
+	paths := parseStatusOutput(out)
+	return len(paths) == 0, paths, nil
 }
 
 // IsDetached check if is detached.
-func (GitImpl) IsDetached() (bool, error) {
-	cmd := exec.Command("git", "symbolic-ref", "-q", "HEAD")
-	out, err := cmd.CombinedOutput()
-	if output := string(out); err != nil { //-q: do not issue an error message if the <name> is not a symbolic ref, but a detached HEAD; instead exit with non-zero status silently.
-		if output == "" {
+func (g GitImpl) IsDetached() (bool, error) {
+	var stderr bytes.Buffer
+	cmd := g.command("symbolic-ref", "-q", "HEAD")
+	cmd.Stderr = &stderr
+	if err := g.execute(cmd); err != nil { //-q: do not issue an error message if the <name> is not a symbolic ref, but a detached HEAD; instead exit with non-zero status silently.
+		if stderr.Len() == 0 {
 			return true, nil
 		}
-		return false, errors.New(output)
+		return false, commandErr([]string{"symbolic-ref", "-q", "HEAD"}, err, stderr.String())
 	}
 	return false, nil
 }
 
+// BehindUpstream returns the upstream tracking branch of current branch and how many commits HEAD is behind it,
+// remote-tracking refs are not fetched. Returns empty upstream if current branch has none.
+func (g GitImpl) BehindUpstream() (string, int, error) {
+	out, err := g.run("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return "", 0, nil // no upstream configured
+	}
+	upstream := strings.TrimSpace(out)
+
+	out, err = g.run("rev-list", "--count", "HEAD.."+upstream)
+	if err != nil {
+		return "", 0, err
+	}
+	behind, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid rev-list count: %s", strings.TrimSpace(out))
+	}
+	return upstream, behind, nil
+}
+
+// DefaultBranch returns the default branch of origin remote from origin/HEAD, e.g. origin/main,
+// set by git clone or git remote set-head origin --auto.
+func (g GitImpl) DefaultBranch() (string, error) {
+	out, err := g.run("symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("default branch not found, origin/HEAD is not set, run: git remote set-head origin --auto")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// TagsAll list every tag, ignoring tag.filter config, sorted by creation date as Tags.
+func (g GitImpl) TagsAll() ([]GitTag, error) {
+	out, err := g.run("for-each-ref", "--sort", "creatordate", "--format", tagsFormat, "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	return parseTagsOutput(out)
+}
+
+// ComponentTagVersion returns the version part of a component tag, e.g. v1.2.3 for "templates/my-component/v1.2.3", the
+// same for tags of the current and previous component paths.
+func ComponentTagVersion(tagName string) string {
+	return tagName[strings.LastIndex(tagName, "/")+1:]
+}
+
 // LastComponentTag returns the most recent Go-style monorepo tag for the given
-// component path (e.g. "templates/my-component/v1.2.3").
+// component path (e.g. "templates/my-component/v1.2.3"), tags of previousPaths are
+// considered too, see FilterComponentTags.
 // Returns an empty string when no tag exists for the component.
-func (GitImpl) LastComponentTag(componentPath string) string {
-	filter := componentPath + "/v*"
-	cmd := exec.Command("git", "for-each-ref", "refs/tags/"+filter, "--sort", "-creatordate", "--format", "%(refname:short)", "--count", "1")
-	out, err := cmd.CombinedOutput()
+func (g GitImpl) LastComponentTag(componentPath string, previousPaths ...string) string {
+	tags, err := g.TagsAll()
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(out))
+	return LatestTag(FilterComponentTags(tags, componentPath, previousPaths...))
+}
+
+// ComponentTags list Go-style monorepo tags for the given component path and its previous paths sorted by creation date.
+func (g GitImpl) ComponentTags(componentPath string, previousPaths ...string) ([]GitTag, error) {
+	tags, err := g.TagsAll()
+	if err != nil {
+		return nil, err
+	}
+	return FilterComponentTags(tags, componentPath, previousPaths...), nil
+}
+
+// FilterComponentTags returns the Go-style monorepo tags of a component (e.g. "templates/my-component/v1.2.3") keeping tags order.
+// Tags created before the component was renamed are prefixed by one of previousPaths, e.g. "services/payments/v1.2.0".
+func FilterComponentTags(tags []GitTag, componentPath string, previousPaths ...string) []GitTag {
+	var result []GitTag
+	for _, tag := range tags {
+		for _, p := range append([]string{componentPath}, previousPaths...) {
+			prefix := p + "/v"
+			if strings.HasPrefix(tag.Name, prefix) && !strings.Contains(tag.Name[len(prefix):], "/") {
+				result = append(result, tag)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// LatestTag returns the name of the most recent tag by creation date from tags sorted by creation date,
+// the first one is used when many tags share the same date. Returns an empty string when tags is empty.
+func LatestTag(tags []GitTag) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	latest := tags[0]
+	for _, tag := range tags[1:] {
+		if tag.Date.After(latest.Date) {
+			latest = tag
+		}
+	}
+	return latest.Name
 }
 
 // TagForComponent creates and pushes an annotated git tag for a monorepo component
 // following the Go standard format: <componentPath>/vX.Y.Z.
-func (GitImpl) TagForComponent(version semver.Version, componentPath string) (string, error) {
-	tag := fmt.Sprintf("%s/v%d.%d.%d", componentPath, version.Major(), version.Minor(), version.Patch())
-	tagMsg := fmt.Sprintf("%s version %d.%d.%d", componentPath, version.Major(), version.Minor(), version.Patch())
+// If remote is empty the tag is not pushed.
+func (g GitImpl) TagForComponent(version semver.Version, componentPath, remote string) (string, error) {
+	return g.TagForComponentAt(version, componentPath, "", remote)
+}
 
-	tagCommand := exec.Command("git", "tag", "-a", tag, "-m", tagMsg)
-	if out, err := tagCommand.CombinedOutput(); err != nil {
-		return tag, combinedOutputErr(err, out)
-	}
+// TagForComponentAt creates and pushes an annotated git tag for a monorepo component on ref, e.g. another tag,
+// HEAD is tagged if ref is empty. If remote is empty the tag is not pushed.
+func (g GitImpl) TagForComponentAt(version semver.Version, componentPath, ref, remote string) (string, error) {
+	tag := g.ComponentTagName(version, componentPath)
+	tagMsg := fmt.Sprintf("%s version %s", componentPath, withPrerelease(fmt.Sprintf("%d.%d.%d", version.Major(), version.Minor(), version.Patch()), version))
+	return tag, g.createTag(tag, tagMsg, ref, remote)
+}
 
-	pushCommand := exec.Command("git", "push", "origin", tag)
-	if out, err := pushCommand.CombinedOutput(); err != nil {
-		return tag, combinedOutputErr(err, out)
+// ComponentTagName returns the Go-style monorepo tag name of component version, e.g. "templates/my-component/v1.2.3"
+// or "templates/my-component/v1.3.0-beta.1" for prereleases.
+func (g GitImpl) ComponentTagName(version semver.Version, componentPath string) string {
+	tag := fmt.Sprintf("%s/v%d.%d.%d", componentPath, version.Major(), version.Minor(), version.Patch())
+	return withMetadata(withPrerelease(tag, version), version)
+}
+
+// withPrerelease appends version prerelease to tag.
+func withPrerelease(tag string, version semver.Version) string {
+	if version.Prerelease() == "" {
+		return tag
 	}
-	return tag, nil
+	return tag + "-" + version.Prerelease()
 }
 
+// parseTagsOutput parses tags listed with tagsFormat, the first valid date of each line is used. Tags are sorted by date
+// keeping the listed order of tags with the same date, tags without dates are listed first.
 func parseTagsOutput(input string) ([]GitTag, error) {
 	scanner := bufio.NewScanner(strings.NewReader(input))
 	var result []GitTag
 	for scanner.Scan() {
 		if line := strings.TrimSpace(scanner.Text()); line != "" {
 			values := strings.Split(line, "#")
-			date, _ := time.Parse("2006-01-02 15:04:05 -0700", values[0]) // ignore invalid dates
-			result = append(result, GitTag{Name: values[1], Date: date})
+			var date time.Time
+			for _, value := range values[:len(values)-1] {
+				if parsed, err := time.Parse("2006-01-02 15:04:05 -0700", value); err == nil { // ignore invalid dates
+					date = parsed
+					break
+				}
+			}
+			result = append(result, GitTag{Name: values[len(values)-1], Date: date})
 		}
 	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
 	return result, nil
 }
 
+// parseStatusOutput parses `git status --porcelain -z` paths, renamed entries are followed by their original path, which is skipped.
+func parseStatusOutput(input string) []string {
+	var paths []string
+	entries := strings.Split(input, "\x00")
+	for i := 0; i < len(entries); i++ {
+		if len(entries[i]) < 4 {
+			continue
+		}
+		paths = append(paths, entries[i][3:])
+		if status := entries[i][:2]; strings.ContainsAny(status, "RC") {
+			i++
+		}
+	}
+	return paths
+}
+
 func parseLogOutput(messageProcessor MessageProcessor, log string) ([]GitCommitLog, error) {
 	scanner := bufio.NewScanner(strings.NewReader(log))
 	scanner.Split(splitAt([]byte(endLine)))
@@ -252,25 +1102,110 @@ func parseLogOutput(messageProcessor MessageProcessor, log string) ([]GitCommitL
 	return logs, nil
 }
 
+func parseLogAllOutput(messageProcessor MessageProcessor, log string) ([]GitCommitLog, error) {
+	var logs []GitCommitLog
+	for _, record := range strings.Split(log, logRecordStart) {
+		end := strings.LastIndex(record, endLine)
+		if end < 0 {
+			continue
+		}
+
+		content := strings.SplitN(record[:end], logSeparator, 9)
+		if len(content) < 9 {
+			return nil, fmt.Errorf("invalid git log record: %s", record[:end])
+		}
+		date, err := parseAuthorDate(content[0], content[4])
+		if err != nil {
+			return nil, err
+		}
+		timestamp, _ := strconv.Atoi(content[1])
+		parents := strings.Fields(content[5])
+		message, err := parseMessage(messageProcessor, content[7], content[8], parents)
+		if err != nil {
+			return nil, err
+		}
+
+		logs = append(logs, GitCommitLog{
+			Date:        date.Format("2006-01-02"),
+			Timestamp:   timestamp,
+			AuthorDate:  date,
+			AuthorName:  content[2],
+			AuthorEmail: content[3],
+			Hash:        content[4],
+			Message:     message,
+			Tags:        parseDecorationTags(content[6]),
+			Parents:     parents,
+			Files:       nonEmptyLines(record[end+len(endLine):]),
+		})
+	}
+	return logs, nil
+}
+
+// parseDecorationTags returns tag names from git %D output, e.g. "HEAD -> main, tag: v1.0.0".
+func parseDecorationTags(decoration string) []string {
+	var tags []string
+	for _, ref := range strings.Split(decoration, ",") {
+		if name := strings.TrimPrefix(strings.TrimSpace(ref), "tag: "); name != strings.TrimSpace(ref) {
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+func nonEmptyLines(input string) []string {
+	var lines []string
+	for _, line := range strings.Split(input, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
 func parseCommitLog(messageProcessor MessageProcessor, commit string) (GitCommitLog, error) {
 	content := strings.Split(strings.Trim(commit, "\""), logSeparator)
 
+	date, err := parseAuthorDate(content[0], content[4])
+	if err != nil {
+		return GitCommitLog{}, err
+	}
 	timestamp, _ := strconv.Atoi(content[1])
-	message, err := messageProcessor.Parse(content[4], content[5])
+	parents := strings.Fields(content[5])
+	message, err := parseMessage(messageProcessor, content[6], content[7], parents)
 
 	if err != nil {
 		return GitCommitLog{}, err
 	}
 
 	return GitCommitLog{
-		Date:       content[0],
-		Timestamp:  timestamp,
-		AuthorName: content[2],
-		Hash:       content[3],
-		Message:    message,
+		Date:        date.Format("2006-01-02"),
+		Timestamp:   timestamp,
+		AuthorDate:  date,
+		AuthorName:  content[2],
+		AuthorEmail: content[3],
+		Hash:        content[4],
+		Message:     message,
+		Parents:     parents,
 	}, nil
 }
 
+// parseAuthorDate parses git %aI author date, a date that does not parse is an error instead of a zero time.
+func parseAuthorDate(value, hash string) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid author date %q of commit %s", value, hash)
+	}
+	return date, nil
+}
+
+// parseMessage parses commit message, merge commits never fail and use the pull request title when available.
+func parseMessage(messageProcessor MessageProcessor, subject, body string, parents []string) (CommitMessage, error) {
+	if len(parents) > 1 {
+		return parseMergeMessage(messageProcessor, subject, body), nil
+	}
+	return messageProcessor.Parse(subject, body)
+}
+
 func splitAt(b []byte) func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		dataLen := len(data)
@@ -311,7 +1246,15 @@ func str(value, defaultValue string) string {
 	return defaultValue
 }
 
-func combinedOutputErr(err error, out []byte) error {
-	msg := strings.Split(string(out), "\n")
-	return fmt.Errorf("%v - %s", err, msg[0])
+// commandErr creates an error with the executed git command and its stderr trimmed to maxErrorOutputLength.
+func commandErr(args []string, err error, stderr string) error {
+	command := "git " + strings.Join(args, " ")
+	msg := strings.TrimSpace(stderr)
+	if msg == "" {
+		return fmt.Errorf("%s: %w", command, err)
+	}
+	if len(msg) > maxErrorOutputLength {
+		msg = msg[:maxErrorOutputLength] + "..."
+	}
+	return fmt.Errorf("%s: %w - %s", command, err, msg)
 }
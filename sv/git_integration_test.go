@@ -1,11 +1,18 @@
 package sv
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 )
@@ -17,7 +24,7 @@ import (
 // Returns:
 //   - gitCmd: runs git subcommands inside workDir, fatals on error
 //   - workDir: path to the working clone
-func setupIntegrationRepo(t *testing.T) (func(args ...string), string) {
+func setupIntegrationRepo(t testing.TB) (func(args ...string), string) {
 	t.Helper()
 
 	originDir := t.TempDir()
@@ -128,7 +135,7 @@ func TestTagForComponent_CreatesAndPushesTag(t *testing.T) {
 
 	g := GitImpl{}
 	ver := semver.MustParse("2.3.4")
-	tagName, err := g.TagForComponent(*ver, "libs/mylib")
+	tagName, err := g.TagForComponent(*ver, "libs/mylib", "origin")
 	if err != nil {
 		t.Fatalf("TagForComponent() error = %v", err)
 	}
@@ -160,7 +167,7 @@ func TestTagForComponent_RoundTrip(t *testing.T) {
 
 	g := GitImpl{}
 	ver1 := semver.MustParse("1.0.0")
-	if tag, err := g.TagForComponent(*ver1, "api/v1"); err != nil {
+	if tag, err := g.TagForComponent(*ver1, "api/v1", "origin"); err != nil {
 		t.Fatalf("TagForComponent() v1 error = %v", err)
 	} else if tag != "api/v1/v1.0.0" {
 		t.Errorf("TagForComponent() v1 = %q, want api/v1/v1.0.0", tag)
@@ -169,7 +176,7 @@ func TestTagForComponent_RoundTrip(t *testing.T) {
 	addCommit(t, gitCmd, workDir, "bump2.txt")
 
 	ver2 := semver.MustParse("1.1.0")
-	if tag, err := g.TagForComponent(*ver2, "api/v1"); err != nil {
+	if tag, err := g.TagForComponent(*ver2, "api/v1", "origin"); err != nil {
 		t.Fatalf("TagForComponent() v2 error = %v", err)
 	} else if tag != "api/v1/v1.1.0" {
 		t.Errorf("TagForComponent() v2 = %q, want api/v1/v1.1.0", tag)
@@ -186,3 +193,918 @@ func TestTagForComponent_RoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestTagForComponentAt_Prerelease(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+
+	g := GitImpl{}
+	beta := semver.MustParse("1.4.0-beta.1")
+	if tag, err := g.TagForComponent(*beta, "libs/mylib", ""); err != nil || tag != "libs/mylib/v1.4.0-beta.1" {
+		t.Fatalf("TagForComponent() = %q, %v, want libs/mylib/v1.4.0-beta.1", tag, err)
+	}
+	addCommit(t, gitCmd, workDir, "after-beta.txt")
+
+	stable := semver.MustParse("1.4.0")
+	if tag, err := g.TagForComponentAt(*stable, "libs/mylib", "refs/tags/libs/mylib/v1.4.0-beta.1", ""); err != nil || tag != "libs/mylib/v1.4.0" {
+		t.Fatalf("TagForComponentAt() = %q, %v, want libs/mylib/v1.4.0", tag, err)
+	}
+
+	betaCommit, err := g.ShortHash("refs/tags/libs/mylib/v1.4.0-beta.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stableCommit, err := g.ShortHash("refs/tags/libs/mylib/v1.4.0"); err != nil || stableCommit != betaCommit {
+		t.Errorf("TagForComponentAt() tagged %q, %v, want prerelease commit %q", stableCommit, err, betaCommit)
+	}
+}
+
+func TestHasStagedChanges(t *testing.T) {
+	_, workDir := setupIntegrationRepo(t)
+
+	g := GitImpl{}
+	if staged, err := g.HasStagedChanges(); err != nil || staged {
+		t.Fatalf("HasStagedChanges() = %v, %v, want false without staged files", staged, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(workDir, "new.txt"), []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Add("new.txt"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if staged, err := g.HasStagedChanges(); err != nil || !staged {
+		t.Errorf("HasStagedChanges() = %v, %v, want true after Add()", staged, err)
+	}
+}
+
+func TestCommit_Paths(t *testing.T) {
+	_, workDir := setupIntegrationRepo(t)
+	for _, name := range []string{"version.json", "other.txt"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var output bytes.Buffer
+	g := GitImpl{}
+	g.SetCommandOutput(&output)
+	if err := g.Add("version.json", "other.txt"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := g.Commit("chore(release): bump version", "", "", "version.json"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if !strings.Contains(output.String(), "chore(release): bump version") {
+		t.Errorf("Commit() output = %q, want git commit output on command output", output.String())
+	}
+	out, err := g.run("show", "--name-only", "--format=", "HEAD")
+	if err != nil || strings.TrimSpace(out) != "version.json" {
+		t.Errorf("Commit() committed files = %q, error %v, want only version.json", out, err)
+	}
+	if staged, err := g.StagedFiles(); err != nil || !reflect.DeepEqual(staged, []string{"other.txt"}) {
+		t.Errorf("StagedFiles() = %v, %v, want other.txt kept staged", staged, err)
+	}
+}
+
+func TestAdd_TrackedOnly(t *testing.T) {
+	_, workDir := setupIntegrationRepo(t)
+
+	if err := os.WriteFile(filepath.Join(workDir, "README.md"), []byte("changed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "untracked.txt"), []byte("untracked"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	g := GitImpl{}
+	if err := g.Add(); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	out, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		t.Fatalf("git diff --cached: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "README.md" {
+		t.Errorf("staged files = %q, want only README.md", got)
+	}
+}
+
+func TestAdd_InvalidPath(t *testing.T) {
+	_, _ = setupIntegrationRepo(t)
+
+	g := GitImpl{}
+	if err := g.Add("does-not-exist.txt"); err == nil {
+		t.Error("Add() expected error for missing path, got nil")
+	}
+}
+
+func TestStagedDiffStat(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+
+	g := GitImpl{}
+	if stat, err := g.StagedDiffStat(); err != nil || stat != "" {
+		t.Fatalf("StagedDiffStat() = %q, %v, want empty without staged files", stat, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(workDir, "staged.txt"), []byte("staged\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("add", "staged.txt")
+
+	stat, err := g.StagedDiffStat()
+	if err != nil {
+		t.Fatalf("StagedDiffStat() error = %v", err)
+	}
+	if !strings.Contains(stat, "staged.txt") || !strings.Contains(stat, "1 file changed") {
+		t.Errorf("StagedDiffStat() = %q, want stat for staged.txt", stat)
+	}
+}
+
+func TestGitImpl_ErrorOutsideRepository(t *testing.T) {
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(orig) })
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	filter := ""
+	g := GitImpl{tagCfg: TagConfig{Filter: &filter}}
+	_, err = g.Tags()
+	if err == nil {
+		t.Fatal("Tags() expected error outside a git repository, got nil")
+	}
+	if !strings.Contains(err.Error(), "git for-each-ref") {
+		t.Errorf("Tags() error = %q, want executed git command", err.Error())
+	}
+	if !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("Tags() error = %q, want git stderr", err.Error())
+	}
+}
+
+func TestGitImpl_LogCommands(t *testing.T) {
+	_, _ = setupIntegrationRepo(t)
+
+	var buf strings.Builder
+	g := GitImpl{}
+	g.LogCommands(&buf)
+	_ = g.LastComponentTag("services/my-service")
+
+	if want := "git for-each-ref --sort creatordate --format %(creatordate:iso8601)#%(*committerdate:iso8601)#%(refname:short) refs/tags\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("LogCommands() output = %q, want to contain %q", buf.String(), want)
+	}
+}
+
+func TestGitImpl_OnCommandExecuted(t *testing.T) {
+	_, _ = setupIntegrationRepo(t)
+
+	var commands []string
+	g := GitImpl{}
+	g.OnCommandExecuted(func(command string, duration time.Duration) {
+		if duration <= 0 {
+			t.Errorf("OnCommandExecuted() duration = %v, want positive duration", duration)
+		}
+		commands = append(commands, command)
+	})
+	if _, err := g.IsDetached(); err != nil {
+		t.Fatalf("IsDetached() error = %v", err)
+	}
+	if _, err := g.TagsAll(); err != nil {
+		t.Fatalf("TagsAll() error = %v", err)
+	}
+
+	want := []string{"symbolic-ref -q HEAD", "for-each-ref --sort creatordate --format %(creatordate:iso8601)#%(*committerdate:iso8601)#%(refname:short) refs/tags"}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("OnCommandExecuted() commands = %q, want %q", commands, want)
+	}
+}
+
+func TestUnshallow(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	addCommit(t, gitCmd, workDir, "a.txt")
+	gitCmd("tag", "-a", "1.0.0", "-m", "Version 1.0.0")
+	addCommit(t, gitCmd, workDir, "b.txt")
+	gitCmd("push", "origin", "HEAD", "--tags")
+
+	out, err := exec.Command("git", "-C", workDir, "remote", "get-url", "origin").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shallowDir := t.TempDir()
+	if out, err := exec.Command("git", "clone", "--depth", "1", "--no-tags", "--origin", "upstream", "file://"+strings.TrimSpace(string(out)), shallowDir).CombinedOutput(); err != nil {
+		t.Fatalf("git clone --depth 1: %v\n%s", err, out)
+	}
+
+	filter := ""
+	g := GitImpl{tagCfg: TagConfig{Filter: &filter}}
+	g.SetDir(shallowDir)
+
+	shallow, err := g.IsShallow()
+	if err != nil || !shallow {
+		t.Fatalf("IsShallow() = %v, %v, want true, nil", shallow, err)
+	}
+	if tag := g.LastTag(); tag != "" {
+		t.Fatalf("LastTag() = %q, want empty on shallow clone without tags", tag)
+	}
+
+	if err := g.Unshallow(); err != nil {
+		t.Fatalf("Unshallow() unexpected error: %v", err)
+	}
+
+	if shallow, err := g.IsShallow(); err != nil || shallow {
+		t.Errorf("IsShallow() after Unshallow() = %v, %v, want false, nil", shallow, err)
+	}
+	if tag := g.LastTag(); tag != "1.0.0" {
+		t.Errorf("LastTag() after Unshallow() = %q, want %q", tag, "1.0.0")
+	}
+}
+
+func TestIsShallow_CompleteRepository(t *testing.T) {
+	_, _ = setupIntegrationRepo(t)
+
+	g := GitImpl{}
+	if shallow, err := g.IsShallow(); err != nil || shallow {
+		t.Errorf("IsShallow() = %v, %v, want false, nil", shallow, err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	g := GitImpl{}
+	version, err := g.Version()
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if !regexp.MustCompile(`^\d+\.\d+`).MatchString(version) {
+		t.Errorf("Version() = %q, want a version number", version)
+	}
+}
+
+func TestHooksPath(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+
+	g := GitImpl{}
+	got, err := g.HooksPath()
+	if err != nil {
+		t.Fatalf("HooksPath() error = %v", err)
+	}
+	if want := filepath.Join(workDir, ".git", "hooks"); got != want {
+		t.Errorf("HooksPath() = %q, want %q", got, want)
+	}
+
+	hooksDir := filepath.Join(workDir, "hooks")
+	gitCmd("config", "core.hooksPath", hooksDir)
+	if got, err = g.HooksPath(); err != nil || got != hooksDir {
+		t.Errorf("HooksPath() with core.hooksPath = %q, %v, want %q", got, err, hooksDir)
+	}
+}
+
+func TestTagRemote(t *testing.T) {
+	gitCmd, _ := setupIntegrationRepo(t)
+
+	g := GitImpl{}
+	if got := g.TagRemote(); got != "origin" {
+		t.Errorf("TagRemote() = %q, want origin", got)
+	}
+
+	upstreamDir := t.TempDir()
+	if err := exec.Command("git", "init", "--bare", upstreamDir).Run(); err != nil {
+		t.Fatalf("git init --bare: %v", err)
+	}
+	gitCmd("remote", "add", "upstream", upstreamDir)
+	gitCmd("push", "-u", "upstream", "HEAD")
+	if got := g.TagRemote(); got != "upstream" {
+		t.Errorf("TagRemote() = %q, want branch remote upstream", got)
+	}
+
+	remote := ""
+	g = GitImpl{tagCfg: TagConfig{Remote: &remote}}
+	if got := g.TagRemote(); got != "" {
+		t.Errorf("TagRemote() = %q, want empty from tag.remote config", got)
+	}
+}
+
+func TestTag_Remote(t *testing.T) {
+	gitCmd, _ := setupIntegrationRepo(t)
+	upstreamDir := t.TempDir()
+	if err := exec.Command("git", "init", "--bare", upstreamDir).Run(); err != nil {
+		t.Fatalf("git init --bare: %v", err)
+	}
+	gitCmd("remote", "add", "upstream", upstreamDir)
+
+	pattern := "v%d.%d.%d"
+	g := GitImpl{tagCfg: TagConfig{Pattern: &pattern}}
+
+	if exists, err := g.RemoteExists("upstream"); err != nil || !exists {
+		t.Fatalf("RemoteExists(upstream) = %v, %v, want true, nil", exists, err)
+	}
+	if exists, err := g.RemoteExists("unknown"); err != nil || exists {
+		t.Fatalf("RemoteExists(unknown) = %v, %v, want false, nil", exists, err)
+	}
+
+	if _, err := g.Tag(*semver.MustParse("1.0.0"), "upstream"); err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+	if _, err := g.Tag(*semver.MustParse("1.1.0"), ""); err != nil {
+		t.Fatalf("Tag() without remote error = %v", err)
+	}
+
+	remoteTags := func(dir string) string {
+		out, err := exec.Command("git", "-C", dir, "tag", "-l").Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if got := remoteTags(upstreamDir); got != "v1.0.0" {
+		t.Errorf("upstream tags = %q, want only v1.0.0", got)
+	}
+	originURL, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := remoteTags(strings.TrimSpace(string(originURL))); got != "" {
+		t.Errorf("origin tags = %q, want none", got)
+	}
+}
+
+func TestTag_ExistingTag(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	pattern := "v%d.%d.%d"
+	g := GitImpl{tagCfg: TagConfig{Pattern: &pattern}}
+
+	if _, err := g.Tag(*semver.MustParse("1.0.0"), "origin"); err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+	tagged := revParse(t, g, "v1.0.0^{commit}")
+	addCommit(t, gitCmd, workDir, "a.txt")
+
+	_, err := g.Tag(*semver.MustParse("1.0.0"), "origin")
+	var existsErr TagExistsError
+	if !errors.As(err, &existsErr) || existsErr.Tag != "v1.0.0" || !strings.HasPrefix(tagged, existsErr.Commit) {
+		t.Fatalf("Tag() error = %v, want TagExistsError for v1.0.0 on %s", err, tagged)
+	}
+
+	if err := g.DeleteTag("v1.0.0", "origin"); err != nil {
+		t.Fatalf("DeleteTag() error = %v", err)
+	}
+	if _, err := g.Tag(*semver.MustParse("1.0.0"), "origin"); err != nil {
+		t.Fatalf("Tag() after DeleteTag() error = %v", err)
+	}
+	if got, want := revParse(t, g, "v1.0.0^{commit}"), revParse(t, g, "HEAD"); got != want {
+		t.Errorf("tag v1.0.0 points to %s, want HEAD %s", got, want)
+	}
+	remote, err := g.run("ls-remote", "--tags", "origin", "refs/tags/v1.0.0^{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(remote, revParse(t, g, "HEAD")) {
+		t.Errorf("origin tag v1.0.0 = %q, want HEAD", remote)
+	}
+
+	if err := g.DeleteTag("v1.0.0", ""); err != nil {
+		t.Fatalf("DeleteTag() without remote error = %v", err)
+	}
+	if err := g.DeleteTag("v1.0.0", "origin"); err == nil {
+		t.Error("DeleteTag() expected error for missing tag, got nil")
+	}
+}
+
+func TestTag_PushFailure(t *testing.T) {
+	gitCmd, _ := setupIntegrationRepo(t)
+	gitCmd("remote", "add", "broken", filepath.Join(t.TempDir(), "missing"))
+	pattern := "v%d.%d.%d"
+	g := GitImpl{tagCfg: TagConfig{Pattern: &pattern}}
+
+	_, err := g.Tag(*semver.MustParse("1.0.0"), "broken")
+	var pushErr TagPushError
+	if !errors.As(err, &pushErr) || pushErr.Tag != "v1.0.0" || pushErr.Remote != "broken" {
+		t.Fatalf("Tag() error = %v, want TagPushError for v1.0.0 on broken", err)
+	}
+	if got, want := revParse(t, g, "v1.0.0^{commit}"), revParse(t, g, "HEAD"); got != want {
+		t.Errorf("local tag v1.0.0 points to %s, want HEAD %s", got, want)
+	}
+}
+
+func TestPushTags(t *testing.T) {
+	gitCmd, _ := setupIntegrationRepo(t)
+	g := GitImpl{}
+	out, err := g.run("remote", "get-url", "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin := strings.TrimSpace(out)
+	hook := filepath.Join(origin, "hooks", "update")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\ncase \"$1\" in refs/tags/beta/*) exit 1;; esac\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"alpha/v1.0.0", "beta/v1.0.0", "gamma/v1.0.0"} {
+		gitCmd("tag", tag)
+	}
+
+	err = g.PushTags("origin", []string{"alpha/v1.0.0", "beta/v1.0.0", "gamma/v1.0.0"})
+	var pushErr TagsPushError
+	if !errors.As(err, &pushErr) {
+		t.Fatalf("PushTags() error = %v, want TagsPushError", err)
+	}
+	if got := pushErr.Tags(); !reflect.DeepEqual(got, []string{"beta/v1.0.0"}) {
+		t.Errorf("PushTags() failed tags = %v, want [beta/v1.0.0]", got)
+	}
+	remoteTags, lerr := g.run("ls-remote", "--tags", origin)
+	if lerr != nil {
+		t.Fatal(lerr)
+	}
+	if !strings.Contains(remoteTags, "refs/tags/alpha/v1.0.0") || !strings.Contains(remoteTags, "refs/tags/gamma/v1.0.0") || strings.Contains(remoteTags, "beta") {
+		t.Errorf("remote tags = %q, want alpha and gamma", remoteTags)
+	}
+
+	gitCmd("remote", "add", "broken", filepath.Join(t.TempDir(), "missing"))
+	if err := g.PushTags("broken", []string{"alpha/v1.0.0", "gamma/v1.0.0"}); !errors.As(err, &pushErr) || len(pushErr.Failed) != 2 {
+		t.Errorf("PushTags() on missing remote error = %v, want every tag failed", err)
+	}
+}
+
+func TestTag_BuildMetadata(t *testing.T) {
+	setupIntegrationRepo(t)
+	pattern, filter := "v%d.%d.%d", "v*"
+	g := GitImpl{tagCfg: TagConfig{Pattern: &pattern, Filter: &filter}}
+
+	version, err := semver.MustParse("1.2.0").SetMetadata("build.7.sha.abc1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := g.Tag(version, "")
+	if err != nil || tag != "v1.2.0+build.7.sha.abc1234" {
+		t.Fatalf("Tag() = %q, %v, want v1.2.0+build.7.sha.abc1234", tag, err)
+	}
+	componentTag, err := g.TagForComponent(version, "services/api", "")
+	if err != nil || componentTag != "services/api/v1.2.0+build.7.sha.abc1234" {
+		t.Fatalf("TagForComponent() = %q, %v, want services/api/v1.2.0+build.7.sha.abc1234", componentTag, err)
+	}
+
+	hash, err := g.ShortHash("HEAD")
+	if err != nil || !strings.HasPrefix(revParse(t, g, "HEAD"), hash) {
+		t.Errorf("ShortHash() = %q, %v, want HEAD abbreviated hash", hash, err)
+	}
+	if lastTag, _ := ToVersion(g.LastTag()); !lastTag.Equal(semver.MustParse("1.2.0")) || lastTag.Metadata() != "" {
+		t.Errorf("ToVersion(LastTag()) = %v, want 1.2.0 without metadata", lastTag)
+	}
+}
+
+func TestPatchID(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	g := GitImpl{}
+	gitCmd("checkout", "-q", "-b", "release")
+	gitCmd("checkout", "-q", "-")
+	addCommit(t, gitCmd, workDir, "a.txt")
+	original := revParse(t, g, "HEAD")
+	addCommit(t, gitCmd, workDir, "b.txt")
+	other := revParse(t, g, "HEAD")
+	gitCmd("checkout", "-q", "release")
+	gitCmd("cherry-pick", "-x", original)
+	picked := revParse(t, g, "HEAD")
+
+	originalID, err := g.PatchID(original)
+	if err != nil || originalID == "" {
+		t.Fatalf("PatchID(%s) = %q, %v, want patch id", original, originalID, err)
+	}
+	if pickedID, err := g.PatchID(picked); err != nil || pickedID != originalID {
+		t.Errorf("PatchID(cherry-pick) = %q, %v, want %q", pickedID, err, originalID)
+	}
+	if otherID, err := g.PatchID(other); err != nil || otherID == originalID {
+		t.Errorf("PatchID(other) = %q, %v, want different patch id", otherID, err)
+	}
+}
+
+func TestStagedFiles(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	g := GitImpl{}
+
+	if files, err := g.StagedFiles(); err != nil || len(files) != 0 {
+		t.Fatalf("StagedFiles() = %v, %v, want no files", files, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(workDir, "infra"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"infra/main tf", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+		gitCmd("add", name)
+	}
+
+	want := []string{"b.txt", "infra/main tf"}
+	if files, err := g.StagedFiles(); err != nil || !reflect.DeepEqual(files, want) {
+		t.Errorf("StagedFiles() = %q, %v, want %q", files, err, want)
+	}
+}
+
+func TestBehindUpstream(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	g := GitImpl{}
+
+	if upstream, behind, err := g.BehindUpstream(); err != nil || behind != 0 || !strings.HasPrefix(upstream, "origin/") {
+		t.Fatalf("BehindUpstream() = %q, %d, %v, want origin branch, 0, nil", upstream, behind, err)
+	}
+
+	originURL, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherDir := t.TempDir()
+	for _, args := range [][]string{
+		{"clone", strings.TrimSpace(string(originURL)), otherDir},
+		{"-C", otherDir, "-c", "user.email=other@test.com", "-c", "user.name=Other", "commit", "--allow-empty", "-m", "fix: one"},
+		{"-C", otherDir, "-c", "user.email=other@test.com", "-c", "user.name=Other", "commit", "--allow-empty", "-m", "fix: two"},
+		{"-C", otherDir, "push", "origin", "HEAD"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	if _, behind, err := g.BehindUpstream(); err != nil || behind != 0 {
+		t.Errorf("BehindUpstream() before fetch = %d, %v, want 0, nil", behind, err)
+	}
+	gitCmd("fetch", "origin")
+	addCommit(t, gitCmd, workDir, "local.txt")
+	if _, behind, err := g.BehindUpstream(); err != nil || behind != 2 {
+		t.Errorf("BehindUpstream() after fetch = %d, %v, want 2, nil", behind, err)
+	}
+
+	gitCmd("checkout", "-q", "-b", "no-upstream")
+	if upstream, behind, err := g.BehindUpstream(); err != nil || behind != 0 || upstream != "" {
+		t.Errorf("BehindUpstream() without upstream = %q, %d, %v, want empty, 0, nil", upstream, behind, err)
+	}
+}
+
+func TestMergeBaseAndDefaultBranch(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	g := GitImpl{}
+
+	if _, err := g.DefaultBranch(); err == nil {
+		t.Errorf("DefaultBranch() without origin/HEAD expected error")
+	}
+	gitCmd("remote", "set-head", "origin", "--auto")
+	defaultBranch, err := g.DefaultBranch()
+	if err != nil || !strings.HasPrefix(defaultBranch, "origin/") {
+		t.Fatalf("DefaultBranch() = %q, %v, want origin branch", defaultBranch, err)
+	}
+
+	base, err := g.ShortHash("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("checkout", "-b", "feature")
+	addCommit(t, gitCmd, workDir, "feature.txt")
+	gitCmd("checkout", "-")
+	addCommit(t, gitCmd, workDir, "main.txt")
+
+	if got, err := g.MergeBase("HEAD", "feature"); err != nil || got != base {
+		t.Errorf("MergeBase() = %q, %v, want %q", got, err, base)
+	}
+	gitCmd("checkout", "--orphan", "unrelated")
+	gitCmd("commit", "--allow-empty", "-m", "chore: unrelated")
+	if _, err := g.MergeBase("unrelated", "feature"); err == nil || !strings.Contains(err.Error(), "no common history") {
+		t.Errorf("MergeBase() of unrelated histories error = %v", err)
+	}
+}
+
+func revParse(t *testing.T, g GitImpl, revision string) string {
+	t.Helper()
+	out, err := g.run("rev-parse", revision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(out)
+}
+
+func TestComponentTags(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+
+	addCommit(t, gitCmd, workDir, "a.txt")
+	gitCmd("tag", "-a", "services/payments/v1.0.0", "-m", "v1.0.0")
+	addCommit(t, gitCmd, workDir, "b.txt")
+	gitCmd("tag", "-a", "services/orders/v1.0.0", "-m", "v1.0.0")
+	addCommit(t, gitCmd, workDir, "c.txt")
+	gitCmd("tag", "-a", "services/payments/v1.1.0", "-m", "v1.1.0")
+
+	g := GitImpl{}
+	tags, err := g.ComponentTags("services/payments")
+	if err != nil {
+		t.Fatalf("ComponentTags() error = %v", err)
+	}
+
+	var got []string
+	for _, tag := range tags {
+		got = append(got, tag.Name)
+	}
+	want := []string{"services/payments/v1.0.0", "services/payments/v1.1.0"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ComponentTags() = %v, want %v", got, want)
+	}
+}
+
+func TestShowFile(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	if err := os.MkdirAll(filepath.Join(workDir, "services", "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	versionFile := filepath.Join(workDir, "services", "api", "version.yml")
+	if err := os.WriteFile(versionFile, []byte("version: 1.0.0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "chore: add version")
+	if err := os.WriteFile(versionFile, []byte("version: 1.1.0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	g := GitImpl{}
+	got, err := g.ShowFile("HEAD", filepath.Join("services", "api", "version.yml"))
+	if err != nil {
+		t.Fatalf("ShowFile() error = %v", err)
+	}
+	if string(got) != "version: 1.0.0\n" {
+		t.Errorf("ShowFile() = %q, want committed content", got)
+	}
+
+	if _, err := g.ShowFile("HEAD", "missing.yml"); err == nil {
+		t.Error("ShowFile() expected error for missing file, got nil")
+	}
+}
+
+func TestLogAll_MatchesLog(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	for _, dir := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(workDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	addCommit(t, gitCmd, workDir, "a/one")
+	gitCmd("tag", "-a", "a/v1.0.0", "-m", "a/v1.0.0")
+	gitCmd("checkout", "-b", "feature")
+	addCommit(t, gitCmd, workDir, "b/two")
+	gitCmd("checkout", "-")
+	addCommit(t, gitCmd, workDir, "a/three")
+	gitCmd("merge", "--no-ff", "-m", "feat: merge feature", "feature")
+	gitCmd("tag", "-a", "v2.0.0", "-m", "v2.0.0")
+	addCommit(t, gitCmd, workDir, "b/four")
+
+	ranges := []LogRange{
+		NewLogRange(TagRange, "", ""),
+		NewLogRange(TagRange, "a/v1.0.0", ""),
+		NewLogRange(TagRange, "a/v1.0.0", "v2.0.0"),
+		NewLogRangeWithPaths(TagRange, "a/v1.0.0", "", []string{"a"}),
+		NewLogRangeWithPaths(TagRange, "", "", []string{"b"}),
+		NewLogRangeWithPaths(TagRange, "v2.0.0", "", []string{"b"}),
+	}
+	for _, firstParent := range []bool{false, true} {
+		for _, policy := range []string{IncludeMergesNever, IncludeMergesAlways, IncludeMergesOnlyConventional} {
+			cfg := LogConfig{IncludeMerges: policy, FirstParent: firstParent}
+			g := NewGit(NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{}), TagConfig{}, cfg)
+			cached := NewCachedLogGit(g, cfg)
+			for _, lr := range ranges {
+				want, err := g.Log(lr)
+				if err != nil {
+					t.Fatalf("Log(%+v) unexpected error: %v", lr, err)
+				}
+				got, err := cached.Log(lr)
+				if err != nil {
+					t.Fatalf("CachedLogGit.Log(%+v) unexpected error: %v", lr, err)
+				}
+				if !reflect.DeepEqual(hashes(got), hashes(want)) {
+					t.Errorf("%s, first-parent %v: CachedLogGit.Log(%+v) = %v, want %v", policy, firstParent, lr, hashes(got), hashes(want))
+				}
+			}
+		}
+	}
+}
+
+func TestLog_IncludeMerges(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	addCommit(t, gitCmd, workDir, "one")
+	gitCmd("checkout", "-b", "feature")
+	addCommit(t, gitCmd, workDir, "two")
+	gitCmd("checkout", "-")
+	gitCmd("merge", "--no-ff", "-m", "Merge pull request #12 from org/feature", "-m", "feat: add feature", "feature")
+	gitCmd("checkout", "-b", "other")
+	addCommit(t, gitCmd, workDir, "three")
+	gitCmd("checkout", "-")
+	gitCmd("merge", "--no-ff", "-m", "Merge branch 'other'", "other")
+
+	tests := []struct {
+		name string
+		cfg  LogConfig
+		want []string
+	}{
+		{"default", LogConfig{}, []string{": initial commit", "chore: add one", "chore: add three", "chore: add two"}},
+		{"never", LogConfig{IncludeMerges: IncludeMergesNever}, []string{": initial commit", "chore: add one", "chore: add three", "chore: add two"}},
+		{"always", LogConfig{IncludeMerges: IncludeMergesAlways}, []string{": Merge branch 'other'", ": initial commit", "chore: add one", "chore: add three", "chore: add two", "feat: add feature"}},
+		{"only conventional", LogConfig{IncludeMerges: IncludeMergesOnlyConventional}, []string{": initial commit", "chore: add one", "chore: add three", "chore: add two", "feat: add feature"}},
+		{"first parent", LogConfig{FirstParent: true}, []string{": initial commit", "chore: add one"}},
+		{"first parent only conventional", LogConfig{IncludeMerges: IncludeMergesOnlyConventional, FirstParent: true}, []string{": initial commit", "chore: add one", "feat: add feature"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGit(NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{}), TagConfig{}, tt.cfg)
+			commits, err := g.Log(NewLogRange(TagRange, "", ""))
+			if err != nil {
+				t.Fatalf("Log() unexpected error: %v", err)
+			}
+			var got []string
+			for _, commit := range commits {
+				got = append(got, commit.Message.Type+": "+commit.Message.Description)
+				if commit.Message.Type == "feat" && commit.Message.Metadata["pr"] != "12" {
+					t.Errorf("Log() pull request metadata = %v, want 12", commit.Message.Metadata)
+				}
+			}
+			sort.Strings(got) // commits created on the same second have no stable order
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Log() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagsAll(t *testing.T) {
+	gitCmd, _ := setupIntegrationRepo(t)
+	gitCmd("tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	gitCmd("tag", "-a", "services/payments/v1.0.0", "-m", "payments v1.0.0")
+
+	tags, err := NewGit(NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{}), TagConfig{}, LogConfig{}).TagsAll()
+	if err != nil {
+		t.Fatalf("TagsAll() unexpected error: %v", err)
+	}
+	var names []string
+	for _, tag := range tags {
+		if tag.Date.IsZero() {
+			t.Errorf("TagsAll() tag %s without creation date", tag.Name)
+		}
+		names = append(names, tag.Name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"services/payments/v1.0.0", "v1.0.0"}) {
+		t.Errorf("TagsAll() = %v, want v1.0.0 and services/payments/v1.0.0", names)
+	}
+}
+
+func TestLastTag_Monotonic(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	tag := func(name, date string) {
+		t.Helper()
+		cmd := exec.Command("git", "tag", "-a", name, "-m", name)
+		cmd.Dir = workDir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git tag %s: %v\n%s", name, err, out)
+		}
+	}
+	// v1.9.5 is tagged on the maintenance branch after v2.3.0 and merged back, v3.0.0 is newer but not merged.
+	gitCmd("checkout", "-b", "main")
+	tag("v1.9.0", "2020-01-01T00:00:00+00:00")
+	gitCmd("branch", "maintenance")
+	gitCmd("branch", "next")
+	addCommit(t, gitCmd, workDir, "two")
+	tag("v2.3.0", "2021-01-01T00:00:00+00:00")
+	tag("latest", "2021-06-01T00:00:00+00:00")
+	gitCmd("checkout", "maintenance")
+	addCommit(t, gitCmd, workDir, "fix")
+	tag("v1.9.5", "2022-01-01T00:00:00+00:00")
+	gitCmd("checkout", "next")
+	addCommit(t, gitCmd, workDir, "three")
+	tag("v3.0.0", "2023-01-01T00:00:00+00:00")
+	gitCmd("checkout", "main")
+	gitCmd("merge", "--no-ff", "-m", "Merge branch 'maintenance'", "maintenance")
+
+	filter := ""
+	tests := []struct {
+		name      string
+		monotonic bool
+		filter    string
+		want      string
+	}{
+		{"most recent", false, "", "v3.0.0"},
+		{"monotonic", true, "", "v2.3.0"},
+		{"monotonic with filter", true, "v1.*", "v1.9.5"},
+		{"monotonic without matching tag", true, "v4.*", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter = tt.filter
+			g := NewGit(NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{}), TagConfig{Filter: &filter}, LogConfig{})
+			g.SetMonotonic(tt.monotonic)
+			if got := g.LastTag(); got != tt.want {
+				t.Errorf("LastTag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// setupTagsFixture creates components tags for each of the components, all pointing to HEAD.
+func setupTagsFixture(b *testing.B, components, tagsPerComponent int) []string {
+	b.Helper()
+	_, workDir := setupIntegrationRepo(b)
+
+	var refs strings.Builder
+	names := make([]string, 0, components)
+	for c := 0; c < components; c++ {
+		name := fmt.Sprintf("services/svc%03d", c)
+		names = append(names, name)
+		for v := 0; v < tagsPerComponent; v++ {
+			fmt.Fprintf(&refs, "create refs/tags/%s/v1.0.%d HEAD\n", name, v)
+		}
+	}
+	cmd := exec.Command("git", "update-ref", "--stdin")
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(refs.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		b.Fatalf("git update-ref: %v\n%s", err, out)
+	}
+	return names
+}
+
+// BenchmarkComponentTags compares one for-each-ref per component, as LastComponentTag used to run,
+// with a single TagsAll call filtered in memory, fixture has 1k tags.
+func BenchmarkComponentTags(b *testing.B) {
+	components := setupTagsFixture(b, 50, 20)
+	g := NewGit(NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{}), TagConfig{}, LogConfig{})
+
+	b.Run("per component", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, component := range components {
+				if runGit(b, "for-each-ref", "refs/tags/"+component+"/v*", "--sort", "-creatordate", "--format", "%(refname:short)", "--count", "1") == "" {
+					b.Fatalf("no tag found for %s", component)
+				}
+			}
+		}
+	})
+	b.Run("tags all", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tags, err := g.TagsAll()
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, component := range components {
+				if LatestTag(FilterComponentTags(tags, component)) == "" {
+					b.Fatalf("no tag found for %s", component)
+				}
+			}
+		}
+	})
+}
+
+func runGit(b *testing.B, args ...string) string {
+	b.Helper()
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		b.Fatalf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestLog_WithFiles(t *testing.T) {
+	gitCmd, workDir := setupIntegrationRepo(t)
+	for _, dir := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(workDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addCommit(t, gitCmd, workDir, "a/one")
+	for _, name := range []string{"a/two", "b/two"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitCmd("add", "a/two", "b/two")
+	gitCmd("commit", "-m", "feat: shared change")
+
+	g := NewGit(NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{}), TagConfig{}, LogConfig{})
+	commits, err := g.Log(NewLogRangeWithPaths(TagRange, "", "", []string{"a"}).WithFiles())
+	if err != nil {
+		t.Fatalf("Log() unexpected error: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Log() returned %d commits, want 2", len(commits))
+	}
+	if commits[0].Message.Description != "shared change" || !reflect.DeepEqual(commits[0].Files, []string{"a/two", "b/two"}) {
+		t.Errorf("Log() first commit = %q, files = %v, want files outside range paths", commits[0].Message.Description, commits[0].Files)
+	}
+	if !reflect.DeepEqual(commits[1].Files, []string{"a/one"}) {
+		t.Errorf("Log() second commit files = %v, want [a/one]", commits[1].Files)
+	}
+
+	commits, err = g.Log(NewLogRange(TagRange, "", ""))
+	if err != nil {
+		t.Fatalf("Log() unexpected error: %v", err)
+	}
+	if len(commits) != 3 || commits[0].Files != nil {
+		t.Errorf("Log() without files = %d commits, files = %v, want 3 commits without files", len(commits), commits[0].Files)
+	}
+}
@@ -1,7 +1,9 @@
 package sv
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -15,6 +17,13 @@ func Test_parseTagsOutput(t *testing.T) {
 	}{
 		{"with date", "2020-05-01 18:00:00 -0300#1.0.0", []GitTag{{Name: "1.0.0", Date: date("2020-05-01 18:00:00 -0300")}}, false},
 		{"without date", "#1.0.0", []GitTag{{Name: "1.0.0", Date: time.Time{}}}, false},
+		{"lightweight tag", "2020-05-01 18:00:00 -0300##1.0.0", []GitTag{{Name: "1.0.0", Date: date("2020-05-01 18:00:00 -0300")}}, false},
+		{"annotated tag without tagger", "#2020-05-01 18:00:00 -0300#1.0.0", []GitTag{{Name: "1.0.0", Date: date("2020-05-01 18:00:00 -0300")}}, false},
+		{"sorted by date", "2020-05-02 10:00:00 +0000#2020-05-01 10:00:00 +0000#a/v1.1.0\n#2020-05-01 12:00:00 +0000#a/v1.0.0\n2020-05-02 10:00:00 +0000##b/v1.0.0", []GitTag{
+			{Name: "a/v1.0.0", Date: date("2020-05-01 12:00:00 +0000")},
+			{Name: "a/v1.1.0", Date: date("2020-05-02 10:00:00 +0000")},
+			{Name: "b/v1.0.0", Date: date("2020-05-02 10:00:00 +0000")},
+		}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -30,6 +39,35 @@ func Test_parseTagsOutput(t *testing.T) {
 	}
 }
 
+func Test_parseRawCommitsOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []GitRawCommit
+	}{
+		{"empty", "", nil},
+		{"messages with body", "\x1eabc1234\x00feat: add login\n\nbody line\n\nRefs: #1\n\n\x1edef5678\x00fix: timeout\n", []GitRawCommit{
+			{Hash: "abc1234", Message: "feat: add login\n\nbody line\n\nRefs: #1"},
+			{Hash: "def5678", Message: "fix: timeout"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRawCommitsOutput(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRawCommitsOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsZeroHash(t *testing.T) {
+	for rev, want := range map[string]bool{strings.Repeat("0", 40): true, strings.Repeat("0", 64): true, "": false, "0a00000": false} {
+		if got := IsZeroHash(rev); got != want {
+			t.Errorf("IsZeroHash(%q) = %v, want %v", rev, got, want)
+		}
+	}
+}
+
 func date(input string) time.Time {
 	t, err := time.Parse("2006-01-02 15:04:05 -0700", input)
 	if err != nil {
@@ -37,3 +75,163 @@ func date(input string) time.Time {
 	}
 	return t
 }
+
+func Test_commandErr(t *testing.T) {
+	longOutput := strings.Repeat("a", maxErrorOutputLength+10)
+	tests := []struct {
+		name   string
+		args   []string
+		stderr string
+		want   string
+	}{
+		{"without stderr", []string{"tag", "v1"}, "", "git tag v1: exit status 1"},
+		{"with stderr", []string{"tag", "v1"}, "fatal: tag 'v1' already exists\n", "git tag v1: exit status 1 - fatal: tag 'v1' already exists"},
+		{"long stderr", []string{"log"}, longOutput, "git log: exit status 1 - " + longOutput[:maxErrorOutputLength] + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandErr(tt.args, errors.New("exit status 1"), tt.stderr); got.Error() != tt.want {
+				t.Errorf("commandErr() = %q, want %q", got.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseLogAllOutput(t *testing.T) {
+	input := "\x1e2022-01-01T21:00:00-03:00###1641081600###Author###author@example.com###b2###a1###HEAD -> main, tag: v1.1.0, origin/main###feat: add b###body~~~\n\nb/file.go\nREADME.md\n" +
+		"\x1e2022-01-01T00:00:00Z###1640995200###Author###author@example.com###a1######tag: v1.0.0###fix: add a###~~~\n\na/file.go\n"
+
+	got, err := parseLogAllOutput(NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{}), input)
+	if err != nil {
+		t.Fatalf("parseLogAllOutput() unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("parseLogAllOutput() returned %d commits, want 2", len(got))
+	}
+	if got[0].Hash != "b2" || got[0].Message.Type != "feat" || got[0].Message.Body != "body" || got[0].Timestamp != 1641081600 || got[0].AuthorEmail != "author@example.com" {
+		t.Errorf("parseLogAllOutput() first commit = %+v", got[0])
+	}
+	if got[0].Date != "2022-01-01" || got[0].AuthorDate.Unix() != 1641081600 {
+		t.Errorf("parseLogAllOutput() first commit date = %s, author date = %v", got[0].Date, got[0].AuthorDate)
+	}
+	if !reflect.DeepEqual(got[0].Tags, []string{"v1.1.0"}) || !reflect.DeepEqual(got[0].Parents, []string{"a1"}) || !reflect.DeepEqual(got[0].Files, []string{"b/file.go", "README.md"}) {
+		t.Errorf("parseLogAllOutput() first commit tags = %v, parents = %v, files = %v", got[0].Tags, got[0].Parents, got[0].Files)
+	}
+	if len(got[1].Parents) != 0 || !reflect.DeepEqual(got[1].Tags, []string{"v1.0.0"}) || !reflect.DeepEqual(got[1].Files, []string{"a/file.go"}) {
+		t.Errorf("parseLogAllOutput() root commit tags = %v, parents = %v, files = %v", got[1].Tags, got[1].Parents, got[1].Files)
+	}
+}
+
+func Test_parseCommitLog_InvalidDate(t *testing.T) {
+	_, err := parseCommitLog(NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{}), "2022-01-01###1640995200###Author###author@example.com###a1######fix: add a###")
+	if want := `invalid author date "2022-01-01" of commit a1`; err == nil || err.Error() != want {
+		t.Errorf("parseCommitLog() error = %v, want %q", err, want)
+	}
+}
+
+func Test_parseStatusOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"clean", "", nil},
+		{"modified", " M a/file.go\x00M  README.md\x00", []string{"a/file.go", "README.md"}},
+		{"renamed", "R  new name.go\x00old name.go\x00 D b/removed.go\x00", []string{"new name.go", "b/removed.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseStatusOutput(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStatusOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseDecorationTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		decoration string
+		want       []string
+	}{
+		{"empty", "", nil},
+		{"branches only", "HEAD -> main, origin/main", nil},
+		{"tags", "HEAD -> main, tag: v1.0.0, tag: payments/v2.0.0", []string{"v1.0.0", "payments/v2.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDecorationTags(tt.decoration); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDecorationTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterComponentTags(t *testing.T) {
+	tags := []GitTag{
+		{Name: "v1.0.0"},
+		{Name: "services/payments/v1.0.0"},
+		{Name: "services/payments-api/v1.0.0"},
+		{Name: "services/payments/nested/v1.0.0"},
+		{Name: "services/payments/v1.1.0"},
+		{Name: "services/billing/v1.2.0"},
+	}
+	tests := []struct {
+		name          string
+		componentPath string
+		previousPaths []string
+		want          []string
+	}{
+		{"component tags in order", "services/payments", nil, []string{"services/payments/v1.0.0", "services/payments/v1.1.0"}},
+		{"prefix of other component", "services/payments-api", nil, []string{"services/payments-api/v1.0.0"}},
+		{"nested component", "services/payments/nested", nil, []string{"services/payments/nested/v1.0.0"}},
+		{"unknown component", "services/unknown", nil, nil},
+		{"renamed component", "services/billing", []string{"services/payments"}, []string{"services/payments/v1.0.0", "services/payments/v1.1.0", "services/billing/v1.2.0"}},
+		{"renamed without tags on previous path", "services/billing", []string{"services/old"}, []string{"services/billing/v1.2.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, tag := range FilterComponentTags(tags, tt.componentPath, tt.previousPaths...) {
+				got = append(got, tag.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterComponentTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []GitTag
+		want string
+	}{
+		{"empty", nil, ""},
+		{"newest last", []GitTag{{Name: "a/v1.0.0", Date: date("2020-05-01 18:00:00 -0300")}, {Name: "a/v1.1.0", Date: date("2020-05-02 18:00:00 -0300")}}, "a/v1.1.0"},
+		{"same date keeps first", []GitTag{{Name: "a/v1.0.0", Date: date("2020-05-01 18:00:00 -0300")}, {Name: "a/v1.0.1", Date: date("2020-05-01 18:00:00 -0300")}}, "a/v1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LatestTag(tt.tags); got != tt.want {
+				t.Errorf("LatestTag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parsePushRejections(t *testing.T) {
+	output := "To /tmp/origin.git\n" +
+		"*\trefs/tags/alpha/v1.1.0:refs/tags/alpha/v1.1.0\t[new tag]\n" +
+		"!\trefs/tags/beta/v1.1.0:refs/tags/beta/v1.1.0\t[remote rejected] (hook declined)\n" +
+		"!\trefs/tags/gamma/v1.1.0:refs/tags/gamma/v1.1.0\t[rejected] (already exists)\n" +
+		"Done\n"
+	want := map[string]string{
+		"refs/tags/beta/v1.1.0":  "[remote rejected] (hook declined)",
+		"refs/tags/gamma/v1.1.0": "[rejected] (already exists)",
+	}
+	if got := parsePushRejections(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePushRejections() = %v, want %v", got, want)
+	}
+}
@@ -37,6 +37,12 @@ func releaseNote(version *semver.Version, tag string, date time.Time, sections [
 	}
 }
 
+func withSummary(rn ReleaseNote, commitCount int, contributors ...string) ReleaseNote {
+	rn.CommitCount = commitCount
+	rn.Contributors = contributors
+	return rn
+}
+
 func newReleaseNoteCommitsSection(name string, types []string, items []GitCommitLog) ReleaseNoteCommitsSection {
 	return ReleaseNoteCommitsSection{
 		Name:  name,
@@ -44,3 +50,7 @@ func newReleaseNoteCommitsSection(name string, types []string, items []GitCommit
 		Items: items,
 	}
 }
+
+func boolPtr(value bool) *bool {
+	return &value
+}
@@ -0,0 +1,134 @@
+package sv
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// URLPlaceholderIssue placeholder replaced by the issue id, without leading #, on commit-message.issue.trackers url-template.
+const URLPlaceholderIssue = "{id}"
+
+// IssueReferences issues of a tracker referenced by a commit, see CommitMessageIssueConfig.Trackers.
+type IssueReferences struct {
+	Tracker string      `json:"tracker"`
+	Issues  []IssueLink `json:"issues"`
+}
+
+// IssueLink issue id and its link from tracker url-template, url is empty if not configured.
+type IssueLink struct {
+	ID  string `json:"id"`
+	URL string `json:"url,omitempty"`
+}
+
+// IDs returns referenced issue ids.
+func (r IssueReferences) IDs() []string {
+	ids := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		ids[i] = issue.ID
+	}
+	return ids
+}
+
+// Validate checks issue trackers config, keys must be unique and regexes valid, and on-conflict.
+func (cfg CommitMessageIssueConfig) Validate() error {
+	switch cfg.OnConflict {
+	case "", IssueOnConflictKeepExisting, IssueOnConflictPreferBranch, IssueOnConflictError:
+	default:
+		return fmt.Errorf("invalid commit-message.issue.on-conflict: %s, use: %s, %s or %s", cfg.OnConflict, IssueOnConflictKeepExisting, IssueOnConflictPreferBranch, IssueOnConflictError)
+	}
+	keys := make(map[string]bool)
+	for _, tracker := range cfg.Trackers {
+		if tracker.Key == "" {
+			return fmt.Errorf("commit-message.issue.trackers key is required")
+		}
+		if keys[strings.ToLower(tracker.Key)] {
+			return fmt.Errorf("duplicated commit-message.issue.trackers key: %s", tracker.Key)
+		}
+		keys[strings.ToLower(tracker.Key)] = true
+		if _, err := tracker.regex(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MatchIssues returns distinct issue ids matching tracker regex on text, in order of appearance.
+func (t CommitMessageIssueTrackerConfig) MatchIssues(text string) ([]string, error) {
+	r, err := t.regex()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, id := range r.FindAllString(text, -1) {
+		if !contains(id, ids) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// References returns issue links of ids using tracker url-template.
+func (t CommitMessageIssueTrackerConfig) References(ids []string) IssueReferences {
+	refs := IssueReferences{Tracker: t.Key, Issues: make([]IssueLink, len(ids))}
+	for i, id := range ids {
+		refs.Issues[i] = IssueLink{ID: id}
+		if t.URLTemplate != "" {
+			refs.Issues[i].URL = strings.ReplaceAll(t.URLTemplate, URLPlaceholderIssue, strings.TrimPrefix(id, "#"))
+		}
+	}
+	return refs
+}
+
+func (t CommitMessageIssueTrackerConfig) regex() (*regexp.Regexp, error) {
+	if t.Regex == "" {
+		return nil, fmt.Errorf("commit-message.issue.trackers regex is required for %s", t.Key)
+	}
+	r, err := regexp.Compile(t.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex on commit-message.issue.trackers %s: %s, error: %v", t.Key, t.Regex, err)
+	}
+	return r, nil
+}
+
+// footerValues returns values of every footer with tracker key, e.g. "jira: PROJ-1, PROJ-2" or "refs #345", case insensitive.
+func (t CommitMessageIssueTrackerConfig) footerValues(body string) []string {
+	r := regexp.MustCompile(fmt.Sprintf(`(?mi)^%s(?:: | (#))(.+)$`, regexp.QuoteMeta(t.Key)))
+	var values []string
+	for _, match := range r.FindAllStringSubmatch(body, -1) {
+		values = append(values, match[1]+match[2])
+	}
+	return values
+}
+
+// parseIssueReferences returns issues referenced on tracker footers of body, trackers without references are omitted.
+func parseIssueReferences(trackers []CommitMessageIssueTrackerConfig, body string) ([]IssueReferences, error) {
+	var result []IssueReferences
+	for _, tracker := range trackers {
+		values := tracker.footerValues(body)
+		if len(values) == 0 {
+			continue
+		}
+		ids, err := tracker.MatchIssues(strings.Join(values, "\n"))
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			result = append(result, tracker.References(ids))
+		}
+	}
+	return result, nil
+}
+
+func formatIssueReferencesFooter(refs IssueReferences) string {
+	return fmt.Sprintf("%s: %s", refs.Tracker, strings.Join(refs.IDs(), ", "))
+}
+
+func findIssueReferences(refs []IssueReferences, tracker string) (IssueReferences, bool) {
+	for _, r := range refs {
+		if strings.EqualFold(r.Tracker, tracker) {
+			return r, true
+		}
+	}
+	return IssueReferences{}, false
+}
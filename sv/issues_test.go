@@ -0,0 +1,152 @@
+package sv
+
+import (
+	"reflect"
+	"testing"
+)
+
+var ccfgTrackers = CommitMessageConfig{
+	Types: []string{"feat", "fix"},
+	Footer: map[string]CommitMessageFooterConfig{
+		"issue": {Key: "jira"},
+	},
+	Issue: CommitMessageIssueConfig{Regex: "[A-Z]+-[0-9]+", Trackers: []CommitMessageIssueTrackerConfig{
+		{Key: "jira", Regex: "PROJ-[0-9]+", URLTemplate: "https://jira.example.com/browse/{id}", Required: true},
+		{Key: "refs", Regex: "#[0-9]+", URLTemplate: "https://github.com/org/repo/issues/{id}"},
+	}},
+}
+
+func TestCommitMessageIssueConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CommitMessageIssueConfig
+		wantErr bool
+	}{
+		{"without trackers", CommitMessageIssueConfig{Regex: "[A-Z]+-[0-9]+"}, false},
+		{"trackers", ccfgTrackers.Issue, false},
+		{"without key", CommitMessageIssueConfig{Trackers: []CommitMessageIssueTrackerConfig{{Regex: "#[0-9]+"}}}, true},
+		{"without regex", CommitMessageIssueConfig{Trackers: []CommitMessageIssueTrackerConfig{{Key: "refs"}}}, true},
+		{"invalid regex", CommitMessageIssueConfig{Trackers: []CommitMessageIssueTrackerConfig{{Key: "refs", Regex: "#[0-9"}}}, true},
+		{"duplicated key", CommitMessageIssueConfig{Trackers: []CommitMessageIssueTrackerConfig{{Key: "refs", Regex: "#[0-9]+"}, {Key: "Refs", Regex: "[0-9]+"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("CommitMessageIssueConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommitMessageIssueTrackerConfig_MatchIssues(t *testing.T) {
+	tracker := ccfgTrackers.Issue.Trackers[0]
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"feature/PROJ-12-fix", []string{"PROJ-12"}},
+		{"PROJ-12, PROJ-13,PROJ-12", []string{"PROJ-12", "PROJ-13"}},
+		{"main", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := tracker.MatchIssues(tt.text)
+			if err != nil {
+				t.Fatalf("CommitMessageIssueTrackerConfig.MatchIssues() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CommitMessageIssueTrackerConfig.MatchIssues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageProcessorImpl_Parse_IssueTrackers(t *testing.T) {
+	jira := IssueReferences{Tracker: "jira", Issues: []IssueLink{
+		{ID: "PROJ-12", URL: "https://jira.example.com/browse/PROJ-12"},
+		{ID: "PROJ-13", URL: "https://jira.example.com/browse/PROJ-13"},
+	}}
+	github := IssueReferences{Tracker: "refs", Issues: []IssueLink{{ID: "#345", URL: "https://github.com/org/repo/issues/345"}}}
+
+	tests := []struct {
+		name         string
+		body         string
+		wantIssues   []IssueReferences
+		wantMetadata map[string]string
+	}{
+		{"without references", "", nil, map[string]string{}},
+		{"one tracker", "jira: PROJ-12, PROJ-13", []IssueReferences{jira}, map[string]string{"issue": "PROJ-12, PROJ-13", "jira": "PROJ-12, PROJ-13"}},
+		{"several footers", "Refs #345\njira: PROJ-12\nJira: PROJ-13", []IssueReferences{jira, github}, map[string]string{"issue": "PROJ-12", "jira": "PROJ-12, PROJ-13", "refs": "#345"}},
+		{"values not matching regex", "refs: 345", nil, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMessageProcessor(ccfgTrackers, newBranchCfg(false)).Parse("feat: something", tt.body)
+			if err != nil {
+				t.Fatalf("MessageProcessorImpl.Parse() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Issues, tt.wantIssues) {
+				t.Errorf("MessageProcessorImpl.Parse() issues = %+v, want %+v", got.Issues, tt.wantIssues)
+			}
+			if !reflect.DeepEqual(got.Metadata, tt.wantMetadata) {
+				t.Errorf("MessageProcessorImpl.Parse() metadata = %v, want %v", got.Metadata, tt.wantMetadata)
+			}
+		})
+	}
+}
+
+func TestMessageProcessorImpl_Violations_IssueTrackers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    int
+	}{
+		{"required tracker missing", "feat: something\n\nrefs: #345", 1},
+		{"one reference", "feat: something\n\njira: PROJ-12", 0},
+		{"several references", "feat: something\n\njira: PROJ-12, PROJ-13\nrefs: #345, #346", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewMessageProcessor(ccfgTrackers, newBranchCfg(false)).Violations(tt.message); len(got) != tt.want {
+				t.Errorf("MessageProcessorImpl.Violations() = %v, want %d violation(s)", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageProcessorImpl_Enhance_IssueTrackers(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		message string
+		want    string
+		wantErr bool
+	}{
+		{"issues on branch", "feature/PROJ-12-PROJ-13-fix", "fix: something", "fix: something\n\njira: PROJ-12, PROJ-13", false},
+		{"footer already on message", "feature/PROJ-12", "fix: something\n\njira: PROJ-99", "", false},
+		{"required tracker not found", "main", "fix: something", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMessageProcessor(ccfgTrackers, newBranchCfg(false)).Enhance(tt.branch, tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MessageProcessorImpl.Enhance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MessageProcessorImpl.Enhance() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageProcessorImpl_Format_IssueTrackers(t *testing.T) {
+	msg := NewCommitMessage("feat", "", "something", "", "", "breaks")
+	msg.Issues = []IssueReferences{
+		ccfgTrackers.Issue.Trackers[0].References([]string{"PROJ-12", "PROJ-13"}),
+		ccfgTrackers.Issue.Trackers[1].References([]string{"#345"}),
+	}
+	_, _, footer := NewMessageProcessor(ccfgTrackers, newBranchCfg(false)).Format(msg)
+	if want := "BREAKING CHANGE: breaks\njira: PROJ-12, PROJ-13\nrefs: #345"; footer != want {
+		t.Errorf("MessageProcessorImpl.Format() footer = %q, want %q", footer, want)
+	}
+}
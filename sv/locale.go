@@ -0,0 +1,168 @@
+package sv
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// locale month and day names and date layouts of a release-notes.locale language.
+type locale struct {
+	months      [12]string
+	shortMonths [12]string
+	days        [7]string // starting on Sunday, as time.Weekday
+	shortDays   [7]string
+	short       string // numeric date layout, default of the locale
+	long        string // date layout with month name
+}
+
+var locales = map[string]locale{
+	"en": {
+		months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		short:       "1/2/2006",
+		long:        "January 2, 2006",
+	},
+	"de": {
+		months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortDays:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		short:       "2.1.2006",
+		long:        "2. January 2006",
+	},
+	"es": {
+		months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		short:       "2/1/2006",
+		long:        "2 de January de 2006",
+	},
+	"fr": {
+		months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortDays:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		short:       "02/01/2006",
+		long:        "2 January 2006",
+	},
+	"it": {
+		months:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		shortMonths: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		days:        [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+		short:       "2/1/2006",
+		long:        "2 January 2006",
+	},
+	"nl": {
+		months:      [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		shortMonths: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		days:        [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		shortDays:   [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+		short:       "2-1-2006",
+		long:        "2 January 2006",
+	},
+	"pt": {
+		months:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		shortMonths: [12]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+		days:        [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		shortDays:   [7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+		short:       "02/01/2006",
+		long:        "2 de January de 2006",
+	},
+}
+
+// findLocale returns the locale of a BCP 47 language tag, e.g. de, de-DE or de_AT, regions and scripts use the locale of their language.
+func findLocale(tag string) (locale, error) {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return locale{}, fmt.Errorf("invalid release-notes.locale: %s, use a language tag, e.g. de or de-DE, message: %v", tag, err)
+	}
+	base, confidence := t.Base()
+	if confidence != language.Exact {
+		return locale{}, fmt.Errorf("invalid release-notes.locale: %s, language is missing, use a language tag, e.g. de or de-DE", tag)
+	}
+	l, found := locales[base.String()]
+	if !found {
+		return locale{}, fmt.Errorf("unsupported release-notes.locale: %s, use one of: %s", tag, strings.Join(supportedLocales(), ", "))
+	}
+	return l, nil
+}
+
+func supportedLocales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// localeNameTokens go layout tokens replaced by locale names, longest first.
+var localeNameTokens = regexp.MustCompile(`January|Jan|Monday|Mon`)
+
+// format formats t with a go time layout, month and day names are written in the locale language.
+func (l locale) format(t time.Time, layout string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range localeNameTokens.FindAllStringIndex(layout, -1) {
+		sb.WriteString(t.Format(layout[last:loc[0]]))
+		switch layout[loc[0]:loc[1]] {
+		case "January":
+			sb.WriteString(l.months[t.Month()-1])
+		case "Jan":
+			sb.WriteString(l.shortMonths[t.Month()-1])
+		case "Monday":
+			sb.WriteString(l.days[t.Weekday()])
+		case "Mon":
+			sb.WriteString(l.shortDays[t.Weekday()])
+		}
+		last = loc[1]
+	}
+	sb.WriteString(t.Format(layout[last:]))
+	return sb.String()
+}
+
+// release-notes.strings keys, phrases written by default templates.
+const (
+	StringChangelog    = "changelog"    // changelog title
+	StringSummary      = "summary"      // summary line, {changes} and {contributors} are replaced by counts and their words
+	StringChange       = "change"       // word of {changes} for a single change
+	StringChanges      = "changes"      // word of {changes} for zero or several changes
+	StringContributor  = "contributor"  // word of {contributors} for a single contributor
+	StringContributors = "contributors" // word of {contributors} for zero or several contributors
+	StringSharedWith   = "shared-with"  // before other components changed by a monorepo commit
+	StringAlsoIn       = "also-in"      // before the release including a cherry-picked commit
+)
+
+var defaultStrings = map[string]string{
+	StringChangelog:    "Changelog",
+	StringSummary:      "{changes} by {contributors}",
+	StringChange:       "change",
+	StringChanges:      "changes",
+	StringContributor:  "contributor",
+	StringContributors: "contributors",
+	StringSharedWith:   "shared with:",
+	StringAlsoIn:       "also in",
+}
+
+func validateStrings(values map[string]string) error {
+	for key := range values {
+		if _, found := defaultStrings[key]; !found {
+			keys := make([]string, 0, len(defaultStrings))
+			for k := range defaultStrings {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return fmt.Errorf("unknown release-notes.strings key: %s, use: %s", key, strings.Join(keys, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package sv
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// CommitGraph in-memory commit history loaded with Git.LogAll, used to slice log ranges without executing git.
+type CommitGraph struct {
+	commits []GitCommitLog
+	hashes  map[string]int
+	tags    map[string]int
+}
+
+// NewCommitGraph CommitGraph constructor, commits must be in git log order starting from HEAD.
+func NewCommitGraph(commits []GitCommitLog) CommitGraph {
+	g := CommitGraph{commits: commits, hashes: make(map[string]int, len(commits)), tags: make(map[string]int)}
+	for i, commit := range commits {
+		g.hashes[commit.Hash] = i
+		for _, tag := range commit.Tags {
+			g.tags[tag] = i
+		}
+	}
+	return g
+}
+
+// Range returns commits reachable from end but not from start that changed any of the paths, same as `git log start..end -- paths`.
+// Empty start includes the complete history and empty end means HEAD.
+// Returns false if start or end are not tags of the graph.
+func (g CommitGraph) Range(start, end string, paths []string) ([]GitCommitLog, bool) {
+	if len(g.commits) == 0 {
+		return nil, start == "" && end == ""
+	}
+
+	endIndex := 0
+	if end != "" {
+		index, found := g.tags[end]
+		if !found {
+			return nil, false
+		}
+		endIndex = index
+	}
+
+	var excluded map[int]bool
+	if start != "" {
+		index, found := g.tags[start]
+		if !found {
+			return nil, false
+		}
+		excluded = g.reachable(index)
+	}
+
+	included := g.reachable(endIndex)
+	var result []GitCommitLog
+	for i, commit := range g.commits {
+		if included[i] && !excluded[i] && changedPaths(commit.Files, paths) {
+			result = append(result, commit)
+		}
+	}
+	return result, true
+}
+
+func (g CommitGraph) reachable(from int) map[int]bool {
+	visited := map[int]bool{from: true}
+	pending := []int{from}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, parent := range g.commits[current].Parents {
+			if index, found := g.hashes[parent]; found && !visited[index] {
+				visited[index] = true
+				pending = append(pending, index)
+			}
+		}
+	}
+	return visited
+}
+
+// changedPaths checks if any file is equal or inside one of the paths, empty paths match every commit.
+func changedPaths(files, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, path := range paths {
+		path = filepath.ToSlash(filepath.Clean(path))
+		for _, file := range files {
+			if path == "." || file == path || strings.HasPrefix(file, path+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CachedLogGit Git decorator that loads the history once with LogAll and answers tag range logs from memory.
+// Merge commits are filtered with the same log config used by Git.Log.
+// Commands that create commits or tags discard the loaded history.
+type CachedLogGit struct {
+	Git
+	cfg   LogConfig
+	graph *CommitGraph
+}
+
+// NewCachedLogGit CachedLogGit constructor.
+func NewCachedLogGit(git Git, cfg LogConfig) *CachedLogGit {
+	return &CachedLogGit{Git: git, cfg: cfg}
+}
+
+// Log returns tag range logs from the cached history, other ranges are delegated to git.
+func (g *CachedLogGit) Log(lr LogRange) ([]GitCommitLog, error) {
+	if lr.rangeType != TagRange {
+		return g.Git.Log(lr)
+	}
+
+	if g.graph == nil {
+		commits, err := g.Git.LogAll(nil)
+		if err != nil {
+			return nil, err
+		}
+		graph := NewCommitGraph(commits)
+		g.graph = &graph
+	}
+
+	if commits, ok := g.graph.Range(lr.start, lr.end, lr.paths); ok {
+		return filterCommits(g.cfg, commits), nil
+	}
+	return g.Git.Log(lr)
+}
+
+// Commit runs git commit and discards the cached history.
+func (g *CachedLogGit) Commit(header, body, footer string, paths ...string) error {
+	g.graph = nil
+	return g.Git.Commit(header, body, footer, paths...)
+}
+
+// Tag creates a git tag and discards the cached history.
+func (g *CachedLogGit) Tag(version semver.Version, remote string) (string, error) {
+	g.graph = nil
+	return g.Git.Tag(version, remote)
+}
+
+// TagAt creates a git tag on ref and discards the cached history.
+func (g *CachedLogGit) TagAt(version semver.Version, ref, remote string) (string, error) {
+	g.graph = nil
+	return g.Git.TagAt(version, ref, remote)
+}
+
+// TagForComponent creates a component tag and discards the cached history.
+func (g *CachedLogGit) TagForComponent(version semver.Version, componentPath, remote string) (string, error) {
+	g.graph = nil
+	return g.Git.TagForComponent(version, componentPath, remote)
+}
+
+// Fetch fetches tags from remote and discards the cached history.
+func (g *CachedLogGit) Fetch(tagsOnly bool) error {
+	g.graph = nil
+	return g.Git.Fetch(tagsOnly)
+}
+
+// Unshallow fetches the complete history and discards the cached history.
+func (g *CachedLogGit) Unshallow() error {
+	g.graph = nil
+	return g.Git.Unshallow()
+}
@@ -0,0 +1,145 @@
+package sv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// history (newest first):
+//
+//	m  merge of f1 into c2, tag v2.0.0
+//	f1 feature commit on b/
+//	c2 commit on a/
+//	c1 commit on a/ and b/, tag v1.0.0
+//	c0 root commit
+func graphCommits() []GitCommitLog {
+	return []GitCommitLog{
+		{Hash: "m", Parents: []string{"c2", "f1"}, Tags: []string{"v2.0.0"}},
+		{Hash: "f1", Parents: []string{"c1"}, Files: []string{"b/feature.go"}},
+		{Hash: "c2", Parents: []string{"c1"}, Files: []string{"a/file.go"}},
+		{Hash: "c1", Parents: []string{"c0"}, Tags: []string{"v1.0.0"}, Files: []string{"a/file.go", "b/file.go"}},
+		{Hash: "c0", Files: []string{"README.md"}},
+	}
+}
+
+func hashes(commits []GitCommitLog) []string {
+	var result []string
+	for _, commit := range commits {
+		result = append(result, commit.Hash)
+	}
+	return result
+}
+
+func TestCommitGraph_Range(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  string
+		end    string
+		paths  []string
+		want   []string
+		wantOk bool
+	}{
+		{"complete history", "", "", nil, []string{"m", "f1", "c2", "c1", "c0"}, true},
+		{"from tag to head", "v1.0.0", "", nil, []string{"m", "f1", "c2"}, true},
+		{"between tags", "v1.0.0", "v2.0.0", nil, []string{"m", "f1", "c2"}, true},
+		{"until tag", "", "v1.0.0", nil, []string{"c1", "c0"}, true},
+		{"path filter", "v1.0.0", "", []string{"b"}, []string{"f1"}, true},
+		{"path filter complete history", "", "", []string{"a/"}, []string{"c2", "c1"}, true},
+		{"root path", "v1.0.0", "", []string{"."}, []string{"f1", "c2"}, true},
+		{"path prefix is not a directory match", "", "", []string{"a/file"}, nil, true},
+		{"unknown start tag", "v0.1.0", "", nil, nil, false},
+		{"unknown end tag", "", "v3.0.0", nil, nil, false},
+	}
+	graph := NewCommitGraph(graphCommits())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := graph.Range(tt.start, tt.end, tt.paths)
+			if ok != tt.wantOk {
+				t.Fatalf("Range() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !reflect.DeepEqual(hashes(got), tt.want) {
+				t.Errorf("Range() = %v, want %v", hashes(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitGraph_RangeEmpty(t *testing.T) {
+	graph := NewCommitGraph(nil)
+	if got, ok := graph.Range("", "", nil); !ok || got != nil {
+		t.Errorf("Range() = (%v, %v), want (nil, true)", got, ok)
+	}
+	if _, ok := graph.Range("v1.0.0", "", nil); ok {
+		t.Error("Range() ok = true for unknown tag on empty graph, want false")
+	}
+}
+
+// countingGit counts log executions, unimplemented methods panic.
+type countingGit struct {
+	Git
+	logCalls    int
+	logAllCalls int
+}
+
+func (g *countingGit) Log(lr LogRange) ([]GitCommitLog, error) {
+	g.logCalls++
+	return nil, nil
+}
+
+func (g *countingGit) LogAll(paths []string) ([]GitCommitLog, error) {
+	g.logAllCalls++
+	return graphCommits(), nil
+}
+
+func (g *countingGit) Commit(header, body, footer string, paths ...string) error { return nil }
+
+func (g *countingGit) TagForComponent(version semver.Version, componentPath, remote string) (string, error) {
+	return componentPath + "/v" + version.String(), nil
+}
+
+func TestCachedLogGit_Log(t *testing.T) {
+	git := &countingGit{}
+	cached := NewCachedLogGit(git, LogConfig{})
+
+	for _, path := range []string{"a", "b", "a", "b"} {
+		if _, err := cached.Log(NewLogRangeWithPaths(TagRange, "v1.0.0", "", []string{path})); err != nil {
+			t.Fatalf("Log() unexpected error: %v", err)
+		}
+	}
+	if git.logAllCalls != 1 || git.logCalls != 0 {
+		t.Errorf("after cached logs: LogAll calls = %d, Log calls = %d, want 1 and 0", git.logAllCalls, git.logCalls)
+	}
+
+	got, _ := cached.Log(NewLogRangeWithPaths(TagRange, "v1.0.0", "", []string{"b"}))
+	if !reflect.DeepEqual(hashes(got), []string{"f1"}) {
+		t.Errorf("Log() = %v, want [f1]", hashes(got))
+	}
+
+	if _, err := cached.Log(NewLogRange(TagRange, "unknown", "")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cached.Log(NewLogRange(DateRange, "2022-01-01", "")); err != nil {
+		t.Fatal(err)
+	}
+	if git.logCalls != 2 {
+		t.Errorf("Log calls = %d, want 2 for unknown tag and date range", git.logCalls)
+	}
+
+	if err := cached.Commit("chore: bump", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cached.Log(NewLogRange(TagRange, "", "")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cached.TagForComponent(*semver.MustParse("1.0.0"), "a", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cached.Log(NewLogRange(TagRange, "", "")); err != nil {
+		t.Fatal(err)
+	}
+	if git.logAllCalls != 3 {
+		t.Errorf("LogAll calls = %d, want 3, history must be reloaded after commit and tag", git.logAllCalls)
+	}
+}
@@ -0,0 +1,79 @@
+package sv
+
+import (
+	"regexp"
+	"strings"
+)
+
+// prNumberRegexes extract pull request numbers from GitHub and GitLab merge commits and squash merge subjects.
+var prNumberRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^Merge pull request #(\d+)`),
+	regexp.MustCompile(`See merge request \S*!(\d+)`),
+	regexp.MustCompile(`\(#(\d+)\)\s*$`),
+}
+
+// IsMerge checks if commit has more than one parent, parents are only available on commits loaded by Git.Log and Git.LogAll.
+func (c GitCommitLog) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// includeCommit checks if commit is kept by log.include-merges policy, non merge commits are always included.
+func includeCommit(cfg LogConfig, commit GitCommitLog) bool {
+	if !commit.IsMerge() {
+		return true
+	}
+	switch cfg.IncludeMerges {
+	case IncludeMergesAlways:
+		return true
+	case IncludeMergesOnlyConventional:
+		return commit.Message.Type != ""
+	default:
+		return false
+	}
+}
+
+// filterCommits removes commits excluded by log.include-merges policy.
+func filterCommits(cfg LogConfig, commits []GitCommitLog) []GitCommitLog {
+	var result []GitCommitLog
+	for _, commit := range commits {
+		if includeCommit(cfg, commit) {
+			result = append(result, commit)
+		}
+	}
+	return result
+}
+
+// parseMergeMessage parses merge commits messages, if subject is not a conventional commit the pull request title
+// from the first body line is used instead, e.g. "Merge pull request #123 from org/branch" with body "feat: title".
+// The pull request number is added to metadata with key pr.
+func parseMergeMessage(messageProcessor MessageProcessor, subject, body string) CommitMessage {
+	message, err := messageProcessor.Parse(subject, body)
+	if err != nil || message.Type == "" {
+		title, rest, _ := strings.Cut(strings.TrimSpace(body), "\n")
+		if bodyMessage, berr := messageProcessor.Parse(strings.TrimSpace(title), strings.TrimSpace(rest)); berr == nil && bodyMessage.Type != "" {
+			message, err = bodyMessage, nil
+		}
+	}
+	if err != nil {
+		message = CommitMessage{Description: subject, Body: body, Metadata: map[string]string{}}
+	}
+
+	if pr := pullRequestNumber(subject, body); pr != "" {
+		if message.Metadata == nil {
+			message.Metadata = map[string]string{}
+		}
+		message.Metadata[prMetadataKey] = pr
+	}
+	return message
+}
+
+func pullRequestNumber(subject, body string) string {
+	for _, text := range []string{subject, body} {
+		for _, regex := range prNumberRegexes {
+			if match := regex.FindStringSubmatch(text); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return ""
+}
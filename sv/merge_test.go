@@ -0,0 +1,62 @@
+package sv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseMergeMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		body    string
+		want    CommitMessage
+	}{
+		{"github pull request", "Merge pull request #123 from org/feature", "feat(api): add endpoint\n\nmore details", CommitMessage{Type: "feat", Scope: "api", Description: "add endpoint", Body: "more details", Metadata: map[string]string{"pr": "123"}}},
+		{"gitlab merge request", "Merge branch 'feature' into 'main'", "fix: handle empty values\n\nSee merge request group/project!45", CommitMessage{Type: "fix", Description: "handle empty values", Body: "See merge request group/project!45", Metadata: map[string]string{"pr": "45"}}},
+		{"conventional subject", "feat: merge feature (#7)", "", CommitMessage{Type: "feat", Description: "merge feature (#7)", Metadata: map[string]string{"pr": "7"}}},
+		{"non conventional", "Merge branch 'other'", "", CommitMessage{Description: "Merge branch 'other'", Metadata: map[string]string{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewMessageProcessor(CommitMessageConfig{}, BranchesConfig{})
+			if got := parseMergeMessage(p, tt.subject, tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMergeMessage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseMergeMessage_InvalidHeaderSelector(t *testing.T) {
+	p := NewMessageProcessor(CommitMessageConfig{HeaderSelector: `^\[(?P<header>.*)\]`}, BranchesConfig{})
+	got := parseMergeMessage(p, "Merge pull request #9 from org/feature", "")
+	want := CommitMessage{Description: "Merge pull request #9 from org/feature", Metadata: map[string]string{"pr": "9"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMergeMessage() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_includeCommit(t *testing.T) {
+	commit := GitCommitLog{Message: CommitMessage{Type: "feat"}}
+	merge := GitCommitLog{Parents: []string{"a", "b"}, Message: CommitMessage{Description: "Merge branch 'x'"}}
+	conventionalMerge := GitCommitLog{Parents: []string{"a", "b"}, Message: CommitMessage{Type: "feat"}}
+
+	tests := []struct {
+		policy string
+		want   []bool
+	}{
+		{"", []bool{true, false, false}},
+		{IncludeMergesNever, []bool{true, false, false}},
+		{IncludeMergesAlways, []bool{true, true, true}},
+		{IncludeMergesOnlyConventional, []bool{true, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(str(tt.policy, "default"), func(t *testing.T) {
+			cfg := LogConfig{IncludeMerges: tt.policy}
+			got := []bool{includeCommit(cfg, commit), includeCommit(cfg, merge), includeCommit(cfg, conventionalMerge)}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("includeCommit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
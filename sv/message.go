@@ -4,24 +4,34 @@ import (
 	"bufio"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"text/template"
 )
 
 const (
 	breakingChangeFooterKey   = "BREAKING CHANGE"
 	breakingChangeMetadataKey = "breaking-change"
 	issueMetadataKey          = "issue"
+	prMetadataKey             = "pr"
+	prURLMetadataKey          = "pr-url"
+	sharedWithMetadataKey     = "shared-with"
 	messageRegexGroupName     = "header"
+	signOffTrailer            = "Signed-off-by: "
 )
 
 // CommitMessage is a message using conventional commits.
 type CommitMessage struct {
-	Type             string            `json:"type,omitempty"`
-	Scope            string            `json:"scope,omitempty"`
-	Description      string            `json:"description,omitempty"`
-	Body             string            `json:"body,omitempty"`
-	IsBreakingChange bool              `json:"isBreakingChange,omitempty"`
-	Metadata         map[string]string `json:"metadata,omitempty"`
+	Type             string `json:"type,omitempty"`
+	Scope            string `json:"scope,omitempty"`
+	Description      string `json:"description,omitempty"`
+	Body             string `json:"body,omitempty"`
+	IsBreakingChange bool   `json:"isBreakingChange,omitempty"`
+	// Metadata footer values by key. Footers of commit-message.footer use the config key, e.g. issue, the breaking change
+	// text uses breaking-change and issue trackers their key. Other trailers, e.g. Co-authored-by, keep their literal key,
+	// values of a key used more than once are joined with ", " and folded values are unfolded.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Issues   []IssueReferences `json:"issues,omitempty"` // issues of commit-message.issue.trackers, in config order
 }
 
 // NewCommitMessage commit message constructor.
@@ -50,6 +60,7 @@ func (m CommitMessage) BreakingMessage() string {
 type MessageProcessor interface {
 	SkipBranch(branch string, detached bool) bool
 	Validate(message string) error
+	Violations(message string) []error
 	ValidateType(ctype string) error
 	ValidateScope(scope string) error
 	ValidateDescription(description string) error
@@ -80,30 +91,88 @@ func (p MessageProcessorImpl) SkipBranch(branch string, detached bool) bool {
 
 // Validate commit message.
 func (p MessageProcessorImpl) Validate(message string) error {
+	if violations := p.Violations(message); len(violations) > 0 {
+		return violations[0]
+	}
+	return nil
+}
+
+// Violations returns every rule violated by message, messages that cannot be parsed return only the parse error.
+func (p MessageProcessorImpl) Violations(message string) []error {
 	subject, body := splitCommitMessageContent(message)
 	msg, parseErr := p.Parse(subject, body)
 
 	if parseErr != nil {
-		return parseErr
+		return []error{parseErr}
 	}
 
-	if !regexp.MustCompile(`^[a-z+]+(\(.+\))?!?: .+$`).MatchString(subject) {
-		return fmt.Errorf("subject [%s] should be valid according with conventional commits", subject)
+	var violations []error
+	if header, _ := p.parseHeader(subject); !header.selected && !headerFormatRegex.MatchString(subject) {
+		violations = append(violations, fmt.Errorf("subject [%s] should be valid according with conventional commits", subject))
 	}
 
 	if err := p.ValidateType(msg.Type); err != nil {
-		return err
+		violations = append(violations, err)
 	}
 
 	if err := p.ValidateScope(msg.Scope); err != nil {
-		return err
+		violations = append(violations, err)
 	}
 
 	if err := p.ValidateDescription(msg.Description); err != nil {
-		return err
+		violations = append(violations, err)
 	}
 
-	return nil
+	for _, key := range p.messageCfg.RequiredFooters {
+		if !hasFooterKey(message, key) {
+			violations = append(violations, fmt.Errorf("message should have footer %s", key))
+		}
+	}
+
+	violations = append(violations, p.duplicatedFooterViolations(body)...)
+
+	for _, tracker := range p.messageCfg.Issue.Trackers {
+		if _, found := findIssueReferences(msg.Issues, tracker.Key); tracker.Required && !found {
+			violations = append(violations, fmt.Errorf("message should reference at least one %s issue matching %s on footer %s", tracker.Key, tracker.Regex, tracker.Key))
+		}
+	}
+
+	violations = append(violations, BannedWordViolations(p.messageCfg.BannedWords, message)...)
+
+	return violations
+}
+
+// duplicatedFooterViolations returns a violation for each commit-message.footer, synonyms included, and issue tracker with
+// more than one footer on body, e.g. an issue typed on the message and another one added from the branch.
+func (p MessageProcessorImpl) duplicatedFooterViolations(body string) []error {
+	var keys [][]string
+	names := make([]string, 0, len(p.messageCfg.Footer))
+	for name := range p.messageCfg.Footer {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cfg := p.messageCfg.Footer[name]; cfg.Key != "" {
+			keys = append(keys, append([]string{cfg.Key}, cfg.KeySynonyms...))
+		}
+	}
+	for _, tracker := range p.messageCfg.Issue.Trackers {
+		keys = append(keys, []string{tracker.Key})
+	}
+
+	var violations []error
+	for _, k := range keys {
+		footers := findFooters(body, k)
+		if len(footers) < 2 {
+			continue
+		}
+		lines := make([]string, len(footers))
+		for i, footer := range footers {
+			lines[i] = footer.text
+		}
+		violations = append(violations, fmt.Errorf("message should have a single %s footer, found %d: %s", k[0], len(footers), strings.Join(lines, "; ")))
+	}
+	return violations
 }
 
 // ValidateType check if commit type is valid.
@@ -116,6 +185,9 @@ func (p MessageProcessorImpl) ValidateType(ctype string) error {
 
 // ValidateScope check if commit scope is valid.
 func (p MessageProcessorImpl) ValidateScope(scope string) error {
+	if p.messageCfg.Scope.Required && scope == "" {
+		return fmt.Errorf("message scope is required")
+	}
 	if len(p.messageCfg.Scope.Values) > 0 && !contains(scope, p.messageCfg.Scope.Values) {
 		return fmt.Errorf("message scope should one of [%v]", strings.Join(p.messageCfg.Scope.Values, ", "))
 	}
@@ -130,26 +202,198 @@ func (p MessageProcessorImpl) ValidateDescription(description string) error {
 	return nil
 }
 
-// Enhance add metadata on commit message.
+// Enhance adds issue footer and commit-message.enhance footers on commit message, returns the enhanced message or empty if no footer was added.
+// Footers already in message are not added again, new footers are inserted before sign-off trailers.
+// If issue id is not found on branch, other footers are still added and the error is returned with the enhanced message.
 func (p MessageProcessorImpl) Enhance(branch string, message string) (string, error) {
-	if p.branchesCfg.DisableIssue || p.messageCfg.IssueFooterConfig().Key == "" || hasIssueID(message, p.messageCfg.IssueFooterConfig()) {
-		return "", nil // enhance disabled
+	var footers []string
+	var issueErr error
+
+	original := message
+	issue, message, ierr := p.enhanceIssue(branch, message)
+	switch {
+	case ierr != nil:
+		issueErr = ierr
+	case issue != "":
+		footers = append(footers, formatIssueFooter(p.messageCfg.IssueFooterConfig(), issue))
+	}
+
+	trackerFooters, terr := p.enhanceIssueTrackers(branch, message)
+	if terr != nil && issueErr == nil {
+		issueErr = terr
+	}
+	footers = append(footers, trackerFooters...)
+
+	if len(p.messageCfg.Enhance) > 0 {
+		subject, body := splitCommitMessageContent(message)
+		msg, _ := p.Parse(subject, body)
+		if issue == "" {
+			issue = msg.Issue()
+		}
+		data := EnhanceFooterData{Branch: branch, Issue: issue, Type: msg.Type, Scope: msg.Scope}
+		for _, cfg := range p.messageCfg.Enhance {
+			footer, err := enhanceFooter(cfg, data, message)
+			if err != nil {
+				return "", err
+			}
+			if footer != "" {
+				footers = append(footers, footer)
+			}
+		}
+	}
+
+	switch {
+	case len(footers) > 0:
+		return insertFooters(message, footers), issueErr
+	case message != original:
+		return message, issueErr
+	}
+	return "", issueErr
+}
+
+// IssueConflictError issue footer of a message with another issue than the branch, see CommitMessageIssueConfig.OnConflict.
+type IssueConflictError struct {
+	Footer     string // existing footer line, e.g. "jira: JIRA-456"
+	Issue      string // issue of the branch
+	OnConflict string // how the conflict was resolved
+}
+
+func (e IssueConflictError) Error() string {
+	msg := fmt.Sprintf("message footer %q references another issue than branch issue %s", e.Footer, e.Issue)
+	switch e.OnConflict {
+	case IssueOnConflictPreferBranch:
+		return msg + ", footer replaced with the branch issue"
+	case IssueOnConflictError:
+		return msg + ", fix the footer or set commit-message.issue.on-conflict to " + IssueOnConflictKeepExisting + " or " + IssueOnConflictPreferBranch
+	default:
+		return msg + ", keeping the message footer"
+	}
+}
+
+// enhanceIssue returns the issue id from branch, empty if issue enhance is disabled, issue trackers are configured or message
+// already has an issue footer, and message. A footer, with key or one of its synonyms, referencing another issue than the branch
+// returns an IssueConflictError, the footer is replaced on message with commit-message.issue.on-conflict prefer-branch.
+func (p MessageProcessorImpl) enhanceIssue(branch, message string) (string, string, error) {
+	footerCfg := p.messageCfg.IssueFooterConfig()
+	if p.branchesCfg.DisableIssue || len(p.messageCfg.Issue.Trackers) > 0 || footerCfg.Key == "" {
+		return "", message, nil // enhance disabled
 	}
 
 	issue, err := p.IssueID(branch)
 	if err != nil {
-		return "", err
+		return "", message, err
+	}
+	if existing := findFooters(message, append([]string{footerCfg.Key}, footerCfg.KeySynonyms...)); len(existing) > 0 {
+		if issue == "" || referencesIssue(existing, issue) {
+			return "", message, nil
+		}
+		conflict := IssueConflictError{Footer: existing[0].text, Issue: issue, OnConflict: str(p.messageCfg.Issue.OnConflict, IssueOnConflictKeepExisting)}
+		if conflict.OnConflict == IssueOnConflictPreferBranch {
+			message = replaceFooters(message, existing, formatIssueFooter(footerCfg, issue))
+		}
+		return "", message, conflict
 	}
 	if issue == "" {
-		return "", fmt.Errorf("could not find issue id using configured regex")
+		return "", message, fmt.Errorf("could not find issue id using configured regex")
 	}
+	return issue, message, nil
+}
+
+// enhanceIssueTrackers returns a footer for each issue tracker without footer on message with the issue ids found on branch.
+// Returns an error if a required tracker has no issue on message or branch.
+func (p MessageProcessorImpl) enhanceIssueTrackers(branch, message string) ([]string, error) {
+	if p.branchesCfg.DisableIssue {
+		return nil, nil
+	}
+	var footers []string
+	var missing []string
+	for _, tracker := range p.messageCfg.Issue.Trackers {
+		if hasFooterKey(message, tracker.Key) {
+			continue
+		}
+		ids, err := tracker.MatchIssues(branch)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			footers = append(footers, formatIssueReferencesFooter(tracker.References(ids)))
+		} else if tracker.Required {
+			missing = append(missing, tracker.Key)
+		}
+	}
+	if len(missing) > 0 {
+		return footers, fmt.Errorf("could not find %s issue id on branch using configured regex", strings.Join(missing, ", "))
+	}
+	return footers, nil
+}
+
+// EnhanceFooterData values available on commit-message.enhance value templates.
+type EnhanceFooterData struct {
+	Branch string
+	Issue  string
+	Type   string
+	Scope  string
+}
 
-	footer := formatIssueFooter(p.messageCfg.IssueFooterConfig(), issue)
-	if !hasFooter(message) {
-		return "\n" + footer, nil
+// enhanceFooter renders footer from config, returns empty if value is empty or message already has a footer with the same key.
+func enhanceFooter(cfg CommitMessageEnhanceConfig, data EnhanceFooterData, message string) (string, error) {
+	if hasFooterKey(message, cfg.Key) {
+		return "", nil
+	}
+	tpl, err := template.New(cfg.Key).Parse(cfg.ValueTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit-message.enhance template for %s, message: %v", cfg.Key, err)
+	}
+	var value strings.Builder
+	if err := tpl.Execute(&value, data); err != nil {
+		return "", fmt.Errorf("could not execute commit-message.enhance template for %s, message: %v", cfg.Key, err)
+	}
+	if strings.TrimSpace(value.String()) == "" {
+		return "", nil
 	}
+	return fmt.Sprintf("%s: %s", cfg.Key, strings.TrimSpace(value.String())), nil
+}
 
-	return footer, nil
+// insertFooters adds footers after the last footer of message and before sign-off trailers, trailing comments and blank lines are kept at the end.
+// ValidationWarningsFooterKey footer with the number of violations of a commit message accepted by validation-mode warn.
+const ValidationWarningsFooterKey = "Validation-Warnings"
+
+// WithValidationWarnings sets the Validation-Warnings footer of message to count, before Signed-off-by trailers, so
+// adoption of commit conventions can be measured, e.g. with git log --grep.
+func WithValidationWarnings(message string, count int) string {
+	footer := fmt.Sprintf("%s: %d", ValidationWarningsFooterKey, count)
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ValidationWarningsFooterKey+": ") {
+			lines[i] = footer
+			return strings.Join(lines, "\n")
+		}
+	}
+	return insertFooters(message, []string{footer})
+}
+
+func insertFooters(message string, footers []string) string {
+	lines := strings.Split(message, "\n")
+
+	end := len(lines)
+	for end > 1 && (strings.TrimSpace(lines[end-1]) == "" || strings.HasPrefix(lines[end-1], "#")) {
+		end--
+	}
+	insertAt := end
+	for insertAt > 1 && strings.HasPrefix(lines[insertAt-1], signOffTrailer) {
+		insertAt--
+	}
+
+	block := footers
+	if previous := lines[insertAt-1]; insertAt == 1 || (strings.TrimSpace(previous) != "" && !footerLineRegex.MatchString(previous)) {
+		block = append([]string{""}, footers...)
+	}
+
+	result := make([]string, 0, len(lines)+len(block))
+	result = append(result, lines[:insertAt]...)
+	result = append(result, block...)
+	result = append(result, lines[insertAt:]...)
+	return strings.Join(result, "\n")
 }
 
 func formatIssueFooter(cfg CommitMessageFooterConfig, issue string) string {
@@ -181,18 +425,24 @@ func (p MessageProcessorImpl) IssueID(branch string) (string, error) {
 	return groups[2], nil
 }
 
-// Format a commit message returning header, body and footer.
+// Format a commit message returning header, body and footer. Breaking changes are written as commit-message.breaking.style,
+// a ! is always used for breaking changes without message.
 func (p MessageProcessorImpl) Format(msg CommitMessage) (string, string, string) {
+	style := p.messageCfg.Breaking.Style
+	breakingFooter := msg.BreakingMessage() != "" && style != BreakingStyleExclamation
 	var header strings.Builder
 	header.WriteString(msg.Type)
 	if msg.Scope != "" {
 		header.WriteString("(" + msg.Scope + ")")
 	}
+	if msg.IsBreakingChange && (style == BreakingStyleExclamation || style == BreakingStyleBoth || !breakingFooter) {
+		header.WriteString("!")
+	}
 	header.WriteString(": ")
 	header.WriteString(msg.Description)
 
 	var footer strings.Builder
-	if msg.BreakingMessage() != "" {
+	if breakingFooter {
 		footer.WriteString(fmt.Sprintf("%s: %s", breakingChangeFooterKey, msg.BreakingMessage()))
 	}
 	if issue, exists := msg.Metadata[issueMetadataKey]; exists && p.messageCfg.IssueFooterConfig().Key != "" {
@@ -201,6 +451,12 @@ func (p MessageProcessorImpl) Format(msg CommitMessage) (string, string, string)
 		}
 		footer.WriteString(formatIssueFooter(p.messageCfg.IssueFooterConfig(), issue))
 	}
+	for _, refs := range msg.Issues {
+		if footer.Len() > 0 {
+			footer.WriteString("\n")
+		}
+		footer.WriteString(formatIssueReferencesFooter(refs))
+	}
 
 	return header.String(), msg.Body, footer.String()
 }
@@ -211,14 +467,14 @@ func removeCarriage(commit string) string {
 
 // Parse a commit message.
 func (p MessageProcessorImpl) Parse(subject, body string) (CommitMessage, error) {
-	preparedSubject, err := p.prepareHeader(subject)
+	header, err := p.parseHeader(subject)
 	commitBody := removeCarriage(body)
 
 	if err != nil {
 		return CommitMessage{}, err
 	}
 
-	commitType, scope, description, hasBreakingChange := parseSubjectMessage(preparedSubject)
+	commitType, scope, description, hasBreakingChange := header.ctype, header.scope, header.description, header.breaking
 
 	metadata := make(map[string]string)
 	for key, mdCfg := range p.messageCfg.Footer {
@@ -232,11 +488,35 @@ func (p MessageProcessorImpl) Parse(subject, body string) (CommitMessage, error)
 			}
 		}
 	}
+	trailers := parseTrailers(commitBody)
 	if tagValue := extractFooterMetadata(breakingChangeFooterKey, commitBody, false); tagValue != "" {
 		metadata[breakingChangeMetadataKey] = tagValue
+		if folded := trailerValues(trailers, breakingChangeFooterKey); len(folded) > 0 {
+			metadata[breakingChangeMetadataKey] = folded[0]
+		}
 		hasBreakingChange = true
 	}
 
+	issues, err := parseIssueReferences(p.messageCfg.Issue.Trackers, commitBody)
+	if err != nil {
+		return CommitMessage{}, err
+	}
+	for _, refs := range issues {
+		metadata[refs.Tracker] = strings.Join(refs.IDs(), ", ")
+	}
+	if _, exists := metadata[issueMetadataKey]; !exists && header.issue != "" {
+		metadata[issueMetadataKey] = header.issue
+	}
+	recognized := p.recognizedFooterKeys()
+	for _, t := range trailers {
+		if recognized[strings.ToLower(t.key)] {
+			continue
+		}
+		if _, exists := metadata[t.key]; !exists {
+			metadata[t.key] = strings.Join(trailerValues(trailers, t.key), ", ")
+		}
+	}
+
 	return CommitMessage{
 		Type:             commitType,
 		Scope:            scope,
@@ -244,9 +524,56 @@ func (p MessageProcessorImpl) Parse(subject, body string) (CommitMessage, error)
 		Body:             commitBody,
 		IsBreakingChange: hasBreakingChange,
 		Metadata:         metadata,
+		Issues:           issues,
 	}, nil
 }
 
+// recognizedFooterKeys returns lower case footer keys with a metadata key from config: commit-message.footer keys and synonyms,
+// breaking change and issue trackers.
+func (p MessageProcessorImpl) recognizedFooterKeys() map[string]bool {
+	keys := map[string]bool{strings.ToLower(breakingChangeFooterKey): true}
+	for _, mdCfg := range p.messageCfg.Footer {
+		if mdCfg.Key == "" {
+			continue
+		}
+		for _, key := range append([]string{mdCfg.Key}, mdCfg.KeySynonyms...) {
+			keys[strings.ToLower(key)] = true
+		}
+	}
+	for _, tracker := range p.messageCfg.Issue.Trackers {
+		keys[strings.ToLower(tracker.Key)] = true
+	}
+	return keys
+}
+
+// ParseCommitMessage parse a commit message using the default configuration.
+//
+// It's the same parsing applied on git log results, but returns an error if the
+// header is not a valid conventional commit. Use MessageProcessorImpl.ParseCommitMessage
+// to parse messages with a custom configuration.
+func ParseCommitMessage(subject, body string) (CommitMessage, error) {
+	cfg := NewDefaultConfig()
+	return NewMessageProcessor(cfg.CommitMessage, cfg.Branches).ParseCommitMessage(subject, body)
+}
+
+// ParseCommitMessage parse a commit message like Parse, but returns an error if
+// the header is not a valid conventional commit.
+func (p MessageProcessorImpl) ParseCommitMessage(subject, body string) (CommitMessage, error) {
+	if strings.TrimSpace(subject) == "" {
+		return CommitMessage{}, fmt.Errorf("commit message header is empty")
+	}
+
+	header, err := p.parseHeader(subject)
+	if err != nil {
+		return CommitMessage{}, err
+	}
+	if !header.selected && !headerFormatRegex.MatchString(header.text) {
+		return CommitMessage{}, fmt.Errorf("header [%s] should be in the format <type>(<scope>)!: <description>", header.text)
+	}
+
+	return p.Parse(subject, body)
+}
+
 func (p MessageProcessorImpl) prepareHeader(header string) (string, error) {
 	if p.messageCfg.HeaderSelector == "" {
 		return header, nil
@@ -271,9 +598,97 @@ func (p MessageProcessorImpl) prepareHeader(header string) (string, error) {
 	return match[index], nil
 }
 
+// commitHeader fields of a commit header, selected is true when they were read from named groups of a header selector.
+type commitHeader struct {
+	text                             string // conventional header, without the text around header-selector header group
+	ctype, scope, description, issue string
+	breaking, selected               bool
+}
+
+// header selector groups defining commit fields, see CommitMessageConfig.HeaderSelectors.
+var headerSelectorFieldGroups = []string{"type", "scope", "description", "issue", "breaking"}
+
+// parseHeader reads header with header-selector and header-selectors tried in order, headers not matching any of them
+// use the conventional commits format. A single header-selector with a header group fails if header does not match it.
+func (p MessageProcessorImpl) parseHeader(header string) (commitHeader, error) {
+	selectors := p.messageCfg.HeaderSelectors
+	if p.messageCfg.HeaderSelector != "" {
+		if len(selectors) == 0 && !hasFieldGroups(p.messageCfg.HeaderSelector) {
+			prepared, err := p.prepareHeader(header)
+			if err != nil {
+				return commitHeader{}, err
+			}
+			return conventionalHeader(prepared), nil
+		}
+		selectors = append([]string{p.messageCfg.HeaderSelector}, selectors...)
+	}
+
+	for _, selector := range selectors {
+		regex, err := compileHeaderSelector(selector)
+		if err != nil {
+			return commitHeader{}, err
+		}
+		match := regex.FindStringSubmatch(header)
+		if match == nil {
+			continue
+		}
+		if index := regex.SubexpIndex(messageRegexGroupName); index >= 0 {
+			return conventionalHeader(match[index]), nil
+		}
+		group := func(name string) string {
+			if index := regex.SubexpIndex(name); index >= 0 {
+				return strings.TrimSpace(match[index])
+			}
+			return ""
+		}
+		return commitHeader{text: header, ctype: group("type"), scope: group("scope"), description: group("description"),
+			issue: group("issue"), breaking: group("breaking") != "", selected: true}, nil
+	}
+	return conventionalHeader(header), nil
+}
+
+func conventionalHeader(header string) commitHeader {
+	ctype, scope, description, breaking := parseSubjectMessage(header)
+	return commitHeader{text: header, ctype: ctype, scope: scope, description: description, breaking: breaking}
+}
+
+// compileHeaderSelector compiles a commit-message.header-selectors regex, it must have a header group or commit field groups.
+func compileHeaderSelector(selector string) (*regexp.Regexp, error) {
+	regex, err := regexp.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex on header-selectors %s, error: %v", selector, err)
+	}
+	if regex.SubexpIndex(messageRegexGroupName) < 0 && !hasFieldGroups(selector) {
+		return nil, fmt.Errorf("header selector %s must have a %s group or at least one of %s groups", selector, messageRegexGroupName, strings.Join(headerSelectorFieldGroups, ", "))
+	}
+	return regex, nil
+}
+
+// hasFieldGroups returns true if selector is a valid regex with commit field groups.
+func hasFieldGroups(selector string) bool {
+	regex, err := regexp.Compile(selector)
+	if err != nil {
+		return false
+	}
+	for _, name := range headerSelectorFieldGroups {
+		if regex.SubexpIndex(name) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// conventional commits header, types are lower case and may have digits and hyphens, e.g. i18n or pre-release.
+const headerTypePattern = `[a-z][a-z0-9+-]*`
+
+var (
+	// headerFormatRegex a valid conventional commits header, used by Validate and ParseCommitMessage.
+	headerFormatRegex  = regexp.MustCompile(`^` + headerTypePattern + `(\(.+\))?!?: .+$`)
+	subjectFieldsRegex = regexp.MustCompile(`(` + headerTypePattern + `)(\((.*)\))?(!)?: (.*)`)
+)
+
 func parseSubjectMessage(message string) (string, string, string, bool) {
-	regex := regexp.MustCompile(`([a-z]+)(\((.*)\))?(!)?: (.*)`)
-	result := regex.FindStringSubmatch(message)
+	result := subjectFieldsRegex.FindStringSubmatch(message)
 	if len(result) != 6 {
 		return "", "", message, false
 	}
@@ -295,13 +710,64 @@ func extractFooterMetadata(key, text string, useHash bool) string {
 	return result[1]
 }
 
-func hasFooter(message string) bool {
-	r := regexp.MustCompile("^[a-zA-Z-]+: .*|^[a-zA-Z-]+ #.*|^" + breakingChangeFooterKey + ": .*")
+// trailer is a footer line of a commit message, e.g. "Co-authored-by: Name <email>" or "Refs #345".
+type trailer struct {
+	key   string
+	value string
+}
+
+var trailerRegex = regexp.MustCompile("^(" + breakingChangeFooterKey + "|[A-Za-z0-9-]+)(: | #)(.*)$")
+
+// parseTrailers returns the trailers of the last paragraph of body following git trailer rules, lines starting with
+// whitespace continue the value of the previous trailer and are unfolded with a single space. Other lines are ignored.
+func parseTrailers(body string) []trailer {
+	lines := strings.Split(strings.TrimRight(body, "\n "), "\n")
+	start := 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			start = i + 1
+		}
+	}
+
+	var trailers []trailer
+	current := -1
+	for _, line := range lines[start:] {
+		if match := trailerRegex.FindStringSubmatch(line); match != nil {
+			value := match[3]
+			if match[2] == " #" {
+				value = "#" + value
+			}
+			trailers = append(trailers, trailer{key: match[1], value: strings.TrimSpace(value)})
+			current = len(trailers) - 1
+			continue
+		}
+		if current >= 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			trailers[current].value = strings.TrimSpace(trailers[current].value + " " + strings.TrimSpace(line))
+			continue
+		}
+		current = -1
+	}
+	return trailers
+}
+
+// trailerValues returns the values of trailers with key, in order of appearance.
+func trailerValues(trailers []trailer, key string) []string {
+	var values []string
+	for _, t := range trailers {
+		if t.key == key {
+			values = append(values, t.value)
+		}
+	}
+	return values
+}
 
+var footerLineRegex = regexp.MustCompile("^[a-zA-Z-]+: .*|^[a-zA-Z-]+ #.*|^" + breakingChangeFooterKey + ": .*")
+
+func hasFooter(message string) bool {
 	scanner := bufio.NewScanner(strings.NewReader(message))
 	lines := 0
 	for scanner.Scan() {
-		if lines > 0 && r.MatchString(scanner.Text()) {
+		if lines > 0 && footerLineRegex.MatchString(scanner.Text()) {
 			return true
 		}
 		lines++
@@ -310,6 +776,69 @@ func hasFooter(message string) bool {
 	return false
 }
 
+// footerLine footer of a message found by findFooters.
+type footerLine struct {
+	index int    // line index on message
+	key   string // key as written on message
+	value string // value with # for footers using hash, e.g. "#13"
+	text  string // whole line
+}
+
+// findFooters returns the lines of message with a footer of one of keys, case insensitive, using ": " or " #" separator.
+func findFooters(message string, keys []string) []footerLine {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	r := regexp.MustCompile(fmt.Sprintf("(?i)^(%s)(: | #)(.+)$", strings.Join(quoted, "|")))
+	var footers []footerLine
+	for i, line := range strings.Split(message, "\n") {
+		if match := r.FindStringSubmatch(strings.TrimRight(line, "\r")); match != nil {
+			value := match[3]
+			if match[2] == " #" {
+				value = "#" + value
+			}
+			footers = append(footers, footerLine{index: i, key: match[1], value: strings.TrimSpace(value), text: strings.TrimRight(line, "\r")})
+		}
+	}
+	return footers
+}
+
+// referencesIssue checks if one of footers references issue, as a whole word of its value, e.g. "JIRA-1, JIRA-2" references JIRA-2.
+func referencesIssue(footers []footerLine, issue string) bool {
+	r := regexp.MustCompile(`(^|[^A-Za-z0-9_-])` + regexp.QuoteMeta(strings.TrimPrefix(issue, "#")) + `($|[^A-Za-z0-9_-])`)
+	for _, footer := range footers {
+		if r.MatchString(footer.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceFooters replaces the first of footers on message with footer, the others are removed.
+func replaceFooters(message string, footers []footerLine, footer string) string {
+	lines := strings.Split(message, "\n")
+	removed := make(map[int]bool, len(footers))
+	for _, f := range footers[1:] {
+		removed[f.index] = true
+	}
+	result := make([]string, 0, len(lines))
+	for i, line := range lines {
+		switch {
+		case i == footers[0].index:
+			result = append(result, footer)
+		case !removed[i]:
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// hasFooterKey checks if message has a footer with key, case insensitive.
+func hasFooterKey(message, key string) bool {
+	return regexp.MustCompile(fmt.Sprintf("(?mi)^%s(: | #).+$", regexp.QuoteMeta(key))).MatchString(message)
+}
+
 func hasIssueID(message string, issueConfig CommitMessageFooterConfig) bool {
 	var r *regexp.Regexp
 	if issueConfig.UseHash {
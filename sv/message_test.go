@@ -3,6 +3,9 @@ package sv
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
 )
 
 var ccfg = CommitMessageConfig{
@@ -15,6 +18,12 @@ var ccfg = CommitMessageConfig{
 	Issue: CommitMessageIssueConfig{Regex: "[A-Z]+-[0-9]+"},
 }
 
+var ccfgPreferBranch = CommitMessageConfig{
+	Types:  []string{"feat", "fix"},
+	Footer: map[string]CommitMessageFooterConfig{"issue": {Key: "jira", KeySynonyms: []string{"Jira"}}},
+	Issue:  CommitMessageIssueConfig{Regex: "[A-Z]+-[0-9]+", OnConflict: IssueOnConflictPreferBranch},
+}
+
 var ccfgHash = CommitMessageConfig{
 	Types: []string{"feat", "fix"},
 	Scope: CommitMessageScopeConfig{},
@@ -76,6 +85,17 @@ func newCommitMessageCfg(headerSelector string) CommitMessageConfig {
 }
 
 // messages samples start.
+var ccfgEnhance = CommitMessageConfig{
+	Types:  []string{"feat", "fix"},
+	Footer: map[string]CommitMessageFooterConfig{"issue": {Key: "jira"}},
+	Issue:  CommitMessageIssueConfig{Regex: "[A-Z]+-[0-9]+"},
+	Enhance: []CommitMessageEnhanceConfig{
+		{Key: "Refs", ValueTemplate: "{{.Branch}}"},
+		{Key: "Change-type", ValueTemplate: "{{.Type}}"},
+		{Key: "Scope", ValueTemplate: "{{.Scope}}"},
+	},
+}
+
 var fullMessage = `fix: correct minor typos in code
 
 see the issue for details
@@ -163,6 +183,8 @@ func TestMessageProcessorImpl_Validate(t *testing.T) {
 
 		{"support ! for breaking change", ccfg, "feat!: add something", false},
 		{"support ! with scope for breaking change", ccfg, "feat(scope)!: add something", false},
+		{"type with digits and hyphen", CommitMessageConfig{Types: []string{"i18n", "pre-release"}}, "i18n(menu): translate labels", false},
+		{"type with hyphen", CommitMessageConfig{Types: []string{"i18n", "pre-release"}}, "pre-release: add rc channel", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -174,6 +196,47 @@ func TestMessageProcessorImpl_Validate(t *testing.T) {
 	}
 }
 
+func TestMessageProcessorImpl_Violations(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CommitMessageConfig
+		message string
+		want    []string
+	}{
+		{"valid message", ccfg, "feat: add something", nil},
+		{"invalid type and description", ccfg, "docs: Add something", []string{
+			"message type should be one of [feat, fix]",
+			"description [Add something] should begins with lowercase letter",
+		}},
+		{"invalid scope and description", ccfgWithScope, "feat(invalid): Add something", []string{
+			"message scope should one of [, scope]",
+			"description [Add something] should begins with lowercase letter",
+		}},
+		{"required scope and footer missing", CommitMessageConfig{Types: []string{"feat"}, Scope: CommitMessageScopeConfig{Required: true}, RequiredFooters: []string{"Risk"}},
+			"feat: add something", []string{
+				"message scope is required",
+				"message should have footer Risk",
+			}},
+		{"required scope and footer", CommitMessageConfig{Types: []string{"feat"}, Scope: CommitMessageScopeConfig{Required: true}, RequiredFooters: []string{"Risk"}},
+			"feat(infra): add something\n\nrisk: low", nil},
+		{"duplicated issue footer", ccfg, "feat: add something\n\njira: JIRA-1\nReviewed-by: A\nReviewed-by: B\nJira: JIRA-2", []string{
+			"message should have a single jira footer, found 2: jira: JIRA-1; Jira: JIRA-2",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewMessageProcessor(tt.cfg, newBranchCfg(false))
+			var got []string
+			for _, err := range p.Violations(tt.message) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MessageProcessorImpl.Violations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMessageProcessorImpl_ValidateType(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -248,18 +311,28 @@ func TestMessageProcessorImpl_Enhance(t *testing.T) {
 		want    string
 		wantErr bool
 	}{
-		{"issue on branch name", ccfg, "JIRA-123", "fix: fix something", "\njira: JIRA-123", false},
-		{"issue on branch name with description", ccfg, "JIRA-123-some-description", "fix: fix something", "\njira: JIRA-123", false},
-		{"issue on branch name with prefix", ccfg, "feature/JIRA-123", "fix: fix something", "\njira: JIRA-123", false},
-		{"with footer", ccfg, "JIRA-123", fullMessage, "jira: JIRA-123", false},
-		{"with issue on footer", ccfg, "JIRA-123", fullMessageWithJira, "", false},
-		{"issue on branch name with prefix and description", ccfg, "feature/JIRA-123-some-description", "fix: fix something", "\njira: JIRA-123", false},
+		{"issue on branch name", ccfg, "JIRA-123", "fix: fix something", "fix: fix something\n\njira: JIRA-123", false},
+		{"issue on branch name with description", ccfg, "JIRA-123-some-description", "fix: fix something", "fix: fix something\n\njira: JIRA-123", false},
+		{"issue on branch name with prefix", ccfg, "feature/JIRA-123", "fix: fix something", "fix: fix something\n\njira: JIRA-123", false},
+		{"with footer", ccfg, "JIRA-123", fullMessage, fullMessage + "\njira: JIRA-123", false},
+		{"with issue on footer", ccfg, "JIRA-456", fullMessageWithJira, "", false},
+		{"with issue on footer synonym", ccfg, "feature/JIRA-123", "fix: fix something\n\nJira: JIRA-122, JIRA-123", "", false},
+		{"with another issue on footer", ccfg, "JIRA-123", fullMessageWithJira, "", true},
+		{"with another issue on footer prefer branch", ccfgPreferBranch, "JIRA-123", "fix: fix something\n\nJira: JIRA-456\njira: JIRA-457\nSigned-off-by: A <a@b.c>", "fix: fix something\n\njira: JIRA-123\nSigned-off-by: A <a@b.c>", true},
+		{"with issue on footer and no issue on branch", ccfg, "branch", fullMessageWithJira, "", false},
+		{"issue on branch name with prefix and description", ccfg, "feature/JIRA-123-some-description", "fix: fix something", "fix: fix something\n\njira: JIRA-123", false},
 		{"no issue on branch name", ccfg, "branch", "fix: fix something", "", true},
 		{"unexpected branch name", ccfg, "feature /JIRA-123", "fix: fix something", "", true},
-		{"issue on branch name using hash", ccfgHash, "JIRA-123-some-description", "fix: fix something", "\njira #JIRA-123", false},
-		{"numeric issue on branch name", ccfgGitIssue, "#13", "fix: fix something", "\nissue: #13", false},
-		{"numeric issue on branch name without hash", ccfgGitIssue, "13", "fix: fix something", "\nissue: #13", false},
-		{"numeric issue on branch name with description without hash", ccfgGitIssue, "13-some-fix", "fix: fix something", "\nissue: #13", false},
+		{"issue on branch name using hash", ccfgHash, "JIRA-123-some-description", "fix: fix something", "fix: fix something\n\njira #JIRA-123", false},
+		{"numeric issue on branch name", ccfgGitIssue, "#13", "fix: fix something", "fix: fix something\n\nissue: #13", false},
+		{"numeric issue on branch name without hash", ccfgGitIssue, "13", "fix: fix something", "fix: fix something\n\nissue: #13", false},
+		{"numeric issue on branch name with description without hash", ccfgGitIssue, "13-some-fix", "fix: fix something", "fix: fix something\n\nissue: #13", false},
+		{"hook message with comments", ccfg, "JIRA-123", "fix: fix something\n\n# Please enter the commit message\n", "fix: fix something\n\njira: JIRA-123\n\n# Please enter the commit message\n", false},
+		{"before sign-off", ccfg, "JIRA-123", "fix: fix something\n\nSigned-off-by: A <a@b.c>\n", "fix: fix something\n\njira: JIRA-123\nSigned-off-by: A <a@b.c>\n", false},
+		{"enhance footers", ccfgEnhance, "feature/JIRA-123", "fix(parser): fix something\n", "fix(parser): fix something\n\njira: JIRA-123\nRefs: feature/JIRA-123\nChange-type: fix\nScope: parser\n", false},
+		{"enhance footers without issue", ccfgEnhance, "main", "feat: add something\n", "feat: add something\n\nRefs: main\nChange-type: feat\n", true},
+		{"enhance footers idempotent", ccfgEnhance, "feature/JIRA-123", "fix: fix something\n\njira: JIRA-123\nRefs: feature/JIRA-123\nChange-type: fix\n", "", false},
+		{"enhance footers before sign-off", ccfgEnhance, "feature/JIRA-123", "fix: fix something\n\nbody\n\nReviewed-by: Z\nSigned-off-by: A <a@b.c>\nSigned-off-by: B <b@b.c>", "fix: fix something\n\nbody\n\nReviewed-by: Z\njira: JIRA-123\nRefs: feature/JIRA-123\nChange-type: fix\nSigned-off-by: A <a@b.c>\nSigned-off-by: B <b@b.c>", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -394,6 +467,36 @@ var hashMetadataBody = `some descriptions
 Jira: JIRA-999
 Refs #123`
 
+func TestMessageProcessorImpl_Parse_Trailers(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want map[string]string
+	}{
+		{"several trailers", "some description\n\njira: JIRA-1\nCo-authored-by: Ana <ana@example.com>\nReviewed-by: Bob <bob@example.com>",
+			map[string]string{issueMetadataKey: "JIRA-1", "Co-authored-by": "Ana <ana@example.com>", "Reviewed-by": "Bob <bob@example.com>"}},
+		{"duplicated keys", "Co-authored-by: Ana <ana@example.com>\nCo-authored-by: Bob <bob@example.com>\nSigned-off-by: Ana <ana@example.com>",
+			map[string]string{"Co-authored-by": "Ana <ana@example.com>, Bob <bob@example.com>", "Signed-off-by": "Ana <ana@example.com>"}},
+		{"folded values", "BREAKING CHANGE: config moved\n  to .sv4git.yml\nDeploy-notes: run migration\n\tbefore release\nTicket #42",
+			map[string]string{breakingChangeMetadataKey: "config moved to .sv4git.yml", "Deploy-notes": "run migration before release", "Ticket": "#42"}},
+		{"only last paragraph", "Note: not a trailer\n\nsome text\nAcked-by: Ana\ntext without key\n  not folded",
+			map[string]string{"Acked-by": "Ana"}},
+		{"recognized keys are not repeated", "Jira: JIRA-2\nRefs #12",
+			map[string]string{issueMetadataKey: "JIRA-2", "refs": "#12"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMessageProcessor(ccfg, newBranchCfg(false)).Parse("feat: something", tt.body)
+			if err != nil {
+				t.Fatalf("MessageProcessorImpl.Parse() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Metadata, tt.want) {
+				t.Errorf("MessageProcessorImpl.Parse() metadata = %v, want %v", got.Metadata, tt.want)
+			}
+		})
+	}
+}
+
 func TestMessageProcessorImpl_Parse(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -410,7 +513,7 @@ func TestMessageProcessorImpl_Parse(t *testing.T) {
 		{"jira synonyms metadata", ccfg, "feat: something new", issueSynonymsBody, CommitMessage{Type: "feat", Scope: "", Description: "something new", Body: issueSynonymsBody, IsBreakingChange: false, Metadata: map[string]string{issueMetadataKey: "JIRA-789"}}},
 		{"breaking change with exclamation mark", ccfg, "feat!: something new", "", CommitMessage{Type: "feat", Scope: "", Description: "something new", Body: "", IsBreakingChange: true, Metadata: map[string]string{}}},
 		{"hash metadata", ccfg, "feat: something new", hashMetadataBody, CommitMessage{Type: "feat", Scope: "", Description: "something new", Body: hashMetadataBody, IsBreakingChange: false, Metadata: map[string]string{issueMetadataKey: "JIRA-999", "refs": "#123"}}},
-		{"empty issue cfg", ccfgEmptyIssue, "feat: something new", hashMetadataBody, CommitMessage{Type: "feat", Scope: "", Description: "something new", Body: hashMetadataBody, IsBreakingChange: false, Metadata: map[string]string{}}},
+		{"empty issue cfg", ccfgEmptyIssue, "feat: something new", hashMetadataBody, CommitMessage{Type: "feat", Scope: "", Description: "something new", Body: hashMetadataBody, IsBreakingChange: false, Metadata: map[string]string{"Jira": "JIRA-999", "Refs": "#123"}}},
 		{"carriage return on body", ccfg, "feat: something new", bodyWithCarriage, CommitMessage{Type: "feat", Scope: "", Description: "something new", Body: expectedBodyWithCarriage, IsBreakingChange: false, Metadata: map[string]string{issueMetadataKey: "JIRA-123"}}},
 	}
 	for _, tt := range tests {
@@ -574,3 +677,263 @@ func Test_removeCarriage(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCommitMessage(t *testing.T) {
+	multiParagraphBody := "first paragraph\n\nsecond paragraph\n\njira: JIRA-123\nBREAKING CHANGE: removed something"
+	tests := []struct {
+		name    string
+		subject string
+		body    string
+		want    CommitMessage
+		wantErr bool
+	}{
+		{"type scope and breaking", "feat(api)!: new endpoint", "", CommitMessage{Type: "feat", Scope: "api", Description: "new endpoint", IsBreakingChange: true, Metadata: map[string]string{}}, false},
+		{"missing scope", "fix: handle nil", "", CommitMessage{Type: "fix", Description: "handle nil", Metadata: map[string]string{}}, false},
+		{"multi paragraph body", "feat: something", multiParagraphBody, CommitMessage{Type: "feat", Description: "something", Body: multiParagraphBody, IsBreakingChange: true, Metadata: map[string]string{issueMetadataKey: "JIRA-123", breakingChangeMetadataKey: "removed something"}}, false},
+		{"empty header", "", "", CommitMessage{}, true},
+		{"missing type", "something", "", CommitMessage{}, true},
+		{"missing description", "feat: ", "", CommitMessage{}, true},
+		{"uppercase type", "Feat: something", "", CommitMessage{}, true},
+		{"type with digits", "i18n(menu): translate labels", "", CommitMessage{Type: "i18n", Scope: "menu", Description: "translate labels", Metadata: map[string]string{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommitMessage(tt.subject, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCommitMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCommitMessage() = [%+v], want [%+v]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageProcessorImpl_Parse_HeaderSelectors(t *testing.T) {
+	cfg := newCommitMessageCfg("")
+	cfg.HeaderSelectors = []string{
+		`^\[(?P<issue>[A-Z]+-[0-9]+)\] (?P<type>[a-z]+)(\((?P<scope>[^)]+)\))?(?P<breaking>!)?: (?P<description>.+)$`,
+		`^Merged PR [0-9]+: (?P<header>.*)$`,
+	}
+	p := NewMessageProcessor(cfg, newBranchCfg(false))
+
+	tests := []struct {
+		name    string
+		subject string
+		body    string
+		want    CommitMessage
+	}{
+		{"bracketed issue", "[PROJ-123] feat(api): add login", "", CommitMessage{Type: "feat", Scope: "api", Description: "add login", Metadata: map[string]string{"issue": "PROJ-123"}}},
+		{"bracketed issue with breaking change", "[PROJ-7] fix!: drop v1", "", CommitMessage{Type: "fix", Description: "drop v1", IsBreakingChange: true, Metadata: map[string]string{"issue": "PROJ-7"}}},
+		{"bracketed issue with issue footer", "[PROJ-7] fix: something", "jira: PROJ-8", CommitMessage{Type: "fix", Description: "something", Body: "jira: PROJ-8", Metadata: map[string]string{"issue": "PROJ-8"}}},
+		{"header group", "Merged PR 12: feat: something", "", CommitMessage{Type: "feat", Description: "something", Metadata: map[string]string{}}},
+		{"conventional", "fix(ui): something", "", CommitMessage{Type: "fix", Scope: "ui", Description: "something", Metadata: map[string]string{}}},
+		{"unknown format", "Update README", "", CommitMessage{Description: "Update README", Metadata: map[string]string{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Parse(tt.subject, tt.body)
+			if err != nil {
+				t.Fatalf("MessageProcessorImpl.Parse() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MessageProcessorImpl.Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageProcessorImpl_Validate_HeaderSelectors(t *testing.T) {
+	cfg := newCommitMessageCfg("")
+	cfg.HeaderSelectors = []string{`^\[(?P<issue>[A-Z]+-[0-9]+)\] (?P<type>[a-z]+): (?P<description>.+)$`}
+	p := NewMessageProcessor(cfg, newBranchCfg(false))
+
+	tests := []struct {
+		message string
+		wantErr bool
+	}{
+		{"[PROJ-123] feat: add login", false},
+		{"[PROJ-123] wip: add login", true},
+		{"feat: add login", false},
+		{"Update README", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if err := p.Validate(tt.message); (err != nil) != tt.wantErr {
+				t.Errorf("MessageProcessorImpl.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageProcessorImpl_ParseCommitMessage_HeaderSelectorFieldGroups(t *testing.T) {
+	p := NewMessageProcessor(newCommitMessageCfg(`^\[(?P<issue>[A-Z]+-[0-9]+)\] (?P<type>[a-z]+): (?P<description>.+)$`), newBranchCfg(false))
+
+	got, err := p.ParseCommitMessage("[PROJ-123] feat: something", "")
+	if err != nil {
+		t.Fatalf("ParseCommitMessage() unexpected error: %v", err)
+	}
+	want := CommitMessage{Type: "feat", Description: "something", Metadata: map[string]string{"issue": "PROJ-123"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCommitMessage() = [%+v], want [%+v]", got, want)
+	}
+
+	if _, err := p.ParseCommitMessage("something", ""); err == nil {
+		t.Error("ParseCommitMessage() expected error for header not matching selector nor conventional format, got nil")
+	}
+}
+
+func TestCommitMessageConfig_Validate_HeaderSelectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors []string
+		wantErr   bool
+	}{
+		{"field groups", []string{`^\[(?P<issue>[A-Z]+-[0-9]+)\] (?P<type>[a-z]+): (?P<description>.+)$`}, false},
+		{"header group", []string{`^Merged PR [0-9]+: (?P<header>.*)$`}, false},
+		{"without groups", []string{`^\[[A-Z]+-[0-9]+\] (.+)$`}, true},
+		{"invalid regex", []string{`^\[(?P<type>[a-z]+`}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (CommitMessageConfig{HeaderSelectors: tt.selectors}).Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("CommitMessageConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageProcessorImpl_ParseCommitMessage_HeaderSelector(t *testing.T) {
+	p := NewMessageProcessor(newCommitMessageCfg("Merged PR (\\d+): (?P<header>.*)"), newBranchCfg(false))
+
+	got, err := p.ParseCommitMessage("Merged PR 123: feat(scope): something", "")
+	if err != nil {
+		t.Fatalf("ParseCommitMessage() unexpected error: %v", err)
+	}
+	want := CommitMessage{Type: "feat", Scope: "scope", Description: "something", Metadata: map[string]string{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCommitMessage() = [%+v], want [%+v]", got, want)
+	}
+
+	if _, err := p.ParseCommitMessage("Merged PR 123: something", ""); err == nil {
+		t.Error("ParseCommitMessage() expected error for non conventional selected header, got nil")
+	}
+}
+
+func TestMessageProcessorImpl_Format_BreakingStyles(t *testing.T) {
+	withMessage := NewCommitMessage("feat", "api", "drop v1 endpoints", "", "", "clients must use v2")
+	withoutMessage := NewCommitMessage("feat", "api", "drop v1 endpoints", "", "", "")
+	withoutMessage.IsBreakingChange = true
+	tests := []struct {
+		style       string
+		msg         CommitMessage
+		wantHeader  string
+		wantFooter  string
+		wantRelease string
+	}{
+		{"", withMessage, "feat(api): drop v1 endpoints", "BREAKING CHANGE: clients must use v2", "clients must use v2"},
+		{BreakingStyleFooter, withMessage, "feat(api): drop v1 endpoints", "BREAKING CHANGE: clients must use v2", "clients must use v2"},
+		{BreakingStyleFooter, withoutMessage, "feat(api)!: drop v1 endpoints", "", "drop v1 endpoints"},
+		{BreakingStyleExclamation, withMessage, "feat(api)!: drop v1 endpoints", "", "drop v1 endpoints"},
+		{BreakingStyleExclamation, withoutMessage, "feat(api)!: drop v1 endpoints", "", "drop v1 endpoints"},
+		{BreakingStyleBoth, withMessage, "feat(api)!: drop v1 endpoints", "BREAKING CHANGE: clients must use v2", "clients must use v2"},
+		{BreakingStyleBoth, withoutMessage, "feat(api)!: drop v1 endpoints", "", "drop v1 endpoints"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style+" "+tt.msg.BreakingMessage(), func(t *testing.T) {
+			cfg := CommitMessageConfig{Types: []string{"feat", "fix"}, Breaking: CommitMessageBreakingConfig{Style: tt.style}}
+			p := NewMessageProcessor(cfg, newBranchCfg(false))
+			header, _, footer := p.Format(tt.msg)
+			if header != tt.wantHeader || footer != tt.wantFooter {
+				t.Fatalf("MessageProcessorImpl.Format() = %q, %q, want %q, %q", header, footer, tt.wantHeader, tt.wantFooter)
+			}
+
+			// every style is parsed as breaking change whatever the configured style, bumps major and is on release notes.
+			for _, parseStyle := range []string{BreakingStyleFooter, BreakingStyleExclamation, BreakingStyleBoth} {
+				parser := NewMessageProcessor(CommitMessageConfig{Types: cfg.Types, Breaking: CommitMessageBreakingConfig{Style: parseStyle}}, newBranchCfg(false))
+				if err := parser.Validate(header + "\n\n" + footer); err != nil {
+					t.Errorf("MessageProcessorImpl.Validate() with style %s error = %v", parseStyle, err)
+				}
+				msg, err := parser.Parse(header, footer)
+				if err != nil || !msg.IsBreakingChange {
+					t.Fatalf("MessageProcessorImpl.Parse() with style %s = %+v, error %v, want breaking change", parseStyle, msg, err)
+				}
+				commits := []GitCommitLog{{Message: msg}}
+				if next, _ := NewSemVerCommitsProcessor(VersioningConfig{UpdateMinor: []string{"feat"}}, cfg).NextVersion(semver.MustParse("1.2.0"), commits); next.String() != "2.0.0" {
+					t.Errorf("NextVersion() with style %s = %s, want 2.0.0", parseStyle, next)
+				}
+				rn := NewReleaseNoteProcessor(ReleaseNotesConfig{Sections: []ReleaseNotesSectionConfig{{Name: "Breaking Changes", SectionType: ReleaseNotesSectionTypeBreakingChanges}}}).Create(nil, "", "", time.Time{}, commits)
+				if len(rn.Sections) != 1 || !reflect.DeepEqual(rn.Sections[0].(ReleaseNoteBreakingChangeSection).Messages, []string{tt.wantRelease}) {
+					t.Errorf("ReleaseNoteProcessorImpl.Create() with style %s sections = %+v, want breaking change %q", parseStyle, rn.Sections, tt.wantRelease)
+				}
+			}
+		})
+	}
+}
+
+func TestCommitMessageBreakingConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         CommitMessageBreakingConfig
+		wantErr     bool
+		wantPrompts []string
+	}{
+		{"default", CommitMessageBreakingConfig{}, false, []string{"feat", "fix", "docs"}},
+		{"always", CommitMessageBreakingConfig{Prompt: BreakingPromptAlways, Style: BreakingStyleBoth}, false, []string{"feat", "fix", "docs"}},
+		{"never", CommitMessageBreakingConfig{Prompt: BreakingPromptNever, Style: BreakingStyleExclamation}, false, nil},
+		{"when type in", CommitMessageBreakingConfig{Prompt: BreakingPromptWhenTypeIn, Types: []string{"feat", "fix"}, Style: BreakingStyleFooter}, false, []string{"feat", "fix"}},
+		{"when type in without types", CommitMessageBreakingConfig{Prompt: BreakingPromptWhenTypeIn}, true, nil},
+		{"invalid prompt", CommitMessageBreakingConfig{Prompt: "sometimes"}, true, nil},
+		{"invalid style", CommitMessageBreakingConfig{Style: "header"}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (CommitMessageConfig{Breaking: tt.cfg}).Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("CommitMessageConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got []string
+			for _, ctype := range []string{"feat", "fix", "docs"} {
+				if tt.cfg.Prompts(ctype) {
+					got = append(got, ctype)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.wantPrompts) {
+				t.Errorf("CommitMessageBreakingConfig.Prompts() = %v, want %v", got, tt.wantPrompts)
+			}
+		})
+	}
+}
+
+func TestWithValidationWarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"header only", "Update readme\n", "Update readme\n\nValidation-Warnings: 2\n"},
+		{"with body", "Update readme\n\nfix typos", "Update readme\n\nfix typos\n\nValidation-Warnings: 2"},
+		{"before sign-off", "Update readme\n\nSigned-off-by: John <john@example.com>\n", "Update readme\n\nValidation-Warnings: 2\nSigned-off-by: John <john@example.com>\n"},
+		{"replaces count", "Update readme\n\nValidation-Warnings: 1\n# comment\n", "Update readme\n\nValidation-Warnings: 2\n# comment\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithValidationWarnings(tt.message, 2); got != tt.want {
+				t.Errorf("WithValidationWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitMessageConfig_Validate_ValidationMode(t *testing.T) {
+	for _, mode := range []string{"", ValidationModeError, ValidationModeWarn, ValidationModeOff} {
+		if err := (CommitMessageConfig{ValidationMode: mode}).Validate(); err != nil {
+			t.Errorf("CommitMessageConfig.Validate() validation-mode %q unexpected error: %v", mode, err)
+		}
+	}
+	if err := (CommitMessageConfig{ValidationMode: "strict"}).Validate(); err == nil {
+		t.Errorf("CommitMessageConfig.Validate() validation-mode strict expected error")
+	}
+}
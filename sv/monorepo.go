@@ -3,8 +3,12 @@ package sv
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -13,15 +17,44 @@ import (
 
 // MonorepoComponent is a versioned component discovered in a monorepo.
 type MonorepoComponent struct {
-	Name               string          // Directory name of the component
+	Name               string          // Name read from monorepo.name-path or directory relative to repository root
 	RootPath           string          // Absolute path to the component root directory
 	VersioningFilePath string          // Absolute path to the versioning file
 	CurrentVersion     *semver.Version // Version read from the file
+	SecondaryFiles     []ComponentFile // Versioning files kept in sync with VersioningFilePath, see MonorepoConfig.VersioningFiles
+	MaxBump            string          // Highest version update allowed by commits, see MonorepoComponentConfig.MaxBump
+	PreviousPaths      []string        // Directories before renames, relative to repository root, see MonorepoComponentConfig.PreviousPaths
+}
+
+// ComponentFile secondary versioning file of a component.
+type ComponentFile struct {
+	Path    string          // Absolute path to the file
+	DotPath string          // Path of the version inside the file
+	Version *semver.Version // Version read from the file, may differ from the component version
+}
+
+// Files returns absolute paths of every versioning file of the component, the authoritative one first.
+func (c MonorepoComponent) Files() []string {
+	files := []string{c.VersioningFilePath}
+	for _, file := range c.SecondaryFiles {
+		files = append(files, file.Path)
+	}
+	return files
+}
+
+// ComponentError error reading a component versioning file skipped by monorepo.on-parse-error config.
+type ComponentError struct {
+	Path string // Absolute path to the versioning file
+	Err  error
+}
+
+func (e ComponentError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
 }
 
 // MonorepoProcessor discovers components and manages their file-based versions.
 type MonorepoProcessor interface {
-	FindComponents(repoRoot string, cfg MonorepoConfig) ([]MonorepoComponent, error)
+	FindComponents(repoRoot string, cfg MonorepoConfig) ([]MonorepoComponent, []ComponentError, error)
 	NextVersion(component MonorepoComponent, commits []GitCommitLog, semverProc SemVerCommitsProcessor) (*semver.Version, bool)
 	UpdateVersion(component MonorepoComponent, version semver.Version, cfg MonorepoConfig) error
 }
@@ -35,36 +68,173 @@ func NewMonorepoProcessor() *MonorepoProcessorImpl {
 }
 
 // FindComponents globs for versioning files and reads each component's current version.
-// The glob pattern in cfg.VersioningFile is relative to repoRoot.
-func (p MonorepoProcessorImpl) FindComponents(repoRoot string, cfg MonorepoConfig) ([]MonorepoComponent, error) {
+// The glob pattern in cfg.VersioningFile is relative to repoRoot and supports ** to match
+// any number of directories, directories matching cfg.Exclude patterns are not visited.
+// Secondary versioning files, see MonorepoConfig.VersioningFiles, are grouped by the component directory containing them.
+func (p MonorepoProcessorImpl) FindComponents(repoRoot string, cfg MonorepoConfig) ([]MonorepoComponent, []ComponentError, error) {
 	if cfg.VersioningFile == "" {
-		return nil, fmt.Errorf("monorepo.versioning-file is not configured")
+		return nil, nil, fmt.Errorf("monorepo.versioning-file is not configured")
+	}
+	onParseError := cfg.OnParseError
+	if onParseError == "" {
+		onParseError = OnParseErrorFail
+	}
+	if onParseError != OnParseErrorFail && onParseError != OnParseErrorSkip && onParseError != OnParseErrorWarn {
+		return nil, nil, fmt.Errorf("invalid monorepo.on-parse-error %q, use: %s, %s or %s", cfg.OnParseError, OnParseErrorFail, OnParseErrorSkip, OnParseErrorWarn)
 	}
 
-	pattern := filepath.Join(repoRoot, cfg.VersioningFile)
-	matches, err := filepath.Glob(pattern)
+	matches, err := globFiles(repoRoot, cfg.VersioningFile, cfg.Exclude)
 	if err != nil {
-		return nil, fmt.Errorf("invalid versioning-file glob %q: %v", cfg.VersioningFile, err)
+		return nil, nil, fmt.Errorf("invalid versioning-file glob %q: %v", cfg.VersioningFile, err)
 	}
 	if len(matches) == 0 {
-		return nil, fmt.Errorf("no files matched versioning-file pattern %q", cfg.VersioningFile)
+		return nil, nil, fmt.Errorf("no files matched versioning-file pattern %q", cfg.VersioningFile)
 	}
 
 	components := make([]MonorepoComponent, 0, len(matches))
+	var skipped []ComponentError
+	paths := make(map[string]string)
 	for _, matchPath := range matches {
-		version, err := readVersionFromFile(matchPath, cfg.Path)
+		component, err := readComponent(repoRoot, matchPath, cfg)
+		if err != nil {
+			if onParseError == OnParseErrorFail {
+				return nil, nil, err
+			}
+			skipped = append(skipped, ComponentError{Path: matchPath, Err: err})
+			continue
+		}
+
+		if other, exists := paths[component.Name]; exists {
+			return nil, nil, fmt.Errorf("duplicate component name %q found on %s and %s", component.Name, other, matchPath)
+		}
+		paths[component.Name] = matchPath
+		component.MaxBump = cfg.component(component.Name).MaxBump
+		component.PreviousPaths = cfg.component(component.Name).PreviousPaths
+		components = append(components, component)
+	}
+
+	components, skipped, err = withSecondaryFiles(repoRoot, cfg, components, skipped, onParseError)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(components) == 0 {
+		return nil, skipped, fmt.Errorf("could not read any component from versioning-file pattern %q, first error: %v", cfg.VersioningFile, skipped[0].Err)
+	}
+	return components, skipped, nil
+}
+
+// withSecondaryFiles reads secondary versioning files and adds them to the component whose directory contains them.
+// Files outside components fail, their authoritative versioning file is missing, unless the component was skipped.
+func withSecondaryFiles(repoRoot string, cfg MonorepoConfig, components []MonorepoComponent, skipped []ComponentError, onParseError string) ([]MonorepoComponent, []ComponentError, error) {
+	secondary := cfg.SecondaryVersioningFiles()
+	if len(secondary) == 0 {
+		return components, skipped, nil
+	}
+
+	invalid := make(map[int]error)
+	for _, file := range secondary {
+		matches, err := globFiles(repoRoot, file.Glob, cfg.Exclude)
 		if err != nil {
-			return nil, fmt.Errorf("reading version from %s: %v", matchPath, err)
+			return nil, nil, fmt.Errorf("invalid versioning-file glob %q: %v", file.Glob, err)
+		}
+		for _, matchPath := range matches {
+			index := componentOf(components, matchPath)
+			if index < 0 {
+				if componentErrorOf(skipped, matchPath) {
+					continue
+				}
+				return nil, nil, fmt.Errorf("versioning file %s has no authoritative versioning file %q on its directory", matchPath, cfg.VersioningFile)
+			}
+			version, err := readVersionFromFile(matchPath, file.Path, cfg.VersionFormat)
+			if err != nil {
+				err = fmt.Errorf("reading version from %s: %v", matchPath, err)
+				if onParseError == OnParseErrorFail {
+					return nil, nil, err
+				}
+				if _, exists := invalid[index]; !exists {
+					invalid[index] = err
+				}
+				continue
+			}
+			components[index].SecondaryFiles = append(components[index].SecondaryFiles, ComponentFile{Path: matchPath, DotPath: file.Path, Version: version})
 		}
-		dir := filepath.Dir(matchPath)
-		components = append(components, MonorepoComponent{
-			Name:               filepath.Base(dir),
-			RootPath:           dir,
-			VersioningFilePath: matchPath,
-			CurrentVersion:     version,
-		})
 	}
-	return components, nil
+
+	if len(invalid) == 0 {
+		return components, skipped, nil
+	}
+	valid := make([]MonorepoComponent, 0, len(components))
+	for i, component := range components {
+		if err, exists := invalid[i]; exists {
+			skipped = append(skipped, ComponentError{Path: component.VersioningFilePath, Err: err})
+			continue
+		}
+		valid = append(valid, component)
+	}
+	return valid, skipped, nil
+}
+
+// componentOf returns the index of the component with the deepest root directory containing filePath, -1 if none.
+func componentOf(components []MonorepoComponent, filePath string) int {
+	index := -1
+	for i, component := range components {
+		if insideDir(component.RootPath, filePath) && (index < 0 || len(component.RootPath) > len(components[index].RootPath)) {
+			index = i
+		}
+	}
+	return index
+}
+
+// componentErrorOf check if filePath is inside the directory of a skipped versioning file.
+func componentErrorOf(skipped []ComponentError, filePath string) bool {
+	for _, s := range skipped {
+		if insideDir(filepath.Dir(s.Path), filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+func insideDir(dir, filePath string) bool {
+	rel, err := filepath.Rel(dir, filePath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func readComponent(repoRoot, matchPath string, cfg MonorepoConfig) (MonorepoComponent, error) {
+	content, err := os.ReadFile(matchPath)
+	if err != nil {
+		return MonorepoComponent{}, fmt.Errorf("reading version from %s: %v", matchPath, err)
+	}
+	version, err := ParseVersionFile(matchPath, content, cfg.Path, cfg.VersionFormat)
+	if err != nil {
+		return MonorepoComponent{}, fmt.Errorf("reading version from %s: %v", matchPath, err)
+	}
+
+	dir := filepath.Dir(matchPath)
+	name, err := componentName(repoRoot, dir, matchPath, content, cfg.NamePath)
+	if err != nil {
+		return MonorepoComponent{}, fmt.Errorf("reading name from %s: %v", matchPath, err)
+	}
+
+	return MonorepoComponent{
+		Name:               name,
+		RootPath:           dir,
+		VersioningFilePath: matchPath,
+		CurrentVersion:     version,
+	}, nil
+}
+
+// componentName reads the name from versioning file content when namePath is defined,
+// otherwise uses the component directory relative to repoRoot.
+func componentName(repoRoot, dir, filePath string, content []byte, namePath string) (string, error) {
+	if namePath != "" {
+		return readStringByPath(filePath, content, namePath)
+	}
+	rel, err := filepath.Rel(repoRoot, dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
 }
 
 // NextVersion delegates to the existing SemVerCommitsProcessor.
@@ -72,39 +242,390 @@ func (p MonorepoProcessorImpl) NextVersion(component MonorepoComponent, commits
 	return semverProc.NextVersion(component.CurrentVersion, commits)
 }
 
-// UpdateVersion writes the new version string into the component's versioning file.
+// UpdateVersion writes the new version string into the component's versioning file and its secondary files,
+// keeping the format of the current version of each file, e.g. v1.3.0 if the file has v1.2.3.
 func (p MonorepoProcessorImpl) UpdateVersion(component MonorepoComponent, version semver.Version, cfg MonorepoConfig) error {
-	return writeVersionToFile(component.VersioningFilePath, cfg.Path, version.Original())
+	if err := updateVersionFile(component.VersioningFilePath, cfg.Path, cfg.VersionFormat, version); err != nil {
+		return err
+	}
+	for _, file := range component.SecondaryFiles {
+		if err := updateVersionFile(file.Path, file.DotPath, cfg.VersionFormat, version); err != nil {
+			return fmt.Errorf("%s: %v", file.Path, err)
+		}
+	}
+	return nil
+}
+
+func updateVersionFile(filePath, dotPath, format string, version semver.Version) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	current, err := readStringByPath(filePath, content, dotPath)
+	if err != nil {
+		return err
+	}
+	prefix, _, suffix, err := splitVersionString(current, format)
+	if err != nil {
+		return fmt.Errorf("path %q: %v", dotPath, err)
+	}
+	return writeVersionToFile(filePath, dotPath, prefix+version.String()+suffix)
+}
+
+// ComponentVersioningFile returns the versioning file path, relative to repository root, of a new component on componentDir.
+// The file name is the last segment of monorepo.versioning-file and the path must match its pattern.
+func ComponentVersioningFile(cfg MonorepoConfig, componentDir string) (string, error) {
+	if cfg.VersioningFile == "" {
+		return "", fmt.Errorf("monorepo.versioning-file is not configured")
+	}
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(cfg.VersioningFile)), "/")
+	fileName := segments[len(segments)-1]
+	if hasMeta(fileName) {
+		return "", fmt.Errorf("monorepo.versioning-file %q must end with a file name without wildcards", cfg.VersioningFile)
+	}
+
+	dir := filepath.ToSlash(filepath.Clean(componentDir))
+	if dir == "." || path.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", fmt.Errorf("component path %s must be a directory inside repository", componentDir)
+	}
+	file := path.Join(dir, fileName)
+	fileSegments := strings.Split(file, "/")
+	if !matchSegments(segments, fileSegments) {
+		return "", fmt.Errorf("%s does not match monorepo.versioning-file %q", file, cfg.VersioningFile)
+	}
+	for i := range fileSegments {
+		if excluded(fileSegments[i], fileSegments[:i+1], cfg.Exclude) {
+			return "", fmt.Errorf("%s is excluded by monorepo.exclude", file)
+		}
+	}
+	return file, nil
+}
+
+// CreateVersioningFile writes a new versioning file with version on monorepo.path and, if monorepo.name-path is configured, name.
+// Nested maps are created for dot-paths, existing files are not overwritten.
+func CreateVersioningFile(filePath string, cfg MonorepoConfig, name, version string) error {
+	if _, err := os.Stat(filePath); err == nil {
+		return fmt.Errorf("versioning file %s already exists", filePath)
+	}
+
+	data := make(map[string]interface{})
+	values := [][2]string{{cfg.Path, version}}
+	if cfg.NamePath != "" {
+		values = append(values, [2]string{cfg.NamePath, name})
+	}
+	for _, value := range values {
+		segments, err := parsePath(value[0])
+		if err != nil {
+			return fmt.Errorf("invalid path %q: %v", value[0], err)
+		}
+		if err := createByPath(data, segments, value[1]); err != nil {
+			return fmt.Errorf("path %q: %v", value[0], err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return marshalToFile(filePath, data)
+}
+
+// ComponentDirStatus a directory matching monorepo.component-dirs and its versioning file.
+type ComponentDirStatus struct {
+	Dir            string             // Directory relative to repository root, slash separated
+	VersioningFile string             // Versioning file of the directory relative to repository root, see ComponentVersioningFile
+	Component      *MonorepoComponent // Component read from the versioning file, nil if it is missing or invalid
+	Err            error              // Error reading the versioning file, nil if it is missing
+}
+
+// Missing reports if the directory has no versioning file, i.e. it is not found by FindComponents.
+func (s ComponentDirStatus) Missing() bool {
+	return s.Component == nil && s.Err == nil
+}
+
+// ComponentDirs lists directories matching the monorepo.component-dirs glob, the expected components, with their versioning
+// file. Unlike FindComponents, directories without versioning file are returned, so new components can be detected.
+func ComponentDirs(repoRoot string, cfg MonorepoConfig) ([]ComponentDirStatus, error) {
+	if cfg.ComponentDirs == "" {
+		return nil, fmt.Errorf("monorepo.component-dirs is not configured")
+	}
+	dirs, err := globDirs(repoRoot, cfg.ComponentDirs, cfg.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid component-dirs glob %q: %v", cfg.ComponentDirs, err)
+	}
+
+	result := make([]ComponentDirStatus, 0, len(dirs))
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(repoRoot, dir)
+		if err != nil {
+			return nil, err
+		}
+		status := ComponentDirStatus{Dir: filepath.ToSlash(rel)}
+		if status.VersioningFile, err = ComponentVersioningFile(cfg, status.Dir); err != nil {
+			return nil, err
+		}
+		filePath := filepath.Join(repoRoot, filepath.FromSlash(status.VersioningFile))
+		if _, err := os.Stat(filePath); err == nil {
+			component, cerr := readComponent(repoRoot, filePath, cfg)
+			if cerr != nil {
+				status.Err = cerr
+			} else {
+				status.Component = &component
+			}
+		} else if !os.IsNotExist(err) {
+			status.Err = err
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// ---- glob helpers ----
+
+// globFiles walks root returning files, sorted, that match the slash separated pattern.
+func globFiles(root, pattern string, exclude []string) ([]string, error) {
+	return globPaths(root, pattern, exclude, false)
+}
+
+// globDirs walks root returning directories, sorted, that match the slash separated pattern.
+func globDirs(root, pattern string, exclude []string) ([]string, error) {
+	return globPaths(root, pattern, exclude, true)
+}
+
+func globPaths(root, pattern string, exclude []string, dirs bool) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	for _, seg := range append(append([]string{}, segments...), exclude...) {
+		if _, err := path.Match(seg, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	// walk only from the static prefix of the pattern.
+	static := 0
+	for static < len(segments)-1 && !hasMeta(segments[static]) {
+		static++
+	}
+	base := filepath.Join(append([]string{root}, segments[:static]...)...)
+	if _, err := os.Stat(base); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	recursive := false
+	for _, seg := range segments {
+		recursive = recursive || seg == "**"
+	}
+
+	var matches []string
+	err := filepath.WalkDir(base, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		relSegments := strings.Split(filepath.ToSlash(rel), "/")
+
+		if d.IsDir() {
+			if p != base && excluded(d.Name(), relSegments, exclude) {
+				return filepath.SkipDir
+			}
+			if dirs && p != root && matchSegments(segments, relSegments) {
+				matches = append(matches, p)
+			}
+			if !recursive && p != root && len(relSegments) >= len(segments) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !dirs && !excluded(d.Name(), relSegments, exclude) && matchSegments(segments, relSegments) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// excluded check if name or relative path match any exclude pattern, patterns with '/' are matched against the path.
+func excluded(name string, relSegments []string, exclude []string) bool {
+	for _, pattern := range exclude {
+		if strings.Contains(pattern, "/") {
+			if matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), relSegments) {
+				return true
+			}
+		} else if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, ** matches zero or more segments.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// WithoutIgnoredPaths removes commits whose changed files inside paths all match versioning.ignore-paths patterns, empty paths
+// consider every file. Patterns without "/" match file or directory names at any level, e.g. "*.md", patterns with "/" match
+// paths from repository root, e.g. ".github/" or "docs/**". Commits without files are kept, files must be loaded, see LogRange.WithFiles.
+func WithoutIgnoredPaths(commits []GitCommitLog, ignore, paths []string) []GitCommitLog {
+	if len(ignore) == 0 {
+		return commits
+	}
+	result := make([]GitCommitLog, 0, len(commits))
+	for _, commit := range commits {
+		if !onlyIgnoredPaths(commit.Files, ignore, paths) {
+			result = append(result, commit)
+		}
+	}
+	return result
+}
+
+func onlyIgnoredPaths(files, ignore, paths []string) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, file := range files {
+		if !changedPaths([]string{file}, paths) {
+			continue
+		}
+		segments := strings.Split(file, "/")
+		ignored := false
+		for i := range segments {
+			if excluded(segments[i], segments[:i+1], ignore) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			return false
+		}
+	}
+	return true
+}
+
+func validPathPattern(pattern string) bool {
+	trimmed := strings.Trim(pattern, "/")
+	if trimmed == "" {
+		return false
+	}
+	for _, segment := range strings.Split(trimmed, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
 }
 
 // ---- file I/O helpers ----
 
-func readVersionFromFile(filePath, dotPath string) (*semver.Version, error) {
+// UpdateVersionFile writes version at dotPath of a JSON or YAML versioning file, keeping a leading v of the current version,
+// e.g. versioning.file of a single component repository.
+func UpdateVersionFile(filePath, dotPath string, version semver.Version) error {
+	return updateVersionFile(filePath, dotPath, "", version)
+}
+
+func readVersionFromFile(filePath, dotPath, format string) (*semver.Version, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	data, err := parseFileContent(filePath, content)
+	return ParseVersionFile(filePath, content, dotPath, format)
+}
+
+// ParseVersionFile reads the version from a versioning file content, the file
+// extension defines if content is parsed as JSON or YAML. The version is extracted
+// with format regex, see MonorepoConfig.VersionFormat.
+func ParseVersionFile(filePath string, content []byte, dotPath, format string) (*semver.Version, error) {
+	vstr, err := readStringByPath(filePath, content, dotPath)
 	if err != nil {
 		return nil, err
 	}
+	_, version, _, err := splitVersionString(vstr, format)
+	if err != nil {
+		return nil, fmt.Errorf("path %q: %v", dotPath, err)
+	}
+	v, err := ToVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("path %q: invalid semver %q: %v", dotPath, vstr, err)
+	}
+	return v, nil
+}
+
+// splitVersionString splits a version string of a versioning file in prefix, version and suffix.
+// Without format only a leading v is kept as prefix.
+func splitVersionString(value, format string) (string, string, string, error) {
+	if format == "" {
+		if strings.HasPrefix(value, "v") || strings.HasPrefix(value, "V") {
+			return value[:1], value[1:], "", nil
+		}
+		return "", value, "", nil
+	}
+	r, err := versionFormatRegex(format)
+	if err != nil {
+		return "", "", "", err
+	}
+	match := r.FindStringSubmatchIndex(value)
+	if match == nil || match[2] < 0 {
+		return "", "", "", fmt.Errorf("version %q does not match monorepo.version-format %s", value, format)
+	}
+	return value[:match[2]], value[match[2]:match[3]], value[match[3]:], nil
+}
+
+func versionFormatRegex(format string) (*regexp.Regexp, error) {
+	r, err := regexp.Compile(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid monorepo.version-format regex: %s, error: %v", format, err)
+	}
+	if r.NumSubexp() == 0 {
+		return nil, fmt.Errorf("invalid monorepo.version-format regex: %s, a capture group for the version is required", format)
+	}
+	return r, nil
+}
+
+func readStringByPath(filePath string, content []byte, dotPath string) (string, error) {
+	data, err := parseFileContent(filePath, content)
+	if err != nil {
+		return "", err
+	}
 	segments, err := parsePath(dotPath)
 	if err != nil {
-		return nil, fmt.Errorf("invalid path %q: %v", dotPath, err)
+		return "", fmt.Errorf("invalid path %q: %v", dotPath, err)
 	}
 	raw, err := getByPath(data, segments)
 	if err != nil {
-		return nil, fmt.Errorf("path %q: %v", dotPath, err)
+		return "", fmt.Errorf("path %q: %v", dotPath, err)
 	}
-	vstr, ok := raw.(string)
+	value, ok := raw.(string)
 	if !ok {
-		return nil, fmt.Errorf("path %q: value is not a string", dotPath)
+		return "", fmt.Errorf("path %q: value is not a string", dotPath)
 	}
-	v, err := ToVersion(vstr)
-	if err != nil {
-		return nil, fmt.Errorf("path %q: invalid semver %q: %v", dotPath, vstr, err)
-	}
-	return v, nil
+	return value, nil
 }
 
 func writeVersionToFile(filePath, dotPath, version string) error {
@@ -287,3 +808,21 @@ func setByPath(data map[string]interface{}, segments []string, value string) err
 	}
 	return setByPath(nested, segments[1:], value)
 }
+
+// createByPath sets a value in a nested map[string]interface{} creating missing maps.
+func createByPath(data map[string]interface{}, segments []string, value string) error {
+	if len(segments) == 1 {
+		data[segments[0]] = value
+		return nil
+	}
+	val, ok := data[segments[0]]
+	if !ok {
+		val = make(map[string]interface{})
+		data[segments[0]] = val
+	}
+	nested, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("value at %q is not a map", segments[0])
+	}
+	return createByPath(nested, segments[1:], value)
+}
@@ -3,7 +3,12 @@ package sv
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
 )
 
 // ---- parsePath tests ----
@@ -236,12 +241,12 @@ func TestSetByPath(t *testing.T) {
 func TestReadVersionFromFile(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		name     string
-		content  string
-		ext      string
-		dotPath  string
-		want     string
-		wantErr  bool
+		name    string
+		content string
+		ext     string
+		dotPath string
+		want    string
+		wantErr bool
 	}{
 		{
 			name:    "simple yaml",
@@ -317,7 +322,7 @@ func TestReadVersionFromFile(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			got, err := readVersionFromFile(f.Name(), tt.dotPath)
+			got, err := readVersionFromFile(f.Name(), tt.dotPath, "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("readVersionFromFile() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -391,7 +396,7 @@ func TestWriteVersionToFile(t *testing.T) {
 				return
 			}
 
-			got, rerr := readVersionFromFile(fpath, tt.dotPath)
+			got, rerr := readVersionFromFile(fpath, tt.dotPath, "")
 			if rerr != nil {
 				t.Fatalf("readVersionFromFile() after write failed: %v", rerr)
 			}
@@ -402,6 +407,222 @@ func TestWriteVersionToFile(t *testing.T) {
 	}
 }
 
+func TestMonorepoProcessorImpl_UpdateVersion_KeepsFormat(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"bare version", "version: 1.2.3\n", "", "version: 1.3.0\n", false},
+		{"v prefix", "version: v1.2.3\n", "", "version: v1.3.0\n", false},
+		{"format with prefix and suffix", "version: release-1.2.3-final\n", "^release-(.+)-final$", "version: release-1.3.0-final\n", false},
+		{"format not matching", "version: 1.2.3\n", "^release-(.+)$", "", true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			fpath := filepath.Join(dir, "api", "version.yml")
+			if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(fpath, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			cfg := MonorepoConfig{VersioningFile: "*/version.yml", Path: "version", VersionFormat: tt.format}
+			p := NewMonorepoProcessor()
+
+			components, _, err := p.FindComponents(dir, cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindComponents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := components[0].CurrentVersion.String(); got != "1.2.3" {
+				t.Errorf("FindComponents() version = %s, want 1.2.3 regardless of file format", got)
+			}
+			if err := p.UpdateVersion(components[0], *semver.MustParse("1.3.0"), cfg); err != nil {
+				t.Fatalf("UpdateVersion() error = %v", err)
+			}
+			if got, _ := os.ReadFile(fpath); string(got) != tt.want {
+				t.Errorf("UpdateVersion() content = %q, want %q", got, tt.want)
+			}
+			components, _, err = p.FindComponents(dir, cfg)
+			if err != nil || components[0].CurrentVersion.String() != "1.3.0" {
+				t.Errorf("FindComponents() after update = %v, %v, want 1.3.0", components, err)
+			}
+		})
+	}
+}
+
+func TestMonorepoConfig_Validate(t *testing.T) {
+	for _, format := range []string{"", "^v?(.+)$"} {
+		if err := (MonorepoConfig{VersionFormat: format}).Validate(); err != nil {
+			t.Errorf("Validate(%q) unexpected error: %v", format, err)
+		}
+	}
+	for _, format := range []string{"^release-.+$", "^(.+"} {
+		if err := (MonorepoConfig{VersionFormat: format}).Validate(); err == nil {
+			t.Errorf("Validate(%q) expected error", format)
+		}
+	}
+}
+
+func TestMonorepoConfig_Validate_Components(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []MonorepoComponentConfig
+		wantErr    bool
+	}{
+		{"max-bump", []MonorepoComponentConfig{{Name: "api", MaxBump: "minor"}, {Name: "web"}}, false},
+		{"without name", []MonorepoComponentConfig{{MaxBump: "patch"}}, true},
+		{"duplicated name", []MonorepoComponentConfig{{Name: "api"}, {Name: "api", MaxBump: "major"}}, true},
+		{"invalid max-bump", []MonorepoComponentConfig{{Name: "api", MaxBump: "none"}}, true},
+		{"previous-paths", []MonorepoComponentConfig{{Name: "billing", PreviousPaths: []string{"services/payments", "payments"}}}, false},
+		{"absolute previous-paths", []MonorepoComponentConfig{{Name: "billing", PreviousPaths: []string{"/services/payments"}}}, true},
+		{"previous-paths outside repository", []MonorepoComponentConfig{{Name: "billing", PreviousPaths: []string{"../payments"}}}, true},
+		{"previous-paths not clean", []MonorepoComponentConfig{{Name: "billing", PreviousPaths: []string{"services/payments/"}}}, true},
+		{"empty previous-paths", []MonorepoComponentConfig{{Name: "billing", PreviousPaths: []string{""}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (MonorepoConfig{Components: tt.components}).Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("MonorepoConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMonorepoConfig_Validate_ComponentDirs(t *testing.T) {
+	for _, dirs := range []string{"", "services/*", "**/cmd/*", "apps/"} {
+		if err := (MonorepoConfig{ComponentDirs: dirs}).Validate(); err != nil {
+			t.Errorf("Validate(%q) unexpected error: %v", dirs, err)
+		}
+	}
+	for _, dirs := range []string{"services/[a", "/services/*", "../services/*", "."} {
+		if err := (MonorepoConfig{ComponentDirs: dirs}).Validate(); err == nil {
+			t.Errorf("Validate(%q) expected error", dirs)
+		}
+	}
+}
+
+func TestMonorepoConfig_YAML_VersioningFiles(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		content   string
+		want      MonorepoConfig
+		wantError bool
+	}{
+		{"glob", "versioning-file: services/*/package.json\npath: version\n", MonorepoConfig{VersioningFile: "services/*/package.json", Path: "version"}, false},
+		{
+			"list",
+			"versioning-file:\n  - glob: services/*/package.json\n    path: version\n  - glob: services/*/k8s/deployment.yaml\n    path: metadata.annotations.version\n",
+			MonorepoConfig{VersioningFile: "services/*/package.json", Path: "version", VersioningFiles: []VersioningFileConfig{
+				{Glob: "services/*/package.json", Path: "version"},
+				{Glob: "services/*/k8s/deployment.yaml", Path: "metadata.annotations.version"},
+			}},
+			false,
+		},
+		{"empty list", "versioning-file: []\n", MonorepoConfig{}, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got MonorepoConfig
+			err := yaml.Unmarshal([]byte(tt.content), &got)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("yaml.Unmarshal() error = %v, wantErr %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("yaml.Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+
+			content, err := yaml.Marshal(got)
+			if err != nil {
+				t.Fatalf("yaml.Marshal() error = %v", err)
+			}
+			var decoded MonorepoConfig
+			if err := yaml.Unmarshal(content, &decoded); err != nil {
+				t.Fatalf("yaml.Unmarshal() of encoded config error = %v", err)
+			}
+			if decoded.VersioningFile != tt.want.VersioningFile || decoded.Path != tt.want.Path || !reflect.DeepEqual(decoded.VersioningFiles, tt.want.VersioningFiles) {
+				t.Errorf("encoded config %s decoded = %+v, want %+v", content, decoded, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonorepoProcessorImpl_SecondaryFiles(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	files := map[string]string{
+		"services/api/package.json":             `{"version": "1.2.3"}`,
+		"services/api/k8s/deployment.yaml":      "metadata:\n    version: v1.2.3\n",
+		"services/billing/package.json":         `{"version": "2.0.0"}`,
+		"services/billing/k8s/deployment.yaml":  "metadata:\n    version: v1.9.0\n",
+		"services/billing/k8s/deployment2.yaml": "metadata:\n    version: v1.9.0\n",
+	}
+	for name, content := range files {
+		fpath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fpath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := MonorepoConfig{VersioningFile: "services/*/package.json", Path: "version", VersioningFiles: []VersioningFileConfig{
+		{Glob: "services/*/package.json"},
+		{Glob: "services/*/k8s/deployment.yaml", Path: "metadata.version"},
+	}}
+	p := NewMonorepoProcessor()
+
+	components, _, err := p.FindComponents(root, cfg)
+	if err != nil {
+		t.Fatalf("FindComponents() error = %v", err)
+	}
+	if len(components) != 2 || len(components[0].SecondaryFiles) != 1 || len(components[1].SecondaryFiles) != 1 {
+		t.Fatalf("FindComponents() = %+v, want 2 components with 1 secondary file each", components)
+	}
+	billing := components[1]
+	if got := billing.SecondaryFiles[0].Version.String(); got != "1.9.0" || !billing.CurrentVersion.Equal(semver.MustParse("2.0.0")) {
+		t.Errorf("FindComponents() billing versions = %s and %s, want 2.0.0 read from authoritative file and 1.9.0", billing.CurrentVersion, got)
+	}
+	if got := billing.Files(); !reflect.DeepEqual(got, []string{filepath.Join(root, "services", "billing", "package.json"), filepath.Join(root, "services", "billing", "k8s", "deployment.yaml")}) {
+		t.Errorf("MonorepoComponent.Files() = %v", got)
+	}
+
+	if err := p.UpdateVersion(billing, *semver.MustParse("2.1.0"), cfg); err != nil {
+		t.Fatalf("UpdateVersion() error = %v", err)
+	}
+	for name, want := range map[string]string{
+		"services/billing/package.json":         "{\n  \"version\": \"2.1.0\"\n}\n",
+		"services/billing/k8s/deployment.yaml":  "metadata:\n    version: v2.1.0\n",
+		"services/billing/k8s/deployment2.yaml": "metadata:\n    version: v1.9.0\n",
+	} {
+		if got, _ := os.ReadFile(filepath.Join(root, filepath.FromSlash(name))); string(got) != want {
+			t.Errorf("UpdateVersion() %s = %q, want %q", name, got, want)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(root, "services", "api", "package.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := p.FindComponents(root, cfg); err == nil || !strings.Contains(err.Error(), "no authoritative versioning file") {
+		t.Errorf("FindComponents() without authoritative file error = %v, want missing authoritative file", err)
+	}
+}
+
 // ---- FindComponents tests ----
 
 func TestFindComponents(t *testing.T) {
@@ -433,10 +654,11 @@ func TestFindComponents(t *testing.T) {
 	cfg := MonorepoConfig{
 		VersioningFile: "templates/*/template.yml",
 		Path:           "version",
+		Components:     []MonorepoComponentConfig{{Name: "templates/beta", MaxBump: "minor"}},
 	}
 
 	proc := NewMonorepoProcessor()
-	components, err := proc.FindComponents(root, cfg)
+	components, _, err := proc.FindComponents(root, cfg)
 	if err != nil {
 		t.Fatalf("FindComponents() error = %v", err)
 	}
@@ -450,9 +672,9 @@ func TestFindComponents(t *testing.T) {
 		byName[c.Name] = c
 	}
 
-	for _, tc := range []struct{ name, version string }{
-		{"alpha", "1.0.0"},
-		{"beta", "2.3.4"},
+	for _, tc := range []struct{ name, version, maxBump string }{
+		{"templates/alpha", "1.0.0", ""},
+		{"templates/beta", "2.3.4", "minor"},
 	} {
 		c, ok := byName[tc.name]
 		if !ok {
@@ -462,10 +684,13 @@ func TestFindComponents(t *testing.T) {
 		if c.CurrentVersion.Original() != tc.version {
 			t.Errorf("component %q version = %v, want %v", tc.name, c.CurrentVersion.Original(), tc.version)
 		}
-		wantRoot := filepath.Join(root, "templates", tc.name)
+		wantRoot := filepath.Join(root, tc.name)
 		if c.RootPath != wantRoot {
 			t.Errorf("component %q RootPath = %v, want %v", tc.name, c.RootPath, wantRoot)
 		}
+		if c.MaxBump != tc.maxBump {
+			t.Errorf("component %q MaxBump = %q, want %q", tc.name, c.MaxBump, tc.maxBump)
+		}
 	}
 }
 
@@ -477,7 +702,7 @@ func TestFindComponents_NoMatch(t *testing.T) {
 		Path:           "version",
 	}
 	proc := NewMonorepoProcessor()
-	_, err := proc.FindComponents(root, cfg)
+	_, _, err := proc.FindComponents(root, cfg)
 	if err == nil {
 		t.Error("FindComponents() expected error for no matches, got nil")
 	}
@@ -486,8 +711,335 @@ func TestFindComponents_NoMatch(t *testing.T) {
 func TestFindComponents_EmptyConfig(t *testing.T) {
 	t.Parallel()
 	proc := NewMonorepoProcessor()
-	_, err := proc.FindComponents(t.TempDir(), MonorepoConfig{})
+	_, _, err := proc.FindComponents(t.TempDir(), MonorepoConfig{})
 	if err == nil {
 		t.Error("FindComponents() expected error for empty config, got nil")
 	}
 }
+
+func TestFindComponents_RecursiveGlob(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, dir := range []string{
+		"services/api",
+		"services/billing/api",
+		"services/billing/internal/worker",
+		"services/web/node_modules/dep",
+		"services/testdata/fixture",
+		"other/api",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "package.json"), []byte(`{"version": "1.0.0"}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		exclude []string
+		want    []string
+	}{
+		{"recursive", "services/**/package.json", []string{"node_modules", "testdata"}, []string{"services/api", "services/billing/api", "services/billing/internal/worker"}},
+		{"recursive without exclude", "services/**/package.json", nil, []string{"services/api", "services/billing/api", "services/billing/internal/worker", "services/testdata/fixture", "services/web/node_modules/dep"}},
+		{"exclude path", "**/package.json", []string{"services/billing", "node_modules", "testdata"}, []string{"other/api", "services/api"}},
+		{"recursive middle segment", "**/api/package.json", nil, []string{"other/api", "services/api", "services/billing/api"}},
+		{"single level", "services/*/package.json", nil, []string{"services/api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components, _, err := NewMonorepoProcessor().FindComponents(root, MonorepoConfig{VersioningFile: tt.pattern, Path: "version", Exclude: tt.exclude})
+			if err != nil {
+				t.Fatalf("FindComponents() error = %v", err)
+			}
+			var got []string
+			for _, c := range components {
+				rel, _ := filepath.Rel(root, c.RootPath)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindComponents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindComponents_InvalidPattern(t *testing.T) {
+	t.Parallel()
+	_, _, err := NewMonorepoProcessor().FindComponents(t.TempDir(), MonorepoConfig{VersioningFile: "services/[/package.json", Path: "version"})
+	if err == nil {
+		t.Error("FindComponents() expected error for invalid pattern, got nil")
+	}
+}
+
+func TestFindComponents_NamePath(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeComponent := func(dir, content string) {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "catalog.yml"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeComponent("billing/api", "version: 1.0.0\nmetadata:\n  name: billing-api\n")
+	writeComponent("payments/api", "version: 2.0.0\nmetadata:\n  name: payments-api\n")
+
+	tests := []struct {
+		name     string
+		namePath string
+		want     []string
+	}{
+		{"relative directory", "", []string{"billing/api", "payments/api"}},
+		{"name path", "metadata.name", []string{"billing-api", "payments-api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components, _, err := NewMonorepoProcessor().FindComponents(root, MonorepoConfig{VersioningFile: "*/api/catalog.yml", Path: "version", NamePath: tt.namePath})
+			if err != nil {
+				t.Fatalf("FindComponents() error = %v", err)
+			}
+			var got []string
+			for _, c := range components {
+				got = append(got, c.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindComponents() names = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindComponents_DuplicateName(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, dir := range []string{"billing/api", "payments/api"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "catalog.yml"), []byte("version: 1.0.0\nname: api\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, _, err := NewMonorepoProcessor().FindComponents(root, MonorepoConfig{VersioningFile: "*/api/catalog.yml", Path: "version", NamePath: "name"})
+	if err == nil {
+		t.Fatal("FindComponents() expected error for duplicate names, got nil")
+	}
+	for _, path := range []string{filepath.Join("billing", "api"), filepath.Join("payments", "api")} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("FindComponents() error = %v, want conflicting path %s", err, path)
+		}
+	}
+}
+
+func TestFindComponents_OnParseError(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"alpha/package.json": `{"version": "1.0.0"}`,
+		"beta/package.json":  `{"version": "invalid"}`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		mode        string
+		wantNames   []string
+		wantSkipped int
+		wantErr     bool
+	}{
+		{"default fails", "", nil, 0, true},
+		{"fail", OnParseErrorFail, nil, 0, true},
+		{"skip", OnParseErrorSkip, []string{"alpha"}, 1, false},
+		{"warn", OnParseErrorWarn, []string{"alpha"}, 1, false},
+		{"invalid mode", "ignore", nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components, skipped, err := NewMonorepoProcessor().FindComponents(root, MonorepoConfig{VersioningFile: "*/package.json", Path: "version", OnParseError: tt.mode})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindComponents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, c := range components {
+				got = append(got, c.Name)
+			}
+			if !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("FindComponents() names = %v, want %v", got, tt.wantNames)
+			}
+			if len(skipped) != tt.wantSkipped {
+				t.Errorf("FindComponents() skipped = %v, want %d", skipped, tt.wantSkipped)
+			}
+			if len(skipped) > 0 && skipped[0].Path != filepath.Join(root, "beta", "package.json") {
+				t.Errorf("FindComponents() skipped path = %s, want beta/package.json", skipped[0].Path)
+			}
+		})
+	}
+}
+
+func TestFindComponents_OnParseErrorAllInvalid(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "alpha"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "alpha", "package.json"), []byte(`{"name": "alpha"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := NewMonorepoProcessor().FindComponents(root, MonorepoConfig{VersioningFile: "*/package.json", Path: "version", OnParseError: OnParseErrorSkip})
+	if err == nil {
+		t.Error("FindComponents() expected error when all components are invalid, got nil")
+	}
+}
+
+func TestComponentVersioningFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     MonorepoConfig
+		dir     string
+		want    string
+		wantErr bool
+	}{
+		{"single level", MonorepoConfig{VersioningFile: "services/*/package.json"}, "services/billing", "services/billing/package.json", false},
+		{"recursive", MonorepoConfig{VersioningFile: "**/version.yml"}, "libs/go/billing", "libs/go/billing/version.yml", false},
+		{"not matching pattern", MonorepoConfig{VersioningFile: "services/*/package.json"}, "libs/billing", "", true},
+		{"excluded", MonorepoConfig{VersioningFile: "**/version.yml", Exclude: []string{"vendor"}}, "vendor/billing", "", true},
+		{"outside repository", MonorepoConfig{VersioningFile: "**/version.yml"}, "../billing", "", true},
+		{"wildcard file name", MonorepoConfig{VersioningFile: "services/*/*.json"}, "services/billing", "", true},
+		{"not configured", MonorepoConfig{}, "services/billing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComponentVersioningFile(tt.cfg, tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ComponentVersioningFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ComponentVersioningFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateVersioningFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "billing", "package.json")
+	cfg := MonorepoConfig{Path: "version"}
+	if err := CreateVersioningFile(filePath, cfg, "billing", "0.1.0"); err != nil {
+		t.Fatalf("CreateVersioningFile() error = %v", err)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"version\": \"0.1.0\"\n}\n"; string(content) != want {
+		t.Errorf("CreateVersioningFile() content = %q, want %q", content, want)
+	}
+	if err := CreateVersioningFile(filePath, cfg, "billing", "0.2.0"); err == nil {
+		t.Error("CreateVersioningFile() expected error for existing file, got nil")
+	}
+}
+
+func TestWithoutIgnoredPaths(t *testing.T) {
+	commit := func(hash string, files ...string) GitCommitLog {
+		return GitCommitLog{Hash: hash, Files: files}
+	}
+	commits := []GitCommitLog{
+		commit("docs", "docs/index.md", "docs/img/logo.png"),
+		commit("workflow", ".github/workflows/ci.yml"),
+		commit("readme", "README.md", "services/a/README.md"),
+		commit("mixed", "docs/index.md", "main.go"),
+		commit("component", "services/a/main.go", "docs/a.md"),
+		commit("component docs", "services/a/docs/usage.txt", "services/b/main.go"),
+		commit("merge"),
+	}
+	hashes := func(commits []GitCommitLog) []string {
+		result := []string{}
+		for _, c := range commits {
+			result = append(result, c.Hash)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		ignore []string
+		paths  []string
+		want   []string
+	}{
+		{"no ignore paths", nil, nil, hashes(commits)},
+		{"directories and names", []string{"docs/**", ".github/", "*.md"}, nil, []string{"mixed", "component", "component docs", "merge"}},
+		{"name matches at any level", []string{"docs"}, nil, []string{"workflow", "readme", "mixed", "component", "component docs", "merge"}},
+		{"component path and not ignored", []string{"docs", "*.md"}, []string{"services/a"}, []string{"component", "merge"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashes(WithoutIgnoredPaths(commits, tt.ignore, tt.paths)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithoutIgnoredPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentDirs(t *testing.T) {
+	root := t.TempDir()
+	for file, content := range map[string]string{
+		"services/alpha/version.yml":  "version: 1.0.0\n",
+		"services/beta/README.md":     "beta\n",
+		"services/broken/version.yml": "version: abc\n",
+		"services/vendor/version.yml": "version: 2.0.0\n",
+		"libs/gamma/version.yml":      "version: 3.0.0\n",
+	} {
+		fpath := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fpath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := MonorepoConfig{VersioningFile: "**/version.yml", Path: "version", ComponentDirs: "services/*", Exclude: []string{"vendor"}}
+
+	got, err := ComponentDirs(root, cfg)
+	if err != nil {
+		t.Fatalf("ComponentDirs() error = %v", err)
+	}
+	var summary []string
+	for _, dir := range got {
+		switch {
+		case dir.Missing():
+			summary = append(summary, dir.Dir+" missing "+dir.VersioningFile)
+		case dir.Err != nil:
+			summary = append(summary, dir.Dir+" invalid")
+		default:
+			summary = append(summary, dir.Dir+" "+dir.Component.Name+" "+dir.Component.CurrentVersion.String())
+		}
+	}
+	want := []string{"services/alpha services/alpha 1.0.0", "services/beta missing services/beta/version.yml", "services/broken invalid"}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("ComponentDirs() = %v, want %v", summary, want)
+	}
+
+	if _, err := ComponentDirs(root, MonorepoConfig{VersioningFile: "**/version.yml"}); err == nil {
+		t.Error("ComponentDirs() expected error without component-dirs")
+	}
+	if _, err := ComponentDirs(root, MonorepoConfig{VersioningFile: "libs/*/version.yml", ComponentDirs: "services/*"}); err == nil {
+		t.Error("ComponentDirs() expected error for directories not matching versioning-file")
+	}
+}
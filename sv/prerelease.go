@@ -0,0 +1,118 @@
+package sv
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var prereleaseIdentifierRegex = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// PrereleaseChannel returns the prerelease identifier mapped to branch by monorepo.prerelease.branch-map, empty if branch is not mapped.
+// Branch regexes must match the whole branch name and a branch cannot match patterns with different identifiers.
+func PrereleaseChannel(branchMap map[string]string, branch string) (string, error) {
+	patterns := make([]string, 0, len(branchMap))
+	for pattern := range branchMap {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	channel, channelPattern := "", ""
+	for _, pattern := range patterns {
+		matched, err := matchBranch([]string{pattern}, branch)
+		if err != nil {
+			return "", fmt.Errorf("invalid branch regex %s on monorepo.prerelease.branch-map, message: %v", pattern, err)
+		}
+		if !matched {
+			continue
+		}
+		identifier := branchMap[pattern]
+		if !prereleaseIdentifierRegex.MatchString(identifier) {
+			return "", fmt.Errorf("invalid prerelease identifier %q for branch regex %s, use only alphanumerics and hyphens", identifier, pattern)
+		}
+		if channel != "" && channel != identifier {
+			return "", fmt.Errorf("branch %s matches prerelease channels %s (%s) and %s (%s)", branch, channel, channelPattern, identifier, pattern)
+		}
+		channel, channelPattern = identifier, pattern
+	}
+	return channel, nil
+}
+
+// StableTags returns component tags whose versions are not prereleases, tags with invalid versions are ignored.
+func StableTags(tags []GitTag, componentPath string) []GitTag {
+	var result []GitTag
+	for _, tag := range tags {
+		if version, err := componentTagVersion(tag); err == nil && version.Prerelease() == "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// StableBaseVersion returns the version used to calculate the next stable version of a component whose current version is
+// a prerelease: the highest stable tag version, or 0.0.0 if the component has no stable tag.
+func StableBaseVersion(tags []GitTag, componentPath string) *semver.Version {
+	base := semver.MustParse("0.0.0")
+	for _, tag := range StableTags(tags, componentPath) {
+		if version, _ := componentTagVersion(tag); version.GreaterThan(base) {
+			base = version
+		}
+	}
+	return base
+}
+
+// NextPrerelease returns version on channel numbered after the highest channel tag of the same version, e.g. 1.4.0-beta.3
+// after component/v1.4.0-beta.2, and the name of that tag, empty if there is none.
+func NextPrerelease(version semver.Version, channel string, tags []GitTag, componentPath string) (semver.Version, string) {
+	last, lastTag := 0, ""
+	for _, tag := range tags {
+		tagVersion, err := componentTagVersion(tag)
+		if err != nil || tagVersion.Major() != version.Major() || tagVersion.Minor() != version.Minor() || tagVersion.Patch() != version.Patch() {
+			continue
+		}
+		if n, ok := channelNumber(tagVersion.Prerelease(), channel); ok && n > last {
+			last, lastTag = n, tag.Name
+		}
+	}
+	next := semver.New(version.Major(), version.Minor(), version.Patch(), fmt.Sprintf("%s.%d", channel, last+1), "")
+	return *next, lastTag
+}
+
+// LatestPrerelease returns the prerelease tag with the highest version of component, only tags of channel are used if it's not empty.
+// Returns an empty tag if there is none.
+func LatestPrerelease(tags []GitTag, componentPath, channel string) (string, *semver.Version) {
+	var latest string
+	var latestVersion *semver.Version
+	for _, tag := range tags {
+		version, err := componentTagVersion(tag)
+		if err != nil || version.Prerelease() == "" {
+			continue
+		}
+		if channel != "" {
+			if _, ok := channelNumber(version.Prerelease(), channel); !ok {
+				continue
+			}
+		}
+		if latestVersion == nil || version.GreaterThan(latestVersion) {
+			latest, latestVersion = tag.Name, version
+		}
+	}
+	return latest, latestVersion
+}
+
+// channelNumber returns N of prerelease "<channel>.N".
+func channelNumber(prerelease, channel string) (int, bool) {
+	if !strings.HasPrefix(prerelease, channel+".") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(prerelease, channel+"."))
+	return n, err == nil && n > 0
+}
+
+func componentTagVersion(tag GitTag) (*semver.Version, error) {
+	return ToVersion(ComponentTagVersion(tag.Name))
+}
@@ -0,0 +1,99 @@
+package sv
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var prereleaseTags = []GitTag{
+	{Name: "alpha/v1.3.0"},
+	{Name: "alpha/v1.4.0-beta.1"},
+	{Name: "alpha/v1.4.0-beta.2"},
+	{Name: "alpha/v1.4.0-beta.10"},
+	{Name: "alpha/v1.4.0-rc.1"},
+	{Name: "alpha/v1.2.0"},
+	{Name: "alpha/v1.5.0-beta"},
+}
+
+func TestPrereleaseChannel(t *testing.T) {
+	branchMap := map[string]string{"develop": "beta", "release/.*": "rc", "release/1\\..*": "rc"}
+	tests := []struct {
+		name      string
+		branchMap map[string]string
+		branch    string
+		want      string
+		wantErr   bool
+	}{
+		{"mapped branch", branchMap, "develop", "beta", false},
+		{"same identifier on many patterns", branchMap, "release/1.4", "rc", false},
+		{"must match whole branch", branchMap, "feature/develop", "", false},
+		{"detached", branchMap, "", "", false},
+		{"no map", nil, "develop", "", false},
+		{"conflicting identifiers", map[string]string{"dev.*": "beta", "develop": "alpha"}, "develop", "", true},
+		{"invalid identifier", map[string]string{"develop": "beta.1"}, "develop", "", true},
+		{"invalid regex", map[string]string{"(": "beta"}, "develop", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrereleaseChannel(tt.branchMap, tt.branch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PrereleaseChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PrereleaseChannel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStableBaseVersion(t *testing.T) {
+	if got := StableBaseVersion(prereleaseTags, "alpha"); got.String() != "1.3.0" {
+		t.Errorf("StableBaseVersion() = %s, want 1.3.0", got)
+	}
+	if got := StableBaseVersion([]GitTag{{Name: "alpha/v1.4.0-beta.1"}}, "alpha"); got.String() != "0.0.0" {
+		t.Errorf("StableBaseVersion() without stable tags = %s, want 0.0.0", got)
+	}
+}
+
+func TestNextPrerelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		channel     string
+		want        string
+		wantLastTag string
+	}{
+		{"numbered after highest", "1.4.0", "beta", "1.4.0-beta.11", "alpha/v1.4.0-beta.10"},
+		{"other channel", "1.4.0", "rc", "1.4.0-rc.2", "alpha/v1.4.0-rc.1"},
+		{"first of version", "1.5.0", "beta", "1.5.0-beta.1", ""},
+		{"metadata is removed", "2.0.0+build.1", "beta", "2.0.0-beta.1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, lastTag := NextPrerelease(*semver.MustParse(tt.version), tt.channel, prereleaseTags, "alpha")
+			if got.String() != tt.want || lastTag != tt.wantLastTag {
+				t.Errorf("NextPrerelease() = %s, %q, want %s, %q", got.String(), lastTag, tt.want, tt.wantLastTag)
+			}
+		})
+	}
+}
+
+func TestLatestPrerelease(t *testing.T) {
+	tests := []struct {
+		channel string
+		want    string
+	}{
+		{"", "alpha/v1.5.0-beta"},
+		{"beta", "alpha/v1.4.0-beta.10"},
+		{"rc", "alpha/v1.4.0-rc.1"},
+		{"alpha", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			if got, _ := LatestPrerelease(prereleaseTags, "alpha", tt.channel); got != tt.want {
+				t.Errorf("LatestPrerelease() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
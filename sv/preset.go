@@ -0,0 +1,118 @@
+package sv
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SelectPresets returns the names of commit-message presets matching files, presets are matched by branch only if files is empty.
+// Names are sorted, more than one name means the files span more than one repository area.
+func SelectPresets(presets map[string]CommitMessagePresetConfig, files []string, branch string) ([]string, error) {
+	var names []string
+	for name, preset := range presets {
+		var matched bool
+		if len(files) > 0 {
+			matched = matchPathPrefix(preset.Paths, files)
+		} else {
+			m, err := matchBranch(preset.Branches, branch)
+			if err != nil {
+				return nil, fmt.Errorf("invalid branch regex on commit-message preset %s, message: %v", name, err)
+			}
+			matched = m
+		}
+		if matched {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func matchPathPrefix(prefixes, files []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(path.Clean(strings.TrimPrefix(prefix, "./")), "/")
+		for _, file := range files {
+			if prefix == "." || file == prefix || strings.HasPrefix(file, prefix+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchBranch(patterns []string, branch string) (bool, error) {
+	if branch == "" {
+		return false, nil
+	}
+	for _, pattern := range patterns {
+		r, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return false, err
+		}
+		if r.MatchString(branch) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// WithPresets returns a copy of commit message config with preset rules applied. When more than one preset is given the strictest
+// rules are used: only types and scope values allowed by every preset, scope required by any preset and footers required by any preset.
+func (c CommitMessageConfig) WithPresets(names ...string) (CommitMessageConfig, error) {
+	result := c
+	result.RequiredFooters = append([]string(nil), c.RequiredFooters...)
+
+	var types, scopes []string
+	var typesSet, scopesSet bool
+	for _, name := range names {
+		preset, ok := c.Presets[name]
+		if !ok {
+			return CommitMessageConfig{}, fmt.Errorf("commit-message preset %s not found", name)
+		}
+		if len(preset.Types) > 0 {
+			types, typesSet = intersect(types, preset.Types, typesSet), true
+		}
+		if preset.Scope != nil {
+			if len(preset.Scope.Values) > 0 {
+				scopes, scopesSet = intersect(scopes, preset.Scope.Values, scopesSet), true
+			}
+			result.Scope.Required = result.Scope.Required || preset.Scope.Required
+		}
+		for _, footer := range preset.RequiredFooters {
+			if !contains(footer, result.RequiredFooters) {
+				result.RequiredFooters = append(result.RequiredFooters, footer)
+			}
+		}
+	}
+
+	if typesSet {
+		if len(types) == 0 {
+			return CommitMessageConfig{}, fmt.Errorf("commit-message presets %s have no type in common", strings.Join(names, ", "))
+		}
+		result.Types = types
+	}
+	if scopesSet {
+		if len(scopes) == 0 {
+			return CommitMessageConfig{}, fmt.Errorf("commit-message presets %s have no scope in common", strings.Join(names, ", "))
+		}
+		result.Scope.Values = scopes
+	}
+	return result, nil
+}
+
+// intersect returns values of current also in values, values are returned if current is not set yet.
+func intersect(current, values []string, set bool) []string {
+	if !set {
+		return append([]string(nil), values...)
+	}
+	var result []string
+	for _, v := range current {
+		if contains(v, values) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package sv
+
+import (
+	"reflect"
+	"testing"
+)
+
+var presetsCfg = CommitMessageConfig{
+	Types: []string{"feat", "fix", "docs", "chore"},
+	Scope: CommitMessageScopeConfig{Values: []string{"api", "infra", "docs"}},
+	Presets: map[string]CommitMessagePresetConfig{
+		"infra": {Paths: []string{"infra/"}, Branches: []string{"infra/.*"}, Types: []string{"fix", "chore"},
+			Scope: &CommitMessageScopeConfig{Values: []string{"infra"}, Required: true}, RequiredFooters: []string{"Risk"}},
+		"docs": {Paths: []string{"./docs", "README.md"}, Branches: []string{"docs-.*"}, Types: []string{"docs", "chore"},
+			RequiredFooters: []string{"Reviewed-by"}},
+	},
+}
+
+func TestSelectPresets(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		branch  string
+		want    []string
+		wantErr bool
+	}{
+		{"single area", []string{"infra/main.tf"}, "docs-fix", []string{"infra"}, false},
+		{"file prefix", []string{"README.md"}, "", []string{"docs"}, false},
+		{"similar directory", []string{"infrastructure/main.tf", "docsite/index.html"}, "infra/x", nil, false},
+		{"two areas", []string{"docs/index.md", "infra/main.tf", "go.mod"}, "", []string{"docs", "infra"}, false},
+		{"by branch without files", nil, "infra/network", []string{"infra"}, false},
+		{"branch must fully match", nil, "feature/infra/network", nil, false},
+		{"no files and no branch", nil, "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectPresets(presetsCfg.Presets, tt.files, tt.branch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectPresets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectPresets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectPresets_InvalidBranchRegex(t *testing.T) {
+	presets := map[string]CommitMessagePresetConfig{"invalid": {Branches: []string{"("}}}
+	if _, err := SelectPresets(presets, nil, "main"); err == nil {
+		t.Error("SelectPresets() expected error for invalid branch regex, got nil")
+	}
+}
+
+func TestCommitMessageConfig_WithPresets(t *testing.T) {
+	tests := []struct {
+		name         string
+		presets      []string
+		wantTypes    []string
+		wantScopes   []string
+		wantRequired bool
+		wantFooters  []string
+		wantErr      bool
+	}{
+		{"no preset", nil, presetsCfg.Types, presetsCfg.Scope.Values, false, nil, false},
+		{"infra", []string{"infra"}, []string{"fix", "chore"}, []string{"infra"}, true, []string{"Risk"}, false},
+		{"docs keeps scope", []string{"docs"}, []string{"docs", "chore"}, presetsCfg.Scope.Values, false, []string{"Reviewed-by"}, false},
+		{"strictest of both", []string{"docs", "infra"}, []string{"chore"}, []string{"infra"}, true, []string{"Reviewed-by", "Risk"}, false},
+		{"unknown preset", []string{"web"}, nil, nil, false, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := presetsCfg.WithPresets(tt.presets...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CommitMessageConfig.WithPresets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.Types, tt.wantTypes) || !reflect.DeepEqual(got.Scope.Values, tt.wantScopes) ||
+				got.Scope.Required != tt.wantRequired || !reflect.DeepEqual(got.RequiredFooters, tt.wantFooters) {
+				t.Errorf("CommitMessageConfig.WithPresets() = types %v, scopes %v, required %v, footers %v, want %v, %v, %v, %v",
+					got.Types, got.Scope.Values, got.Scope.Required, got.RequiredFooters, tt.wantTypes, tt.wantScopes, tt.wantRequired, tt.wantFooters)
+			}
+		})
+	}
+}
+
+func TestCommitMessageConfig_WithPresets_NoCommonType(t *testing.T) {
+	cfg := CommitMessageConfig{Presets: map[string]CommitMessagePresetConfig{
+		"a": {Types: []string{"feat"}},
+		"b": {Types: []string{"fix"}},
+	}}
+	if _, err := cfg.WithPresets("a", "b"); err == nil {
+		t.Error("CommitMessageConfig.WithPresets() expected error for presets without common type, got nil")
+	}
+}
@@ -1,6 +1,9 @@
 package sv
 
 import (
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -8,12 +11,22 @@ import (
 
 // ReleaseNoteProcessor release note processor interface.
 type ReleaseNoteProcessor interface {
-	Create(version *semver.Version, tag string, date time.Time, commits []GitCommitLog) ReleaseNote
+	Create(version *semver.Version, tag, previousTag string, date time.Time, commits []GitCommitLog) ReleaseNote
 }
 
+const (
+	// URLPlaceholderTag placeholder replaced by the release tag on release-notes URL templates, HEAD for unreleased versions.
+	URLPlaceholderTag = "{tag}"
+	// URLPlaceholderPreviousTag placeholder replaced by the previous release tag on release-notes.compare-url-template.
+	URLPlaceholderPreviousTag = "{previous-tag}"
+	// URLPlaceholderPR placeholder replaced by the pull request number on release-notes.pr-url-template.
+	URLPlaceholderPR = "{pr}"
+)
+
 // ReleaseNoteProcessorImpl release note based on commit log.
 type ReleaseNoteProcessorImpl struct {
-	cfg ReleaseNotesConfig
+	cfg          ReleaseNotesConfig
+	typeSettings map[string]CommitTypeSettings
 }
 
 // NewReleaseNoteProcessor ReleaseNoteProcessor constructor.
@@ -21,34 +34,171 @@ func NewReleaseNoteProcessor(cfg ReleaseNotesConfig) *ReleaseNoteProcessorImpl {
 	return &ReleaseNoteProcessorImpl{cfg: cfg}
 }
 
-// Create create a release note based on commits.
-func (p ReleaseNoteProcessorImpl) Create(version *semver.Version, tag string, date time.Time, commits []GitCommitLog) ReleaseNote {
+// SetTypeSettings defines commit-message.type-settings, types with notes attribute are included or excluded from release
+// notes regardless of hidden sections.
+func (p *ReleaseNoteProcessorImpl) SetTypeSettings(settings map[string]CommitTypeSettings) {
+	p.typeSettings = settings
+}
+
+// Create create a release note based on commits, previousTag is empty for the first release.
+func (p ReleaseNoteProcessorImpl) Create(version *semver.Version, tag, previousTag string, date time.Time, commits []GitCommitLog) ReleaseNote {
 	mapping := commitSectionMapping(p.cfg.Sections)
+	catchAll := p.cfg.catchAllSection()
 
 	sections := make(map[string]ReleaseNoteCommitsSection)
 	authors := make(map[string]struct{})
 	var breakingChanges []string
 	for _, commit := range commits {
+		commit = p.withPullRequestURL(commit)
 		authors[commit.AuthorName] = struct{}{}
-		if sectionCfg, exists := mapping[commit.Message.Type]; exists {
+		sectionCfg, exists := mapping[commit.Message.Type]
+		if !exists && catchAll != nil && commit.Message.Type != "" {
+			sectionCfg, exists = *catchAll, true
+		}
+		if notes := p.typeSettings[commit.Message.Type].Notes; notes != nil {
+			exists, sectionCfg.Hidden = exists && *notes, false
+		}
+		if exists && !sectionCfg.Hidden {
 			section, sexists := sections[sectionCfg.Name]
 			if !sexists {
-				section = ReleaseNoteCommitsSection{Name: sectionCfg.Name, Types: sectionCfg.CommitTypes}
+				section = ReleaseNoteCommitsSection{Name: sectionCfg.Name, Prefix: sectionCfg.Prefix, Types: sectionCfg.CommitTypes}
+			}
+			if !contains(commit.Message.Type, section.Types) {
+				section.Types = append(append([]string(nil), section.Types...), commit.Message.Type)
 			}
 			section.Items = append(section.Items, commit)
 			sections[sectionCfg.Name] = section
 		}
-		if commit.Message.BreakingMessage() != "" {
-			// TODO: if no message found, should use description instead?
-			breakingChanges = append(breakingChanges, commit.Message.BreakingMessage())
+		if message := breakingMessage(commit.Message); message != "" {
+			breakingChanges = append(breakingChanges, message)
 		}
 	}
 
 	var breakingChangeSection ReleaseNoteBreakingChangeSection
 	if bcCfg := p.cfg.sectionConfig(ReleaseNotesSectionTypeBreakingChanges); bcCfg != nil && len(breakingChanges) > 0 {
-		breakingChangeSection = ReleaseNoteBreakingChangeSection{Name: bcCfg.Name, Messages: breakingChanges}
+		breakingChangeSection = ReleaseNoteBreakingChangeSection{Name: bcCfg.Name, Prefix: bcCfg.Prefix, Messages: breakingChanges}
+	}
+	return ReleaseNote{Version: version, Tag: tag, PreviousTag: previousTag, CompareURL: p.compareURL(tag, previousTag), Date: date.Truncate(time.Minute), Sections: p.toReleaseNoteSections(sections, breakingChangeSection), AuthorsNames: authors,
+		CommitCount: len(commits), Contributors: ContributorNames(commits), ShowSummary: p.cfg.ShowSummary}
+}
+
+// breakingMessage returns the BREAKING CHANGE footer of a breaking commit, or its description when marked only by !,
+// as the description describes the breaking change.
+func breakingMessage(msg CommitMessage) string {
+	if !msg.IsBreakingChange {
+		return ""
+	}
+	return str(msg.BreakingMessage(), msg.Description)
+}
+
+// ContributorNames returns the unique authors names of commits sorted case-insensitively. Authors are identified by email,
+// case-insensitive, or by name if commit has no author email. When an author used more than one name, the name of the most recent commit is used.
+func ContributorNames(commits []GitCommitLog) []string {
+	type contributor struct {
+		name      string
+		timestamp int
+	}
+	contributors := make(map[string]contributor)
+	for _, commit := range commits {
+		key := strings.ToLower(commit.AuthorEmail)
+		if key == "" {
+			key = strings.ToLower(commit.AuthorName)
+		}
+		if c, exists := contributors[key]; !exists || commit.Timestamp > c.timestamp {
+			contributors[key] = contributor{name: commit.AuthorName, timestamp: commit.Timestamp}
+		}
+	}
+
+	var names []string
+	for _, c := range contributors {
+		names = append(names, c.name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if a, b := strings.ToLower(names[i]), strings.ToLower(names[j]); a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// withPullRequestURL adds pr-url metadata from release-notes.pr-url-template config if commit has a pull request number.
+func (p ReleaseNoteProcessorImpl) withPullRequestURL(commit GitCommitLog) GitCommitLog {
+	pr := commit.Message.Metadata[prMetadataKey]
+	if pr == "" || p.cfg.PRURLTemplate == "" {
+		return commit
+	}
+	metadata := make(map[string]string, len(commit.Message.Metadata)+1)
+	for k, v := range commit.Message.Metadata {
+		metadata[k] = v
+	}
+	metadata[prURLMetadataKey] = strings.ReplaceAll(p.cfg.PRURLTemplate, URLPlaceholderPR, pr)
+	commit.Message.Metadata = metadata
+	return commit
+}
+
+// compareURL returns the link between previous and current tags, the first release links to the tag tree instead.
+func (p ReleaseNoteProcessorImpl) compareURL(tag, previousTag string) string {
+	if tag == "" {
+		tag = "HEAD"
+	}
+	urlTemplate := p.cfg.CompareURLTemplate
+	if previousTag == "" {
+		urlTemplate = p.cfg.TagURLTemplate
+	}
+	if urlTemplate == "" {
+		return ""
+	}
+	return strings.NewReplacer(URLPlaceholderTag, tag, URLPlaceholderPreviousTag, previousTag).Replace(urlTemplate)
+}
+
+// AnnotateSharedCommits adds shared-with metadata to commits that changed files of other components, used by release notes templates
+// to identify changes shared between components. Roots maps component names to their slash separated directories relative to
+// repository root, commit files must be loaded, see LogRange.WithFiles.
+func AnnotateSharedCommits(commits []GitCommitLog, component string, roots map[string]string) []GitCommitLog {
+	names := make([]string, 0, len(roots))
+	for name := range roots {
+		if name != component {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := make([]GitCommitLog, len(commits))
+	for i, commit := range commits {
+		result[i] = commit
+		var shared []string
+		for _, name := range names {
+			if changedPaths(commit.Files, []string{roots[name]}) {
+				shared = append(shared, name)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		result[i] = withCommitMetadata(commit, sharedWithMetadataKey, strings.Join(shared, ", "))
+	}
+	return result
+}
+
+// RelativeCommitPaths rewrites paths inside dir mentioned on commit descriptions and breaking change messages to be relative to dir,
+// e.g. "fix services/sigma/handler.go" becomes "fix handler.go" with dir services/sigma. Dir is slash separated, relative to repository root.
+func RelativeCommitPaths(commits []GitCommitLog, dir string) []GitCommitLog {
+	dir = strings.Trim(dir, "/")
+	if dir == "" || dir == "." {
+		return commits
+	}
+	r := regexp.MustCompile("(^|[\\s(\\[`'\"])(?:\\./)?" + regexp.QuoteMeta(dir) + "/")
+
+	result := make([]GitCommitLog, len(commits))
+	for i, commit := range commits {
+		commit.Message.Description = r.ReplaceAllString(commit.Message.Description, "$1")
+		if message := commit.Message.BreakingMessage(); message != "" {
+			commit = withCommitMetadata(commit, breakingChangeMetadataKey, r.ReplaceAllString(message, "$1"))
+		}
+		result[i] = commit
 	}
-	return ReleaseNote{Version: version, Tag: tag, Date: date.Truncate(time.Minute), Sections: p.toReleaseNoteSections(sections, breakingChangeSection), AuthorsNames: authors}
+	return result
 }
 
 func (p ReleaseNoteProcessorImpl) toReleaseNoteSections(commitSections map[string]ReleaseNoteCommitsSection, breakingChange ReleaseNoteBreakingChangeSection) []ReleaseNoteSection {
@@ -89,9 +239,14 @@ func commitSectionMapping(sections []ReleaseNotesSectionConfig) map[string]Relea
 type ReleaseNote struct {
 	Version      *semver.Version
 	Tag          string
+	PreviousTag  string
+	CompareURL   string
 	Date         time.Time
 	Sections     []ReleaseNoteSection
 	AuthorsNames map[string]struct{}
+	CommitCount  int      // number of commits included on release
+	Contributors []string // unique authors names sorted case-insensitively, see ContributorNames
+	ShowSummary  bool     // render changes and contributors summary line, from release-notes.show-summary
 }
 
 // ReleaseNoteSection section in release notes.
@@ -103,6 +258,7 @@ type ReleaseNoteSection interface {
 // ReleaseNoteBreakingChangeSection breaking change section.
 type ReleaseNoteBreakingChangeSection struct {
 	Name     string
+	Prefix   string // release-notes.sections prefix, e.g. an emoji
 	Messages []string
 }
 
@@ -118,9 +274,10 @@ func (s ReleaseNoteBreakingChangeSection) SectionName() string {
 
 // ReleaseNoteCommitsSection release note section.
 type ReleaseNoteCommitsSection struct {
-	Name  string
-	Types []string
-	Items []GitCommitLog
+	Name   string
+	Prefix string // release-notes.sections prefix, e.g. an emoji
+	Types  []string
+	Items  []GitCommitLog
 }
 
 // SectionType section type.
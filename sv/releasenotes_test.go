@@ -25,7 +25,7 @@ func TestReleaseNoteProcessorImpl_Create(t *testing.T) {
 			tag:     "v1.0.0",
 			date:    date,
 			commits: []GitCommitLog{commitlog("t1", map[string]string{}, "a")},
-			want:    releaseNote(semver.MustParse("1.0.0"), "v1.0.0", date, []ReleaseNoteSection{newReleaseNoteCommitsSection("Tag 1", []string{"t1"}, []GitCommitLog{commitlog("t1", map[string]string{}, "a")})}, map[string]struct{}{"a": {}}),
+			want:    withSummary(releaseNote(semver.MustParse("1.0.0"), "v1.0.0", date, []ReleaseNoteSection{newReleaseNoteCommitsSection("Tag 1", []string{"t1"}, []GitCommitLog{commitlog("t1", map[string]string{}, "a")})}, map[string]struct{}{"a": {}}), 1, "a"),
 		},
 		{
 			name:    "unmapped tag",
@@ -33,7 +33,7 @@ func TestReleaseNoteProcessorImpl_Create(t *testing.T) {
 			tag:     "v1.0.0",
 			date:    date,
 			commits: []GitCommitLog{commitlog("t1", map[string]string{}, "a"), commitlog("unmapped", map[string]string{}, "a")},
-			want:    releaseNote(semver.MustParse("1.0.0"), "v1.0.0", date, []ReleaseNoteSection{newReleaseNoteCommitsSection("Tag 1", []string{"t1"}, []GitCommitLog{commitlog("t1", map[string]string{}, "a")})}, map[string]struct{}{"a": {}}),
+			want:    withSummary(releaseNote(semver.MustParse("1.0.0"), "v1.0.0", date, []ReleaseNoteSection{newReleaseNoteCommitsSection("Tag 1", []string{"t1"}, []GitCommitLog{commitlog("t1", map[string]string{}, "a")})}, map[string]struct{}{"a": {}}), 2, "a"),
 		},
 		{
 			name:    "breaking changes tag",
@@ -41,7 +41,7 @@ func TestReleaseNoteProcessorImpl_Create(t *testing.T) {
 			tag:     "v1.0.0",
 			date:    date,
 			commits: []GitCommitLog{commitlog("t1", map[string]string{}, "a"), commitlog("unmapped", map[string]string{"breaking-change": "breaks"}, "a")},
-			want:    releaseNote(semver.MustParse("1.0.0"), "v1.0.0", date, []ReleaseNoteSection{newReleaseNoteCommitsSection("Tag 1", []string{"t1"}, []GitCommitLog{commitlog("t1", map[string]string{}, "a")}), ReleaseNoteBreakingChangeSection{Name: "Breaking Changes", Messages: []string{"breaks"}}}, map[string]struct{}{"a": {}}),
+			want:    withSummary(releaseNote(semver.MustParse("1.0.0"), "v1.0.0", date, []ReleaseNoteSection{newReleaseNoteCommitsSection("Tag 1", []string{"t1"}, []GitCommitLog{commitlog("t1", map[string]string{}, "a")}), ReleaseNoteBreakingChangeSection{Name: "Breaking Changes", Messages: []string{"breaks"}}}, map[string]struct{}{"a": {}}), 2, "a"),
 		},
 		{
 			name:    "multiple authors",
@@ -49,15 +49,254 @@ func TestReleaseNoteProcessorImpl_Create(t *testing.T) {
 			tag:     "v1.0.0",
 			date:    date,
 			commits: []GitCommitLog{commitlog("t1", map[string]string{}, "author3"), commitlog("t1", map[string]string{}, "author2"), commitlog("t1", map[string]string{}, "author1")},
-			want:    releaseNote(semver.MustParse("1.0.0"), "v1.0.0", date, []ReleaseNoteSection{newReleaseNoteCommitsSection("Tag 1", []string{"t1"}, []GitCommitLog{commitlog("t1", map[string]string{}, "author3"), commitlog("t1", map[string]string{}, "author2"), commitlog("t1", map[string]string{}, "author1")})}, map[string]struct{}{"author1": {}, "author2": {}, "author3": {}}),
+			want:    withSummary(releaseNote(semver.MustParse("1.0.0"), "v1.0.0", date, []ReleaseNoteSection{newReleaseNoteCommitsSection("Tag 1", []string{"t1"}, []GitCommitLog{commitlog("t1", map[string]string{}, "author3"), commitlog("t1", map[string]string{}, "author2"), commitlog("t1", map[string]string{}, "author1")})}, map[string]struct{}{"author1": {}, "author2": {}, "author3": {}}), 3, "author1", "author2", "author3"),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := NewReleaseNoteProcessor(ReleaseNotesConfig{Sections: []ReleaseNotesSectionConfig{{Name: "Tag 1", SectionType: "commits", CommitTypes: []string{"t1"}}, {Name: "Tag 2", SectionType: "commits", CommitTypes: []string{"t2"}}, {Name: "Breaking Changes", SectionType: "breaking-changes"}}})
-			if got := p.Create(tt.version, tt.tag, tt.date, tt.commits); !reflect.DeepEqual(got, tt.want) {
+			if got := p.Create(tt.version, tt.tag, "", tt.date, tt.commits); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ReleaseNoteProcessorImpl.Create() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestReleaseNoteProcessorImpl_Create_SectionsConfig(t *testing.T) {
+	commits := []GitCommitLog{
+		commitlog("feat", map[string]string{}, "a"),
+		commitlog("chore", map[string]string{}, "a"),
+		commitlog("docs", map[string]string{}, "a"),
+		commitlog("build", map[string]string{"breaking-change": "drops go 1.18"}, "a"),
+		commitlog("perf", map[string]string{}, "a"),
+		commitlog("", map[string]string{}, "a"),
+	}
+	features := ReleaseNotesSectionConfig{Name: "Features", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"feat"}}
+	maintenance := ReleaseNotesSectionConfig{Name: "Maintenance", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"docs", "chore"}}
+	build := ReleaseNotesSectionConfig{Name: "Build", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"build"}, Hidden: true}
+	breaking := ReleaseNotesSectionConfig{Name: "Breaking Changes", SectionType: ReleaseNotesSectionTypeBreakingChanges}
+	breakingSection := ReleaseNoteBreakingChangeSection{Name: "Breaking Changes", Messages: []string{"drops go 1.18"}}
+
+	tests := []struct {
+		name     string
+		sections []ReleaseNotesSectionConfig
+		want     []ReleaseNoteSection
+	}{
+		{"merged section in config order", []ReleaseNotesSectionConfig{breaking, maintenance, features}, []ReleaseNoteSection{
+			breakingSection,
+			newReleaseNoteCommitsSection("Maintenance", []string{"docs", "chore"}, []GitCommitLog{commits[1], commits[2]}),
+			newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{commits[0]}),
+		}},
+		{"hidden type keeps breaking changes", []ReleaseNotesSectionConfig{features, build, breaking}, []ReleaseNoteSection{
+			newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{commits[0]}),
+			breakingSection,
+		}},
+		{"catch-all section", []ReleaseNotesSectionConfig{features, build, {Name: "Other", SectionType: ReleaseNotesSectionTypeCommits, CatchAll: true}, breaking}, []ReleaseNoteSection{
+			newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{commits[0]}),
+			newReleaseNoteCommitsSection("Other", []string{"chore", "docs", "perf"}, []GitCommitLog{commits[1], commits[2], commits[4]}),
+			breakingSection,
+		}},
+		{"hidden catch-all section", []ReleaseNotesSectionConfig{features, {Name: "Other", SectionType: ReleaseNotesSectionTypeCommits, CatchAll: true, Hidden: true}}, []ReleaseNoteSection{
+			newReleaseNoteCommitsSection("Features", []string{"feat"}, []GitCommitLog{commits[0]}),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewReleaseNoteProcessor(ReleaseNotesConfig{Sections: tt.sections}).Create(nil, "", "", time.Now(), commits)
+			if !reflect.DeepEqual(got.Sections, tt.want) {
+				t.Errorf("ReleaseNoteProcessorImpl.Create() sections = %+v, want %+v", got.Sections, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseNoteProcessorImpl_Create_TypeSettings(t *testing.T) {
+	commits := []GitCommitLog{
+		commitlog("feat", map[string]string{}, "a"),
+		commitlog("ci", map[string]string{}, "a"),
+		commitlog("fix", map[string]string{"breaking-change": "removes retries"}, "a"),
+		commitlog("build", map[string]string{}, "a"),
+	}
+	sections := []ReleaseNotesSectionConfig{
+		{Name: "Features", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"feat", "fix"}},
+		{Name: "Maintenance", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"ci", "build"}, Hidden: true},
+		{Name: "Breaking Changes", SectionType: ReleaseNotesSectionTypeBreakingChanges},
+	}
+	p := NewReleaseNoteProcessor(ReleaseNotesConfig{Sections: sections})
+	p.SetTypeSettings(map[string]CommitTypeSettings{"ci": {Bump: "none", Notes: boolPtr(true)}, "fix": {Notes: boolPtr(false)}})
+
+	want := []ReleaseNoteSection{
+		newReleaseNoteCommitsSection("Features", []string{"feat", "fix"}, []GitCommitLog{commits[0]}),
+		newReleaseNoteCommitsSection("Maintenance", []string{"ci", "build"}, []GitCommitLog{commits[1]}),
+		ReleaseNoteBreakingChangeSection{Name: "Breaking Changes", Messages: []string{"removes retries"}},
+	}
+	if got := p.Create(nil, "", "", time.Now(), commits); !reflect.DeepEqual(got.Sections, want) {
+		t.Errorf("ReleaseNoteProcessorImpl.Create() sections = %+v, want %+v", got.Sections, want)
+	}
+}
+
+func TestContributorNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []GitCommitLog
+		want    []string
+	}{
+		{"no commits", nil, nil},
+		{"sorted case-insensitively", []GitCommitLog{
+			{AuthorName: "bob", AuthorEmail: "bob@example.com"},
+			{AuthorName: "Alice", AuthorEmail: "alice@example.com"},
+			{AuthorName: "carol", AuthorEmail: "carol@example.com"},
+		}, []string{"Alice", "bob", "carol"}},
+		{"same email with most recent name", []GitCommitLog{
+			{AuthorName: "Bob Smith", AuthorEmail: "Bob@Example.com", Timestamp: 300},
+			{AuthorName: "bob", AuthorEmail: "bob@example.com", Timestamp: 100},
+			{AuthorName: "Robert", AuthorEmail: "BOB@example.com", Timestamp: 200},
+		}, []string{"Bob Smith"}},
+		{"same name with different emails", []GitCommitLog{
+			{AuthorName: "Bob", AuthorEmail: "bob@work.com"},
+			{AuthorName: "Bob", AuthorEmail: "bob@home.com"},
+		}, []string{"Bob", "Bob"}},
+		{"without email", []GitCommitLog{{AuthorName: "a"}, {AuthorName: "A"}, {AuthorName: "b"}}, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContributorNames(tt.commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ContributorNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseNoteProcessorImpl_CompareURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         ReleaseNotesConfig
+		tag         string
+		previousTag string
+		want        string
+	}{
+		{"without config", ReleaseNotesConfig{}, "v1.1.0", "v1.0.0", ""},
+		{"compare tags", ReleaseNotesConfig{CompareURLTemplate: "https://github.com/org/repo/compare/{previous-tag}...{tag}"}, "v1.1.0", "v1.0.0", "https://github.com/org/repo/compare/v1.0.0...v1.1.0"},
+		{"unreleased version", ReleaseNotesConfig{CompareURLTemplate: "https://github.com/org/repo/compare/{previous-tag}...{tag}"}, "", "v1.0.0", "https://github.com/org/repo/compare/v1.0.0...HEAD"},
+		{"first release", ReleaseNotesConfig{CompareURLTemplate: "https://github.com/org/repo/compare/{previous-tag}...{tag}", TagURLTemplate: "https://github.com/org/repo/tree/{tag}"}, "v1.0.0", "", "https://github.com/org/repo/tree/v1.0.0"},
+		{"first release without tag url", ReleaseNotesConfig{CompareURLTemplate: "https://github.com/org/repo/compare/{previous-tag}...{tag}"}, "v1.0.0", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewReleaseNoteProcessor(tt.cfg).Create(semver.MustParse("1.1.0"), tt.tag, tt.previousTag, time.Now(), nil)
+			if got.CompareURL != tt.want {
+				t.Errorf("ReleaseNoteProcessorImpl.Create() CompareURL = %q, want %q", got.CompareURL, tt.want)
+			}
+			if got.PreviousTag != tt.previousTag {
+				t.Errorf("ReleaseNoteProcessorImpl.Create() PreviousTag = %q, want %q", got.PreviousTag, tt.previousTag)
+			}
+		})
+	}
+}
+
+func TestReleaseNoteProcessorImpl_PullRequestURL(t *testing.T) {
+	metadata := map[string]string{"pr": "12"}
+	commits := []GitCommitLog{{Hash: "a", Message: CommitMessage{Type: "feat", Metadata: metadata}}}
+	cfg := ReleaseNotesConfig{
+		Sections:      []ReleaseNotesSectionConfig{{Name: "Features", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"feat"}}},
+		PRURLTemplate: "https://github.com/org/repo/pull/{pr}",
+	}
+
+	got := NewReleaseNoteProcessor(cfg).Create(nil, "", "", time.Now(), commits)
+	items := got.Sections[0].(ReleaseNoteCommitsSection).Items
+	if url := items[0].Message.Metadata["pr-url"]; url != "https://github.com/org/repo/pull/12" {
+		t.Errorf("ReleaseNoteProcessorImpl.Create() pr-url = %q, want https://github.com/org/repo/pull/12", url)
+	}
+	if _, exists := metadata["pr-url"]; exists {
+		t.Error("ReleaseNoteProcessorImpl.Create() changed commit metadata")
+	}
+}
+
+func TestAnnotateSharedCommits(t *testing.T) {
+	roots := map[string]string{"api": "services/api", "web": "services/web", "lib": "lib"}
+	metadata := map[string]string{"issue": "JIRA-1"}
+	commits := []GitCommitLog{
+		{Hash: "a", Message: CommitMessage{Metadata: metadata}, Files: []string{"services/api/main.go", "lib/util.go", "services/web/index.js"}},
+		{Hash: "b", Files: []string{"services/api/main.go", "README.md"}},
+		{Hash: "c", Files: []string{"services/api-docs/index.md"}},
+	}
+
+	got := AnnotateSharedCommits(commits, "api", roots)
+	want := []string{"lib, web", "", ""}
+	for i, commit := range got {
+		if shared := commit.Message.Metadata["shared-with"]; shared != want[i] {
+			t.Errorf("AnnotateSharedCommits() commit %s shared-with = %q, want %q", commit.Hash, shared, want[i])
+		}
+	}
+	if got[0].Message.Metadata["issue"] != "JIRA-1" {
+		t.Errorf("AnnotateSharedCommits() metadata = %v, want issue kept", got[0].Message.Metadata)
+	}
+	if _, exists := metadata["shared-with"]; exists {
+		t.Error("AnnotateSharedCommits() changed commit metadata")
+	}
+}
+
+func TestRelativeCommitPaths(t *testing.T) {
+	metadata := map[string]string{"breaking-change": "remove services/sigma/legacy.go"}
+	commits := []GitCommitLog{
+		{Hash: "a", Message: CommitMessage{Description: "fix services/sigma/handler.go and `./services/sigma/cmd/main.go`", Metadata: metadata}},
+		{Hash: "b", Message: CommitMessage{Description: "update services/sigma-docs/index.md and lib/services/sigma/util.go"}},
+	}
+
+	got := RelativeCommitPaths(commits, "services/sigma")
+	if want := "fix handler.go and `cmd/main.go`"; got[0].Message.Description != want {
+		t.Errorf("RelativeCommitPaths() description = %q, want %q", got[0].Message.Description, want)
+	}
+	if want := "remove legacy.go"; got[0].Message.BreakingMessage() != want {
+		t.Errorf("RelativeCommitPaths() breaking message = %q, want %q", got[0].Message.BreakingMessage(), want)
+	}
+	if got[1].Message.Description != commits[1].Message.Description {
+		t.Errorf("RelativeCommitPaths() description = %q, want unchanged", got[1].Message.Description)
+	}
+	if metadata["breaking-change"] != "remove services/sigma/legacy.go" {
+		t.Error("RelativeCommitPaths() changed commit metadata")
+	}
+}
+
+func TestReleaseNotesConfig_DateLayout(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", "2006-01-02"},
+		{"iso", "2006-01-02"},
+		{"us", "01/02/2006"},
+		{"eu", "02/01/2006"},
+		{"2 Jan 2006", "2 Jan 2006"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := (ReleaseNotesConfig{DateFormat: tt.format}).DateLayout(); got != tt.want {
+				t.Errorf("ReleaseNotesConfig.DateLayout() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseNotesConfig_Location(t *testing.T) {
+	tests := []struct {
+		timezone string
+		want     *time.Location
+		wantErr  bool
+	}{
+		{"", nil, false},
+		{"UTC", time.UTC, false},
+		{"Mars/Olympus_Mons", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			got, err := ReleaseNotesConfig{Timezone: tt.timezone}.Location()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReleaseNotesConfig.Location() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReleaseNotesConfig.Location() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-# Changelog
+# {{text "changelog"}}
 {{- range .}}
 
 {{template "releasenotes-md.tpl" .}}
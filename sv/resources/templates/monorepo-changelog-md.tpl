@@ -0,0 +1,9 @@
+{{- range $i, $component := .}}{{if $i}}
+
+{{end}}# {{$component.Name}}
+{{- range $component.ReleaseNotes}}
+
+{{template "releasenotes-md.tpl" .}}
+---
+{{- end}}
+{{- end}}
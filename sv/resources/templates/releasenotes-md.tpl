@@ -1,4 +1,8 @@
-## {{if .Release}}{{.Release}}{{end}}{{if and (not .Date.IsZero) .Release}} ({{end}}{{timefmt .Date "2006-01-02"}}{{if and (not .Date.IsZero) .Release}}){{end}}
+## {{if .Release}}{{if .CompareURL}}[{{.Release}}]({{.CompareURL}}){{else}}{{.Release}}{{end}}{{end}}{{if and (not .Date.IsZero) .Release}} ({{end}}{{timefmt .Date .DateFormat}}{{if and (not .Date.IsZero) .Release}}){{end}}
+{{- if .ShowSummary}}
+
+_{{.Summary}}_
+{{- end}}
 {{- range $section := .Sections }}
 {{- if (eq $section.SectionType "commits") }}
 {{- template "rn-md-section-commits.tpl" $section }}
@@ -0,0 +1,7 @@
+{{- if ne .Name ""}}
+
+### {{with .Prefix}}{{.}} {{end}}{{.Name}}
+{{range $k,$v := .Messages}}
+- {{if checklist}}[ ] {{end}}{{escape $v}}
+{{- end}}
+{{- end}}
\ No newline at end of file
@@ -0,0 +1,7 @@
+{{- if .}}{{- if ne .SectionName ""}}
+
+### {{with .Prefix}}{{.}} {{end}}{{.SectionName}}
+{{range $k,$v := .Items}}
+- {{if checklist}}[ ] {{$v.Message.Type}}{{with $v.Message.Scope}}({{escape .}}){{end}}{{if $v.Message.IsBreakingChange}}!{{end}}: {{else if $v.Message.Scope}}**{{escape $v.Message.Scope}}:** {{end}}{{escape $v.Message.Description}} ({{$v.Hash}}){{if $v.Message.Issues}}{{range $v.Message.Issues}} ({{range $i, $issue := .Issues}}{{if $i}}, {{end}}{{if $issue.URL}}[{{$issue.ID}}]({{$issue.URL}}){{else}}{{$issue.ID}}{{end}}{{end}}){{end}}{{else if $v.Message.Metadata.issue}} ({{$v.Message.Metadata.issue}}){{end}}{{with $v.Message.Metadata.pr}} ({{with index $v.Message.Metadata "pr-url"}}[#{{$v.Message.Metadata.pr}}]({{.}}){{else}}#{{.}}{{end}}){{end}}{{with index $v.Message.Metadata "shared-with"}} ({{text "shared-with"}} {{.}}){{end}}{{with index $v.Message.Metadata "duplicate-of"}} ({{text "also-in"}} {{.}}){{end}}
+{{- end}}
+{{- end}}{{- end}}
\ No newline at end of file
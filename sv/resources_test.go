@@ -1,4 +1,4 @@
-package main
+package sv
 
 import (
 	"testing"
@@ -8,6 +8,7 @@ func Test_checkTemplatesFiles(t *testing.T) {
 	tests := []string{
 		"resources/templates/changelog-md.tpl",
 		"resources/templates/releasenotes-md.tpl",
+		"resources/templates/monorepo-changelog-md.tpl",
 	}
 	for _, tt := range tests {
 		t.Run(tt, func(t *testing.T) {
@@ -1,6 +1,13 @@
 package sv
 
-import "github.com/Masterminds/semver/v3"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
 
 type versionType int
 
@@ -17,18 +24,68 @@ func IsValidVersion(value string) bool {
 	return err == nil
 }
 
-// ToVersion parse string to semver.Version.
+// ToVersion parse string to semver.Version, build metadata is removed.
 func ToVersion(value string) (*semver.Version, error) {
 	version := value
 	if version == "" {
 		version = "0.0.0"
 	}
-	return semver.NewVersion(version)
+	v, err := semver.NewVersion(version)
+	if err != nil || v.Metadata() == "" {
+		return v, err
+	}
+	withoutMetadata, err := v.SetMetadata("")
+	return &withoutMetadata, err
+}
+
+const (
+	// MetadataPlaceholderHash placeholder replaced by HEAD abbreviated hash on versioning.metadata-template.
+	MetadataPlaceholderHash = "{hash}"
+	// MetadataPlaceholderBranch placeholder replaced by current branch on versioning.metadata-template.
+	MetadataPlaceholderBranch = "{branch}"
+)
+
+var (
+	metadataEnvPlaceholder   = regexp.MustCompile(`\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+	invalidMetadataCharacter = regexp.MustCompile(`[^0-9A-Za-z-]`)
+)
+
+// BuildMetadata renders versioning.metadata-template as semver build metadata, e.g. "build.{env:BUILD_NUMBER}.sha.{hash}".
+// {env:NAME} placeholders are replaced by environment variables, characters not allowed on metadata are replaced by "-"
+// and empty identifiers are removed.
+func BuildMetadata(template, hash, branch string, getenv func(string) string) string {
+	rendered := strings.NewReplacer(MetadataPlaceholderHash, hash, MetadataPlaceholderBranch, branch).Replace(template)
+	rendered = metadataEnvPlaceholder.ReplaceAllStringFunc(rendered, func(placeholder string) string {
+		return getenv(metadataEnvPlaceholder.FindStringSubmatch(placeholder)[1])
+	})
+
+	var identifiers []string
+	for _, identifier := range strings.Split(rendered, ".") {
+		if identifier = invalidMetadataCharacter.ReplaceAllString(identifier, "-"); identifier != "" {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	return strings.Join(identifiers, ".")
 }
 
 // SemVerCommitsProcessor interface.
 type SemVerCommitsProcessor interface {
 	NextVersion(version *semver.Version, commits []GitCommitLog) (*semver.Version, bool)
+	Explain(commits []GitCommitLog) BumpExplanation
+}
+
+// CommitBump is the version update required by a single commit and why.
+type CommitBump struct {
+	Commit GitCommitLog
+	Bump   string // major, minor, patch or none
+	Reason string
+}
+
+// BumpExplanation describes how NextVersion decides the version update.
+type BumpExplanation struct {
+	Bump    string       // highest update required by commits: major, minor, patch or none
+	Applied string       // update applied, differs from Bump when capped by a release branch
+	Commits []CommitBump // commits requiring the highest update, in log order
 }
 
 // SemVerCommitsProcessorImpl process versions using commit log.
@@ -38,17 +95,52 @@ type SemVerCommitsProcessorImpl struct {
 	PatchVersionTypes         map[string]struct{}
 	KnownTypes                []string
 	IncludeUnknownTypeAsPatch bool
+	UnknownCommitBump         string // versioning.unknown-commit-bump, update of non-conventional commits
+	typeBumps                 map[string]versionType
+	maxUpdate                 versionType
+	calVer                    *calVerLayout
+	now                       func() time.Time
 }
 
 // NewSemVerCommitsProcessor SemanticVersionCommitsProcessorImpl constructor.
+// With calver scheme, versions are calculated from the current date, an invalid versioning.calver-layout
+// falls back to the default layout, use VersioningConfig.Validate to check it.
+// Bump attributes of commit-message.type-settings override versioning update lists, types without it keep using the lists.
 func NewSemVerCommitsProcessor(vcfg VersioningConfig, mcfg CommitMessageConfig) *SemVerCommitsProcessorImpl {
-	return &SemVerCommitsProcessorImpl{
+	p := &SemVerCommitsProcessorImpl{
 		IncludeUnknownTypeAsPatch: !vcfg.IgnoreUnknown,
 		MajorVersionTypes:         toMap(vcfg.UpdateMajor),
 		MinorVersionTypes:         toMap(vcfg.UpdateMinor),
 		PatchVersionTypes:         toMap(vcfg.UpdatePatch),
 		KnownTypes:                mcfg.Types,
+		UnknownCommitBump:         vcfg.UnknownCommitBump,
+		typeBumps:                 make(map[string]versionType),
+		now:                       time.Now,
 	}
+	for commitType, settings := range mcfg.TypeSettings {
+		if settings.Bump != "" {
+			p.typeBumps[commitType] = parseVersionType(settings.Bump)
+		}
+	}
+	if vcfg.Scheme == VersioningSchemeCalVer {
+		layout, err := parseCalVerLayout(vcfg.CalVerLayout)
+		if err != nil {
+			layout, _ = parseCalVerLayout(defaultCalVerLayout)
+		}
+		p.calVer = &layout
+	}
+	return p
+}
+
+// SetReleaseBranch caps version updates while on a release branch, updates are limited to patch,
+// or to minor if the branch captures only the major version.
+func (p *SemVerCommitsProcessorImpl) SetReleaseBranch(branch ReleaseBranch) {
+	p.maxUpdate = branch.maxUpdate()
+}
+
+// SetClock defines the clock used as current date of calendar versions, system time by default.
+func (p *SemVerCommitsProcessorImpl) SetClock(clock Clock) {
+	p.now = clock.Now
 }
 
 // NextVersion calculates next version based on commit log.
@@ -60,10 +152,21 @@ func (p SemVerCommitsProcessorImpl) NextVersion(version *semver.Version, commits
 		}
 	}
 
+	if p.maxUpdate != none && versionToUpdate > p.maxUpdate {
+		versionToUpdate = p.maxUpdate
+	}
+
 	updated := versionToUpdate != none
 	if version == nil {
 		return nil, updated
 	}
+	if p.calVer != nil {
+		if !updated {
+			return version, false
+		}
+		newVersion := p.calVer.next(*version, p.now())
+		return &newVersion, true
+	}
 	newVersion := updateVersion(*version, versionToUpdate)
 	return &newVersion, updated
 }
@@ -81,23 +184,102 @@ func updateVersion(version semver.Version, versionToUpdate versionType) semver.V
 	}
 }
 
+// Explain classifies each commit and returns the commits that decided the version update.
+func (p SemVerCommitsProcessorImpl) Explain(commits []GitCommitLog) BumpExplanation {
+	highest := none
+	var contributing []CommitBump
+	for _, commit := range commits {
+		v, reason := p.classify(commit)
+		if v == none || v < highest {
+			continue
+		}
+		if v > highest {
+			highest, contributing = v, nil
+		}
+		contributing = append(contributing, CommitBump{Commit: commit, Bump: v.String(), Reason: reason})
+	}
+
+	applied := highest
+	if p.maxUpdate != none && applied > p.maxUpdate {
+		applied = p.maxUpdate
+	}
+	return BumpExplanation{Bump: highest.String(), Applied: applied.String(), Commits: contributing}
+}
+
 func (p SemVerCommitsProcessorImpl) versionTypeToUpdate(commit GitCommitLog) versionType {
+	v, _ := p.classify(commit)
+	return v
+}
+
+func (p SemVerCommitsProcessorImpl) classify(commit GitCommitLog) (versionType, string) {
 	if commit.Message.IsBreakingChange {
-		return major
+		return major, "breaking change"
+	}
+	if v, exists := p.typeBumps[commit.Message.Type]; exists {
+		if v == none {
+			return none, ""
+		}
+		return v, fmt.Sprintf("type %s with bump %s on commit-message.type-settings", commit.Message.Type, v)
 	}
 	if _, exists := p.MajorVersionTypes[commit.Message.Type]; exists {
-		return major
+		return major, fmt.Sprintf("type %s on versioning.update-major", commit.Message.Type)
 	}
 	if _, exists := p.MinorVersionTypes[commit.Message.Type]; exists {
-		return minor
+		return minor, fmt.Sprintf("type %s on versioning.update-minor", commit.Message.Type)
 	}
 	if _, exists := p.PatchVersionTypes[commit.Message.Type]; exists {
-		return patch
+		return patch, fmt.Sprintf("type %s on versioning.update-patch", commit.Message.Type)
+	}
+	if commit.Message.Type == "" {
+		switch p.UnknownCommitBump {
+		case UnknownCommitBumpPatch:
+			return patch, "non-conventional commit with versioning.unknown-commit-bump patch"
+		case UnknownCommitBumpNone:
+			return none, ""
+		}
 	}
 	if !contains(commit.Message.Type, p.KnownTypes) && p.IncludeUnknownTypeAsPatch {
+		return patch, fmt.Sprintf("unknown type %q with versioning.ignore-unknown disabled", commit.Message.Type)
+	}
+	return none, ""
+}
+
+// UnknownCommits returns commits whose header does not follow conventional commits, in log order.
+// They update versions according to versioning.unknown-commit-bump and ignore-unknown.
+func UnknownCommits(commits []GitCommitLog) []GitCommitLog {
+	var result []GitCommitLog
+	for _, commit := range commits {
+		if commit.Message.Type == "" {
+			result = append(result, commit)
+		}
+	}
+	return result
+}
+
+func (v versionType) String() string {
+	switch v {
+	case major:
+		return "major"
+	case minor:
+		return "minor"
+	case patch:
+		return "patch"
+	default:
+		return "none"
+	}
+}
+
+func parseVersionType(value string) versionType {
+	switch value {
+	case "major":
+		return major
+	case "minor":
+		return minor
+	case "patch":
 		return patch
+	default:
+		return none
 	}
-	return none
 }
 
 func toMap(values []string) map[string]struct{} {
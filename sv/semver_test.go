@@ -41,6 +41,122 @@ func TestSemVerCommitsProcessorImpl_NextVersion(t *testing.T) {
 	}
 }
 
+func TestSemVerCommitsProcessorImpl_NextVersion_UnknownCommitBump(t *testing.T) {
+	freeForm := GitCommitLog{Hash: "b", Message: CommitMessage{Description: "update readme"}}
+	mixed := []GitCommitLog{commitlog("none", map[string]string{}, "a"), freeForm}
+	tests := []struct {
+		name          string
+		bump          string
+		ignoreUnknown bool
+		commits       []GitCommitLog
+		want          *semver.Version
+	}{
+		{"ignore-unknown applies without config", "", true, mixed, version("1.0.0")},
+		{"patch with ignore-unknown", UnknownCommitBumpPatch, true, mixed, version("1.0.1")},
+		{"none without ignore-unknown", UnknownCommitBumpNone, false, mixed, version("1.0.0")},
+		{"none keeps unknown types as patch", UnknownCommitBumpNone, false, []GitCommitLog{commitlog("wip", map[string]string{}, "a"), freeForm}, version("1.0.1")},
+		{"higher update of conventional commits", UnknownCommitBumpPatch, true, append([]GitCommitLog{commitlog("minor", map[string]string{}, "a")}, mixed...), version("1.1.0")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewSemVerCommitsProcessor(VersioningConfig{UpdateMinor: []string{"minor"}, IgnoreUnknown: tt.ignoreUnknown, UnknownCommitBump: tt.bump}, CommitMessageConfig{Types: []string{"minor", "none"}})
+			if got, _ := p.NextVersion(version("1.0.0"), tt.commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SemVerCommitsProcessorImpl.NextVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownCommits(t *testing.T) {
+	commits := []GitCommitLog{
+		{Hash: "a", Message: CommitMessage{Type: "feat", Description: "add login"}},
+		{Hash: "b", Message: CommitMessage{Description: "update readme"}},
+		{Hash: "c", Message: CommitMessage{Type: "wip", Description: "unknown type"}},
+		{Hash: "d", Message: CommitMessage{Description: "Fix typo"}},
+	}
+	var got []string
+	for _, commit := range UnknownCommits(commits) {
+		got = append(got, commit.Hash)
+	}
+	if want := []string{"b", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownCommits() = %v, want %v", got, want)
+	}
+}
+
+func TestSemVerCommitsProcessorImpl_NextVersion_ReleaseBranch(t *testing.T) {
+	cherryPicked := []GitCommitLog{
+		commitlog("patch", map[string]string{}, "a"),
+		commitlog("minor", map[string]string{}, "a"),
+		commitlog("patch", map[string]string{"breaking-change": "break"}, "a"),
+	}
+	tests := []struct {
+		name    string
+		branch  ReleaseBranch
+		commits []GitCommitLog
+		want    *semver.Version
+	}{
+		{"patch only branch with feat and breaking commits", ReleaseBranch{Name: "release/1.2.x", Major: 1, Minor: 2}, cherryPicked, version("1.2.4")},
+		{"patch only branch without captured version", ReleaseBranch{Name: "maintenance", Major: -1, Minor: -1}, cherryPicked, version("1.2.4")},
+		{"major line branch with feat and breaking commits", ReleaseBranch{Name: "release/1.x", Major: 1, Minor: -1}, cherryPicked, version("1.3.0")},
+		{"major line branch with patch commits", ReleaseBranch{Name: "release/1.x", Major: 1, Minor: -1}, cherryPicked[:1], version("1.2.4")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewSemVerCommitsProcessor(VersioningConfig{UpdateMajor: []string{"major"}, UpdateMinor: []string{"minor"}, UpdatePatch: []string{"patch"}}, CommitMessageConfig{Types: []string{"major", "minor", "patch"}})
+			p.SetReleaseBranch(tt.branch)
+			got, updated := p.NextVersion(version("1.2.3"), tt.commits)
+			if !reflect.DeepEqual(got, tt.want) || !updated {
+				t.Errorf("SemVerCommitsProcessorImpl.NextVersion() = %v, %v, want %v, true", got, updated, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemVerCommitsProcessorImpl_Explain(t *testing.T) {
+	withHash := func(hash string, c GitCommitLog) GitCommitLog {
+		c.Hash = hash
+		return c
+	}
+	feat := withHash("a1", commitlog("minor", map[string]string{}, "a"))
+	fix := withHash("b2", commitlog("patch", map[string]string{}, "a"))
+	breaking := withHash("c3", commitlog("patch", map[string]string{"breaking-change": "break"}, "a"))
+	major := withHash("d4", commitlog("major", map[string]string{}, "a"))
+	unknown := withHash("e5", commitlog("other", map[string]string{}, "a"))
+
+	tests := []struct {
+		name    string
+		branch  ReleaseBranch
+		commits []GitCommitLog
+		want    BumpExplanation
+	}{
+		{"no commits", ReleaseBranch{}, nil, BumpExplanation{Bump: "none", Applied: "none"}},
+		{"highest type", ReleaseBranch{}, []GitCommitLog{fix, feat, fix}, BumpExplanation{Bump: "minor", Applied: "minor", Commits: []CommitBump{
+			{Commit: feat, Bump: "minor", Reason: "type minor on versioning.update-minor"},
+		}}},
+		{"breaking changes and major types", ReleaseBranch{}, []GitCommitLog{feat, breaking, major}, BumpExplanation{Bump: "major", Applied: "major", Commits: []CommitBump{
+			{Commit: breaking, Bump: "major", Reason: "breaking change"},
+			{Commit: major, Bump: "major", Reason: "type major on versioning.update-major"},
+		}}},
+		{"unknown type", ReleaseBranch{}, []GitCommitLog{unknown}, BumpExplanation{Bump: "patch", Applied: "patch", Commits: []CommitBump{
+			{Commit: unknown, Bump: "patch", Reason: `unknown type "other" with versioning.ignore-unknown disabled`},
+		}}},
+		{"release branch", ReleaseBranch{Name: "release/1.2.x", Major: 1, Minor: 2}, []GitCommitLog{feat}, BumpExplanation{Bump: "minor", Applied: "patch", Commits: []CommitBump{
+			{Commit: feat, Bump: "minor", Reason: "type minor on versioning.update-minor"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewSemVerCommitsProcessor(VersioningConfig{UpdateMajor: []string{"major"}, UpdateMinor: []string{"minor"}, UpdatePatch: []string{"patch"}}, CommitMessageConfig{Types: []string{"major", "minor", "patch"}})
+			if tt.branch.Name != "" {
+				p.SetReleaseBranch(tt.branch)
+			}
+			if got := p.Explain(tt.commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SemVerCommitsProcessorImpl.Explain() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestToVersion(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -51,6 +167,7 @@ func TestToVersion(t *testing.T) {
 		{"empty version", "", version("0.0.0"), false},
 		{"invalid version", "abc", nil, true},
 		{"valid version", "1.2.3", version("1.2.3"), false},
+		{"version with metadata", "1.2.3+build.4821.sha.abc1234", version("1.2.3"), false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -66,6 +183,29 @@ func TestToVersion(t *testing.T) {
 	}
 }
 
+func TestBuildMetadata(t *testing.T) {
+	env := map[string]string{"BUILD_NUMBER": "4821"}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"static", "build.1", "build.1"},
+		{"placeholders", "build.{env:BUILD_NUMBER}.sha.{hash}", "build.4821.sha.abc1234"},
+		{"branch with invalid characters", "{branch}", "feature-add-new-x"},
+		{"empty env var", "build.{env:MISSING}.sha.{hash}", "build.sha.abc1234"},
+		{"empty", "{env:MISSING}", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildMetadata(tt.template, "abc1234", "feature/add_new+x", func(key string) string { return env[key] })
+			if got != tt.want {
+				t.Errorf("BuildMetadata() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIsValidVersion(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -95,3 +235,110 @@ func TestIsValidVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestVersioningConfig_BumpTypeWarnings(t *testing.T) {
+	types := []string{"feat", "fix", "chore"}
+	tests := []struct {
+		name string
+		cfg  VersioningConfig
+		want []string
+	}{
+		{"valid", VersioningConfig{UpdateMinor: []string{"feat"}, UpdatePatch: []string{"fix", "chore"}}, nil},
+		{"unknown type", VersioningConfig{UpdateMinor: []string{"feature"}, UpdatePatch: []string{"fix"}},
+			[]string{"type feature on versioning.update-minor is not on commit-message.types"}},
+		{"type on several lists", VersioningConfig{UpdateMajor: []string{"feat"}, UpdateMinor: []string{"feat"}, UpdatePatch: []string{"feat", "fix"}},
+			[]string{
+				"type feat is on versioning.update-major and versioning.update-minor, versioning.update-major is applied",
+				"type feat is on versioning.update-major and versioning.update-patch, versioning.update-major is applied",
+			}},
+		{"duplicated on the same list", VersioningConfig{UpdatePatch: []string{"fix", "fix"}}, nil},
+		{"unknown type on several lists", VersioningConfig{UpdateMinor: []string{"bug"}, UpdatePatch: []string{"bug"}},
+			[]string{
+				"type bug on versioning.update-minor is not on commit-message.types",
+				"type bug on versioning.update-patch is not on commit-message.types",
+				"type bug is on versioning.update-minor and versioning.update-patch, versioning.update-minor is applied",
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.BumpTypeWarnings(types); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("VersioningConfig.BumpTypeWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemVerCommitsProcessorImpl_NextVersion_TypeSettings(t *testing.T) {
+	vcfg := VersioningConfig{UpdateMinor: []string{"feat"}, UpdatePatch: []string{"fix", "ci"}, IgnoreUnknown: true}
+	mcfg := CommitMessageConfig{Types: []string{"feat", "fix", "ci", "security"}, TypeSettings: map[string]CommitTypeSettings{
+		"ci":       {Bump: "none"},
+		"security": {Bump: "patch"},
+		"fix":      {Notes: boolPtr(false)},
+	}}
+	tests := []struct {
+		name    string
+		commits []GitCommitLog
+		want    *semver.Version
+	}{
+		{"bump none overrides update list", []GitCommitLog{commitlog("ci", map[string]string{}, "a")}, version("1.0.0")},
+		{"bump without update list", []GitCommitLog{commitlog("security", map[string]string{}, "a")}, version("1.0.1")},
+		{"update list without bump", []GitCommitLog{commitlog("fix", map[string]string{}, "a")}, version("1.0.1")},
+		{"breaking change with bump none", []GitCommitLog{commitlog("ci", map[string]string{"breaking-change": "drops travis"}, "a")}, version("2.0.0")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := NewSemVerCommitsProcessor(vcfg, mcfg).NextVersion(version("1.0.0"), tt.commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SemVerCommitsProcessorImpl.NextVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	explanation := NewSemVerCommitsProcessor(vcfg, mcfg).Explain([]GitCommitLog{commitlog("security", map[string]string{}, "a")})
+	if len(explanation.Commits) != 1 || explanation.Commits[0].Reason != "type security with bump patch on commit-message.type-settings" {
+		t.Errorf("SemVerCommitsProcessorImpl.Explain() = %+v, want type-settings reason", explanation)
+	}
+}
+
+func TestConfig_TypeSettingsWarnings(t *testing.T) {
+	cfg := Config{
+		CommitMessage: CommitMessageConfig{Types: []string{"feat", "ci"}, TypeSettings: map[string]CommitTypeSettings{
+			"ci":       {Notes: boolPtr(true)},
+			"feat":     {Notes: boolPtr(true)},
+			"security": {Bump: "patch", Notes: boolPtr(false)},
+		}},
+		ReleaseNotes: ReleaseNotesConfig{Sections: []ReleaseNotesSectionConfig{{Name: "Features", SectionType: ReleaseNotesSectionTypeCommits, CommitTypes: []string{"feat"}}}},
+	}
+	want := []string{
+		"type ci has notes enabled on commit-message.type-settings but no release-notes section lists it",
+		"type security on commit-message.type-settings is not on commit-message.types",
+	}
+	if got := cfg.TypeSettingsWarnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config.TypeSettingsWarnings() = %q, want %q", got, want)
+	}
+
+	cfg.ReleaseNotes.Sections = append(cfg.ReleaseNotes.Sections, ReleaseNotesSectionConfig{Name: "Other", SectionType: ReleaseNotesSectionTypeCommits, CatchAll: true})
+	if got := cfg.TypeSettingsWarnings(); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("Config.TypeSettingsWarnings() with catch-all = %q, want %q", got, want[1:])
+	}
+}
+
+func TestCommitMessageConfig_Validate_TypeSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		bump    string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"none", "none", false},
+		{"major", "major", false},
+		{"invalid", "minimal", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := CommitMessageConfig{TypeSettings: map[string]CommitTypeSettings{"ci": {Bump: tt.bump}}}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("CommitMessageConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package sv
+
+import (
+	// used to embed the misspellings list.
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed resources/misspellings.txt
+var defaultMisspellings string
+
+var (
+	spellingWordRegex   = regexp.MustCompile(`[\w']+`)
+	spellingIgnoreRegex = regexp.MustCompile("https?://\\S+|`[^`]*`")
+)
+
+// WordError word of a commit message violating banned-words or spelling rules.
+type WordError struct {
+	Word   string
+	Line   int // line of the message, the subject is line 1
+	Column int
+	Rule   string
+	Reason string
+}
+
+func (e WordError) Error() string {
+	return fmt.Sprintf("word %q at line %d, column %d %s (%s)", e.Word, e.Line, e.Column, e.Reason, e.Rule)
+}
+
+func (rule CommitMessageBannedWordConfig) regex() (*regexp.Regexp, error) {
+	r, err := regexp.Compile(rule.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex on commit-message.banned-words: %s, error: %v", rule.Regex, err)
+	}
+	return r, nil
+}
+
+// BannedWordViolations returns words of message matching commit-message.banned-words, footers are not checked.
+func BannedWordViolations(rules []CommitMessageBannedWordConfig, message string) []error {
+	var violations []error
+	for _, rule := range rules {
+		r, err := rule.regex()
+		if err != nil {
+			violations = append(violations, err)
+			continue
+		}
+		reason := "is not allowed"
+		if rule.Message != "" {
+			reason += ": " + rule.Message
+		}
+		for _, line := range checkedLines(message) {
+			for _, match := range r.FindAllStringIndex(line.text, -1) {
+				violations = append(violations, line.wordError(match, "banned-words: "+rule.Regex, reason))
+			}
+		}
+	}
+	return violations
+}
+
+// SpellChecker finds common misspellings on commit messages, like codespell only known misspellings are reported.
+type SpellChecker struct {
+	misspellings map[string]string
+}
+
+// NewSpellChecker SpellChecker constructor, dictionary words are accepted even if they are on the misspellings list.
+func NewSpellChecker(dictionary []string) *SpellChecker {
+	misspellings := make(map[string]string)
+	for _, line := range strings.Split(defaultMisspellings, "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+			misspellings[fields[0]] = strings.Join(fields[1:], " ")
+		}
+	}
+	for _, word := range dictionary {
+		delete(misspellings, strings.ToLower(word))
+	}
+	return &SpellChecker{misspellings: misspellings}
+}
+
+// ParseDictionary returns the words of a dictionary file, one per line, lines starting with # are ignored.
+func ParseDictionary(content string) []string {
+	var words []string
+	for _, line := range strings.Split(content, "\n") {
+		if word := strings.TrimSpace(line); word != "" && !strings.HasPrefix(word, "#") {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Violations returns misspelled words of message, urls, code between backticks and footers are not checked.
+func (s SpellChecker) Violations(message string) []error {
+	var violations []error
+	for _, line := range checkedLines(message) {
+		text := spellingIgnoreRegex.ReplaceAllStringFunc(line.text, func(ignored string) string {
+			return strings.Repeat(" ", len(ignored))
+		})
+		for _, match := range spellingWordRegex.FindAllStringIndex(text, -1) {
+			if correction, found := s.misspellings[strings.ToLower(text[match[0]:match[1]])]; found {
+				violations = append(violations, line.wordError(match, "spelling", fmt.Sprintf("is misspelled, did you mean %q?", correction)))
+			}
+		}
+	}
+	return violations
+}
+
+type messageLine struct {
+	number int
+	text   string
+}
+
+func (l messageLine) wordError(match []int, rule, reason string) WordError {
+	return WordError{
+		Word:   l.text[match[0]:match[1]],
+		Line:   l.number,
+		Column: utf8.RuneCountInString(l.text[:match[0]]) + 1,
+		Rule:   rule,
+		Reason: reason,
+	}
+}
+
+// checkedLines returns subject and body lines checked by word rules, comments and footers of the last paragraph,
+// that carry issue ids and urls, are ignored. Breaking change footers are checked.
+func checkedLines(message string) []messageLine {
+	var lines []messageLine
+	for i, line := range strings.Split(removeCarriage(message), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, messageLine{number: i + 1, text: line})
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1].text) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	footersStart := len(lines)
+	for i := len(lines) - 1; i > 0; i-- {
+		if strings.TrimSpace(lines[i].text) == "" {
+			footersStart = i + 1
+			break
+		}
+	}
+
+	var result []messageLine
+	for i, line := range lines {
+		if i >= footersStart && footerLineRegex.MatchString(line.text) && !strings.HasPrefix(line.text, breakingChangeFooterKey) {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
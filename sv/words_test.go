@@ -0,0 +1,91 @@
+package sv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBannedWordViolations(t *testing.T) {
+	rules := []CommitMessageBannedWordConfig{{Regex: `(?i)\bcapybara\b`, Message: "internal codenames are not public"}}
+	tests := []struct {
+		name    string
+		message string
+		want    []WordError
+	}{
+		{"clean message", "feat: add login", nil},
+		{"subject", "feat: enable Capybara mode", []WordError{{Word: "Capybara", Line: 1, Column: 14}}},
+		{"body", "fix: something\n\nfound while testing\nthe capybara build", []WordError{{Word: "capybara", Line: 4, Column: 5}}},
+		{"footers are ignored", "fix: something\n\nRefs: https://tracker/capybara\njira: CAPYBARA-12", nil},
+		{"breaking change footer", "fix: something\n\nBREAKING CHANGE: capybara removed", []WordError{{Word: "capybara", Line: 3, Column: 18}}},
+		{"comments are ignored", "fix: something\n# capybara", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []WordError
+			for _, violation := range BannedWordViolations(rules, tt.message) {
+				werr := violation.(WordError)
+				if werr.Rule != `banned-words: (?i)\bcapybara\b` || werr.Reason != "is not allowed: internal codenames are not public" {
+					t.Errorf("BannedWordViolations() rule = %q, reason = %q", werr.Rule, werr.Reason)
+				}
+				got = append(got, WordError{Word: werr.Word, Line: werr.Line, Column: werr.Column})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BannedWordViolations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpellChecker_Violations(t *testing.T) {
+	tests := []struct {
+		name       string
+		dictionary []string
+		message    string
+		want       []string
+	}{
+		{"without misspellings", nil, "fix: receive events", nil},
+		{"subject and body", nil, "fix: recieve events\n\nTeh handler is now idempotent", []string{
+			`word "recieve" at line 1, column 6 is misspelled, did you mean "receive"? (spelling)`,
+			`word "Teh" at line 3, column 1 is misspelled, did you mean "the"? (spelling)`,
+		}},
+		{"urls and code are ignored", nil, "fix: handle `recieve` of https://example.com/recieve", nil},
+		{"dictionary", []string{"Recieve"}, "fix: recieve events", nil},
+		{"footers are ignored", nil, "fix: events\n\nRefs: https://example.com/seperate", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, violation := range NewSpellChecker(tt.dictionary).Violations(tt.message) {
+				got = append(got, violation.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SpellChecker.Violations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDictionary(t *testing.T) {
+	got := ParseDictionary("# project terms\nkubectl\n\n  sv4git \n")
+	if want := []string{"kubectl", "sv4git"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDictionary() = %v, want %v", got, want)
+	}
+}
+
+func TestCommitMessageConfig_Validate_BannedWords(t *testing.T) {
+	if err := (CommitMessageConfig{BannedWords: []CommitMessageBannedWordConfig{{Regex: "capybara"}}}).Validate(); err != nil {
+		t.Errorf("CommitMessageConfig.Validate() unexpected error: %v", err)
+	}
+	if err := (CommitMessageConfig{BannedWords: []CommitMessageBannedWordConfig{{Regex: "capy(bara"}}}).Validate(); err == nil {
+		t.Error("CommitMessageConfig.Validate() expected error for invalid regex")
+	}
+}
+
+func TestMessageProcessorImpl_Violations_BannedWords(t *testing.T) {
+	cfg := ccfg
+	cfg.BannedWords = []CommitMessageBannedWordConfig{{Regex: "capybara"}}
+	got := NewMessageProcessor(cfg, newBranchCfg(false)).Violations("feat: capybara support")
+	if len(got) != 1 || got[0].Error() != `word "capybara" at line 1, column 7 is not allowed (banned-words: capybara)` {
+		t.Errorf("MessageProcessorImpl.Violations() = %v, want banned word violation", got)
+	}
+}